@@ -0,0 +1,150 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Address family control for inter-node RPC. In mixed IPv4/IPv6
+// clusters peers may resolve to different families on different nodes,
+// producing inconsistent connectivity. MINIO_ADDRESS_FAMILY pins every
+// DNS resolution used for peer communication to one family.
+const (
+	// EnvAddressFamily restricts peer resolution to one address family,
+	// one of "dualstack" (default), "ipv4" or "ipv6".
+	EnvAddressFamily = "MINIO_ADDRESS_FAMILY"
+
+	addressFamilyDualStack = "dualstack"
+	addressFamilyInet4     = "ipv4"
+	addressFamilyInet6     = "ipv6"
+)
+
+var (
+	addressFamilyOnce  sync.Once
+	addressFamilyValue string
+)
+
+// addressFamily returns the configured inter-node address family.
+func addressFamily() string {
+	addressFamilyOnce.Do(func() {
+		switch v := env.Get(EnvAddressFamily, addressFamilyDualStack); v {
+		case addressFamilyDualStack, addressFamilyInet4, addressFamilyInet6:
+			addressFamilyValue = v
+		default:
+			internalLogIf(GlobalContext, fmt.Errorf("invalid %s value: %s, expecting one of 'dualstack', 'ipv4' or 'ipv6', ignoring", EnvAddressFamily, v))
+			addressFamilyValue = addressFamilyDualStack
+		}
+	})
+	return addressFamilyValue
+}
+
+// filterByAddressFamily drops resolved addresses not matching the
+// configured family. Hostnames pass through untouched.
+func filterByAddressFamily(addrs []string) []string {
+	family := addressFamily()
+	if family == addressFamilyDualStack {
+		return addrs
+	}
+	filtered := addrs[:0:len(addrs)]
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			filtered = append(filtered, addr)
+			continue
+		}
+		if (family == addressFamilyInet4) == (ip.To4() != nil) {
+			filtered = append(filtered, addr)
+		}
+	}
+	if len(filtered) == 0 {
+		internalLogOnceIf(GlobalContext, fmt.Errorf("no resolved addresses match %s=%s, peer resolution will fail", EnvAddressFamily, family), "address-family-empty")
+	}
+	return filtered
+}
+
+// lookupHostByFamily resolves host through the DNS cache and restricts
+// the answers to the configured address family. All inter-node dialers
+// resolve through this function.
+func lookupHostByFamily(ctx context.Context, host string) ([]string, error) {
+	addrs, err := globalDNSCache.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	return filterByAddressFamily(addrs), nil
+}
+
+// PeerAddressesHandler - GET /minio/admin/v3/peer-addresses
+// Reports how this node resolves each peer under the configured
+// address family, for debugging mixed IPv4/IPv6 clusters.
+func (a adminAPIHandlers) PeerAddressesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	type peerAddrs struct {
+		Host      string   `json:"host"`
+		Addresses []string `json:"addresses,omitempty"`
+		Error     string   `json:"error,omitempty"`
+	}
+
+	resp := struct {
+		Node   string      `json:"node"`
+		Family string      `json:"family"`
+		Peers  []peerAddrs `json:"peers"`
+	}{
+		Node:   globalLocalNodeName,
+		Family: addressFamily(),
+	}
+
+	seen := make(map[string]struct{})
+	for _, ep := range globalEndpoints.Hostnames() {
+		if _, ok := seen[ep]; ok {
+			continue
+		}
+		seen[ep] = struct{}{}
+
+		pa := peerAddrs{Host: ep}
+		if net.ParseIP(ep) != nil {
+			pa.Addresses = []string{ep}
+		} else if addrs, err := lookupHostByFamily(ctx, ep); err != nil {
+			pa.Error = err.Error()
+		} else {
+			pa.Addresses = addrs
+		}
+		resp.Peers = append(resp.Peers, pa)
+	}
+
+	buf, err := json.Marshal(resp)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Anonymous access audit and lockdown. Buckets become reachable
+// without credentials through bucket policy statements whose principal
+// is the wildcard - including the policies our ACL compatibility layer
+// writes for "public" and "download" access. The audit enumerates
+// every such statement across all buckets; the lockdown flips them
+// from Allow to Deny in a single metadata update per bucket, so a
+// bucket is never observable in a half-locked state, and reports
+// exactly what changed.
+
+// anonStatement describes one wildcard-principal Allow statement.
+type anonStatement struct {
+	SID           string   `json:"sid,omitempty"`
+	Actions       []string `json:"actions"`
+	Resources     []string `json:"resources"`
+	HasConditions bool     `json:"hasConditions,omitempty"`
+}
+
+// anonBucketReport lists a bucket's anonymously reachable statements.
+type anonBucketReport struct {
+	Bucket     string          `json:"bucket"`
+	Statements []anonStatement `json:"statements"`
+}
+
+// anonLockdownReport records a completed lockdown of one bucket.
+type anonLockdownReport struct {
+	Bucket    string          `json:"bucket"`
+	Denied    []anonStatement `json:"denied"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// describeAnonStatement flattens a statement for reporting.
+func describeAnonStatement(stmt policy.BPStatement) anonStatement {
+	out := anonStatement{
+		SID:           string(stmt.SID),
+		HasConditions: len(stmt.Conditions) > 0,
+	}
+	for action := range stmt.Actions {
+		out.Actions = append(out.Actions, string(action))
+	}
+	sort.Strings(out.Actions)
+	for res := range stmt.Resources {
+		out.Resources = append(out.Resources, res.String())
+	}
+	sort.Strings(out.Resources)
+	return out
+}
+
+// anonStatements returns the wildcard-principal Allow statements of a
+// bucket's policy, or nothing when the bucket has no policy.
+func anonStatements(bucket string) []policy.BPStatement {
+	p, err := globalPolicySys.Get(bucket)
+	if err != nil || p == nil {
+		return nil
+	}
+	var stmts []policy.BPStatement
+	for _, stmt := range p.Statements {
+		if stmt.Effect == policy.Allow && stmt.Principal.Match("*") {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// lockdownBucketAnon flips a bucket's wildcard-principal Allow
+// statements to Deny and persists the policy in one update. Returns
+// nil report when the bucket had nothing to lock down.
+func lockdownBucketAnon(ctx context.Context, bucket string) (*anonLockdownReport, error) {
+	p, err := globalPolicySys.Get(bucket)
+	if err != nil || p == nil {
+		// No policy means nothing reachable anonymously.
+		return nil, nil
+	}
+
+	// The policy returned by Get is the cached instance shared with
+	// concurrent evaluation and must not be modified, edit a deep copy
+	// and let the metadata update swap it in.
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var update policy.BucketPolicy
+	if err = json.Unmarshal(data, &update); err != nil {
+		return nil, err
+	}
+
+	report := anonLockdownReport{Bucket: bucket}
+	for i, stmt := range update.Statements {
+		if stmt.Effect != policy.Allow || !stmt.Principal.Match("*") {
+			continue
+		}
+		update.Statements[i].Effect = policy.Deny
+		report.Denied = append(report.Denied, describeAnonStatement(stmt))
+	}
+	if len(report.Denied) == 0 {
+		return nil, nil
+	}
+
+	configData, err := json.Marshal(&update)
+	if err != nil {
+		return nil, err
+	}
+	updatedAt, err := globalBucketMetadataSys.Update(ctx, bucket, bucketPolicyConfig, configData)
+	if err != nil {
+		return nil, err
+	}
+	report.UpdatedAt = updatedAt
+	return &report, nil
+}
+
+// AuditAnonymousAccessHandler - GET /minio/admin/v3/anonymous-access
+// Enumerates every bucket reachable without credentials and the policy
+// statements granting that access.
+func (a adminAPIHandlers) AuditAnonymousAccessHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	buckets, err := objectAPI.ListBuckets(ctx, BucketOptions{})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	reports := []anonBucketReport{}
+	for _, bucket := range buckets {
+		stmts := anonStatements(bucket.Name)
+		if len(stmts) == 0 {
+			continue
+		}
+		report := anonBucketReport{Bucket: bucket.Name}
+		for _, stmt := range stmts {
+			report.Statements = append(report.Statements, describeAnonStatement(stmt))
+		}
+		reports = append(reports, report)
+	}
+
+	buf, err := json.Marshal(reports)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
+
+// LockdownAnonymousAccessHandler - POST /minio/admin/v3/anonymous-access/lockdown
+// Flips every anonymous Allow statement to Deny, optionally scoped to
+// one bucket with ?bucket=, and reports what changed.
+func (a adminAPIHandlers) LockdownAnonymousAccessHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	var names []string
+	if bucket := r.Form.Get("bucket"); bucket != "" {
+		if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+			writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		names = []string{bucket}
+	} else {
+		buckets, err := objectAPI.ListBuckets(ctx, BucketOptions{})
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+		for _, bucket := range buckets {
+			names = append(names, bucket.Name)
+		}
+	}
+
+	reports := []anonLockdownReport{}
+	for _, name := range names {
+		report, err := lockdownBucketAnon(ctx, name)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+		if report == nil {
+			continue
+		}
+		reports = append(reports, *report)
+
+		auditLogInternal(ctx, AuditLogOptions{
+			Event:   "LockdownAnonymousAccess",
+			APIName: "LockdownAnonymousAccess",
+			Bucket:  name,
+			Status:  "success",
+		})
+	}
+
+	buf, err := json.Marshal(reports)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
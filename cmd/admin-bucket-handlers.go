@@ -56,7 +56,7 @@ const (
 func (a adminAPIHandlers) PutBucketQuotaConfigHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	objectAPI, _ := validateAdminReq(ctx, w, r, policy.SetBucketQuotaAdminAction)
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.SetBucketQuotaAdminAction)
 	if objectAPI == nil {
 		return
 	}
@@ -64,6 +64,11 @@ func (a adminAPIHandlers) PutBucketQuotaConfigHandler(w http.ResponseWriter, r *
 	vars := mux.Vars(r)
 	bucket := pathClean(vars["bucket"])
 
+	if !tenantAllowsEntity(cred, bucket) {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAccessDenied), r.URL)
+		return
+	}
+
 	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
 		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
 		return
@@ -81,6 +86,11 @@ func (a adminAPIHandlers) PutBucketQuotaConfigHandler(w http.ResponseWriter, r *
 		return
 	}
 
+	if _, err := parseBucketQuotaExt(bucket, data); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
 	updatedAt, err := globalBucketMetadataSys.Update(ctx, bucket, bucketQuotaConfigFile, data)
 	if err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
@@ -127,7 +137,26 @@ func (a adminAPIHandlers) GetBucketQuotaConfigHandler(w http.ResponseWriter, r *
 		return
 	}
 
-	configData, err := json.Marshal(config)
+	ext, err := globalBucketMetadataSys.GetQuotaConfigExt(ctx, bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	bui := globalBucketQuotaSys.GetBucketUsageInfo(ctx, bucket)
+	configData, err := json.Marshal(struct {
+		*madmin.BucketQuota
+		bucketQuotaExt
+		UsageSize          uint64 `json:"usagesize"`
+		UsageObjectsCount  uint64 `json:"usageobjectscount"`
+		UsageVersionsCount uint64 `json:"usageversionscount"`
+	}{
+		BucketQuota:        config,
+		bucketQuotaExt:     ext,
+		UsageSize:          bui.Size,
+		UsageObjectsCount:  bui.ObjectsCount,
+		UsageVersionsCount: bui.VersionsCount,
+	})
 	if err != nil {
 		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
 		return
@@ -0,0 +1,286 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Two-phase config changes. A config KV change is first staged, which
+// only validates it and reports how it would take effect, then either
+// committed or rolled back. A committed change can still be rolled back
+// to the configuration captured at commit time.
+const (
+	// stagedConfigTTL is how long an uncommitted staged change is kept
+	// before it expires.
+	stagedConfigTTL = 30 * time.Minute
+)
+
+// stagedConfig is one staged config KV change.
+type stagedConfig struct {
+	ID        string    `json:"id"`
+	SubSys    string    `json:"subSys"`
+	Dynamic   bool      `json:"dynamic"`
+	StagedAt  time.Time `json:"stagedAt"`
+	Committed bool      `json:"committed"`
+
+	kvBytes []byte
+	prevCfg config.Config // captured at commit, for rollback
+}
+
+var globalStagedConfigs = struct {
+	mu sync.Mutex
+	m  map[string]*stagedConfig
+}{m: make(map[string]*stagedConfig)}
+
+// stageConfigResult is the response of a stage request, reporting how
+// the change would take effect before anything is applied.
+type stageConfigResult struct {
+	ID       string    `json:"id"`
+	SubSys   string    `json:"subSys"`
+	Dynamic  bool      `json:"dynamic"`
+	Action   string    `json:"action"` // "reload" or "restart"
+	StagedAt time.Time `json:"stagedAt"`
+}
+
+// validateConfigKV validates the change against the current server
+// config without saving anything.
+func validateConfigKV(ctx context.Context, objectAPI ObjectLayer, kvBytes []byte) (subSys string, dynamic bool, err error) {
+	cfg, err := readServerConfig(ctx, objectAPI, nil)
+	if err != nil {
+		return "", false, err
+	}
+	dynamic, err = cfg.ReadConfig(bytes.NewReader(kvBytes))
+	if err != nil {
+		return "", false, err
+	}
+	subSys, _, _, err = config.GetSubSys(string(kvBytes))
+	if err != nil {
+		return "", false, err
+	}
+	tgts, err := config.ParseConfigTargetID(bytes.NewReader(kvBytes))
+	if err != nil {
+		return "", false, err
+	}
+	ctx = context.WithValue(ctx, config.ContextKeyForTargetFromConfig, tgts)
+	if verr := validateConfig(ctx, cfg, subSys); verr != nil {
+		return "", false, badConfigErr{Err: verr}
+	}
+	return subSys, dynamic, nil
+}
+
+// StageConfigKVHandler - validates and stages a config KV change
+// without applying it, reporting whether a commit would reload the
+// subsystem dynamically or require a restart.
+//
+// PUT /minio/admin/v3/stage-config-kv
+func (a adminAPIHandlers) StageConfigKVHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	if r.ContentLength > maxEConfigJSONSize || r.ContentLength == -1 {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminConfigTooLarge), r.URL)
+		return
+	}
+
+	password := cred.SecretKey
+	kvBytes, err := madmin.DecryptData(password, io.LimitReader(r.Body, r.ContentLength))
+	if err != nil {
+		adminLogIf(ctx, err)
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminConfigBadJSON), r.URL)
+		return
+	}
+
+	subSys, dynamic, err := validateConfigKV(ctx, objectAPI, kvBytes)
+	if err != nil {
+		switch err.(type) {
+		case badConfigErr:
+			writeCustomErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminConfigBadJSON), err.Error(), r.URL)
+		default:
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		}
+		return
+	}
+
+	sc := &stagedConfig{
+		ID:       mustGetUUID(),
+		SubSys:   subSys,
+		Dynamic:  dynamic,
+		StagedAt: time.Now().UTC(),
+		kvBytes:  kvBytes,
+	}
+
+	globalStagedConfigs.mu.Lock()
+	// Expire stale uncommitted entries while we are here.
+	for id, old := range globalStagedConfigs.m {
+		if !old.Committed && time.Since(old.StagedAt) > stagedConfigTTL {
+			delete(globalStagedConfigs.m, id)
+		}
+	}
+	globalStagedConfigs.m[sc.ID] = sc
+	globalStagedConfigs.mu.Unlock()
+
+	action := "restart"
+	if dynamic {
+		action = "reload"
+	}
+	data, err := json.Marshal(stageConfigResult{
+		ID:       sc.ID,
+		SubSys:   subSys,
+		Dynamic:  dynamic,
+		Action:   action,
+		StagedAt: sc.StagedAt,
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// CommitStagedConfigHandler - applies a previously staged config KV
+// change, keeping the prior configuration so the change can still be
+// rolled back.
+//
+// PUT /minio/admin/v3/commit-staged-config?id=...
+func (a adminAPIHandlers) CommitStagedConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	globalStagedConfigs.mu.Lock()
+	sc, ok := globalStagedConfigs.m[r.Form.Get("id")]
+	globalStagedConfigs.mu.Unlock()
+	if !ok || sc.Committed {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+
+	// Capture the current config for rollback before applying.
+	prevCfg, err := readServerConfig(ctx, objectAPI, nil)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	result, err := setConfigKV(ctx, objectAPI, sc.kvBytes)
+	if err != nil {
+		switch err.(type) {
+		case badConfigErr:
+			writeCustomErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminConfigBadJSON), err.Error(), r.URL)
+		default:
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		}
+		return
+	}
+
+	if result.Dynamic {
+		applyDynamic(ctx, objectAPI, result.Cfg, result.SubSys, r, w)
+		if result.LoggerWebhookCfgUpdated {
+			applyDynamic(ctx, objectAPI, result.Cfg, config.LoggerWebhookSubSys, r, w)
+		}
+	}
+
+	globalStagedConfigs.mu.Lock()
+	sc.Committed = true
+	sc.prevCfg = prevCfg
+	globalStagedConfigs.mu.Unlock()
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// RollbackStagedConfigHandler - discards an uncommitted staged change,
+// or restores the configuration captured when the change was committed.
+//
+// PUT /minio/admin/v3/rollback-staged-config?id=...
+func (a adminAPIHandlers) RollbackStagedConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	id := r.Form.Get("id")
+	globalStagedConfigs.mu.Lock()
+	sc, ok := globalStagedConfigs.m[id]
+	globalStagedConfigs.mu.Unlock()
+	if !ok {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+
+	if sc.Committed {
+		if err := saveServerConfig(ctx, objectAPI, sc.prevCfg); err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+		if sc.Dynamic {
+			applyDynamic(ctx, objectAPI, sc.prevCfg, sc.SubSys, r, w)
+		}
+	}
+
+	globalStagedConfigs.mu.Lock()
+	delete(globalStagedConfigs.m, id)
+	globalStagedConfigs.mu.Unlock()
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// ListStagedConfigHandler - lists the staged config changes.
+//
+// GET /minio/admin/v3/list-staged-config
+func (a adminAPIHandlers) ListStagedConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	globalStagedConfigs.mu.Lock()
+	staged := make([]*stagedConfig, 0, len(globalStagedConfigs.m))
+	for _, sc := range globalStagedConfigs.m {
+		staged = append(staged, sc)
+	}
+	globalStagedConfigs.mu.Unlock()
+
+	data, err := json.Marshal(staged)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
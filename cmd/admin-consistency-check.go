@@ -0,0 +1,317 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// consistencyDivergence describes one disagreement found between the
+// xl.meta copies of an object across the drives of its erasure set.
+type consistencyDivergence struct {
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+	Pool   int    `json:"pool"`
+	Set    int    `json:"set"`
+	Drive  string `json:"drive"`
+	Issue  string `json:"issue"`
+}
+
+// maxConsistencyDivergences caps the divergences retained in memory per
+// job, counters keep counting beyond the cap.
+const maxConsistencyDivergences = 1000
+
+// consistencyCheckStatus is the state of a consistency check job,
+// cross-checking xl.meta across the drives of each erasure set.
+type consistencyCheckStatus struct {
+	Bucket      string                  `json:"bucket"`
+	Prefix      string                  `json:"prefix,omitempty"`
+	Resolve     bool                    `json:"resolve"`
+	StartTime   time.Time               `json:"startTime"`
+	Scanned     uint64                  `json:"scanned"`
+	Divergent   uint64                  `json:"divergent"`
+	Healed      uint64                  `json:"healed"`
+	HealFailed  uint64                  `json:"healFailed"`
+	Divergences []consistencyDivergence `json:"divergences,omitempty"`
+	Complete    bool                    `json:"complete"`
+	Error       string                  `json:"error,omitempty"`
+	cancel      context.CancelFunc
+}
+
+// consistencyCheckState holds the single consistency check job allowed
+// to run at a time.
+type consistencyCheckState struct {
+	mu  sync.Mutex
+	job *consistencyCheckStatus
+}
+
+var globalConsistencyCheck = &consistencyCheckState{}
+
+var errConsistencyCheckRunning = errors.New("a consistency check is already in progress")
+
+func (s *consistencyCheckState) start(z *erasureServerPools, bucket, prefix string, resolve bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.job != nil && !s.job.Complete {
+		return errConsistencyCheckRunning
+	}
+
+	ctx, cancel := context.WithCancel(GlobalContext)
+	job := &consistencyCheckStatus{
+		Bucket:    bucket,
+		Prefix:    prefix,
+		Resolve:   resolve,
+		StartTime: time.Now().UTC(),
+		cancel:    cancel,
+	}
+	s.job = job
+
+	go func() {
+		err := z.checkConsistency(ctx, bucket, prefix, resolve, job)
+		s.mu.Lock()
+		job.Complete = true
+		if err != nil && !errors.Is(err, context.Canceled) {
+			job.Error = err.Error()
+		}
+		s.mu.Unlock()
+		cancel()
+	}()
+	return nil
+}
+
+func (s *consistencyCheckState) addDivergence(job *consistencyCheckStatus, d consistencyDivergence) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(job.Divergences) < maxConsistencyDivergences {
+		job.Divergences = append(job.Divergences, d)
+	}
+}
+
+// checkConsistency walks the bucket/prefix on every erasure set and
+// cross-checks the xl.meta copies of each object.
+func (z *erasureServerPools) checkConsistency(ctx context.Context, bucket, prefix string, resolve bool, job *consistencyCheckStatus) error {
+	for poolIdx, pool := range z.serverPools {
+		for setIdx, set := range pool.sets {
+			err := set.listObjectsToDecommission(ctx, decomBucketInfo{Name: bucket, Prefix: prefix},
+				func(entry metaCacheEntry) {
+					if entry.isDir() {
+						return
+					}
+					z.checkObjectConsistency(ctx, poolIdx, setIdx, set, bucket, entry.name, resolve, job)
+				})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// versionSignature condenses the identifying fields of one object
+// version for cross-drive comparison.
+func versionSignature(fi FileInfo) string {
+	vid := fi.VersionID
+	if vid == "" {
+		vid = nullVersionID
+	}
+	return fmt.Sprintf("%s/%d/%s/%s/%v", vid, fi.ModTime.UnixNano(), fi.Metadata["etag"], fi.DataDir, fi.Deleted)
+}
+
+// checkObjectConsistency reads xl.meta of one object from every drive
+// of its erasure set and reports drives that diverge from the version
+// set agreed on by the majority of drives.
+func (z *erasureServerPools) checkObjectConsistency(ctx context.Context, poolIdx, setIdx int, set *erasureObjects, bucket, object string, resolve bool, job *consistencyCheckStatus) {
+	disks := set.getDisks()
+
+	type driveVersions struct {
+		drive string
+		sigs  map[string]struct{}
+		err   error
+	}
+
+	perDrive := make([]driveVersions, 0, len(disks))
+	sigCount := make(map[string]int)
+	var online int
+	for _, disk := range disks {
+		if disk == nil || !disk.IsOnline() {
+			continue
+		}
+		online++
+		dv := driveVersions{drive: disk.String(), sigs: make(map[string]struct{})}
+		rf, err := disk.ReadXL(ctx, bucket, object, false)
+		if err != nil {
+			dv.err = err
+		} else if fivs, err := getFileInfoVersions(rf.Buf, bucket, object, false); err != nil {
+			dv.err = err
+		} else {
+			for _, fi := range fivs.Versions {
+				sig := versionSignature(fi)
+				dv.sigs[sig] = struct{}{}
+				sigCount[sig]++
+			}
+		}
+		perDrive = append(perDrive, dv)
+	}
+
+	globalConsistencyCheck.mu.Lock()
+	job.Scanned++
+	globalConsistencyCheck.mu.Unlock()
+
+	if online == 0 {
+		return
+	}
+
+	// The reference version set is whatever more than half the online
+	// drives agree on, drives missing reference versions or carrying
+	// extra versions diverge.
+	quorum := online/2 + 1
+	var divergent bool
+	for _, dv := range perDrive {
+		var issues []string
+		if dv.err != nil {
+			if errors.Is(dv.err, errFileNotFound) || errors.Is(dv.err, errPathNotFound) {
+				issues = append(issues, "xl.meta missing")
+			} else {
+				issues = append(issues, fmt.Sprintf("xl.meta unreadable: %v", dv.err))
+			}
+		} else {
+			for sig, n := range sigCount {
+				if n < quorum {
+					continue
+				}
+				if _, ok := dv.sigs[sig]; !ok {
+					issues = append(issues, fmt.Sprintf("missing quorum version %s", sig))
+				}
+			}
+			for sig := range dv.sigs {
+				if sigCount[sig] < quorum {
+					issues = append(issues, fmt.Sprintf("stray version %s only on %d of %d drives", sig, sigCount[sig], online))
+				}
+			}
+		}
+		for _, issue := range issues {
+			divergent = true
+			globalConsistencyCheck.addDivergence(job, consistencyDivergence{
+				Bucket: bucket,
+				Object: decodeDirObject(object),
+				Pool:   poolIdx,
+				Set:    setIdx,
+				Drive:  dv.drive,
+				Issue:  issue,
+			})
+		}
+	}
+
+	if !divergent {
+		return
+	}
+
+	globalConsistencyCheck.mu.Lock()
+	job.Divergent++
+	globalConsistencyCheck.mu.Unlock()
+
+	if !resolve {
+		return
+	}
+
+	// Auto-resolve by quorum via the regular heal path, healing all
+	// versions of the object.
+	_, err := z.HealObject(ctx, bucket, decodeDirObject(object), "", madmin.HealOpts{
+		ScanMode: madmin.HealNormalScan,
+		Remove:   healDeleteDangling,
+	})
+	globalConsistencyCheck.mu.Lock()
+	if err != nil {
+		job.HealFailed++
+	} else {
+		job.Healed++
+	}
+	globalConsistencyCheck.mu.Unlock()
+}
+
+// StartConsistencyCheckHandler - starts a job cross-checking xl.meta
+// across erasure set drives for a bucket/prefix, optionally resolving
+// divergences by quorum via heal.
+func (a adminAPIHandlers) StartConsistencyCheckHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	if bucket == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	if _, err := z.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	prefix := r.Form.Get("prefix")
+	resolve := r.Form.Get("resolve") == "true"
+
+	if err := globalConsistencyCheck.start(z, bucket, prefix, resolve); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// ConsistencyCheckStatusHandler - returns progress and divergences of
+// the running or last finished consistency check.
+func (a adminAPIHandlers) ConsistencyCheckStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	globalConsistencyCheck.mu.Lock()
+	statusData, err := json.Marshal(globalConsistencyCheck.job)
+	globalConsistencyCheck.mu.Unlock()
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, statusData)
+}
@@ -0,0 +1,332 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	objectlock "github.com/minio/minio/internal/bucket/object/lock"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Admin API for the governance bypass dual control workflow, see
+// bucket-object-lock-dual.go. The second approver lists the pending
+// requests, then approves or rejects individual ones; approval is only
+// valid when the approving credential differs from the requesting one.
+
+// ListGovernanceBypassRequests - GET /minio/admin/v3/governance-bypass/requests
+// Lists pending and approved-but-unconsumed governance bypass delete
+// requests.
+func (a adminAPIHandlers) ListGovernanceBypassRequests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ForceUnlockAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	if err := objectAPI.Walk(ctx, minioMetaBucket, govBypassApprovalPrefix, results, WalkOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	approvals := []govBypassApproval{}
+	for res := range results {
+		if res.Err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, res.Err), r.URL)
+			return
+		}
+		data, err := readConfig(ctx, objectAPI, res.Item.Name)
+		if err != nil {
+			adminLogIf(ctx, err)
+			continue
+		}
+		var approval govBypassApproval
+		if err := json.Unmarshal(data, &approval); err != nil {
+			adminLogIf(ctx, err)
+			continue
+		}
+		if approval.expired() {
+			continue
+		}
+		approvals = append(approvals, approval)
+	}
+
+	buf, err := json.Marshal(approvals)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
+
+// ApproveGovernanceBypassRequest - POST /minio/admin/v3/governance-bypass/approve?bucket=&object=&versionId=
+// Records the second approval for a pending governance bypass delete.
+// The approving credential must differ from the requesting one.
+func (a adminAPIHandlers) ApproveGovernanceBypassRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ForceUnlockAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	object := r.Form.Get("object")
+	versionID := r.Form.Get("versionId")
+
+	approval, err := loadGovBypassApproval(ctx, objectAPI, bucket, object, versionID)
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminNoSuchGovernanceBypassRequest), r.URL)
+			return
+		}
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if approval.expired() {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminNoSuchGovernanceBypassRequest), r.URL)
+		return
+	}
+
+	// Four-eyes: the approver must be a different credential than the
+	// requester.
+	if approval.RequestedBy == cred.AccessKey {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminGovernanceSelfApproval), r.URL)
+		return
+	}
+
+	approval.Approved = true
+	approval.ApprovedBy = cred.AccessKey
+	approval.ApprovedAt = UTCNow()
+	if err := saveGovBypassApproval(ctx, objectAPI, approval); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:     "GovernanceBypassApproved",
+		APIName:   "ApproveGovernanceBypassRequest",
+		Bucket:    bucket,
+		Object:    object,
+		VersionID: versionID,
+		Tags: map[string]string{
+			"requestedBy": approval.RequestedBy,
+			"approvedBy":  approval.ApprovedBy,
+		},
+	})
+
+	buf, err := json.Marshal(approval)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
+
+// RejectGovernanceBypassRequest - DELETE /minio/admin/v3/governance-bypass/request?bucket=&object=&versionId=
+// Rejects a pending governance bypass delete request, or revokes an
+// unconsumed approval.
+func (a adminAPIHandlers) RejectGovernanceBypassRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ForceUnlockAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	object := r.Form.Get("object")
+	versionID := r.Form.Get("versionId")
+
+	approval, err := loadGovBypassApproval(ctx, objectAPI, bucket, object, versionID)
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminNoSuchGovernanceBypassRequest), r.URL)
+			return
+		}
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if err := deleteGovBypassApproval(ctx, objectAPI, bucket, object, versionID); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:     "GovernanceBypassRejected",
+		APIName:   "RejectGovernanceBypassRequest",
+		Bucket:    bucket,
+		Object:    object,
+		VersionID: versionID,
+		Tags: map[string]string{
+			"requestedBy": approval.RequestedBy,
+			"rejectedBy":  cred.AccessKey,
+		},
+	})
+
+	writeSuccessNoContent(w)
+}
+
+// objectLockModeSummary aggregates the versions held under one
+// retention mode.
+type objectLockModeSummary struct {
+	Versions            int64     `json:"versions"`
+	Expired             int64     `json:"expired"` // retain-until date already past
+	EarliestRetainUntil time.Time `json:"earliestRetainUntil,omitempty"`
+	LatestRetainUntil   time.Time `json:"latestRetainUntil,omitempty"`
+}
+
+// observe folds one version's retain-until date into the summary.
+func (s *objectLockModeSummary) observe(retainUntil, now time.Time) {
+	s.Versions++
+	if retainUntil.Before(now) {
+		s.Expired++
+	}
+	if s.EarliestRetainUntil.IsZero() || retainUntil.Before(s.EarliestRetainUntil) {
+		s.EarliestRetainUntil = retainUntil
+	}
+	if retainUntil.After(s.LatestRetainUntil) {
+		s.LatestRetainUntil = retainUntil
+	}
+}
+
+// objectLockComplianceReport is the retention coverage report of one
+// bucket, the shape auditors consume.
+type objectLockComplianceReport struct {
+	Bucket             string                `json:"bucket"`
+	Prefix             string                `json:"prefix,omitempty"`
+	GeneratedAt        time.Time             `json:"generatedAt"`
+	DefaultMode        string                `json:"defaultRetentionMode,omitempty"`
+	TotalVersions      int64                 `json:"totalVersions"`
+	DeleteMarkers      int64                 `json:"deleteMarkers"`
+	Governance         objectLockModeSummary `json:"governance"`
+	Compliance         objectLockModeSummary `json:"compliance"`
+	LegalHolds         int64                 `json:"legalHolds"`
+	Unprotected        int64                 `json:"unprotected"` // versions with neither retention nor legal hold
+	UnprotectedSamples []string              `json:"unprotectedSamples,omitempty"`
+}
+
+// maxUnprotectedSamples bounds how many unprotected versions the
+// report names explicitly.
+const maxUnprotectedSamples = 100
+
+// ObjectLockComplianceReport - GET /minio/admin/v3/object-lock/compliance-report?bucket=&prefix=
+// Walks every version in an Object Lock enabled bucket and reports its
+// retention coverage: versions per retention mode with earliest and
+// latest retain-until dates, active legal holds, and versions carrying
+// neither retention nor a legal hold.
+func (a adminAPIHandlers) ObjectLockComplianceReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	prefix := r.Form.Get("prefix")
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	// The report only makes sense on Object Lock enabled buckets.
+	cfg, _, err := globalBucketMetadataSys.GetObjectLockConfig(bucket)
+	if err != nil {
+		if errors.Is(err, BucketObjectLockConfigNotFound{Bucket: bucket}) {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNoSuchObjectLockConfiguration), r.URL)
+			return
+		}
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	report := objectLockComplianceReport{
+		Bucket:      bucket,
+		Prefix:      prefix,
+		GeneratedAt: UTCNow(),
+	}
+	if cfg.Rule != nil && cfg.Rule.DefaultRetention.Mode.Valid() {
+		report.DefaultMode = string(cfg.Rule.DefaultRetention.Mode)
+	}
+
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	if err := objectAPI.Walk(ctx, bucket, prefix, results, WalkOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	now := UTCNow()
+	for res := range results {
+		if res.Err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, res.Err), r.URL)
+			return
+		}
+		oi := res.Item
+		report.TotalVersions++
+		if oi.DeleteMarker {
+			// Delete markers carry no data and cannot be locked.
+			report.DeleteMarkers++
+			continue
+		}
+
+		held := false
+		lhold := objectlock.GetObjectLegalHoldMeta(oi.UserDefined)
+		if lhold.Status.Valid() && lhold.Status == objectlock.LegalHoldOn {
+			report.LegalHolds++
+			held = true
+		}
+
+		ret := objectlock.GetObjectRetentionMeta(oi.UserDefined)
+		if ret.Mode.Valid() {
+			switch ret.Mode {
+			case objectlock.RetGovernance:
+				report.Governance.observe(ret.RetainUntilDate.Time, now)
+			case objectlock.RetCompliance:
+				report.Compliance.observe(ret.RetainUntilDate.Time, now)
+			}
+			continue
+		}
+		if held {
+			continue
+		}
+
+		report.Unprotected++
+		if len(report.UnprotectedSamples) < maxUnprotectedSamples {
+			sample := oi.Name
+			if oi.VersionID != "" {
+				sample += " (versionId=" + oi.VersionID + ")"
+			}
+			report.UnprotectedSamples = append(report.UnprotectedSamples, sample)
+		}
+	}
+
+	buf, err := json.Marshal(report)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// healStreamPollInterval is how often the heal stream checks a heal
+// sequence for newly accumulated result items.
+const healStreamPollInterval = time.Second
+
+// popHealItems drains accumulated heal result items for streaming
+// delivery. Items are cleared like the poll heal-status API does, so a
+// connected stream keeps the heal sequence from pausing on
+// maxUnconsumedHealResultItems.
+func (h *healSequence) popHealItems() (items []madmin.HealResultItem, summary healStatusSummary, detail string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	items = h.currentStatus.Items
+	if n := len(items); n > 0 {
+		h.lastSentResultIndex = items[n-1].ResultIndex
+	}
+	h.currentStatus.Items = nil
+	return items, h.currentStatus.Summary, h.currentStatus.FailureDetail
+}
+
+// healStreamFinalStatus is the terminating line of a heal stream,
+// distinguishable from heal result items by its Summary field.
+type healStreamFinalStatus struct {
+	Summary healStatusSummary `json:"Summary"`
+	Detail  string            `json:"Detail,omitempty"`
+}
+
+// HealStreamHandler - streams heal result items of a running heal
+// sequence as newline separated JSON, one heal result item per line,
+// terminated by a line carrying the final heal summary. This avoids
+// client side polling of the heal-status API on long running heals.
+//
+// GET /minio/admin/v3/heal-stream/{bucket}/{prefix}?clientToken={token}
+func (a adminAPIHandlers) HealStreamHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	healPath := pathJoin(vars["bucket"], vars["prefix"])
+
+	h, exists := globalAllHealState.getHealSequence(healPath)
+	if !exists {
+		// Heal sequence doesn't exist, must have finished already.
+		finalData, err := json.Marshal(healStreamFinalStatus{Summary: healFinishedStatus})
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+		writeSuccessResponseJSON(w, finalData)
+		return
+	}
+
+	if clientToken := r.Form.Get("clientToken"); clientToken != h.clientToken {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrHealInvalidClientToken), r.URL)
+		return
+	}
+
+	setEventStreamHeaders(w)
+
+	enc := json.NewEncoder(w)
+	ticker := time.NewTicker(healStreamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		items, summary, detail := h.popHealItems()
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return
+			}
+		}
+		if len(items) > 0 {
+			w.(http.Flusher).Flush()
+		}
+
+		if summary == healFinishedStatus || summary == healStoppedStatus {
+			if err := enc.Encode(healStreamFinalStatus{Summary: summary, Detail: detail}); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/minio/internal/kms"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Composite health score thresholds and penalties. The score starts at
+// 100 and each contributor subtracts its penalty, the result is clamped
+// to [0, 100].
+const (
+	healthScoreHealthy  = 90
+	healthScoreDegraded = 60
+
+	healthPenaltyWriteQuorum   = 60
+	healthPenaltyReadQuorum    = 80
+	healthPenaltyPerHealDrive  = 5
+	healthPenaltyHealDriveCap  = 20
+	healthPenaltyPerOffline    = 10
+	healthPenaltyOfflineCap    = 30
+	healthPenaltySpaceSkew     = 10
+	healthPenaltyReplBacklog   = 10
+	healthPenaltyKMSUnreach    = 40
+	healthSpaceSkewThreshold   = 0.2
+	healthReplBacklogThreshold = 1000
+)
+
+// healthScoreContributor is one concrete cause subtracting from the
+// composite score.
+type healthScoreContributor struct {
+	Component string `json:"component"`
+	Severity  string `json:"severity"` // "warning" or "critical"
+	Detail    string `json:"detail"`
+	Penalty   int    `json:"penalty"`
+}
+
+// healthScoreReport is the response of the health score API.
+type healthScoreReport struct {
+	Score        int                      `json:"score"`
+	Status       string                   `json:"status"` // "healthy", "degraded" or "critical"
+	Time         time.Time                `json:"time"`
+	Contributors []healthScoreContributor `json:"contributors,omitempty"`
+}
+
+func (r *healthScoreReport) add(component, severity, detail string, penalty int) {
+	r.Contributors = append(r.Contributors, healthScoreContributor{
+		Component: component,
+		Severity:  severity,
+		Detail:    detail,
+		Penalty:   penalty,
+	})
+	r.Score -= penalty
+}
+
+// computeHealthScore builds the composite health score from quorum
+// status, drive states, heal backlog, replication lag, free-space skew
+// and KMS reachability.
+func computeHealthScore(ctx context.Context, objectAPI ObjectLayer) healthScoreReport {
+	report := healthScoreReport{
+		Score: 100,
+		Time:  time.Now().UTC(),
+	}
+
+	result := objectAPI.Health(ctx, HealthOptions{NoLogging: true})
+	if !result.HealthyRead {
+		report.add("read-quorum", "critical",
+			fmt.Sprintf("one or more erasure sets have lost read quorum (read quorum %d)", result.ReadQuorum),
+			healthPenaltyReadQuorum)
+	} else if !result.Healthy {
+		report.add("write-quorum", "critical",
+			fmt.Sprintf("one or more erasure sets have lost write quorum (write quorum %d)", result.WriteQuorum),
+			healthPenaltyWriteQuorum)
+	}
+
+	if result.HealingDrives > 0 {
+		penalty := result.HealingDrives * healthPenaltyPerHealDrive
+		if penalty > healthPenaltyHealDriveCap {
+			penalty = healthPenaltyHealDriveCap
+		}
+		report.add("heal-backlog", "warning",
+			fmt.Sprintf("%d drive(s) are healing", result.HealingDrives), penalty)
+	}
+
+	storageInfo := objectAPI.StorageInfo(ctx, true)
+	var offline int
+	minUsage, maxUsage := 1.0, 0.0
+	for _, disk := range storageInfo.Disks {
+		if disk.State != madmin.DriveStateOk {
+			offline++
+			continue
+		}
+		if disk.TotalSpace > 0 {
+			usage := float64(disk.UsedSpace) / float64(disk.TotalSpace)
+			if usage < minUsage {
+				minUsage = usage
+			}
+			if usage > maxUsage {
+				maxUsage = usage
+			}
+		}
+	}
+	if offline > 0 {
+		penalty := offline * healthPenaltyPerOffline
+		if penalty > healthPenaltyOfflineCap {
+			penalty = healthPenaltyOfflineCap
+		}
+		report.add("drives", "critical",
+			fmt.Sprintf("%d drive(s) are offline or faulty", offline), penalty)
+	}
+	if maxUsage > minUsage && maxUsage-minUsage > healthSpaceSkewThreshold {
+		report.add("free-space-skew", "warning",
+			fmt.Sprintf("drive usage differs by %.0f%% between fullest and emptiest drive", (maxUsage-minUsage)*100),
+			healthPenaltySpaceSkew)
+	}
+
+	if stats := globalReplicationStats.Load(); stats != nil {
+		var queued float64
+		for _, bs := range stats.GetAll() {
+			queued += bs.QStat.Curr.Count
+		}
+		if queued > healthReplBacklogThreshold {
+			report.add("replication-lag", "warning",
+				fmt.Sprintf("%.0f object(s) queued for replication", queued),
+				healthPenaltyReplBacklog)
+		}
+	}
+
+	if GlobalKMS != nil {
+		kctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		_, err := GlobalKMS.GenerateKey(kctx, &kms.GenerateKeyRequest{AssociatedData: kms.Context{"healthcheck": ""}})
+		cancel()
+		if err != nil {
+			report.add("kms", "critical",
+				fmt.Sprintf("KMS is not reachable: %v", err), healthPenaltyKMSUnreach)
+		}
+	}
+
+	if report.Score < 0 {
+		report.Score = 0
+	}
+	switch {
+	case report.Score >= healthScoreHealthy:
+		report.Status = "healthy"
+	case report.Score >= healthScoreDegraded:
+		report.Status = "degraded"
+	default:
+		report.Status = "critical"
+	}
+	return report
+}
+
+// HealthScoreHandler - returns a composite cluster health score with
+// the concrete contributors, suitable for load balancers and
+// dashboards.
+//
+// GET /minio/admin/v3/health-score
+func (a adminAPIHandlers) HealthScoreHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealthInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	data, err := json.Marshal(computeHealthScore(ctx, objectAPI))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/minio/internal/dsync"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Lock dependency graph and forced expiry by resource pattern, for
+// recovering from stuck distributed locks without restarting nodes.
+
+// lockGraphNode is one lock in the dependency graph.
+type lockGraphNode struct {
+	ID       string        `json:"id"`
+	Resource string        `json:"resource"`
+	Type     string        `json:"type"`
+	Owner    string        `json:"owner"`
+	Source   string        `json:"source"`
+	Elapsed  time.Duration `json:"elapsed"`
+	Servers  []string      `json:"servers"`
+	Quorum   int           `json:"quorum"`
+	Held     bool          `json:"held"` // quorum of servers confirm the lock
+}
+
+// lockGraphEdge records that the lock 'from' was requested after, and
+// therefore waits on, the lock 'to' on the same resource. Edges are
+// inferred from request order - read locks on the same resource are
+// concurrent and produce no edges among themselves.
+type lockGraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Resource string `json:"resource"`
+}
+
+// lockGraph is the response of the lock graph endpoint.
+type lockGraph struct {
+	Nodes []lockGraphNode `json:"nodes"`
+	Edges []lockGraphEdge `json:"edges"`
+}
+
+// buildLockGraph converts the collected lock entries into nodes and
+// dependency edges.
+func buildLockGraph(entries madmin.LockEntries) lockGraph {
+	graph := lockGraph{Nodes: []lockGraphNode{}, Edges: []lockGraphEdge{}}
+
+	byResource := make(map[string]madmin.LockEntries)
+	for _, entry := range entries {
+		graph.Nodes = append(graph.Nodes, lockGraphNode{
+			ID:       entry.ID,
+			Resource: entry.Resource,
+			Type:     entry.Type,
+			Owner:    entry.Owner,
+			Source:   entry.Source,
+			Elapsed:  entry.Elapsed,
+			Servers:  entry.ServerList,
+			Quorum:   entry.Quorum,
+			Held:     len(entry.ServerList) >= entry.Quorum,
+		})
+		byResource[entry.Resource] = append(byResource[entry.Resource], entry)
+	}
+
+	for resource, locks := range byResource {
+		if len(locks) < 2 {
+			continue
+		}
+		sort.Slice(locks, func(i, j int) bool {
+			return locks[i].Timestamp.Before(locks[j].Timestamp)
+		})
+		for i := 1; i < len(locks); i++ {
+			for j := 0; j < i; j++ {
+				// Later locks wait on every earlier lock unless both
+				// are read locks, which share the resource.
+				if locks[i].Type == "READ" && locks[j].Type == "READ" {
+					continue
+				}
+				graph.Edges = append(graph.Edges, lockGraphEdge{
+					From:     locks[i].ID,
+					To:       locks[j].ID,
+					Resource: resource,
+				})
+			}
+		}
+	}
+	return graph
+}
+
+// LockGraphHandler - returns the locks currently in use as a dependency
+// graph of who waits on whom, with elapsed times.
+//
+// GET /minio/admin/v3/locks/graph
+func (a adminAPIHandlers) LockGraphHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.TopLocksAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	peerLocks := globalNotificationSys.GetLocks(ctx, r)
+	entries := topLockEntries(peerLocks, true)
+
+	data, err := json.Marshal(buildLockGraph(entries))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// ForceExpireLocksHandler - force-expires all locks whose resource
+// matches the given bucket and optional prefix.
+//
+// POST /minio/admin/v3/locks/force-expire?bucket=...&prefix=...
+func (a adminAPIHandlers) ForceExpireLocksHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ForceUnlockAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	if bucket == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	pattern := pathJoin(bucket, r.Form.Get("prefix"))
+
+	peerLocks := globalNotificationSys.GetLocks(ctx, r)
+	entries := topLockEntries(peerLocks, true)
+
+	var args dsync.LockArgs
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Resource, pattern) {
+			args.Resources = append(args.Resources, entry.Resource)
+		}
+	}
+
+	if len(args.Resources) > 0 {
+		var lockers []dsync.NetLocker
+		for _, lks := range z.serverPools[0].erasureLockers {
+			lockers = append(lockers, lks...)
+		}
+		for _, locker := range lockers {
+			locker.ForceUnlock(ctx, args)
+		}
+	}
+
+	data, err := json.Marshal(struct {
+		Expired int `json:"expired"`
+	}{Expired: len(args.Resources)})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
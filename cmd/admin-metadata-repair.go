@@ -0,0 +1,289 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Supported repair of specific xl.meta inconsistencies per object -
+// missing version IDs, duplicate versions and part sizes that disagree
+// with the version size - replacing the offline debug tool for these
+// cases. The default is a dry run that only reports the changes each
+// drive would see; actual repairs are audit logged.
+
+// Problems the repair tool recognizes.
+const (
+	xlMetaProblemMissingVersionID = "missing-version-id"
+	xlMetaProblemDuplicateVersion = "duplicate-version"
+	xlMetaProblemPartSizeMismatch = "part-size-mismatch"
+)
+
+// xlMetaRepairChange is one planned or applied change on one drive.
+type xlMetaRepairChange struct {
+	Drive     string `json:"drive"`
+	VersionID string `json:"versionId"`
+	Problem   string `json:"problem"`
+	Action    string `json:"action"`
+}
+
+// xlMetaRepairResult is the response of the repair endpoint.
+type xlMetaRepairResult struct {
+	Bucket  string               `json:"bucket"`
+	Object  string               `json:"object"`
+	DryRun  bool                 `json:"dryRun"`
+	Changes []xlMetaRepairChange `json:"changes"`
+}
+
+// nullVersionKey identifies one surplus null version across drives by
+// its modification time and its occurrence among surplus null versions
+// sharing that time.
+type nullVersionKey struct {
+	modTime int64
+	n       int
+}
+
+// repairXLMeta inspects the parsed xl.meta and fixes what it
+// recognizes, returning one change record per fix. assignVID hands out
+// the replacement version IDs and is shared across all drives of the
+// object, so the same surplus null version receives the same ID
+// everywhere instead of fresh per-drive divergence.
+func repairXLMeta(x *xlMetaV2, drive string, assignVID func(nullVersionKey) [16]byte) ([]xlMetaRepairChange, error) {
+	var changes []xlMetaRepairChange
+
+	// Assign fresh version IDs to null versions beyond the newest one;
+	// a single null version is legitimate, several mean version IDs
+	// were lost.
+	var nullSeen bool
+	nthByModTime := make(map[int64]int)
+	for i := range x.versions {
+		if x.versions[i].header.VersionID != [16]byte{} {
+			continue
+		}
+		if !nullSeen {
+			nullSeen = true
+			continue
+		}
+		ver, err := x.getIdx(i)
+		if err != nil {
+			return nil, err
+		}
+		modTime := x.versions[i].header.ModTime
+		key := nullVersionKey{modTime: modTime, n: nthByModTime[modTime]}
+		nthByModTime[modTime]++
+		vid := assignVID(key)
+		switch {
+		case ver.ObjectV2 != nil:
+			ver.ObjectV2.VersionID = vid
+		case ver.DeleteMarker != nil:
+			ver.DeleteMarker.VersionID = vid
+		default:
+			continue
+		}
+		if err = x.setIdx(i, *ver); err != nil {
+			return nil, err
+		}
+		changes = append(changes, xlMetaRepairChange{
+			Drive:     drive,
+			VersionID: uuid.UUID(vid).String(),
+			Problem:   xlMetaProblemMissingVersionID,
+			Action:    "assigned new version id",
+		})
+	}
+
+	// Drop duplicate versions, keeping the first (newest) occurrence.
+	seen := make(map[[16]byte]bool, len(x.versions))
+	for i := 0; i < len(x.versions); i++ {
+		vid := x.versions[i].header.VersionID
+		if !seen[vid] {
+			seen[vid] = true
+			continue
+		}
+		changes = append(changes, xlMetaRepairChange{
+			Drive:     drive,
+			VersionID: uuid.UUID(vid).String(),
+			Problem:   xlMetaProblemDuplicateVersion,
+			Action:    "removed duplicate version",
+		})
+		x.versions = append(x.versions[:i], x.versions[i+1:]...)
+		i--
+	}
+
+	// Fix version sizes that disagree with the sum of the part sizes.
+	for i := range x.versions {
+		if x.versions[i].header.Type != ObjectType {
+			continue
+		}
+		ver, err := x.getIdx(i)
+		if err != nil {
+			return nil, err
+		}
+		if ver.ObjectV2 == nil || len(ver.ObjectV2.PartSizes) == 0 {
+			continue
+		}
+		var sum int64
+		for _, sz := range ver.ObjectV2.PartSizes {
+			sum += sz
+		}
+		if sum == ver.ObjectV2.Size {
+			continue
+		}
+		ver.ObjectV2.Size = sum
+		if err = x.setIdx(i, *ver); err != nil {
+			return nil, err
+		}
+		changes = append(changes, xlMetaRepairChange{
+			Drive:     drive,
+			VersionID: uuid.UUID(ver.ObjectV2.VersionID).String(),
+			Problem:   xlMetaProblemPartSizeMismatch,
+			Action:    fmt.Sprintf("size corrected to %d", sum),
+		})
+	}
+
+	return changes, nil
+}
+
+// repairObjectMetadata runs the repair on every drive holding the
+// object, writing back repaired metadata unless dryRun is set.
+func repairObjectMetadata(ctx context.Context, z *erasureServerPools, bucket, object string, dryRun bool) ([]xlMetaRepairChange, error) {
+	var changes []xlMetaRepairChange
+	var found bool
+
+	// Replacement version IDs are generated once per object and reused
+	// on every drive, keyed by the surplus version's modification time.
+	assigned := make(map[nullVersionKey][16]byte)
+	assignVID := func(key nullVersionKey) [16]byte {
+		vid, ok := assigned[key]
+		if !ok {
+			vid = [16]byte(uuid.New())
+			assigned[key] = vid
+		}
+		return vid
+	}
+	for _, pool := range z.serverPools {
+		set := pool.getHashedSet(object)
+		for _, disk := range set.getDisks() {
+			if disk == nil {
+				continue
+			}
+			rfi, err := disk.ReadXL(ctx, bucket, object, false)
+			if err != nil {
+				if errors.Is(err, errFileNotFound) || errors.Is(err, errVolumeNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			found = true
+
+			var x xlMetaV2
+			if err = x.LoadOrConvert(rfi.Buf); err != nil {
+				return nil, fmt.Errorf("drive %s: %w", disk.String(), err)
+			}
+
+			diskChanges, err := repairXLMeta(&x, disk.String(), assignVID)
+			if err != nil {
+				return nil, fmt.Errorf("drive %s: %w", disk.String(), err)
+			}
+			if len(diskChanges) == 0 {
+				continue
+			}
+			changes = append(changes, diskChanges...)
+
+			if dryRun {
+				continue
+			}
+			buf, err := x.AppendTo(nil)
+			if err != nil {
+				return nil, fmt.Errorf("drive %s: %w", disk.String(), err)
+			}
+			if err = disk.WriteAll(ctx, bucket, pathJoin(object, xlStorageFormatFile), buf); err != nil {
+				return nil, fmt.Errorf("drive %s: %w", disk.String(), err)
+			}
+		}
+	}
+	if !found {
+		return nil, ObjectNotFound{Bucket: bucket, Object: object}
+	}
+	return changes, nil
+}
+
+// RepairObjectMetadataHandler - inspects and repairs xl.meta
+// inconsistencies of one object.
+//
+// POST /minio/admin/v3/repair-metadata?bucket=...&object=...&dry-run=...
+func (a adminAPIHandlers) RepairObjectMetadataHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	object := trimLeadingSlash(r.Form.Get("object"))
+	if bucket == "" || object == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	// Repairs are applied only when explicitly asked for.
+	dryRun := r.Form.Get("dry-run") != "false"
+
+	changes, err := repairObjectMetadata(ctx, z, bucket, object, dryRun)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	if !dryRun {
+		auditLogInternal(ctx, AuditLogOptions{
+			Event:   "metadata-repair",
+			APIName: "RepairObjectMetadata",
+			Bucket:  bucket,
+			Object:  object,
+			Tags: map[string]string{
+				"changes": strconv.Itoa(len(changes)),
+			},
+		})
+	}
+
+	data, err := json.Marshal(xlMetaRepairResult{
+		Bucket:  bucket,
+		Object:  object,
+		DryRun:  dryRun,
+		Changes: changes,
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
@@ -175,6 +175,9 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 			adminRouter.Methods(http.MethodPost).Path(adminVersion + "/heal/").HandlerFunc(adminMiddleware(adminAPI.HealHandler, traceAllFlag))
 			adminRouter.Methods(http.MethodPost).Path(adminVersion + "/heal/{bucket}").HandlerFunc(adminMiddleware(adminAPI.HealHandler, traceAllFlag))
 			adminRouter.Methods(http.MethodPost).Path(adminVersion + "/heal/{bucket}/{prefix:.*}").HandlerFunc(adminMiddleware(adminAPI.HealHandler, traceAllFlag))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/heal-stream/").HandlerFunc(adminMiddleware(adminAPI.HealStreamHandler, traceAllFlag))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/heal-stream/{bucket}").HandlerFunc(adminMiddleware(adminAPI.HealStreamHandler, traceAllFlag))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/heal-stream/{bucket}/{prefix:.*}").HandlerFunc(adminMiddleware(adminAPI.HealStreamHandler, traceAllFlag))
 			adminRouter.Methods(http.MethodPost).Path(adminVersion + "/background-heal/status").HandlerFunc(adminMiddleware(adminAPI.BackgroundHealStatusHandler, traceAllFlag))
 
 			// Pool operations
@@ -184,10 +187,16 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 			adminRouter.Methods(http.MethodPost).Path(adminVersion+"/pools/decommission").HandlerFunc(adminMiddleware(adminAPI.StartDecommission, traceAllFlag)).Queries("pool", "{pool:.*}")
 			adminRouter.Methods(http.MethodPost).Path(adminVersion+"/pools/cancel").HandlerFunc(adminMiddleware(adminAPI.CancelDecommission, traceAllFlag)).Queries("pool", "{pool:.*}")
 
+			adminRouter.Methods(http.MethodPost).Path(adminVersion+"/pools/decommission-set").HandlerFunc(adminMiddleware(adminAPI.StartDecommissionSetHandler, traceAllFlag)).Queries("pool", "{pool:.*}", "set", "{set:.*}")
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/pools/decommission-set-status").HandlerFunc(adminMiddleware(adminAPI.DecommissionSetStatusHandler, traceAllFlag))
+
 			// Rebalance operations
 			adminRouter.Methods(http.MethodPost).Path(adminVersion + "/rebalance/start").HandlerFunc(adminMiddleware(adminAPI.RebalanceStart, traceAllFlag))
 			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/rebalance/status").HandlerFunc(adminMiddleware(adminAPI.RebalanceStatus, traceAllFlag))
 			adminRouter.Methods(http.MethodPost).Path(adminVersion + "/rebalance/stop").HandlerFunc(adminMiddleware(adminAPI.RebalanceStop, traceAllFlag))
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/rebalance/progress").HandlerFunc(adminMiddleware(adminAPI.RebalanceProgressHandler, traceAllFlag))
+			adminRouter.Methods(http.MethodPost).Path(adminVersion+"/rebalance/throttle").HandlerFunc(
+				adminMiddleware(adminAPI.RebalanceThrottleHandler, traceAllFlag)).Queries("pool", "{pool:.*}")
 		}
 
 		// Profiling operations - deprecated API
@@ -196,12 +205,20 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/profiling/download").HandlerFunc(adminMiddleware(adminAPI.DownloadProfilingHandler, traceHdrsS3HFlag, noObjLayerFlag))
 		// Profiling operations
 		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/profile").HandlerFunc(adminMiddleware(adminAPI.ProfileHandler, traceHdrsS3HFlag, noObjLayerFlag))
+		// Continuous profiling ring buffer download
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/continuous-profiling/download").HandlerFunc(adminMiddleware(adminAPI.DownloadContinuousProfilesHandler, traceHdrsS3HFlag, noObjLayerFlag))
 
 		// Config KV operations.
 		if enableConfigOps {
 			adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-config-kv").HandlerFunc(adminMiddleware(adminAPI.GetConfigKVHandler)).Queries("key", "{key:.*}")
 			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/set-config-kv").HandlerFunc(adminMiddleware(adminAPI.SetConfigKVHandler))
 			adminRouter.Methods(http.MethodDelete).Path(adminVersion + "/del-config-kv").HandlerFunc(adminMiddleware(adminAPI.DelConfigKVHandler))
+
+			// Two-phase config changes: validate+stage, then commit or roll back.
+			adminRouter.Methods(http.MethodPut).Path(adminVersion + "/stage-config-kv").HandlerFunc(adminMiddleware(adminAPI.StageConfigKVHandler))
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/commit-staged-config").HandlerFunc(adminMiddleware(adminAPI.CommitStagedConfigHandler)).Queries("id", "{id:.*}")
+			adminRouter.Methods(http.MethodPut).Path(adminVersion+"/rollback-staged-config").HandlerFunc(adminMiddleware(adminAPI.RollbackStagedConfigHandler)).Queries("id", "{id:.*}")
+			adminRouter.Methods(http.MethodGet).Path(adminVersion + "/list-staged-config").HandlerFunc(adminMiddleware(adminAPI.ListStagedConfigHandler))
 		}
 
 		// Enable config help in all modes.
@@ -321,6 +338,165 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 		adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-quota").HandlerFunc(
 			adminMiddleware(adminAPI.PutBucketQuotaConfigHandler)).Queries("bucket", "{bucket:.*}")
 
+		// EncryptionAudit
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/encryption-audit").HandlerFunc(
+			adminMiddleware(adminAPI.EncryptionAuditHandler, traceAllFlag)).Queries("bucket", "{bucket:.*}")
+
+		// GetBucketEncryptionEnforce
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-encryption-enforce").HandlerFunc(
+			adminMiddleware(adminAPI.GetBucketEncryptionEnforceHandler)).Queries("bucket", "{bucket:.*}")
+		// PutBucketEncryptionEnforce
+		adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-encryption-enforce").HandlerFunc(
+			adminMiddleware(adminAPI.PutBucketEncryptionEnforceHandler)).Queries("bucket", "{bucket:.*}")
+
+		// GetBucketRegion
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-region").HandlerFunc(
+			adminMiddleware(adminAPI.GetBucketRegionHandler)).Queries("bucket", "{bucket:.*}")
+		// SetBucketRegion
+		adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-region").HandlerFunc(
+			adminMiddleware(adminAPI.SetBucketRegionHandler)).Queries("bucket", "{bucket:.*}", "region", "{region:.*}")
+
+		// ObjectProvenance
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/object-provenance").HandlerFunc(
+			adminMiddleware(adminAPI.ObjectProvenanceHandler, traceAllFlag)).Queries("bucket", "{bucket:.*}", "object", "{object:.*}")
+
+		// GetBucketListConsistency
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/get-bucket-list-consistency").HandlerFunc(
+			adminMiddleware(adminAPI.GetBucketListConsistencyHandler)).Queries("bucket", "{bucket:.*}")
+		// SetBucketListConsistency
+		adminRouter.Methods(http.MethodPut).Path(adminVersion+"/set-bucket-list-consistency").HandlerFunc(
+			adminMiddleware(adminAPI.SetBucketListConsistencyHandler)).Queries("bucket", "{bucket:.*}", "consistency", "{consistency:.*}")
+
+		// SmokeTest - end-to-end canary check of all erasure sets
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/smoke-test").HandlerFunc(
+			adminMiddleware(adminAPI.SmokeTestHandler))
+
+		// Deep scrub operations
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/scrub/status").HandlerFunc(
+			adminMiddleware(adminAPI.DriveScrubStatusHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/scrub/pause").HandlerFunc(
+			adminMiddleware(adminAPI.DriveScrubPauseHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/scrub/resume").HandlerFunc(
+			adminMiddleware(adminAPI.DriveScrubResumeHandler))
+
+		// Per-tenant (access key) usage metrics
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/tenant-metrics").HandlerFunc(
+			adminMiddleware(adminAPI.TenantMetricsHandler))
+
+		// Bucket inventory operations
+		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/inventory").HandlerFunc(
+			adminMiddleware(adminAPI.PutBucketInventoryHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/inventory").HandlerFunc(
+			adminMiddleware(adminAPI.GetBucketInventoryHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/inventory").HandlerFunc(
+			adminMiddleware(adminAPI.DeleteBucketInventoryHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/inventory/run").HandlerFunc(
+			adminMiddleware(adminAPI.RunBucketInventoryHandler)).Queries("bucket", "{bucket:.*}")
+
+		// Lock dependency graph and forced expiry
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/locks/graph").HandlerFunc(
+			adminMiddleware(adminAPI.LockGraphHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/locks/force-expire").HandlerFunc(
+			adminMiddleware(adminAPI.ForceExpireLocksHandler))
+
+		// Traffic shaping for client S3 traffic
+		adminRouter.Methods(http.MethodPut).Path(adminVersion + "/traffic-shaping").HandlerFunc(
+			adminMiddleware(adminAPI.SetTrafficShapingHandler))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/traffic-shaping").HandlerFunc(
+			adminMiddleware(adminAPI.ListTrafficShapingHandler))
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion + "/traffic-shaping").HandlerFunc(
+			adminMiddleware(adminAPI.RemoveTrafficShapingHandler))
+
+		// Pool placement rules for tiering within the cluster
+		adminRouter.Methods(http.MethodPut).Path(adminVersion + "/pool-placement").HandlerFunc(
+			adminMiddleware(adminAPI.SetPoolPlacementHandler))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/pool-placement").HandlerFunc(
+			adminMiddleware(adminAPI.ListPoolPlacementHandler))
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion + "/pool-placement").HandlerFunc(
+			adminMiddleware(adminAPI.RemovePoolPlacementHandler))
+
+		// Small-object packing
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/pack-objects").HandlerFunc(
+			adminMiddleware(adminAPI.PackObjectsHandler))
+
+		// Inline data statistics
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/inline-stats").HandlerFunc(
+			adminMiddleware(adminAPI.InlineDataStatsHandler))
+
+		// Erasure coding acceleration report
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/erasure-accel").HandlerFunc(
+			adminMiddleware(adminAPI.ErasureAccelHandler))
+
+		// NBD gateway exports report
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/nbd-exports").HandlerFunc(
+			adminMiddleware(adminAPI.NBDExportsHandler))
+
+		// Resolved peer addresses report
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/peer-addresses").HandlerFunc(
+			adminMiddleware(adminAPI.PeerAddressesHandler))
+
+		// Object metadata repair
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/repair-metadata").HandlerFunc(
+			adminMiddleware(adminAPI.RepairObjectMetadataHandler))
+
+		// Batch job schedules
+		adminRouter.Methods(http.MethodPut).Path(adminVersion + "/batch-schedule").HandlerFunc(
+			adminMiddleware(adminAPI.AddBatchJobScheduleHandler))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/batch-schedules").HandlerFunc(
+			adminMiddleware(adminAPI.ListBatchJobSchedulesHandler))
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion + "/batch-schedule").HandlerFunc(
+			adminMiddleware(adminAPI.RemoveBatchJobScheduleHandler))
+
+		// Per-node maintenance mode
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/maintenance/start").HandlerFunc(
+			adminMiddleware(adminAPI.StartMaintenanceHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/maintenance/stop").HandlerFunc(
+			adminMiddleware(adminAPI.StopMaintenanceHandler))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/maintenance").HandlerFunc(
+			adminMiddleware(adminAPI.MaintenanceStatusHandler))
+
+		// In-flight request listing and cancellation
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/inflight-requests").HandlerFunc(
+			adminMiddleware(adminAPI.InflightRequestsHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/inflight-requests/cancel").HandlerFunc(
+			adminMiddleware(adminAPI.CancelInflightRequestHandler))
+
+		// Tenant management
+		adminRouter.Methods(http.MethodPut).Path(adminVersion + "/tenant").HandlerFunc(
+			adminMiddleware(adminAPI.AddTenantHandler))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/tenant").HandlerFunc(
+			adminMiddleware(adminAPI.GetTenantHandler))
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion + "/tenant").HandlerFunc(
+			adminMiddleware(adminAPI.RemoveTenantHandler))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/tenants").HandlerFunc(
+			adminMiddleware(adminAPI.ListTenantsHandler))
+
+		// Consistency check operations
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/consistency-check/start").HandlerFunc(
+			adminMiddleware(adminAPI.StartConsistencyCheckHandler))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/consistency-check/status").HandlerFunc(
+			adminMiddleware(adminAPI.ConsistencyCheckStatusHandler))
+
+		// Parity upgrade operations
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/parity-upgrade/start").HandlerFunc(
+			adminMiddleware(adminAPI.StartParityUpgradeHandler))
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/parity-upgrade/status").HandlerFunc(
+			adminMiddleware(adminAPI.ParityUpgradeStatusHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/parity-upgrade/cancel").HandlerFunc(
+			adminMiddleware(adminAPI.CancelParityUpgradeHandler))
+
+		// Drive quarantine operations
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/drive/quarantine").HandlerFunc(
+			adminMiddleware(adminAPI.DriveQuarantineStatusHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/drive/readmit").HandlerFunc(
+			adminMiddleware(adminAPI.DriveReadmitHandler)).Queries("drive", "{drive:.*}")
+
+		// Drive performance baseline operations
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/drive/perf-baseline").HandlerFunc(
+			adminMiddleware(adminAPI.DrivePerfBaselineStatusHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/drive/perf-baseline").HandlerFunc(
+			adminMiddleware(adminAPI.DrivePerfRebaselineHandler))
+
 		// Bucket replication operations
 		// GetBucketTargetHandler
 		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/list-remote-targets").HandlerFunc(
@@ -353,6 +529,78 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 		adminRouter.Methods(http.MethodDelete).Path(adminVersion + "/cancel-job").HandlerFunc(
 			adminMiddleware(adminAPI.CancelBatchJob))
 
+		// Governance bypass dual control operations
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/governance-bypass/requests").HandlerFunc(
+			adminMiddleware(adminAPI.ListGovernanceBypassRequests))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/governance-bypass/approve").HandlerFunc(
+			adminMiddleware(adminAPI.ApproveGovernanceBypassRequest)).Queries("bucket", "{bucket:.*}", "object", "{object:.*}")
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/governance-bypass/request").HandlerFunc(
+			adminMiddleware(adminAPI.RejectGovernanceBypassRequest)).Queries("bucket", "{bucket:.*}", "object", "{object:.*}")
+
+		// Object Lock compliance reporting
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/object-lock/compliance-report").HandlerFunc(
+			adminMiddleware(adminAPI.ObjectLockComplianceReport)).Queries("bucket", "{bucket:.*}")
+
+		// Audit chain verification
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/audit-chain/verify").HandlerFunc(
+			adminMiddleware(adminAPI.VerifyAuditChain))
+
+		// Soft delete protection operations
+		adminRouter.Methods(http.MethodPut).Path(adminVersion+"/soft-delete").HandlerFunc(
+			adminMiddleware(adminAPI.SetBucketSoftDeleteHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/soft-delete").HandlerFunc(
+			adminMiddleware(adminAPI.GetBucketSoftDeleteHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/soft-delete").HandlerFunc(
+			adminMiddleware(adminAPI.RemoveBucketSoftDeleteHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/undelete").HandlerFunc(
+			adminMiddleware(adminAPI.UndeleteObjectHandler)).Queries("bucket", "{bucket:.*}", "object", "{object:.*}")
+
+		// Bucket snapshot operations
+		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/snapshot").HandlerFunc(
+			adminMiddleware(adminAPI.CreateBucketSnapshotHandler)).Queries("bucket", "{bucket:.*}", "snapshot", "{snapshot:.*}")
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/snapshot").HandlerFunc(
+			adminMiddleware(adminAPI.GetBucketSnapshotHandler)).Queries("bucket", "{bucket:.*}", "snapshot", "{snapshot:.*}")
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/snapshot").HandlerFunc(
+			adminMiddleware(adminAPI.DeleteBucketSnapshotHandler)).Queries("bucket", "{bucket:.*}", "snapshot", "{snapshot:.*}")
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/snapshots").HandlerFunc(
+			adminMiddleware(adminAPI.ListBucketSnapshotsHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/snapshot/diff").HandlerFunc(
+			adminMiddleware(adminAPI.DiffBucketSnapshotsHandler)).Queries("bucket", "{bucket:.*}", "from", "{from:.*}", "to", "{to:.*}")
+
+		// Compression dictionary training
+		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/compression/train-dict").HandlerFunc(
+			adminMiddleware(adminAPI.TrainCompressionDictHandler)).Queries("bucket", "{bucket:.*}")
+
+		// Bucket multipart upload quotas
+		adminRouter.Methods(http.MethodPut).Path(adminVersion+"/multipart-quota").HandlerFunc(
+			adminMiddleware(adminAPI.SetBucketMultipartQuotaHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/multipart-quota").HandlerFunc(
+			adminMiddleware(adminAPI.GetBucketMultipartQuotaHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/multipart-quota").HandlerFunc(
+			adminMiddleware(adminAPI.RemoveBucketMultipartQuotaHandler)).Queries("bucket", "{bucket:.*}")
+
+		// Server-tracked presigned URL grants
+		adminRouter.Methods(http.MethodPost).Path(adminVersion+"/presigned-grant").HandlerFunc(
+			adminMiddleware(adminAPI.MintPresignedGrantHandler)).Queries("bucket", "{bucket:.*}", "object", "{object:.*}")
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/presigned-grants").HandlerFunc(
+			adminMiddleware(adminAPI.ListPresignedGrantsHandler))
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/presigned-grant").HandlerFunc(
+			adminMiddleware(adminAPI.RevokePresignedGrantHandler)).Queries("id", "{id:.*}")
+
+		// Anonymous access audit and lockdown
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/anonymous-access").HandlerFunc(
+			adminMiddleware(adminAPI.AuditAnonymousAccessHandler))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/anonymous-access/lockdown").HandlerFunc(
+			adminMiddleware(adminAPI.LockdownAnonymousAccessHandler))
+
+		// Bucket maintenance freeze
+		adminRouter.Methods(http.MethodPut).Path(adminVersion+"/bucket-freeze").HandlerFunc(
+			adminMiddleware(adminAPI.SetBucketFreezeHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodGet).Path(adminVersion+"/bucket-freeze").HandlerFunc(
+			adminMiddleware(adminAPI.GetBucketFreezeHandler)).Queries("bucket", "{bucket:.*}")
+		adminRouter.Methods(http.MethodDelete).Path(adminVersion+"/bucket-freeze").HandlerFunc(
+			adminMiddleware(adminAPI.RemoveBucketFreezeHandler)).Queries("bucket", "{bucket:.*}")
+
 		// Bucket migration operations
 		// ExportBucketMetaHandler
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/export-bucket-metadata").HandlerFunc(
@@ -400,6 +648,7 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 
 		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/speedtest").HandlerFunc(adminMiddleware(adminAPI.ObjectSpeedTestHandler, noGZFlag))
 		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/speedtest/object").HandlerFunc(adminMiddleware(adminAPI.ObjectSpeedTestHandler, noGZFlag))
+		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/speedtest/profile").HandlerFunc(adminMiddleware(adminAPI.SpeedTestProfileHandler, noGZFlag))
 		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/speedtest/drive").HandlerFunc(adminMiddleware(adminAPI.DriveSpeedtestHandler, noGZFlag))
 		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/speedtest/net").HandlerFunc(adminMiddleware(adminAPI.NetperfHandler, noGZFlag))
 		adminRouter.Methods(http.MethodPost).Path(adminVersion + "/speedtest/site").HandlerFunc(adminMiddleware(adminAPI.SitePerfHandler, noGZFlag))
@@ -408,6 +657,8 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 
 		// HTTP Trace
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/trace").HandlerFunc(adminMiddleware(adminAPI.TraceHandler, noObjLayerFlag))
+		// Query over the in-memory trace ring buffer
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/trace/query").HandlerFunc(adminMiddleware(adminAPI.TraceQueryHandler, noObjLayerFlag))
 
 		// Console Logs
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/log").HandlerFunc(adminMiddleware(adminAPI.ConsoleLogHandler, traceAllFlag))
@@ -424,6 +675,9 @@ func registerAdminRouter(router *mux.Router, enableConfigOps bool) {
 		// -- Health API --
 		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/healthinfo").
 			HandlerFunc(adminMiddleware(adminAPI.HealthInfoHandler))
+		// Composite health score with contributors
+		adminRouter.Methods(http.MethodGet).Path(adminVersion + "/health-score").
+			HandlerFunc(adminMiddleware(adminAPI.HealthScoreHandler))
 	}
 
 	// If none of the routes match add default error handler routes
@@ -0,0 +1,230 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/event"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Request anomaly alerting. A small set of threshold rules is evaluated
+// over a rolling window of request counters and violations are sent as
+// `s3:Anomaly:Alert` events to the configured system event targets,
+// giving small deployments basic alerting without an external
+// observability stack.
+const (
+	// EnvAnomalyAlerts enables the request anomaly rules engine,
+	// disabled by default.
+	EnvAnomalyAlerts = "MINIO_ANOMALY_ALERTS"
+
+	// EnvAnomalyAlertsWindow is the rolling window over which the rule
+	// thresholds apply, defaults to 1m.
+	EnvAnomalyAlertsWindow = "MINIO_ANOMALY_ALERTS_WINDOW"
+
+	// EnvAnomalyAlertsDeniedThreshold is the number of 403 responses
+	// per access key within one window that fires an alert.
+	EnvAnomalyAlertsDeniedThreshold = "MINIO_ANOMALY_ALERTS_DENIED_THRESHOLD"
+
+	// EnvAnomalyAlertsListingThreshold is the number of listing calls
+	// per access key within one window that fires an alert.
+	EnvAnomalyAlertsListingThreshold = "MINIO_ANOMALY_ALERTS_LISTING_THRESHOLD"
+
+	// EnvAnomalyAlertsErrorRate is the 5xx error rate per bucket that
+	// fires an alert, evaluated only once a bucket has seen at least
+	// EnvAnomalyAlertsErrorMinRequests requests in the window.
+	EnvAnomalyAlertsErrorRate        = "MINIO_ANOMALY_ALERTS_ERROR_RATE"
+	EnvAnomalyAlertsErrorMinRequests = "MINIO_ANOMALY_ALERTS_ERROR_MIN_REQUESTS"
+
+	// EnvAnomalyAlertsCooldown suppresses repeated alerts for the same
+	// rule and subject, defaults to 5m.
+	EnvAnomalyAlertsCooldown = "MINIO_ANOMALY_ALERTS_COOLDOWN"
+
+	defaultAnomalyWindow           = time.Minute
+	defaultAnomalyDeniedThreshold  = 100
+	defaultAnomalyListingThreshold = 1000
+	defaultAnomalyErrorRate        = 0.5
+	defaultAnomalyErrorMinRequests = 100
+	defaultAnomalyCooldown         = 5 * time.Minute
+)
+
+// Anomaly rule names as reported in the alert event.
+const (
+	anomalyRuleAccessDenied = "access-denied-spike"
+	anomalyRuleListingStorm = "listing-storm"
+	anomalyRuleErrorRate    = "bucket-error-rate"
+)
+
+var (
+	anomalyAlertsOnce       sync.Once
+	anomalyAlertsEnabled    bool
+	anomalyWindow           time.Duration
+	anomalyDeniedThreshold  uint64
+	anomalyListingThreshold uint64
+	anomalyErrorRate        float64
+	anomalyErrorMinRequests uint64
+	anomalyCooldown         time.Duration
+)
+
+func anomalyAlertsConfig() bool {
+	anomalyAlertsOnce.Do(func() {
+		anomalyAlertsEnabled = env.Get(EnvAnomalyAlerts, config.EnableOff) == config.EnableOn
+		anomalyWindow = defaultAnomalyWindow
+		if d, err := time.ParseDuration(env.Get(EnvAnomalyAlertsWindow, "")); err == nil && d > 0 {
+			anomalyWindow = d
+		}
+		anomalyDeniedThreshold = defaultAnomalyDeniedThreshold
+		if n, err := strconv.ParseUint(env.Get(EnvAnomalyAlertsDeniedThreshold, ""), 10, 64); err == nil && n > 0 {
+			anomalyDeniedThreshold = n
+		}
+		anomalyListingThreshold = defaultAnomalyListingThreshold
+		if n, err := strconv.ParseUint(env.Get(EnvAnomalyAlertsListingThreshold, ""), 10, 64); err == nil && n > 0 {
+			anomalyListingThreshold = n
+		}
+		anomalyErrorRate = defaultAnomalyErrorRate
+		if f, err := strconv.ParseFloat(env.Get(EnvAnomalyAlertsErrorRate, ""), 64); err == nil && f > 0 && f <= 1 {
+			anomalyErrorRate = f
+		}
+		anomalyErrorMinRequests = defaultAnomalyErrorMinRequests
+		if n, err := strconv.ParseUint(env.Get(EnvAnomalyAlertsErrorMinRequests, ""), 10, 64); err == nil && n > 0 {
+			anomalyErrorMinRequests = n
+		}
+		anomalyCooldown = defaultAnomalyCooldown
+		if d, err := time.ParseDuration(env.Get(EnvAnomalyAlertsCooldown, "")); err == nil && d > 0 {
+			anomalyCooldown = d
+		}
+	})
+	return anomalyAlertsEnabled
+}
+
+// anomalyBucketCounts tracks requests and server errors of one bucket
+// within the current window.
+type anomalyBucketCounts struct {
+	requests  uint64
+	errors5xx uint64
+}
+
+// anomalyDetector accumulates per-window request counters and evaluates
+// the anomaly rules when the window rolls over.
+type anomalyDetector struct {
+	mu          sync.Mutex
+	windowStart time.Time
+	denied      map[string]uint64 // 403 responses per access key
+	listings    map[string]uint64 // listing calls per access key
+	buckets     map[string]*anomalyBucketCounts
+	lastAlert   map[string]time.Time // rule+subject -> last fired
+}
+
+var globalAnomalyDetector = &anomalyDetector{
+	denied:    make(map[string]uint64),
+	listings:  make(map[string]uint64),
+	buckets:   make(map[string]*anomalyBucketCounts),
+	lastAlert: make(map[string]time.Time),
+}
+
+// record accounts one finished S3 request, evaluating the rules once
+// the rolling window has elapsed.
+func (d *anomalyDetector) record(bucket, api, accessKey string, statusCode int) {
+	if !anomalyAlertsConfig() {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.windowStart.IsZero() {
+		d.windowStart = now
+	}
+
+	if statusCode == http.StatusForbidden && accessKey != "" {
+		d.denied[accessKey]++
+	}
+	if strings.HasPrefix(api, "List") && accessKey != "" {
+		d.listings[accessKey]++
+	}
+	if bucket != "" {
+		bc, ok := d.buckets[bucket]
+		if !ok {
+			bc = &anomalyBucketCounts{}
+			d.buckets[bucket] = bc
+		}
+		bc.requests++
+		if statusCode >= 500 {
+			bc.errors5xx++
+		}
+	}
+
+	if now.Sub(d.windowStart) >= anomalyWindow {
+		d.evaluateLocked(now)
+		d.windowStart = now
+		d.denied = make(map[string]uint64)
+		d.listings = make(map[string]uint64)
+		d.buckets = make(map[string]*anomalyBucketCounts)
+	}
+}
+
+// evaluateLocked checks every rule against the counters of the elapsed
+// window and fires one alert event per violation, the caller must hold
+// the lock.
+func (d *anomalyDetector) evaluateLocked(now time.Time) {
+	for accessKey, n := range d.denied {
+		if n >= anomalyDeniedThreshold {
+			d.alertLocked(now, anomalyRuleAccessDenied, accessKey, n, anomalyDeniedThreshold)
+		}
+	}
+	for accessKey, n := range d.listings {
+		if n >= anomalyListingThreshold {
+			d.alertLocked(now, anomalyRuleListingStorm, accessKey, n, anomalyListingThreshold)
+		}
+	}
+	for bucket, bc := range d.buckets {
+		if bc.requests < anomalyErrorMinRequests {
+			continue
+		}
+		rate := float64(bc.errors5xx) / float64(bc.requests)
+		if rate >= anomalyErrorRate {
+			d.alertLocked(now, anomalyRuleErrorRate, bucket, bc.errors5xx, uint64(anomalyErrorRate*float64(bc.requests)))
+		}
+	}
+}
+
+// alertLocked sends one anomaly alert event unless the same rule and
+// subject fired within the cooldown, the caller must hold the lock.
+func (d *anomalyDetector) alertLocked(now time.Time, rule, subject string, count, threshold uint64) {
+	key := rule + "/" + subject
+	if last, ok := d.lastAlert[key]; ok && now.Sub(last) < anomalyCooldown {
+		return
+	}
+	d.lastAlert[key] = now
+
+	sendSystemEvent(event.AnomalyAlert, map[string]string{
+		"rule":      rule,
+		"subject":   subject,
+		"count":     strconv.FormatUint(count, 10),
+		"threshold": strconv.FormatUint(threshold, 10),
+		"window":    anomalyWindow.String(),
+	})
+}
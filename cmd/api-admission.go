@@ -0,0 +1,222 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Per API class admission control. The global requests pool in
+// maxClients treats all S3 calls alike, so a listing storm competes
+// for the same slots as data traffic and can starve it - LIST calls
+// are far more expensive per slot than GETs. The admission controller
+// holds a separate concurrency pool per API class; a request beyond
+// its class limit queues for a bounded time and is shed with 503 and
+// Retry-After when the deadline passes, so clients back off instead
+// of piling up.
+//
+// Configured through environment variables, off when unset:
+//
+//	MINIO_API_CLASS_LIMITS="list=64,put=512,get=1024,delete=256"
+//	MINIO_API_CLASS_QUEUE_DEADLINE="5s"
+//
+// Classes without a limit are unthrottled.
+
+const (
+	// EnvAPIClassLimits configures per class concurrency limits.
+	EnvAPIClassLimits = "MINIO_API_CLASS_LIMITS"
+
+	// EnvAPIClassQueueDeadline bounds how long an over-limit request
+	// may wait for a slot before it is shed.
+	EnvAPIClassQueueDeadline = "MINIO_API_CLASS_QUEUE_DEADLINE"
+
+	// apiClassQueueDeadlineDefault applies when no deadline is set.
+	apiClassQueueDeadlineDefault = 5 * time.Second
+)
+
+// API classes an S3 request is admitted under.
+const (
+	apiClassList   = "list"
+	apiClassGet    = "get"
+	apiClassPut    = "put"
+	apiClassDelete = "delete"
+	apiClassOther  = "other"
+)
+
+// apiClassLimiter is the concurrency pool of one API class.
+type apiClassLimiter struct {
+	slots    chan struct{}
+	deadline time.Duration
+}
+
+// acquire waits up to the queue deadline for a slot. Returns shed
+// true when the deadline passed without one, in which case the caller
+// owes a 503; on false acquire the request was canceled by the client.
+func (l *apiClassLimiter) acquire(r *http.Request) (ok, shed bool) {
+	select {
+	case l.slots <- struct{}{}:
+		return true, false
+	default:
+	}
+
+	globalHTTPStats.addRequestsInQueue(1)
+	defer globalHTTPStats.addRequestsInQueue(-1)
+
+	t := time.NewTimer(l.deadline)
+	defer t.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true, false
+	case <-r.Context().Done():
+		return false, false
+	case <-t.C:
+		return false, true
+	}
+}
+
+// release returns the slot.
+func (l *apiClassLimiter) release() {
+	<-l.slots
+}
+
+var (
+	apiAdmissionOnce     sync.Once
+	apiAdmissionLimiters map[string]*apiClassLimiter
+)
+
+// apiAdmission returns the per class limiters, parsing the
+// configuration on first use. Nil when admission control is off.
+func apiAdmission() map[string]*apiClassLimiter {
+	apiAdmissionOnce.Do(func() {
+		limits := env.Get(EnvAPIClassLimits, "")
+		if limits == "" {
+			return
+		}
+
+		deadline := apiClassQueueDeadlineDefault
+		if v := env.Get(EnvAPIClassQueueDeadline, ""); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				logger.Fatal(errInvalidArgument, "Invalid %s value '%s'", EnvAPIClassQueueDeadline, v)
+			}
+			deadline = d
+		}
+
+		limiters := make(map[string]*apiClassLimiter)
+		for _, kv := range strings.Split(limits, ",") {
+			class, limit, found := strings.Cut(strings.TrimSpace(kv), "=")
+			if !found {
+				logger.Fatal(errInvalidArgument, "Invalid %s entry '%s'", EnvAPIClassLimits, kv)
+			}
+			switch class {
+			case apiClassList, apiClassGet, apiClassPut, apiClassDelete, apiClassOther:
+			default:
+				logger.Fatal(errInvalidArgument, "Unknown API class '%s' in %s", class, EnvAPIClassLimits)
+			}
+			n, err := strconv.Atoi(limit)
+			if err != nil || n <= 0 {
+				logger.Fatal(errInvalidArgument, "Invalid limit for API class '%s' in %s", class, EnvAPIClassLimits)
+			}
+			limiters[class] = &apiClassLimiter{
+				slots:    make(chan struct{}, n),
+				deadline: deadline,
+			}
+		}
+		apiAdmissionLimiters = limiters
+	})
+	return apiAdmissionLimiters
+}
+
+// apiRequestClass buckets an S3 request into an API class.
+func apiRequestClass(r *http.Request, object string) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if object == "" {
+			return apiClassList
+		}
+		return apiClassGet
+	case http.MethodPut:
+		return apiClassPut
+	case http.MethodPost:
+		if r.URL.Query().Has("delete") {
+			return apiClassDelete
+		}
+		return apiClassPut
+	case http.MethodDelete:
+		return apiClassDelete
+	}
+	return apiClassOther
+}
+
+// setAPIAdmissionMiddleware admits S3 requests under their API class
+// limit, queueing and shedding over-limit requests.
+func setAPIAdmissionMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiters := apiAdmission()
+		if limiters == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		bucket, object := request2BucketObjectName(r)
+		if bucket == "" || isMinioReservedBucket(bucket) || isMinioMetaBucket(bucket) ||
+			guessIsRPCReq(r) || guessIsBrowserReq(r) || guessIsHealthCheckReq(r) ||
+			guessIsMetricsReq(r) || isAdminReq(r) || isKMSReq(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		class := apiRequestClass(r, object)
+		limiter := limiters[class]
+		if limiter == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		ok, shed := limiter.acquire(r)
+		if !ok {
+			if !shed {
+				// Client went away while queued.
+				w.WriteHeader(499)
+				return
+			}
+			errCode := ErrSlowDownWrite
+			if class == apiClassList || class == apiClassGet {
+				errCode = ErrSlowDownRead
+			}
+			retryAfter := int(limiter.deadline / time.Second)
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			w.Header().Set(xhttp.RetryAfter, strconv.Itoa(retryAfter))
+			writeErrorResponse(r.Context(), w, errorCodes.ToAPIErr(errCode), r.URL)
+			return
+		}
+		defer limiter.release()
+
+		h.ServeHTTP(w, r)
+	})
+}
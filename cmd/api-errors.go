@@ -448,6 +448,17 @@ const (
 
 	ErrIAMNotInitialized
 
+	// Dual control (four-eyes) for governance bypass deletes
+	ErrGovernanceBypassPendingApproval
+	ErrAdminNoSuchGovernanceBypassRequest
+	ErrAdminGovernanceSelfApproval
+
+	// Per-bucket multipart upload quotas
+	ErrMultipartQuotaExceeded
+
+	// Content dedup reference protection
+	ErrDedupSourceReferenced
+
 	apiErrCodeEnd // This is used only for the testing code
 )
 
@@ -1067,6 +1078,21 @@ var errorCodes = errorCodeMap{
 		Description:    "Object is WORM protected and cannot be overwritten",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrGovernanceBypassPendingApproval: {
+		Code:           "GovernanceBypassPendingApproval",
+		Description:    "Deleting this object with governance bypass requires approval by a second credential",
+		HTTPStatusCode: http.StatusForbidden,
+	},
+	ErrMultipartQuotaExceeded: {
+		Code:           "MultipartQuotaExceeded",
+		Description:    "The bucket has reached its limit of concurrent multipart uploads, complete or abort existing uploads first",
+		HTTPStatusCode: http.StatusTooManyRequests,
+	},
+	ErrDedupSourceReferenced: {
+		Code:           "DedupSourceReferenced",
+		Description:    "This version is referenced by deduplicated objects and cannot be deleted",
+		HTTPStatusCode: http.StatusMethodNotAllowed,
+	},
 	ErrInvalidRetentionDate: {
 		Code:           "InvalidRequest",
 		Description:    "Date must be provided in ISO 8601 format",
@@ -1353,6 +1379,16 @@ var errorCodes = errorCodeMap{
 		Description:    "The specified job does not exist.",
 		HTTPStatusCode: http.StatusNotFound,
 	},
+	ErrAdminNoSuchGovernanceBypassRequest: {
+		Code:           "XMinioAdminNoSuchGovernanceBypassRequest",
+		Description:    "No pending governance bypass request for the specified object version.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrAdminGovernanceSelfApproval: {
+		Code:           "XMinioAdminGovernanceSelfApproval",
+		Description:    "A governance bypass delete cannot be approved by the credential that requested it.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
 	ErrAdminGroupNotEmpty: {
 		Code:           "XMinioAdminGroupNotEmpty",
 		Description:    "The specified group is not empty - cannot remove it.",
@@ -2169,6 +2205,10 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrAdminInvalidGroupName
 	case errNoSuchJob:
 		apiErr = ErrAdminNoSuchJob
+	case errGovernanceBypassPendingApproval:
+		apiErr = ErrGovernanceBypassPendingApproval
+	case errDedupSourceReferenced:
+		apiErr = ErrDedupSourceReferenced
 	case errNoPolicyToAttachOrDetach:
 		apiErr = ErrAdminPolicyChangeAlreadyApplied
 	case errSignatureMismatch:
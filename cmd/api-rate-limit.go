@@ -0,0 +1,279 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/minio/minio/internal/config"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Server-side API rate limiting. Requests are classified per bucket,
+// per access key and per API class (read, write, list, delete) and
+// checked against token buckets before the handler runs; requests over
+// the limit are rejected with 429 and a Retry-After header. Configured
+// rates are cluster-wide and divided evenly across the nodes, each node
+// enforcing its share locally.
+const (
+	// EnvAPIRateLimit enables API rate limiting, disabled by default.
+	EnvAPIRateLimit = "MINIO_API_RATE_LIMIT"
+
+	// EnvAPIRateLimitRules holds the rate limit rules, separated by
+	// ';'. Each rule is '<scope>:<class>:<rate>[:<burst>]' where scope
+	// is 'bucket=<name>', 'key=<access-key>' or '*', with '*' as the
+	// name or access key applying the limit to each bucket or key
+	// individually. Class is one of the slow operation log API classes
+	// (read, write, list, delete) or '*' for all of them. Rate is the
+	// cluster-wide limit in requests per second, burst defaults to
+	// twice the rate.
+	EnvAPIRateLimitRules = "MINIO_API_RATE_LIMIT_RULES"
+)
+
+// Rate limit rule scopes.
+const (
+	rateLimitScopeBucket = "bucket"
+	rateLimitScopeKey    = "key"
+	rateLimitScopeAll    = "*"
+)
+
+// rateLimitRule is one parsed rate limit rule.
+type rateLimitRule struct {
+	scope   string // rateLimitScope*
+	subject string // bucket name or access key, "*" for each individually
+	class   string // slowOpClass* or "*"
+	limit   rate.Limit
+	burst   int
+}
+
+// matches returns the limiter key for the rule if it applies to the
+// request, and whether it does.
+func (rl rateLimitRule) matches(idx int, bucket, accessKey, class string) (string, bool) {
+	if rl.class != "*" && rl.class != class {
+		return "", false
+	}
+	var instance string
+	switch rl.scope {
+	case rateLimitScopeBucket:
+		if bucket == "" {
+			return "", false
+		}
+		if rl.subject == "*" {
+			instance = bucket
+		} else if rl.subject != bucket {
+			return "", false
+		}
+	case rateLimitScopeKey:
+		if accessKey == "" {
+			return "", false
+		}
+		if rl.subject == "*" {
+			instance = accessKey
+		} else if rl.subject != accessKey {
+			return "", false
+		}
+	}
+	return strconv.Itoa(idx) + "|" + instance, true
+}
+
+// apiRateLimiter holds the parsed rules and the token buckets, created
+// lazily per rule and subject.
+type apiRateLimiter struct {
+	enabled bool
+	rules   []rateLimitRule
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+
+	// Throttled request counters per API class.
+	throttledRead   atomic.Uint64
+	throttledWrite  atomic.Uint64
+	throttledList   atomic.Uint64
+	throttledDelete atomic.Uint64
+	throttledOther  atomic.Uint64
+}
+
+var (
+	globalAPIRateLimiter     = &apiRateLimiter{limiters: make(map[string]*rate.Limiter)}
+	globalAPIRateLimiterOnce sync.Once
+)
+
+// parseRateLimitRules parses the EnvAPIRateLimitRules value, dividing
+// the configured cluster-wide rates across the nodes.
+func parseRateLimitRules(s string, nodes int) ([]rateLimitRule, error) {
+	var rules []rateLimitRule
+	for _, rs := range strings.Split(s, ";") {
+		rs = strings.TrimSpace(rs)
+		if rs == "" {
+			continue
+		}
+		tokens := strings.Split(rs, ":")
+		if len(tokens) < 3 || len(tokens) > 4 {
+			return nil, fmt.Errorf("invalid rate limit rule '%s'", rs)
+		}
+
+		rule := rateLimitRule{scope: rateLimitScopeAll}
+		if tokens[0] != "*" {
+			scope, subject, ok := strings.Cut(tokens[0], "=")
+			if !ok || subject == "" || (scope != rateLimitScopeBucket && scope != rateLimitScopeKey) {
+				return nil, fmt.Errorf("invalid rate limit scope '%s'", tokens[0])
+			}
+			rule.scope, rule.subject = scope, subject
+		}
+
+		switch tokens[1] {
+		case slowOpClassRead, slowOpClassWrite, slowOpClassList, slowOpClassDelete, "*":
+			rule.class = tokens[1]
+		default:
+			return nil, fmt.Errorf("invalid rate limit class '%s'", tokens[1])
+		}
+
+		rps, err := strconv.ParseFloat(tokens[2], 64)
+		if err != nil || rps <= 0 {
+			return nil, fmt.Errorf("invalid rate limit rate '%s'", tokens[2])
+		}
+		burst := 2 * rps
+		if len(tokens) == 4 {
+			burst, err = strconv.ParseFloat(tokens[3], 64)
+			if err != nil || burst < rps {
+				return nil, fmt.Errorf("invalid rate limit burst '%s'", tokens[3])
+			}
+		}
+
+		// Each node enforces its share of the cluster-wide rate.
+		rule.limit = rate.Limit(rps / float64(nodes))
+		rule.burst = int(math.Max(1, math.Ceil(burst/float64(nodes))))
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// apiRateLimiterConfig parses the rate limiting configuration from the
+// environment once and returns the limiter.
+func apiRateLimiterConfig() *apiRateLimiter {
+	globalAPIRateLimiterOnce.Do(func() {
+		if env.Get(EnvAPIRateLimit, config.EnableOff) != config.EnableOn {
+			return
+		}
+		rules, err := parseRateLimitRules(env.Get(EnvAPIRateLimitRules, ""), totalNodeCount())
+		if err != nil {
+			internalLogIf(GlobalContext, fmt.Errorf("unable to parse %s: %w", EnvAPIRateLimitRules, err))
+			return
+		}
+		if len(rules) == 0 {
+			return
+		}
+		globalAPIRateLimiter.rules = rules
+		globalAPIRateLimiter.enabled = true
+	})
+	return globalAPIRateLimiter
+}
+
+// limiter returns the token bucket for the given limiter key, creating
+// it on first use.
+func (l *apiRateLimiter) limiter(key string, rule rateLimitRule) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lim, ok := l.limiters[key]
+	if !ok {
+		lim = rate.NewLimiter(rule.limit, rule.burst)
+		l.limiters[key] = lim
+	}
+	return lim
+}
+
+// allow checks the request against all matching rules. When the request
+// is over the limit it returns false and the duration after which a
+// retry may succeed.
+func (l *apiRateLimiter) allow(bucket, accessKey, class string) (time.Duration, bool) {
+	for i, rule := range l.rules {
+		key, ok := rule.matches(i, bucket, accessKey, class)
+		if !ok {
+			continue
+		}
+		if !l.limiter(key, rule).Allow() {
+			switch class {
+			case slowOpClassRead:
+				l.throttledRead.Add(1)
+			case slowOpClassWrite:
+				l.throttledWrite.Add(1)
+			case slowOpClassList:
+				l.throttledList.Add(1)
+			case slowOpClassDelete:
+				l.throttledDelete.Add(1)
+			default:
+				l.throttledOther.Add(1)
+			}
+			return time.Duration(float64(time.Second) / float64(rule.limit)), false
+		}
+	}
+	return 0, true
+}
+
+// requestAccessKey extracts the access key from the request without
+// verifying the signature - rate limiting runs before authentication,
+// which still rejects requests with a forged key.
+func requestAccessKey(r *http.Request) string {
+	if auth := r.Header.Get(xhttp.Authorization); auth != "" {
+		if i := strings.Index(auth, "Credential="); i >= 0 {
+			cred := auth[i+len("Credential="):]
+			if j := strings.IndexByte(cred, '/'); j > 0 {
+				return cred[:j]
+			}
+		}
+		if strings.HasPrefix(auth, signV2Algorithm+" ") {
+			cred := auth[len(signV2Algorithm)+1:]
+			if j := strings.IndexByte(cred, ':'); j > 0 {
+				return cred[:j]
+			}
+		}
+	}
+	if cred := r.URL.Query().Get(xhttp.AmzCredential); cred != "" {
+		if j := strings.IndexByte(cred, '/'); j > 0 {
+			return cred[:j]
+		}
+	}
+	return r.URL.Query().Get(xhttp.AmzAccessKeyID)
+}
+
+// rateLimitRequest rejects the request with 429 and a Retry-After
+// header when it is over a configured limit, returning true if the
+// request was rejected.
+func rateLimitRequest(w http.ResponseWriter, r *http.Request, api, bucket string) bool {
+	l := apiRateLimiterConfig()
+	if !l.enabled {
+		return false
+	}
+	retryAfter, ok := l.allow(bucket, requestAccessKey(r), slowOpClass(api))
+	if ok {
+		return false
+	}
+	w.Header().Set(xhttp.RetryAfter, strconv.Itoa(int(math.Max(1, math.Ceil(retryAfter.Seconds())))))
+	writeErrorResponse(r.Context(), w, errorCodes.ToAPIErr(ErrTooManyRequests), r.URL)
+	return true
+}
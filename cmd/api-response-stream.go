@@ -0,0 +1,196 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+
+	xhttp "github.com/minio/minio/internal/http"
+	xioutil "github.com/minio/minio/internal/ioutil"
+	xxml "github.com/minio/xxml"
+)
+
+// Streaming writer for listing responses. A full page of 1000 keys
+// with user metadata marshals to several megabytes; building that
+// document in memory per request makes listing storms expensive and
+// delays the first byte until the last key is formatted. The streamer
+// encodes entries as they are produced: formatting runs ahead through
+// a bounded channel, the encoder drains it onto a buffered writer and
+// flushes at fixed entry counts, so memory stays bounded by the
+// channel and buffer regardless of page size and the client starts
+// reading while the tail of the page is still being formatted.
+
+const (
+	// listStreamQueueDepth bounds how many formatted entries may wait
+	// for the encoder, and with it the formatting lookahead.
+	listStreamQueueDepth = 64
+
+	// listStreamFlushEvery pushes buffered XML to the client after
+	// this many entries.
+	listStreamFlushEvery = 100
+
+	// listStreamBufSize is the size of the write buffer in front of
+	// the network.
+	listStreamBufSize = 16 << 10
+)
+
+// xmlElem is one header element of a streamed response.
+type xmlElem struct {
+	name  string
+	value interface{}
+
+	// omitEmpty skips empty string values the way an `omitempty`
+	// struct tag would.
+	omitEmpty bool
+}
+
+// xmlEncodeElements encodes header elements in order.
+func xmlEncodeElements(enc *xxml.Encoder, elems []xmlElem) error {
+	for _, elem := range elems {
+		if elem.omitEmpty {
+			if s, ok := elem.value.(string); ok && s == "" {
+				continue
+			}
+		}
+		if err := enc.EncodeElement(elem.value, xxml.StartElement{Name: xxml.Name{Local: elem.name}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamListObjectsV2Response writes a ListObjectsV2 response without
+// materializing it, see the comment at the top of the file. The
+// element order matches what encodeResponseList produced for
+// ListObjectsV2Response so clients observe an identical document.
+func streamListObjectsV2Response(ctx context.Context, w http.ResponseWriter, bucket, prefix, token, nextToken, startAfter, delimiter, encodingType string, fetchOwner, isTruncated bool, maxKeys int, objects []ObjectInfo, prefixes []string, metadata metaCheckFn) {
+	var owner *Owner
+	if fetchOwner {
+		owner = &Owner{
+			ID:          globalMinioDefaultOwnerID,
+			DisplayName: "minio",
+		}
+	}
+
+	keyCount := len(prefixes)
+	for _, object := range objects {
+		if object.Name != "" {
+			keyCount++
+		}
+	}
+
+	// Formatting runs ahead of the encoder through a bounded channel;
+	// when the client reads slowly the buffer fills, the channel fills
+	// and formatting stops - backpressure instead of buildup.
+	entries := make(chan Object, listStreamQueueDepth)
+	go func() {
+		defer xioutil.SafeClose(entries)
+		for _, object := range objects {
+			if object.Name == "" {
+				continue
+			}
+			select {
+			case entries <- listObjectEntry(ctx, object, owner, encodingType, metadata):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	setCommonHeaders(w)
+	w.Header().Set(xhttp.ContentType, string(mimeXML))
+	w.WriteHeader(http.StatusOK)
+
+	bw := bufio.NewWriterSize(w, listStreamBufSize)
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		bw.Flush()
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if _, err := io.WriteString(bw, xxml.Header); err != nil {
+		return
+	}
+	enc := xxml.NewEncoder(bw)
+	root := xxml.StartElement{
+		Name: xxml.Name{Local: "ListBucketResult"},
+		Attr: []xxml.Attr{{Name: xxml.Name{Local: "xmlns"}, Value: "http://s3.amazonaws.com/doc/2006-03-01/"}},
+	}
+	if err := enc.EncodeToken(root); err != nil {
+		return
+	}
+
+	err := xmlEncodeElements(enc, []xmlElem{
+		{name: "Name", value: bucket},
+		{name: "Prefix", value: s3EncodeName(prefix, encodingType)},
+		{name: "StartAfter", value: s3EncodeName(startAfter, encodingType), omitEmpty: true},
+		{name: "ContinuationToken", value: base64.StdEncoding.EncodeToString([]byte(token)), omitEmpty: true},
+		{name: "NextContinuationToken", value: base64.StdEncoding.EncodeToString([]byte(nextToken)), omitEmpty: true},
+		{name: "KeyCount", value: keyCount},
+		{name: "MaxKeys", value: maxKeys},
+		{name: "Delimiter", value: s3EncodeName(delimiter, encodingType), omitEmpty: true},
+		{name: "IsTruncated", value: isTruncated},
+	})
+	if err != nil {
+		return
+	}
+
+	written := 0
+	for entry := range entries {
+		if err != nil {
+			// Keep draining so the producer can finish.
+			continue
+		}
+		err = enc.EncodeElement(entry, xxml.StartElement{Name: xxml.Name{Local: "Contents"}})
+		if err == nil {
+			if written++; written%listStreamFlushEvery == 0 {
+				if err = enc.Flush(); err == nil {
+					flush()
+				}
+			}
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	for _, prefix := range prefixes {
+		item := CommonPrefix{Prefix: s3EncodeName(prefix, encodingType)}
+		if err = enc.EncodeElement(item, xxml.StartElement{Name: xxml.Name{Local: "CommonPrefixes"}}); err != nil {
+			return
+		}
+	}
+	if encodingType != "" {
+		if err = enc.EncodeElement(encodingType, xxml.StartElement{Name: xxml.Name{Local: "EncodingType"}}); err != nil {
+			return
+		}
+	}
+	if err = enc.EncodeToken(root.End()); err != nil {
+		return
+	}
+	if err = enc.Flush(); err != nil {
+		return
+	}
+	flush()
+}
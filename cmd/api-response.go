@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/xml"
 	"fmt"
 	"net/http"
@@ -680,82 +679,47 @@ func generateListObjectsV1Response(ctx context.Context, bucket, prefix, marker,
 	return data
 }
 
-// generates an ListObjectsV2 response for the said bucket with other enumerated options.
-func generateListObjectsV2Response(ctx context.Context, bucket, prefix, token, nextToken, startAfter, delimiter, encodingType string, fetchOwner, isTruncated bool, maxKeys int, objects []ObjectInfo, prefixes []string, metadata metaCheckFn) ListObjectsV2Response {
-	contents := make([]Object, 0, len(objects))
-	var owner *Owner
-	if fetchOwner {
-		owner = &Owner{
-			ID:          globalMinioDefaultOwnerID,
-			DisplayName: "minio",
-		}
+// listObjectEntry formats one object for a ListObjectsV2 response
+// page, the entries themselves are encoded by the streaming writer in
+// streamListObjectsV2Response.
+func listObjectEntry(ctx context.Context, object ObjectInfo, owner *Owner, encodingType string, metadata metaCheckFn) Object {
+	content := Object{}
+	content.Key = s3EncodeName(object.Name, encodingType)
+	content.LastModified = amztime.ISO8601Format(object.ModTime.UTC())
+	if object.ETag != "" {
+		content.ETag = "\"" + object.ETag + "\""
 	}
-
-	data := ListObjectsV2Response{}
-
-	for _, object := range objects {
-		content := Object{}
-		if object.Name == "" {
-			continue
-		}
-		content.Key = s3EncodeName(object.Name, encodingType)
-		content.LastModified = amztime.ISO8601Format(object.ModTime.UTC())
-		if object.ETag != "" {
-			content.ETag = "\"" + object.ETag + "\""
-		}
-		content.Size = object.Size
-		if object.StorageClass != "" {
-			content.StorageClass = filterStorageClass(ctx, object.StorageClass)
-		} else {
-			content.StorageClass = globalMinioDefaultStorageClass
+	content.Size = object.Size
+	if object.StorageClass != "" {
+		content.StorageClass = filterStorageClass(ctx, object.StorageClass)
+	} else {
+		content.StorageClass = globalMinioDefaultStorageClass
+	}
+	content.Owner = owner
+	if metadata != nil {
+		if metadata(object.Name, policy.GetObjectTaggingAction) == ErrNone {
+			content.UserTags = object.UserTags
 		}
-		content.Owner = owner
-		if metadata != nil {
-			if metadata(object.Name, policy.GetObjectTaggingAction) == ErrNone {
-				content.UserTags = object.UserTags
+		if metadata(object.Name, policy.GetObjectAction) == ErrNone {
+			content.UserMetadata = &Metadata{}
+			switch kind, _ := crypto.IsEncrypted(object.UserDefined); kind {
+			case crypto.S3:
+				content.UserMetadata.Set(xhttp.AmzServerSideEncryption, xhttp.AmzEncryptionAES)
+			case crypto.S3KMS:
+				content.UserMetadata.Set(xhttp.AmzServerSideEncryption, xhttp.AmzEncryptionKMS)
+			case crypto.SSEC:
+				content.UserMetadata.Set(xhttp.AmzServerSideEncryptionCustomerAlgorithm, xhttp.AmzEncryptionAES)
+			}
+			for k, v := range cleanReservedKeys(object.UserDefined) {
+				content.UserMetadata.Set(k, v)
 			}
-			if metadata(object.Name, policy.GetObjectAction) == ErrNone {
-				content.UserMetadata = &Metadata{}
-				switch kind, _ := crypto.IsEncrypted(object.UserDefined); kind {
-				case crypto.S3:
-					content.UserMetadata.Set(xhttp.AmzServerSideEncryption, xhttp.AmzEncryptionAES)
-				case crypto.S3KMS:
-					content.UserMetadata.Set(xhttp.AmzServerSideEncryption, xhttp.AmzEncryptionKMS)
-				case crypto.SSEC:
-					content.UserMetadata.Set(xhttp.AmzServerSideEncryptionCustomerAlgorithm, xhttp.AmzEncryptionAES)
-				}
-				for k, v := range cleanReservedKeys(object.UserDefined) {
-					content.UserMetadata.Set(k, v)
-				}
-				content.Internal = &ObjectInternalInfo{
-					K: object.DataBlocks,
-					M: object.ParityBlocks,
-				}
+			content.Internal = &ObjectInternalInfo{
+				K: object.DataBlocks,
+				M: object.ParityBlocks,
 			}
 		}
-		contents = append(contents, content)
 	}
-	data.Name = bucket
-	data.Contents = contents
-
-	data.EncodingType = encodingType
-	data.StartAfter = s3EncodeName(startAfter, encodingType)
-	data.Delimiter = s3EncodeName(delimiter, encodingType)
-	data.Prefix = s3EncodeName(prefix, encodingType)
-	data.MaxKeys = maxKeys
-	data.ContinuationToken = base64.StdEncoding.EncodeToString([]byte(token))
-	data.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(nextToken))
-	data.IsTruncated = isTruncated
-
-	commonPrefixes := make([]CommonPrefix, 0, len(prefixes))
-	for _, prefix := range prefixes {
-		prefixItem := CommonPrefix{}
-		prefixItem.Prefix = s3EncodeName(prefix, encodingType)
-		commonPrefixes = append(commonPrefixes, prefixItem)
-	}
-	data.CommonPrefixes = commonPrefixes
-	data.KeyCount = len(data.Contents) + len(data.CommonPrefixes)
-	return data
+	return content
 }
 
 type metaCheckFn = func(name string, action policy.Action) (s3Err APIErrorCode)
@@ -400,6 +400,16 @@ func registerAPIRouter(router *mux.Router) {
 			HandlerFunc(s3APIMiddleware(api.PostRestoreObjectHandler)).
 			Queries("restore", "")
 
+		// PostRotateObjectSSECKey - MinIO extension API
+		router.Methods(http.MethodPost).Path("/{object:.+}").
+			HandlerFunc(s3APIMiddleware(api.PostRotateObjectSSECKeyHandler)).
+			Queries("rotate-sse-c", "")
+
+		// ComposeObject - MinIO extension API
+		router.Methods(http.MethodPost).Path("/{object:.+}").
+			HandlerFunc(s3APIMiddleware(api.ComposeObjectHandler, traceHdrsS3HFlag)).
+			Queries("compose", "")
+
 		// Bucket operations
 
 		// GetBucketLocation
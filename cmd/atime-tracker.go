@@ -0,0 +1,229 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Optional access time tracking. When MINIO_ATIME_TRACKING=on, object
+// reads record a last-access timestamp which is batched in memory and
+// periodically merged into a per bucket sidecar index under the minio
+// metadata bucket. The timestamps are eventually consistent - a crash
+// loses at most one flush interval of updates and concurrent nodes
+// merge by keeping the newest timestamp. The recorded time is exposed
+// on HEAD responses and drives lifecycle DaysSinceLastAccess rules.
+const (
+	// EnvATimeTracking enables access time tracking when set to "on".
+	EnvATimeTracking = "MINIO_ATIME_TRACKING"
+
+	// atimeIndexPrefix is where the sidecar indexes live, relative to
+	// minioMetaBucket.
+	atimeIndexPrefix = ".atime-index"
+
+	// atimeIndexFile is the per bucket index filename.
+	atimeIndexFile = "atime.json"
+
+	// atimeFlushInterval is how often pending timestamps are merged
+	// into the sidecar indexes.
+	atimeFlushInterval = 5 * time.Minute
+
+	// atimeFlushMaxPending triggers an early flush once this many
+	// objects have unmerged timestamps.
+	atimeFlushMaxPending = 10000
+
+	// atimeIndexCacheTTL bounds how stale a cached sidecar index may
+	// be when answering lookups.
+	atimeIndexCacheTTL = time.Minute
+
+	// AmzMinioLastAccessTime is the HEAD response header carrying the
+	// recorded last-access time.
+	AmzMinioLastAccessTime = "X-Minio-Last-Access-Time"
+)
+
+// aTimeIndexCache is one bucket's loaded sidecar index.
+type aTimeIndexCache struct {
+	entries  map[string]time.Time
+	loadedAt time.Time
+}
+
+// aTimeTracker batches last-access timestamps and merges them into the
+// per bucket sidecar indexes.
+type aTimeTracker struct {
+	mu      sync.Mutex
+	pending map[string]map[string]time.Time // bucket -> object -> last access
+	count   int
+	cache   map[string]aTimeIndexCache
+}
+
+// globalATimeTracker is nil unless access time tracking is enabled.
+var globalATimeTracker *aTimeTracker
+
+// initATimeTracker starts the access time tracker when enabled via
+// environment.
+func initATimeTracker(ctx context.Context) {
+	if env.Get(EnvATimeTracking, config.EnableOff) != config.EnableOn {
+		return
+	}
+	t := &aTimeTracker{
+		pending: make(map[string]map[string]time.Time),
+		cache:   make(map[string]aTimeIndexCache),
+	}
+	go t.flushLoop(ctx)
+	globalATimeTracker = t
+}
+
+// atimeIndexPath returns a bucket's sidecar index location relative to
+// minioMetaBucket.
+func atimeIndexPath(bucket string) string {
+	return pathJoin(atimeIndexPrefix, bucket, atimeIndexFile)
+}
+
+// record notes that an object was read just now. Safe to call on a nil
+// tracker.
+func (t *aTimeTracker) record(bucket, object string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	m := t.pending[bucket]
+	if m == nil {
+		m = make(map[string]time.Time)
+		t.pending[bucket] = m
+	}
+	if _, ok := m[object]; !ok {
+		t.count++
+	}
+	m[object] = UTCNow()
+	full := t.count >= atimeFlushMaxPending
+	t.mu.Unlock()
+
+	if full {
+		go t.flush(GlobalContext)
+	}
+}
+
+// lastAccess returns the recorded last-access time of an object, zero
+// when unknown. Safe to call on a nil tracker.
+func (t *aTimeTracker) lastAccess(ctx context.Context, bucket, object string) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	t.mu.Lock()
+	if at, ok := t.pending[bucket][object]; ok {
+		t.mu.Unlock()
+		return at
+	}
+	cached, ok := t.cache[bucket]
+	t.mu.Unlock()
+
+	if !ok || time.Since(cached.loadedAt) > atimeIndexCacheTTL {
+		entries, err := t.loadIndex(ctx, bucket)
+		if err != nil {
+			internalLogIf(ctx, err)
+			return time.Time{}
+		}
+		cached = aTimeIndexCache{entries: entries, loadedAt: UTCNow()}
+		t.mu.Lock()
+		t.cache[bucket] = cached
+		t.mu.Unlock()
+	}
+	return cached.entries[object]
+}
+
+// loadIndex reads a bucket's sidecar index, a missing index reads as
+// empty.
+func (t *aTimeTracker) loadIndex(ctx context.Context, bucket string) (map[string]time.Time, error) {
+	api := newObjectLayerFn()
+	if api == nil {
+		return nil, errServerNotInitialized
+	}
+	data, err := readConfig(ctx, api, atimeIndexPath(bucket))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return make(map[string]time.Time), nil
+		}
+		return nil, err
+	}
+	entries := make(map[string]time.Time)
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// flushLoop merges pending timestamps on an interval.
+func (t *aTimeTracker) flushLoop(ctx context.Context) {
+	tick := time.NewTicker(atimeFlushInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			t.flush(ctx)
+		}
+	}
+}
+
+// flush merges the pending timestamps into the sidecar indexes,
+// keeping the newest timestamp per object so concurrent nodes converge.
+func (t *aTimeTracker) flush(ctx context.Context) {
+	api := newObjectLayerFn()
+	if api == nil {
+		return
+	}
+
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[string]map[string]time.Time)
+	t.count = 0
+	t.mu.Unlock()
+
+	for bucket, updates := range pending {
+		entries, err := t.loadIndex(ctx, bucket)
+		if err != nil {
+			internalLogIf(ctx, err)
+			continue
+		}
+		for object, at := range updates {
+			if at.After(entries[object]) {
+				entries[object] = at
+			}
+		}
+		data, err := json.Marshal(entries)
+		if err != nil {
+			internalLogIf(ctx, err)
+			continue
+		}
+		if err = saveConfig(ctx, api, atimeIndexPath(bucket), data); err != nil {
+			internalLogIf(ctx, err)
+			continue
+		}
+		t.mu.Lock()
+		t.cache[bucket] = aTimeIndexCache{entries: entries, loadedAt: UTCNow()}
+		t.mu.Unlock()
+	}
+}
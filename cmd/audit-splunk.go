@@ -0,0 +1,43 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/minio/minio/internal/logger"
+)
+
+// initAuditSplunkTarget configures the Splunk HTTP Event Collector audit
+// target from the environment, if enabled.
+func initAuditSplunkTarget(ctx context.Context) {
+	splunkCfg, err := logger.LookupAuditSplunkConfig()
+	if err != nil {
+		configLogIf(ctx, fmt.Errorf("Unable to load audit splunk config: %w", err))
+		return
+	}
+	if !splunkCfg.Enabled {
+		return
+	}
+	splunkCfg.LogOnce = configLogOnceIf
+	splunkCfg.Transport = NewHTTPTransport()
+	if errs := logger.UpdateAuditSplunkTargets(ctx, splunkCfg); len(errs) > 0 {
+		configLogIf(ctx, fmt.Errorf("Unable to update audit splunk target: %v", errs))
+	}
+}
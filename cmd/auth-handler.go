@@ -179,6 +179,7 @@ func validateAdminSignature(ctx context.Context, r *http.Request, region string)
 	logger.GetReqInfo(ctx).Cred = cred
 	logger.GetReqInfo(ctx).Owner = owner
 	logger.GetReqInfo(ctx).Region = globalSite.Region()
+	setTenantInTraceCtxt(ctx, cred)
 
 	return cred, owner, ErrNone
 }
@@ -389,6 +390,7 @@ func authenticateRequest(ctx context.Context, r *http.Request, action policy.Act
 	logger.GetReqInfo(ctx).Cred = cred
 	logger.GetReqInfo(ctx).Owner = owner
 	logger.GetReqInfo(ctx).Region = globalSite.Region()
+	setTenantInTraceCtxt(ctx, cred)
 
 	// region is valid only for CreateBucketAction.
 	var region string
@@ -765,6 +767,7 @@ func isPutActionAllowed(ctx context.Context, atype authType, bucketName, objectN
 	logger.GetReqInfo(ctx).Cred = cred
 	logger.GetReqInfo(ctx).Owner = owner
 	logger.GetReqInfo(ctx).Region = region
+	setTenantInTraceCtxt(ctx, cred)
 
 	// Do not check for PutObjectRetentionAction permission,
 	// if mode and retain until date are not set.
@@ -99,6 +99,19 @@ func waitForLowHTTPReq() {
 	waitForLowIO(maxIO, maxWait, currentHTTPIO)
 }
 
+// waitForHealWindow blocks until the configured healing window is open,
+// checking once a minute. Returns immediately when no window is
+// configured or when ctx is canceled.
+func waitForHealWindow(ctx context.Context) {
+	for !globalHealConfig.InWindow(time.Now()) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Minute):
+		}
+	}
+}
+
 func initBackgroundHealing(ctx context.Context, objAPI ObjectLayer) {
 	bgSeq := newBgHealSequence()
 	// Run the background healer
@@ -0,0 +1,242 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+)
+
+// Any batch job definition may declare a dependency on another job,
+// forming chains of jobs without an external orchestrator - e.g. an
+// expire job chained after a replicate job:
+//
+// expire:
+//   apiVersion: v1
+//   ...
+// chain:
+//   after: "replicate-xxxx:0" # job ID this job waits for
+//   onFailure: "stop" # "stop" (default) skips this job when the
+//                     # predecessor fails, "continue" runs it anyway
+//
+// The chained job is queued immediately but its execution is held back
+// until the predecessor's report shows it finished. Longer workflows
+// are expressed by chaining each submitted job after the previous one.
+
+//go:generate msgp -file $GOFILE -unexported
+
+const (
+	// batchJobChainOnFailureStop skips the chained job when the
+	// predecessor fails, the default.
+	batchJobChainOnFailureStop = "stop"
+
+	// batchJobChainOnFailureContinue runs the chained job regardless of
+	// the predecessor's outcome.
+	batchJobChainOnFailureContinue = "continue"
+
+	// batchJobChainPollInterval is how often a held back job re-checks
+	// its predecessor's report.
+	batchJobChainPollInterval = 15 * time.Second
+)
+
+// BatchJobChain declares that a job must not start before another job
+// has finished, with a policy for the predecessor failing.
+type BatchJobChain struct {
+	After     string `yaml:"after" json:"after"`
+	OnFailure string `yaml:"onFailure" json:"onFailure"`
+}
+
+//msgp:ignore batchJobChainError
+type batchJobChainError struct {
+	Code           string
+	Description    string
+	HTTPStatusCode int
+}
+
+func (e batchJobChainError) Error() string {
+	return e.Description
+}
+
+// batchJobRequestByID returns a minimal BatchJobRequest for jobID with
+// the job type stub populated from the ID prefix, enough to load the
+// job's report.
+func batchJobRequestByID(jobID string) (BatchJobRequest, error) {
+	req := BatchJobRequest{ID: jobID}
+	if i := strings.Index(jobID, "-"); i > 0 {
+		switch madmin.BatchJobType(jobID[:i]) {
+		case madmin.BatchJobReplicate:
+			req.Replicate = &BatchJobReplicateV1{}
+		case madmin.BatchJobKeyRotate:
+			req.KeyRotate = &BatchJobKeyRotateV1{}
+		case madmin.BatchJobExpire:
+			req.Expire = &BatchJobExpire{}
+		case batchJobTypeCopy:
+			req.Copy = &BatchJobCopyV1{}
+		case batchJobTypeVerify:
+			req.Verify = &BatchJobVerifyV1{}
+		case batchJobTypeRetag:
+			req.Retag = &BatchJobRetagV1{}
+		case batchJobTypeMPCleanup:
+			req.MPCleanup = &BatchJobMPCleanupV1{}
+		case batchJobTypeExport:
+			req.Export = &BatchJobExportV1{}
+		default:
+			return req, errors.New("job ID format unrecognized")
+		}
+	}
+	return req, nil
+}
+
+// Validate validates the chain declaration and verifies the
+// predecessor is known - either still queued or already reporting.
+func (c *BatchJobChain) Validate(ctx context.Context, job BatchJobRequest, o ObjectLayer) error {
+	if c == nil {
+		return nil
+	}
+
+	if c.After == "" {
+		return batchJobChainError{
+			Code:           "InvalidRequest",
+			Description:    "A job chain must name the job it runs after",
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	}
+
+	switch c.OnFailure {
+	case "", batchJobChainOnFailureStop, batchJobChainOnFailureContinue:
+	default:
+		return batchJobChainError{
+			Code:           "InvalidRequest",
+			Description:    fmt.Sprintf("Unknown chain failure policy %q", c.OnFailure),
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	}
+
+	pred, err := batchJobRequestByID(c.After)
+	if err != nil {
+		return batchJobChainError{
+			Code:           "InvalidRequest",
+			Description:    fmt.Sprintf("Invalid predecessor job ID %q", c.After),
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	}
+
+	// The predecessor is known when its definition is still queued or
+	// its report already exists.
+	ri := &batchJobInfo{}
+	if err := ri.load(ctx, o, pred); err == nil {
+		return nil
+	}
+	preq := &BatchJobRequest{}
+	if err := preq.load(ctx, o, pathJoin(batchJobPrefix, c.After)); err != nil {
+		if errors.Is(err, errNoSuchJob) {
+			return batchJobChainError{
+				Code:           "NoSuchPredecessorJob",
+				Description:    fmt.Sprintf("The chained job %s does not exist", c.After),
+				HTTPStatusCode: http.StatusNotFound,
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// predecessorStatus reports whether the job this job is chained after
+// allows it to run. waiting is true while the predecessor is still
+// running, run is valid once waiting is false.
+func (j *BatchJobPool) predecessorStatus(job *BatchJobRequest) (run, waiting bool) {
+	after := job.Chain.After
+	pred, err := batchJobRequestByID(after)
+	if err != nil {
+		batchLogIf(j.ctx, err)
+		return false, false
+	}
+
+	ri := &batchJobInfo{}
+	err = ri.load(j.ctx, j.objLayer, pred)
+	switch {
+	case err == nil:
+		if ri.Complete {
+			return true, false
+		}
+		if ri.Failed {
+			if job.Chain.OnFailure == batchJobChainOnFailureContinue {
+				return true, false
+			}
+			batchLogIf(j.ctx, fmt.Errorf("job %s skipped: chained job %s failed", job.ID, after))
+			return false, false
+		}
+		// Predecessor still running, keep waiting.
+	case errors.Is(err, errNoSuchJob):
+		// The predecessor reports only from its first checkpoint. As
+		// long as its definition is still queued keep waiting; when
+		// both report and definition are gone it finished long ago
+		// and its report was cleaned up.
+		preq := &BatchJobRequest{}
+		if perr := preq.load(j.ctx, j.objLayer, pathJoin(batchJobPrefix, after)); perr != nil {
+			if errors.Is(perr, errNoSuchJob) {
+				return true, false
+			}
+			batchLogIf(j.ctx, perr)
+		}
+	default:
+		batchLogIf(j.ctx, err)
+	}
+	return false, true
+}
+
+// watchPredecessor waits for the predecessor off the worker pool and
+// requeues the chained job once it may run, skipped jobs are canceled
+// in place. Waiting this way keeps workers free for runnable jobs - a
+// chain queued ahead of its predecessor could otherwise occupy every
+// worker and deadlock the pool.
+func (j *BatchJobPool) watchPredecessor(job *BatchJobRequest) {
+	t := time.NewTicker(batchJobChainPollInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-job.ctx.Done():
+			j.canceler(job.ID, false)
+			return
+		case <-t.C:
+		}
+		run, waiting := j.predecessorStatus(job)
+		if waiting {
+			continue
+		}
+		if !run {
+			j.canceler(job.ID, false)
+			return
+		}
+		select {
+		case <-j.ctx.Done():
+			return
+		case j.jobCh <- job:
+			return
+		default:
+			// Queue momentarily full, requeue on the next tick.
+		}
+	}
+}
@@ -0,0 +1,468 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/minio-go/v7/pkg/tags"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/workers"
+)
+
+// copy:
+//   apiVersion: v1
+//   source:
+//     bucket: BUCKET
+//     prefix: PREFIX # 'prefix' is re-written to target 'prefix'
+//   target:
+//     bucket: BUCKET
+//     prefix: PREFIX
+//     storageClass: "STANDARD" # optional storage class override
+//     kmskeyid: "key-id" # optional, re-encrypt copies with this SSE-KMS key
+//     tags: # optional, replaces object tags on the copies
+//       - key: "environment"
+//         value: "prod"
+//     metadata: # optional, replaces user metadata on the copies
+//       - key: "x-amz-meta-team"
+//         value: "storage"
+// # optional flags based filtering criteria
+// # for all objects
+// flags:
+//   filter:
+//     newerThan: "7d" # match objects newer than this value (e.g. 7d10h31s)
+//     olderThan: "7d" # match objects older than this value (e.g. 7d10h31s)
+//     createdAfter: "date" # match objects created after "date"
+//     createdBefore: "date" # match objects created before "date"
+//     tags:
+//       - key: "name"
+//         value: "pick*" # match objects with tag 'name', with all values starting with 'pick'
+//     metadata:
+//       - key: "content-type"
+//         value: "image/*" # match objects with 'content-type', with all values starting with 'image/'
+//   notify:
+//     endpoint: "https://notify.endpoint" # notification endpoint to receive job status events
+//     token: "Bearer xxxxx" # optional authentication token for the notification endpoint
+//   retry:
+//     attempts: 10 # number of retries for the job before giving up
+//     delay: "500ms" # least amount of delay between each retry
+
+//go:generate msgp -file $GOFILE -unexported
+
+// batchJobTypeCopy denotes the intra-cluster copy job type.
+const batchJobTypeCopy = madmin.BatchJobType("copy")
+
+// BatchJobCopyResource describes the source of an intra-cluster copy job.
+type BatchJobCopyResource struct {
+	Bucket string `yaml:"bucket" json:"bucket"`
+	Prefix string `yaml:"prefix" json:"prefix"`
+}
+
+// BatchJobCopyTarget describes where and how copies are written. The
+// source prefix is rewritten to Prefix on each key; the remaining
+// fields optionally transform the copies in flight.
+type BatchJobCopyTarget struct {
+	Bucket       string       `yaml:"bucket" json:"bucket"`
+	Prefix       string       `yaml:"prefix" json:"prefix"`
+	StorageClass string       `yaml:"storageClass" json:"storageClass"`
+	KMSKeyID     string       `yaml:"kmskeyid" json:"kmskeyid"`
+	Tags         []BatchJobKV `yaml:"tags" json:"tags"`
+	Metadata     []BatchJobKV `yaml:"metadata" json:"metadata"`
+}
+
+// BatchJobCopyFlags various configurations for copy job definition currently includes
+// - filter
+// - notify
+// - retry
+type BatchJobCopyFlags struct {
+	Filter BatchReplicateFilter `yaml:"filter" json:"filter"`
+	Notify BatchJobNotification `yaml:"notify" json:"notify"`
+	Retry  BatchJobRetry        `yaml:"retry" json:"retry"`
+}
+
+// BatchJobCopyV1 v1 of batch copy job. Copies objects between local
+// buckets/prefixes server-side, with optional key rewriting, tag and
+// metadata replacement, storage class change and re-encryption,
+// replacing external `mc mirror` runs for intra-cluster moves.
+type BatchJobCopyV1 struct {
+	APIVersion string               `yaml:"apiVersion" json:"apiVersion"`
+	Flags      BatchJobCopyFlags    `yaml:"flags" json:"flags"`
+	Source     BatchJobCopyResource `yaml:"source" json:"source"`
+	Target     BatchJobCopyTarget   `yaml:"target" json:"target"`
+}
+
+// Notify notifies notification endpoint if configured regarding job failure or success.
+func (r BatchJobCopyV1) Notify(ctx context.Context, ri *batchJobInfo) error {
+	return notifyEndpoint(ctx, ri, r.Flags.Notify.Endpoint, r.Flags.Notify.Token)
+}
+
+// targetObject rewrites the source prefix of an object key to the
+// target prefix.
+func (r *BatchJobCopyV1) targetObject(object string) string {
+	return pathJoin(r.Target.Prefix, strings.TrimPrefix(object, r.Source.Prefix))
+}
+
+// CopyObject copies a single object to the target through the S3 layer
+// so that transformation - metadata and tag replacement, storage class
+// change, re-encryption - goes through the same code paths a client
+// driven server-side copy would.
+func (r *BatchJobCopyV1) CopyObject(ctx context.Context, api ObjectLayer, clnt *miniogo.Core, objInfo ObjectInfo) error {
+	if objInfo.DeleteMarker || !objInfo.VersionPurgeStatus.Empty() {
+		return nil
+	}
+
+	tgtObject := r.targetObject(objInfo.Name)
+	if err := enforceBucketQuotaHard(ctx, r.Target.Bucket, objInfo.Size); err != nil {
+		return err
+	}
+
+	srcOpts := miniogo.CopySrcOptions{
+		Bucket:    r.Source.Bucket,
+		Object:    objInfo.Name,
+		VersionID: objInfo.VersionID,
+	}
+	dstOpts := miniogo.PutObjectOptions{
+		StorageClass: r.Target.StorageClass,
+	}
+	if r.Target.KMSKeyID != "" {
+		sse, err := encrypt.NewSSEKMS(r.Target.KMSKeyID, nil)
+		if err != nil {
+			return err
+		}
+		dstOpts.ServerSideEncryption = sse
+	}
+	if len(r.Target.Tags) > 0 {
+		tagMap := make(map[string]string, len(r.Target.Tags))
+		for _, kv := range r.Target.Tags {
+			tagMap[kv.Key] = kv.Value
+		}
+		t, err := tags.NewTags(tagMap, true)
+		if err != nil {
+			return err
+		}
+		dstOpts.UserTags = t.ToMap()
+	}
+
+	// When no metadata replacement is requested the copy retains the
+	// source metadata, mirroring x-amz-metadata-directive: COPY.
+	var metadata map[string]string
+	if len(r.Target.Metadata) > 0 {
+		metadata = make(map[string]string, len(r.Target.Metadata))
+		for _, kv := range r.Target.Metadata {
+			metadata[kv.Key] = kv.Value
+		}
+		metadata["x-amz-metadata-directive"] = "REPLACE"
+	}
+
+	_, err := clnt.CopyObject(ctx, r.Source.Bucket, objInfo.Name, r.Target.Bucket, tgtObject, metadata, srcOpts, dstOpts)
+	return err
+}
+
+const (
+	batchCopyName               = "batch-copy.bin"
+	batchCopyFormat             = 1
+	batchCopyVersionV1          = 1
+	batchCopyVersion            = batchCopyVersionV1
+	batchCopyAPIVersion         = "v1"
+	batchCopyJobDefaultRetries  = 3
+	batchCopyJobDefaultRetryDel = 250 * time.Millisecond
+)
+
+// Start the batch copy job, resumes from the last checkpointed object
+// if there was a pending job via "job.ID"
+func (r *BatchJobCopyV1) Start(ctx context.Context, api ObjectLayer, job BatchJobRequest) error {
+	ri := &batchJobInfo{
+		JobID:     job.ID,
+		JobType:   string(job.Type()),
+		StartTime: job.Started,
+	}
+	if err := ri.loadOrInit(ctx, api, job); err != nil {
+		return err
+	}
+	if ri.Complete {
+		return nil
+	}
+
+	globalBatchJobsMetrics.save(job.ID, ri)
+	lastObject := ri.Object
+
+	retryAttempts := r.Flags.Retry.Attempts
+	if retryAttempts <= 0 {
+		retryAttempts = batchCopyJobDefaultRetries
+	}
+	delay := r.Flags.Retry.Delay
+	if delay <= 0 {
+		delay = batchCopyJobDefaultRetryDel
+	}
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	selectObj := func(info FileInfo) (ok bool) {
+		if r.Flags.Filter.OlderThan > 0 && time.Since(info.ModTime) < r.Flags.Filter.OlderThan.D() {
+			// skip all objects that are newer than specified older duration
+			return false
+		}
+
+		if r.Flags.Filter.NewerThan > 0 && time.Since(info.ModTime) >= r.Flags.Filter.NewerThan.D() {
+			// skip all objects that are older than specified newer duration
+			return false
+		}
+
+		if !r.Flags.Filter.CreatedAfter.IsZero() && r.Flags.Filter.CreatedAfter.Before(info.ModTime) {
+			// skip all objects that are created before the specified time.
+			return false
+		}
+
+		if !r.Flags.Filter.CreatedBefore.IsZero() && r.Flags.Filter.CreatedBefore.After(info.ModTime) {
+			// skip all objects that are created after the specified time.
+			return false
+		}
+
+		if len(r.Flags.Filter.Tags) > 0 {
+			// Only parse object tags if tags filter is specified.
+			tagMap := map[string]string{}
+			tagStr := info.Metadata[xhttp.AmzObjectTagging]
+			if len(tagStr) != 0 {
+				t, err := tags.ParseObjectTags(tagStr)
+				if err != nil {
+					return false
+				}
+				tagMap = t.ToMap()
+			}
+
+			for _, kv := range r.Flags.Filter.Tags {
+				for t, v := range tagMap {
+					if kv.Match(BatchJobKV{Key: t, Value: v}) {
+						return true
+					}
+				}
+			}
+
+			// None of the provided tags filter match skip the object
+			return false
+		}
+
+		if len(r.Flags.Filter.Metadata) > 0 {
+			for _, kv := range r.Flags.Filter.Metadata {
+				for k, v := range info.Metadata {
+					if !stringsHasPrefixFold(k, "x-amz-meta-") && !isStandardHeader(k) {
+						continue
+					}
+					// We only need to match x-amz-meta or standardHeaders
+					if kv.Match(BatchJobKV{Key: k, Value: v}) {
+						return true
+					}
+				}
+			}
+
+			// None of the provided metadata filters match skip the object.
+			return false
+		}
+
+		// Copy only the latest version, delete markers are skipped on copy.
+		return info.IsLatest
+	}
+
+	// The copy goes through the local S3 port so transformations reuse
+	// the server-side copy handler code paths.
+	clnt, err := miniogo.NewCore(fmt.Sprintf("127.0.0.1:%s", globalMinioPort), &miniogo.Options{
+		Creds:     credentials.NewStaticV4(globalActiveCred.AccessKey, globalActiveCred.SecretKey, ""),
+		Secure:    globalIsTLS,
+		Transport: getRemoteInstanceTransport(),
+	})
+	if err != nil {
+		return err
+	}
+	clnt.SetAppInfo("minio-"+batchJobPrefix, r.APIVersion+" "+job.ID)
+
+	workerSize, err := strconv.Atoi(env.Get("_MINIO_BATCH_COPY_WORKERS", strconv.Itoa(runtime.GOMAXPROCS(0)/2)))
+	if err != nil {
+		return err
+	}
+
+	wk, err := workers.New(workerSize)
+	if err != nil {
+		// invalid worker size.
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	if err := api.Walk(ctx, r.Source.Bucket, r.Source.Prefix, results, WalkOptions{
+		Marker: lastObject,
+		Filter: selectObj,
+	}); err != nil {
+		cancel()
+		// Do not need to retry if we can't list objects on source.
+		return err
+	}
+	failed := false
+	for res := range results {
+		if res.Err != nil {
+			failed = true
+			batchLogIf(ctx, res.Err)
+			break
+		}
+		result := res.Item
+		wk.Take()
+		go func() {
+			defer wk.Give()
+			for attempts := 1; attempts <= retryAttempts; attempts++ {
+				stopFn := globalBatchJobsMetrics.trace(batchJobMetricCopy, job.ID, attempts)
+				success := true
+				if err := r.CopyObject(ctx, api, clnt, result); err != nil {
+					stopFn(result, err)
+					batchLogIf(ctx, err)
+					success = false
+					if attempts >= retryAttempts {
+						auditOptions := AuditLogOptions{
+							Event:     "Copy",
+							APIName:   "StartBatchJob",
+							Bucket:    result.Bucket,
+							Object:    result.Name,
+							VersionID: result.VersionID,
+							Error:     err.Error(),
+						}
+						auditLogInternal(ctx, auditOptions)
+					}
+				} else {
+					stopFn(result, nil)
+				}
+				ri.trackCurrentBucketObject(r.Source.Bucket, result, success, attempts)
+				globalBatchJobsMetrics.save(job.ID, ri)
+				// persist in-memory state to disk after every 10secs.
+				batchLogIf(ctx, ri.updateAfter(ctx, api, 10*time.Second, job))
+				if success {
+					break
+				}
+				if delay > 0 {
+					time.Sleep(delay + time.Duration(rnd.Float64()*float64(delay)))
+				}
+			}
+		}()
+	}
+	wk.Wait()
+
+	ri.Complete = !failed && ri.ObjectsFailed == 0
+	ri.Failed = failed || ri.ObjectsFailed > 0
+	globalBatchJobsMetrics.save(job.ID, ri)
+	// persist in-memory state to disk.
+	batchLogIf(ctx, ri.updateAfter(ctx, api, 0, job))
+
+	if err := r.Notify(ctx, ri); err != nil {
+		batchLogIf(ctx, fmt.Errorf("unable to notify %v", err))
+	}
+
+	cancel()
+	return nil
+}
+
+//msgp:ignore batchCopyJobError
+type batchCopyJobError struct {
+	Code           string
+	Description    string
+	HTTPStatusCode int
+}
+
+func (e batchCopyJobError) Error() string {
+	return e.Description
+}
+
+// Validate validates the job definition input
+func (r *BatchJobCopyV1) Validate(ctx context.Context, job BatchJobRequest, o ObjectLayer) error {
+	if r == nil {
+		return nil
+	}
+
+	if r.APIVersion != batchCopyAPIVersion {
+		return errInvalidArgument
+	}
+
+	if r.Source.Bucket == "" || r.Target.Bucket == "" {
+		return errInvalidArgument
+	}
+
+	if r.Source.Bucket == r.Target.Bucket && r.Source.Prefix == r.Target.Prefix {
+		return batchCopyJobError{
+			Code:           "InvalidRequest",
+			Description:    "The source and target of a copy job cannot be identical",
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if _, err := o.GetBucketInfo(ctx, r.Source.Bucket, BucketOptions{}); err != nil {
+		if isErrBucketNotFound(err) {
+			return batchCopyJobError{
+				Code:           "NoSuchSourceBucket",
+				Description:    fmt.Sprintf("The specified bucket %s does not exist", r.Source.Bucket),
+				HTTPStatusCode: http.StatusNotFound,
+			}
+		}
+		return err
+	}
+
+	if _, err := o.GetBucketInfo(ctx, r.Target.Bucket, BucketOptions{}); err != nil {
+		if isErrBucketNotFound(err) {
+			return batchCopyJobError{
+				Code:           "NoSuchTargetBucket",
+				Description:    fmt.Sprintf("The specified bucket %s does not exist", r.Target.Bucket),
+				HTTPStatusCode: http.StatusNotFound,
+			}
+		}
+		return err
+	}
+
+	for _, tag := range r.Flags.Filter.Tags {
+		if err := tag.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, meta := range r.Flags.Filter.Metadata {
+		if err := meta.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, tag := range r.Target.Tags {
+		if err := tag.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, meta := range r.Target.Metadata {
+		if err := meta.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return r.Flags.Retry.Validate()
+}
@@ -0,0 +1,548 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"github.com/minio/minio/internal/hash"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/workers"
+)
+
+// export:
+//   apiVersion: v1
+//   bucket: BUCKET
+//   prefix: PREFIX
+//   target:
+//     bucket: BUCKET # bucket receiving the export archive, required
+//     prefix: PREFIX # defaults to ".legal-exports"
+// # optional flags based filtering criteria
+// # for all object versions
+// flags:
+//   filter:
+//     newerThan: "7d" # match objects newer than this value (e.g. 7d10h31s)
+//     olderThan: "7d" # match objects older than this value (e.g. 7d10h31s)
+//     createdAfter: "date" # match objects created after "date"
+//     createdBefore: "date" # match objects created before "date"
+//     tags:
+//       - key: "legal-hold"
+//         value: "case-*" # match objects with tag 'legal-hold', with all values starting with 'case-'
+//     metadata:
+//       - key: "x-amz-meta-owner"
+//         value: "alice@*" # match objects with metadata 'owner', with all values starting with 'alice@'
+//   notify:
+//     endpoint: "https://notify.endpoint" # notification endpoint to receive job status events
+//     token: "Bearer xxxxx" # optional authentication token for the notification endpoint
+//   retry:
+//     attempts: 10 # number of retries for the job before giving up
+//     delay: "500ms" # least amount of delay between each retry
+
+//go:generate msgp -file $GOFILE -unexported
+
+// batchJobTypeExport denotes the legal discovery export job type.
+const batchJobTypeExport = madmin.BatchJobType("export")
+
+// BatchJobExportTarget describes where the export archive is written.
+type BatchJobExportTarget struct {
+	Bucket string `yaml:"bucket" json:"bucket"`
+	Prefix string `yaml:"prefix" json:"prefix"`
+}
+
+// BatchJobExportFlags various configurations for export job definition currently includes
+// - filter
+// - notify
+// - retry
+type BatchJobExportFlags struct {
+	Filter BatchReplicateFilter `yaml:"filter" json:"filter"`
+	Notify BatchJobNotification `yaml:"notify" json:"notify"`
+	Retry  BatchJobRetry        `yaml:"retry" json:"retry"`
+}
+
+// BatchJobExportV1 v1 of batch export job. Copies every matching object
+// version - not just the latest - into a self-contained archive prefix
+// in the target bucket along with the version's full user metadata,
+// tags and object lock state, and writes a signed manifest recording a
+// SHA-256 of each exported copy. Intended for legal discovery requests
+// where the produced archive must be portable and tamper-evident.
+type BatchJobExportV1 struct {
+	APIVersion string               `yaml:"apiVersion" json:"apiVersion"`
+	Flags      BatchJobExportFlags  `yaml:"flags" json:"flags"`
+	Bucket     string               `yaml:"bucket" json:"bucket"`
+	Prefix     string               `yaml:"prefix" json:"prefix"`
+	Target     BatchJobExportTarget `yaml:"target" json:"target"`
+}
+
+// Notify notifies notification endpoint if configured regarding job failure or success.
+func (r BatchJobExportV1) Notify(ctx context.Context, ri *batchJobInfo) error {
+	return notifyEndpoint(ctx, ri, r.Flags.Notify.Endpoint, r.Flags.Notify.Token)
+}
+
+//msgp:ignore batchExportEntry
+type batchExportEntry struct {
+	Object       string            `json:"object"`
+	VersionID    string            `json:"versionId,omitempty"`
+	DeleteMarker bool              `json:"deleteMarker,omitempty"`
+	Size         int64             `json:"size"`
+	ETag         string            `json:"etag,omitempty"`
+	ModTime      time.Time         `json:"modTime"`
+	SHA256       string            `json:"sha256,omitempty"`
+	ExportedAs   string            `json:"exportedAs,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+//msgp:ignore batchExportManifest
+type batchExportManifest struct {
+	Version     string             `json:"version"`
+	JobID       string             `json:"jobId"`
+	Bucket      string             `json:"bucket"`
+	Prefix      string             `json:"prefix"`
+	AuditEvent  string             `json:"auditEvent"`
+	StartedAt   time.Time          `json:"startedAt"`
+	CompletedAt time.Time          `json:"completedAt"`
+	Exported    int64              `json:"exported"`
+	Failed      int64              `json:"failed"`
+	Entries     []batchExportEntry `json:"entries"`
+}
+
+// batchExportAuditEvent correlates exported versions with the audit
+// log, every export writes one audit entry under this event name.
+const batchExportAuditEvent = "LegalExport"
+
+// exportBase returns the archive prefix in the target bucket all of
+// this job's output is written under.
+func (r *BatchJobExportV1) exportBase(jobID string) string {
+	prefix := r.Target.Prefix
+	if prefix == "" {
+		prefix = ".legal-exports"
+	}
+	return pathJoin(prefix, "export-"+jobID)
+}
+
+// ExportObject copies one object version into the archive and returns
+// its manifest entry. Delete markers are recorded in the manifest but
+// carry no data to copy. Encrypted versions are exported in their
+// stored form together with their key metadata, the archive copy is a
+// faithful replica of what the cluster holds.
+func (r *BatchJobExportV1) ExportObject(ctx context.Context, api ObjectLayer, objInfo ObjectInfo, jobID string) (batchExportEntry, error) {
+	entry := batchExportEntry{
+		Object:       objInfo.Name,
+		VersionID:    objInfo.VersionID,
+		DeleteMarker: objInfo.DeleteMarker,
+		Size:         objInfo.Size,
+		ETag:         objInfo.ETag,
+		ModTime:      objInfo.ModTime,
+		Metadata:     objInfo.UserDefined,
+	}
+	if objInfo.DeleteMarker {
+		return entry, nil
+	}
+
+	gr, err := api.GetObjectNInfo(ctx, r.Bucket, objInfo.Name, nil, http.Header{}, ObjectOptions{
+		VersionID: objInfo.VersionID,
+		NoLock:    true,
+	})
+	if err != nil {
+		return entry, err
+	}
+	defer gr.Close()
+
+	versionID := objInfo.VersionID
+	if versionID == "" {
+		versionID = nullVersionID
+	}
+	exportedAs := pathJoin(r.exportBase(jobID), "data", objInfo.Name, versionID)
+
+	sum := sha256.New()
+	hr, err := hash.NewReader(ctx, io.TeeReader(gr, sum), objInfo.Size, "", "", objInfo.Size)
+	if err != nil {
+		return entry, err
+	}
+
+	// The archive copy keeps the source version's metadata verbatim -
+	// tags, object lock state and encryption headers included - with
+	// provenance added on top.
+	meta := make(map[string]string, len(objInfo.UserDefined)+2)
+	for k, v := range objInfo.UserDefined {
+		meta[k] = v
+	}
+	meta["X-Amz-Meta-Export-Job"] = jobID
+	meta["X-Amz-Meta-Export-Source"] = pathJoin(r.Bucket, objInfo.Name)
+
+	if _, err := api.PutObject(ctx, r.Target.Bucket, exportedAs, NewPutObjReader(hr), ObjectOptions{
+		UserDefined: meta,
+	}); err != nil {
+		return entry, err
+	}
+
+	entry.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	entry.ExportedAs = exportedAs
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:     batchExportAuditEvent,
+		APIName:   "StartBatchJob",
+		Bucket:    r.Bucket,
+		Object:    objInfo.Name,
+		VersionID: objInfo.VersionID,
+		Tags: map[string]string{
+			"jobId":      jobID,
+			"exportedAs": pathJoin(r.Target.Bucket, exportedAs),
+		},
+	})
+	return entry, nil
+}
+
+// writeManifest serializes, signs and stores the export manifest at the
+// root of the archive prefix. Like the verify job's manifest, the
+// HMAC-SHA256 over the manifest bytes is keyed with the cluster root
+// credentials and stored as user metadata, making later edits to the
+// manifest detectable.
+func (r *BatchJobExportV1) writeManifest(ctx context.Context, api ObjectLayer, m batchExportManifest, jobID string) error {
+	data, err := json.MarshalIndent(m, "", " ")
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, []byte(globalActiveCred.SecretKey))
+	mac.Write(data)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	object := pathJoin(r.exportBase(jobID), "manifest.json")
+	hr, err := hash.NewReader(ctx, bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)))
+	if err != nil {
+		return err
+	}
+	_, err = api.PutObject(ctx, r.Target.Bucket, object, NewPutObjReader(hr), ObjectOptions{
+		UserDefined: map[string]string{
+			xhttp.ContentType:               "application/json",
+			"X-Amz-Meta-Manifest-Signature": signature,
+			"X-Amz-Meta-Manifest-Signed-By": "hmac-sha256-root-credentials",
+		},
+	})
+	return err
+}
+
+const (
+	batchExportName               = "batch-export.bin"
+	batchExportFormat             = 1
+	batchExportVersionV1          = 1
+	batchExportVersion            = batchExportVersionV1
+	batchExportAPIVersion         = "v1"
+	batchExportJobDefaultRetries  = 3
+	batchExportJobDefaultRetryDel = 250 * time.Millisecond
+)
+
+// Start the batch export job, resumes from the last checkpointed
+// object if there was a pending job via "job.ID". Note that on resume
+// the manifest covers only the remainder of the walk.
+func (r *BatchJobExportV1) Start(ctx context.Context, api ObjectLayer, job BatchJobRequest) error {
+	ri := &batchJobInfo{
+		JobID:     job.ID,
+		JobType:   string(job.Type()),
+		StartTime: job.Started,
+	}
+	if err := ri.loadOrInit(ctx, api, job); err != nil {
+		return err
+	}
+	if ri.Complete {
+		return nil
+	}
+
+	globalBatchJobsMetrics.save(job.ID, ri)
+	lastObject := ri.Object
+
+	retryAttempts := r.Flags.Retry.Attempts
+	if retryAttempts <= 0 {
+		retryAttempts = batchExportJobDefaultRetries
+	}
+	delay := r.Flags.Retry.Delay
+	if delay <= 0 {
+		delay = batchExportJobDefaultRetryDel
+	}
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	selectObj := func(info FileInfo) (ok bool) {
+		if r.Flags.Filter.OlderThan > 0 && time.Since(info.ModTime) < r.Flags.Filter.OlderThan.D() {
+			// skip all objects that are newer than specified older duration
+			return false
+		}
+
+		if r.Flags.Filter.NewerThan > 0 && time.Since(info.ModTime) >= r.Flags.Filter.NewerThan.D() {
+			// skip all objects that are older than specified newer duration
+			return false
+		}
+
+		if !r.Flags.Filter.CreatedAfter.IsZero() && r.Flags.Filter.CreatedAfter.Before(info.ModTime) {
+			// skip all objects that are created before the specified time.
+			return false
+		}
+
+		if !r.Flags.Filter.CreatedBefore.IsZero() && r.Flags.Filter.CreatedBefore.After(info.ModTime) {
+			// skip all objects that are created after the specified time.
+			return false
+		}
+
+		if len(r.Flags.Filter.Tags) > 0 {
+			// Only parse object tags if tags filter is specified.
+			tagMap := map[string]string{}
+			tagStr := info.Metadata[xhttp.AmzObjectTagging]
+			if len(tagStr) != 0 {
+				t, err := tags.ParseObjectTags(tagStr)
+				if err != nil {
+					return false
+				}
+				tagMap = t.ToMap()
+			}
+
+			for _, kv := range r.Flags.Filter.Tags {
+				for t, v := range tagMap {
+					if kv.Match(BatchJobKV{Key: t, Value: v}) {
+						return true
+					}
+				}
+			}
+
+			// None of the provided tags filter match skip the object
+			return false
+		}
+
+		if len(r.Flags.Filter.Metadata) > 0 {
+			for _, kv := range r.Flags.Filter.Metadata {
+				for k, v := range info.Metadata {
+					if !stringsHasPrefixFold(k, "x-amz-meta-") && !isStandardHeader(k) {
+						continue
+					}
+					// We only need to match x-amz-meta or standardHeaders
+					if kv.Match(BatchJobKV{Key: k, Value: v}) {
+						return true
+					}
+				}
+			}
+
+			// None of the provided metadata filters match skip the object.
+			return false
+		}
+
+		// Export every matching version, legal discovery wants the full
+		// history including delete markers.
+		return true
+	}
+
+	workerSize, err := strconv.Atoi(env.Get("_MINIO_BATCH_EXPORT_WORKERS", strconv.Itoa(runtime.GOMAXPROCS(0)/2)))
+	if err != nil {
+		return err
+	}
+
+	wk, err := workers.New(workerSize)
+	if err != nil {
+		// invalid worker size.
+		return err
+	}
+
+	manifest := batchExportManifest{
+		Version:    batchExportAPIVersion,
+		JobID:      job.ID,
+		Bucket:     r.Bucket,
+		Prefix:     r.Prefix,
+		AuditEvent: batchExportAuditEvent,
+		StartedAt:  UTCNow(),
+	}
+	var manifestMu sync.Mutex
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	if err := api.Walk(ctx, r.Bucket, r.Prefix, results, WalkOptions{
+		Marker: lastObject,
+		Filter: selectObj,
+	}); err != nil {
+		cancel()
+		// Do not need to retry if we can't list objects on source.
+		return err
+	}
+	failed := false
+	for res := range results {
+		if res.Err != nil {
+			failed = true
+			batchLogIf(ctx, res.Err)
+			break
+		}
+		result := res.Item
+		if !result.VersionPurgeStatus.Empty() {
+			continue
+		}
+		wk.Take()
+		go func() {
+			defer wk.Give()
+			var entry batchExportEntry
+			var exportErr error
+			for attempts := 1; attempts <= retryAttempts; attempts++ {
+				stopFn := globalBatchJobsMetrics.trace(batchJobMetricExport, job.ID, attempts)
+				success := true
+				if entry, exportErr = r.ExportObject(ctx, api, result, job.ID); exportErr != nil {
+					stopFn(result, exportErr)
+					batchLogIf(ctx, exportErr)
+					success = false
+					if attempts >= retryAttempts {
+						auditOptions := AuditLogOptions{
+							Event:     batchExportAuditEvent,
+							APIName:   "StartBatchJob",
+							Bucket:    result.Bucket,
+							Object:    result.Name,
+							VersionID: result.VersionID,
+							Error:     exportErr.Error(),
+						}
+						auditLogInternal(ctx, auditOptions)
+					}
+				} else {
+					stopFn(result, nil)
+				}
+				ri.trackCurrentBucketObject(r.Bucket, result, success, attempts)
+				globalBatchJobsMetrics.save(job.ID, ri)
+				// persist in-memory state to disk after every 10secs.
+				batchLogIf(ctx, ri.updateAfter(ctx, api, 10*time.Second, job))
+				if success {
+					break
+				}
+				if delay > 0 {
+					time.Sleep(delay + time.Duration(rnd.Float64()*float64(delay)))
+				}
+			}
+
+			if exportErr != nil {
+				entry.Error = exportErr.Error()
+			}
+			manifestMu.Lock()
+			if exportErr == nil {
+				manifest.Exported++
+			} else {
+				manifest.Failed++
+			}
+			manifest.Entries = append(manifest.Entries, entry)
+			manifestMu.Unlock()
+		}()
+	}
+	wk.Wait()
+
+	manifest.CompletedAt = UTCNow()
+	if err := r.writeManifest(ctx, api, manifest, job.ID); err != nil {
+		failed = true
+		batchLogIf(ctx, fmt.Errorf("unable to write export manifest: %w", err))
+	}
+
+	ri.Complete = !failed && ri.ObjectsFailed == 0
+	ri.Failed = failed || ri.ObjectsFailed > 0
+	globalBatchJobsMetrics.save(job.ID, ri)
+	// persist in-memory state to disk.
+	batchLogIf(ctx, ri.updateAfter(ctx, api, 0, job))
+
+	if err := r.Notify(ctx, ri); err != nil {
+		batchLogIf(ctx, fmt.Errorf("unable to notify %v", err))
+	}
+
+	cancel()
+	return nil
+}
+
+//msgp:ignore batchExportJobError
+type batchExportJobError struct {
+	Code           string
+	Description    string
+	HTTPStatusCode int
+}
+
+func (e batchExportJobError) Error() string {
+	return e.Description
+}
+
+// Validate validates the job definition input
+func (r *BatchJobExportV1) Validate(ctx context.Context, job BatchJobRequest, o ObjectLayer) error {
+	if r == nil {
+		return nil
+	}
+
+	if r.APIVersion != batchExportAPIVersion {
+		return errInvalidArgument
+	}
+
+	if r.Bucket == "" {
+		return errInvalidArgument
+	}
+
+	if r.Target.Bucket == "" {
+		return batchExportJobError{
+			Code:           "InvalidRequest",
+			Description:    "An export job must name the target bucket receiving the archive",
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if _, err := o.GetBucketInfo(ctx, r.Bucket, BucketOptions{}); err != nil {
+		if isErrBucketNotFound(err) {
+			return batchExportJobError{
+				Code:           "NoSuchSourceBucket",
+				Description:    fmt.Sprintf("The specified bucket %s does not exist", r.Bucket),
+				HTTPStatusCode: http.StatusNotFound,
+			}
+		}
+		return err
+	}
+
+	if _, err := o.GetBucketInfo(ctx, r.Target.Bucket, BucketOptions{}); err != nil {
+		if isErrBucketNotFound(err) {
+			return batchExportJobError{
+				Code:           "NoSuchTargetBucket",
+				Description:    fmt.Sprintf("The specified bucket %s does not exist", r.Target.Bucket),
+				HTTPStatusCode: http.StatusNotFound,
+			}
+		}
+		return err
+	}
+
+	for _, tag := range r.Flags.Filter.Tags {
+		if err := tag.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, meta := range r.Flags.Filter.Metadata {
+		if err := meta.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return r.Flags.Retry.Validate()
+}
@@ -71,6 +71,12 @@ type BatchJobRequest struct {
 	Replicate *BatchJobReplicateV1 `yaml:"replicate" json:"replicate"`
 	KeyRotate *BatchJobKeyRotateV1 `yaml:"keyrotate" json:"keyrotate"`
 	Expire    *BatchJobExpire      `yaml:"expire" json:"expire"`
+	Copy      *BatchJobCopyV1      `yaml:"copy" json:"copy"`
+	Verify    *BatchJobVerifyV1    `yaml:"verify" json:"verify"`
+	Retag     *BatchJobRetagV1     `yaml:"retag" json:"retag"`
+	MPCleanup *BatchJobMPCleanupV1 `yaml:"mpcleanup" json:"mpcleanup"`
+	Export    *BatchJobExportV1    `yaml:"export" json:"export"`
+	Chain     *BatchJobChain       `yaml:"chain" json:"chain"`
 	ctx       context.Context      `msg:"-"`
 }
 
@@ -763,6 +769,16 @@ func (ri *batchJobInfo) getJobReportPath() (string, error) {
 		fileName = batchKeyRotationName
 	case madmin.BatchJobExpire:
 		fileName = batchExpireName
+	case batchJobTypeCopy:
+		fileName = batchCopyName
+	case batchJobTypeVerify:
+		fileName = batchVerifyName
+	case batchJobTypeRetag:
+		fileName = batchRetagName
+	case batchJobTypeMPCleanup:
+		fileName = batchMPCleanupName
+	case batchJobTypeExport:
+		fileName = batchExportName
 	default:
 		return "", fmt.Errorf("unknown job type: %v", ri.JobType)
 	}
@@ -779,6 +795,16 @@ func (ri *batchJobInfo) loadOrInit(ctx context.Context, api ObjectLayer, job Bat
 			ri.Version = batchKeyRotateVersionV1
 		case job.Expire != nil:
 			ri.Version = batchExpireVersionV1
+		case job.Copy != nil:
+			ri.Version = batchCopyVersionV1
+		case job.Verify != nil:
+			ri.Version = batchVerifyVersionV1
+		case job.Retag != nil:
+			ri.Version = batchRetagVersionV1
+		case job.MPCleanup != nil:
+			ri.Version = batchMPCleanupVersionV1
+		case job.Export != nil:
+			ri.Version = batchExportVersionV1
 		}
 		return nil
 	}
@@ -806,6 +832,21 @@ func (ri *batchJobInfo) loadByPath(ctx context.Context, api ObjectLayer, path st
 	case batchExpireName:
 		version = batchExpireVersionV1
 		format = batchExpireFormat
+	case batchCopyName:
+		version = batchCopyVersionV1
+		format = batchCopyFormat
+	case batchVerifyName:
+		version = batchVerifyVersionV1
+		format = batchVerifyFormat
+	case batchRetagName:
+		version = batchRetagVersionV1
+		format = batchRetagFormat
+	case batchMPCleanupName:
+		version = batchMPCleanupVersionV1
+		format = batchMPCleanupFormat
+	case batchExportName:
+		version = batchExportVersionV1
+		format = batchExportFormat
 	default:
 		return errors.New("no supported batch job request specified")
 	}
@@ -938,6 +979,31 @@ func (ri *batchJobInfo) updateAfter(ctx context.Context, api ObjectLayer, durati
 			version = batchExpireVersion
 			jobTyp = string(job.Type())
 			ri.Version = batchExpireVersionV1
+		case batchJobTypeCopy:
+			format = batchCopyFormat
+			version = batchCopyVersion
+			jobTyp = string(job.Type())
+			ri.Version = batchCopyVersionV1
+		case batchJobTypeVerify:
+			format = batchVerifyFormat
+			version = batchVerifyVersion
+			jobTyp = string(job.Type())
+			ri.Version = batchVerifyVersionV1
+		case batchJobTypeRetag:
+			format = batchRetagFormat
+			version = batchRetagVersion
+			jobTyp = string(job.Type())
+			ri.Version = batchRetagVersionV1
+		case batchJobTypeMPCleanup:
+			format = batchMPCleanupFormat
+			version = batchMPCleanupVersion
+			jobTyp = string(job.Type())
+			ri.Version = batchMPCleanupVersionV1
+		case batchJobTypeExport:
+			format = batchExportFormat
+			version = batchExportVersion
+			jobTyp = string(job.Type())
+			ri.Version = batchExportVersionV1
 		default:
 			return errInvalidArgument
 		}
@@ -1480,6 +1546,16 @@ func (j BatchJobRequest) Type() madmin.BatchJobType {
 		return madmin.BatchJobKeyRotate
 	case j.Expire != nil:
 		return madmin.BatchJobExpire
+	case j.Copy != nil:
+		return batchJobTypeCopy
+	case j.Verify != nil:
+		return batchJobTypeVerify
+	case j.Retag != nil:
+		return batchJobTypeRetag
+	case j.MPCleanup != nil:
+		return batchJobTypeMPCleanup
+	case j.Export != nil:
+		return batchJobTypeExport
 	}
 	return madmin.BatchJobType("unknown")
 }
@@ -1487,6 +1563,9 @@ func (j BatchJobRequest) Type() madmin.BatchJobType {
 // Validate validates the current job, used by 'save()' before
 // persisting the job request
 func (j BatchJobRequest) Validate(ctx context.Context, o ObjectLayer) error {
+	if err := j.Chain.Validate(ctx, j, o); err != nil {
+		return err
+	}
 	switch {
 	case j.Replicate != nil:
 		return j.Replicate.Validate(ctx, j, o)
@@ -1494,6 +1573,16 @@ func (j BatchJobRequest) Validate(ctx context.Context, o ObjectLayer) error {
 		return j.KeyRotate.Validate(ctx, j, o)
 	case j.Expire != nil:
 		return j.Expire.Validate(ctx, j, o)
+	case j.Copy != nil:
+		return j.Copy.Validate(ctx, j, o)
+	case j.Verify != nil:
+		return j.Verify.Validate(ctx, j, o)
+	case j.Retag != nil:
+		return j.Retag.Validate(ctx, j, o)
+	case j.MPCleanup != nil:
+		return j.MPCleanup.Validate(ctx, j, o)
+	case j.Export != nil:
+		return j.Export.Validate(ctx, j, o)
 	}
 	return errInvalidArgument
 }
@@ -1511,6 +1600,16 @@ func (j BatchJobRequest) getJobReportPath() (string, error) {
 		fileName = batchKeyRotationName
 	case j.Expire != nil:
 		fileName = batchExpireName
+	case j.Copy != nil:
+		fileName = batchCopyName
+	case j.Verify != nil:
+		fileName = batchVerifyName
+	case j.Retag != nil:
+		fileName = batchRetagName
+	case j.MPCleanup != nil:
+		fileName = batchMPCleanupName
+	case j.Export != nil:
+		fileName = batchExportName
 	default:
 		return "", errors.New("unknown job type")
 	}
@@ -1518,7 +1617,7 @@ func (j BatchJobRequest) getJobReportPath() (string, error) {
 }
 
 func (j *BatchJobRequest) save(ctx context.Context, api ObjectLayer) error {
-	if j.Replicate == nil && j.KeyRotate == nil && j.Expire == nil {
+	if j.Replicate == nil && j.KeyRotate == nil && j.Expire == nil && j.Copy == nil && j.Verify == nil && j.Retag == nil && j.MPCleanup == nil && j.Export == nil {
 		return errInvalidArgument
 	}
 
@@ -1634,19 +1733,10 @@ func (a adminAPIHandlers) BatchJobStatus(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	req := BatchJobRequest{ID: jobID}
-	if i := strings.Index(jobID, "-"); i > 0 {
-		switch madmin.BatchJobType(jobID[:i]) {
-		case madmin.BatchJobReplicate:
-			req.Replicate = &BatchJobReplicateV1{}
-		case madmin.BatchJobKeyRotate:
-			req.KeyRotate = &BatchJobKeyRotateV1{}
-		case madmin.BatchJobExpire:
-			req.Expire = &BatchJobExpire{}
-		default:
-			writeErrorResponseJSON(ctx, w, toAPIError(ctx, errors.New("job ID format unrecognized")), r.URL)
-			return
-		}
+	req, err := batchJobRequestByID(jobID)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
 	}
 
 	ri := &batchJobInfo{}
@@ -1658,7 +1748,19 @@ func (a adminAPIHandlers) BatchJobStatus(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	buf, err := json.Marshal(madmin.BatchJobStatus{LastMetric: ri.metric()})
+	// Extend the status with progress/ETA of jobs running on this node,
+	// older clients simply ignore the extra fields.
+	status := struct {
+		madmin.BatchJobStatus
+		Percent    float64 `json:"percent,omitempty"`
+		ETASeconds int64   `json:"etaSeconds,omitempty"`
+	}{BatchJobStatus: madmin.BatchJobStatus{LastMetric: ri.metric()}}
+	if p, ok := globalBatchJobProgress.get(jobID); ok {
+		status.Percent = p.Percent
+		status.ETASeconds = int64(p.ETA / time.Second)
+	}
+
+	buf, err := json.Marshal(&status)
 	if err != nil {
 		batchLogIf(ctx, err)
 		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
@@ -1705,6 +1807,31 @@ func (a adminAPIHandlers) DescribeBatchJob(w http.ResponseWriter, r *http.Reques
 	w.Write(buf)
 }
 
+// applyBatchJobDefaults fills the optional fields of a parsed job
+// request with their default values.
+func applyBatchJobDefaults(job *BatchJobRequest) {
+	if job.Replicate != nil {
+		if job.Replicate.Source.Snowball.Disable == nil {
+			job.Replicate.Source.Snowball.Disable = ptr(false)
+		}
+		if job.Replicate.Source.Snowball.Batch == nil {
+			job.Replicate.Source.Snowball.Batch = ptr(100)
+		}
+		if job.Replicate.Source.Snowball.InMemory == nil {
+			job.Replicate.Source.Snowball.InMemory = ptr(true)
+		}
+		if job.Replicate.Source.Snowball.Compress == nil {
+			job.Replicate.Source.Snowball.Compress = ptr(false)
+		}
+		if job.Replicate.Source.Snowball.SmallerThan == nil {
+			job.Replicate.Source.Snowball.SmallerThan = ptr("5MiB")
+		}
+		if job.Replicate.Source.Snowball.SkipErrs == nil {
+			job.Replicate.Source.Snowball.SkipErrs = ptr(true)
+		}
+	}
+}
+
 // StartBatchJob queue a new job for execution
 func (a adminAPIHandlers) StartBatchJob(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -1732,26 +1859,7 @@ func (a adminAPIHandlers) StartBatchJob(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Fill with default values
-	if job.Replicate != nil {
-		if job.Replicate.Source.Snowball.Disable == nil {
-			job.Replicate.Source.Snowball.Disable = ptr(false)
-		}
-		if job.Replicate.Source.Snowball.Batch == nil {
-			job.Replicate.Source.Snowball.Batch = ptr(100)
-		}
-		if job.Replicate.Source.Snowball.InMemory == nil {
-			job.Replicate.Source.Snowball.InMemory = ptr(true)
-		}
-		if job.Replicate.Source.Snowball.Compress == nil {
-			job.Replicate.Source.Snowball.Compress = ptr(false)
-		}
-		if job.Replicate.Source.Snowball.SmallerThan == nil {
-			job.Replicate.Source.Snowball.SmallerThan = ptr("5MiB")
-		}
-		if job.Replicate.Source.Snowball.SkipErrs == nil {
-			job.Replicate.Source.Snowball.SkipErrs = ptr(true)
-		}
-	}
+	applyBatchJobDefaults(job)
 
 	//  Validate the incoming job request
 	if err := job.Validate(ctx, objectAPI); err != nil {
@@ -1950,42 +2058,53 @@ func (j *BatchJobPool) AddWorker() {
 			if !ok {
 				return
 			}
+			if job.Chain != nil && job.Chain.After != "" {
+				run, waiting := j.predecessorStatus(job)
+				if waiting {
+					// Hold back chained jobs until their predecessor
+					// finishes, waiting off-worker so the chain cannot
+					// starve the pool.
+					go j.watchPredecessor(job)
+					continue
+				}
+				if !run {
+					j.canceler(job.ID, false)
+					continue
+				}
+			}
+			// Lifecycle webhooks and progress/ETA sampling run for the
+			// duration of the job.
+			stopMon := globalBatchJobProgress.monitor(job, j.objLayer)
+			var err error
 			switch {
 			case job.Replicate != nil:
 				if job.Replicate.RemoteToLocal() {
-					if err := job.Replicate.StartFromSource(job.ctx, j.objLayer, *job); err != nil {
-						if !isErrBucketNotFound(err) {
-							batchLogIf(j.ctx, err)
-							j.canceler(job.ID, false)
-							continue
-						}
-						// Bucket not found proceed to delete such a job.
-					}
+					err = job.Replicate.StartFromSource(job.ctx, j.objLayer, *job)
 				} else {
-					if err := job.Replicate.Start(job.ctx, j.objLayer, *job); err != nil {
-						if !isErrBucketNotFound(err) {
-							batchLogIf(j.ctx, err)
-							j.canceler(job.ID, false)
-							continue
-						}
-						// Bucket not found proceed to delete such a job.
-					}
+					err = job.Replicate.Start(job.ctx, j.objLayer, *job)
 				}
 			case job.KeyRotate != nil:
-				if err := job.KeyRotate.Start(job.ctx, j.objLayer, *job); err != nil {
-					if !isErrBucketNotFound(err) {
-						batchLogIf(j.ctx, err)
-						continue
-					}
-				}
+				err = job.KeyRotate.Start(job.ctx, j.objLayer, *job)
 			case job.Expire != nil:
-				if err := job.Expire.Start(job.ctx, j.objLayer, *job); err != nil {
-					if !isErrBucketNotFound(err) {
-						batchLogIf(j.ctx, err)
-						continue
-					}
-				}
+				err = job.Expire.Start(job.ctx, j.objLayer, *job)
+			case job.Copy != nil:
+				err = job.Copy.Start(job.ctx, j.objLayer, *job)
+			case job.Verify != nil:
+				err = job.Verify.Start(job.ctx, j.objLayer, *job)
+			case job.Retag != nil:
+				err = job.Retag.Start(job.ctx, j.objLayer, *job)
+			case job.MPCleanup != nil:
+				err = job.MPCleanup.Start(job.ctx, j.objLayer, *job)
+			case job.Export != nil:
+				err = job.Export.Start(job.ctx, j.objLayer, *job)
+			}
+			stopMon()
+			if err != nil && !isErrBucketNotFound(err) {
+				batchLogIf(j.ctx, err)
+				j.canceler(job.ID, false)
+				continue
 			}
+			// Bucket not found proceed to delete such a job.
 			j.canceler(job.ID, false)
 		case <-j.workerKillCh:
 			return
@@ -2066,6 +2185,11 @@ const (
 	batchJobMetricReplication batchJobMetric = iota
 	batchJobMetricKeyRotation
 	batchJobMetricExpire
+	batchJobMetricCopy
+	batchJobMetricVerify
+	batchJobMetricRetag
+	batchJobMetricMPCleanup
+	batchJobMetricExport
 )
 
 func batchJobTrace(d batchJobMetric, job string, startTime time.Time, duration time.Duration, info objTraceInfoer, attempts int, err error) madmin.TraceInfo {
@@ -2130,6 +2254,55 @@ func (ri *batchJobInfo) metric() madmin.JobMetric {
 			Objects:       ri.Objects,
 			ObjectsFailed: ri.ObjectsFailed,
 		}
+	case string(batchJobTypeCopy):
+		// Copy jobs report through the replicate metric shape, the
+		// counters carry the same meaning.
+		m.Replicate = &madmin.ReplicateInfo{
+			Bucket:           ri.Bucket,
+			Object:           ri.Object,
+			Objects:          ri.Objects,
+			ObjectsFailed:    ri.ObjectsFailed,
+			BytesTransferred: ri.BytesTransferred,
+			BytesFailed:      ri.BytesFailed,
+		}
+	case string(batchJobTypeVerify):
+		// Verify jobs count objects like key rotation does, reuse its
+		// metric shape.
+		m.KeyRotate = &madmin.KeyRotationInfo{
+			Bucket:        ri.Bucket,
+			Object:        ri.Object,
+			Objects:       ri.Objects,
+			ObjectsFailed: ri.ObjectsFailed,
+		}
+	case string(batchJobTypeRetag):
+		// Retag jobs are metadata-only updates, object counters map onto
+		// the key rotation metric shape.
+		m.KeyRotate = &madmin.KeyRotationInfo{
+			Bucket:        ri.Bucket,
+			Object:        ri.Object,
+			Objects:       ri.Objects,
+			ObjectsFailed: ri.ObjectsFailed,
+		}
+	case string(batchJobTypeMPCleanup):
+		// Multipart cleanup removes uploads much like expiration removes
+		// objects, reuse its metric shape.
+		m.Expired = &madmin.ExpirationInfo{
+			Bucket:        ri.Bucket,
+			Object:        ri.Object,
+			Objects:       ri.Objects,
+			ObjectsFailed: ri.ObjectsFailed,
+		}
+	case string(batchJobTypeExport):
+		// Export jobs move bytes between buckets, report through the
+		// replicate metric shape.
+		m.Replicate = &madmin.ReplicateInfo{
+			Bucket:           ri.Bucket,
+			Object:           ri.Object,
+			Objects:          ri.Objects,
+			ObjectsFailed:    ri.ObjectsFailed,
+			BytesTransferred: ri.BytesTransferred,
+			BytesFailed:      ri.BytesFailed,
+		}
 	}
 
 	return m
@@ -179,6 +179,114 @@ func (z *BatchJobRequest) DecodeMsg(dc *msgp.Reader) (err error) {
 					return
 				}
 			}
+		case "Copy":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "Copy")
+					return
+				}
+				z.Copy = nil
+			} else {
+				if z.Copy == nil {
+					z.Copy = new(BatchJobCopyV1)
+				}
+				err = z.Copy.DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Copy")
+					return
+				}
+			}
+		case "Verify":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "Verify")
+					return
+				}
+				z.Verify = nil
+			} else {
+				if z.Verify == nil {
+					z.Verify = new(BatchJobVerifyV1)
+				}
+				err = z.Verify.DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Verify")
+					return
+				}
+			}
+		case "Retag":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "Retag")
+					return
+				}
+				z.Retag = nil
+			} else {
+				if z.Retag == nil {
+					z.Retag = new(BatchJobRetagV1)
+				}
+				err = z.Retag.DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Retag")
+					return
+				}
+			}
+		case "MPCleanup":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "MPCleanup")
+					return
+				}
+				z.MPCleanup = nil
+			} else {
+				if z.MPCleanup == nil {
+					z.MPCleanup = new(BatchJobMPCleanupV1)
+				}
+				err = z.MPCleanup.DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "MPCleanup")
+					return
+				}
+			}
+		case "Export":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "Export")
+					return
+				}
+				z.Export = nil
+			} else {
+				if z.Export == nil {
+					z.Export = new(BatchJobExportV1)
+				}
+				err = z.Export.DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Export")
+					return
+				}
+			}
+		case "Chain":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "Chain")
+					return
+				}
+				z.Chain = nil
+			} else {
+				if z.Chain == nil {
+					z.Chain = new(BatchJobChain)
+				}
+				err = z.Chain.DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Chain")
+					return
+				}
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -192,9 +300,9 @@ func (z *BatchJobRequest) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *BatchJobRequest) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 6
+	// map header, size 12
 	// write "ID"
-	err = en.Append(0x86, 0xa2, 0x49, 0x44)
+	err = en.Append(0x8c, 0xa2, 0x49, 0x44)
 	if err != nil {
 		return
 	}
@@ -274,15 +382,117 @@ func (z *BatchJobRequest) EncodeMsg(en *msgp.Writer) (err error) {
 			return
 		}
 	}
+	// write "Copy"
+	err = en.Append(0xa4, 0x43, 0x6f, 0x70, 0x79)
+	if err != nil {
+		return
+	}
+	if z.Copy == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.Copy.EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Copy")
+			return
+		}
+	}
+	// write "Verify"
+	err = en.Append(0xa6, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79)
+	if err != nil {
+		return
+	}
+	if z.Verify == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.Verify.EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Verify")
+			return
+		}
+	}
+	// write "Retag"
+	err = en.Append(0xa5, 0x52, 0x65, 0x74, 0x61, 0x67)
+	if err != nil {
+		return
+	}
+	if z.Retag == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.Retag.EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Retag")
+			return
+		}
+	}
+	// write "MPCleanup"
+	err = en.Append(0xa9, 0x4d, 0x50, 0x43, 0x6c, 0x65, 0x61, 0x6e, 0x75, 0x70)
+	if err != nil {
+		return
+	}
+	if z.MPCleanup == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.MPCleanup.EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "MPCleanup")
+			return
+		}
+	}
+	// write "Export"
+	err = en.Append(0xa6, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74)
+	if err != nil {
+		return
+	}
+	if z.Export == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.Export.EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Export")
+			return
+		}
+	}
+	// write "Chain"
+	err = en.Append(0xa5, 0x43, 0x68, 0x61, 0x69, 0x6e)
+	if err != nil {
+		return
+	}
+	if z.Chain == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.Chain.EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Chain")
+			return
+		}
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *BatchJobRequest) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 6
+	// map header, size 12
 	// string "ID"
-	o = append(o, 0x86, 0xa2, 0x49, 0x44)
+	o = append(o, 0x8c, 0xa2, 0x49, 0x44)
 	o = msgp.AppendString(o, z.ID)
 	// string "User"
 	o = append(o, 0xa4, 0x55, 0x73, 0x65, 0x72)
@@ -323,6 +533,72 @@ func (z *BatchJobRequest) MarshalMsg(b []byte) (o []byte, err error) {
 			return
 		}
 	}
+	// string "Copy"
+	o = append(o, 0xa4, 0x43, 0x6f, 0x70, 0x79)
+	if z.Copy == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.Copy.MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Copy")
+			return
+		}
+	}
+	// string "Verify"
+	o = append(o, 0xa6, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79)
+	if z.Verify == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.Verify.MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Verify")
+			return
+		}
+	}
+	// string "Retag"
+	o = append(o, 0xa5, 0x52, 0x65, 0x74, 0x61, 0x67)
+	if z.Retag == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.Retag.MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Retag")
+			return
+		}
+	}
+	// string "MPCleanup"
+	o = append(o, 0xa9, 0x4d, 0x50, 0x43, 0x6c, 0x65, 0x61, 0x6e, 0x75, 0x70)
+	if z.MPCleanup == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.MPCleanup.MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "MPCleanup")
+			return
+		}
+	}
+	// string "Export"
+	o = append(o, 0xa6, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74)
+	if z.Export == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.Export.MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Export")
+			return
+		}
+	}
+	// string "Chain"
+	o = append(o, 0xa5, 0x43, 0x68, 0x61, 0x69, 0x6e)
+	if z.Chain == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.Chain.MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Chain")
+			return
+		}
+	}
 	return
 }
 
@@ -413,6 +689,108 @@ func (z *BatchJobRequest) UnmarshalMsg(bts []byte) (o []byte, err error) {
 					return
 				}
 			}
+		case "Copy":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.Copy = nil
+			} else {
+				if z.Copy == nil {
+					z.Copy = new(BatchJobCopyV1)
+				}
+				bts, err = z.Copy.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Copy")
+					return
+				}
+			}
+		case "Verify":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.Verify = nil
+			} else {
+				if z.Verify == nil {
+					z.Verify = new(BatchJobVerifyV1)
+				}
+				bts, err = z.Verify.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Verify")
+					return
+				}
+			}
+		case "Retag":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.Retag = nil
+			} else {
+				if z.Retag == nil {
+					z.Retag = new(BatchJobRetagV1)
+				}
+				bts, err = z.Retag.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Retag")
+					return
+				}
+			}
+		case "MPCleanup":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.MPCleanup = nil
+			} else {
+				if z.MPCleanup == nil {
+					z.MPCleanup = new(BatchJobMPCleanupV1)
+				}
+				bts, err = z.MPCleanup.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "MPCleanup")
+					return
+				}
+			}
+		case "Export":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.Export = nil
+			} else {
+				if z.Export == nil {
+					z.Export = new(BatchJobExportV1)
+				}
+				bts, err = z.Export.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Export")
+					return
+				}
+			}
+		case "Chain":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.Chain = nil
+			} else {
+				if z.Chain == nil {
+					z.Chain = new(BatchJobChain)
+				}
+				bts, err = z.Chain.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Chain")
+					return
+				}
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -445,6 +823,42 @@ func (z *BatchJobRequest) Msgsize() (s int) {
 	} else {
 		s += z.Expire.Msgsize()
 	}
+	s += 5
+	if z.Copy == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.Copy.Msgsize()
+	}
+	s += 7
+	if z.Verify == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.Verify.Msgsize()
+	}
+	s += 6
+	if z.Retag == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.Retag.Msgsize()
+	}
+	s += 10
+	if z.MPCleanup == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.MPCleanup.Msgsize()
+	}
+	s += 7
+	if z.Export == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.Export.Msgsize()
+	}
+	s += 6
+	if z.Chain == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.Chain.Msgsize()
+	}
 	return
 }
 
@@ -0,0 +1,507 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/lithammer/shortuuid/v4"
+	"github.com/minio/pkg/v3/policy"
+	"gopkg.in/yaml.v3"
+)
+
+// Persistent scheduler for recurring batch jobs. A schedule pairs a
+// batch job definition (replicate, keyrotate, expire) with a cron
+// expression; the first node in the deployment launches due jobs
+// through the regular batch job pool, so recurring jobs no longer need
+// external cron and mc. Schedules are stored under the config prefix on
+// the object layer.
+const (
+	// batchJobSchedulePrefix is where schedules are stored.
+	batchJobSchedulePrefix = minioConfigPrefix + "/batch-schedules"
+
+	// batchJobSchedulerInterval is how often due schedules are checked.
+	batchJobSchedulerInterval = time.Minute
+)
+
+// Missed-run policies, applied when runs were missed while the cluster
+// was down.
+const (
+	// missedRunSkip skips missed runs, the schedule resumes at the
+	// next cron occurrence. This is the default.
+	missedRunSkip = "skip"
+
+	// missedRunOnce runs the job once immediately to catch up,
+	// regardless of how many runs were missed.
+	missedRunOnce = "runOnce"
+)
+
+// cronSchedule is a parsed five-field cron expression, one set bit per
+// allowed value of each field.
+type cronSchedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+}
+
+// parseCronField parses one cron field - '*', '*/n', lists and ranges -
+// into a bitmask of allowed values between min and max.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if base, stepStr, ok := strings.Cut(part, "/"); ok {
+			s, err := strconv.Atoi(stepStr)
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid cron step '%s'", part)
+			}
+			part, step = base, s
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			loStr, hiStr, isRange := strings.Cut(part, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return 0, fmt.Errorf("invalid cron value '%s'", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return 0, fmt.Errorf("invalid cron range '%s'", part)
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("cron value '%s' out of range [%d-%d]", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+// parseCronExpr parses a standard five-field cron expression
+// 'minute hour day-of-month month day-of-week'.
+func parseCronExpr(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression '%s' must have 5 fields", expr)
+	}
+	var (
+		cs  cronSchedule
+		err error
+	)
+	if cs.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if cs.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if cs.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if cs.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if cs.dow, err = parseCronField(fields[4], 0, 6); err != nil {
+		return nil, err
+	}
+	return &cs, nil
+}
+
+func (cs *cronSchedule) matches(t time.Time) bool {
+	return cs.minute&(1<<uint(t.Minute())) != 0 &&
+		cs.hour&(1<<uint(t.Hour())) != 0 &&
+		cs.dom&(1<<uint(t.Day())) != 0 &&
+		cs.month&(1<<uint(t.Month())) != 0 &&
+		cs.dow&(1<<uint(t.Weekday())) != 0
+}
+
+// next returns the first occurrence after the given time, the zero time
+// when there is none within two years.
+func (cs *cronSchedule) next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(2, 0, 0)
+	for t.Before(limit) {
+		if cs.matches(t) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+// batchJobSchedule is one persistent schedule.
+type batchJobSchedule struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Cron            string    `json:"cron"`
+	Job             string    `json:"job"` // batch job definition in YAML
+	Concurrency     int       `json:"concurrency"`
+	MissedRunPolicy string    `json:"missedRunPolicy"`
+	User            string    `json:"user"`
+	CreatedAt       time.Time `json:"createdAt"`
+	LastRun         time.Time `json:"lastRun,omitempty"`
+
+	cron         *cronSchedule
+	nextRun      time.Time
+	activeJobIDs []string
+}
+
+// batchJobScheduleInfo is the admin API representation of a schedule,
+// including its next run time.
+type batchJobScheduleInfo struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Cron            string    `json:"cron"`
+	Concurrency     int       `json:"concurrency"`
+	MissedRunPolicy string    `json:"missedRunPolicy"`
+	User            string    `json:"user"`
+	LastRun         time.Time `json:"lastRun,omitempty"`
+	NextRun         time.Time `json:"nextRun,omitempty"`
+	ActiveJobs      int       `json:"activeJobs"`
+}
+
+type batchJobScheduler struct {
+	mu        sync.Mutex
+	schedules map[string]*batchJobSchedule
+}
+
+var globalBatchJobScheduler = &batchJobScheduler{schedules: make(map[string]*batchJobSchedule)}
+
+func batchJobSchedulePath(id string) string {
+	return path.Join(batchJobSchedulePrefix, id+".json")
+}
+
+// initBatchJobScheduler loads the schedules and starts the scheduler
+// loop on the first node of the deployment.
+func initBatchJobScheduler(ctx context.Context, objAPI ObjectLayer) {
+	// Only the first node of the deployment runs the scheduler; with no
+	// endpoints configured (single drive setups under test) there is
+	// nothing to schedule against either.
+	if len(globalEndpoints) == 0 || !globalEndpoints.FirstLocal() {
+		return
+	}
+
+	marker := ""
+	for {
+		res, err := objAPI.ListObjects(ctx, minioMetaBucket, batchJobSchedulePrefix, marker, "", maxObjectList)
+		if err != nil {
+			internalLogIf(ctx, fmt.Errorf("unable to list batch job schedules: %w", err))
+			return
+		}
+		for _, obj := range res.Objects {
+			data, err := readConfig(ctx, objAPI, obj.Name)
+			if err != nil {
+				internalLogIf(ctx, fmt.Errorf("unable to read batch job schedule %s: %w", obj.Name, err))
+				continue
+			}
+			var sched batchJobSchedule
+			if err = json.Unmarshal(data, &sched); err != nil {
+				internalLogIf(ctx, fmt.Errorf("unable to parse batch job schedule %s: %w", obj.Name, err))
+				continue
+			}
+			if sched.cron, err = parseCronExpr(sched.Cron); err != nil {
+				internalLogIf(ctx, fmt.Errorf("invalid cron expression in schedule %s: %w", sched.ID, err))
+				continue
+			}
+
+			now := time.Now().UTC()
+			sched.nextRun = sched.cron.next(now)
+			if !sched.LastRun.IsZero() && sched.MissedRunPolicy == missedRunOnce {
+				// A run was missed while the cluster was down,
+				// catch up with a single immediate run.
+				if missed := sched.cron.next(sched.LastRun); !missed.IsZero() && missed.Before(now) {
+					sched.nextRun = now
+				}
+			}
+			globalBatchJobScheduler.add(&sched)
+		}
+		if !res.IsTruncated {
+			break
+		}
+		marker = res.NextMarker
+	}
+
+	go globalBatchJobScheduler.run(ctx, objAPI)
+}
+
+func (s *batchJobScheduler) add(sched *batchJobSchedule) {
+	s.mu.Lock()
+	s.schedules[sched.ID] = sched
+	s.mu.Unlock()
+}
+
+func (s *batchJobScheduler) remove(id string) {
+	s.mu.Lock()
+	delete(s.schedules, id)
+	s.mu.Unlock()
+}
+
+// run launches due jobs until the context is canceled.
+func (s *batchJobScheduler) run(ctx context.Context, objAPI ObjectLayer) {
+	ticker := time.NewTicker(batchJobSchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now().UTC()
+		s.mu.Lock()
+		var due []*batchJobSchedule
+		for _, sched := range s.schedules {
+			if !sched.nextRun.IsZero() && !sched.nextRun.After(now) {
+				due = append(due, sched)
+			}
+		}
+		s.mu.Unlock()
+
+		for _, sched := range due {
+			s.launch(ctx, objAPI, sched)
+		}
+	}
+}
+
+// pruneActiveJobs drops finished jobs from the schedule, the batch job
+// pool removes the job object once a job completes.
+func (s *batchJobScheduler) pruneActiveJobs(ctx context.Context, objAPI ObjectLayer, sched *batchJobSchedule) {
+	var active []string
+	for _, id := range sched.activeJobIDs {
+		if _, err := objAPI.GetObjectInfo(ctx, minioMetaBucket, pathJoin(batchJobPrefix, id), ObjectOptions{}); err == nil {
+			active = append(active, id)
+		}
+	}
+	sched.activeJobIDs = active
+}
+
+// launch starts one run of the schedule unless its concurrency limit is
+// reached, and advances the next run time either way.
+func (s *batchJobScheduler) launch(ctx context.Context, objAPI ObjectLayer, sched *batchJobSchedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	sched.nextRun = sched.cron.next(now)
+
+	s.pruneActiveJobs(ctx, objAPI, sched)
+	if len(sched.activeJobIDs) >= sched.Concurrency {
+		internalLogIf(ctx, fmt.Errorf("batch job schedule %s skipped a run, %d jobs still active", sched.ID, len(sched.activeJobIDs)))
+		return
+	}
+
+	job := &BatchJobRequest{}
+	if err := yaml.Unmarshal([]byte(sched.Job), job); err != nil {
+		internalLogIf(ctx, fmt.Errorf("unable to parse job of schedule %s: %w", sched.ID, err))
+		return
+	}
+	applyBatchJobDefaults(job)
+	if err := job.Validate(ctx, objAPI); err != nil {
+		internalLogIf(ctx, fmt.Errorf("invalid job of schedule %s: %w", sched.ID, err))
+		return
+	}
+
+	job.ID = fmt.Sprintf("%s-%s%s%d", job.Type(), shortuuid.New(), getKeySeparator(), GetProxyEndpointLocalIndex(globalProxyEndpoints))
+	job.User = sched.User
+	job.Started = now
+
+	if err := job.save(ctx, objAPI); err != nil {
+		internalLogIf(ctx, fmt.Errorf("unable to save job of schedule %s: %w", sched.ID, err))
+		return
+	}
+	if err := globalBatchJobPool.queueJob(job); err != nil {
+		internalLogIf(ctx, fmt.Errorf("unable to queue job of schedule %s: %w", sched.ID, err))
+		return
+	}
+
+	sched.LastRun = now
+	sched.activeJobIDs = append(sched.activeJobIDs, job.ID)
+	if data, err := json.Marshal(sched); err == nil {
+		internalLogIf(ctx, saveConfig(ctx, objAPI, batchJobSchedulePath(sched.ID), data))
+	}
+}
+
+// AddBatchJobScheduleHandler - creates a batch job schedule.
+//
+// PUT /minio/admin/v3/batch-schedule
+func (a adminAPIHandlers) AddBatchJobScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, creds := validateAdminReq(ctx, w, r, policy.StartBatchJobAction)
+	if objectAPI == nil {
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, humanize.MiByte*4))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	var sched batchJobSchedule
+	if err = json.Unmarshal(data, &sched); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if sched.cron, err = parseCronExpr(sched.Cron); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	switch sched.MissedRunPolicy {
+	case "":
+		sched.MissedRunPolicy = missedRunSkip
+	case missedRunSkip, missedRunOnce:
+	default:
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	if sched.Concurrency <= 0 {
+		sched.Concurrency = 1
+	}
+
+	// Validate the job definition up front.
+	job := &BatchJobRequest{}
+	if err = yaml.Unmarshal([]byte(sched.Job), job); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	applyBatchJobDefaults(job)
+	if err = job.Validate(ctx, objectAPI); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	sched.ID = shortuuid.New()
+	sched.User = creds.AccessKey
+	if creds.ParentUser != "" {
+		sched.User = creds.ParentUser
+	}
+	sched.CreatedAt = time.Now().UTC()
+	sched.nextRun = sched.cron.next(sched.CreatedAt)
+
+	if data, err = json.Marshal(&sched); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if err = saveConfig(ctx, objectAPI, batchJobSchedulePath(sched.ID), data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	globalBatchJobScheduler.add(&sched)
+
+	resp, err := json.Marshal(batchJobScheduleInfo{
+		ID:              sched.ID,
+		Name:            sched.Name,
+		Cron:            sched.Cron,
+		Concurrency:     sched.Concurrency,
+		MissedRunPolicy: sched.MissedRunPolicy,
+		User:            sched.User,
+		NextRun:         sched.nextRun,
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, resp)
+}
+
+// ListBatchJobSchedulesHandler - lists the schedules with their next
+// run times.
+//
+// GET /minio/admin/v3/batch-schedules
+func (a adminAPIHandlers) ListBatchJobSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ListBatchJobsAction)
+	if objectAPI == nil {
+		return
+	}
+
+	s := globalBatchJobScheduler
+	s.mu.Lock()
+	infos := make([]batchJobScheduleInfo, 0, len(s.schedules))
+	for _, sched := range s.schedules {
+		infos = append(infos, batchJobScheduleInfo{
+			ID:              sched.ID,
+			Name:            sched.Name,
+			Cron:            sched.Cron,
+			Concurrency:     sched.Concurrency,
+			MissedRunPolicy: sched.MissedRunPolicy,
+			User:            sched.User,
+			LastRun:         sched.LastRun,
+			NextRun:         sched.nextRun,
+			ActiveJobs:      len(sched.activeJobIDs),
+		})
+	}
+	s.mu.Unlock()
+
+	data, err := json.Marshal(infos)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// RemoveBatchJobScheduleHandler - removes a schedule, jobs already
+// launched are unaffected.
+//
+// DELETE /minio/admin/v3/batch-schedule?id=...
+func (a adminAPIHandlers) RemoveBatchJobScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.CancelBatchJobAction)
+	if objectAPI == nil {
+		return
+	}
+
+	id := r.Form.Get("id")
+	globalBatchJobScheduler.mu.Lock()
+	_, ok := globalBatchJobScheduler.schedules[id]
+	globalBatchJobScheduler.mu.Unlock()
+	if !ok {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errConfigNotFound), r.URL)
+		return
+	}
+	if err := deleteConfig(ctx, objectAPI, batchJobSchedulePath(id)); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	globalBatchJobScheduler.remove(id)
+
+	writeSuccessResponseHeadersOnly(w)
+}
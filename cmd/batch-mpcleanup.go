@@ -0,0 +1,377 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/workers"
+	"github.com/minio/pkg/v3/xtime"
+)
+
+// mpcleanup:
+//   apiVersion: v1
+//   bucket: BUCKET # optional, scans all buckets when empty
+//   prefix: PREFIX # optional
+//   olderThan: "72h" # uploads initiated longer ago than this are purged
+// # optional flags for the cleanup job
+// flags:
+//   dryRun: false # when true only report reclaimable bytes, purge nothing
+//   notify:
+//     endpoint: "https://notify.endpoint" # notification endpoint to receive job status events
+//     token: "Bearer xxxxx" # optional authentication token for the notification endpoint
+//   retry:
+//     attempts: 10 # number of retries for the job before giving up
+//     delay: "500ms" # least amount of delay between each retry
+
+//go:generate msgp -file $GOFILE -unexported
+
+// batchJobTypeMPCleanup denotes the stale multipart upload cleanup job
+// type.
+const batchJobTypeMPCleanup = madmin.BatchJobType("mpcleanup")
+
+// BatchJobMPCleanupFlags various configurations for cleanup job definition currently includes
+// - dryRun
+// - notify
+// - retry
+type BatchJobMPCleanupFlags struct {
+	DryRun bool                 `yaml:"dryRun" json:"dryRun"`
+	Notify BatchJobNotification `yaml:"notify" json:"notify"`
+	Retry  BatchJobRetry        `yaml:"retry" json:"retry"`
+}
+
+// BatchJobMPCleanupV1 v1 of batch multipart cleanup job. Scans for
+// multipart uploads initiated longer ago than the configured age -
+// typically uploads that were abandoned without ever being completed or
+// aborted - reports the bytes their parts hold per bucket and purges
+// them with an audit trail.
+type BatchJobMPCleanupV1 struct {
+	APIVersion string                 `yaml:"apiVersion" json:"apiVersion"`
+	Flags      BatchJobMPCleanupFlags `yaml:"flags" json:"flags"`
+	Bucket     string                 `yaml:"bucket" json:"bucket"`
+	Prefix     string                 `yaml:"prefix" json:"prefix"`
+	OlderThan  xtime.Duration         `yaml:"olderThan" json:"olderThan"`
+}
+
+// Notify notifies notification endpoint if configured regarding job failure or success.
+func (r BatchJobMPCleanupV1) Notify(ctx context.Context, ri *batchJobInfo) error {
+	return notifyEndpoint(ctx, ri, r.Flags.Notify.Endpoint, r.Flags.Notify.Token)
+}
+
+const (
+	batchMPCleanupName               = "batch-mpcleanup.bin"
+	batchMPCleanupFormat             = 1
+	batchMPCleanupVersionV1          = 1
+	batchMPCleanupVersion            = batchMPCleanupVersionV1
+	batchMPCleanupAPIVersion         = "v1"
+	batchMPCleanupJobDefaultRetries  = 3
+	batchMPCleanupJobDefaultRetryDel = 250 * time.Millisecond
+
+	// batchMPCleanupDefaultOlderThan purges uploads initiated more than
+	// three days ago when the job does not configure an age.
+	batchMPCleanupDefaultOlderThan = 72 * time.Hour
+)
+
+// uploadSize sums the sizes of the parts uploaded so far, the bytes the
+// cleanup reclaims when the upload is aborted.
+func (r *BatchJobMPCleanupV1) uploadSize(ctx context.Context, api ObjectLayer, bucket string, upload MultipartInfo) (size int64, err error) {
+	var partNumberMarker int
+	for {
+		parts, err := api.ListObjectParts(ctx, bucket, upload.Object, upload.UploadID, partNumberMarker, maxPartsList, ObjectOptions{})
+		if err != nil {
+			return size, err
+		}
+		for _, part := range parts.Parts {
+			size += part.Size
+		}
+		if !parts.IsTruncated {
+			return size, nil
+		}
+		partNumberMarker = parts.NextPartNumberMarker
+	}
+}
+
+// cleanupUpload aborts a stale multipart upload releasing all of its
+// parts, leaving an audit trail of what was purged and why.
+func (r *BatchJobMPCleanupV1) cleanupUpload(ctx context.Context, api ObjectLayer, bucket string, upload MultipartInfo, size int64) error {
+	if err := api.AbortMultipartUpload(ctx, bucket, upload.Object, upload.UploadID, ObjectOptions{}); err != nil {
+		return err
+	}
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:   "AbortStaleMultipartUpload",
+		APIName: "StartBatchJob",
+		Bucket:  bucket,
+		Object:  upload.Object,
+		Tags: map[string]string{
+			"uploadId":       upload.UploadID,
+			"initiated":      upload.Initiated.Format(time.RFC3339),
+			"reclaimedBytes": strconv.FormatInt(size, 10),
+		},
+	})
+	return nil
+}
+
+// Start the batch multipart cleanup job, resumes from the last
+// checkpointed upload if there was a pending job via "job.ID". In
+// dry-run mode matching uploads are only counted and their reclaimable
+// bytes reported, nothing is purged.
+func (r *BatchJobMPCleanupV1) Start(ctx context.Context, api ObjectLayer, job BatchJobRequest) error {
+	ri := &batchJobInfo{
+		JobID:     job.ID,
+		JobType:   string(job.Type()),
+		StartTime: job.Started,
+	}
+	if err := ri.loadOrInit(ctx, api, job); err != nil {
+		return err
+	}
+	if ri.Complete {
+		return nil
+	}
+
+	globalBatchJobsMetrics.save(job.ID, ri)
+	lastBucket, lastObject := ri.Bucket, ri.Object
+
+	retryAttempts := r.Flags.Retry.Attempts
+	if retryAttempts <= 0 {
+		retryAttempts = batchMPCleanupJobDefaultRetries
+	}
+	delay := r.Flags.Retry.Delay
+	if delay <= 0 {
+		delay = batchMPCleanupJobDefaultRetryDel
+	}
+
+	olderThan := r.OlderThan.D()
+	if olderThan <= 0 {
+		olderThan = batchMPCleanupDefaultOlderThan
+	}
+	cutoff := time.Now().Add(-olderThan)
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	var buckets []string
+	if r.Bucket != "" {
+		buckets = []string{r.Bucket}
+	} else {
+		infos, err := api.ListBuckets(ctx, BucketOptions{})
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			buckets = append(buckets, info.Name)
+		}
+	}
+
+	workerSize, err := strconv.Atoi(env.Get("_MINIO_BATCH_MPCLEANUP_WORKERS", strconv.Itoa(runtime.GOMAXPROCS(0)/2)))
+	if err != nil {
+		return err
+	}
+
+	wk, err := workers.New(workerSize)
+	if err != nil {
+		// invalid worker size.
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Reclaimable bytes and purged upload counts per bucket, reported
+	// once the scan finishes.
+	var summaryMu sync.Mutex
+	reclaimable := make(map[string]int64, len(buckets))
+	purged := make(map[string]int64, len(buckets))
+
+	failed := false
+	for _, bucket := range buckets {
+		// Resume from the last checkpointed bucket, buckets are listed
+		// in a stable order.
+		if lastBucket != "" && bucket < lastBucket {
+			continue
+		}
+		keyMarker, uploadIDMarker := "", ""
+		if bucket == lastBucket {
+			keyMarker = lastObject
+		}
+		for {
+			uploads, err := api.ListMultipartUploads(ctx, bucket, r.Prefix, keyMarker, uploadIDMarker, "", maxUploadsList)
+			if err != nil {
+				failed = true
+				batchLogIf(ctx, err)
+				break
+			}
+			for _, upload := range uploads.Uploads {
+				upload := upload
+				if upload.Initiated.IsZero() || upload.Initiated.After(cutoff) {
+					continue
+				}
+				wk.Take()
+				go func() {
+					defer wk.Give()
+					size, err := r.uploadSize(ctx, api, bucket, upload)
+					if err != nil {
+						// The upload may have completed or been aborted since
+						// it was listed, count what we can still see.
+						batchLogIf(ctx, err)
+					}
+
+					summaryMu.Lock()
+					reclaimable[bucket] += size
+					summaryMu.Unlock()
+
+					if r.Flags.DryRun {
+						ri.trackCurrentBucketObject(bucket, ObjectInfo{Bucket: bucket, Name: upload.Object, Size: size}, true, 1)
+						globalBatchJobsMetrics.save(job.ID, ri)
+						batchLogIf(ctx, ri.updateAfter(ctx, api, 10*time.Second, job))
+						return
+					}
+
+					for attempts := 1; attempts <= retryAttempts; attempts++ {
+						stopFn := globalBatchJobsMetrics.trace(batchJobMetricMPCleanup, job.ID, attempts)
+						success := true
+						oi := ObjectInfo{Bucket: bucket, Name: upload.Object, Size: size}
+						if err := r.cleanupUpload(ctx, api, bucket, upload, size); err != nil {
+							stopFn(oi, err)
+							batchLogIf(ctx, err)
+							success = false
+							if attempts >= retryAttempts {
+								auditLogInternal(ctx, AuditLogOptions{
+									Event:   "AbortStaleMultipartUpload",
+									APIName: "StartBatchJob",
+									Bucket:  bucket,
+									Object:  upload.Object,
+									Error:   err.Error(),
+									Tags: map[string]string{
+										"uploadId": upload.UploadID,
+									},
+								})
+							}
+						} else {
+							stopFn(oi, nil)
+							summaryMu.Lock()
+							purged[bucket]++
+							summaryMu.Unlock()
+						}
+						ri.trackCurrentBucketObject(bucket, oi, success, attempts)
+						globalBatchJobsMetrics.save(job.ID, ri)
+						// persist in-memory state to disk after every 10secs.
+						batchLogIf(ctx, ri.updateAfter(ctx, api, 10*time.Second, job))
+						if success {
+							break
+						}
+						if delay > 0 {
+							time.Sleep(delay + time.Duration(rnd.Float64()*float64(delay)))
+						}
+					}
+				}()
+			}
+			if !uploads.IsTruncated {
+				break
+			}
+			keyMarker = uploads.NextKeyMarker
+			uploadIDMarker = uploads.NextUploadIDMarker
+		}
+	}
+	wk.Wait()
+
+	// Report the per-bucket reclaimable bytes on the audit trail, in
+	// dry-run mode this is the job's entire output.
+	for _, bucket := range buckets {
+		summaryMu.Lock()
+		bytes, uploads := reclaimable[bucket], purged[bucket]
+		summaryMu.Unlock()
+		if bytes == 0 && uploads == 0 {
+			continue
+		}
+		auditLogInternal(ctx, AuditLogOptions{
+			Event:   "StaleMultipartUploadSummary",
+			APIName: "StartBatchJob",
+			Bucket:  bucket,
+			Tags: map[string]string{
+				"reclaimableBytes": strconv.FormatInt(bytes, 10),
+				"purgedUploads":    strconv.FormatInt(uploads, 10),
+				"dryRun":           strconv.FormatBool(r.Flags.DryRun),
+			},
+		})
+	}
+
+	ri.Complete = !failed && ri.ObjectsFailed == 0
+	ri.Failed = failed || ri.ObjectsFailed > 0
+	globalBatchJobsMetrics.save(job.ID, ri)
+	// persist in-memory state to disk.
+	batchLogIf(ctx, ri.updateAfter(ctx, api, 0, job))
+
+	if err := r.Notify(ctx, ri); err != nil {
+		batchLogIf(ctx, fmt.Errorf("unable to notify %v", err))
+	}
+
+	return nil
+}
+
+//msgp:ignore batchMPCleanupJobError
+type batchMPCleanupJobError struct {
+	Code           string
+	Description    string
+	HTTPStatusCode int
+}
+
+func (e batchMPCleanupJobError) Error() string {
+	return e.Description
+}
+
+// Validate validates the job definition input
+func (r *BatchJobMPCleanupV1) Validate(ctx context.Context, job BatchJobRequest, o ObjectLayer) error {
+	if r == nil {
+		return nil
+	}
+
+	if r.APIVersion != batchMPCleanupAPIVersion {
+		return errInvalidArgument
+	}
+
+	if r.OlderThan < 0 {
+		return batchMPCleanupJobError{
+			Code:           "InvalidRequest",
+			Description:    "The upload age to clean up cannot be negative",
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if r.Bucket != "" {
+		if _, err := o.GetBucketInfo(ctx, r.Bucket, BucketOptions{}); err != nil {
+			if isErrBucketNotFound(err) {
+				return batchMPCleanupJobError{
+					Code:           "NoSuchSourceBucket",
+					Description:    fmt.Sprintf("The specified bucket %s does not exist", r.Bucket),
+					HTTPStatusCode: http.StatusNotFound,
+				}
+			}
+			return err
+		}
+	}
+
+	return r.Flags.Retry.Validate()
+}
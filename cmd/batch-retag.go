@@ -0,0 +1,505 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/minio-go/v7/pkg/tags"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/workers"
+	"golang.org/x/time/rate"
+)
+
+// retag:
+//   apiVersion: v1
+//   bucket: BUCKET
+//   prefix: PREFIX
+//   tags:
+//     set: # tags to set, existing values are overwritten
+//       - key: "classification"
+//         value: "internal"
+//     remove: # tag keys to remove
+//       - "temporary"
+//   metadata:
+//     set: # user metadata to set, existing values are overwritten
+//       - key: "x-amz-meta-team"
+//         value: "storage"
+//     remove: # user metadata keys to remove
+//       - "x-amz-meta-legacy"
+// # optional flags based filtering criteria
+// # for all objects
+// flags:
+//   dryRun: false # when true only count matching objects, modify nothing
+//   rateLimit: 100 # objects re-tagged per second, unlimited when 0
+//   filter:
+//     newerThan: "7d" # match objects newer than this value (e.g. 7d10h31s)
+//     olderThan: "7d" # match objects older than this value (e.g. 7d10h31s)
+//     createdAfter: "date" # match objects created after "date"
+//     createdBefore: "date" # match objects created before "date"
+//     tags:
+//       - key: "name"
+//         value: "pick*" # match objects with tag 'name', with all values starting with 'pick'
+//     metadata:
+//       - key: "content-type"
+//         value: "image/*" # match objects with 'content-type', with all values starting with 'image/'
+//   notify:
+//     endpoint: "https://notify.endpoint" # notification endpoint to receive job status events
+//     token: "Bearer xxxxx" # optional authentication token for the notification endpoint
+//   retry:
+//     attempts: 10 # number of retries for the job before giving up
+//     delay: "500ms" # least amount of delay between each retry
+
+//go:generate msgp -file $GOFILE -unexported
+
+// batchJobTypeRetag denotes the mass re-tagging job type.
+const batchJobTypeRetag = madmin.BatchJobType("retag")
+
+// BatchJobRetagRules lists keys to set and keys to remove, used for
+// both object tags and user metadata.
+type BatchJobRetagRules struct {
+	Set    []BatchJobKV `yaml:"set" json:"set"`
+	Remove []string     `yaml:"remove" json:"remove"`
+}
+
+// empty returns true if the rules neither set nor remove anything.
+func (r BatchJobRetagRules) empty() bool {
+	return len(r.Set) == 0 && len(r.Remove) == 0
+}
+
+// BatchJobRetagFlags various configurations for retag job definition currently includes
+// - dryRun
+// - rateLimit
+// - filter
+// - notify
+// - retry
+type BatchJobRetagFlags struct {
+	DryRun    bool                 `yaml:"dryRun" json:"dryRun"`
+	RateLimit int64                `yaml:"rateLimit" json:"rateLimit"`
+	Filter    BatchReplicateFilter `yaml:"filter" json:"filter"`
+	Notify    BatchJobNotification `yaml:"notify" json:"notify"`
+	Retry     BatchJobRetry        `yaml:"retry" json:"retry"`
+}
+
+// BatchJobRetagV1 v1 of batch retag job. Rewrites object tags and user
+// metadata in bulk as metadata-only updates - object data is never
+// rewritten - with optional rate limiting and a dry-run mode that only
+// counts the matching objects.
+type BatchJobRetagV1 struct {
+	APIVersion string             `yaml:"apiVersion" json:"apiVersion"`
+	Flags      BatchJobRetagFlags `yaml:"flags" json:"flags"`
+	Bucket     string             `yaml:"bucket" json:"bucket"`
+	Prefix     string             `yaml:"prefix" json:"prefix"`
+	Tags       BatchJobRetagRules `yaml:"tags" json:"tags"`
+	Metadata   BatchJobRetagRules `yaml:"metadata" json:"metadata"`
+}
+
+// Notify notifies notification endpoint if configured regarding job failure or success.
+func (r BatchJobRetagV1) Notify(ctx context.Context, ri *batchJobInfo) error {
+	return notifyEndpoint(ctx, ri, r.Flags.Notify.Endpoint, r.Flags.Notify.Token)
+}
+
+// RetagObject rewrites the tags and user metadata of a single object
+// in place. The update follows the key rotation code path - clone the
+// metadata, modify it and write it back via a metadata-only self copy -
+// so the object data and ETag remain untouched.
+func (r *BatchJobRetagV1) RetagObject(ctx context.Context, api ObjectLayer, objInfo ObjectInfo) error {
+	srcBucket := r.Bucket
+	srcObject := objInfo.Name
+
+	if objInfo.DeleteMarker || !objInfo.VersionPurgeStatus.Empty() {
+		return nil
+	}
+
+	versioned := globalBucketVersioningSys.PrefixEnabled(srcBucket, srcObject)
+	versionSuspended := globalBucketVersioningSys.PrefixSuspended(srcBucket, srcObject)
+
+	lock := api.NewNSLock(r.Bucket, objInfo.Name)
+	lkctx, err := lock.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lock.Unlock(lkctx)
+
+	opts := ObjectOptions{
+		VersionID:        objInfo.VersionID,
+		Versioned:        versioned,
+		VersionSuspended: versionSuspended,
+		NoLock:           true,
+	}
+	obj, err := api.GetObjectInfo(ctx, r.Bucket, objInfo.Name, opts)
+	if err != nil {
+		return err
+	}
+	oi := obj.Clone()
+
+	// Apply tag rules on the current tag set.
+	if !r.Tags.empty() {
+		tagMap := map[string]string{}
+		if tagStr := oi.UserDefined[xhttp.AmzObjectTagging]; tagStr != "" {
+			t, err := tags.ParseObjectTags(tagStr)
+			if err != nil {
+				return err
+			}
+			tagMap = t.ToMap()
+		}
+		for _, key := range r.Tags.Remove {
+			delete(tagMap, key)
+		}
+		for _, kv := range r.Tags.Set {
+			tagMap[kv.Key] = kv.Value
+		}
+		if len(tagMap) > 0 {
+			t, err := tags.NewTags(tagMap, true)
+			if err != nil {
+				return err
+			}
+			oi.UserDefined[xhttp.AmzObjectTagging] = t.String()
+		} else {
+			delete(oi.UserDefined, xhttp.AmzObjectTagging)
+		}
+	}
+
+	// Apply metadata rules, keys are matched case-insensitively the way
+	// HTTP headers are.
+	for _, key := range r.Metadata.Remove {
+		for k := range oi.UserDefined {
+			if strings.EqualFold(k, key) {
+				delete(oi.UserDefined, k)
+			}
+		}
+	}
+	for _, kv := range r.Metadata.Set {
+		for k := range oi.UserDefined {
+			if strings.EqualFold(k, kv.Key) {
+				delete(oi.UserDefined, k)
+			}
+		}
+		oi.UserDefined[kv.Key] = kv.Value
+	}
+
+	// Write back as a metadata-only self referential copy, the object
+	// data is left in place.
+	oi.metadataOnly = true
+	if _, err := api.CopyObject(ctx, r.Bucket, oi.Name, r.Bucket, oi.Name, oi, ObjectOptions{
+		VersionID: oi.VersionID,
+	}, ObjectOptions{
+		VersionID: oi.VersionID,
+		NoLock:    true,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const (
+	batchRetagName               = "batch-retag.bin"
+	batchRetagFormat             = 1
+	batchRetagVersionV1          = 1
+	batchRetagVersion            = batchRetagVersionV1
+	batchRetagAPIVersion         = "v1"
+	batchRetagJobDefaultRetries  = 3
+	batchRetagJobDefaultRetryDel = 250 * time.Millisecond
+)
+
+// Start the batch retag job, resumes from the last checkpointed object
+// if there was a pending job via "job.ID". In dry-run mode matching
+// objects are only counted, nothing is modified.
+func (r *BatchJobRetagV1) Start(ctx context.Context, api ObjectLayer, job BatchJobRequest) error {
+	ri := &batchJobInfo{
+		JobID:     job.ID,
+		JobType:   string(job.Type()),
+		StartTime: job.Started,
+	}
+	if err := ri.loadOrInit(ctx, api, job); err != nil {
+		return err
+	}
+	if ri.Complete {
+		return nil
+	}
+
+	globalBatchJobsMetrics.save(job.ID, ri)
+	lastObject := ri.Object
+
+	retryAttempts := r.Flags.Retry.Attempts
+	if retryAttempts <= 0 {
+		retryAttempts = batchRetagJobDefaultRetries
+	}
+	delay := r.Flags.Retry.Delay
+	if delay <= 0 {
+		delay = batchRetagJobDefaultRetryDel
+	}
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	selectObj := func(info FileInfo) (ok bool) {
+		if r.Flags.Filter.OlderThan > 0 && time.Since(info.ModTime) < r.Flags.Filter.OlderThan.D() {
+			// skip all objects that are newer than specified older duration
+			return false
+		}
+
+		if r.Flags.Filter.NewerThan > 0 && time.Since(info.ModTime) >= r.Flags.Filter.NewerThan.D() {
+			// skip all objects that are older than specified newer duration
+			return false
+		}
+
+		if !r.Flags.Filter.CreatedAfter.IsZero() && r.Flags.Filter.CreatedAfter.Before(info.ModTime) {
+			// skip all objects that are created before the specified time.
+			return false
+		}
+
+		if !r.Flags.Filter.CreatedBefore.IsZero() && r.Flags.Filter.CreatedBefore.After(info.ModTime) {
+			// skip all objects that are created after the specified time.
+			return false
+		}
+
+		if len(r.Flags.Filter.Tags) > 0 {
+			// Only parse object tags if tags filter is specified.
+			tagMap := map[string]string{}
+			tagStr := info.Metadata[xhttp.AmzObjectTagging]
+			if len(tagStr) != 0 {
+				t, err := tags.ParseObjectTags(tagStr)
+				if err != nil {
+					return false
+				}
+				tagMap = t.ToMap()
+			}
+
+			for _, kv := range r.Flags.Filter.Tags {
+				for t, v := range tagMap {
+					if kv.Match(BatchJobKV{Key: t, Value: v}) {
+						return true
+					}
+				}
+			}
+
+			// None of the provided tags filter match skip the object
+			return false
+		}
+
+		if len(r.Flags.Filter.Metadata) > 0 {
+			for _, kv := range r.Flags.Filter.Metadata {
+				for k, v := range info.Metadata {
+					if !stringsHasPrefixFold(k, "x-amz-meta-") && !isStandardHeader(k) {
+						continue
+					}
+					// We only need to match x-amz-meta or standardHeaders
+					if kv.Match(BatchJobKV{Key: k, Value: v}) {
+						return true
+					}
+				}
+			}
+
+			// None of the provided metadata filters match skip the object.
+			return false
+		}
+
+		// Retag only the latest version, delete markers carry no metadata.
+		return info.IsLatest
+	}
+
+	// Optional pacing of metadata updates so bulk re-tagging does not
+	// starve foreground traffic.
+	var limiter *rate.Limiter
+	if r.Flags.RateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(r.Flags.RateLimit), int(r.Flags.RateLimit))
+	}
+
+	workerSize, err := strconv.Atoi(env.Get("_MINIO_BATCH_RETAG_WORKERS", strconv.Itoa(runtime.GOMAXPROCS(0)/2)))
+	if err != nil {
+		return err
+	}
+
+	wk, err := workers.New(workerSize)
+	if err != nil {
+		// invalid worker size.
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	if err := api.Walk(ctx, r.Bucket, r.Prefix, results, WalkOptions{
+		Marker: lastObject,
+		Filter: selectObj,
+	}); err != nil {
+		cancel()
+		// Do not need to retry if we can't list objects on source.
+		return err
+	}
+	failed := false
+	for res := range results {
+		if res.Err != nil {
+			failed = true
+			batchLogIf(ctx, res.Err)
+			break
+		}
+		result := res.Item
+		if r.Flags.DryRun {
+			// Dry-run only counts the objects the job would touch, the
+			// count is reported through the job status.
+			ri.trackCurrentBucketObject(r.Bucket, result, true, 1)
+			globalBatchJobsMetrics.save(job.ID, ri)
+			batchLogIf(ctx, ri.updateAfter(ctx, api, 10*time.Second, job))
+			continue
+		}
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				failed = true
+				batchLogIf(ctx, err)
+				break
+			}
+		}
+		wk.Take()
+		go func() {
+			defer wk.Give()
+			for attempts := 1; attempts <= retryAttempts; attempts++ {
+				stopFn := globalBatchJobsMetrics.trace(batchJobMetricRetag, job.ID, attempts)
+				success := true
+				if err := r.RetagObject(ctx, api, result); err != nil {
+					stopFn(result, err)
+					batchLogIf(ctx, err)
+					success = false
+					if attempts >= retryAttempts {
+						auditOptions := AuditLogOptions{
+							Event:     "Retag",
+							APIName:   "StartBatchJob",
+							Bucket:    result.Bucket,
+							Object:    result.Name,
+							VersionID: result.VersionID,
+							Error:     err.Error(),
+						}
+						auditLogInternal(ctx, auditOptions)
+					}
+				} else {
+					stopFn(result, nil)
+				}
+				ri.trackCurrentBucketObject(r.Bucket, result, success, attempts)
+				globalBatchJobsMetrics.save(job.ID, ri)
+				// persist in-memory state to disk after every 10secs.
+				batchLogIf(ctx, ri.updateAfter(ctx, api, 10*time.Second, job))
+				if success {
+					break
+				}
+				if delay > 0 {
+					time.Sleep(delay + time.Duration(rnd.Float64()*float64(delay)))
+				}
+			}
+		}()
+	}
+	wk.Wait()
+
+	ri.Complete = !failed && ri.ObjectsFailed == 0
+	ri.Failed = failed || ri.ObjectsFailed > 0
+	globalBatchJobsMetrics.save(job.ID, ri)
+	// persist in-memory state to disk.
+	batchLogIf(ctx, ri.updateAfter(ctx, api, 0, job))
+
+	if err := r.Notify(ctx, ri); err != nil {
+		batchLogIf(ctx, fmt.Errorf("unable to notify %v", err))
+	}
+
+	cancel()
+	return nil
+}
+
+//msgp:ignore batchRetagJobError
+type batchRetagJobError struct {
+	Code           string
+	Description    string
+	HTTPStatusCode int
+}
+
+func (e batchRetagJobError) Error() string {
+	return e.Description
+}
+
+// Validate validates the job definition input
+func (r *BatchJobRetagV1) Validate(ctx context.Context, job BatchJobRequest, o ObjectLayer) error {
+	if r == nil {
+		return nil
+	}
+
+	if r.APIVersion != batchRetagAPIVersion {
+		return errInvalidArgument
+	}
+
+	if r.Bucket == "" {
+		return errInvalidArgument
+	}
+
+	if r.Tags.empty() && r.Metadata.empty() {
+		return batchRetagJobError{
+			Code:           "InvalidRequest",
+			Description:    "A retag job must set or remove at least one tag or metadata key",
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if r.Flags.RateLimit < 0 {
+		return errInvalidArgument
+	}
+
+	if _, err := o.GetBucketInfo(ctx, r.Bucket, BucketOptions{}); err != nil {
+		if isErrBucketNotFound(err) {
+			return batchRetagJobError{
+				Code:           "NoSuchSourceBucket",
+				Description:    fmt.Sprintf("The specified bucket %s does not exist", r.Bucket),
+				HTTPStatusCode: http.StatusNotFound,
+			}
+		}
+		return err
+	}
+
+	for _, tag := range r.Tags.Set {
+		if err := tag.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, meta := range r.Metadata.Set {
+		if err := meta.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, tag := range r.Flags.Filter.Tags {
+		if err := tag.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, meta := range r.Flags.Filter.Metadata {
+		if err := meta.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return r.Flags.Retry.Validate()
+}
@@ -45,6 +45,9 @@ import (
 //     type: sse-s3 # valid values are sse-s3 and sse-kms
 //     key: <new-kms-key> # valid only for sse-kms
 //     context: <new-kms-key-context> # valid only for sse-kms
+//     rewrap: false # re-wrap object encryption keys under the latest
+//                   # version of the key currently protecting each object,
+//                   # without changing key or type - 'key' must be unset
 // # optional flags based filtering criteria
 // # for all objects
 // flags:
@@ -83,11 +86,23 @@ type BatchJobKeyRotateEncryption struct {
 	Type       BatchKeyRotationType `yaml:"type" json:"type"`
 	Key        string               `yaml:"key" json:"key"`
 	Context    string               `yaml:"context" json:"context"`
+	Rewrap     bool                 `yaml:"rewrap" json:"rewrap"`
 	kmsContext kms.Context          `msg:"-"`
 }
 
 // Validate validates input key rotation encryption options.
 func (e BatchJobKeyRotateEncryption) Validate() error {
+	if e.Rewrap {
+		// Re-wrap keeps the key protecting each object unchanged,
+		// specifying a new key or context is ambiguous.
+		if e.Key != "" || e.Context != "" {
+			return errInvalidArgument
+		}
+		if e.Type != "" && e.Type != sses3 && e.Type != ssekms {
+			return errInvalidArgument
+		}
+		return nil
+	}
 	if e.Type != sses3 && e.Type != ssekms {
 		return errInvalidArgument
 	}
@@ -211,7 +226,21 @@ func (r *BatchJobKeyRotateV1) KeyRotate(ctx context.Context, api ObjectLayer, ob
 		}
 	}
 
-	if (sseKMS || sseS3) && r.Encryption.Type == ssekms {
+	switch {
+	case r.Encryption.Rewrap:
+		// Re-wrap the object encryption key under the latest version
+		// of the key currently protecting the object. The key and the
+		// KMS context remain unchanged, only the wrapped OEK in xl.meta
+		// is refreshed.
+		if sseKMS {
+			keyID, _, _, cryptoCtx, cerr := crypto.S3KMS.ParseMetadata(oi.UserDefined)
+			if cerr != nil {
+				return cerr
+			}
+			newKeyID = strings.TrimPrefix(keyID, crypto.ARNPrefix)
+			newKeyContext = cryptoCtx
+		}
+	case (sseKMS || sseS3) && r.Encryption.Type == ssekms:
 		if err = r.Encryption.Validate(); err != nil {
 			return err
 		}
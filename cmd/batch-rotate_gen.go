@@ -46,6 +46,12 @@ func (z *BatchJobKeyRotateEncryption) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "Context")
 				return
 			}
+		case "Rewrap":
+			z.Rewrap, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Rewrap")
+				return
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -59,9 +65,9 @@ func (z *BatchJobKeyRotateEncryption) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z BatchJobKeyRotateEncryption) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 3
+	// map header, size 4
 	// write "Type"
-	err = en.Append(0x83, 0xa4, 0x54, 0x79, 0x70, 0x65)
+	err = en.Append(0x84, 0xa4, 0x54, 0x79, 0x70, 0x65)
 	if err != nil {
 		return
 	}
@@ -90,15 +96,25 @@ func (z BatchJobKeyRotateEncryption) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Context")
 		return
 	}
+	// write "Rewrap"
+	err = en.Append(0xa6, 0x52, 0x65, 0x77, 0x72, 0x61, 0x70)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.Rewrap)
+	if err != nil {
+		err = msgp.WrapError(err, "Rewrap")
+		return
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z BatchJobKeyRotateEncryption) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 3
+	// map header, size 4
 	// string "Type"
-	o = append(o, 0x83, 0xa4, 0x54, 0x79, 0x70, 0x65)
+	o = append(o, 0x84, 0xa4, 0x54, 0x79, 0x70, 0x65)
 	o = msgp.AppendString(o, string(z.Type))
 	// string "Key"
 	o = append(o, 0xa3, 0x4b, 0x65, 0x79)
@@ -106,6 +122,9 @@ func (z BatchJobKeyRotateEncryption) MarshalMsg(b []byte) (o []byte, err error)
 	// string "Context"
 	o = append(o, 0xa7, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74)
 	o = msgp.AppendString(o, z.Context)
+	// string "Rewrap"
+	o = append(o, 0xa6, 0x52, 0x65, 0x77, 0x72, 0x61, 0x70)
+	o = msgp.AppendBool(o, z.Rewrap)
 	return
 }
 
@@ -149,6 +168,12 @@ func (z *BatchJobKeyRotateEncryption) UnmarshalMsg(bts []byte) (o []byte, err er
 				err = msgp.WrapError(err, "Context")
 				return
 			}
+		case "Rewrap":
+			z.Rewrap, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Rewrap")
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -163,7 +188,7 @@ func (z *BatchJobKeyRotateEncryption) UnmarshalMsg(bts []byte) (o []byte, err er
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z BatchJobKeyRotateEncryption) Msgsize() (s int) {
-	s = 1 + 5 + msgp.StringPrefixSize + len(string(z.Type)) + 4 + msgp.StringPrefixSize + len(z.Key) + 8 + msgp.StringPrefixSize + len(z.Context)
+	s = 1 + 5 + msgp.StringPrefixSize + len(string(z.Type)) + 4 + msgp.StringPrefixSize + len(z.Key) + 8 + msgp.StringPrefixSize + len(z.Context) + 7 + msgp.BoolSize
 	return
 }
 
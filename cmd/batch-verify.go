@@ -0,0 +1,540 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	gohash "hash"
+	"io"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/minio-go/v7/pkg/tags"
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/hash"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/workers"
+)
+
+// verify:
+//   apiVersion: v1
+//   bucket: BUCKET
+//   prefix: PREFIX
+//   manifest: # optional, where the signed verification manifest is written
+//     bucket: BUCKET # defaults to the verified bucket
+//     prefix: PREFIX # defaults to ".verify-manifests"
+// # optional flags based filtering criteria
+// # for all objects
+// flags:
+//   filter:
+//     newerThan: "7d" # match objects newer than this value (e.g. 7d10h31s)
+//     olderThan: "7d" # match objects older than this value (e.g. 7d10h31s)
+//     createdAfter: "date" # match objects created after "date"
+//     createdBefore: "date" # match objects created before "date"
+//     tags:
+//       - key: "name"
+//         value: "pick*" # match objects with tag 'name', with all values starting with 'pick'
+//     metadata:
+//       - key: "content-type"
+//         value: "image/*" # match objects with 'content-type', with all values starting with 'image/'
+//   notify:
+//     endpoint: "https://notify.endpoint" # notification endpoint to receive job status events
+//     token: "Bearer xxxxx" # optional authentication token for the notification endpoint
+//   retry:
+//     attempts: 10 # number of retries for the job before giving up
+//     delay: "500ms" # least amount of delay between each retry
+
+//go:generate msgp -file $GOFILE -unexported
+
+// batchJobTypeVerify denotes the checksum verification job type.
+const batchJobTypeVerify = madmin.BatchJobType("verify")
+
+// BatchJobVerifyManifest describes where the signed verification
+// manifest is written once the job completes.
+type BatchJobVerifyManifest struct {
+	Bucket string `yaml:"bucket" json:"bucket"`
+	Prefix string `yaml:"prefix" json:"prefix"`
+}
+
+// BatchJobVerifyFlags various configurations for verify job definition currently includes
+// - filter
+// - notify
+// - retry
+type BatchJobVerifyFlags struct {
+	Filter BatchReplicateFilter `yaml:"filter" json:"filter"`
+	Notify BatchJobNotification `yaml:"notify" json:"notify"`
+	Retry  BatchJobRetry        `yaml:"retry" json:"retry"`
+}
+
+// BatchJobVerifyV1 v1 of batch verify job. Streams every matching
+// object back through the erasure layer - which verifies bitrot on
+// read - recomputes the checksums declared at upload time and records
+// the outcome per object in a signed manifest for compliance
+// attestation.
+type BatchJobVerifyV1 struct {
+	APIVersion string                 `yaml:"apiVersion" json:"apiVersion"`
+	Flags      BatchJobVerifyFlags    `yaml:"flags" json:"flags"`
+	Bucket     string                 `yaml:"bucket" json:"bucket"`
+	Prefix     string                 `yaml:"prefix" json:"prefix"`
+	Manifest   BatchJobVerifyManifest `yaml:"manifest" json:"manifest"`
+}
+
+// Notify notifies notification endpoint if configured regarding job failure or success.
+func (r BatchJobVerifyV1) Notify(ctx context.Context, ri *batchJobInfo) error {
+	return notifyEndpoint(ctx, ri, r.Flags.Notify.Endpoint, r.Flags.Notify.Token)
+}
+
+//msgp:ignore batchVerifyEntry
+type batchVerifyEntry struct {
+	Object    string            `json:"object"`
+	VersionID string            `json:"versionId,omitempty"`
+	Size      int64             `json:"size"`
+	ETag      string            `json:"etag"`
+	Checksums map[string]string `json:"checksums,omitempty"`
+	Verified  bool              `json:"verified"`
+	Error     string            `json:"error,omitempty"`
+}
+
+//msgp:ignore batchVerifyManifest
+type batchVerifyManifest struct {
+	Version     string             `json:"version"`
+	JobID       string             `json:"jobId"`
+	Bucket      string             `json:"bucket"`
+	Prefix      string             `json:"prefix"`
+	StartedAt   time.Time          `json:"startedAt"`
+	CompletedAt time.Time          `json:"completedAt"`
+	Verified    int64              `json:"verified"`
+	Failed      int64              `json:"failed"`
+	Entries     []batchVerifyEntry `json:"entries"`
+}
+
+// VerifyObject streams a single object end to end. Reading the full
+// content through the object layer verifies the erasure coded shards
+// against their bitrot hashes; on top of that every checksum declared
+// at upload time is recomputed and compared. Returns the declared
+// checksums for the manifest entry.
+func (r *BatchJobVerifyV1) VerifyObject(ctx context.Context, api ObjectLayer, objInfo ObjectInfo) (map[string]string, error) {
+	declared := hash.ReadCheckSums(objInfo.Checksum, 0)
+
+	gr, err := api.GetObjectNInfo(ctx, r.Bucket, objInfo.Name, nil, http.Header{}, ObjectOptions{
+		VersionID: objInfo.VersionID,
+		NoLock:    true,
+	})
+	if err != nil {
+		return declared, err
+	}
+	defer gr.Close()
+
+	// Encrypted objects store ciphertext while declared checksums cover
+	// the plaintext, recomputing them here is not possible without the
+	// object key - the streaming read still verifies bitrot.
+	_, encrypted := crypto.IsEncrypted(objInfo.UserDefined)
+
+	type wantSum struct {
+		typ    hash.ChecksumType
+		want   string
+		hasher gohash.Hash
+	}
+	var sums []wantSum
+	if !encrypted {
+		for alg, value := range declared {
+			// Composite multipart checksums carry a "-<parts>" suffix
+			// and are not recomputable from the byte stream alone.
+			if strings.ContainsRune(value, '-') {
+				continue
+			}
+			typ := hash.NewChecksumType(alg)
+			if !typ.IsSet() {
+				continue
+			}
+			sums = append(sums, wantSum{typ: typ, want: value, hasher: typ.Hasher()})
+		}
+	}
+
+	var w io.Writer = io.Discard
+	if len(sums) > 0 {
+		writers := make([]io.Writer, 0, len(sums))
+		for _, s := range sums {
+			writers = append(writers, s.hasher)
+		}
+		w = io.MultiWriter(writers...)
+	}
+	if _, err := io.Copy(w, gr); err != nil {
+		return declared, err
+	}
+
+	for _, s := range sums {
+		got := base64.StdEncoding.EncodeToString(s.hasher.Sum(nil))
+		if got != s.want {
+			return declared, fmt.Errorf("%s checksum mismatch: declared %s, computed %s", s.typ.String(), s.want, got)
+		}
+	}
+
+	return declared, nil
+}
+
+// manifestObject returns the object name the signed manifest is
+// written to for this job.
+func (r *BatchJobVerifyV1) manifestObject(jobID string) (bucket, object string) {
+	bucket = r.Manifest.Bucket
+	if bucket == "" {
+		bucket = r.Bucket
+	}
+	prefix := r.Manifest.Prefix
+	if prefix == "" {
+		prefix = ".verify-manifests"
+	}
+	return bucket, pathJoin(prefix, fmt.Sprintf("verify-manifest-%s.json", jobID))
+}
+
+// writeManifest serializes, signs and stores the verification
+// manifest. The signature is an HMAC-SHA256 of the manifest bytes
+// keyed with the cluster root credentials and is stored as user
+// metadata on the manifest object, so any later tampering of the
+// manifest content is detectable by re-deriving the HMAC.
+func (r *BatchJobVerifyV1) writeManifest(ctx context.Context, api ObjectLayer, m batchVerifyManifest, jobID string) error {
+	data, err := json.MarshalIndent(m, "", " ")
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, []byte(globalActiveCred.SecretKey))
+	mac.Write(data)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	bucket, object := r.manifestObject(jobID)
+	hr, err := hash.NewReader(ctx, bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)))
+	if err != nil {
+		return err
+	}
+	_, err = api.PutObject(ctx, bucket, object, NewPutObjReader(hr), ObjectOptions{
+		UserDefined: map[string]string{
+			xhttp.ContentType:               "application/json",
+			"X-Amz-Meta-Manifest-Signature": signature,
+			"X-Amz-Meta-Manifest-Signed-By": "hmac-sha256-root-credentials",
+		},
+	})
+	return err
+}
+
+const (
+	batchVerifyName               = "batch-verify.bin"
+	batchVerifyFormat             = 1
+	batchVerifyVersionV1          = 1
+	batchVerifyVersion            = batchVerifyVersionV1
+	batchVerifyAPIVersion         = "v1"
+	batchVerifyJobDefaultRetries  = 3
+	batchVerifyJobDefaultRetryDel = 250 * time.Millisecond
+)
+
+// Start the batch verify job, resumes from the last checkpointed
+// object if there was a pending job via "job.ID". Note that on resume
+// the manifest covers only the remainder of the walk.
+func (r *BatchJobVerifyV1) Start(ctx context.Context, api ObjectLayer, job BatchJobRequest) error {
+	ri := &batchJobInfo{
+		JobID:     job.ID,
+		JobType:   string(job.Type()),
+		StartTime: job.Started,
+	}
+	if err := ri.loadOrInit(ctx, api, job); err != nil {
+		return err
+	}
+	if ri.Complete {
+		return nil
+	}
+
+	globalBatchJobsMetrics.save(job.ID, ri)
+	lastObject := ri.Object
+
+	retryAttempts := r.Flags.Retry.Attempts
+	if retryAttempts <= 0 {
+		retryAttempts = batchVerifyJobDefaultRetries
+	}
+	delay := r.Flags.Retry.Delay
+	if delay <= 0 {
+		delay = batchVerifyJobDefaultRetryDel
+	}
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	selectObj := func(info FileInfo) (ok bool) {
+		if r.Flags.Filter.OlderThan > 0 && time.Since(info.ModTime) < r.Flags.Filter.OlderThan.D() {
+			// skip all objects that are newer than specified older duration
+			return false
+		}
+
+		if r.Flags.Filter.NewerThan > 0 && time.Since(info.ModTime) >= r.Flags.Filter.NewerThan.D() {
+			// skip all objects that are older than specified newer duration
+			return false
+		}
+
+		if !r.Flags.Filter.CreatedAfter.IsZero() && r.Flags.Filter.CreatedAfter.Before(info.ModTime) {
+			// skip all objects that are created before the specified time.
+			return false
+		}
+
+		if !r.Flags.Filter.CreatedBefore.IsZero() && r.Flags.Filter.CreatedBefore.After(info.ModTime) {
+			// skip all objects that are created after the specified time.
+			return false
+		}
+
+		if len(r.Flags.Filter.Tags) > 0 {
+			// Only parse object tags if tags filter is specified.
+			tagMap := map[string]string{}
+			tagStr := info.Metadata[xhttp.AmzObjectTagging]
+			if len(tagStr) != 0 {
+				t, err := tags.ParseObjectTags(tagStr)
+				if err != nil {
+					return false
+				}
+				tagMap = t.ToMap()
+			}
+
+			for _, kv := range r.Flags.Filter.Tags {
+				for t, v := range tagMap {
+					if kv.Match(BatchJobKV{Key: t, Value: v}) {
+						return true
+					}
+				}
+			}
+
+			// None of the provided tags filter match skip the object
+			return false
+		}
+
+		if len(r.Flags.Filter.Metadata) > 0 {
+			for _, kv := range r.Flags.Filter.Metadata {
+				for k, v := range info.Metadata {
+					if !stringsHasPrefixFold(k, "x-amz-meta-") && !isStandardHeader(k) {
+						continue
+					}
+					// We only need to match x-amz-meta or standardHeaders
+					if kv.Match(BatchJobKV{Key: k, Value: v}) {
+						return true
+					}
+				}
+			}
+
+			// None of the provided metadata filters match skip the object.
+			return false
+		}
+
+		// Verify only the latest version, delete markers carry no content.
+		return info.IsLatest
+	}
+
+	workerSize, err := strconv.Atoi(env.Get("_MINIO_BATCH_VERIFY_WORKERS", strconv.Itoa(runtime.GOMAXPROCS(0)/2)))
+	if err != nil {
+		return err
+	}
+
+	wk, err := workers.New(workerSize)
+	if err != nil {
+		// invalid worker size.
+		return err
+	}
+
+	manifest := batchVerifyManifest{
+		Version:   batchVerifyAPIVersion,
+		JobID:     job.ID,
+		Bucket:    r.Bucket,
+		Prefix:    r.Prefix,
+		StartedAt: UTCNow(),
+	}
+	var manifestMu sync.Mutex
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	if err := api.Walk(ctx, r.Bucket, r.Prefix, results, WalkOptions{
+		Marker: lastObject,
+		Filter: selectObj,
+	}); err != nil {
+		cancel()
+		// Do not need to retry if we can't list objects on source.
+		return err
+	}
+	failed := false
+	for res := range results {
+		if res.Err != nil {
+			failed = true
+			batchLogIf(ctx, res.Err)
+			break
+		}
+		result := res.Item
+		if result.DeleteMarker || !result.VersionPurgeStatus.Empty() {
+			continue
+		}
+		wk.Take()
+		go func() {
+			defer wk.Give()
+			var declared map[string]string
+			var verifyErr error
+			for attempts := 1; attempts <= retryAttempts; attempts++ {
+				stopFn := globalBatchJobsMetrics.trace(batchJobMetricVerify, job.ID, attempts)
+				success := true
+				if declared, verifyErr = r.VerifyObject(ctx, api, result); verifyErr != nil {
+					stopFn(result, verifyErr)
+					batchLogIf(ctx, verifyErr)
+					success = false
+					if attempts >= retryAttempts {
+						auditOptions := AuditLogOptions{
+							Event:     "Verify",
+							APIName:   "StartBatchJob",
+							Bucket:    result.Bucket,
+							Object:    result.Name,
+							VersionID: result.VersionID,
+							Error:     verifyErr.Error(),
+						}
+						auditLogInternal(ctx, auditOptions)
+					}
+				} else {
+					stopFn(result, nil)
+				}
+				ri.trackCurrentBucketObject(r.Bucket, result, success, attempts)
+				globalBatchJobsMetrics.save(job.ID, ri)
+				// persist in-memory state to disk after every 10secs.
+				batchLogIf(ctx, ri.updateAfter(ctx, api, 10*time.Second, job))
+				if success {
+					break
+				}
+				if delay > 0 {
+					time.Sleep(delay + time.Duration(rnd.Float64()*float64(delay)))
+				}
+			}
+
+			entry := batchVerifyEntry{
+				Object:    result.Name,
+				VersionID: result.VersionID,
+				Size:      result.Size,
+				ETag:      result.ETag,
+				Checksums: declared,
+				Verified:  verifyErr == nil,
+			}
+			if verifyErr != nil {
+				entry.Error = verifyErr.Error()
+			}
+			manifestMu.Lock()
+			if entry.Verified {
+				manifest.Verified++
+			} else {
+				manifest.Failed++
+			}
+			manifest.Entries = append(manifest.Entries, entry)
+			manifestMu.Unlock()
+		}()
+	}
+	wk.Wait()
+
+	manifest.CompletedAt = UTCNow()
+	if err := r.writeManifest(ctx, api, manifest, job.ID); err != nil {
+		failed = true
+		batchLogIf(ctx, fmt.Errorf("unable to write verification manifest: %w", err))
+	}
+
+	ri.Complete = !failed && ri.ObjectsFailed == 0
+	ri.Failed = failed || ri.ObjectsFailed > 0
+	globalBatchJobsMetrics.save(job.ID, ri)
+	// persist in-memory state to disk.
+	batchLogIf(ctx, ri.updateAfter(ctx, api, 0, job))
+
+	if err := r.Notify(ctx, ri); err != nil {
+		batchLogIf(ctx, fmt.Errorf("unable to notify %v", err))
+	}
+
+	cancel()
+	return nil
+}
+
+//msgp:ignore batchVerifyJobError
+type batchVerifyJobError struct {
+	Code           string
+	Description    string
+	HTTPStatusCode int
+}
+
+func (e batchVerifyJobError) Error() string {
+	return e.Description
+}
+
+// Validate validates the job definition input
+func (r *BatchJobVerifyV1) Validate(ctx context.Context, job BatchJobRequest, o ObjectLayer) error {
+	if r == nil {
+		return nil
+	}
+
+	if r.APIVersion != batchVerifyAPIVersion {
+		return errInvalidArgument
+	}
+
+	if r.Bucket == "" {
+		return errInvalidArgument
+	}
+
+	if _, err := o.GetBucketInfo(ctx, r.Bucket, BucketOptions{}); err != nil {
+		if isErrBucketNotFound(err) {
+			return batchVerifyJobError{
+				Code:           "NoSuchSourceBucket",
+				Description:    fmt.Sprintf("The specified bucket %s does not exist", r.Bucket),
+				HTTPStatusCode: http.StatusNotFound,
+			}
+		}
+		return err
+	}
+
+	if r.Manifest.Bucket != "" {
+		if _, err := o.GetBucketInfo(ctx, r.Manifest.Bucket, BucketOptions{}); err != nil {
+			if isErrBucketNotFound(err) {
+				return batchVerifyJobError{
+					Code:           "NoSuchManifestBucket",
+					Description:    fmt.Sprintf("The specified bucket %s does not exist", r.Manifest.Bucket),
+					HTTPStatusCode: http.StatusNotFound,
+				}
+			}
+			return err
+		}
+	}
+
+	for _, tag := range r.Flags.Filter.Tags {
+		if err := tag.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, meta := range r.Flags.Filter.Metadata {
+		if err := meta.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return r.Flags.Retry.Validate()
+}
@@ -0,0 +1,294 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Batch job lifecycle webhooks. When MINIO_BATCH_WEBHOOK_ENDPOINT is
+// set every batch job posts started, progress, completed and failed
+// events there, independent of the per-job notify endpoint which only
+// fires once at the end. Progress events carry a completion percentage
+// and an ETA derived from a moving average of the job's object
+// throughput against the bucket's object count from data usage; both
+// are also surfaced through the batch status admin API.
+const (
+	// EnvBatchWebhookEndpoint receives batch job lifecycle events.
+	EnvBatchWebhookEndpoint = "MINIO_BATCH_WEBHOOK_ENDPOINT"
+
+	// EnvBatchWebhookAuthToken is sent as the Authorization header on
+	// lifecycle events.
+	EnvBatchWebhookAuthToken = "MINIO_BATCH_WEBHOOK_AUTH_TOKEN"
+
+	// batchJobProgressInterval is how often running jobs sample their
+	// progress and throughput.
+	batchJobProgressInterval = 30 * time.Second
+
+	// batchJobRateAlpha is the smoothing factor of the throughput
+	// moving average, newer samples weigh 30%.
+	batchJobRateAlpha = 0.3
+)
+
+// Batch job lifecycle event names.
+const (
+	batchJobEventStarted   = "started"
+	batchJobEventProgress  = "progress"
+	batchJobEventCompleted = "completed"
+	batchJobEventFailed    = "failed"
+)
+
+// batchJobEvent is the lifecycle webhook payload.
+type batchJobEvent struct {
+	JobID         string    `json:"jobId"`
+	JobType       string    `json:"jobType"`
+	Event         string    `json:"event"`
+	Timestamp     time.Time `json:"timestamp"`
+	Percent       float64   `json:"percent,omitempty"`
+	ETASeconds    int64     `json:"etaSeconds,omitempty"`
+	Objects       int64     `json:"objects"`
+	ObjectsFailed int64     `json:"objectsFailed"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// sendBatchJobEvent posts ev to the configured lifecycle endpoint,
+// no-op when none is configured. Failures are logged, never fatal to
+// the job.
+func sendBatchJobEvent(ctx context.Context, ev batchJobEvent) {
+	endpoint := env.Get(EnvBatchWebhookEndpoint, "")
+	if endpoint == "" {
+		return
+	}
+
+	buf, err := json.Marshal(ev)
+	if err != nil {
+		batchLogIf(ctx, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(buf))
+	if err != nil {
+		batchLogIf(ctx, err)
+		return
+	}
+	if token := env.Get(EnvBatchWebhookAuthToken, ""); token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	clnt := http.Client{Transport: getRemoteInstanceTransport()}
+	resp, err := clnt.Do(req)
+	if err != nil {
+		batchLogIf(ctx, err)
+		return
+	}
+	xhttp.DrainBody(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		batchLogIf(ctx, errors.New(resp.Status))
+	}
+}
+
+// batchJobProgress is the in-memory progress state of a running job.
+type batchJobProgress struct {
+	Total   int64         // estimated objects in scope, 0 when unknown
+	Rate    float64       // moving average, objects per second
+	Percent float64       // 0-100, only when Total is known
+	ETA     time.Duration // only when Total and Rate are known
+
+	lastObjects int64
+	lastSample  time.Time
+}
+
+// batchJobProgressTracker tracks progress of all running jobs on this
+// node.
+type batchJobProgressTracker struct {
+	mu   sync.RWMutex
+	jobs map[string]*batchJobProgress
+}
+
+var globalBatchJobProgress = &batchJobProgressTracker{
+	jobs: make(map[string]*batchJobProgress),
+}
+
+// get returns a copy of the progress of a running job.
+func (t *batchJobProgressTracker) get(jobID string) (batchJobProgress, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	p, ok := t.jobs[jobID]
+	if !ok {
+		return batchJobProgress{}, false
+	}
+	return *p, true
+}
+
+// info returns the in-memory state of a job, nil if unknown.
+func (m *batchJobMetrics) info(jobID string) *batchJobInfo {
+	m.RLock()
+	defer m.RUnlock()
+	return m.metrics[jobID]
+}
+
+// sourceBucket returns the bucket a job walks, used to estimate the
+// number of objects in scope.
+func (j *BatchJobRequest) sourceBucket() string {
+	switch {
+	case j.Replicate != nil:
+		return j.Replicate.Source.Bucket
+	case j.KeyRotate != nil:
+		return j.KeyRotate.Bucket
+	case j.Expire != nil:
+		return j.Expire.Bucket
+	case j.Copy != nil:
+		return j.Copy.Source.Bucket
+	case j.Verify != nil:
+		return j.Verify.Bucket
+	case j.Retag != nil:
+		return j.Retag.Bucket
+	case j.MPCleanup != nil:
+		return j.MPCleanup.Bucket
+	case j.Export != nil:
+		return j.Export.Bucket
+	}
+	return ""
+}
+
+// monitor emits the started event for job and begins periodic progress
+// sampling. The returned stop function ends sampling and emits the
+// completed or failed event from the job's final state.
+func (t *batchJobProgressTracker) monitor(job *BatchJobRequest, api ObjectLayer) (stop func()) {
+	p := &batchJobProgress{lastSample: time.Now()}
+
+	// Bucket object count is only an estimate - filters narrow the
+	// scope, writes change it - but good enough for an ETA.
+	if bucket := job.sourceBucket(); bucket != "" {
+		if dui, err := loadDataUsageFromBackend(job.ctx, api); err == nil {
+			if bui, ok := dui.BucketsUsage[bucket]; ok {
+				p.Total = int64(bui.ObjectsCount)
+			}
+		}
+	}
+
+	t.mu.Lock()
+	t.jobs[job.ID] = p
+	t.mu.Unlock()
+
+	sendBatchJobEvent(job.ctx, batchJobEvent{
+		JobID:     job.ID,
+		JobType:   string(job.Type()),
+		Event:     batchJobEventStarted,
+		Timestamp: UTCNow(),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		tick := time.NewTicker(batchJobProgressInterval)
+		defer tick.Stop()
+
+		var lastMilestone int64
+		for {
+			select {
+			case <-done:
+				return
+			case <-job.ctx.Done():
+				return
+			case <-tick.C:
+			}
+
+			ri := globalBatchJobsMetrics.info(job.ID)
+			if ri == nil {
+				continue
+			}
+			objects := ri.Objects + ri.DeleteMarkers + ri.ObjectsFailed
+
+			t.mu.Lock()
+			now := time.Now()
+			if elapsed := now.Sub(p.lastSample).Seconds(); elapsed > 0 {
+				instant := float64(objects-p.lastObjects) / elapsed
+				if p.Rate == 0 {
+					p.Rate = instant
+				} else {
+					p.Rate = (1-batchJobRateAlpha)*p.Rate + batchJobRateAlpha*instant
+				}
+			}
+			p.lastObjects = objects
+			p.lastSample = now
+			if p.Total > 0 {
+				p.Percent = min(100, float64(objects)*100/float64(p.Total))
+				if p.Rate > 0 {
+					p.ETA = time.Duration(float64(p.Total-objects) / p.Rate * float64(time.Second))
+				}
+			}
+			percent, eta := p.Percent, p.ETA
+			t.mu.Unlock()
+
+			// Emit progress whenever another 10% milestone is crossed.
+			if milestone := int64(percent) / 10; milestone > lastMilestone && percent < 100 {
+				lastMilestone = milestone
+				sendBatchJobEvent(job.ctx, batchJobEvent{
+					JobID:         job.ID,
+					JobType:       string(job.Type()),
+					Event:         batchJobEventProgress,
+					Timestamp:     UTCNow(),
+					Percent:       percent,
+					ETASeconds:    int64(eta / time.Second),
+					Objects:       ri.Objects,
+					ObjectsFailed: ri.ObjectsFailed,
+				})
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+
+		t.mu.Lock()
+		delete(t.jobs, job.ID)
+		t.mu.Unlock()
+
+		ev := batchJobEvent{
+			JobID:     job.ID,
+			JobType:   string(job.Type()),
+			Event:     batchJobEventCompleted,
+			Timestamp: UTCNow(),
+			Percent:   100,
+		}
+		if ri := globalBatchJobsMetrics.info(job.ID); ri != nil {
+			ev.Objects = ri.Objects
+			ev.ObjectsFailed = ri.ObjectsFailed
+			if ri.Failed {
+				ev.Event = batchJobEventFailed
+				ev.Percent = 0
+				ev.Error = fmt.Sprintf("%d of %d objects failed", ri.ObjectsFailed, ri.Objects+ri.ObjectsFailed)
+			}
+		}
+		sendBatchJobEvent(GlobalContext, ev)
+	}
+}
@@ -11,11 +11,16 @@ func _() {
 	_ = x[batchJobMetricReplication-0]
 	_ = x[batchJobMetricKeyRotation-1]
 	_ = x[batchJobMetricExpire-2]
+	_ = x[batchJobMetricCopy-3]
+	_ = x[batchJobMetricVerify-4]
+	_ = x[batchJobMetricRetag-5]
+	_ = x[batchJobMetricMPCleanup-6]
+	_ = x[batchJobMetricExport-7]
 }
 
-const _batchJobMetric_name = "ReplicationKeyRotationExpire"
+const _batchJobMetric_name = "ReplicationKeyRotationExpireCopyVerifyRetagMPCleanupExport"
 
-var _batchJobMetric_index = [...]uint8{0, 11, 22, 28}
+var _batchJobMetric_index = [...]uint8{0, 11, 22, 28, 32, 38, 43, 52, 58}
 
 func (i batchJobMetric) String() string {
 	if i >= batchJobMetric(len(_batchJobMetric_index)-1) {
@@ -0,0 +1,315 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// bucketEncryptionEnforceConfigFile holds the per-bucket encryption
+// enforcement rules, stored next to other per-bucket configuration
+// under the bucket metadata prefix.
+const bucketEncryptionEnforceConfigFile = "encryption-enforce.json"
+
+// Client-side encryption materials metadata keys as stored by the AWS
+// encryption SDKs. Their presence identifies client-side encrypted
+// uploads.
+var cseMaterialsHeaders = []string{
+	"X-Amz-Meta-X-Amz-Key",
+	"X-Amz-Meta-X-Amz-Key-V2",
+	"X-Amz-Meta-X-Amz-Matdesc",
+}
+
+// Supported values for encryption enforcement rules.
+const (
+	encEnforceSSEC   = "sse-c"
+	encEnforceSSEKMS = "sse-kms"
+	encEnforceAny    = "any"
+
+	encEnforceRequire = "require"
+	encEnforceDeny    = "deny"
+)
+
+// bucketEncryptionEnforceRule requires or denies encryption metadata for
+// objects uploaded under the given prefix. The first rule matching the
+// object name applies.
+type bucketEncryptionEnforceRule struct {
+	Prefix string `json:"prefix"`
+	// RequireSSE requires server-side encryption at PUT time, one of
+	// "sse-c", "sse-kms" or "any". Empty does not restrict SSE.
+	RequireSSE string `json:"requireSSE,omitempty"`
+	// CSEMaterials requires ("require") or rejects ("deny") client-side
+	// encryption materials metadata. Empty passes materials through
+	// unchanged without enforcement.
+	CSEMaterials string `json:"cseMaterials,omitempty"`
+}
+
+// bucketEncryptionEnforceConfig is the per-bucket encryption enforcement
+// configuration as stored on drives.
+type bucketEncryptionEnforceConfig struct {
+	Rules []bucketEncryptionEnforceRule `json:"rules"`
+}
+
+// parseBucketEncryptionEnforceConfig parses and validates enforcement rules.
+func parseBucketEncryptionEnforceConfig(data []byte) (*bucketEncryptionEnforceConfig, error) {
+	cfg := &bucketEncryptionEnforceConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	for _, rule := range cfg.Rules {
+		switch rule.RequireSSE {
+		case "", encEnforceSSEC, encEnforceSSEKMS, encEnforceAny:
+		default:
+			return nil, fmt.Errorf("invalid requireSSE value %q", rule.RequireSSE)
+		}
+		switch rule.CSEMaterials {
+		case "", encEnforceRequire, encEnforceDeny:
+		default:
+			return nil, fmt.Errorf("invalid cseMaterials value %q", rule.CSEMaterials)
+		}
+	}
+	return cfg, nil
+}
+
+// BucketEncryptionEnforceSys caches per-bucket encryption enforcement
+// rules applied at PUT time.
+type BucketEncryptionEnforceSys struct {
+	sync.RWMutex
+	configs map[string]*bucketEncryptionEnforceConfig
+}
+
+// NewBucketEncryptionEnforceSys - creates new encryption enforcement subsystem.
+func NewBucketEncryptionEnforceSys() *BucketEncryptionEnforceSys {
+	return &BucketEncryptionEnforceSys{
+		configs: make(map[string]*bucketEncryptionEnforceConfig),
+	}
+}
+
+func (sys *BucketEncryptionEnforceSys) set(bucket string, cfg *bucketEncryptionEnforceConfig) {
+	sys.Lock()
+	defer sys.Unlock()
+	sys.configs[bucket] = cfg
+}
+
+// Get returns the enforcement config of the bucket, nil if none is set.
+func (sys *BucketEncryptionEnforceSys) Get(ctx context.Context, bucket string) *bucketEncryptionEnforceConfig {
+	if sys == nil {
+		return nil
+	}
+
+	sys.RLock()
+	cfg, ok := sys.configs[bucket]
+	sys.RUnlock()
+	if ok {
+		return cfg
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return nil
+	}
+
+	data, err := readConfig(ctx, objAPI, path.Join(bucketMetaPrefix, bucket, bucketEncryptionEnforceConfigFile))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			// Negative cache lookups for buckets without rules.
+			sys.set(bucket, nil)
+		}
+		return nil
+	}
+
+	cfg, err = parseBucketEncryptionEnforceConfig(data)
+	if err != nil {
+		return nil
+	}
+	sys.set(bucket, cfg)
+	return cfg
+}
+
+// Set persists the enforcement config for the bucket, a nil config
+// clears all rules.
+func (sys *BucketEncryptionEnforceSys) Set(ctx context.Context, bucket string, cfg *bucketEncryptionEnforceConfig) error {
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+
+	configFile := path.Join(bucketMetaPrefix, bucket, bucketEncryptionEnforceConfigFile)
+	if cfg == nil || len(cfg.Rules) == 0 {
+		if err := deleteConfig(ctx, objAPI, configFile); err != nil && !errors.Is(err, errConfigNotFound) {
+			return err
+		}
+		sys.set(bucket, nil)
+		return nil
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err = saveConfig(ctx, objAPI, configFile, data); err != nil {
+		return err
+	}
+
+	sys.set(bucket, cfg)
+	return nil
+}
+
+// hasCSEMaterials returns true if the request carries client-side
+// encryption materials metadata.
+func hasCSEMaterials(h http.Header) bool {
+	for _, k := range cseMaterialsHeaders {
+		if h.Get(k) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkEncryptionEnforcement applies the first matching enforcement rule
+// to an incoming PUT request, returning a 400-level error code if the
+// request does not satisfy the rule.
+func (sys *BucketEncryptionEnforceSys) checkEncryptionEnforcement(ctx context.Context, r *http.Request, bucket, object string) APIErrorCode {
+	cfg := sys.Get(ctx, bucket)
+	if cfg == nil {
+		return ErrNone
+	}
+
+	for _, rule := range cfg.Rules {
+		if !strings.HasPrefix(object, rule.Prefix) {
+			continue
+		}
+
+		sseC := crypto.SSEC.IsRequested(r.Header)
+		sseKMS := crypto.S3KMS.IsRequested(r.Header)
+		switch rule.RequireSSE {
+		case encEnforceSSEC:
+			if !sseC {
+				return ErrInvalidEncryptionParametersSSEC
+			}
+		case encEnforceSSEKMS:
+			if !sseKMS {
+				return ErrInvalidEncryptionParameters
+			}
+		case encEnforceAny:
+			if !sseC && !sseKMS {
+				return ErrInvalidEncryptionParameters
+			}
+		}
+
+		switch rule.CSEMaterials {
+		case encEnforceRequire:
+			if !hasCSEMaterials(r.Header) {
+				return ErrInvalidEncryptionParameters
+			}
+		case encEnforceDeny:
+			if hasCSEMaterials(r.Header) {
+				return ErrInvalidEncryptionParameters
+			}
+		}
+
+		// Only the first matching rule applies.
+		break
+	}
+	return ErrNone
+}
+
+// PutBucketEncryptionEnforceHandler - sets encryption enforcement rules
+// for a bucket, an empty rule set clears the configuration.
+func (a adminAPIHandlers) PutBucketEncryptionEnforceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	var cfg *bucketEncryptionEnforceConfig
+	if len(data) > 0 {
+		cfg, err = parseBucketEncryptionEnforceConfig(data)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+	}
+
+	if err := globalBucketEncryptionEnforceSys.Set(ctx, bucket, cfg); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketEncryptionEnforceHandler - gets encryption enforcement rules
+// of a bucket.
+func (a adminAPIHandlers) GetBucketEncryptionEnforceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	cfg := globalBucketEncryptionEnforceSys.Get(ctx, bucket)
+	if cfg == nil {
+		cfg = &bucketEncryptionEnforceConfig{}
+	}
+
+	configData, err := json.Marshal(cfg)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, configData)
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Bucket maintenance freezes. A freeze puts a bucket in read-only or
+// fully frozen state independent of bucket policy - policy edits are
+// slow to reason about under incident pressure and easy to get wrong,
+// a freeze is one switch that is guaranteed to restore the previous
+// behavior when lifted. Blocked requests receive 503 with Retry-After
+// so well-behaved clients back off and resume on their own. A freeze
+// may carry an expiry after which it lifts automatically.
+
+const (
+	// bucketFreezeConfigFile is the per bucket config filename.
+	bucketFreezeConfigFile = "freeze.json"
+
+	// freezeModeReadOnly blocks writes, reads continue.
+	freezeModeReadOnly = "readonly"
+
+	// freezeModeFrozen blocks reads and writes.
+	freezeModeFrozen = "frozen"
+
+	// bucketFreezeCacheTTL bounds how stale the per node freeze view
+	// may be; a freeze set on one node applies cluster wide within
+	// this window.
+	bucketFreezeCacheTTL = 10 * time.Second
+
+	// freezeDefaultRetryAfter is advertised when the freeze has no
+	// expiry to derive a better value from.
+	freezeDefaultRetryAfter = 60
+)
+
+// bucketFreeze is the stored freeze state of one bucket.
+type bucketFreeze struct {
+	Mode   string `json:"mode"`
+	Reason string `json:"reason,omitempty"`
+	SetBy  string `json:"setBy"`
+
+	SetAt time.Time `json:"setAt"`
+
+	// ExpiresAt lifts the freeze automatically, zero keeps it until
+	// removed explicitly.
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+// expired reports whether the freeze has lifted on its own.
+func (f bucketFreeze) expired() bool {
+	return !f.ExpiresAt.IsZero() && UTCNow().After(f.ExpiresAt)
+}
+
+// blocksRead reports whether read requests are blocked.
+func (f bucketFreeze) blocksRead() bool {
+	return f.Mode == freezeModeFrozen
+}
+
+// retryAfterSeconds derives the Retry-After value from the remaining
+// freeze duration when known.
+func (f bucketFreeze) retryAfterSeconds() int {
+	if f.ExpiresAt.IsZero() {
+		return freezeDefaultRetryAfter
+	}
+	secs := int(time.Until(f.ExpiresAt) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
+
+// bucketFreezePath returns the config location relative to
+// minioMetaBucket.
+func bucketFreezePath(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, bucketFreezeConfigFile)
+}
+
+type freezeCacheEntry struct {
+	freeze  *bucketFreeze
+	fetched time.Time
+}
+
+var (
+	bucketFreezeCacheMu sync.Mutex
+	bucketFreezeCache   = make(map[string]freezeCacheEntry)
+)
+
+// getBucketFreeze returns the active freeze of a bucket, nil when the
+// bucket is not frozen. Lookups are cached briefly since every S3
+// request consults this.
+func getBucketFreeze(ctx context.Context, bucket string) *bucketFreeze {
+	api := newObjectLayerFn()
+	if api == nil {
+		return nil
+	}
+
+	bucketFreezeCacheMu.Lock()
+	entry, found := bucketFreezeCache[bucket]
+	bucketFreezeCacheMu.Unlock()
+	if found && time.Since(entry.fetched) < bucketFreezeCacheTTL {
+		if entry.freeze != nil && entry.freeze.expired() {
+			return nil
+		}
+		return entry.freeze
+	}
+
+	var freeze *bucketFreeze
+	data, err := readConfig(ctx, api, bucketFreezePath(bucket))
+	switch {
+	case err == nil:
+		var f bucketFreeze
+		if err = json.Unmarshal(data, &f); err == nil && !f.expired() {
+			freeze = &f
+		}
+	case errors.Is(err, errConfigNotFound):
+	default:
+		// Fail open - an unreadable config must not take the bucket
+		// offline harder than the freeze would.
+		internalLogIf(ctx, err)
+	}
+
+	bucketFreezeCacheMu.Lock()
+	bucketFreezeCache[bucket] = freezeCacheEntry{freeze: freeze, fetched: time.Now()}
+	bucketFreezeCacheMu.Unlock()
+	return freeze
+}
+
+// invalidateBucketFreeze drops the local cache entry after an update.
+func invalidateBucketFreeze(bucket string) {
+	bucketFreezeCacheMu.Lock()
+	delete(bucketFreezeCache, bucket)
+	bucketFreezeCacheMu.Unlock()
+}
+
+// setBucketFreezeMiddleware rejects S3 requests against frozen
+// buckets: writes under both modes, reads only when fully frozen.
+func setBucketFreezeMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket, _ := request2BucketObjectName(r)
+		if bucket == "" || isMinioReservedBucket(bucket) || isMinioMetaBucket(bucket) ||
+			guessIsRPCReq(r) || guessIsBrowserReq(r) || guessIsHealthCheckReq(r) ||
+			guessIsMetricsReq(r) || isAdminReq(r) || isKMSReq(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		freeze := getBucketFreeze(r.Context(), bucket)
+		if freeze == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		isRead := r.Method == http.MethodGet || r.Method == http.MethodHead
+		if isRead && !freeze.blocksRead() {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		errCode := ErrSlowDownWrite
+		if isRead {
+			errCode = ErrSlowDownRead
+		}
+		apiErr := errorCodes.ToAPIErr(errCode)
+		apiErr.Description = "The bucket is frozen for maintenance, please retry later"
+		if freeze.Reason != "" {
+			apiErr.Description += ": " + freeze.Reason
+		}
+		w.Header().Set(xhttp.RetryAfter, strconv.Itoa(freeze.retryAfterSeconds()))
+		writeErrorResponse(r.Context(), w, apiErr, r.URL)
+	})
+}
+
+// SetBucketFreezeHandler - PUT /minio/admin/v3/bucket-freeze?bucket=&mode=
+// Freezes a bucket. Optional query parameters: expiry (seconds after
+// which the freeze lifts automatically) and reason (echoed to blocked
+// clients).
+func (a adminAPIHandlers) SetBucketFreezeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := pathClean(mux.Vars(r)["bucket"])
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	mode := r.Form.Get("mode")
+	if mode != freezeModeReadOnly && mode != freezeModeFrozen {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	freeze := bucketFreeze{
+		Mode:   mode,
+		Reason: r.Form.Get("reason"),
+		SetBy:  cred.AccessKey,
+		SetAt:  UTCNow(),
+	}
+	if v := r.Form.Get("expiry"); v != "" {
+		secs, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || secs <= 0 {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+		freeze.ExpiresAt = UTCNow().Add(time.Duration(secs) * time.Second)
+	}
+
+	data, err := json.Marshal(freeze)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if err = saveConfig(ctx, objectAPI, bucketFreezePath(bucket), data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	invalidateBucketFreeze(bucket)
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:   "SetBucketFreeze",
+		APIName: "SetBucketFreeze",
+		Bucket:  bucket,
+		Status:  "success",
+		Tags:    map[string]string{"mode": mode},
+	})
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketFreezeHandler - GET /minio/admin/v3/bucket-freeze?bucket=
+// Returns a bucket's freeze state, 404 when not frozen.
+func (a adminAPIHandlers) GetBucketFreezeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := pathClean(mux.Vars(r)["bucket"])
+	data, err := readConfig(ctx, objectAPI, bucketFreezePath(bucket))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminNoSuchConfigTarget), r.URL)
+			return
+		}
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	var freeze bucketFreeze
+	if err = json.Unmarshal(data, &freeze); err != nil || freeze.expired() {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminNoSuchConfigTarget), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// RemoveBucketFreezeHandler - DELETE /minio/admin/v3/bucket-freeze?bucket=
+// Lifts a bucket's freeze.
+func (a adminAPIHandlers) RemoveBucketFreezeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := pathClean(mux.Vars(r)["bucket"])
+	if err := deleteConfig(ctx, objectAPI, bucketFreezePath(bucket)); err != nil && !errors.Is(err, errConfigNotFound) {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	invalidateBucketFreeze(bucket)
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:   "RemoveBucketFreeze",
+		APIName: "RemoveBucketFreeze",
+		Bucket:  bucket,
+		Status:  "success",
+	})
+
+	writeSuccessNoContent(w)
+}
@@ -229,8 +229,9 @@ func (api objectAPIHandlers) GetBucketLocationHandler(w http.ResponseWriter, r *
 
 	// Generate response.
 	encodedSuccessResponse := encodeResponse(LocationResponse{})
-	// Get current region.
-	region := globalSite.Region()
+	// Get the effective region of the bucket, the per-bucket label if
+	// one is set, deployment region otherwise.
+	region := globalBucketRegionSys.Region(ctx, bucket)
 	if region != globalMinioDefaultRegion {
 		encodedSuccessResponse = encodeResponse(LocationResponse{
 			Location: region,
@@ -492,6 +493,13 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 		hasLockEnabled = true
 	}
 
+	// Soft delete protection applies to multi-delete like to single
+	// deletes: plain deletes only stack a delete marker, removing a
+	// specific version permanently requires the governance bypass
+	// permission. Replica deletes carry the source's decision.
+	replica := r.Header.Get(xhttp.AmzBucketReplicationStatus) == replication.Replica.String()
+	softDeleteEnabled := !replica && bucketSoftDeleteEnabled(ctx, bucket)
+
 	type deleteResult struct {
 		delInfo DeletedObject
 		errInfo DeleteError
@@ -582,6 +590,19 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 			}
 		}
 
+		if object.VersionID != "" && softDeleteEnabled {
+			if apiErrCode := checkRequestAuthTypeWithVID(ctx, r, policy.BypassGovernanceRetentionAction, bucket, object.ObjectName, object.VersionID); apiErrCode != ErrNone {
+				apiErr := errorCodes.ToAPIErr(apiErrCode)
+				deleteResults[index].errInfo = DeleteError{
+					Code:      apiErr.Code,
+					Message:   apiErr.Description,
+					Key:       object.ObjectName,
+					VersionID: object.VersionID,
+				}
+				continue
+			}
+		}
+
 		// Avoid duplicate objects, we use map to filter them out.
 		if _, ok := objectsToDelete[object]; !ok {
 			objectsToDelete[object] = index
@@ -601,9 +622,16 @@ func (api objectAPIHandlers) DeleteMultipleObjectsHandler(w http.ResponseWriter,
 	// Disable timeouts and cancellation
 	ctx = bgContext(ctx)
 
+	prefixEnabledFn := vc.PrefixEnabled
+	if softDeleteEnabled {
+		// Plain deletes on a soft delete protected bucket always
+		// stack a delete marker, like the single delete path.
+		prefixEnabledFn = func(string) bool { return true }
+	}
+
 	deleteList := toNames(objectsToDelete)
 	dObjects, errs := deleteObjectsFn(ctx, bucket, deleteList, ObjectOptions{
-		PrefixEnabledFn:  vc.PrefixEnabled,
+		PrefixEnabledFn:  prefixEnabledFn,
 		VersionSuspended: vc.Suspended(),
 	})
 
@@ -1255,6 +1283,7 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	addProvenanceMetadata(ctx, r, metadata)
 
 	rawReader := hashReader
 	pReader := NewPutObjReader(rawReader)
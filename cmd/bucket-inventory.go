@@ -0,0 +1,486 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Scheduled bucket inventory reports. Inventory runs list a bucket into
+// gzip compressed CSV data files in a destination bucket and write a
+// manifest compatible with the S3 Inventory manifest format, so
+// downstream tooling built for S3 Inventory works unchanged. ORC and
+// Parquet outputs are not supported.
+const (
+	// inventoryConfigFile is the per bucket inventory configuration,
+	// kept with the other bucket metadata.
+	inventoryConfigFile = "inventory.json"
+
+	inventoryFormatCSV = "CSV"
+
+	// inventoryRowsPerFile caps the rows of one data file, larger
+	// listings are split across multiple files.
+	inventoryRowsPerFile = 100000
+
+	// inventoryCheckInterval is how often the scheduler looks for due
+	// inventory runs.
+	inventoryCheckInterval = time.Hour
+
+	inventoryScheduleDaily  = "daily"
+	inventoryScheduleWeekly = "weekly"
+
+	// inventoryManifestVersion is the S3 Inventory manifest version
+	// emitted in every manifest.
+	inventoryManifestVersion = "2016-11-30"
+)
+
+// inventoryFileSchema is the column order of the CSV data files, also
+// emitted in the manifest.
+var inventoryFileSchema = []string{
+	"Bucket", "Key", "VersionId", "IsLatest", "IsDeleteMarker", "Size",
+	"LastModifiedDate", "ETag", "StorageClass", "IsMultipartUploaded",
+	"ReplicationStatus", "EncryptionStatus",
+}
+
+// inventoryConfig is the per bucket inventory configuration.
+type inventoryConfig struct {
+	ID              string    `json:"id"`
+	Prefix          string    `json:"prefix,omitempty"`
+	Destination     string    `json:"destination"`
+	DestPrefix      string    `json:"destPrefix,omitempty"`
+	Format          string    `json:"format"`
+	Schedule        string    `json:"schedule"`
+	IncludeVersions bool      `json:"includeVersions"`
+	LastRun         time.Time `json:"lastRun,omitempty"`
+}
+
+func (cfg inventoryConfig) period() time.Duration {
+	if cfg.Schedule == inventoryScheduleWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+func inventoryConfigPath(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, inventoryConfigFile)
+}
+
+// inventoryManifestFile describes one data file in the manifest.
+type inventoryManifestFile struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	MD5Checksum string `json:"MD5checksum"`
+}
+
+// inventoryManifest mirrors the S3 Inventory manifest.json layout.
+type inventoryManifest struct {
+	SourceBucket      string                  `json:"sourceBucket"`
+	DestinationBucket string                  `json:"destinationBucket"`
+	Version           string                  `json:"version"`
+	CreationTimestamp string                  `json:"creationTimestamp"`
+	FileFormat        string                  `json:"fileFormat"`
+	FileSchema        string                  `json:"fileSchema"`
+	Files             []inventoryManifestFile `json:"files"`
+}
+
+// inventoryRuns guards against concurrent runs of the same bucket.
+var inventoryRuns = struct {
+	mu      sync.Mutex
+	running map[string]bool
+}{running: make(map[string]bool)}
+
+func inventoryTryLock(bucket string) bool {
+	inventoryRuns.mu.Lock()
+	defer inventoryRuns.mu.Unlock()
+	if inventoryRuns.running[bucket] {
+		return false
+	}
+	inventoryRuns.running[bucket] = true
+	return true
+}
+
+func inventoryUnlock(bucket string) {
+	inventoryRuns.mu.Lock()
+	delete(inventoryRuns.running, bucket)
+	inventoryRuns.mu.Unlock()
+}
+
+// inventoryEncryptionStatus returns the encryption column value of one
+// object version.
+func inventoryEncryptionStatus(oi ObjectInfo) string {
+	switch {
+	case crypto.S3KMS.IsEncrypted(oi.UserDefined):
+		return "SSE-KMS"
+	case crypto.S3.IsEncrypted(oi.UserDefined):
+		return "SSE-S3"
+	case crypto.SSEC.IsEncrypted(oi.UserDefined):
+		return "SSE-C"
+	}
+	return "NOT-SSE"
+}
+
+// putInventoryObject writes one inventory artifact to the destination
+// bucket and returns its manifest entry.
+func putInventoryObject(ctx context.Context, objectAPI ObjectLayer, bucket, object string, data []byte) (inventoryManifestFile, error) {
+	md5Hex := getMD5Hash(data)
+	hashReader, err := hash.NewReader(ctx, bytes.NewReader(data), int64(len(data)), md5Hex, getSHA256Hash(data), int64(len(data)))
+	if err != nil {
+		return inventoryManifestFile{}, err
+	}
+	if _, err = objectAPI.PutObject(ctx, bucket, object, NewPutObjReader(hashReader), ObjectOptions{}); err != nil {
+		return inventoryManifestFile{}, err
+	}
+	return inventoryManifestFile{
+		Key:         object,
+		Size:        int64(len(data)),
+		MD5Checksum: md5Hex,
+	}, nil
+}
+
+// generateBucketInventory produces one inventory run of the bucket:
+// gzip compressed CSV data files plus an S3 Inventory compatible
+// manifest under the destination prefix.
+func generateBucketInventory(ctx context.Context, objectAPI ObjectLayer, bucket string, cfg inventoryConfig) error {
+	if !inventoryTryLock(bucket) {
+		return fmt.Errorf("inventory run for bucket %s is already in progress", bucket)
+	}
+	defer inventoryUnlock(bucket)
+
+	if _, err := objectAPI.GetBucketInfo(ctx, cfg.Destination, BucketOptions{}); err != nil {
+		return fmt.Errorf("inventory destination bucket %s: %w", cfg.Destination, err)
+	}
+
+	runID := time.Now().UTC().Format("2006-01-02T15-04Z")
+	dataPrefix := pathJoin(cfg.DestPrefix, bucket, cfg.ID, "data")
+
+	var (
+		buf      bytes.Buffer
+		zw       *gzip.Writer
+		cw       *csv.Writer
+		rows     int
+		fileSeq  int
+		files    []inventoryManifestFile
+		flushErr error
+	)
+
+	newFile := func() {
+		buf.Reset()
+		zw = gzip.NewWriter(&buf)
+		cw = csv.NewWriter(zw)
+		rows = 0
+	}
+	flushFile := func() {
+		if rows == 0 {
+			return
+		}
+		cw.Flush()
+		if flushErr = cw.Error(); flushErr != nil {
+			return
+		}
+		if flushErr = zw.Close(); flushErr != nil {
+			return
+		}
+		fileSeq++
+		object := pathJoin(dataPrefix, fmt.Sprintf("%s-%05d.csv.gz", runID, fileSeq))
+		var file inventoryManifestFile
+		if file, flushErr = putInventoryObject(ctx, objectAPI, cfg.Destination, object, buf.Bytes()); flushErr != nil {
+			return
+		}
+		files = append(files, file)
+		newFile()
+	}
+
+	newFile()
+
+	results := make(chan itemOrErr[ObjectInfo])
+	if err := objectAPI.Walk(ctx, bucket, cfg.Prefix, results, WalkOptions{
+		LatestOnly: !cfg.IncludeVersions,
+	}); err != nil {
+		return err
+	}
+
+	for res := range results {
+		if res.Err != nil {
+			return res.Err
+		}
+		oi := res.Item
+
+		versionID := oi.VersionID
+		if !cfg.IncludeVersions {
+			versionID = ""
+		}
+		if err := cw.Write([]string{
+			bucket,
+			oi.Name,
+			versionID,
+			strconv.FormatBool(oi.IsLatest),
+			strconv.FormatBool(oi.DeleteMarker),
+			strconv.FormatInt(oi.Size, 10),
+			oi.ModTime.UTC().Format(time.RFC3339),
+			oi.ETag,
+			filterStorageClass(ctx, oi.StorageClass),
+			strconv.FormatBool(strings.Contains(oi.ETag, "-")),
+			oi.ReplicationStatus.String(),
+			inventoryEncryptionStatus(oi),
+		}); err != nil {
+			return err
+		}
+		rows++
+		if rows >= inventoryRowsPerFile {
+			flushFile()
+			if flushErr != nil {
+				return flushErr
+			}
+		}
+	}
+	flushFile()
+	if flushErr != nil {
+		return flushErr
+	}
+
+	manifest := inventoryManifest{
+		SourceBucket:      bucket,
+		DestinationBucket: "arn:aws:s3:::" + cfg.Destination,
+		Version:           inventoryManifestVersion,
+		CreationTimestamp: strconv.FormatInt(time.Now().UnixMilli(), 10),
+		FileFormat:        inventoryFormatCSV,
+		FileSchema:        strings.Join(inventoryFileSchema, ", "),
+		Files:             files,
+	}
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	runPrefix := pathJoin(cfg.DestPrefix, bucket, cfg.ID, runID)
+	if _, err = putInventoryObject(ctx, objectAPI, cfg.Destination, pathJoin(runPrefix, "manifest.json"), manifestData); err != nil {
+		return err
+	}
+	_, err = putInventoryObject(ctx, objectAPI, cfg.Destination, pathJoin(runPrefix, "manifest.checksum"), []byte(getMD5Hash(manifestData)))
+	return err
+}
+
+// runInventoryIfDue runs the bucket inventory when its schedule period
+// has elapsed, persisting the new run timestamp afterwards.
+func runInventoryIfDue(ctx context.Context, objectAPI ObjectLayer, bucket string) {
+	data, err := readConfig(ctx, objectAPI, inventoryConfigPath(bucket))
+	if err != nil {
+		if err != errConfigNotFound {
+			internalLogIf(ctx, err)
+		}
+		return
+	}
+	var cfg inventoryConfig
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		internalLogIf(ctx, err)
+		return
+	}
+	if time.Since(cfg.LastRun) < cfg.period() {
+		return
+	}
+	if err = generateBucketInventory(ctx, objectAPI, bucket, cfg); err != nil {
+		internalLogIf(ctx, fmt.Errorf("inventory run failed for bucket %s: %w", bucket, err))
+		return
+	}
+	cfg.LastRun = time.Now().UTC()
+	data, err = json.Marshal(cfg)
+	if err == nil {
+		err = saveConfig(ctx, objectAPI, inventoryConfigPath(bucket), data)
+	}
+	internalLogIf(ctx, err)
+}
+
+// initBucketInventory starts the inventory scheduler.
+func initBucketInventory(ctx context.Context, objectAPI ObjectLayer) {
+	go func() {
+		ticker := time.NewTicker(inventoryCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			buckets, err := objectAPI.ListBuckets(ctx, BucketOptions{})
+			if err != nil {
+				internalLogIf(ctx, err)
+				continue
+			}
+			for _, bucket := range buckets {
+				runInventoryIfDue(ctx, objectAPI, bucket.Name)
+			}
+		}
+	}()
+}
+
+// PutBucketInventoryHandler - configures inventory generation for a
+// bucket.
+//
+// POST /minio/admin/v3/inventory?bucket=...
+func (a adminAPIHandlers) PutBucketInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	if bucket == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	var cfg inventoryConfig
+	if err := json.NewDecoder(io.LimitReader(r.Body, humanize.MiByte)).Decode(&cfg); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if cfg.Format == "" {
+		cfg.Format = inventoryFormatCSV
+	}
+	if cfg.Format != inventoryFormatCSV {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, fmt.Errorf("unsupported inventory format %s, only CSV is supported", cfg.Format)), r.URL)
+		return
+	}
+	if cfg.ID == "" || cfg.Destination == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	if cfg.Schedule != inventoryScheduleDaily && cfg.Schedule != inventoryScheduleWeekly {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	cfg.LastRun = time.Time{}
+
+	data, err := json.Marshal(cfg)
+	if err == nil {
+		err = saveConfig(ctx, objectAPI, inventoryConfigPath(bucket), data)
+	}
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketInventoryHandler - returns the inventory configuration of a
+// bucket.
+//
+// GET /minio/admin/v3/inventory?bucket=...
+func (a adminAPIHandlers) GetBucketInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	if bucket == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	data, err := readConfig(ctx, objectAPI, inventoryConfigPath(bucket))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// DeleteBucketInventoryHandler - removes the inventory configuration of
+// a bucket.
+//
+// DELETE /minio/admin/v3/inventory?bucket=...
+func (a adminAPIHandlers) DeleteBucketInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	if bucket == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	if err := deleteConfig(ctx, objectAPI, inventoryConfigPath(bucket)); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// RunBucketInventoryHandler - triggers an immediate inventory run of a
+// bucket.
+//
+// POST /minio/admin/v3/inventory/run?bucket=...
+func (a adminAPIHandlers) RunBucketInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	if bucket == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	data, err := readConfig(ctx, objectAPI, inventoryConfigPath(bucket))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	var cfg inventoryConfig
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	go func() {
+		if err := generateBucketInventory(GlobalContext, objectAPI, bucket, cfg); err != nil {
+			internalLogIf(GlobalContext, fmt.Errorf("inventory run failed for bucket %s: %w", bucket, err))
+		}
+	}()
+
+	writeSuccessResponseHeadersOnly(w)
+}
@@ -1118,3 +1118,14 @@ func (oi ObjectInfo) ToLifecycleOpts() lifecycle.ObjectOpts {
 		TransitionStatus: oi.TransitionedObject.Status,
 	}
 }
+
+// lifecycleOptsWithAccessTime returns lifecycle.ObjectOpts for oi with
+// LastAccessTime filled in when lc has DaysSinceLastAccess rules and
+// access time tracking is enabled.
+func lifecycleOptsWithAccessTime(lc lifecycle.Lifecycle, oi ObjectInfo) lifecycle.ObjectOpts {
+	opts := oi.ToLifecycleOpts()
+	if globalATimeTracker != nil && lc.UsesAccessTime() {
+		opts.LastAccessTime = globalATimeTracker.lastAccess(GlobalContext, oi.Bucket, oi.Name)
+	}
+	return opts
+}
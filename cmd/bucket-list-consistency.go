@@ -0,0 +1,253 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"sync"
+
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// bucketListConsistencyConfigFile is the per-bucket default listing
+// consistency, stored next to other per-bucket configuration under the
+// bucket metadata prefix.
+const bucketListConsistencyConfigFile = "list-consistency.json"
+
+// Listing consistency modes. Strict is the regular quorum-consistent
+// listing, fast lists from a single drive per erasure set and may serve
+// very recent cached state.
+const (
+	listConsistencyStrict = "strict"
+	listConsistencyFast   = "fast"
+)
+
+// bucketListConsistencyConfig represents the default listing consistency
+// of a bucket as stored on drives.
+type bucketListConsistencyConfig struct {
+	Consistency string `json:"consistency"`
+}
+
+// listConsistencyCtxKey carries a per-request listing consistency
+// override through the object layer.
+type listConsistencyCtxKey struct{}
+
+// BucketListConsistencySys caches per-bucket default listing consistency
+// so latency-sensitive clients - e.g. browsing UIs - can opt into fast
+// listings per bucket while ETL workloads keep strict semantics.
+type BucketListConsistencySys struct {
+	sync.RWMutex
+	buckets map[string]string
+}
+
+// NewBucketListConsistencySys - creates new bucket listing consistency
+// subsystem.
+func NewBucketListConsistencySys() *BucketListConsistencySys {
+	return &BucketListConsistencySys{
+		buckets: make(map[string]string),
+	}
+}
+
+func (sys *BucketListConsistencySys) set(bucket, consistency string) {
+	sys.Lock()
+	defer sys.Unlock()
+	sys.buckets[bucket] = consistency
+}
+
+func (sys *BucketListConsistencySys) remove(bucket string) {
+	if sys == nil {
+		return
+	}
+	sys.Lock()
+	defer sys.Unlock()
+	delete(sys.buckets, bucket)
+}
+
+// Get returns the default listing consistency configured on the bucket,
+// empty if none is configured.
+func (sys *BucketListConsistencySys) Get(ctx context.Context, bucket string) string {
+	if sys == nil {
+		return ""
+	}
+
+	// Internal buckets carry no bucket metadata, looking their
+	// configuration up would recurse into the object layer on every
+	// internal listing.
+	if isMinioMetaBucketName(bucket) {
+		return ""
+	}
+
+	sys.RLock()
+	consistency, ok := sys.buckets[bucket]
+	sys.RUnlock()
+	if ok {
+		return consistency
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return ""
+	}
+
+	data, err := readConfig(ctx, objAPI, path.Join(bucketMetaPrefix, bucket, bucketListConsistencyConfigFile))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			// Negative cache lookups to avoid re-reading the
+			// backend for buckets without a configuration. A later
+			// Set drops this entry on every node through the peer
+			// notification.
+			sys.set(bucket, "")
+		}
+		return ""
+	}
+
+	var cfg bucketListConsistencyConfig
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return ""
+	}
+
+	sys.set(bucket, cfg.Consistency)
+	return cfg.Consistency
+}
+
+// Set persists the default listing consistency for the bucket, an empty
+// value clears the configuration.
+func (sys *BucketListConsistencySys) Set(ctx context.Context, bucket, consistency string) error {
+	switch consistency {
+	case "", listConsistencyStrict, listConsistencyFast:
+	default:
+		return errInvalidArgument
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+
+	configFile := path.Join(bucketMetaPrefix, bucket, bucketListConsistencyConfigFile)
+	if consistency == "" {
+		if err := deleteConfig(ctx, objAPI, configFile); err != nil && !errors.Is(err, errConfigNotFound) {
+			return err
+		}
+		sys.remove(bucket)
+	} else {
+		data, err := json.Marshal(bucketListConsistencyConfig{Consistency: consistency})
+		if err != nil {
+			return err
+		}
+		if err = saveConfig(ctx, objAPI, configFile, data); err != nil {
+			return err
+		}
+		sys.set(bucket, consistency)
+	}
+
+	// Peers cache this configuration too, including negative entries,
+	// tell them to drop theirs and re-read the persisted value.
+	globalNotificationSys.LoadBucketMetadata(bgContext(ctx), bucket)
+	return nil
+}
+
+// withListConsistency returns ctx carrying the per-request listing
+// consistency override from the 'x-minio-list-consistency' header, if
+// present and valid.
+func withListConsistency(ctx context.Context, r *http.Request) context.Context {
+	switch consistency := r.Header.Get(xhttp.MinIOListConsistency); consistency {
+	case listConsistencyStrict, listConsistencyFast:
+		return context.WithValue(ctx, listConsistencyCtxKey{}, consistency)
+	}
+	return ctx
+}
+
+// listQuorumFor returns the list quorum to ask for when listing bucket,
+// honoring a per-request consistency override first, the per-bucket
+// default second and falling back to the global API list quorum.
+func listQuorumFor(ctx context.Context, bucket string) string {
+	consistency, _ := ctx.Value(listConsistencyCtxKey{}).(string)
+	if consistency == "" {
+		consistency = globalBucketListConsistencySys.Get(ctx, bucket)
+	}
+	switch consistency {
+	case listConsistencyFast:
+		return "disk"
+	case listConsistencyStrict:
+		return "strict"
+	}
+	return globalAPIConfig.getListQuorum()
+}
+
+// SetBucketListConsistencyHandler - sets the default listing consistency
+// for a bucket, an empty value clears the configuration.
+func (a adminAPIHandlers) SetBucketListConsistencyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+	consistency := vars["consistency"]
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	if err := globalBucketListConsistencySys.Set(ctx, bucket, consistency); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketListConsistencyHandler - gets the default listing consistency
+// of a bucket.
+func (a adminAPIHandlers) GetBucketListConsistencyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	configData, err := json.Marshal(bucketListConsistencyConfig{
+		Consistency: globalBucketListConsistencySys.Get(ctx, bucket),
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, configData)
+}
@@ -88,6 +88,10 @@ func (api objectAPIHandlers) listObjectVersionsHandler(w http.ResponseWriter, r
 		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
 		return
 	}
+
+	// Honor a per-request listing consistency override, if requested.
+	ctx = withListConsistency(ctx, r)
+
 	var checkObjMeta metaCheckFn
 	if metadata {
 		checkObjMeta = func(name string, action policy.Action) (s3Err APIErrorCode) {
@@ -174,6 +178,9 @@ func (api objectAPIHandlers) listObjectsV2Handler(ctx context.Context, w http.Re
 		return
 	}
 
+	// Honor a per-request listing consistency override, if requested.
+	ctx = withListConsistency(ctx, r)
+
 	var checkObjMeta metaCheckFn
 	if metadata {
 		checkObjMeta = func(name string, action policy.Action) (s3Err APIErrorCode) {
@@ -200,7 +207,16 @@ func (api objectAPIHandlers) listObjectsV2Handler(ctx context.Context, w http.Re
 		err               error
 	)
 
-	if r.Header.Get(xMinIOExtract) == "true" && strings.Contains(prefix, archivePattern) {
+	if useListSnapshot(r, token) {
+		// Serve the page from a frozen enumeration so keys changing
+		// mid-pagination never show up inconsistently across pages.
+		var s3Error APIErrorCode
+		listObjectsV2Info, s3Error, err = listObjectsV2FromSnapshot(ctx, objectAPI, bucket, prefix, token, delimiter, startAfter, maxKeys, fetchOwner)
+		if s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+	} else if r.Header.Get(xMinIOExtract) == "true" && strings.Contains(prefix, archivePattern) {
 		// Initiate a list objects operation inside a zip file based in the input params
 		listObjectsV2Info, err = listObjectsV2InArchive(ctx, objectAPI, bucket, prefix, token, delimiter, maxKeys, startAfter, r.Header)
 	} else {
@@ -219,12 +235,11 @@ func (api objectAPIHandlers) listObjectsV2Handler(ctx context.Context, w http.Re
 		return
 	}
 
-	response := generateListObjectsV2Response(ctx, bucket, prefix, token, listObjectsV2Info.NextContinuationToken, startAfter,
+	// Stream the response instead of buffering the full page, entries
+	// are encoded to the wire as they are formatted.
+	streamListObjectsV2Response(ctx, w, bucket, prefix, token, listObjectsV2Info.NextContinuationToken, startAfter,
 		delimiter, encodingType, fetchOwner, listObjectsV2Info.IsTruncated,
 		maxKeys, listObjectsV2Info.Objects, listObjectsV2Info.Prefixes, checkObjMeta)
-
-	// Write success response.
-	writeSuccessResponseXML(w, encodeResponseList(response))
 }
 
 func parseRequestToken(token string) (subToken string, nodeIndex int) {
@@ -289,6 +304,9 @@ func (api objectAPIHandlers) ListObjectsV1Handler(w http.ResponseWriter, r *http
 		return
 	}
 
+	// Honor a per-request listing consistency override, if requested.
+	ctx = withListConsistency(ctx, r)
+
 	// Extract all the listObjectsV1 query params to their native values.
 	prefix, marker, delimiter, maxKeys, encodingType, s3Error := getListObjectsV1Args(r.Form)
 	if s3Error != ErrNone {
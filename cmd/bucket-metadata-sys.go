@@ -397,6 +397,18 @@ func (sys *BucketMetadataSys) GetQuotaConfig(ctx context.Context, bucket string)
 	return meta.quotaConfig, meta.QuotaConfigUpdatedAt, nil
 }
 
+// GetQuotaConfigExt returns the extended bucket quota limits.
+func (sys *BucketMetadataSys) GetQuotaConfigExt(ctx context.Context, bucket string) (bucketQuotaExt, error) {
+	meta, _, err := sys.GetConfig(ctx, bucket)
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return bucketQuotaExt{}, BucketQuotaConfigNotFound{Bucket: bucket}
+		}
+		return bucketQuotaExt{}, err
+	}
+	return meta.quotaConfigExt, nil
+}
+
 // GetReplicationConfig returns configured bucket replication config
 // The returned object may not be modified.
 func (sys *BucketMetadataSys) GetReplicationConfig(ctx context.Context, bucket string) (*replication.Config, time.Time, error) {
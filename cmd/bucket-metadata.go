@@ -104,6 +104,7 @@ type BucketMetadata struct {
 	sseConfig              *bucketsse.BucketSSEConfig
 	taggingConfig          *tags.Tags
 	quotaConfig            *madmin.BucketQuota
+	quotaConfigExt         bucketQuotaExt
 	replicationConfig      *replication.Config
 	bucketTargetConfig     *madmin.BucketTargets
 	bucketTargetConfigMeta map[string]string
@@ -336,6 +337,10 @@ func (b *BucketMetadata) parseAllConfigs(ctx context.Context, objectAPI ObjectLa
 		if err != nil {
 			return err
 		}
+		b.quotaConfigExt, err = parseBucketQuotaExt(b.Name, b.QuotaConfigJSON)
+		if err != nil {
+			return err
+		}
 	}
 
 	if len(b.ReplicationConfigXML) != 0 {
@@ -0,0 +1,272 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Per bucket multipart upload quotas. Uncommitted parts consume drive
+// space invisibly until something aborts the upload; a misbehaving
+// client can exhaust a drive long before lifecycle cleanup runs. The
+// quota caps how many multipart uploads a bucket may have in flight
+// and how long an upload may retain parts, both enforced at the API:
+// starting an upload beyond the cap fails with MultipartQuotaExceeded,
+// and uploads past the retention age are aborted server side the next
+// time the bucket's uploads are touched.
+
+// bucketMultipartQuotaConfig is the per bucket config filename, stored
+// alongside the other bucket configs under the bucket metadata prefix.
+const bucketMultipartQuotaConfig = "multipart-quota.json"
+
+// multipartQuotaConfig limits a bucket's in-flight multipart uploads.
+// Zero values leave the corresponding limit unenforced.
+type multipartQuotaConfig struct {
+	// MaxConcurrentUploads caps in-flight multipart uploads.
+	MaxConcurrentUploads int `json:"maxConcurrentUploads"`
+
+	// MaxRetentionSeconds is how long an upload may retain parts
+	// before it is aborted server side.
+	MaxRetentionSeconds int64 `json:"maxRetentionSeconds"`
+}
+
+// retentionAge returns the part retention limit as a duration.
+func (cfg multipartQuotaConfig) retentionAge() time.Duration {
+	return time.Duration(cfg.MaxRetentionSeconds) * time.Second
+}
+
+// enforced reports whether any limit is configured.
+func (cfg multipartQuotaConfig) enforced() bool {
+	return cfg.MaxConcurrentUploads > 0 || cfg.MaxRetentionSeconds > 0
+}
+
+// bucketMultipartQuotaPath returns the config location relative to
+// minioMetaBucket.
+func bucketMultipartQuotaPath(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, bucketMultipartQuotaConfig)
+}
+
+// getMultipartQuotaConfig loads a bucket's multipart quota, a missing
+// config reads as unenforced.
+func getMultipartQuotaConfig(ctx context.Context, api ObjectLayer, bucket string) (cfg multipartQuotaConfig, err error) {
+	data, err := readConfig(ctx, api, bucketMultipartQuotaPath(bucket))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	err = json.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+// abortExpiredUpload aborts one upload past the retention age and
+// leaves an audit trail of the enforcement.
+func abortExpiredUpload(ctx context.Context, api ObjectLayer, bucket string, upload MultipartInfo) {
+	err := api.AbortMultipartUpload(ctx, bucket, upload.Object, upload.UploadID, ObjectOptions{})
+	if err != nil {
+		internalLogIf(ctx, err)
+		return
+	}
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:   "AbortExpiredMultipartUpload",
+		APIName: "AbortMultipartUpload",
+		Bucket:  bucket,
+		Object:  upload.Object,
+		Status:  "success",
+		Tags: map[string]string{
+			"uploadId":  upload.UploadID,
+			"initiated": upload.Initiated.Format(time.RFC3339),
+		},
+	})
+}
+
+// enforceMultipartQuota is consulted before starting a new multipart
+// upload. It aborts uploads past the retention age and rejects the new
+// upload when the bucket is at its concurrency cap.
+func enforceMultipartQuota(ctx context.Context, api ObjectLayer, bucket string) APIErrorCode {
+	cfg, err := getMultipartQuotaConfig(ctx, api, bucket)
+	if err != nil {
+		internalLogIf(ctx, err)
+		return ErrNone
+	}
+	if !cfg.enforced() {
+		return ErrNone
+	}
+
+	var cutoff time.Time
+	if cfg.MaxRetentionSeconds > 0 {
+		cutoff = UTCNow().Add(-cfg.retentionAge())
+	}
+
+	active := 0
+	keyMarker, uploadIDMarker := "", ""
+	for {
+		result, err := api.ListMultipartUploads(ctx, bucket, "", keyMarker, uploadIDMarker, "", maxUploadsList)
+		if err != nil {
+			internalLogIf(ctx, err)
+			return ErrNone
+		}
+		for _, upload := range result.Uploads {
+			if !cutoff.IsZero() && upload.Initiated.Before(cutoff) {
+				abortExpiredUpload(ctx, api, bucket, upload)
+				continue
+			}
+			active++
+		}
+		if cfg.MaxConcurrentUploads > 0 && active >= cfg.MaxConcurrentUploads {
+			return ErrMultipartQuotaExceeded
+		}
+		if !result.IsTruncated {
+			return ErrNone
+		}
+		keyMarker, uploadIDMarker = result.NextKeyMarker, result.NextUploadIDMarker
+	}
+}
+
+// enforceMultipartRetention is consulted before completing an upload.
+// An upload past the retention age is aborted and reported as gone.
+func enforceMultipartRetention(ctx context.Context, api ObjectLayer, bucket, object, uploadID string) APIErrorCode {
+	cfg, err := getMultipartQuotaConfig(ctx, api, bucket)
+	if err != nil {
+		internalLogIf(ctx, err)
+		return ErrNone
+	}
+	if cfg.MaxRetentionSeconds <= 0 {
+		return ErrNone
+	}
+
+	info, err := api.GetMultipartInfo(ctx, bucket, object, uploadID, ObjectOptions{})
+	if err != nil {
+		// Let the complete call surface its own error.
+		return ErrNone
+	}
+	if info.Initiated.IsZero() || !info.Initiated.Before(UTCNow().Add(-cfg.retentionAge())) {
+		return ErrNone
+	}
+	abortExpiredUpload(ctx, api, bucket, info)
+	return ErrNoSuchUpload
+}
+
+// SetBucketMultipartQuotaHandler - PUT /minio/admin/v3/multipart-quota?bucket=
+// Places a multipart upload quota on a bucket.
+func (a adminAPIHandlers) SetBucketMultipartQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := pathClean(mux.Vars(r)["bucket"])
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxEConfigJSONSize))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+	var cfg multipartQuotaConfig
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminConfigBadJSON), r.URL)
+		return
+	}
+	if cfg.MaxConcurrentUploads < 0 || cfg.MaxRetentionSeconds < 0 {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	if err = saveConfig(ctx, objectAPI, bucketMultipartQuotaPath(bucket), data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:   "SetBucketMultipartQuota",
+		APIName: "SetBucketMultipartQuota",
+		Bucket:  bucket,
+		Status:  "success",
+		Tags: map[string]string{
+			"maxConcurrentUploads": strconv.Itoa(cfg.MaxConcurrentUploads),
+			"maxRetentionSeconds":  strconv.FormatInt(cfg.MaxRetentionSeconds, 10),
+		},
+	})
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketMultipartQuotaHandler - GET /minio/admin/v3/multipart-quota?bucket=
+// Returns a bucket's multipart upload quota.
+func (a adminAPIHandlers) GetBucketMultipartQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := pathClean(mux.Vars(r)["bucket"])
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	cfg, err := getMultipartQuotaConfig(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	buf, err := json.Marshal(cfg)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
+
+// RemoveBucketMultipartQuotaHandler - DELETE /minio/admin/v3/multipart-quota?bucket=
+// Removes a bucket's multipart upload quota.
+func (a adminAPIHandlers) RemoveBucketMultipartQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := pathClean(mux.Vars(r)["bucket"])
+	if err := deleteConfig(ctx, objectAPI, bucketMultipartQuotaPath(bucket)); err != nil && !errors.Is(err, errConfigNotFound) {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessNoContent(w)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Dual control (four-eyes) for governance bypass deletes. When
+// MINIO_GOVERNANCE_DUAL_CONTROL is enabled a delete carrying
+// x-amz-bypass-governance-retention no longer succeeds on the first
+// call even with s3:BypassGovernanceRetention granted - instead it
+// records a pending approval and fails until a second, distinct
+// credential approves the request through the admin API. The approval
+// is consumed by the delete that uses it and both sides of the
+// workflow leave audit entries.
+const (
+	// EnvGovernanceDualControl enables the four-eyes workflow for
+	// governance bypass deletes.
+	EnvGovernanceDualControl = "MINIO_GOVERNANCE_DUAL_CONTROL"
+
+	// govBypassApprovalPrefix is where pending approvals are stored in
+	// the metadata bucket.
+	govBypassApprovalPrefix = "governance-bypass-approvals"
+
+	// govBypassApprovalTTL bounds how long a request or an unused
+	// approval stays actionable.
+	govBypassApprovalTTL = 24 * time.Hour
+)
+
+// errGovernanceBypassPendingApproval - governance bypass delete awaits its second approval.
+var errGovernanceBypassPendingApproval = errors.New("governance bypass delete is pending approval by a second credential")
+
+// govDualControlEnabled returns true when the four-eyes workflow for
+// governance bypass deletes is turned on.
+func govDualControlEnabled() bool {
+	return env.Get(EnvGovernanceDualControl, config.EnableOff) == config.EnableOn
+}
+
+// govBypassApproval is one pending or approved governance bypass
+// delete request.
+type govBypassApproval struct {
+	Bucket      string    `json:"bucket"`
+	Object      string    `json:"object"`
+	VersionID   string    `json:"versionId,omitempty"`
+	RequestedBy string    `json:"requestedBy"`
+	RequestedAt time.Time `json:"requestedAt"`
+	Approved    bool      `json:"approved"`
+	ApprovedBy  string    `json:"approvedBy,omitempty"`
+	ApprovedAt  time.Time `json:"approvedAt,omitempty"`
+}
+
+// expired returns true once the request - or its unused approval - is
+// past the approval TTL.
+func (g govBypassApproval) expired() bool {
+	return time.Since(g.RequestedAt) > govBypassApprovalTTL
+}
+
+// govBypassApprovalPath returns the config path the approval for an
+// object version is stored at.
+func govBypassApprovalPath(bucket, object, versionID string) string {
+	sum := sha256.Sum256([]byte(pathJoin(bucket, object, versionID)))
+	return pathJoin(govBypassApprovalPrefix, hex.EncodeToString(sum[:])+".json")
+}
+
+// loadGovBypassApproval reads the stored approval for an object
+// version, errConfigNotFound when none exists.
+func loadGovBypassApproval(ctx context.Context, api ObjectLayer, bucket, object, versionID string) (govBypassApproval, error) {
+	var approval govBypassApproval
+	data, err := readConfig(ctx, api, govBypassApprovalPath(bucket, object, versionID))
+	if err != nil {
+		return approval, err
+	}
+	err = json.Unmarshal(data, &approval)
+	return approval, err
+}
+
+// saveGovBypassApproval persists the approval state of an object
+// version.
+func saveGovBypassApproval(ctx context.Context, api ObjectLayer, approval govBypassApproval) error {
+	data, err := json.Marshal(approval)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, api, govBypassApprovalPath(approval.Bucket, approval.Object, approval.VersionID), data)
+}
+
+// deleteGovBypassApproval removes the approval state of an object
+// version, used both when an approval is consumed and when a request
+// is rejected.
+func deleteGovBypassApproval(ctx context.Context, api ObjectLayer, bucket, object, versionID string) error {
+	return deleteConfig(ctx, api, govBypassApprovalPath(bucket, object, versionID))
+}
+
+// enforceGovernanceDualControl holds back a governance bypass delete
+// until a second credential has approved it. The first call records
+// the pending request and fails; once an approver distinct from the
+// requester signs off through the admin API the delete goes through
+// and consumes the approval. No-op unless dual control is enabled.
+func enforceGovernanceDualControl(ctx context.Context, r *http.Request, bucket, object, versionID string) error {
+	if !govDualControlEnabled() {
+		return nil
+	}
+
+	api := newObjectLayerFn()
+	if api == nil {
+		return errServerNotInitialized
+	}
+
+	cred := getReqAccessCred(r, globalSite.Region())
+
+	approval, err := loadGovBypassApproval(ctx, api, bucket, object, versionID)
+	if err == nil && !approval.expired() {
+		if approval.Approved && approval.ApprovedBy != cred.AccessKey {
+			// Consume the approval, a second bypass delete needs a fresh
+			// request/approval round.
+			internalLogIf(ctx, deleteGovBypassApproval(ctx, api, bucket, object, versionID))
+			auditLogInternal(ctx, AuditLogOptions{
+				Event:     "GovernanceBypassDelete",
+				APIName:   "DeleteObject",
+				Bucket:    bucket,
+				Object:    object,
+				VersionID: versionID,
+				Tags: map[string]string{
+					"requestedBy": approval.RequestedBy,
+					"approvedBy":  approval.ApprovedBy,
+					"deletedBy":   cred.AccessKey,
+				},
+			})
+			return nil
+		}
+		// Pending, or the approver is trying to use their own approval.
+		return errGovernanceBypassPendingApproval
+	}
+	if err != nil && !errors.Is(err, errConfigNotFound) {
+		return err
+	}
+
+	// First bypass attempt (or the previous round expired), record the
+	// pending request for the second approver.
+	approval = govBypassApproval{
+		Bucket:      bucket,
+		Object:      object,
+		VersionID:   versionID,
+		RequestedBy: cred.AccessKey,
+		RequestedAt: UTCNow(),
+	}
+	if err := saveGovBypassApproval(ctx, api, approval); err != nil {
+		return err
+	}
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:     "GovernanceBypassRequested",
+		APIName:   "DeleteObject",
+		Bucket:    bucket,
+		Object:    object,
+		VersionID: versionID,
+		Tags: map[string]string{
+			"requestedBy": cred.AccessKey,
+		},
+	})
+	return errGovernanceBypassPendingApproval
+}
@@ -153,6 +153,11 @@ func enforceRetentionBypassForDelete(ctx context.Context, r *http.Request, bucke
 			if checkRequestAuthType(ctx, r, policy.BypassGovernanceRetentionAction, bucket, object.ObjectName) != ErrNone {
 				return errAuthentication
 			}
+			// When dual control is enabled the bypass additionally needs
+			// an approval from a second, distinct credential.
+			if err := enforceGovernanceDualControl(ctx, r, bucket, object.ObjectName, object.VersionID); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
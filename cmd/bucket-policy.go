@@ -144,6 +144,11 @@ func getConditionValues(r *http.Request, lc string, cred auth.Credentials) map[s
 		args["LocationConstraint"] = []string{lc}
 	}
 
+	// Expose whether the request carries client-side encryption
+	// materials metadata, usable to require or deny client-side
+	// encrypted uploads via policy conditions.
+	args["RequestClientSideEncryption"] = []string{strconv.FormatBool(hasCSEMaterials(r.Header))}
+
 	cloneHeader := r.Header.Clone()
 	if v := cloneHeader.Get("x-amz-signature-age"); v != "" {
 		args["signatureAge"] = []string{v}
@@ -84,6 +84,47 @@ func (sys *BucketQuotaSys) GetBucketUsageInfo(ctx context.Context, bucket string
 	return BucketUsageInfo{}
 }
 
+// bucketQuotaExt holds the quota limits not covered by
+// madmin.BucketQuota, parsed from the same quota config JSON.
+type bucketQuotaExt struct {
+	// MaxObjects is the maximum number of objects allowed in the
+	// bucket, zero means unlimited.
+	MaxObjects int64 `json:"maxobjects,omitempty"`
+
+	// MaxVersions is the maximum number of versions allowed per
+	// object, zero means the server-wide limit applies.
+	MaxVersions int64 `json:"maxversions,omitempty"`
+}
+
+// parseBucketQuotaExt parses the extended quota limits from json.
+func parseBucketQuotaExt(bucket string, data []byte) (ext bucketQuotaExt, err error) {
+	if err = json.Unmarshal(data, &ext); err != nil {
+		return ext, err
+	}
+	if ext.MaxObjects < 0 || ext.MaxVersions < 0 {
+		return ext, fmt.Errorf("Invalid quota config %#v for bucket %s", ext, bucket)
+	}
+	return ext, nil
+}
+
+// GetExt - Get the extended quota limits.
+func (sys *BucketQuotaSys) GetExt(ctx context.Context, bucketName string) (bucketQuotaExt, error) {
+	return globalBucketMetadataSys.GetQuotaConfigExt(ctx, bucketName)
+}
+
+// bucketQuotaMaxVersions returns the per-bucket maximum versions per
+// object, zero when unset - the server-wide limit always applies.
+func bucketQuotaMaxVersions(bucket string) int64 {
+	if globalBucketMetadataSys == nil {
+		return 0
+	}
+	ext, err := globalBucketMetadataSys.GetQuotaConfigExt(GlobalContext, bucket)
+	if err != nil {
+		return 0
+	}
+	return ext.MaxVersions
+}
+
 // parseBucketQuota parses BucketQuota from json
 func parseBucketQuota(bucket string, data []byte) (quotaCfg *madmin.BucketQuota, err error) {
 	quotaCfg = &madmin.BucketQuota{}
@@ -118,13 +159,21 @@ func (sys *BucketQuotaSys) enforceQuotaHard(ctx context.Context, bucket string,
 			quotaSize = q.Quota
 		}
 	}
-	if quotaSize > 0 {
-		if uint64(size) >= quotaSize { // check if file size already exceeds the quota
+	ext, err := sys.GetExt(ctx, bucket)
+	if err != nil {
+		return err
+	}
+
+	if quotaSize > 0 || ext.MaxObjects > 0 {
+		if quotaSize > 0 && uint64(size) >= quotaSize { // check if file size already exceeds the quota
 			return BucketQuotaExceeded{Bucket: bucket}
 		}
 
 		bui := sys.GetBucketUsageInfo(ctx, bucket)
-		if bui.Size > 0 && ((bui.Size + uint64(size)) >= quotaSize) {
+		if quotaSize > 0 && bui.Size > 0 && ((bui.Size + uint64(size)) >= quotaSize) {
+			return BucketQuotaExceeded{Bucket: bucket}
+		}
+		if ext.MaxObjects > 0 && bui.ObjectsCount >= uint64(ext.MaxObjects) {
 			return BucketQuotaExceeded{Bucket: bucket}
 		}
 	}
@@ -0,0 +1,199 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// bucketRegionConfigFile is the per-bucket region label, stored next to
+// other per-bucket configuration under the bucket metadata prefix.
+const bucketRegionConfigFile = "region.json"
+
+// bucketRegionConfig represents the region label as stored on drives.
+type bucketRegionConfig struct {
+	Region string `json:"region"`
+}
+
+// BucketRegionSys caches per-bucket region labels, used to emulate a
+// multi-region namespace on a consolidated deployment. GetBucketLocation
+// returns the label, and the label feeds the LocationConstraint policy
+// condition and replication rule evaluation. Buckets without an explicit
+// label fall back to the deployment region.
+type BucketRegionSys struct {
+	sync.RWMutex
+	regions map[string]string
+}
+
+// NewBucketRegionSys - creates new bucket region subsystem.
+func NewBucketRegionSys() *BucketRegionSys {
+	return &BucketRegionSys{
+		regions: make(map[string]string),
+	}
+}
+
+func (sys *BucketRegionSys) set(bucket, region string) {
+	sys.Lock()
+	defer sys.Unlock()
+	sys.regions[bucket] = region
+}
+
+func (sys *BucketRegionSys) remove(bucket string) {
+	sys.Lock()
+	defer sys.Unlock()
+	delete(sys.regions, bucket)
+}
+
+// Get returns the region label explicitly set on the bucket, the second
+// return value indicates if a label exists at all.
+func (sys *BucketRegionSys) Get(ctx context.Context, bucket string) (string, bool) {
+	if sys == nil {
+		return "", false
+	}
+
+	sys.RLock()
+	region, ok := sys.regions[bucket]
+	sys.RUnlock()
+	if ok {
+		return region, region != ""
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return "", false
+	}
+
+	data, err := readConfig(ctx, objAPI, path.Join(bucketMetaPrefix, bucket, bucketRegionConfigFile))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			// Negative cache lookups to avoid re-reading the
+			// backend for buckets without a label.
+			sys.set(bucket, "")
+		}
+		return "", false
+	}
+
+	var cfg bucketRegionConfig
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return "", false
+	}
+
+	sys.set(bucket, cfg.Region)
+	return cfg.Region, cfg.Region != ""
+}
+
+// Region returns the effective region of the bucket - the explicit label
+// when set, the deployment region otherwise.
+func (sys *BucketRegionSys) Region(ctx context.Context, bucket string) string {
+	if region, ok := sys.Get(ctx, bucket); ok {
+		return region
+	}
+	return globalSite.Region()
+}
+
+// Set persists the region label for the bucket, an empty region clears
+// the label.
+func (sys *BucketRegionSys) Set(ctx context.Context, bucket, region string) error {
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+
+	configFile := path.Join(bucketMetaPrefix, bucket, bucketRegionConfigFile)
+	if region == "" {
+		if err := deleteConfig(ctx, objAPI, configFile); err != nil && !errors.Is(err, errConfigNotFound) {
+			return err
+		}
+		sys.remove(bucket)
+		return nil
+	}
+
+	data, err := json.Marshal(bucketRegionConfig{Region: region})
+	if err != nil {
+		return err
+	}
+	if err = saveConfig(ctx, objAPI, configFile, data); err != nil {
+		return err
+	}
+
+	sys.set(bucket, region)
+	return nil
+}
+
+// SetBucketRegionHandler - sets the region label for a bucket, an empty
+// region clears the label reverting the bucket to the deployment region.
+func (a adminAPIHandlers) SetBucketRegionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+	region := vars["region"]
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	if err := globalBucketRegionSys.Set(ctx, bucket, region); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketRegionHandler - gets the effective region of a bucket.
+func (a adminAPIHandlers) GetBucketRegionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	configData, err := json.Marshal(bucketRegionConfig{
+		Region: globalBucketRegionSys.Region(ctx, bucket),
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, configData)
+}
@@ -0,0 +1,399 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Lightweight bucket snapshots. A snapshot captures the version
+// pointer of every key in a versioned bucket at a point in time
+// without copying any data - objects remain readable through their
+// recorded version IDs for as long as those versions exist. Snapshots
+// are stored as manifests under the minio metadata bucket, and two
+// snapshots of the same bucket can be diffed to enumerate what was
+// added, removed or overwritten between them.
+
+// bucketSnapshotPrefix is where snapshot manifests live, relative to
+// minioMetaBucket.
+const bucketSnapshotPrefix = ".snapshots"
+
+// snapshotEntry records one key's version pointer at snapshot time.
+type snapshotEntry struct {
+	Object    string    `json:"object"`
+	VersionID string    `json:"versionId,omitempty"`
+	ETag      string    `json:"etag,omitempty"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"modTime"`
+}
+
+// bucketSnapshot is a point-in-time view of a bucket's namespace.
+type bucketSnapshot struct {
+	Name      string          `json:"name"`
+	Bucket    string          `json:"bucket"`
+	CreatedBy string          `json:"createdBy"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Entries   []snapshotEntry `json:"entries"`
+}
+
+// snapshotDiffEntry describes one key that differs between two
+// snapshots.
+type snapshotDiffEntry struct {
+	Object      string `json:"object"`
+	FromVersion string `json:"fromVersion,omitempty"`
+	ToVersion   string `json:"toVersion,omitempty"`
+	FromSize    int64  `json:"fromSize,omitempty"`
+	ToSize      int64  `json:"toSize,omitempty"`
+}
+
+// snapshotDiff is the result of comparing two snapshots of one bucket.
+type snapshotDiff struct {
+	Bucket   string              `json:"bucket"`
+	From     string              `json:"from"`
+	To       string              `json:"to"`
+	Added    []snapshotDiffEntry `json:"added,omitempty"`
+	Removed  []snapshotDiffEntry `json:"removed,omitempty"`
+	Modified []snapshotDiffEntry `json:"modified,omitempty"`
+}
+
+// bucketSnapshotPath returns a snapshot manifest location relative to
+// minioMetaBucket.
+func bucketSnapshotPath(bucket, name string) string {
+	return pathJoin(bucketSnapshotPrefix, bucket, name+".json")
+}
+
+// validSnapshotName reports whether name is usable as a snapshot name.
+func validSnapshotName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, "/\\")
+}
+
+// loadBucketSnapshot reads a snapshot manifest.
+func loadBucketSnapshot(ctx context.Context, api ObjectLayer, bucket, name string) (snap bucketSnapshot, err error) {
+	data, err := readConfig(ctx, api, bucketSnapshotPath(bucket, name))
+	if err != nil {
+		return snap, err
+	}
+	err = json.Unmarshal(data, &snap)
+	return snap, err
+}
+
+// captureBucketSnapshot records the latest version pointer of every
+// key in the bucket. Keys whose latest version is a delete marker are
+// absent from the namespace and hence from the snapshot.
+func captureBucketSnapshot(ctx context.Context, api ObjectLayer, bucket, name, createdBy string) (bucketSnapshot, error) {
+	snap := bucketSnapshot{
+		Name:      name,
+		Bucket:    bucket,
+		CreatedBy: createdBy,
+		CreatedAt: UTCNow(),
+	}
+
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	if err := api.Walk(ctx, bucket, "", results, WalkOptions{
+		LatestOnly: true,
+	}); err != nil {
+		return snap, err
+	}
+	for res := range results {
+		if res.Err != nil {
+			return snap, res.Err
+		}
+		oi := res.Item
+		if oi.DeleteMarker {
+			continue
+		}
+		snap.Entries = append(snap.Entries, snapshotEntry{
+			Object:    oi.Name,
+			VersionID: oi.VersionID,
+			ETag:      oi.ETag,
+			Size:      oi.Size,
+			ModTime:   oi.ModTime,
+		})
+	}
+	return snap, nil
+}
+
+// diffBucketSnapshots compares two snapshots of the same bucket.
+func diffBucketSnapshots(from, to bucketSnapshot) snapshotDiff {
+	diff := snapshotDiff{
+		Bucket: from.Bucket,
+		From:   from.Name,
+		To:     to.Name,
+	}
+
+	fromEntries := make(map[string]snapshotEntry, len(from.Entries))
+	for _, e := range from.Entries {
+		fromEntries[e.Object] = e
+	}
+
+	for _, e := range to.Entries {
+		old, ok := fromEntries[e.Object]
+		if !ok {
+			diff.Added = append(diff.Added, snapshotDiffEntry{
+				Object:    e.Object,
+				ToVersion: e.VersionID,
+				ToSize:    e.Size,
+			})
+			continue
+		}
+		delete(fromEntries, e.Object)
+		if old.VersionID != e.VersionID || old.ETag != e.ETag {
+			diff.Modified = append(diff.Modified, snapshotDiffEntry{
+				Object:      e.Object,
+				FromVersion: old.VersionID,
+				ToVersion:   e.VersionID,
+				FromSize:    old.Size,
+				ToSize:      e.Size,
+			})
+		}
+	}
+
+	for _, old := range fromEntries {
+		diff.Removed = append(diff.Removed, snapshotDiffEntry{
+			Object:      old.Object,
+			FromVersion: old.VersionID,
+			FromSize:    old.Size,
+		})
+	}
+	return diff
+}
+
+// CreateBucketSnapshotHandler - POST /minio/admin/v3/snapshot?bucket=&snapshot=
+// Captures a point-in-time view of a bucket's namespace.
+func (a adminAPIHandlers) CreateBucketSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+	name := vars["snapshot"]
+	if !validSnapshotName(name) {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	// Snapshots are immutable once taken, refuse to overwrite.
+	if _, err := readConfig(ctx, objectAPI, bucketSnapshotPath(bucket, name)); err == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrMethodNotAllowed), r.URL)
+		return
+	} else if !errors.Is(err, errConfigNotFound) {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	snap, err := captureBucketSnapshot(ctx, objectAPI, bucket, name, cred.AccessKey)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if err = saveConfig(ctx, objectAPI, bucketSnapshotPath(bucket, name), data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:   "CreateBucketSnapshot",
+		APIName: "CreateBucketSnapshot",
+		Bucket:  bucket,
+		Status:  "success",
+		Tags: map[string]string{
+			"snapshot": name,
+			"entries":  strconv.Itoa(len(snap.Entries)),
+		},
+	})
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketSnapshotHandler - GET /minio/admin/v3/snapshot?bucket=&snapshot=
+// Returns a snapshot manifest; clients read through a snapshot by
+// fetching the recorded version IDs.
+func (a adminAPIHandlers) GetBucketSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+	name := vars["snapshot"]
+	if !validSnapshotName(name) {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	snap, err := loadBucketSnapshot(ctx, objectAPI, bucket, name)
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminNoSuchConfigTarget), r.URL)
+			return
+		}
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	buf, err := json.Marshal(snap)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
+
+// ListBucketSnapshotsHandler - GET /minio/admin/v3/snapshots?bucket=
+// Lists a bucket's snapshots without their entries.
+func (a adminAPIHandlers) ListBucketSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := pathClean(mux.Vars(r)["bucket"])
+
+	type snapshotInfo struct {
+		Name      string    `json:"name"`
+		CreatedBy string    `json:"createdBy"`
+		CreatedAt time.Time `json:"createdAt"`
+		Entries   int       `json:"entries"`
+	}
+	snapshots := []snapshotInfo{}
+
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	if err := objectAPI.Walk(ctx, minioMetaBucket, pathJoin(bucketSnapshotPrefix, bucket), results, WalkOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	for res := range results {
+		if res.Err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, res.Err), r.URL)
+			return
+		}
+		name := strings.TrimSuffix(pathClean(strings.TrimPrefix(res.Item.Name, pathJoin(bucketSnapshotPrefix, bucket))), ".json")
+		snap, err := loadBucketSnapshot(ctx, objectAPI, bucket, name)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshotInfo{
+			Name:      snap.Name,
+			CreatedBy: snap.CreatedBy,
+			CreatedAt: snap.CreatedAt,
+			Entries:   len(snap.Entries),
+		})
+	}
+
+	buf, err := json.Marshal(snapshots)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
+
+// DeleteBucketSnapshotHandler - DELETE /minio/admin/v3/snapshot?bucket=&snapshot=
+// Removes a snapshot manifest, the referenced versions are untouched.
+func (a adminAPIHandlers) DeleteBucketSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+	name := vars["snapshot"]
+	if !validSnapshotName(name) {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	if err := deleteConfig(ctx, objectAPI, bucketSnapshotPath(bucket, name)); err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminNoSuchConfigTarget), r.URL)
+			return
+		}
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessNoContent(w)
+}
+
+// DiffBucketSnapshotsHandler - GET /minio/admin/v3/snapshot/diff?bucket=&from=&to=
+// Enumerates keys added, removed or overwritten between two snapshots.
+func (a adminAPIHandlers) DiffBucketSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+	fromName := vars["from"]
+	toName := vars["to"]
+	if !validSnapshotName(fromName) || !validSnapshotName(toName) {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	from, err := loadBucketSnapshot(ctx, objectAPI, bucket, fromName)
+	if err == nil {
+		var to bucketSnapshot
+		to, err = loadBucketSnapshot(ctx, objectAPI, bucket, toName)
+		if err == nil {
+			var buf []byte
+			buf, err = json.Marshal(diffBucketSnapshots(from, to))
+			if err == nil {
+				writeSuccessResponseJSON(w, buf)
+				return
+			}
+		}
+	}
+	if errors.Is(err, errConfigNotFound) {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminNoSuchConfigTarget), r.URL)
+		return
+	}
+	writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Soft delete protection for versioned buckets. When enabled on a
+// bucket, a plain DeleteObject only ever stacks a delete marker - even
+// when the client is unaware of versioning - and deleting a specific
+// version permanently requires the governance bypass permission. The
+// undelete admin API removes the newest delete marker, restoring the
+// latest real version. Together these guard against accidental mass
+// deletion while keeping the recycle-bin contents recoverable.
+
+// bucketSoftDeleteConfig is the per bucket config filename, stored
+// alongside the other bucket configs under the bucket metadata prefix.
+const bucketSoftDeleteConfig = "soft-delete.json"
+
+// softDeleteConfig records whether soft delete protection is enabled
+// on a bucket and who last changed it.
+type softDeleteConfig struct {
+	Enabled   bool      `json:"enabled"`
+	UpdatedBy string    `json:"updatedBy"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// bucketSoftDeleteConfigPath returns the config location relative to
+// minioMetaBucket.
+func bucketSoftDeleteConfigPath(bucket string) string {
+	return pathJoin(bucketMetaPrefix, bucket, bucketSoftDeleteConfig)
+}
+
+// getBucketSoftDelete loads a bucket's soft delete config, a missing
+// config reads as disabled.
+func getBucketSoftDelete(ctx context.Context, api ObjectLayer, bucket string) (cfg softDeleteConfig, err error) {
+	data, err := readConfig(ctx, api, bucketSoftDeleteConfigPath(bucket))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	err = json.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+// bucketSoftDeleteSys caches the per bucket soft delete state, the
+// delete paths consult it on every request. Buckets without a
+// configuration are cached as disabled; a config update drops the
+// cached entry on every node through the peer notification.
+type bucketSoftDeleteSys struct {
+	sync.RWMutex
+	buckets map[string]bool
+}
+
+var globalBucketSoftDeleteSys = &bucketSoftDeleteSys{
+	buckets: make(map[string]bool),
+}
+
+func (sys *bucketSoftDeleteSys) set(bucket string, enabled bool) {
+	sys.Lock()
+	defer sys.Unlock()
+	sys.buckets[bucket] = enabled
+}
+
+func (sys *bucketSoftDeleteSys) remove(bucket string) {
+	sys.Lock()
+	defer sys.Unlock()
+	delete(sys.buckets, bucket)
+}
+
+// bucketSoftDeleteEnabled reports whether soft delete protection is in
+// effect for a bucket.
+func bucketSoftDeleteEnabled(ctx context.Context, bucket string) bool {
+	sys := globalBucketSoftDeleteSys
+	sys.RLock()
+	enabled, ok := sys.buckets[bucket]
+	sys.RUnlock()
+	if ok {
+		return enabled
+	}
+
+	api := newObjectLayerFn()
+	if api == nil {
+		return false
+	}
+	cfg, err := getBucketSoftDelete(ctx, api, bucket)
+	if err != nil {
+		internalLogIf(ctx, err)
+		return false
+	}
+
+	sys.set(bucket, cfg.Enabled)
+	return cfg.Enabled
+}
+
+// SetBucketSoftDeleteHandler - PUT /minio/admin/v3/soft-delete?bucket=
+// Enables soft delete protection on a bucket.
+func (a adminAPIHandlers) SetBucketSoftDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	a.updateBucketSoftDelete(w, r, true)
+}
+
+// RemoveBucketSoftDeleteHandler - DELETE /minio/admin/v3/soft-delete?bucket=
+// Disables soft delete protection on a bucket.
+func (a adminAPIHandlers) RemoveBucketSoftDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	a.updateBucketSoftDelete(w, r, false)
+}
+
+func (a adminAPIHandlers) updateBucketSoftDelete(w http.ResponseWriter, r *http.Request, enabled bool) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := pathClean(mux.Vars(r)["bucket"])
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(softDeleteConfig{
+		Enabled:   enabled,
+		UpdatedBy: cred.AccessKey,
+		UpdatedAt: UTCNow(),
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if err := saveConfig(ctx, objectAPI, bucketSoftDeleteConfigPath(bucket), data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	globalBucketSoftDeleteSys.set(bucket, enabled)
+	globalNotificationSys.LoadBucketMetadata(bgContext(ctx), bucket)
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetBucketSoftDeleteHandler - GET /minio/admin/v3/soft-delete?bucket=
+// Returns a bucket's soft delete protection config.
+func (a adminAPIHandlers) GetBucketSoftDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := pathClean(mux.Vars(r)["bucket"])
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	cfg, err := getBucketSoftDelete(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	buf, err := json.Marshal(cfg)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
+
+// undeleteResponse is returned by UndeleteObjectHandler describing
+// what was restored.
+type undeleteResponse struct {
+	Bucket           string    `json:"bucket"`
+	Object           string    `json:"object"`
+	RemovedMarker    string    `json:"removedMarker"`
+	RestoredVersion  string    `json:"restoredVersion,omitempty"`
+	RestoredModTime  time.Time `json:"restoredModTime,omitempty"`
+	RestoredIsLatest bool      `json:"restoredIsLatest"`
+}
+
+// UndeleteObjectHandler - POST /minio/admin/v3/undelete?bucket=&object=
+// Removes the newest delete marker of an object, restoring the latest
+// real version.
+func (a adminAPIHandlers) UndeleteObjectHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ForceUnlockAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+	object, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	res, err := objectAPI.ListObjectVersions(ctx, bucket, object, "", "", "", maxObjectList)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	// The newest version of the exact object name must be a delete
+	// marker, otherwise there is nothing to undelete.
+	var marker *ObjectInfo
+	var restored *ObjectInfo
+	for i := range res.Objects {
+		oi := res.Objects[i]
+		if oi.Name != object {
+			continue
+		}
+		if marker == nil {
+			if !oi.DeleteMarker {
+				writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNoSuchVersion), r.URL)
+				return
+			}
+			marker = &res.Objects[i]
+			continue
+		}
+		restored = &res.Objects[i]
+		break
+	}
+	if marker == nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNoSuchKey), r.URL)
+		return
+	}
+
+	_, err = objectAPI.DeleteObject(ctx, bucket, object, ObjectOptions{
+		VersionID: marker.VersionID,
+		Versioned: globalBucketVersioningSys.PrefixEnabled(bucket, object),
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	resp := undeleteResponse{
+		Bucket:        bucket,
+		Object:        object,
+		RemovedMarker: marker.VersionID,
+	}
+	if restored != nil {
+		resp.RestoredVersion = restored.VersionID
+		resp.RestoredModTime = restored.ModTime
+		resp.RestoredIsLatest = true
+	}
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:     "UndeleteObject",
+		APIName:   "UndeleteObject",
+		Bucket:    bucket,
+		Object:    object,
+		VersionID: marker.VersionID,
+		Status:    "success",
+		Tags: map[string]string{
+			"requestedBy":     cred.AccessKey,
+			"restoredVersion": resp.RestoredVersion,
+		},
+	})
+
+	buf, err := json.Marshal(resp)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/bucket/bandwidth"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Bandwidth shaping for regular S3 traffic. The replication-oriented
+// bucket monitor is reused to throttle client GET and PUT streams per
+// bucket and optionally per storage class, with a configurable burst
+// allowance. Rules are stored under the config prefix and can be
+// adjusted at runtime through the admin API.
+const (
+	// trafficShapingConfigFile is where the shaping rules are stored.
+	trafficShapingConfigFile = minioConfigPrefix + "/traffic-shaping.json"
+)
+
+// Directions client traffic can be shaped in.
+const (
+	trafficShapeGet = "get"
+	trafficShapePut = "put"
+)
+
+// trafficShapeRule is one shaping rule. Limit and Burst are
+// cluster-wide bytes per second and bytes respectively.
+type trafficShapeRule struct {
+	Bucket       string `json:"bucket"`
+	Direction    string `json:"direction"`
+	StorageClass string `json:"storageClass,omitempty"`
+	Limit        int64  `json:"limit"`
+	Burst        int64  `json:"burst,omitempty"`
+}
+
+func (rule trafficShapeRule) key() string {
+	return rule.Bucket + "|" + trafficShapeARN(rule.Direction, rule.StorageClass)
+}
+
+// trafficShapeARN returns the pseudo replication ARN under which the
+// bucket monitor tracks the shaped stream.
+func trafficShapeARN(direction, storageClass string) string {
+	if storageClass != "" {
+		return "s3-" + direction + ":" + storageClass
+	}
+	return "s3-" + direction
+}
+
+var globalTrafficShaping = struct {
+	mu    sync.Mutex
+	rules map[string]trafficShapeRule
+}{rules: make(map[string]trafficShapeRule)}
+
+// initTrafficShaping loads the shaping rules and applies them to the
+// bucket monitor.
+func initTrafficShaping(ctx context.Context, objAPI ObjectLayer) {
+	data, err := readConfig(ctx, objAPI, trafficShapingConfigFile)
+	if err != nil {
+		if !errors.Is(err, errConfigNotFound) {
+			internalLogIf(ctx, fmt.Errorf("unable to read traffic shaping config: %w", err))
+		}
+		return
+	}
+
+	var rules map[string]trafficShapeRule
+	if err = json.Unmarshal(data, &rules); err != nil {
+		internalLogIf(ctx, fmt.Errorf("unable to parse traffic shaping config: %w", err))
+		return
+	}
+
+	globalTrafficShaping.mu.Lock()
+	globalTrafficShaping.rules = rules
+	globalTrafficShaping.mu.Unlock()
+
+	for _, rule := range rules {
+		globalBucketMonitor.SetBandwidthLimitBurst(rule.Bucket,
+			trafficShapeARN(rule.Direction, rule.StorageClass), rule.Limit, rule.Burst)
+	}
+}
+
+// saveTrafficShaping persists the current rule set.
+func saveTrafficShaping(ctx context.Context, objAPI ObjectLayer) error {
+	globalTrafficShaping.mu.Lock()
+	data, err := json.Marshal(globalTrafficShaping.rules)
+	globalTrafficShaping.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, trafficShapingConfigFile, data)
+}
+
+// trafficShapeOptions returns the monitor options of the shaped stream
+// for the bucket, preferring a storage class specific rule, and whether
+// shaping applies at all.
+func trafficShapeOptions(bucket, direction, storageClass string) (bandwidth.BucketOptions, bool) {
+	if storageClass != "" {
+		opts := bandwidth.BucketOptions{Name: bucket, ReplicationARN: trafficShapeARN(direction, storageClass)}
+		if globalBucketMonitor.IsThrottled(opts.Name, opts.ReplicationARN) {
+			return opts, true
+		}
+	}
+	opts := bandwidth.BucketOptions{Name: bucket, ReplicationARN: trafficShapeARN(direction, "")}
+	if globalBucketMonitor.IsThrottled(opts.Name, opts.ReplicationARN) {
+		return opts, true
+	}
+	return bandwidth.BucketOptions{}, false
+}
+
+// shapePutReader wraps the PUT payload reader with the configured
+// throttle, a no-op when the bucket is not shaped.
+func shapePutReader(ctx context.Context, bucket, storageClass string, r io.Reader) io.Reader {
+	if globalBucketMonitor == nil {
+		return r
+	}
+	opts, ok := trafficShapeOptions(bucket, trafficShapePut, storageClass)
+	if !ok {
+		return r
+	}
+	return bandwidth.NewMonitoredReader(ctx, globalBucketMonitor, r, &bandwidth.MonitorReaderOptions{
+		BucketOptions: opts,
+	})
+}
+
+// shapeGetWriter wraps the GET response writer with the configured
+// throttle, a no-op when the bucket is not shaped.
+func shapeGetWriter(ctx context.Context, bucket, storageClass string, w io.Writer) io.Writer {
+	if globalBucketMonitor == nil {
+		return w
+	}
+	opts, ok := trafficShapeOptions(bucket, trafficShapeGet, storageClass)
+	if !ok {
+		return w
+	}
+	return bandwidth.NewMonitoredWriter(ctx, globalBucketMonitor, w, opts)
+}
+
+// SetTrafficShapingHandler - creates or updates a shaping rule and
+// applies it immediately.
+//
+// PUT /minio/admin/v3/traffic-shaping
+func (a adminAPIHandlers) SetTrafficShapingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, humanize.MiByte))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	var rule trafficShapeRule
+	if err = json.Unmarshal(data, &rule); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if rule.Bucket == "" || rule.Limit <= 0 ||
+		(rule.Direction != trafficShapeGet && rule.Direction != trafficShapePut) {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+
+	globalTrafficShaping.mu.Lock()
+	globalTrafficShaping.rules[rule.key()] = rule
+	globalTrafficShaping.mu.Unlock()
+
+	globalBucketMonitor.SetBandwidthLimitBurst(rule.Bucket,
+		trafficShapeARN(rule.Direction, rule.StorageClass), rule.Limit, rule.Burst)
+
+	if err = saveTrafficShaping(ctx, objectAPI); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// ListTrafficShapingHandler - lists the shaping rules.
+//
+// GET /minio/admin/v3/traffic-shaping
+func (a adminAPIHandlers) ListTrafficShapingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	globalTrafficShaping.mu.Lock()
+	rules := make([]trafficShapeRule, 0, len(globalTrafficShaping.rules))
+	for _, rule := range globalTrafficShaping.rules {
+		rules = append(rules, rule)
+	}
+	globalTrafficShaping.mu.Unlock()
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// RemoveTrafficShapingHandler - removes a shaping rule.
+//
+// DELETE /minio/admin/v3/traffic-shaping?bucket=...&direction=...&storageClass=...
+func (a adminAPIHandlers) RemoveTrafficShapingHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	rule := trafficShapeRule{
+		Bucket:       r.Form.Get("bucket"),
+		Direction:    r.Form.Get("direction"),
+		StorageClass: r.Form.Get("storageClass"),
+	}
+
+	globalTrafficShaping.mu.Lock()
+	_, ok := globalTrafficShaping.rules[rule.key()]
+	delete(globalTrafficShaping.rules, rule.key())
+	globalTrafficShaping.mu.Unlock()
+	if !ok {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errConfigNotFound), r.URL)
+		return
+	}
+
+	globalBucketMonitor.DeleteBucketThrottle(rule.Bucket, trafficShapeARN(rule.Direction, rule.StorageClass))
+
+	if err := saveTrafficShaping(ctx, objectAPI); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseHeadersOnly(w)
+}
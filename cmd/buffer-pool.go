@@ -0,0 +1,105 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+	xioutil "github.com/minio/minio/internal/ioutil"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Central sizing for the reusable buffer pools. The erasure byte pool
+// and the aligned O_DIRECT pools together can pin a significant amount
+// of memory, this derives one aggregate budget for them instead of
+// each pool sizing itself off total memory independently.
+const (
+	// EnvBufferMemory limits the aggregate memory reserved for
+	// reusable buffers, accepts values like "4GiB".
+	EnvBufferMemory = "MINIO_BUFFER_MEMORY"
+)
+
+var (
+	bufferMemoryOnce   sync.Once
+	bufferMemoryBudget uint64
+)
+
+// bufferMemoryLimit returns the aggregate buffer budget. The explicit
+// env knob wins, otherwise a quarter of GOMEMLIMIT when one is set,
+// otherwise half of the available memory as before.
+func bufferMemoryLimit() uint64 {
+	bufferMemoryOnce.Do(func() {
+		if v := env.Get(EnvBufferMemory, ""); v != "" {
+			if sz, err := humanize.ParseBytes(v); err == nil && sz > 0 {
+				bufferMemoryBudget = sz
+				return
+			}
+			internalLogIf(GlobalContext, fmt.Errorf("invalid %s value: %s, ignoring", EnvBufferMemory, v))
+		}
+		if limit := debug.SetMemoryLimit(-1); limit > 0 && limit < math.MaxInt64 {
+			bufferMemoryBudget = uint64(limit) / 4
+			return
+		}
+		bufferMemoryBudget = availableMemory() / 2
+	})
+	return bufferMemoryBudget
+}
+
+// Buffer pool metric names.
+const (
+	bufferPoolGetsTotal   MetricName = "gets_total"
+	bufferPoolAllocsTotal MetricName = "allocs_total"
+	bufferPoolMemoryBytes MetricName = "memory_bytes"
+	bufferPoolBudgetBytes MetricName = "budget_bytes"
+)
+
+var (
+	bufferPoolGetsTotalMD = NewCounterMD(bufferPoolGetsTotal,
+		"Total buffer requests served by the pool", "pool")
+	bufferPoolAllocsTotalMD = NewCounterMD(bufferPoolAllocsTotal,
+		"Total buffer requests that allocated a new buffer", "pool")
+	bufferPoolMemoryBytesMD = NewGaugeMD(bufferPoolMemoryBytes,
+		"Memory currently held by the erasure buffer pool")
+	bufferPoolBudgetBytesMD = NewGaugeMD(bufferPoolBudgetBytes,
+		"Aggregate memory budget for reusable buffers")
+)
+
+// loadBufferPoolMetrics - `MetricsLoaderFn` for buffer pool metrics.
+func loadBufferPoolMetrics(_ context.Context, m MetricValues, _ *metricsCache) error {
+	pools := map[string]interface {
+		Stats() (gets, allocs uint64)
+	}{
+		"erasure":        globalBytePoolCap.Load(),
+		"odirect_small":  xioutil.ODirectPoolSmall,
+		"odirect_medium": xioutil.ODirectPoolMedium,
+		"odirect_large":  xioutil.ODirectPoolLarge,
+	}
+	for name, pool := range pools {
+		gets, allocs := pool.Stats()
+		m.Set(bufferPoolGetsTotal, float64(gets), "pool", name)
+		m.Set(bufferPoolAllocsTotal, float64(allocs), "pool", name)
+	}
+	m.Set(bufferPoolMemoryBytes, float64(globalBytePoolCap.Load().CurrentSize()))
+	m.Set(bufferPoolBudgetBytes, float64(bufferMemoryLimit()))
+	return nil
+}
@@ -0,0 +1,405 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Per bucket zstd dictionaries for small-object compression. Buckets
+// holding many small, similar objects (JSON, logs) compress poorly
+// object by object because each object is too short to build up a
+// useful match history. A dictionary sampled from the bucket's own
+// content restores those cross-object matches. Dictionaries are
+// trained in the background from sampled small objects, stored
+// immutably by content ID so previously written objects always find
+// the dictionary they were compressed with, and applied to uploads
+// small enough to benefit. Larger uploads and encrypted uploads keep
+// the streaming s2 path.
+const (
+	// zstdDictPrefix is where bucket dictionaries live, relative to
+	// minioMetaBucket.
+	zstdDictPrefix = ".zstd-dicts"
+
+	// zstdDictCurrentFile points at the dictionary applied to new
+	// uploads.
+	zstdDictCurrentFile = "current.json"
+
+	// compDictMaxObjectSize bounds uploads eligible for dictionary
+	// compression, larger objects gain little from a dictionary.
+	compDictMaxObjectSize = 1 << 20
+
+	// zstdDictMinSamples is the least number of sampled objects worth
+	// training from.
+	zstdDictMinSamples = 64
+
+	// zstdDictMaxSamples bounds how many objects one training pass
+	// reads.
+	zstdDictMaxSamples = 1000
+
+	// zstdDictSampleMax is the most bytes read from a single sample.
+	zstdDictSampleMax = 64 << 10
+
+	// zstdDictMaxSize is the standard 110 KiB zstd dictionary budget.
+	zstdDictMaxSize = 110 << 10
+
+	// zstdDictRetrainInterval is how long a trained dictionary is
+	// considered current before the trainer rebuilds it.
+	zstdDictRetrainInterval = 7 * 24 * time.Hour
+
+	// zstdDictTrainInterval is how often the background trainer scans
+	// for buckets needing a dictionary.
+	zstdDictTrainInterval = 24 * time.Hour
+)
+
+// zstdDictInfo is the stored pointer to a bucket's current dictionary.
+type zstdDictInfo struct {
+	ID        string    `json:"id"`
+	Samples   int       `json:"samples"`
+	Size      int       `json:"size"`
+	TrainedAt time.Time `json:"trainedAt"`
+}
+
+// bucketZstdDict is a loaded dictionary with its ready to use zstd
+// encoder and decoder.
+type bucketZstdDict struct {
+	ID   string
+	data []byte
+	enc  *zstd.Encoder
+	dec  *zstd.Decoder
+}
+
+// zstdDictCacheEntry caches one bucket's current dictionary, a nil
+// dict caches the absence of one.
+type zstdDictCacheEntry struct {
+	dict     *bucketZstdDict
+	loadedAt time.Time
+}
+
+var (
+	// zstdDictCache caches current dictionaries by bucket and loaded
+	// dictionaries by bucket/id.
+	zstdDictCache   = make(map[string]zstdDictCacheEntry)
+	zstdDictCacheMu sync.Mutex
+)
+
+// zstdDictID derives the immutable content ID of a dictionary.
+func zstdDictID(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// zstdDictRawID derives the numeric raw dictionary ID zstd embeds in
+// frame headers from the content ID.
+func zstdDictRawID(id string) uint32 {
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) < 4 {
+		return 1
+	}
+	v := binary.BigEndian.Uint32(b[:4])
+	if v == 0 {
+		v = 1
+	}
+	return v
+}
+
+// zstdDictPath returns a dictionary's location relative to
+// minioMetaBucket.
+func zstdDictPath(bucket, id string) string {
+	return pathJoin(zstdDictPrefix, bucket, id+".bin")
+}
+
+// newBucketZstdDict wires up encoder and decoder for a dictionary.
+func newBucketZstdDict(id string, data []byte) (*bucketZstdDict, error) {
+	rawID := zstdDictRawID(id)
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderDictRaw(rawID, data),
+		zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return nil, err
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderDictRaw(rawID, data))
+	if err != nil {
+		return nil, err
+	}
+	return &bucketZstdDict{ID: id, data: data, enc: enc, dec: dec}, nil
+}
+
+// loadZstdDictByID reads and caches a dictionary by content ID, as
+// recorded in object metadata.
+func loadZstdDictByID(ctx context.Context, bucket, id string) (*bucketZstdDict, error) {
+	key := pathJoin(bucket, id)
+	zstdDictCacheMu.Lock()
+	cached, ok := zstdDictCache[key]
+	zstdDictCacheMu.Unlock()
+	if ok && cached.dict != nil {
+		return cached.dict, nil
+	}
+
+	api := newObjectLayerFn()
+	if api == nil {
+		return nil, errServerNotInitialized
+	}
+	data, err := readConfig(ctx, api, zstdDictPath(bucket, id))
+	if err != nil {
+		return nil, err
+	}
+	d, err := newBucketZstdDict(id, data)
+	if err != nil {
+		return nil, err
+	}
+	zstdDictCacheMu.Lock()
+	zstdDictCache[key] = zstdDictCacheEntry{dict: d, loadedAt: UTCNow()}
+	zstdDictCacheMu.Unlock()
+	return d, nil
+}
+
+// lookupZstdDict returns a bucket's current dictionary, nil when the
+// bucket has none.
+func lookupZstdDict(ctx context.Context, bucket string) *bucketZstdDict {
+	zstdDictCacheMu.Lock()
+	cached, ok := zstdDictCache[bucket]
+	zstdDictCacheMu.Unlock()
+	if ok && time.Since(cached.loadedAt) < time.Minute {
+		return cached.dict
+	}
+
+	api := newObjectLayerFn()
+	if api == nil {
+		return nil
+	}
+	var dict *bucketZstdDict
+	data, err := readConfig(ctx, api, pathJoin(zstdDictPrefix, bucket, zstdDictCurrentFile))
+	if err == nil {
+		var info zstdDictInfo
+		if err = json.Unmarshal(data, &info); err == nil {
+			dict, err = loadZstdDictByID(ctx, bucket, info.ID)
+		}
+	}
+	if err != nil && !errors.Is(err, errConfigNotFound) {
+		internalLogIf(ctx, err)
+	}
+	zstdDictCacheMu.Lock()
+	zstdDictCache[bucket] = zstdDictCacheEntry{dict: dict, loadedAt: UTCNow()}
+	zstdDictCacheMu.Unlock()
+	return dict
+}
+
+// newZstdDictCompressReader reads all of r - bounded by
+// compDictMaxObjectSize - and returns the dictionary compressed bytes
+// as a reader.
+func newZstdDictCompressReader(r io.Reader, on int64, d *bucketZstdDict) (io.Reader, error) {
+	src, err := io.ReadAll(io.LimitReader(r, compDictMaxObjectSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if on >= 0 && int64(len(src)) != on {
+		return nil, IncompleteBody{}
+	}
+	return bytes.NewReader(d.enc.EncodeAll(src, nil)), nil
+}
+
+// zstdDictDecompress reads a dictionary compressed object and returns
+// its decompressed content.
+func zstdDictDecompress(ctx context.Context, oi ObjectInfo, r io.Reader) ([]byte, error) {
+	id := oi.UserDefined[ReservedMetadataPrefix+"compression-dict"]
+	if id == "" {
+		return nil, fmt.Errorf("object %s/%s has no compression dictionary ID", oi.Bucket, oi.Name)
+	}
+	d, err := loadZstdDictByID(ctx, oi.Bucket, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load compression dictionary %s for %s/%s: %w", id, oi.Bucket, oi.Name, err)
+	}
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.dec.DecodeAll(src, nil)
+}
+
+// trainBucketZstdDict samples small objects from a bucket and stores a
+// new dictionary built from them. Raw content dictionaries carry no
+// entropy tables but give the encoder a shared match history, which is
+// where the small-object gains come from.
+func trainBucketZstdDict(ctx context.Context, api ObjectLayer, bucket string) (zstdDictInfo, error) {
+	var info zstdDictInfo
+
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	if err := api.Walk(ctx, bucket, "", results, WalkOptions{
+		LatestOnly: true,
+		Limit:      zstdDictMaxSamples,
+		Filter: func(fi FileInfo) bool {
+			return !fi.Deleted && fi.Size > 0 && fi.Size <= compDictMaxObjectSize
+		},
+	}); err != nil {
+		return info, err
+	}
+
+	var dictBuf bytes.Buffer
+	samples := 0
+	for res := range results {
+		if res.Err != nil {
+			return info, res.Err
+		}
+		if dictBuf.Len() >= zstdDictMaxSize {
+			break
+		}
+		oi := res.Item
+		if oi.DeleteMarker {
+			continue
+		}
+		gr, err := api.GetObjectNInfo(ctx, bucket, oi.Name, nil, http.Header{}, ObjectOptions{
+			VersionID: oi.VersionID,
+			NoLock:    true,
+		})
+		if err != nil {
+			continue
+		}
+		n, err := io.Copy(&dictBuf, io.LimitReader(gr, zstdDictSampleMax))
+		gr.Close()
+		if err != nil || n == 0 {
+			continue
+		}
+		samples++
+	}
+	if samples < zstdDictMinSamples {
+		return info, fmt.Errorf("bucket %s has only %d sampleable small objects, need %d", bucket, samples, zstdDictMinSamples)
+	}
+
+	data := dictBuf.Bytes()
+	if len(data) > zstdDictMaxSize {
+		// Keep the most recent content, zstd favors matches near the
+		// end of the dictionary.
+		data = data[len(data)-zstdDictMaxSize:]
+	}
+	info = zstdDictInfo{
+		ID:        zstdDictID(data),
+		Samples:   samples,
+		Size:      len(data),
+		TrainedAt: UTCNow(),
+	}
+
+	if err := saveConfig(ctx, api, zstdDictPath(bucket, info.ID), data); err != nil {
+		return info, err
+	}
+	ptr, err := json.Marshal(info)
+	if err != nil {
+		return info, err
+	}
+	if err := saveConfig(ctx, api, pathJoin(zstdDictPrefix, bucket, zstdDictCurrentFile), ptr); err != nil {
+		return info, err
+	}
+
+	// Invalidate the cached current dictionary.
+	zstdDictCacheMu.Lock()
+	delete(zstdDictCache, bucket)
+	zstdDictCacheMu.Unlock()
+	return info, nil
+}
+
+// initZstdDictTrainer starts the background dictionary trainer. It
+// periodically revisits every bucket and (re)trains dictionaries that
+// are missing or stale.
+func initZstdDictTrainer(ctx context.Context, api ObjectLayer) {
+	go func() {
+		tick := time.NewTicker(zstdDictTrainInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				trainAllBucketZstdDicts(ctx, api)
+			}
+		}
+	}()
+}
+
+// trainAllBucketZstdDicts trains dictionaries for buckets missing a
+// current one or holding a stale one.
+func trainAllBucketZstdDicts(ctx context.Context, api ObjectLayer) {
+	buckets, err := api.ListBuckets(ctx, BucketOptions{})
+	if err != nil {
+		internalLogIf(ctx, err)
+		return
+	}
+	for _, bucket := range buckets {
+		if isMinioMetaBucketName(bucket.Name) {
+			continue
+		}
+		data, err := readConfig(ctx, api, pathJoin(zstdDictPrefix, bucket.Name, zstdDictCurrentFile))
+		if err == nil {
+			var info zstdDictInfo
+			if json.Unmarshal(data, &info) == nil && time.Since(info.TrainedAt) < zstdDictRetrainInterval {
+				continue
+			}
+		} else if !errors.Is(err, errConfigNotFound) {
+			internalLogIf(ctx, err)
+			continue
+		}
+		if _, err := trainBucketZstdDict(ctx, api, bucket.Name); err != nil {
+			// Buckets without enough small objects are expected, keep
+			// quiet about them.
+			continue
+		}
+	}
+}
+
+// TrainCompressionDictHandler - POST /minio/admin/v3/compression/train-dict?bucket=
+// Trains a bucket's zstd dictionary on demand.
+func (a adminAPIHandlers) TrainCompressionDictHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := pathClean(mux.Vars(r)["bucket"])
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	info, err := trainBucketZstdDict(ctx, objectAPI, bucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	buf, err := json.Marshal(info)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
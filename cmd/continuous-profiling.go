@@ -0,0 +1,244 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/policy"
+)
+
+const (
+	// EnvContinuousProfiling enables the always-on continuous profiler
+	// keeping low-overhead CPU and heap profiles in an on-disk ring
+	// buffer, so the minutes leading up to an incident can be
+	// retrieved after the fact.
+	EnvContinuousProfiling = "MINIO_CONTINUOUS_PROFILING"
+
+	// EnvContinuousProfilingDir overrides where profile chunks are
+	// kept, defaults to a directory under the OS temporary directory.
+	EnvContinuousProfilingDir = "MINIO_CONTINUOUS_PROFILING_DIR"
+
+	// EnvContinuousProfilingRetain is how much profile history is
+	// retained in the ring buffer, defaults to 30m.
+	EnvContinuousProfilingRetain = "MINIO_CONTINUOUS_PROFILING_RETAIN"
+
+	// continuousProfileInterval is the length of one CPU profile
+	// chunk, heap snapshots are taken at the same cadence.
+	continuousProfileInterval = time.Minute
+
+	// continuousProfileCPUHz is the reduced CPU sampling rate used to
+	// keep the always-on profiler overhead low (default pprof is 100).
+	continuousProfileCPUHz = 10
+
+	defaultContinuousProfilingRetain = 30 * time.Minute
+)
+
+var (
+	continuousProfilingOnce    sync.Once
+	continuousProfilingEnabled bool
+	continuousProfilingDir     string
+	continuousProfilingRetain  time.Duration
+)
+
+func continuousProfilingConfig() (enabled bool, dir string, retain time.Duration) {
+	continuousProfilingOnce.Do(func() {
+		continuousProfilingEnabled = env.Get(EnvContinuousProfiling, config.EnableOff) == config.EnableOn
+		continuousProfilingDir = env.Get(EnvContinuousProfilingDir,
+			filepath.Join(os.TempDir(), "minio-continuous-profiling"))
+		continuousProfilingRetain = defaultContinuousProfilingRetain
+		if v := env.Get(EnvContinuousProfilingRetain, ""); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d > 0 {
+				continuousProfilingRetain = d
+			}
+		}
+	})
+	return continuousProfilingEnabled, continuousProfilingDir, continuousProfilingRetain
+}
+
+// initContinuousProfiler starts the continuous profiler when enabled.
+func initContinuousProfiler(ctx context.Context) {
+	enabled, dir, retain := continuousProfilingConfig()
+	if !enabled {
+		return
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		internalLogIf(ctx, fmt.Errorf("unable to create continuous profiling directory %s: %w", dir, err))
+		return
+	}
+	go runContinuousProfiler(ctx, dir, retain)
+}
+
+// runContinuousProfiler records back to back CPU profile chunks at a
+// reduced sampling rate plus periodic heap snapshots, pruning chunks
+// that have aged out of the retention window.
+func runContinuousProfiler(ctx context.Context, dir string, retain time.Duration) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := time.Now().UTC()
+		stamp := strconv.FormatInt(now.Unix(), 10)
+
+		cpuFile, err := os.Create(filepath.Join(dir, "cpu-"+stamp+".pprof"))
+		if err != nil {
+			internalLogIf(ctx, err)
+			return
+		}
+
+		// Reduce the sampling rate before the profile starts, pprof
+		// latches the rate when profiling begins.
+		runtime.SetCPUProfileRate(continuousProfileCPUHz)
+		profiling := pprof.StartCPUProfile(cpuFile) == nil
+
+		select {
+		case <-ctx.Done():
+			if profiling {
+				pprof.StopCPUProfile()
+			}
+			cpuFile.Close()
+			return
+		case <-time.After(continuousProfileInterval):
+		}
+
+		if profiling {
+			pprof.StopCPUProfile()
+		}
+		cpuFile.Close()
+
+		heapFile, err := os.Create(filepath.Join(dir, "heap-"+stamp+".pprof"))
+		if err == nil {
+			internalLogIf(ctx, pprof.Lookup("heap").WriteTo(heapFile, 0))
+			heapFile.Close()
+		}
+
+		pruneContinuousProfiles(dir, retain)
+	}
+}
+
+// continuousProfileTime extracts the unix timestamp out of a profile
+// chunk file name such as cpu-1700000000.pprof.
+func continuousProfileTime(name string) (time.Time, bool) {
+	base := strings.TrimSuffix(name, ".pprof")
+	idx := strings.IndexByte(base, '-')
+	if idx < 0 {
+		return time.Time{}, false
+	}
+	ts, err := strconv.ParseInt(base[idx+1:], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(ts, 0).UTC(), true
+}
+
+// pruneContinuousProfiles deletes profile chunks older than the
+// retention window.
+func pruneContinuousProfiles(dir string, retain time.Duration) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().UTC().Add(-retain)
+	for _, entry := range entries {
+		ts, ok := continuousProfileTime(entry.Name())
+		if ok && ts.Before(cutoff) {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
+
+// DownloadContinuousProfilesHandler - returns the continuously recorded
+// CPU and heap profile chunks of the last N minutes as a zip archive.
+//
+// GET /minio/admin/v3/continuous-profiling/download?duration=10m
+func (a adminAPIHandlers) DownloadContinuousProfilesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	_, adminAPIErr := checkAdminRequestAuth(ctx, r, policy.ProfilingAdminAction, "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(adminAPIErr), r.URL)
+		return
+	}
+
+	enabled, dir, retain := continuousProfilingConfig()
+	if !enabled {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errors.New("continuous profiling is not enabled")), r.URL)
+		return
+	}
+
+	duration := retain
+	if v := r.Form.Get("duration"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+			return
+		}
+		duration = d
+	}
+	cutoff := time.Now().UTC().Add(-duration)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if ts, ok := continuousProfileTime(entry.Name()); ok && !ts.Before(cutoff) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	w.Header().Set(xhttp.ContentDisposition, "attachment; filename=profiles.zip")
+	w.Header().Set(xhttp.ContentType, "application/zip")
+
+	zw := zip.NewWriter(w)
+	defer zw.Close()
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		f, err := zw.Create(pathJoin(globalLocalNodeName, name))
+		if err != nil {
+			return
+		}
+		if _, err = f.Write(data); err != nil {
+			return
+		}
+	}
+}
@@ -230,6 +230,9 @@ func runDataScanner(ctx context.Context, objAPI ObjectLayer) {
 				if err != nil {
 					scannerLogIf(ctx, fmt.Errorf("%w, Object %s", err, dataUsageBloomNamePath))
 				}
+
+				// Export a per-prefix usage snapshot if configured.
+				maybeExportUsageSnapshot(ctx, objAPI)
 			}
 		}
 	}
@@ -1185,6 +1188,9 @@ func (i *scannerItem) applyVersionActions(ctx context.Context, o ObjectLayer, fi
 // The metadata will be compared to consensus on the object layer before any changes are applied.
 // If no metadata is supplied, -1 is returned if no action is taken.
 func (i *scannerItem) applyActions(ctx context.Context, o ObjectLayer, oi ObjectInfo, sizeS *sizeSummary) (objDeleted bool, size int64) {
+	recordInlineStats(oi)
+	globalListingIndex.reconcile(oi)
+
 	done := globalScannerMetrics.time(scannerMetricILM)
 	var action lifecycle.Action
 	action, size = i.applyLifecycle(ctx, o, oi)
@@ -1224,7 +1230,7 @@ func (i *scannerItem) applyActions(ctx context.Context, o ObjectLayer, oi Object
 }
 
 func evalActionFromLifecycle(ctx context.Context, lc lifecycle.Lifecycle, lr lock.Retention, rcfg *replication.Config, obj ObjectInfo) lifecycle.Event {
-	event := lc.Eval(obj.ToLifecycleOpts())
+	event := lc.Eval(lifecycleOptsWithAccessTime(lc, obj))
 	if serverDebugLog {
 		console.Debugf(applyActionsLogPrefix+" lifecycle: Secondary scan: %v\n", event.Action)
 	}
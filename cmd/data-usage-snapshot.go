@@ -0,0 +1,172 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/pkg/v3/env"
+)
+
+const (
+	// EnvUsageSnapshotBucket designates a bucket the scanner
+	// periodically writes per-prefix usage snapshots into as CSV, so
+	// capacity reporting can be done with SQL engines (including S3
+	// Select) instead of the usage API. Empty disables snapshots.
+	EnvUsageSnapshotBucket = "MINIO_SCANNER_USAGE_SNAPSHOT_BUCKET"
+
+	// EnvUsageSnapshotInterval configures how often a new snapshot is
+	// written, defaults to once every 24h.
+	EnvUsageSnapshotInterval = "MINIO_SCANNER_USAGE_SNAPSHOT_INTERVAL"
+
+	usageSnapshotPrefix          = "usage-snapshots"
+	defaultUsageSnapshotInterval = 24 * time.Hour
+)
+
+var (
+	usageSnapshotOnce     sync.Once
+	usageSnapshotBucket   string
+	usageSnapshotInterval time.Duration
+	usageSnapshotLast     time.Time
+)
+
+func usageSnapshotConfig() (bucket string, interval time.Duration) {
+	usageSnapshotOnce.Do(func() {
+		usageSnapshotBucket = env.Get(EnvUsageSnapshotBucket, "")
+		usageSnapshotInterval = defaultUsageSnapshotInterval
+		if is := env.Get(EnvUsageSnapshotInterval, ""); is != "" {
+			if d, err := time.ParseDuration(is); err == nil && d > 0 {
+				usageSnapshotInterval = d
+			}
+		}
+	})
+	return usageSnapshotBucket, usageSnapshotInterval
+}
+
+// prefixUsageSnapshotEntry is the aggregated usage of one prefix across
+// all erasure sets.
+type prefixUsageSnapshotEntry struct {
+	objects       uint64
+	versions      uint64
+	deleteMarkers uint64
+	size          int64
+}
+
+// maybeExportUsageSnapshot writes a usage snapshot after a scanner
+// cycle when one is configured and the last snapshot is older than the
+// configured interval. Only the cluster leader runs the scanner, so no
+// cross-node coordination is needed.
+func maybeExportUsageSnapshot(ctx context.Context, objAPI ObjectLayer) {
+	bucket, interval := usageSnapshotConfig()
+	if bucket == "" {
+		return
+	}
+	if time.Since(usageSnapshotLast) < interval {
+		return
+	}
+	if err := exportUsageSnapshot(ctx, objAPI, bucket); err != nil {
+		scannerLogIf(ctx, fmt.Errorf("unable to export usage snapshot: %w", err))
+		return
+	}
+	usageSnapshotLast = time.Now().UTC()
+}
+
+// exportUsageSnapshot aggregates per-prefix usage from the scanner
+// caches of all erasure sets and writes it as one CSV object named
+// after the snapshot time.
+func exportUsageSnapshot(ctx context.Context, objAPI ObjectLayer, snapshotBucket string) error {
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return nil
+	}
+
+	buckets, err := z.ListBuckets(ctx, BucketOptions{})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err = w.Write([]string{"bucket", "prefix", "objects", "versions", "delete_markers", "bytes"}); err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		if bucket.Name == snapshotBucket || isMinioMetaBucketName(bucket.Name) {
+			continue
+		}
+		usage := make(map[string]prefixUsageSnapshotEntry)
+		cache := dataUsageCache{}
+		for _, pool := range z.serverPools {
+			for _, er := range pool.sets {
+				loadCtx, done := context.WithTimeout(ctx, 2*time.Second)
+				loadErr := cache.load(loadCtx, er, bucket.Name+slashSeparator+dataUsageCacheName)
+				done()
+				if loadErr != nil {
+					continue
+				}
+				root := cache.find(bucket.Name)
+				if root == nil {
+					continue
+				}
+				for id, e := range cache.flattenChildrens(*root) {
+					prefix := decodeDirObject(strings.TrimPrefix(id, bucket.Name+slashSeparator))
+					u := usage[prefix]
+					u.objects += e.Objects
+					u.versions += e.Versions
+					u.deleteMarkers += e.DeleteMarkers
+					u.size += e.Size
+					usage[prefix] = u
+				}
+			}
+		}
+		for prefix, u := range usage {
+			if err = w.Write([]string{
+				bucket.Name,
+				prefix,
+				strconv.FormatUint(u.objects, 10),
+				strconv.FormatUint(u.versions, 10),
+				strconv.FormatUint(u.deleteMarkers, 10),
+				strconv.FormatInt(u.size, 10),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return err
+	}
+
+	object := pathJoin(usageSnapshotPrefix, time.Now().UTC().Format("2006-01-02T15-04-05Z")+".csv")
+	data := buf.Bytes()
+	hashReader, err := hash.NewReader(ctx, bytes.NewReader(data), int64(len(data)), "", getSHA256Hash(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	_, err = z.PutObject(ctx, snapshotBucket, object, NewPutObjReader(hashReader), ObjectOptions{})
+	return err
+}
@@ -0,0 +1,415 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/dperf/pkg/dperf"
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/event"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Drive performance baselines. A drive that silently degrades - media
+// retries, dying cache, throttling - keeps answering I/O and never
+// trips the health monitor, it just gets slower. With baselines
+// enabled each local drive is benchmarked once (sequential read/write
+// throughput and fsync latency), the result is persisted next to
+// format.json on the drive itself, and a background monitor probes
+// fsync latency periodically. When the live latency deviates from the
+// baseline beyond the configured percentage a DrivePerfDegraded system
+// event is raised so the operator hears about the drive before it
+// fails outright.
+
+const (
+	// EnvDrivePerfBaseline enables drive performance baselines and the
+	// deviation monitor.
+	EnvDrivePerfBaseline = "MINIO_DRIVE_PERF_BASELINE"
+
+	// EnvDrivePerfDeviationPerc is the percentage the live fsync
+	// latency may exceed the baseline before the drive is flagged.
+	EnvDrivePerfDeviationPerc = "MINIO_DRIVE_PERF_DEVIATION_PERC"
+
+	// EnvDrivePerfCheckInterval is how often the monitor probes each
+	// drive.
+	EnvDrivePerfCheckInterval = "MINIO_DRIVE_PERF_CHECK_INTERVAL"
+
+	// drivePerfBaselineFile is stored under <drive>/.minio.sys, next
+	// to format.json, so the baseline travels with the drive.
+	drivePerfBaselineFile = "perf-baseline.json"
+
+	defaultDrivePerfDeviationPerc = 50
+	defaultDrivePerfCheckInterval = 5 * time.Minute
+
+	// Benchmark parameters, deliberately smaller than the on-demand
+	// drive speedtest so startup stays cheap.
+	drivePerfBenchBlockSize = 4 * humanize.MiByte
+	drivePerfBenchFileSize  = 128 * humanize.MiByte
+
+	// Fsync probe parameters.
+	drivePerfProbeSize  = 4 * humanize.KiByte
+	drivePerfProbeCount = 8
+)
+
+var (
+	drivePerfOnce          sync.Once
+	drivePerfEnabled       bool
+	drivePerfDeviationPerc int
+	drivePerfCheckInterval time.Duration
+)
+
+func drivePerfConfig() (enabled bool, deviationPerc int, interval time.Duration) {
+	drivePerfOnce.Do(func() {
+		drivePerfEnabled = env.Get(EnvDrivePerfBaseline, config.EnableOff) == config.EnableOn
+		drivePerfDeviationPerc = defaultDrivePerfDeviationPerc
+		if v := env.Get(EnvDrivePerfDeviationPerc, ""); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				drivePerfDeviationPerc = n
+			}
+		}
+		drivePerfCheckInterval = defaultDrivePerfCheckInterval
+		if v := env.Get(EnvDrivePerfCheckInterval, ""); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d > 0 {
+				drivePerfCheckInterval = d
+			}
+		}
+	})
+	return drivePerfEnabled, drivePerfDeviationPerc, drivePerfCheckInterval
+}
+
+// drivePerfBaseline is the persisted benchmark result of one drive.
+type drivePerfBaseline struct {
+	Version         int           `json:"version"`
+	Path            string        `json:"path"`
+	ReadThroughput  uint64        `json:"readThroughput"`
+	WriteThroughput uint64        `json:"writeThroughput"`
+	FsyncLatency    time.Duration `json:"fsyncLatency"`
+	MeasuredAt      time.Time     `json:"measuredAt"`
+}
+
+// drivePerfMonitor holds the loaded baselines and the latest probe
+// outcome per local drive.
+type drivePerfMonitor struct {
+	mu        sync.Mutex
+	baselines map[string]drivePerfBaseline
+	lastProbe map[string]time.Duration
+	degraded  map[string]bool
+}
+
+var globalDrivePerfMonitor = &drivePerfMonitor{
+	baselines: make(map[string]drivePerfBaseline),
+	lastProbe: make(map[string]time.Duration),
+	degraded:  make(map[string]bool),
+}
+
+// measureFsyncLatency times small write+fsync cycles on the drive and
+// returns the median, which is robust against a single slow probe.
+func measureFsyncLatency(lp string) (time.Duration, error) {
+	fn := pathJoin(lp, minioMetaTmpBucket, mustGetUUID())
+	f, err := OpenFile(fn, os.O_CREATE|os.O_WRONLY, 0o666)
+	if err != nil {
+		return 0, err
+	}
+	defer Remove(fn)
+	defer f.Close()
+
+	buf := make([]byte, drivePerfProbeSize)
+	latencies := make([]time.Duration, 0, drivePerfProbeCount)
+	for i := 0; i < drivePerfProbeCount; i++ {
+		if _, err = f.Write(buf); err != nil {
+			return 0, err
+		}
+		t := time.Now()
+		if err = f.Sync(); err != nil {
+			return 0, err
+		}
+		latencies = append(latencies, time.Since(t))
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencies[len(latencies)/2], nil
+}
+
+// benchmarkDrive measures throughput and fsync latency of one local
+// formatted drive.
+func benchmarkDrive(ctx context.Context, lp string) (drivePerfBaseline, error) {
+	perf := &dperf.DrivePerf{
+		BlockSize: drivePerfBenchBlockSize,
+		FileSize:  drivePerfBenchFileSize,
+	}
+	perfs, err := perf.Run(ctx, pathJoin(lp, minioMetaTmpBucket))
+	if err != nil {
+		return drivePerfBaseline{}, err
+	}
+	if len(perfs) == 0 {
+		return drivePerfBaseline{}, errDiskNotFound
+	}
+	if perfs[0].Error != nil {
+		return drivePerfBaseline{}, perfs[0].Error
+	}
+
+	fsyncLatency, err := measureFsyncLatency(lp)
+	if err != nil {
+		return drivePerfBaseline{}, err
+	}
+
+	return drivePerfBaseline{
+		Version:         1,
+		Path:            lp,
+		ReadThroughput:  perfs[0].ReadThroughput,
+		WriteThroughput: perfs[0].WriteThroughput,
+		FsyncLatency:    fsyncLatency,
+		MeasuredAt:      UTCNow(),
+	}, nil
+}
+
+func drivePerfBaselinePath(lp string) string {
+	return pathJoin(lp, minioMetaBucket, drivePerfBaselineFile)
+}
+
+func loadDrivePerfBaseline(lp string) (drivePerfBaseline, error) {
+	var baseline drivePerfBaseline
+	data, err := os.ReadFile(drivePerfBaselinePath(lp))
+	if err != nil {
+		return baseline, err
+	}
+	err = json.Unmarshal(data, &baseline)
+	return baseline, err
+}
+
+func saveDrivePerfBaseline(baseline drivePerfBaseline) error {
+	data, err := json.Marshal(baseline)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(drivePerfBaselinePath(baseline.Path), data, 0o666)
+}
+
+// localFormattedDrivePaths returns the paths of local drives that
+// carry a format.json, unformatted drives are skipped like the drive
+// speedtest does.
+func localFormattedDrivePaths() (paths []string) {
+	for _, lp := range globalEndpoints.LocalDisksPaths() {
+		if _, err := Lstat(pathJoin(lp, minioMetaBucket, formatConfigFile)); err == nil {
+			paths = append(paths, lp)
+		}
+	}
+	return paths
+}
+
+// ensureBaseline loads the persisted baseline of a drive, benchmarking
+// and persisting one when the drive has none yet.
+func (m *drivePerfMonitor) ensureBaseline(ctx context.Context, lp string) error {
+	baseline, err := loadDrivePerfBaseline(lp)
+	if err != nil {
+		if baseline, err = benchmarkDrive(ctx, lp); err != nil {
+			return err
+		}
+		if err = saveDrivePerfBaseline(baseline); err != nil {
+			return err
+		}
+		logger.Event(ctx, "perf",
+			"node(%s): recorded performance baseline for drive %s: read %s/s write %s/s fsync %v",
+			globalLocalNodeName, lp, humanize.IBytes(baseline.ReadThroughput),
+			humanize.IBytes(baseline.WriteThroughput), baseline.FsyncLatency)
+	}
+
+	m.mu.Lock()
+	m.baselines[lp] = baseline
+	m.mu.Unlock()
+	return nil
+}
+
+// probe compares the live fsync latency of one drive against its
+// baseline and flags or clears the drive accordingly.
+func (m *drivePerfMonitor) probe(ctx context.Context, lp string, deviationPerc int) {
+	m.mu.Lock()
+	baseline, ok := m.baselines[lp]
+	m.mu.Unlock()
+	if !ok || baseline.FsyncLatency <= 0 {
+		return
+	}
+
+	live, err := measureFsyncLatency(lp)
+	if err != nil {
+		// The health monitor owns hard I/O failures, the perf monitor
+		// only tracks latency of working drives.
+		return
+	}
+
+	threshold := baseline.FsyncLatency + baseline.FsyncLatency*time.Duration(deviationPerc)/100
+
+	m.mu.Lock()
+	m.lastProbe[lp] = live
+	wasDegraded := m.degraded[lp]
+	m.degraded[lp] = live > threshold
+	m.mu.Unlock()
+
+	if live > threshold && !wasDegraded {
+		storageLogAlwaysIf(ctx, fmt.Errorf("node(%s): drive %s fsync latency %v exceeds baseline %v by more than %d%%",
+			globalLocalNodeName, lp, live, baseline.FsyncLatency, deviationPerc))
+		sendSystemEvent(event.DrivePerfDegraded, map[string]string{
+			"node":     globalLocalNodeName,
+			"drive":    lp,
+			"baseline": baseline.FsyncLatency.String(),
+			"live":     live.String(),
+		})
+	}
+	if live <= threshold && wasDegraded {
+		logger.Event(ctx, "perf",
+			"node(%s): drive %s fsync latency %v back within baseline %v", globalLocalNodeName, lp, live, baseline.FsyncLatency)
+	}
+}
+
+// initDrivePerfBaselines benchmarks local drives without a recorded
+// baseline and starts the deviation monitor.
+func initDrivePerfBaselines(ctx context.Context) {
+	enabled, deviationPerc, interval := drivePerfConfig()
+	if !enabled {
+		return
+	}
+
+	go func() {
+		for _, lp := range localFormattedDrivePaths() {
+			if err := globalDrivePerfMonitor.ensureBaseline(ctx, lp); err != nil {
+				storageLogIf(ctx, fmt.Errorf("unable to record performance baseline for drive %s: %w", lp, err))
+			}
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, lp := range localFormattedDrivePaths() {
+					globalDrivePerfMonitor.probe(ctx, lp, deviationPerc)
+				}
+			}
+		}
+	}()
+}
+
+// drivePerfBaselineStatus is the per-drive state returned by the admin
+// API.
+type drivePerfBaselineStatus struct {
+	Node            string        `json:"node"`
+	Drive           string        `json:"drive"`
+	ReadThroughput  uint64        `json:"readThroughput"`
+	WriteThroughput uint64        `json:"writeThroughput"`
+	FsyncLatency    time.Duration `json:"fsyncLatency"`
+	MeasuredAt      time.Time     `json:"measuredAt"`
+	LastFsync       time.Duration `json:"lastFsyncLatency,omitempty"`
+	Degraded        bool          `json:"degraded"`
+}
+
+func (m *drivePerfMonitor) status() []drivePerfBaselineStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]drivePerfBaselineStatus, 0, len(m.baselines))
+	for lp, baseline := range m.baselines {
+		statuses = append(statuses, drivePerfBaselineStatus{
+			Node:            globalLocalNodeName,
+			Drive:           lp,
+			ReadThroughput:  baseline.ReadThroughput,
+			WriteThroughput: baseline.WriteThroughput,
+			FsyncLatency:    baseline.FsyncLatency,
+			MeasuredAt:      baseline.MeasuredAt,
+			LastFsync:       m.lastProbe[lp],
+			Degraded:        m.degraded[lp],
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Drive < statuses[j].Drive })
+	return statuses
+}
+
+// DrivePerfBaselineStatusHandler - returns the recorded baselines and
+// the latest probe results of the local drives.
+func (a adminAPIHandlers) DrivePerfBaselineStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	statusData, err := json.Marshal(globalDrivePerfMonitor.status())
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, statusData)
+}
+
+// DrivePerfRebaselineHandler - re-benchmarks the local drives and
+// persists fresh baselines, replacing the recorded ones.
+func (a adminAPIHandlers) DrivePerfRebaselineHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealthInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	var statuses []drivePerfBaselineStatus
+	for _, lp := range localFormattedDrivePaths() {
+		baseline, err := benchmarkDrive(ctx, lp)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+		if err = saveDrivePerfBaseline(baseline); err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+		globalDrivePerfMonitor.mu.Lock()
+		globalDrivePerfMonitor.baselines[lp] = baseline
+		delete(globalDrivePerfMonitor.degraded, lp)
+		globalDrivePerfMonitor.mu.Unlock()
+		statuses = append(statuses, drivePerfBaselineStatus{
+			Node:            globalLocalNodeName,
+			Drive:           lp,
+			ReadThroughput:  baseline.ReadThroughput,
+			WriteThroughput: baseline.WriteThroughput,
+			FsyncLatency:    baseline.FsyncLatency,
+			MeasuredAt:      baseline.MeasuredAt,
+		})
+	}
+
+	statusData, err := json.Marshal(statuses)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, statusData)
+}
@@ -0,0 +1,320 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/event"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/policy"
+)
+
+const (
+	// EnvDriveQuarantine enables automatic quarantine of flapping
+	// drives. A drive that keeps cycling between offline and online is
+	// kept offline permanently until an operator re-admits it, instead
+	// of being brought back automatically by the health monitor.
+	EnvDriveQuarantine = "MINIO_DRIVE_QUARANTINE"
+
+	// EnvDriveQuarantineFlapThreshold is the number of offline
+	// transitions within the flap window that quarantines a drive.
+	EnvDriveQuarantineFlapThreshold = "MINIO_DRIVE_QUARANTINE_FLAP_THRESHOLD"
+
+	// EnvDriveQuarantineFlapWindow is the sliding window flap
+	// transitions are counted over, old transitions age out of the
+	// window which provides the hysteresis for slowly degrading drives.
+	EnvDriveQuarantineFlapWindow = "MINIO_DRIVE_QUARANTINE_FLAP_WINDOW"
+
+	// EnvDriveQuarantineErrThreshold quarantines a drive when it
+	// accumulates this many availability errors (faulty I/O or
+	// timed out operations) within the flap window, even if the
+	// health monitor never took it offline. Zero disables the
+	// error rate trigger.
+	EnvDriveQuarantineErrThreshold = "MINIO_DRIVE_QUARANTINE_ERROR_THRESHOLD"
+
+	defaultDriveQuarantineFlapThreshold = 3
+	defaultDriveQuarantineFlapWindow    = time.Hour
+)
+
+var (
+	driveQuarantineOnce          sync.Once
+	driveQuarantineEnabled       bool
+	driveQuarantineFlapThreshold int
+	driveQuarantineFlapWindow    time.Duration
+	driveQuarantineErrThreshold  uint64
+)
+
+func driveQuarantineConfig() (enabled bool, flapThreshold int, window time.Duration, errThreshold uint64) {
+	driveQuarantineOnce.Do(func() {
+		driveQuarantineEnabled = env.Get(EnvDriveQuarantine, config.EnableOff) == config.EnableOn
+		driveQuarantineFlapThreshold = defaultDriveQuarantineFlapThreshold
+		if v := env.Get(EnvDriveQuarantineFlapThreshold, ""); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				driveQuarantineFlapThreshold = n
+			}
+		}
+		driveQuarantineFlapWindow = defaultDriveQuarantineFlapWindow
+		if v := env.Get(EnvDriveQuarantineFlapWindow, ""); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d > 0 {
+				driveQuarantineFlapWindow = d
+			}
+		}
+		if v := env.Get(EnvDriveQuarantineErrThreshold, ""); v != "" {
+			if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+				driveQuarantineErrThreshold = n
+			}
+		}
+	})
+	return driveQuarantineEnabled, driveQuarantineFlapThreshold, driveQuarantineFlapWindow, driveQuarantineErrThreshold
+}
+
+// quarantinedDrive records why and when a local drive was quarantined,
+// keeping a handle on the drive so a later re-admission can flip its
+// health status back.
+type quarantinedDrive struct {
+	disk   *xlStorageDiskIDCheck
+	since  time.Time
+	flaps  int
+	reason string
+}
+
+// driveQuarantine tracks offline transitions and availability errors of
+// local drives over a sliding window and quarantines drives that exceed
+// the configured thresholds.
+type driveQuarantine struct {
+	mu          sync.Mutex
+	flaps       map[string][]time.Time
+	errs        map[string][]time.Time
+	quarantined map[string]*quarantinedDrive
+}
+
+var globalDriveQuarantine = &driveQuarantine{
+	flaps:       make(map[string][]time.Time),
+	errs:        make(map[string][]time.Time),
+	quarantined: make(map[string]*quarantinedDrive),
+}
+
+var errDriveNotQuarantined = errors.New("drive is not quarantined")
+
+// prune drops transitions that have aged out of the window.
+func pruneOlderThan(ts []time.Time, now time.Time, window time.Duration) []time.Time {
+	for len(ts) > 0 && now.Sub(ts[0]) > window {
+		ts = ts[1:]
+	}
+	return ts
+}
+
+// recordOffline notes that the health monitor took a drive offline.
+// Returns true when the drive flapped beyond the configured threshold
+// and has been quarantined, in which case the caller must not start the
+// automatic recovery monitor.
+func (q *driveQuarantine) recordOffline(p *xlStorageDiskIDCheck) bool {
+	enabled, flapThreshold, window, _ := driveQuarantineConfig()
+	if !enabled {
+		return false
+	}
+
+	drive := p.storage.String()
+	now := time.Now().UTC()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	flaps := append(pruneOlderThan(q.flaps[drive], now, window), now)
+	q.flaps[drive] = flaps
+	if len(flaps) < flapThreshold {
+		return false
+	}
+
+	q.quarantineLocked(p, drive, len(flaps),
+		fmt.Sprintf("%d offline transitions within %v", len(flaps), window))
+	return true
+}
+
+// recordIOError notes an availability error (faulty I/O or timeout) on
+// a drive that is still online, quarantining it once the error rate
+// trigger is exceeded.
+func (q *driveQuarantine) recordIOError(p *xlStorageDiskIDCheck) {
+	enabled, _, window, errThreshold := driveQuarantineConfig()
+	if !enabled || errThreshold == 0 {
+		return
+	}
+
+	drive := p.storage.String()
+	now := time.Now().UTC()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	errs := append(pruneOlderThan(q.errs[drive], now, window), now)
+	q.errs[drive] = errs
+	if uint64(len(errs)) < errThreshold {
+		return
+	}
+
+	if !p.health.status.CompareAndSwap(diskHealthOK, diskHealthQuarantined) {
+		return
+	}
+	p.health.waiting.Add(1)
+	q.quarantineLocked(p, drive, len(q.flaps[drive]),
+		fmt.Sprintf("%d availability errors within %v", len(errs), window))
+}
+
+// quarantineLocked moves the drive into quarantined state, it stays
+// offline until re-admitted via the admin API. Must be called with
+// q.mu held.
+func (q *driveQuarantine) quarantineLocked(p *xlStorageDiskIDCheck, drive string, flaps int, reason string) {
+	p.health.status.Store(diskHealthQuarantined)
+	q.quarantined[drive] = &quarantinedDrive{
+		disk:   p,
+		since:  time.Now().UTC(),
+		flaps:  flaps,
+		reason: reason,
+	}
+	storageLogAlwaysIf(context.Background(), fmt.Errorf("node(%s): quarantining drive %s: %s", globalLocalNodeName, drive, reason))
+	sendSystemEvent(event.DriveQuarantined, map[string]string{
+		"node":   globalLocalNodeName,
+		"drive":  drive,
+		"reason": reason,
+	})
+}
+
+// readmit verifies a quarantined drive with a write/read/delete probe
+// and brings it back online when the probe succeeds.
+func (q *driveQuarantine) readmit(ctx context.Context, drive string) error {
+	q.mu.Lock()
+	qd, ok := q.quarantined[drive]
+	q.mu.Unlock()
+	if !ok {
+		return errDriveNotQuarantined
+	}
+
+	fn := mustGetUUID()
+	p := qd.disk
+	if err := p.storage.WriteAll(ctx, minioMetaTmpBucket, fn, toWrite); err != nil {
+		return fmt.Errorf("re-admission probe failed to write: %w", err)
+	}
+	b, err := p.storage.ReadAll(ctx, minioMetaTmpBucket, fn)
+	if err != nil {
+		return fmt.Errorf("re-admission probe failed to read: %w", err)
+	}
+	if len(b) != len(toWrite) {
+		return fmt.Errorf("re-admission probe read %d bytes, expected %d", len(b), len(toWrite))
+	}
+	if err = p.storage.Delete(ctx, minioMetaTmpBucket, fn, DeleteOptions{}); err != nil {
+		return fmt.Errorf("re-admission probe failed to delete: %w", err)
+	}
+
+	q.mu.Lock()
+	delete(q.quarantined, drive)
+	delete(q.flaps, drive)
+	delete(q.errs, drive)
+	q.mu.Unlock()
+
+	p.health.status.Store(diskHealthOK)
+	p.health.waiting.Add(-1)
+	logger.Event(ctx, "healthcheck",
+		"node(%s): re-admission probe successful, bringing drive %s online", globalLocalNodeName, drive)
+	sendSystemEvent(event.DriveReadmitted, map[string]string{
+		"node":  globalLocalNodeName,
+		"drive": drive,
+	})
+	return nil
+}
+
+// quarantinedDriveStatus is the per-drive quarantine state returned by
+// the admin API.
+type quarantinedDriveStatus struct {
+	Node   string    `json:"node"`
+	Drive  string    `json:"drive"`
+	Since  time.Time `json:"since"`
+	Flaps  int       `json:"flaps"`
+	Reason string    `json:"reason"`
+}
+
+func (q *driveQuarantine) status() []quarantinedDriveStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	statuses := make([]quarantinedDriveStatus, 0, len(q.quarantined))
+	for drive, qd := range q.quarantined {
+		statuses = append(statuses, quarantinedDriveStatus{
+			Node:   globalLocalNodeName,
+			Drive:  drive,
+			Since:  qd.since,
+			Flaps:  qd.flaps,
+			Reason: qd.reason,
+		})
+	}
+	return statuses
+}
+
+// DriveQuarantineStatusHandler - returns the drives currently
+// quarantined on this node.
+func (a adminAPIHandlers) DriveQuarantineStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	statusData, err := json.Marshal(globalDriveQuarantine.status())
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, statusData)
+}
+
+// DriveReadmitHandler - re-admits a quarantined drive after verifying
+// it with a write/read/delete probe.
+func (a adminAPIHandlers) DriveReadmitHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	drive := vars["drive"]
+	if drive == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+
+	if err := globalDriveQuarantine.readmit(ctx, drive); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
@@ -0,0 +1,417 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/event"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/policy"
+)
+
+const (
+	// EnvDriveScrub turns the background deep scrubber on, off by
+	// default since a full scrub reads every erasure shard on a drive.
+	EnvDriveScrub = "MINIO_DRIVE_SCRUB"
+
+	// EnvDriveScrubInterval configures how often a full scrub cycle is
+	// started per drive, defaults to once every 30 days.
+	EnvDriveScrubInterval = "MINIO_DRIVE_SCRUB_INTERVAL"
+
+	// scrubCursorFilename is where per-drive scrub progress is
+	// persisted, next to the healing tracker.
+	scrubCursorFilename = ".scrub.json"
+
+	defaultScrubInterval = 30 * 24 * time.Hour
+
+	// Persist the cursor after this many objects so an interrupted
+	// cycle resumes close to where it stopped.
+	scrubCursorSaveInterval = 1000
+)
+
+// driveScrubCursor records deep scrub progress of a single drive, it is
+// persisted on the drive itself so a restarted node resumes mid-cycle.
+type driveScrubCursor struct {
+	// Last fully scrubbed bucket/object of the in-progress cycle,
+	// both empty when no cycle is running.
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+
+	CycleStarted   time.Time `json:"cycleStarted"`
+	CycleCompleted time.Time `json:"cycleCompleted"`
+
+	CyclesCompleted  uint64 `json:"cyclesCompleted"`
+	ObjectsScanned   uint64 `json:"objectsScanned"`
+	ObjectsCorrupted uint64 `json:"objectsCorrupted"`
+
+	LastUpdate time.Time `json:"lastUpdate"`
+}
+
+// driveScrubber runs deep bitrot scrubs of all local drives, one drive
+// at a time, independently of the usage scanner.
+type driveScrubber struct {
+	mu       sync.RWMutex
+	enabled  bool
+	paused   bool
+	interval time.Duration
+	cursors  map[string]*driveScrubCursor
+}
+
+// newDriveScrubber - creates new deep scrubber subsystem.
+func newDriveScrubber() *driveScrubber {
+	interval := defaultScrubInterval
+	if is := env.Get(EnvDriveScrubInterval, ""); is != "" {
+		if d, err := time.ParseDuration(is); err == nil && d > 0 {
+			interval = d
+		}
+	}
+	return &driveScrubber{
+		enabled:  env.Get(EnvDriveScrub, config.EnableOff) == config.EnableOn,
+		interval: interval,
+		cursors:  make(map[string]*driveScrubCursor),
+	}
+}
+
+// initDriveScrubber initializes the deep scrubber and starts its
+// background loop when enabled via environment.
+func initDriveScrubber(ctx context.Context) {
+	globalDriveScrubber = newDriveScrubber()
+	if globalDriveScrubber.enabled {
+		go globalDriveScrubber.run(ctx)
+	}
+}
+
+func (s *driveScrubber) pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+}
+
+func (s *driveScrubber) resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = false
+}
+
+func (s *driveScrubber) isPaused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.paused
+}
+
+// waitIfPaused blocks while the scrubber is administratively paused.
+func (s *driveScrubber) waitIfPaused(ctx context.Context) {
+	for s.isPaused() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// run wakes up once a minute and scrubs any local drive whose last full
+// cycle is older than the configured interval, resuming in-progress
+// cycles first. Drives are scrubbed sequentially to bound the extra IO
+// on a node.
+func (s *driveScrubber) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Minute):
+		}
+
+		globalLocalDrivesMu.RLock()
+		localDrives := cloneDrives(globalLocalDrivesMap)
+		globalLocalDrivesMu.RUnlock()
+
+		for _, disk := range localDrives {
+			if disk == nil {
+				continue
+			}
+			cursor := s.loadCursor(ctx, disk)
+			resuming := cursor.Bucket != ""
+			if !resuming && time.Since(cursor.CycleCompleted) < s.interval {
+				continue
+			}
+			if !resuming {
+				cursor.CycleStarted = time.Now().UTC()
+			}
+			if err := s.scrubDrive(ctx, disk, cursor); err != nil {
+				scannerLogIf(ctx, err)
+			}
+		}
+	}
+}
+
+// loadCursor reads the persisted scrub cursor of a drive, returning a
+// fresh cursor if none exists yet.
+func (s *driveScrubber) loadCursor(ctx context.Context, disk StorageAPI) *driveScrubCursor {
+	endpoint := disk.Endpoint().String()
+
+	s.mu.RLock()
+	cursor, ok := s.cursors[endpoint]
+	s.mu.RUnlock()
+	if ok {
+		return cursor
+	}
+
+	cursor = &driveScrubCursor{}
+	if b, err := disk.ReadAll(ctx, minioMetaBucket, pathJoin(bucketMetaPrefix, scrubCursorFilename)); err == nil {
+		// A corrupt or unreadable cursor simply restarts the cycle.
+		_ = json.Unmarshal(b, cursor)
+	}
+
+	s.mu.Lock()
+	s.cursors[endpoint] = cursor
+	s.mu.Unlock()
+	return cursor
+}
+
+func (s *driveScrubber) saveCursor(ctx context.Context, disk StorageAPI, cursor *driveScrubCursor) {
+	s.mu.Lock()
+	cursor.LastUpdate = time.Now().UTC()
+	b, err := json.Marshal(cursor)
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+	_ = disk.WriteAll(ctx, minioMetaBucket, pathJoin(bucketMetaPrefix, scrubCursorFilename), b)
+}
+
+// scrubDrive runs one full scrub cycle over a drive, continuing from
+// the persisted cursor if a cycle was interrupted.
+func (s *driveScrubber) scrubDrive(ctx context.Context, disk StorageAPI, cursor *driveScrubCursor) error {
+	volumes, err := disk.ListVols(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+
+	for _, volume := range volumes {
+		if isMinioMetaBucketName(volume.Name) {
+			continue
+		}
+		// Buckets fully scrubbed in this cycle already.
+		if volume.Name < cursor.Bucket {
+			continue
+		}
+		if err := s.scrubPrefix(ctx, disk, volume.Name, "", cursor); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		cursor.Bucket, cursor.Object = volume.Name, ""
+		s.mu.Unlock()
+		s.saveCursor(ctx, disk, cursor)
+	}
+
+	s.mu.Lock()
+	cursor.Bucket, cursor.Object = "", ""
+	cursor.CycleCompleted = time.Now().UTC()
+	cursor.CyclesCompleted++
+	s.mu.Unlock()
+	s.saveCursor(ctx, disk, cursor)
+	return nil
+}
+
+// scrubPrefix walks a prefix on the drive in sorted order, verifying
+// every object it finds and recursing into sub-prefixes.
+func (s *driveScrubber) scrubPrefix(ctx context.Context, disk StorageAPI, volume, dirPath string, cursor *driveScrubCursor) error {
+	if contextCanceled(ctx) {
+		return ctx.Err()
+	}
+
+	entries, err := disk.ListDir(ctx, "", volume, dirPath, -1)
+	if err != nil {
+		if errors.Is(err, errVolumeNotFound) || errors.Is(err, errFileNotFound) {
+			return nil
+		}
+		return err
+	}
+	sort.Strings(entries)
+
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry, SlashSeparator) {
+			continue
+		}
+		name := pathJoin(dirPath, strings.TrimSuffix(entry, SlashSeparator))
+
+		fi, err := disk.ReadVersion(ctx, "", volume, name, "", ReadOptions{})
+		switch {
+		case err == nil:
+			// Objects up to the cursor were verified in the
+			// interrupted cycle.
+			if volume == cursor.Bucket && name <= cursor.Object {
+				continue
+			}
+			s.scrubObject(ctx, disk, volume, name, fi, cursor)
+		case errors.Is(err, errFileNotFound), errors.Is(err, errPathNotFound):
+			// Not an object, recurse into the prefix.
+			if err := s.scrubPrefix(ctx, disk, volume, name, cursor); err != nil {
+				return err
+			}
+		default:
+			// Unreadable metadata is left alone here, the healer
+			// deals with it.
+			continue
+		}
+	}
+	return nil
+}
+
+// scrubObject verifies the erasure shards of the latest object version
+// on this drive, emitting a corruption event and queueing a bitrot heal
+// when verification fails.
+func (s *driveScrubber) scrubObject(ctx context.Context, disk StorageAPI, volume, object string, fi FileInfo, cursor *driveScrubCursor) {
+	s.waitIfPaused(ctx)
+	waitForLowHTTPReq()
+
+	s.mu.Lock()
+	cursor.ObjectsScanned++
+	cursor.Bucket, cursor.Object = volume, object
+	saveNow := cursor.ObjectsScanned%scrubCursorSaveInterval == 0
+	s.mu.Unlock()
+	if saveNow {
+		s.saveCursor(ctx, disk, cursor)
+	}
+
+	if fi.Deleted || fi.InlineData() {
+		// Delete markers have no data, inline data is verified as
+		// part of reading the metadata itself.
+		return
+	}
+
+	corrupted := false
+	resp, err := disk.VerifyFile(ctx, volume, object, fi)
+	if err != nil {
+		corrupted = errors.Is(err, errFileCorrupt)
+	} else {
+		for _, result := range resp.Results {
+			if result == checkPartFileCorrupt {
+				corrupted = true
+				break
+			}
+		}
+	}
+	if !corrupted {
+		return
+	}
+
+	s.mu.Lock()
+	cursor.ObjectsCorrupted++
+	s.mu.Unlock()
+
+	ep := disk.Endpoint()
+	sendSystemEvent(event.ScrubObjectCorrupted, map[string]string{
+		"bucket":    volume,
+		"object":    object,
+		"versionId": fi.VersionID,
+		"drive":     ep.String(),
+	})
+
+	globalMRFState.addPartialOp(PartialOperation{
+		Bucket:     volume,
+		Object:     object,
+		VersionID:  fi.VersionID,
+		SetIndex:   ep.SetIdx,
+		PoolIndex:  ep.PoolIdx,
+		Queued:     time.Now(),
+		BitrotScan: true,
+	})
+}
+
+// driveScrubStatus is the JSON status returned by the scrub status
+// admin API.
+type driveScrubStatus struct {
+	Enabled  bool                        `json:"enabled"`
+	Paused   bool                        `json:"paused"`
+	Interval string                      `json:"interval"`
+	Drives   map[string]driveScrubCursor `json:"drives"`
+}
+
+func (s *driveScrubber) status() driveScrubStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st := driveScrubStatus{
+		Enabled:  s.enabled,
+		Paused:   s.paused,
+		Interval: s.interval.String(),
+		Drives:   make(map[string]driveScrubCursor, len(s.cursors)),
+	}
+	for endpoint, cursor := range s.cursors {
+		st.Drives[endpoint] = *cursor
+	}
+	return st
+}
+
+// DriveScrubStatusHandler - returns deep scrub progress of all local
+// drives on this node.
+func (a adminAPIHandlers) DriveScrubStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	statusData, err := json.Marshal(globalDriveScrubber.status())
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, statusData)
+}
+
+// DriveScrubPauseHandler - pauses background deep scrubbing on this
+// node until resumed.
+func (a adminAPIHandlers) DriveScrubPauseHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	globalDriveScrubber.pause()
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// DriveScrubResumeHandler - resumes background deep scrubbing on this
+// node.
+func (a adminAPIHandlers) DriveScrubResumeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	globalDriveScrubber.resume()
+	writeSuccessResponseHeadersOnly(w)
+}
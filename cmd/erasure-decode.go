@@ -24,6 +24,7 @@ import (
 	"io"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	xioutil "github.com/minio/minio/internal/ioutil"
 )
@@ -39,10 +40,12 @@ type parallelReader struct {
 	buf           [][]byte
 	readerToBuf   []int
 	stashBuffer   []byte
+	hedgeDelay    time.Duration
 }
 
 // newParallelReader returns parallelReader.
 func newParallelReader(readers []io.ReaderAt, e Erasure, offset, totalLength int64) *parallelReader {
+	_, hedgeDelay := erasureReadConfig()
 	r2b := make([]int, len(readers))
 	for i := range r2b {
 		r2b[i] = i
@@ -72,6 +75,7 @@ func newParallelReader(readers []io.ReaderAt, e Erasure, offset, totalLength int
 		buf:           make([][]byte, len(readers)),
 		readerToBuf:   r2b,
 		stashBuffer:   b,
+		hedgeDelay:    hedgeDelay,
 	}
 }
 
@@ -142,7 +146,8 @@ func (p *parallelReader) Read(dst [][]byte) ([][]byte, error) {
 		return newBuf, nil
 	}
 
-	readTriggerCh := make(chan bool, len(p.readers))
+	// One extra slot so a hedged trigger never blocks its goroutine.
+	readTriggerCh := make(chan bool, len(p.readers)+1)
 	defer xioutil.SafeClose(readTriggerCh) // close the channel upon return
 
 	for i := 0; i < p.dataBlocks; i++ {
@@ -150,6 +155,27 @@ func (p *parallelReader) Read(dst [][]byte) ([][]byte, error) {
 		readTriggerCh <- true
 	}
 
+	if p.hedgeDelay > 0 {
+		// Hedge the read: when the shards are not decodable once the
+		// delay expires, one of the dispatched reads is stuck on a
+		// slow drive - trigger an extra read on the next (parity)
+		// drive instead of waiting for it.
+		hedgeDone := make(chan struct{})
+		defer close(hedgeDone)
+		go func() {
+			t := time.NewTimer(p.hedgeDelay)
+			defer t.Stop()
+			select {
+			case <-hedgeDone:
+			case <-t.C:
+				select {
+				case <-hedgeDone:
+				case readTriggerCh <- true:
+				}
+			}
+		}()
+	}
+
 	disksNotFound := int32(0)
 	bitrotHeal := int32(0)       // Atomic bool flag.
 	missingPartsHeal := int32(0) // Atomic bool flag.
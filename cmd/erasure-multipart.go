@@ -25,6 +25,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -32,6 +33,7 @@ import (
 	"time"
 
 	"github.com/klauspost/readahead"
+	"github.com/minio/madmin-go/v3"
 	"github.com/minio/minio-go/v7/pkg/set"
 	"github.com/minio/minio/internal/config/storageclass"
 	"github.com/minio/minio/internal/crypto"
@@ -1066,6 +1068,60 @@ func objPartToPartErr(part ObjectPartInfo) error {
 	return errors.New(part.Error)
 }
 
+// completePartsPerWorker is the number of parts a single verification
+// worker handles at CompleteMultipartUpload time, uploads with fewer
+// parts are verified inline.
+const completePartsPerWorker = 256
+
+// completeVerifyWorkers returns how many workers verify the uploaded
+// parts. The count scales with the number of parts up to GOMAXPROCS and
+// backs off when the drives of this set report waiting operations, so a
+// large completion does not starve concurrent I/O.
+func (er erasureObjects) completeVerifyWorkers(ctx context.Context, parts int) int {
+	workers := (parts + completePartsPerWorker - 1) / completePartsPerWorker
+	if workers <= 1 {
+		return 1
+	}
+	if procs := runtime.GOMAXPROCS(0); workers > procs {
+		workers = procs
+	}
+
+	var waiting int
+	for _, disk := range er.getDisks() {
+		if disk == nil || !disk.IsOnline() {
+			continue
+		}
+		info, err := disk.DiskInfo(ctx, DiskInfoOptions{NoOp: true})
+		if err != nil {
+			continue
+		}
+		if w := int(info.Metrics.TotalWaiting); w > waiting {
+			waiting = w
+		}
+	}
+	switch {
+	case waiting > 128:
+		workers = 1
+	case waiting > 32:
+		workers = (workers + 1) / 2
+	}
+	return workers
+}
+
+// completeMultipartTrace returns a trace event covering part
+// verification and erasure metadata assembly of one completed upload.
+func completeMultipartTrace(startTime time.Time, duration time.Duration, path string, parts, workers int) madmin.TraceInfo {
+	return madmin.TraceInfo{
+		TraceType: madmin.TraceInternal,
+		Time:      startTime,
+		NodeName:  globalLocalNodeName,
+		FuncName:  "erasure.completeMultipartVerify",
+		Duration:  duration,
+		Path:      path,
+		Message:   fmt.Sprintf("parts=%d, workers=%d", parts, workers),
+	}
+}
+
 // CompleteMultipartUpload - completes an ongoing multipart
 // transaction after receiving all the parts indicated by the client.
 // Returns an md5sum calculated by concatenating all the individual
@@ -1220,84 +1276,101 @@ func (er erasureObjects) CompleteMultipartUpload(ctx context.Context, bucket str
 	// Allocate parts similar to incoming slice.
 	fi.Parts = make([]ObjectPartInfo, len(parts))
 
-	// Validate each part and then commit to disk.
+	// Validate each part concurrently and then commit to disk, uploads
+	// with thousands of parts spend most of the completion here.
+	verifyStart := time.Now()
+	workers := er.completeVerifyWorkers(ctx, len(parts))
+	partChecksums := make([][]byte, len(parts))
+	vg := errgroup.WithNErrs(len(parts)).WithConcurrency(workers)
 	for i, part := range parts {
-		partIdx := objectPartIndex(currentFI.Parts, part.PartNumber)
-		// All parts should have same part number.
-		if partIdx == -1 {
-			invp := InvalidPart{
-				PartNumber: part.PartNumber,
-				GotETag:    part.ETag,
-			}
-			return oi, invp
-		}
-		expPart := currentFI.Parts[partIdx]
-
-		// ensure that part ETag is canonicalized to strip off extraneous quotes
-		part.ETag = canonicalizeETag(part.ETag)
-		expETag := tryDecryptETag(objectEncryptionKey, expPart.ETag, kind == crypto.S3)
-		if expETag != part.ETag {
-			invp := InvalidPart{
-				PartNumber: part.PartNumber,
-				ExpETag:    expETag,
-				GotETag:    part.ETag,
+		i, part := i, part
+		vg.Go(func() error {
+			partIdx := objectPartIndex(currentFI.Parts, part.PartNumber)
+			// All parts should have same part number.
+			if partIdx == -1 {
+				return InvalidPart{
+					PartNumber: part.PartNumber,
+					GotETag:    part.ETag,
+				}
 			}
-			return oi, invp
-		}
+			expPart := currentFI.Parts[partIdx]
 
-		if checksumType.IsSet() {
-			crc := expPart.Checksums[checksumType.String()]
-			if crc == "" {
-				return oi, InvalidPart{
+			// ensure that part ETag is canonicalized to strip off extraneous quotes
+			part.ETag = canonicalizeETag(part.ETag)
+			expETag := tryDecryptETag(objectEncryptionKey, expPart.ETag, kind == crypto.S3)
+			if expETag != part.ETag {
+				return InvalidPart{
 					PartNumber: part.PartNumber,
+					ExpETag:    expETag,
+					GotETag:    part.ETag,
 				}
 			}
-			wantCS := map[string]string{
-				hash.ChecksumCRC32.String():  part.ChecksumCRC32,
-				hash.ChecksumCRC32C.String(): part.ChecksumCRC32C,
-				hash.ChecksumSHA1.String():   part.ChecksumSHA1,
-				hash.ChecksumSHA256.String(): part.ChecksumSHA256,
-			}
-			if wantCS[checksumType.String()] != crc {
-				return oi, InvalidPart{
-					PartNumber: part.PartNumber,
-					ExpETag:    wantCS[checksumType.String()],
-					GotETag:    crc,
+
+			if checksumType.IsSet() {
+				crc := expPart.Checksums[checksumType.String()]
+				if crc == "" {
+					return InvalidPart{
+						PartNumber: part.PartNumber,
+					}
+				}
+				wantCS := map[string]string{
+					hash.ChecksumCRC32.String():  part.ChecksumCRC32,
+					hash.ChecksumCRC32C.String(): part.ChecksumCRC32C,
+					hash.ChecksumSHA1.String():   part.ChecksumSHA1,
+					hash.ChecksumSHA256.String(): part.ChecksumSHA256,
+				}
+				if wantCS[checksumType.String()] != crc {
+					return InvalidPart{
+						PartNumber: part.PartNumber,
+						ExpETag:    wantCS[checksumType.String()],
+						GotETag:    crc,
+					}
 				}
+				cs := hash.NewChecksumString(checksumType.String(), crc)
+				if !cs.Valid() {
+					return InvalidPart{
+						PartNumber: part.PartNumber,
+					}
+				}
+				partChecksums[i] = cs.Raw
 			}
-			cs := hash.NewChecksumString(checksumType.String(), crc)
-			if !cs.Valid() {
-				return oi, InvalidPart{
+
+			// All parts except the last part has to be at least 5MB.
+			if (i < len(parts)-1) && !isMinAllowedPartSize(expPart.ActualSize) {
+				return PartTooSmall{
 					PartNumber: part.PartNumber,
+					PartSize:   expPart.ActualSize,
+					PartETag:   part.ETag,
 				}
 			}
-			checksumCombined = append(checksumCombined, cs.Raw...)
-		}
 
-		// All parts except the last part has to be at least 5MB.
-		if (i < len(parts)-1) && !isMinAllowedPartSize(currentFI.Parts[partIdx].ActualSize) {
-			return oi, PartTooSmall{
-				PartNumber: part.PartNumber,
-				PartSize:   expPart.ActualSize,
-				PartETag:   part.ETag,
+			// Add incoming parts.
+			fi.Parts[i] = ObjectPartInfo{
+				Number:     part.PartNumber,
+				Size:       expPart.Size,
+				ActualSize: expPart.ActualSize,
+				ModTime:    expPart.ModTime,
+				Index:      expPart.Index,
+				Checksums:  nil, // Not transferred since we do not need it.
 			}
+			return nil
+		}, i)
+	}
+	for _, verr := range vg.Wait() {
+		if verr != nil {
+			return oi, verr
 		}
+	}
 
-		// Save for total object size.
-		objectSize += expPart.Size
-
-		// Save the consolidated actual size.
-		objectActualSize += expPart.ActualSize
+	// Combine sizes and checksums in part order.
+	for i := range parts {
+		objectSize += fi.Parts[i].Size
+		objectActualSize += fi.Parts[i].ActualSize
+		checksumCombined = append(checksumCombined, partChecksums[i]...)
+	}
 
-		// Add incoming parts.
-		fi.Parts[i] = ObjectPartInfo{
-			Number:     part.PartNumber,
-			Size:       expPart.Size,
-			ActualSize: expPart.ActualSize,
-			ModTime:    expPart.ModTime,
-			Index:      expPart.Index,
-			Checksums:  nil, // Not transferred since we do not need it.
-		}
+	if globalTrace.NumSubscribers(madmin.TraceInternal) > 0 {
+		globalTrace.Publish(completeMultipartTrace(verifyStart, time.Since(verifyStart), pathJoin(bucket, object), len(parts), workers))
 	}
 
 	if opts.WantChecksum != nil {
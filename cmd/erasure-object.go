@@ -379,10 +379,17 @@ func (er erasureObjects) getObjectWithFileInfo(ctx context.Context, bucket, obje
 			partPath := pathJoin(object, metaArr[index].DataDir, fmt.Sprintf("part.%d", partNumber))
 			readers[index] = newBitrotReader(disk, metaArr[index].Data, bucket, partPath, tillOffset,
 				checksumInfo.Algorithm, checksumInfo.Hash, erasure.ShardSize())
+			if fastestRead, _ := erasureReadConfig(); fastestRead {
+				readers[index] = newLatencyTrackingReader(readers[index], disk.String())
+			}
 
 			// Prefer local disks
 			prefer[index] = disk.Hostname() == ""
 		}
+		if fastestRead, _ := erasureReadConfig(); fastestRead {
+			// Prefer the fastest disks by read latency EWMA instead.
+			preferFastestReaders(prefer, readers, onlineDisks, fi.Erasure.DataBlocks)
+		}
 
 		written, err := erasure.Decode(ctx, writer, readers, partOffset, partLength, partSize, prefer)
 		// Note: we should not be defer'ing the following closeBitrotReaders() call as
@@ -1381,7 +1388,7 @@ func (er erasureObjects) putObject(ctx context.Context, bucket string, object st
 	defer er.deleteAll(context.Background(), minioMetaTmpBucket, tempObj)
 
 	var inlineBuffers []*bytes.Buffer
-	if globalStorageClass.ShouldInline(erasure.ShardFileSize(data.ActualSize()), opts.Versioned) {
+	if globalStorageClass.ShouldInlineForSC(userDefined[xhttp.AmzStorageClass], erasure.ShardFileSize(data.ActualSize()), opts.Versioned) {
 		inlineBuffers = make([]*bytes.Buffer, len(onlineDisks))
 	}
 
@@ -0,0 +1,150 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Latency-aware erasure reads. A GET only needs the data shards, so
+// the parallel reader dispatches K reads and falls back to parity when
+// one fails. With a fixed ordering one slow-but-working drive sits on
+// the critical path of every read it participates in and drags p99 GET
+// latency with it. With latency-aware reads enabled each drive's shard
+// read latency is folded into an EWMA, the K drives with the lowest
+// EWMA are dispatched first, and when a dispatched read has not
+// completed within the hedge delay an extra read is started on the
+// next (parity) drive so the slow one is off the critical path.
+
+const (
+	// EnvErasureReadFastest enables EWMA based shard selection for
+	// erasure reads.
+	EnvErasureReadFastest = "MINIO_ERASURE_READ_FASTEST"
+
+	// EnvErasureReadHedgeDelay starts an extra shard read on a parity
+	// drive when a dispatched read has not completed within this
+	// delay. Zero disables hedging.
+	EnvErasureReadHedgeDelay = "MINIO_ERASURE_READ_HEDGE_DELAY"
+
+	// driveReadEWMAAlpha is the smoothing factor, ~70% of the weight
+	// sits in the last five observations.
+	driveReadEWMAAlpha = 0.3
+)
+
+var (
+	erasureReadOnce       sync.Once
+	erasureReadFastest    bool
+	erasureReadHedgeDelay time.Duration
+)
+
+func erasureReadConfig() (fastest bool, hedgeDelay time.Duration) {
+	erasureReadOnce.Do(func() {
+		erasureReadFastest = env.Get(EnvErasureReadFastest, config.EnableOff) == config.EnableOn
+		if v := env.Get(EnvErasureReadHedgeDelay, ""); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d > 0 {
+				erasureReadHedgeDelay = d
+			}
+		}
+	})
+	return erasureReadFastest, erasureReadHedgeDelay
+}
+
+// driveReadEWMA maintains one shard read latency EWMA per drive.
+type driveReadEWMA struct {
+	mu   sync.RWMutex
+	ewma map[string]float64 // nanoseconds
+}
+
+var globalDriveReadEWMA = &driveReadEWMA{
+	ewma: make(map[string]float64),
+}
+
+func (t *driveReadEWMA) observe(drive string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cur, ok := t.ewma[drive]
+	if !ok {
+		t.ewma[drive] = float64(d)
+		return
+	}
+	t.ewma[drive] = driveReadEWMAAlpha*float64(d) + (1-driveReadEWMAAlpha)*cur
+}
+
+// get returns the current EWMA of a drive, zero when the drive has no
+// observations yet so new drives are tried eagerly.
+func (t *driveReadEWMA) get(drive string) float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.ewma[drive]
+}
+
+// latencyTrackingReader folds the latency of every shard read into the
+// drive's EWMA.
+type latencyTrackingReader struct {
+	r     io.ReaderAt
+	drive string
+}
+
+func newLatencyTrackingReader(r io.ReaderAt, drive string) *latencyTrackingReader {
+	return &latencyTrackingReader{r: r, drive: drive}
+}
+
+func (l *latencyTrackingReader) ReadAt(p []byte, off int64) (int, error) {
+	t := time.Now()
+	n, err := l.r.ReadAt(p, off)
+	if err == nil {
+		globalDriveReadEWMA.observe(l.drive, time.Since(t))
+	}
+	return n, err
+}
+
+func (l *latencyTrackingReader) Close() error {
+	if c, ok := l.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// preferFastestReaders marks the dataBlocks readers with the lowest
+// latency EWMA as preferred, replacing the static local-first
+// ordering.
+func preferFastestReaders(prefer []bool, readers []io.ReaderAt, disks []StorageAPI, dataBlocks int) {
+	candidates := make([]int, 0, len(readers))
+	for index, r := range readers {
+		prefer[index] = false
+		if r == nil || disks[index] == OfflineDisk {
+			continue
+		}
+		candidates = append(candidates, index)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return globalDriveReadEWMA.get(disks[candidates[i]].String()) < globalDriveReadEWMA.get(disks[candidates[j]].String())
+	})
+	if len(candidates) > dataBlocks {
+		candidates = candidates[:dataBlocks]
+	}
+	for _, index := range candidates {
+		prefer[index] = true
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,6 +37,7 @@ import (
 	objectlock "github.com/minio/minio/internal/bucket/object/lock"
 	"github.com/minio/minio/internal/bucket/replication"
 	"github.com/minio/minio/internal/bucket/versioning"
+	"github.com/minio/minio/internal/event"
 	"github.com/minio/minio/internal/hash"
 	xioutil "github.com/minio/minio/internal/ioutil"
 	"github.com/minio/minio/internal/logger"
@@ -438,6 +440,9 @@ func (z *erasureServerPools) rebalanceBuckets(ctx context.Context, poolIdx int)
 	}()
 
 	rebalanceLogEvent(ctx, "Pool %d rebalancing is started", poolIdx+1)
+	sendSystemEvent(event.RebalanceStarted, map[string]string{
+		"pool": strconv.Itoa(poolIdx),
+	})
 
 	for {
 		select {
@@ -465,9 +470,16 @@ func (z *erasureServerPools) rebalanceBuckets(ctx context.Context, poolIdx int)
 		}
 		stopFn(0, nil)
 		z.bucketRebalanceDone(bucket, poolIdx)
+		sendSystemEvent(event.RebalanceBucketCompleted, map[string]string{
+			"pool":   strconv.Itoa(poolIdx),
+			"bucket": bucket,
+		})
 	}
 
 	rebalanceLogEvent(GlobalContext, "Pool %d rebalancing is done", poolIdx+1)
+	sendSystemEvent(event.RebalanceCompleted, map[string]string{
+		"pool": strconv.Itoa(poolIdx),
+	})
 
 	return err
 }
@@ -570,6 +582,11 @@ func (z *erasureServerPools) rebalanceBucket(ctx context.Context, bucket string,
 		workerSize = len(pool.sets)
 	}
 
+	// Runtime worker override set via the rebalance throttle admin API.
+	if w := globalRebalanceThrottle.workersFor(poolIdx); w > 0 {
+		workerSize = w
+	}
+
 	// Each decom worker needs one List() goroutine/worker
 	// add that many extra workers.
 	workerSize += len(pool.sets)
@@ -581,6 +598,7 @@ func (z *erasureServerPools) rebalanceBucket(ctx context.Context, bucket string,
 
 	for setIdx, set := range pool.sets {
 		set := set
+		globalRebalanceProgress.initSet(poolIdx, setIdx)
 
 		filterLifecycle := func(bucket, object string, fi FileInfo) bool {
 			if lc == nil {
@@ -689,6 +707,10 @@ func (z *erasureServerPools) rebalanceBucket(ctx context.Context, bucket string,
 					continue
 				}
 
+				// Apply the runtime byte-rate throttle of this pool,
+				// delete markers carry no data and are not throttled.
+				globalRebalanceThrottle.waitN(ctx, poolIdx, version.Size)
+
 				for try := 0; try < 3; try++ {
 					// GetObjectReader.Close is called by rebalanceObject
 					gr, err := set.GetObjectNInfo(ctx,
@@ -740,6 +762,7 @@ func (z *erasureServerPools) rebalanceBucket(ctx context.Context, bucket string,
 					break // break out on first error
 				}
 				z.updatePoolStats(poolIdx, bucket, version)
+				globalRebalanceProgress.add(poolIdx, setIdx, uint64(version.Size))
 				rebalanced++
 			}
 
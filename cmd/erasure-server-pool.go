@@ -95,8 +95,8 @@ func newErasureServerPools(ctx context.Context, endpointServerPools EndpointServ
 		n = 2048
 	}
 
-	// Avoid allocating more than half of the available memory
-	if maxN := availableMemory() / (blockSizeV2 * 2); n > maxN {
+	// Stay within the central buffer memory budget.
+	if maxN := bufferMemoryLimit() / (blockSizeV2 * 2); n > maxN {
 		n = maxN
 	}
 
@@ -196,6 +196,77 @@ func newErasureServerPools(ctx context.Context, endpointServerPools EndpointServ
 		initAutoHeal(GlobalContext, z)
 	})
 
+	// Start the background deep scrubber for local drives.
+	bootstrapTrace("initDriveScrubber", func() {
+		initDriveScrubber(GlobalContext)
+	})
+
+	// Start OTLP trace export, if configured.
+	bootstrapTrace("initOTelTraceExporter", func() {
+		initOTelTraceExporter(GlobalContext)
+	})
+
+	// Start the continuous profiler, if enabled.
+	bootstrapTrace("initContinuousProfiler", func() {
+		initContinuousProfiler(GlobalContext)
+	})
+
+	// Configure the Splunk HEC audit target, if enabled.
+	bootstrapTrace("initAuditSplunkTarget", func() {
+		initAuditSplunkTarget(GlobalContext)
+	})
+
+	// Start buffering recent traces for after-the-fact queries.
+	bootstrapTrace("initTraceBuffer", func() {
+		initTraceBuffer(GlobalContext)
+	})
+
+	// Start pushing metrics via Prometheus remote-write, if configured.
+	bootstrapTrace("initMetricsRemoteWrite", func() {
+		initMetricsRemoteWrite(GlobalContext)
+	})
+
+	// Start the bucket inventory scheduler.
+	bootstrapTrace("initBucketInventory", func() {
+		initBucketInventory(GlobalContext, z)
+	})
+
+	bootstrapTrace("initTenantSys", func() {
+		initTenantSys(GlobalContext, z)
+	})
+
+	bootstrapTrace("initBatchJobScheduler", func() {
+		initBatchJobScheduler(GlobalContext, z)
+	})
+
+	bootstrapTrace("initTrafficShaping", func() {
+		initTrafficShaping(GlobalContext, z)
+	})
+
+	bootstrapTrace("initPoolPlacement", func() {
+		initPoolPlacement(GlobalContext, z)
+	})
+
+	bootstrapTrace("initInlineStats", func() {
+		initInlineStats(z)
+	})
+
+	bootstrapTrace("initReadCache", func() {
+		initReadCache(GlobalContext)
+	})
+
+	bootstrapTrace("initListingIndex", func() {
+		initListingIndex(GlobalContext, z)
+	})
+
+	bootstrapTrace("initWriteJournal", func() {
+		initWriteJournal(GlobalContext, z)
+	})
+
+	bootstrapTrace("initNBDGateway", func() {
+		initNBDGateway(GlobalContext, z)
+	})
+
 	// initialize the object layer.
 	defer setObjectLayer(z)
 
@@ -387,9 +458,11 @@ func (p serverPoolsAvailableSpace) FilterMaxUsed(maxUsed int) {
 
 // getAvailablePoolIdx will return an index that can hold size bytes.
 // -1 is returned if no serverPools have available space for the size given.
-func (z *erasureServerPools) getAvailablePoolIdx(ctx context.Context, bucket, object string, size int64) int {
+func (z *erasureServerPools) getAvailablePoolIdx(ctx context.Context, bucket, object, storageClass string, size int64) int {
 	serverPools := z.getServerPoolsAvailableSpace(ctx, bucket, object, size)
 	serverPools.FilterMaxUsed(100 - (100 * diskReserveFraction))
+	serverPools.FilterByPoolAffinity(poolsMatchingBucketAffinity(bucket))
+	serverPools.FilterByPlacement(placementMatchingPools(bucket, object, storageClass))
 	total := serverPools.TotalAvailable()
 	if total == 0 {
 		return -1
@@ -421,8 +494,9 @@ func (z *erasureServerPools) getServerPoolsAvailableSpace(ctx context.Context, b
 	for index := range z.serverPools {
 		index := index
 		// Skip suspended pools or pools participating in rebalance for any new
-		// I/O.
-		if z.IsSuspended(index) || z.IsPoolRebalancing(index) {
+		// I/O. Likewise skip pools where the object would hash onto an
+		// erasure set being drained, it must not re-fill.
+		if z.IsSuspended(index) || z.IsPoolRebalancing(index) || z.isObjectSetDraining(index, object) {
 			continue
 		}
 		pool := z.serverPools[index]
@@ -531,8 +605,9 @@ func (z *erasureServerPools) getPoolInfoExistingWithOpts(ctx context.Context, bu
 	defPool := PoolObjInfo{Index: -1}
 	for _, pinfo := range poolObjInfos {
 		// skip all objects from suspended pools if asked by the
-		// caller.
-		if opts.SkipDecommissioned && z.IsSuspended(pinfo.Index) {
+		// caller, same for pools where the object hashes onto an
+		// erasure set being drained.
+		if opts.SkipDecommissioned && (z.IsSuspended(pinfo.Index) || z.isObjectSetDraining(pinfo.Index, object)) {
 			continue
 		}
 		// Skip object if it's from pools participating in a rebalance operation.
@@ -614,14 +689,14 @@ func (z *erasureServerPools) getPoolIdxExistingNoLock(ctx context.Context, bucke
 	})
 }
 
-func (z *erasureServerPools) getPoolIdxNoLock(ctx context.Context, bucket, object string, size int64) (idx int, err error) {
+func (z *erasureServerPools) getPoolIdxNoLock(ctx context.Context, bucket, object, storageClass string, size int64) (idx int, err error) {
 	idx, err = z.getPoolIdxExistingNoLock(ctx, bucket, object)
 	if err != nil && !isErrObjectNotFound(err) {
 		return idx, err
 	}
 
 	if isErrObjectNotFound(err) {
-		idx = z.getAvailablePoolIdx(ctx, bucket, object, size)
+		idx = z.getAvailablePoolIdx(ctx, bucket, object, storageClass, size)
 		if idx < 0 {
 			return -1, toObjectErr(errDiskFull)
 		}
@@ -633,7 +708,7 @@ func (z *erasureServerPools) getPoolIdxNoLock(ctx context.Context, bucket, objec
 // getPoolIdx returns the found previous object and its corresponding pool idx,
 // if none are found falls back to most available space pool, this function is
 // designed to be only used by PutObject, CopyObject (newObject creation) and NewMultipartUpload.
-func (z *erasureServerPools) getPoolIdx(ctx context.Context, bucket, object string, size int64) (idx int, err error) {
+func (z *erasureServerPools) getPoolIdx(ctx context.Context, bucket, object, storageClass string, size int64) (idx int, err error) {
 	idx, err = z.getPoolIdxExistingWithOpts(ctx, bucket, object, ObjectOptions{
 		SkipDecommissioned: true,
 		SkipRebalancing:    true,
@@ -643,7 +718,7 @@ func (z *erasureServerPools) getPoolIdx(ctx context.Context, bucket, object stri
 	}
 
 	if isErrObjectNotFound(err) {
-		idx = z.getAvailablePoolIdx(ctx, bucket, object, size)
+		idx = z.getAvailablePoolIdx(ctx, bucket, object, storageClass, size)
 		if idx < 0 {
 			return -1, toObjectErr(errDiskFull)
 		}
@@ -919,8 +994,19 @@ func (z *erasureServerPools) GetObjectNInfo(ctx context.Context, bucket, object
 
 	object = encodeDirObject(object)
 
+	if cgr, ok := globalReadCache.serve(ctx, z, bucket, object, rs, opts); ok {
+		return cgr, nil
+	}
+
 	if z.SinglePool() {
-		return z.serverPools[0].GetObjectNInfo(ctx, bucket, object, rs, h, opts)
+		gr, err = z.serverPools[0].GetObjectNInfo(ctx, bucket, object, rs, h, opts)
+		if err == nil && isPackedObject(gr.ObjInfo) {
+			return z.packedGetObjectNInfo(ctx, gr, rs, opts)
+		}
+		if err == nil {
+			gr = globalReadCache.maybeCache(gr, rs, opts)
+		}
+		return gr, err
 	}
 
 	var unlockOnDefer bool
@@ -973,6 +1059,14 @@ func (z *erasureServerPools) GetObjectNInfo(ctx context.Context, bucket, object
 		return nil, err
 	}
 
+	if isPackedObject(gr.ObjInfo) {
+		// The deferred unlock releases the stub, the container is
+		// read without holding it.
+		return z.packedGetObjectNInfo(ctx, gr, rs, opts)
+	}
+
+	gr = globalReadCache.maybeCache(gr, rs, opts)
+
 	if unlockOnDefer {
 		unlockOnDefer = gr.ObjInfo.Inlined
 	}
@@ -1059,7 +1153,8 @@ func (z *erasureServerPools) GetObjectInfo(ctx context.Context, bucket, object s
 	object = encodeDirObject(object)
 
 	if z.SinglePool() {
-		return z.serverPools[0].GetObjectInfo(ctx, bucket, object, opts)
+		objInfo, err = z.serverPools[0].GetObjectInfo(ctx, bucket, object, opts)
+		return packedObjectInfo(objInfo), err
 	}
 
 	if !opts.NoLock {
@@ -1076,7 +1171,7 @@ func (z *erasureServerPools) GetObjectInfo(ctx context.Context, bucket, object s
 	}
 
 	objInfo, _, err = z.getLatestObjectInfoWithIdx(ctx, bucket, object, opts)
-	return objInfo, err
+	return packedObjectInfo(objInfo), err
 }
 
 // PutObject - writes an object to least used erasure pool.
@@ -1087,11 +1182,22 @@ func (z *erasureServerPools) PutObject(ctx context.Context, bucket string, objec
 	}
 
 	object = encodeDirObject(object)
+
+	// Write-back journal: small PUTs are acknowledged after the local
+	// journal fsync and destaged asynchronously.
+	if oi, ok := globalWriteJournal.intercept(ctx, bucket, object, data, opts); ok {
+		return oi, nil
+	}
+
 	if z.SinglePool() {
-		return z.serverPools[0].PutObject(ctx, bucket, object, data, opts)
+		oi, err := z.serverPools[0].PutObject(ctx, bucket, object, data, opts)
+		if err == nil {
+			globalListingIndex.upsert(bucket, oi)
+		}
+		return oi, err
 	}
 
-	idx, err := z.getPoolIdx(ctx, bucket, object, data.Size())
+	idx, err := z.getPoolIdx(ctx, bucket, object, placementStorageClass(opts.UserDefined), data.Size())
 	if err != nil {
 		return ObjectInfo{}, err
 	}
@@ -1105,7 +1211,11 @@ func (z *erasureServerPools) PutObject(ctx context.Context, bucket string, objec
 		}
 	}
 
-	return z.serverPools[idx].PutObject(ctx, bucket, object, data, opts)
+	oi, err := z.serverPools[idx].PutObject(ctx, bucket, object, data, opts)
+	if err == nil {
+		globalListingIndex.upsert(bucket, oi)
+	}
+	return oi, err
 }
 
 func (z *erasureServerPools) deletePrefix(ctx context.Context, bucket string, prefix string) error {
@@ -1122,10 +1232,37 @@ func (z *erasureServerPools) DeleteObject(ctx context.Context, bucket string, ob
 		return objInfo, err
 	}
 
+	// Content dedup: refuse to remove source data versions that
+	// deduplicated references still point to, and maintain reference
+	// counts for removed versions. Enforcing here covers every caller -
+	// the handlers as well as ILM and batch expiry. The check runs
+	// before the namespace lock below, it reads the version being
+	// deleted.
+	var dedupPost func()
+	if contentDedupEnabled() && opts.VersionID != "" && !opts.DeletePrefix && !isMinioMetaBucketName(bucket) {
+		dedupPost, err = dedupCheckDelete(ctx, z, bucket, object, opts.VersionID)
+		if err != nil {
+			return objInfo, err
+		}
+		if dedupPost != nil {
+			defer func() {
+				if err == nil {
+					dedupPost()
+				}
+			}()
+		}
+	}
+
 	if !opts.DeletePrefix { // DeletePrefix handles dir object encoding differently.
 		object = encodeDirObject(object)
 	}
 
+	defer func() {
+		if err == nil && opts.VersionID == "" && !opts.DeletePrefix {
+			globalListingIndex.remove(bucket, objInfo.Name)
+		}
+	}()
+
 	// Acquire a write lock before deleting the object.
 	lk := z.NewNSLock(bucket, object)
 	lkctx, err := lk.GetLock(ctx, globalDeleteOperationTimeout)
@@ -1225,7 +1362,59 @@ func (z *erasureServerPools) deleteObjectFromAllPools(ctx context.Context, bucke
 	return objInfo, err
 }
 
+// DeleteObjects screens version deletes through the dedup reference
+// index when content dedup is enabled - source data versions that
+// deduplicated references still point to are rejected and survive the
+// bulk delete, admitted versions maintain the reference counts. Like
+// the single delete path this covers every caller, bulk handlers as
+// well as ILM and batch expiry.
 func (z *erasureServerPools) DeleteObjects(ctx context.Context, bucket string, objects []ObjectToDelete, opts ObjectOptions) ([]DeletedObject, []error) {
+	if !contentDedupEnabled() || isMinioMetaBucketName(bucket) {
+		return z.deleteObjects(ctx, bucket, objects, opts)
+	}
+
+	posts := make([]func(), len(objects))
+	rejected := make([]error, len(objects))
+	admitted := make([]ObjectToDelete, 0, len(objects))
+	admittedIdx := make([]int, 0, len(objects))
+	for i, obj := range objects {
+		if obj.VersionID != "" {
+			post, err := dedupCheckDelete(ctx, z, bucket, obj.ObjectName, obj.VersionID)
+			if err != nil {
+				rejected[i] = err
+				continue
+			}
+			posts[i] = post
+		}
+		admitted = append(admitted, obj)
+		admittedIdx = append(admittedIdx, i)
+	}
+
+	dobjects := make([]DeletedObject, len(objects))
+	for i, err := range rejected {
+		if err != nil {
+			dobjects[i] = DeletedObject{
+				ObjectName: objects[i].ObjectName,
+				VersionID:  objects[i].VersionID,
+			}
+		}
+	}
+	if len(admitted) == 0 {
+		return dobjects, rejected
+	}
+
+	dobjs, errs := z.deleteObjects(ctx, bucket, admitted, opts)
+	for k, i := range admittedIdx {
+		dobjects[i] = dobjs[k]
+		rejected[i] = errs[k]
+		if errs[k] == nil && posts[i] != nil {
+			posts[i]()
+		}
+	}
+	return dobjects, rejected
+}
+
+func (z *erasureServerPools) deleteObjects(ctx context.Context, bucket string, objects []ObjectToDelete, opts ObjectOptions) ([]DeletedObject, []error) {
 	derrs := make([]error, len(objects))
 	dobjects := make([]DeletedObject, len(objects))
 	objSets := set.NewStringSet()
@@ -1248,6 +1437,31 @@ func (z *erasureServerPools) DeleteObjects(ctx context.Context, bucket string, o
 	ctx = lkctx.Context()
 	defer multiDeleteLock.Unlock(lkctx)
 
+	// Fast path: with a single pool the per-object location lookup
+	// below is pure overhead, hand the whole batch to the pool which
+	// groups the keys by erasure set and removes each group with one
+	// bulk DeleteVersions call per disk. Versioned buckets keep the
+	// slow path, it avoids stacking delete markers on top of existing
+	// ones.
+	if z.SinglePool() && !opts.Versioned && !opts.VersionSuspended {
+		dobjs, errs := z.serverPools[0].DeleteObjects(ctx, bucket, objects, opts)
+		for i := range dobjs {
+			if derrs[i] == nil {
+				derrs[i] = errs[i]
+			}
+			dobjs[i].ObjectName = decodeDirObject(dobjs[i].ObjectName)
+			dobjects[i] = dobjs[i]
+		}
+		if globalListingIndex != nil {
+			for i := range dobjects {
+				if derrs[i] == nil && objects[i].VersionID == "" {
+					globalListingIndex.remove(bucket, dobjects[i].ObjectName)
+				}
+			}
+		}
+		return dobjects, derrs
+	}
+
 	// Fetch location of up to 10 objects concurrently.
 	poolObjIdxMap := map[int][]ObjectToDelete{}
 	origIndexMap := map[int][]int{}
@@ -1331,6 +1545,14 @@ func (z *erasureServerPools) DeleteObjects(ctx context.Context, bucket string, o
 		wg.Wait()
 	}
 
+	if globalListingIndex != nil {
+		for i := range dobjects {
+			if derrs[i] == nil && objects[i].VersionID == "" {
+				globalListingIndex.remove(bucket, dobjects[i].ObjectName)
+			}
+		}
+	}
+
 	return dobjects, derrs
 }
 
@@ -1345,6 +1567,12 @@ func (z *erasureServerPools) CopyObject(ctx context.Context, srcBucket, srcObjec
 	srcObject = encodeDirObject(srcObject)
 	dstObject = encodeDirObject(dstObject)
 
+	defer func() {
+		if err == nil {
+			globalListingIndex.upsert(dstBucket, objInfo)
+		}
+	}()
+
 	cpSrcDstSame := isStringEqual(pathJoin(srcBucket, srcObject), pathJoin(dstBucket, dstObject))
 
 	if !dstOpts.NoLock {
@@ -1358,7 +1586,7 @@ func (z *erasureServerPools) CopyObject(ctx context.Context, srcBucket, srcObjec
 		dstOpts.NoLock = true
 	}
 
-	poolIdx, err := z.getPoolIdxNoLock(ctx, dstBucket, dstObject, srcInfo.Size)
+	poolIdx, err := z.getPoolIdxNoLock(ctx, dstBucket, dstObject, srcInfo.StorageClass, srcInfo.Size)
 	if err != nil {
 		return objInfo, err
 	}
@@ -1435,7 +1663,7 @@ func (z *erasureServerPools) ListObjectVersions(ctx context.Context, bucket, pre
 		Limit:       maxKeysPlusOne(maxKeys, marker != ""),
 		Marker:      marker,
 		InclDeleted: true,
-		AskDisks:    globalAPIConfig.getListQuorum(),
+		AskDisks:    listQuorumFor(ctx, bucket),
 		Versioned:   true,
 	}
 
@@ -1516,6 +1744,9 @@ func maxKeysPlusOne(maxKeys int, addOne bool) int {
 }
 
 func (z *erasureServerPools) listObjectsGeneric(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int, v1 bool) (loi ListObjectsInfo, err error) {
+	if loi, ok := globalListingIndex.serve(bucket, prefix, marker, delimiter, maxKeys); ok {
+		return loi, nil
+	}
 	opts := listPathOptions{
 		V1:          v1,
 		Bucket:      bucket,
@@ -1524,7 +1755,7 @@ func (z *erasureServerPools) listObjectsGeneric(ctx context.Context, bucket, pre
 		Limit:       maxKeysPlusOne(maxKeys, marker != ""),
 		Marker:      marker,
 		InclDeleted: false,
-		AskDisks:    globalAPIConfig.getListQuorum(),
+		AskDisks:    listQuorumFor(ctx, bucket),
 	}
 	opts.setBucketMeta(ctx)
 	listFn := func(ctx context.Context, opts listPathOptions, limitTo int) (ListObjectsInfo, error) {
@@ -1825,7 +2056,7 @@ func (z *erasureServerPools) NewMultipartUpload(ctx context.Context, bucket, obj
 
 	// any parallel writes on the object will block for this poolIdx
 	// to return since this holds a read lock on the namespace.
-	idx, err := z.getPoolIdx(ctx, bucket, object, -1)
+	idx, err := z.getPoolIdx(ctx, bucket, object, placementStorageClass(opts.UserDefined), -1)
 	if err != nil {
 		return nil, err
 	}
@@ -2020,6 +2251,7 @@ func (z *erasureServerPools) CompleteMultipartUpload(ctx context.Context, bucket
 		if err == nil {
 			z.mpCache.Delete(uploadID)
 			globalNotificationSys.DeleteUploadID(ctx, uploadID)
+			globalListingIndex.upsert(bucket, objInfo)
 		}
 	}()
 
@@ -3016,7 +3248,7 @@ func (z *erasureServerPools) DecomTieredObject(ctx context.Context, bucket, obje
 		defer ns.Unlock(lkctx)
 		opts.NoLock = true
 	}
-	idx, err := z.getPoolIdxNoLock(ctx, bucket, object, fi.Size)
+	idx, err := z.getPoolIdxNoLock(ctx, bucket, object, placementStorageClass(fi.Metadata), fi.Size)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,334 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// setDecomStatus tracks an ongoing drain of a single erasure set. The
+// drained objects are moved to other pools via the regular data
+// movement path, objects cannot be re-homed onto sibling sets of the
+// same pool because placement within a pool is determined by the
+// object name hash. While the drain runs, new-object placement skips
+// pools where the object would hash onto the draining set, keeping the
+// set from re-filling while it empties.
+type setDecomStatus struct {
+	Pool       int       `json:"pool"`
+	Set        int       `json:"set"`
+	StartTime  time.Time `json:"startTime"`
+	Objects    uint64    `json:"objects"`
+	Versions   uint64    `json:"versions"`
+	Bytes      uint64    `json:"bytes"`
+	Failed     uint64    `json:"failed"`
+	Complete   bool      `json:"complete"`
+	Error      string    `json:"error,omitempty"`
+	LastBucket string    `json:"lastBucket,omitempty"`
+	LastObject string    `json:"lastObject,omitempty"`
+	cancel     context.CancelFunc
+}
+
+// setDecomState tracks all per-set drains started on this node.
+type setDecomState struct {
+	mu     sync.Mutex
+	drains map[string]*setDecomStatus
+}
+
+var globalSetDecomState = &setDecomState{
+	drains: make(map[string]*setDecomStatus),
+}
+
+func setDecomKey(poolIdx, setIdx int) string {
+	return fmt.Sprintf("%d/%d", poolIdx, setIdx)
+}
+
+// isDraining reports whether an erasure set currently has a drain in
+// progress.
+func (s *setDecomState) isDraining(poolIdx, setIdx int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.drains[setDecomKey(poolIdx, setIdx)]
+	return ok && !st.Complete
+}
+
+// isObjectSetDraining reports whether the erasure set the object hashes
+// onto in the given pool is being drained. New objects must not be
+// placed onto a draining set, it would re-fill while it empties.
+func (z *erasureServerPools) isObjectSetDraining(poolIdx int, object string) bool {
+	return globalSetDecomState.isDraining(poolIdx, z.serverPools[poolIdx].getHashedSetIndex(object))
+}
+
+var (
+	errSetDecomAlreadyRunning = errors.New("decommission already in progress for this erasure set")
+	errSetDecomSinglePool     = errors.New("decommissioning an erasure set requires at least one other pool to move its objects to")
+)
+
+// startSetDecommission begins draining all objects off one erasure set
+// in the background.
+func (z *erasureServerPools) startSetDecommission(poolIdx, setIdx int) error {
+	if poolIdx < 0 || poolIdx >= len(z.serverPools) {
+		return errInvalidArgument
+	}
+	pool := z.serverPools[poolIdx]
+	if setIdx < 0 || setIdx >= pool.setCount {
+		return errInvalidArgument
+	}
+	if len(z.serverPools) <= 1 {
+		return errSetDecomSinglePool
+	}
+
+	globalSetDecomState.mu.Lock()
+	defer globalSetDecomState.mu.Unlock()
+
+	key := setDecomKey(poolIdx, setIdx)
+	if st, ok := globalSetDecomState.drains[key]; ok && !st.Complete {
+		return errSetDecomAlreadyRunning
+	}
+
+	ctx, cancel := context.WithCancel(GlobalContext)
+	status := &setDecomStatus{
+		Pool:      poolIdx,
+		Set:       setIdx,
+		StartTime: time.Now().UTC(),
+		cancel:    cancel,
+	}
+	globalSetDecomState.drains[key] = status
+
+	go func() {
+		err := z.decommissionSet(ctx, poolIdx, setIdx, status)
+		globalSetDecomState.mu.Lock()
+		status.Complete = true
+		if err != nil {
+			status.Error = err.Error()
+		}
+		globalSetDecomState.mu.Unlock()
+		cancel()
+	}()
+	return nil
+}
+
+// decommissionSet drains one erasure set bucket by bucket, moving every
+// object version to another pool and deleting fully moved objects from
+// the source set.
+func (z *erasureServerPools) decommissionSet(ctx context.Context, poolIdx, setIdx int, status *setDecomStatus) error {
+	set := z.serverPools[poolIdx].sets[setIdx]
+
+	buckets, err := z.ListBuckets(ctx, BucketOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		if isMinioMetaBucketName(bucket.Name) {
+			continue
+		}
+		err := set.listObjectsToDecommission(ctx, decomBucketInfo{Name: bucket.Name},
+			func(entry metaCacheEntry) {
+				z.decommissionSetEntry(ctx, poolIdx, set, bucket.Name, entry, status)
+			})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// decommissionSetEntry moves all versions of one object off the set,
+// mirroring the rebalance data movement semantics.
+func (z *erasureServerPools) decommissionSetEntry(ctx context.Context, poolIdx int, set *erasureObjects, bucket string, entry metaCacheEntry, status *setDecomStatus) {
+	if entry.isDir() {
+		return
+	}
+
+	fivs, err := entry.fileInfoVersions(bucket)
+	if err != nil {
+		return
+	}
+
+	// Reversed order recreates the version stack on the target pool.
+	versionsSorter(fivs.Versions).reverse()
+
+	var moved int
+	for _, version := range fivs.Versions {
+		if ctx.Err() != nil {
+			return
+		}
+
+		versionID := version.VersionID
+		if versionID == "" {
+			versionID = nullVersionID
+		}
+
+		if version.Deleted {
+			_, err := z.DeleteObject(ctx, bucket, version.Name, ObjectOptions{
+				Versioned:         true,
+				VersionID:         versionID,
+				MTime:             version.ModTime,
+				DeleteReplication: version.ReplicationState,
+				SrcPoolIdx:        poolIdx,
+				DataMovement:      true,
+				DeleteMarker:      true,
+				SkipRebalancing:   true,
+				NoAuditLog:        true,
+			})
+			if err != nil && !isErrObjectNotFound(err) && !isErrVersionNotFound(err) && !isDataMovementOverWriteErr(err) {
+				decomLogIf(ctx, err)
+				globalSetDecomState.mu.Lock()
+				status.Failed++
+				globalSetDecomState.mu.Unlock()
+				return
+			}
+			moved++
+			continue
+		}
+
+		gr, err := set.GetObjectNInfo(ctx, bucket, encodeDirObject(version.Name), nil, http.Header{}, ObjectOptions{
+			VersionID:    versionID,
+			NoDecryption: true,
+			NoLock:       true,
+			NoAuditLog:   true,
+		})
+		if isErrObjectNotFound(err) || isErrVersionNotFound(err) {
+			moved++
+			continue
+		}
+		if err != nil {
+			decomLogIf(ctx, err)
+			globalSetDecomState.mu.Lock()
+			status.Failed++
+			globalSetDecomState.mu.Unlock()
+			return
+		}
+
+		// rebalanceObject moves to another pool via the DataMovement
+		// option, closing gr.
+		if err = z.rebalanceObject(ctx, poolIdx, bucket, gr); err != nil {
+			if !isErrObjectNotFound(err) && !isErrVersionNotFound(err) && !isDataMovementOverWriteErr(err) {
+				decomLogIf(ctx, err)
+				globalSetDecomState.mu.Lock()
+				status.Failed++
+				globalSetDecomState.mu.Unlock()
+				return
+			}
+		}
+
+		globalSetDecomState.mu.Lock()
+		if version.IsLatest {
+			status.Objects++
+		}
+		status.Versions++
+		status.Bytes += uint64(version.Size)
+		status.LastBucket = bucket
+		status.LastObject = version.Name
+		globalSetDecomState.mu.Unlock()
+		moved++
+	}
+
+	// Delete exactly the versions that were moved from the source set.
+	// The set stays writable while it drains - a node that has not yet
+	// observed the drain may still write onto it - so a prefix delete
+	// here could take out a version a client wrote after the listing,
+	// exact version deletes cannot.
+	if moved == len(fivs.Versions) {
+		toDel := make([]ObjectToDelete, 0, len(fivs.Versions))
+		for _, version := range fivs.Versions {
+			toDel = append(toDel, ObjectToDelete{
+				ObjectV: ObjectV{
+					ObjectName: encodeDirObject(version.Name),
+					VersionID:  version.VersionID,
+				},
+			})
+		}
+		_, delErrs := set.DeleteObjects(ctx, bucket, toDel, ObjectOptions{
+			NoAuditLog: true,
+		})
+		for _, err := range delErrs {
+			if err != nil {
+				decomLogIf(ctx, err)
+			}
+		}
+	}
+}
+
+// StartDecommissionSetHandler - drains a single erasure set of a pool,
+// moving its objects to other pools.
+func (a adminAPIHandlers) StartDecommissionSetHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.DecommissionAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	poolIdx, perr := strconv.Atoi(vars["pool"])
+	setIdx, serr := strconv.Atoi(vars["set"])
+	if perr != nil || serr != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+
+	if err := z.startSetDecommission(poolIdx, setIdx); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// DecommissionSetStatusHandler - returns the status of all per-set
+// decommissions started on this node.
+func (a adminAPIHandlers) DecommissionSetStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction, policy.DecommissionAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	globalSetDecomState.mu.Lock()
+	statuses := make([]setDecomStatus, 0, len(globalSetDecomState.drains))
+	for _, st := range globalSetDecomState.drains {
+		statuses = append(statuses, *st)
+	}
+	globalSetDecomState.mu.Unlock()
+
+	statusData, err := json.Marshal(statuses)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, statusData)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+
+	"github.com/klauspost/cpuid/v2"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// erasureAccelPath returns the SIMD path the reedsolomon GF arithmetic
+// uses on this CPU. Detection mirrors what the encoder probes at
+// runtime - on amd64 the GFNI/AVX512/AVX2 ladder, on arm64 the NEON
+// kernels. The vendored reedsolomon release carries no SVE and no
+// riscv64 kernels, so nothing beyond that is reported even when the
+// CPU advertises it. Everything else falls back to the generic tables.
+func erasureAccelPath() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		switch {
+		case cpuid.CPU.Supports(cpuid.AVX512F, cpuid.GFNI):
+			return "AVX512+GFNI"
+		case cpuid.CPU.Supports(cpuid.AVX512F):
+			return "AVX512"
+		case cpuid.CPU.Supports(cpuid.AVX2, cpuid.GFNI):
+			return "AVX2+GFNI"
+		case cpuid.CPU.Supports(cpuid.AVX2):
+			return "AVX2"
+		case cpuid.CPU.Supports(cpuid.SSSE3):
+			return "SSSE3"
+		}
+	case "arm64":
+		return "NEON"
+	}
+	return "generic"
+}
+
+// logErasureAccel logs the active acceleration path once at startup,
+// right after the erasure self-test proved the kernels correct.
+func logErasureAccel() {
+	logger.Info("Erasure coding: %s/%s using %s galois field acceleration", runtime.GOOS, runtime.GOARCH, erasureAccelPath())
+}
+
+// ErasureAccelHandler - GET /minio/admin/v3/erasure-accel
+//
+// Reports which galois field acceleration path the erasure coder uses
+// on this node, node-local like the self-tests it complements.
+func (a adminAPIHandlers) ErasureAccelHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	resp := struct {
+		Node  string `json:"node"`
+		OS    string `json:"os"`
+		Arch  string `json:"arch"`
+		Accel string `json:"accel"`
+	}{
+		Node:  globalLocalNodeName,
+		OS:    runtime.GOOS,
+		Arch:  runtime.GOARCH,
+		Accel: erasureAccelPath(),
+	}
+
+	buf, err := json.Marshal(resp)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, buf)
+}
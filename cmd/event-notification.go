@@ -151,6 +151,16 @@ func (evnot *EventNotifier) Send(args eventArgs) {
 	evnot.targetList.Send(args.ToEvent(true), targetIDSet, globalAPIConfig.isSyncEventsEnabled())
 }
 
+// SendToTargets sends an event directly to the given targets bypassing
+// the per-bucket notification rules. Used for system (infrastructure)
+// events which are not tied to a particular bucket.
+func (evnot *EventNotifier) SendToTargets(args eventArgs, targetIDSet event.TargetIDSet) {
+	if len(targetIDSet) == 0 {
+		return
+	}
+	evnot.targetList.Send(args.ToEvent(true), targetIDSet, false)
+}
+
 type eventArgs struct {
 	EventName    event.Name
 	BucketName   string
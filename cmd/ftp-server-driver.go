@@ -28,6 +28,7 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -88,6 +89,21 @@ func (m *minioFileInfo) Mode() os.FileMode {
 var minFileDate = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC) // Workaround for Filezilla
 
 func (m *minioFileInfo) ModTime() time.Time {
+	// Prefer the source modification time preserved by transfer tools
+	// (mc, rclone) in user metadata, FTP clients compare the MLSD
+	// modify fact against the local file when deciding on resume.
+	v := m.info.UserMetadata["X-Amz-Meta-Mtime"]
+	if v == "" {
+		v = m.info.UserMetadata["Mtime"]
+	}
+	if v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil && secs > 0 {
+			return time.Unix(0, int64(secs*1e9)).UTC()
+		}
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+	}
 	if !m.info.LastModified.IsZero() {
 		return m.info.LastModified
 	}
@@ -234,8 +250,9 @@ func (driver *ftpDriver) ListDir(ctx *ftp.Context, objPath string, callback func
 	prefix = retainSlash(prefix)
 
 	for object := range clnt.ListObjects(cctx, bucket, minio.ListObjectsOptions{
-		Prefix:    prefix,
-		Recursive: false,
+		Prefix:       prefix,
+		Recursive:    false,
+		WithMetadata: true,
 	}) {
 		if object.Err != nil {
 			return object.Err
@@ -245,6 +262,12 @@ func (driver *ftpDriver) ListDir(ctx *ftp.Context, objPath string, callback func
 			continue
 		}
 
+		// On versioned buckets delete markers hide the object, do not
+		// surface them as zero-sized entries.
+		if object.IsDeleteMarker {
+			continue
+		}
+
 		isDir := strings.HasSuffix(object.Key, SlashSeparator)
 		info := minioFileInfo{
 			p:     pathClean(strings.TrimPrefix(object.Key, prefix)),
@@ -529,6 +552,10 @@ func (driver *ftpDriver) GetFile(ctx *ftp.Context, objPath string, offset int64)
 	return n, obj, nil
 }
 
+// Server side compose requires every source except the last to be at
+// least 5MiB, smaller objects are resumed by rewriting them in full.
+const ftpResumeMinComposeSize = 5 << 20
+
 // PutFile implements ftpDriver
 func (driver *ftpDriver) PutFile(ctx *ftp.Context, objPath string, data io.Reader, offset int64) (n int64, err error) {
 	stopFn := globalFtpMetrics.log(ctx, objPath)
@@ -539,16 +566,17 @@ func (driver *ftpDriver) PutFile(ctx *ftp.Context, objPath string, data io.Reade
 		return 0, errors.New("bucket name cannot be empty")
 	}
 
-	if offset != -1 {
-		// FTP - APPEND not implemented
-		return 0, NotImplemented{}
-	}
-
 	clnt, err := driver.getMinIOClient(ctx)
 	if err != nil {
 		return 0, err
 	}
 
+	if offset > 0 {
+		// REST/APPE - the client resumes an interrupted upload with
+		// the remaining bytes.
+		return driver.resumePutFile(clnt, bucket, object, data, offset)
+	}
+
 	info, err := clnt.PutObject(context.Background(), bucket, object, data, -1, minio.PutObjectOptions{
 		ContentType:          mimedb.TypeByExtension(path.Ext(object)),
 		DisableContentSha256: true,
@@ -556,3 +584,60 @@ func (driver *ftpDriver) PutFile(ctx *ftp.Context, objPath string, data io.Reade
 	n = info.Size
 	return n, err
 }
+
+// resumePutFile appends data at offset to an existing object. The bytes
+// already uploaded are reused with a server side compose when large
+// enough, so only the remainder travels over the wire again.
+func (driver *ftpDriver) resumePutFile(clnt *minio.Client, bucket, object string, data io.Reader, offset int64) (n int64, err error) {
+	info, err := clnt.StatObject(context.Background(), bucket, object, minio.StatObjectOptions{})
+	if err != nil {
+		return 0, err
+	}
+	if info.Size != offset {
+		return 0, fmt.Errorf("resume offset %d does not match current object size %d", offset, info.Size)
+	}
+
+	if info.Size < ftpResumeMinComposeSize {
+		// Too small for server side compose, prepend the existing
+		// content and rewrite the object in full.
+		obj, err := clnt.GetObject(context.Background(), bucket, object, minio.GetObjectOptions{})
+		if err != nil {
+			return 0, err
+		}
+		defer obj.Close()
+
+		uinfo, err := clnt.PutObject(context.Background(), bucket, object, io.MultiReader(obj, data), -1, minio.PutObjectOptions{
+			ContentType:          mimedb.TypeByExtension(path.Ext(object)),
+			DisableContentSha256: true,
+		})
+		if err != nil {
+			return 0, err
+		}
+		return uinfo.Size - offset, nil
+	}
+
+	// Upload the remainder as a temporary object and stitch both parts
+	// together server side.
+	tmpObject := object + ".ftp-resume." + mustGetUUID()
+	tinfo, err := clnt.PutObject(context.Background(), bucket, tmpObject, data, -1, minio.PutObjectOptions{
+		DisableContentSha256: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer clnt.RemoveObject(context.Background(), bucket, tmpObject, minio.RemoveObjectOptions{})
+
+	if _, err = clnt.ComposeObject(context.Background(), minio.CopyDestOptions{
+		Bucket: bucket,
+		Object: object,
+	}, minio.CopySrcOptions{
+		Bucket: bucket,
+		Object: object,
+	}, minio.CopySrcOptions{
+		Bucket: bucket,
+		Object: tmpObject,
+	}); err != nil {
+		return 0, err
+	}
+	return tinfo.Size, nil
+}
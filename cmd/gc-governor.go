@@ -0,0 +1,178 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Adaptive GC governor. Static GOGC tuning forces one trade-off on
+// every deployment: a high value wastes memory on small nodes, a low
+// one burns CPU on garbage collection under heavy PUT traffic. The
+// governor observes the live heap, the allocation rate and the
+// remaining memory headroom every interval and steps GOGC between a
+// floor and a ceiling - widening the heap while memory is plentiful,
+// tightening it as headroom shrinks - and keeps the runtime's soft
+// memory limit aligned with what the node can actually spare. Every
+// decision is visible under the /system/gc metrics.
+
+const (
+	// EnvGCGovernor enables the governor, off by default.
+	EnvGCGovernor = "MINIO_GC_GOVERNOR"
+
+	// gcGovernorInterval is how often the governor re-evaluates.
+	gcGovernorInterval = 30 * time.Second
+
+	// GOGC bounds and step. The governor never leaves this range and
+	// moves gradually so consecutive observations, not spikes, drive
+	// the tuning.
+	gcGovernorMinGOGC  = 50
+	gcGovernorMaxGOGC  = 300
+	gcGovernorGOGCStep = 25
+
+	// Headroom thresholds, as a fraction of the memory budget still
+	// unused. Above the high mark GOGC widens, below the low mark it
+	// tightens.
+	gcGovernorHighHeadroom = 0.5
+	gcGovernorLowHeadroom  = 0.2
+)
+
+// gcGovernor holds the governor state, exposed to metrics.
+type gcGovernor struct {
+	mu sync.Mutex
+
+	gogc        int
+	memLimit    int64
+	allocRate   float64 // bytes per second
+	headroom    float64
+	adjustments uint64
+
+	lastTotalAlloc uint64
+	lastSample     time.Time
+}
+
+// globalGCGovernor is nil unless MINIO_GC_GOVERNOR is enabled.
+var globalGCGovernor *gcGovernor
+
+// snapshot returns the current state for metrics.
+func (g *gcGovernor) snapshot() (gogc int, memLimit int64, allocRate, headroom float64, adjustments uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.gogc, g.memLimit, g.allocRate, g.headroom, g.adjustments
+}
+
+// tune performs one governor step.
+func (g *gcGovernor) tune() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.lastSample.IsZero() {
+		if secs := now.Sub(g.lastSample).Seconds(); secs > 0 {
+			g.allocRate = float64(ms.TotalAlloc-g.lastTotalAlloc) / secs
+		}
+	}
+	g.lastTotalAlloc = ms.TotalAlloc
+	g.lastSample = now
+
+	// The memory budget is the configured limit when one was given,
+	// otherwise what the node currently has to spare plus what we
+	// already occupy.
+	budget := globalServerCtxt.MemLimit
+	if budget == 0 {
+		budget = availableMemory() + ms.Sys - ms.HeapReleased
+	}
+	used := ms.Sys - ms.HeapReleased
+	g.headroom = 0
+	if budget > used {
+		g.headroom = float64(budget-used) / float64(budget)
+	}
+
+	// Keep the runtime's soft limit at 90% of the budget so the
+	// collector backstops us before the kernel does.
+	if limit := int64(budget / 10 * 9); limit > 0 && limit != g.memLimit {
+		debug.SetMemoryLimit(limit)
+		g.memLimit = limit
+		g.adjustments++
+	}
+
+	target := g.gogc
+	switch {
+	case g.headroom > gcGovernorHighHeadroom && g.allocRate > 0:
+		target += gcGovernorGOGCStep
+	case g.headroom < gcGovernorLowHeadroom:
+		target -= gcGovernorGOGCStep
+	default:
+		// Drift back toward the default when neither pressure applies.
+		if g.gogc > 100 {
+			target -= gcGovernorGOGCStep
+		} else if g.gogc < 100 {
+			target += gcGovernorGOGCStep
+		}
+	}
+	if target > gcGovernorMaxGOGC {
+		target = gcGovernorMaxGOGC
+	}
+	if target < gcGovernorMinGOGC {
+		target = gcGovernorMinGOGC
+	}
+	if target != g.gogc {
+		debug.SetGCPercent(target)
+		g.gogc = target
+		g.adjustments++
+	}
+}
+
+// initGCGovernor starts the governor loop when enabled.
+func initGCGovernor(ctx context.Context) {
+	if env.Get(EnvGCGovernor, config.EnableOff) != config.EnableOn {
+		return
+	}
+
+	// Start from the runtime's current settings. SetGCPercent has no
+	// read-only form, so restore the value it returns.
+	g := &gcGovernor{
+		memLimit: debug.SetMemoryLimit(-1),
+	}
+	g.gogc = debug.SetGCPercent(-1)
+	debug.SetGCPercent(g.gogc)
+	globalGCGovernor = g
+
+	go func() {
+		t := time.NewTicker(gcGovernorInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				g.tune()
+			}
+		}
+	}()
+}
@@ -24,6 +24,7 @@ import (
 	"math/rand"
 	"runtime"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -34,6 +35,7 @@ import (
 	"github.com/minio/minio/internal/bucket/versioning"
 	"github.com/minio/minio/internal/color"
 	"github.com/minio/minio/internal/config/storageclass"
+	"github.com/minio/minio/internal/event"
 	"github.com/minio/minio/internal/logger"
 	"github.com/minio/pkg/v3/console"
 	"github.com/minio/pkg/v3/wildcard"
@@ -149,12 +151,29 @@ type healEntryResult struct {
 }
 
 // healErasureSet lists and heals all objects in a specific erasure set
-func (er *erasureObjects) healErasureSet(ctx context.Context, buckets []string, tracker *healingTracker) error {
+func (er *erasureObjects) healErasureSet(ctx context.Context, buckets []string, tracker *healingTracker) (err error) {
 	bgSeq, found := globalBackgroundHealState.getHealSequenceByToken(bgHealingUUID)
 	if !found {
 		return errors.New("no local healing sequence initialized, unable to heal the drive")
 	}
 
+	sendSystemEvent(event.HealSetStarted, map[string]string{
+		"pool":  strconv.Itoa(tracker.PoolIndex),
+		"set":   strconv.Itoa(tracker.SetIndex),
+		"drive": tracker.Endpoint,
+	})
+	defer func() {
+		params := map[string]string{
+			"pool":  strconv.Itoa(tracker.PoolIndex),
+			"set":   strconv.Itoa(tracker.SetIndex),
+			"drive": tracker.Endpoint,
+		}
+		if err != nil {
+			params["error"] = err.Error()
+		}
+		sendSystemEvent(event.HealSetCompleted, params)
+	}()
+
 	scanMode := madmin.HealNormalScan
 
 	// Make sure to copy since `buckets slice`
@@ -162,6 +181,11 @@ func (er *erasureObjects) healErasureSet(ctx context.Context, buckets []string,
 	healBuckets := make([]string, len(buckets))
 	copy(healBuckets, buckets)
 
+	// Heal buckets with configured priority before all others.
+	sort.SliceStable(healBuckets, func(i, j int) bool {
+		return globalHealConfig.IsPriorityBucket(healBuckets[i]) && !globalHealConfig.IsPriorityBucket(healBuckets[j])
+	})
+
 	objAPI := newObjectLayerFn()
 	if objAPI == nil {
 		return errServerNotInitialized
@@ -353,6 +377,19 @@ func (er *erasureObjects) healErasureSet(ctx context.Context, buckets []string,
 		})
 
 		expectedDisks := len(disks)/2 + 1
+		if globalHealConfig.FastRebuildEnabled() {
+			// Fast rebuild mode: list from the smallest set of drives
+			// that still guarantees completeness. Fallback drives are
+			// consulted only when a listed drive errors, so a smaller
+			// set may not miss objects: every object was written to at
+			// least setDriveCount/2+1 drives, meaning at most
+			// setDriveCount/2-1 drives lack it - any setDriveCount/2
+			// drives therefore contain every object between them.
+			// Healing drives were already dropped from the candidate
+			// list and fewer than setDriveCount/2 remaining drives
+			// aborted above.
+			expectedDisks = er.setDriveCount / 2
+		}
 		fallbackDisks := disks[expectedDisks:]
 		disks = disks[:expectedDisks]
 
@@ -507,6 +544,10 @@ func (er *erasureObjects) healErasureSet(ctx context.Context, buckets []string,
 
 			// Wait and proceed if there are active requests
 			waitForLowHTTPReq()
+
+			// Hold off further healing until the configured
+			// healing window opens.
+			waitForHealWindow(ctx)
 		}
 
 		// How to resolve partial results.
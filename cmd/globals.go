@@ -156,8 +156,9 @@ type serverCtxt struct {
 
 	RootUser, RootPwd string
 
-	FTP  []string
-	SFTP []string
+	FTP    []string
+	SFTP   []string
+	WebDAV []string
 
 	MemLimit uint64
 
@@ -322,8 +323,12 @@ var (
 
 	globalBucketObjectLockSys *BucketObjectLockSys
 	globalBucketQuotaSys      *BucketQuotaSys
+	globalBucketRegionSys     *BucketRegionSys
 	globalBucketVersioningSys *BucketVersioningSys
 
+	globalBucketEncryptionEnforceSys *BucketEncryptionEnforceSys
+	globalBucketListConsistencySys   *BucketListConsistencySys
+
 	// Allocated etcd endpoint for config and bucket DNS.
 	globalEtcdClient *etcd.Client
 
@@ -415,6 +420,9 @@ var (
 
 	globalDriveMonitoring = env.Get("_MINIO_DRIVE_ACTIVE_MONITORING", config.EnableOn) == config.EnableOn
 
+	// Background deep scrubber for local drives.
+	globalDriveScrubber *driveScrubber
+
 	// Is MINIO_CI_CD set?
 	globalIsCICD bool
 
@@ -42,7 +42,7 @@ var globalLockGridStart = make(chan struct{})
 
 func initGlobalGrid(ctx context.Context, eps EndpointServerPools) error {
 	hosts, local := eps.GridHosts()
-	lookupHost := globalDNSCache.LookupHost
+	lookupHost := lookupHostByFamily
 	g, err := grid.NewManager(ctx, grid.ManagerOptions{
 		// Pass Dialer for websocket grid, make sure we do not
 		// provide any DriveOPTimeout() function, as that is not
@@ -75,7 +75,7 @@ func initGlobalGrid(ctx context.Context, eps EndpointServerPools) error {
 
 func initGlobalLockGrid(ctx context.Context, eps EndpointServerPools) error {
 	hosts, local := eps.GridHosts()
-	lookupHost := globalDNSCache.LookupHost
+	lookupHost := lookupHostByFamily
 	g, err := grid.NewManager(ctx, grid.ManagerOptions{
 		// Pass Dialer for websocket grid, make sure we do not
 		// provide any DriveOPTimeout() function, as that is not
@@ -0,0 +1,376 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/minio/minio/internal/auth"
+	"github.com/minio/minio/internal/config"
+	xtls "github.com/minio/minio/internal/config/identity/tls"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/policy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Experimental gRPC data plane for in-cluster sidecar clients, served
+// on the S3 port itself - gRPC requests are recognized by their
+// content type and handed to the gRPC server, everything else takes
+// the regular S3 path. Clients authenticate with mTLS, the client
+// certificate common name is the IAM access key, so no SigV4 signing
+// is involved per request. Messages are JSON encoded, the method
+// surface is intentionally small: unary List, server streamed Get and
+// client streamed Put.
+const (
+	// EnvGRPCDataPlane enables the gRPC data plane.
+	EnvGRPCDataPlane = "MINIO_GRPC_DATAPLANE"
+
+	// grpcDataChunkSize is the Get response chunk size.
+	grpcDataChunkSize = 1 << 20
+)
+
+// globalGRPCDataPlane is nil unless MINIO_GRPC_DATAPLANE=on.
+var globalGRPCDataPlane *grpc.Server
+
+type grpcGetRequest struct {
+	Bucket    string `json:"bucket"`
+	Object    string `json:"object"`
+	VersionID string `json:"versionId,omitempty"`
+}
+
+type grpcPutHeader struct {
+	Bucket      string            `json:"bucket"`
+	Object      string            `json:"object"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	ContentType string            `json:"contentType,omitempty"`
+}
+
+// grpcDataChunk carries object data on Get and Put streams. The first
+// Put chunk holds the header, the last one sets EOF.
+type grpcDataChunk struct {
+	Header *grpcPutHeader `json:"header,omitempty"`
+	Data   []byte         `json:"data,omitempty"`
+	EOF    bool           `json:"eof,omitempty"`
+}
+
+type grpcPutResponse struct {
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+type grpcListRequest struct {
+	Bucket    string `json:"bucket"`
+	Prefix    string `json:"prefix,omitempty"`
+	Marker    string `json:"marker,omitempty"`
+	Delimiter string `json:"delimiter,omitempty"`
+	MaxKeys   int    `json:"maxKeys,omitempty"`
+}
+
+type grpcObjectEntry struct {
+	Name    string `json:"name"`
+	Size    int64  `json:"size"`
+	ETag    string `json:"etag,omitempty"`
+	ModTime int64  `json:"modTime"`
+}
+
+type grpcListResponse struct {
+	Objects     []grpcObjectEntry `json:"objects,omitempty"`
+	Prefixes    []string          `json:"prefixes,omitempty"`
+	IsTruncated bool              `json:"isTruncated,omitempty"`
+	NextMarker  string            `json:"nextMarker,omitempty"`
+}
+
+// grpcJSONCodec encodes data plane messages as JSON, avoiding a
+// protobuf toolchain dependency for this small surface.
+type grpcJSONCodec struct{}
+
+func (grpcJSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (grpcJSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (grpcJSONCodec) Name() string                               { return "json" }
+
+// grpcDataPlaneCred maps the connection's client certificate to IAM
+// credentials, the certificate common name is the access key. Only
+// certificates the handshake verified against the configured client CA
+// count - a presented but unverified certificate proves nothing.
+func grpcDataPlaneCred(ctx context.Context) (auth.Credentials, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return auth.Credentials{}, status.Error(codes.Unauthenticated, "client certificate required")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return auth.Credentials{}, status.Error(codes.Unauthenticated, "verified client certificate required")
+	}
+	accessKey := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	u, ok := globalIAMSys.GetUser(ctx, accessKey)
+	if !ok {
+		return auth.Credentials{}, status.Error(codes.Unauthenticated, "no IAM user matches certificate common name")
+	}
+	return u.Credentials, nil
+}
+
+// grpcDataPlaneErr converts an object layer error into a gRPC status.
+func grpcDataPlaneErr(ctx context.Context, err error) error {
+	apiErr := toAPIError(ctx, err)
+	code := codes.Internal
+	switch apiErr.Code {
+	case "NoSuchBucket", "NoSuchKey", "NoSuchVersion":
+		code = codes.NotFound
+	case "AccessDenied":
+		code = codes.PermissionDenied
+	case "InvalidArgument":
+		code = codes.InvalidArgument
+	}
+	return status.Error(code, apiErr.Description)
+}
+
+// grpcDataPlaneAllowed authorizes one action with the mapped identity.
+func grpcDataPlaneAllowed(cred auth.Credentials, action policy.Action, bucket, object string) error {
+	if globalIAMSys.IsAllowed(policy.Args{
+		AccountName: cred.AccessKey,
+		Groups:      cred.Groups,
+		Action:      action,
+		BucketName:  bucket,
+		ObjectName:  object,
+		IsOwner:     cred.AccessKey == globalActiveCred.AccessKey,
+		Claims:      cred.Claims,
+	}) {
+		return nil
+	}
+	return status.Error(codes.PermissionDenied, "access denied")
+}
+
+func grpcListHandler(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req grpcListRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	cred, err := grpcDataPlaneCred(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = grpcDataPlaneAllowed(cred, policy.ListBucketAction, req.Bucket, ""); err != nil {
+		return nil, err
+	}
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return nil, status.Error(codes.Unavailable, "server not initialized")
+	}
+	maxKeys := req.MaxKeys
+	if maxKeys <= 0 || maxKeys > maxObjectList {
+		maxKeys = maxObjectList
+	}
+	loi, err := objAPI.ListObjects(ctx, req.Bucket, req.Prefix, req.Marker, req.Delimiter, maxKeys)
+	if err != nil {
+		return nil, grpcDataPlaneErr(ctx, err)
+	}
+	resp := grpcListResponse{
+		IsTruncated: loi.IsTruncated,
+		NextMarker:  loi.NextMarker,
+		Prefixes:    loi.Prefixes,
+	}
+	for _, oi := range loi.Objects {
+		resp.Objects = append(resp.Objects, grpcObjectEntry{
+			Name:    oi.Name,
+			Size:    oi.Size,
+			ETag:    oi.ETag,
+			ModTime: oi.ModTime.UnixNano(),
+		})
+	}
+	return &resp, nil
+}
+
+func grpcGetHandler(_ interface{}, stream grpc.ServerStream) error {
+	var req grpcGetRequest
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	ctx := stream.Context()
+	cred, err := grpcDataPlaneCred(ctx)
+	if err != nil {
+		return err
+	}
+	if err = grpcDataPlaneAllowed(cred, policy.GetObjectAction, req.Bucket, req.Object); err != nil {
+		return err
+	}
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return status.Error(codes.Unavailable, "server not initialized")
+	}
+	gr, err := objAPI.GetObjectNInfo(ctx, req.Bucket, req.Object, nil, http.Header{}, ObjectOptions{
+		VersionID: req.VersionID,
+	})
+	if err != nil {
+		return grpcDataPlaneErr(ctx, err)
+	}
+	defer gr.Close()
+
+	buf := make([]byte, grpcDataChunkSize)
+	for {
+		n, rerr := gr.Read(buf)
+		if n > 0 {
+			if serr := stream.SendMsg(&grpcDataChunk{Data: buf[:n]}); serr != nil {
+				return serr
+			}
+		}
+		if rerr == io.EOF {
+			return stream.SendMsg(&grpcDataChunk{EOF: true})
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+}
+
+func grpcPutHandler(_ interface{}, stream grpc.ServerStream) error {
+	var first grpcDataChunk
+	if err := stream.RecvMsg(&first); err != nil {
+		return err
+	}
+	if first.Header == nil {
+		return status.Error(codes.InvalidArgument, "first chunk must carry the header")
+	}
+	hdr := *first.Header
+	ctx := stream.Context()
+	cred, err := grpcDataPlaneCred(ctx)
+	if err != nil {
+		return err
+	}
+	if err = grpcDataPlaneAllowed(cred, policy.PutObjectAction, hdr.Bucket, hdr.Object); err != nil {
+		return err
+	}
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return status.Error(codes.Unavailable, "server not initialized")
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		if len(first.Data) > 0 {
+			if _, werr := pw.Write(first.Data); werr != nil {
+				return
+			}
+		}
+		if first.EOF {
+			pw.Close()
+			return
+		}
+		for {
+			var chunk grpcDataChunk
+			if rerr := stream.RecvMsg(&chunk); rerr != nil {
+				if rerr == io.EOF {
+					pw.Close()
+				} else {
+					pw.CloseWithError(rerr)
+				}
+				return
+			}
+			if len(chunk.Data) > 0 {
+				if _, werr := pw.Write(chunk.Data); werr != nil {
+					return
+				}
+			}
+			if chunk.EOF {
+				pw.Close()
+				return
+			}
+		}
+	}()
+
+	metadata := make(map[string]string, len(hdr.Metadata)+1)
+	for k, v := range hdr.Metadata {
+		metadata[k] = v
+	}
+	if hdr.ContentType != "" {
+		metadata["content-type"] = hdr.ContentType
+	}
+	hr, err := hash.NewReader(ctx, pr, -1, "", "", -1)
+	if err != nil {
+		return err
+	}
+	oi, err := objAPI.PutObject(ctx, hdr.Bucket, hdr.Object, NewPutObjReader(hr), ObjectOptions{
+		UserDefined: metadata,
+	})
+	if err != nil {
+		return grpcDataPlaneErr(ctx, err)
+	}
+	return stream.SendMsg(&grpcPutResponse{
+		Bucket: oi.Bucket,
+		Object: oi.Name,
+		ETag:   oi.ETag,
+		Size:   oi.Size,
+	})
+}
+
+var grpcDataPlaneServiceDesc = grpc.ServiceDesc{
+	ServiceName: "minio.v1.DataPlane",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "List", Handler: grpcListHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "Get", Handler: grpcGetHandler, ServerStreams: true},
+		{StreamName: "Put", Handler: grpcPutHandler, ClientStreams: true},
+	},
+}
+
+// initGRPCDataPlane prepares the co-located gRPC server when enabled.
+// The data plane stays off unless the S3 handshake verifies client
+// certificates: without the cluster CA there is nothing to verify
+// against, and with STS TLS identity enabled the handshake only
+// requests certificates without verifying them (see newTLSConfig), so
+// the common name cannot be trusted as an identity.
+func initGRPCDataPlane() {
+	if env.Get(EnvGRPCDataPlane, config.EnableOff) != config.EnableOn {
+		return
+	}
+	if globalInternodeCA == nil {
+		logger.Fatal(errInvalidArgument, "gRPC data plane requires the cluster CA to verify client certificates")
+	}
+	if env.Get(xtls.EnvIdentityTLSEnabled, "") == config.EnableOn {
+		logger.Fatal(errInvalidArgument, "gRPC data plane cannot be enabled together with STS TLS identity, client certificates are not verified by the handshake")
+	}
+	srv := grpc.NewServer(grpc.ForceServerCodec(grpcJSONCodec{}))
+	srv.RegisterService(&grpcDataPlaneServiceDesc, nil)
+	globalGRPCDataPlane = srv
+}
+
+// setGRPCDataPlaneHandler routes gRPC requests on the S3 port to the
+// data plane server, a no-op unless the data plane is enabled.
+func setGRPCDataPlaneHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if globalGRPCDataPlane != nil && r.ProtoMajor == 2 && r.TLS != nil &&
+			strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			globalGRPCDataPlane.ServeHTTP(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
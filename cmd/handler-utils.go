@@ -25,6 +25,7 @@ import (
 	"net/textproto"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/minio/madmin-go/v3"
 	"github.com/minio/minio/internal/auth"
@@ -326,7 +327,7 @@ func collectAPIStats(api string, f http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		bucket, _ := path2BucketObject(resource)
+		bucket, object := path2BucketObject(resource)
 
 		meta, err := globalBucketMetadataSys.Get(bucket) // check if this bucket exists.
 		countBktStat := bucket != "" && bucket != minioReservedBucket && err == nil && !meta.Created.IsZero()
@@ -334,15 +335,37 @@ func collectAPIStats(api string, f http.HandlerFunc) http.HandlerFunc {
 			globalBucketHTTPStats.updateHTTPStats(bucket, api, nil)
 		}
 
+		if maintenanceRejectRequest(w, r) {
+			return
+		}
+
+		if rateLimitRequest(w, r, api, bucket) {
+			return
+		}
+
+		// Track the request so the admin API can list and cancel it.
+		reqCtx, cancel := context.WithCancel(r.Context())
+		r = r.WithContext(reqCtx)
+		reqID := globalInflightRequests.track(api, bucket, object, requestAccessKey(r), r, cancel)
+
 		globalHTTPStats.currentS3Requests.Inc(api)
 		f.ServeHTTP(w, r)
 		globalHTTPStats.currentS3Requests.Dec(api)
 
+		globalInflightRequests.untrack(reqID)
+		cancel()
+
 		tc, _ := r.Context().Value(mcontext.ContextTraceKey).(*mcontext.TraceCtxt)
 		if tc != nil {
 			globalHTTPStats.updateStats(api, tc.ResponseRecorder)
 			globalConnStats.incS3InputBytes(int64(tc.RequestRecorder.Size()))
 			globalConnStats.incS3OutputBytes(int64(tc.ResponseRecorder.Size()))
+			globalTenantMetrics.record(tc.AccessKey, tc.ParentUser,
+				tc.ResponseRecorder.StatusCode,
+				int64(tc.RequestRecorder.Size()), int64(tc.ResponseRecorder.Size()),
+				time.Since(tc.ResponseRecorder.StartTime))
+			globalAnomalyDetector.record(bucket, api, tc.AccessKey, tc.ResponseRecorder.StatusCode)
+			globalSlowOpLog.record(api, bucket, object, tc)
 
 			if countBktStat {
 				globalBucketConnStats.incS3InputBytes(bucket, int64(tc.RequestRecorder.Size()))
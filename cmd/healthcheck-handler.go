@@ -133,6 +133,14 @@ func ReadinessCheckHandler(w http.ResponseWriter, r *http.Request) {
 	if objLayer := newObjectLayerFn(); objLayer == nil {
 		w.Header().Set(xhttp.MinIOServerStatus, unavailable) // Service not initialized yet
 	}
+
+	// A node in maintenance mode fails readiness so peers and load
+	// balancers stop sending it new work.
+	if globalMaintenanceMode.active() {
+		writeResponse(w, http.StatusServiceUnavailable, nil, mimeNone)
+		return
+	}
+
 	if r.Header.Get(xhttp.MinIOPeerCall) != "" {
 		writeResponse(w, http.StatusOK, nil, mimeNone)
 		return
@@ -0,0 +1,70 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+
+	"github.com/minio/minio/internal/logger"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// Experimental HTTP/3 (QUIC) listener for the S3 API, enabled with
+// `--address-quic`. QUIC's 0-RTT resumption saves round trips for
+// returning clients, which matters on high-latency WAN links. The
+// regular HTTPS listeners advertise the endpoint via Alt-Svc so
+// capable clients upgrade on their own. The listener requires TLS
+// certificates, QUIC has no cleartext mode.
+var globalHTTP3Server *http3.Server
+
+// startHTTP3Server serves the S3 API over HTTP/3 on addr.
+func startHTTP3Server(ctx context.Context, addr string, handler http.Handler, tlsConfig *tls.Config) {
+	if tlsConfig == nil {
+		logger.Fatal(errInvalidArgument, "HTTP/3 listener requires TLS certificates to be configured")
+	}
+
+	globalHTTP3Server = &http3.Server{
+		Addr:      addr,
+		Handler:   handler,
+		TLSConfig: http3.ConfigureTLSConfig(tlsConfig),
+		QUICConfig: &quic.Config{
+			Allow0RTT: true,
+		},
+	}
+
+	go func() {
+		if err := globalHTTP3Server.ListenAndServe(); err != nil && ctx.Err() == nil {
+			bootLogIf(ctx, fmt.Errorf("Unable to listen on `%s` for HTTP/3: %v", addr, err))
+		}
+	}()
+}
+
+// setAltSvcHandler advertises the HTTP/3 endpoint on responses served
+// over the TCP listeners, a no-op while no HTTP/3 listener runs.
+func setAltSvcHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if globalHTTP3Server != nil && r.TLS != nil {
+			globalHTTP3Server.SetQUICHeaders(w.Header())
+		}
+		h.ServeHTTP(w, r)
+	})
+}
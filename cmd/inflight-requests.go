@@ -0,0 +1,211 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/handlers"
+	"github.com/minio/minio/internal/mcontext"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Tracking of in-flight S3 requests for runaway-client mitigation. Each
+// request is registered while its handler runs; the admin API can list
+// them and cancel a specific request, or all requests of an access key,
+// by canceling the request context.
+
+// inflightRequest is one tracked in-flight request.
+type inflightRequest struct {
+	id        string
+	api       string
+	bucket    string
+	object    string
+	accessKey string
+	startTime time.Time
+
+	r      *http.Request
+	cancel context.CancelFunc
+}
+
+// inflightRequestInfo is the JSON representation of one in-flight
+// request in the admin API response.
+type inflightRequestInfo struct {
+	ID         string        `json:"id"`
+	Node       string        `json:"node"`
+	API        string        `json:"api"`
+	Bucket     string        `json:"bucket,omitempty"`
+	Object     string        `json:"object,omitempty"`
+	AccessKey  string        `json:"accessKey,omitempty"`
+	RemoteHost string        `json:"remoteHost"`
+	Duration   time.Duration `json:"duration"`
+	BytesIn    int64         `json:"bytesIn"`
+	BytesOut   int64         `json:"bytesOut"`
+}
+
+// inflightRequests tracks the requests currently being served.
+type inflightRequests struct {
+	mu sync.Mutex
+	m  map[string]*inflightRequest
+}
+
+var globalInflightRequests = &inflightRequests{m: make(map[string]*inflightRequest)}
+
+// track registers an in-flight request, the returned id is passed to
+// untrack when the handler returns.
+func (ir *inflightRequests) track(api, bucket, object, accessKey string, r *http.Request, cancel context.CancelFunc) string {
+	req := &inflightRequest{
+		id:        mustGetUUID(),
+		api:       api,
+		bucket:    bucket,
+		object:    object,
+		accessKey: accessKey,
+		startTime: time.Now().UTC(),
+		r:         r,
+		cancel:    cancel,
+	}
+	ir.mu.Lock()
+	ir.m[req.id] = req
+	ir.mu.Unlock()
+	return req.id
+}
+
+func (ir *inflightRequests) untrack(id string) {
+	ir.mu.Lock()
+	delete(ir.m, id)
+	ir.mu.Unlock()
+}
+
+// list returns the tracked requests, oldest first.
+func (ir *inflightRequests) list() []inflightRequestInfo {
+	ir.mu.Lock()
+	infos := make([]inflightRequestInfo, 0, len(ir.m))
+	for _, req := range ir.m {
+		info := inflightRequestInfo{
+			ID:         req.id,
+			Node:       globalLocalNodeName,
+			API:        req.api,
+			Bucket:     req.bucket,
+			Object:     req.object,
+			AccessKey:  req.accessKey,
+			RemoteHost: handlers.GetSourceIP(req.r),
+			Duration:   time.Since(req.startTime),
+		}
+		if tc, ok := req.r.Context().Value(mcontext.ContextTraceKey).(*mcontext.TraceCtxt); ok {
+			info.BytesIn = int64(tc.RequestRecorder.Size())
+			info.BytesOut = int64(tc.ResponseRecorder.Size())
+		}
+		infos = append(infos, info)
+	}
+	ir.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Duration > infos[j].Duration
+	})
+	return infos
+}
+
+// cancelRequest cancels the in-flight request with the given id,
+// returning whether it was found.
+func (ir *inflightRequests) cancelRequest(id string) bool {
+	ir.mu.Lock()
+	req, ok := ir.m[id]
+	ir.mu.Unlock()
+	if ok {
+		req.cancel()
+	}
+	return ok
+}
+
+// cancelAccessKey cancels all in-flight requests of the given access
+// key, returning how many were canceled.
+func (ir *inflightRequests) cancelAccessKey(accessKey string) int {
+	ir.mu.Lock()
+	var reqs []*inflightRequest
+	for _, req := range ir.m {
+		if req.accessKey == accessKey {
+			reqs = append(reqs, req)
+		}
+	}
+	ir.mu.Unlock()
+
+	for _, req := range reqs {
+		req.cancel()
+	}
+	return len(reqs)
+}
+
+// InflightRequestsHandler - lists the in-flight S3 requests on this
+// node.
+//
+// GET /minio/admin/v3/inflight-requests
+func (a adminAPIHandlers) InflightRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.TopLocksAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	data, err := json.Marshal(globalInflightRequests.list())
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// CancelInflightRequestHandler - cancels one in-flight request by id,
+// or all in-flight requests of an access key.
+//
+// POST /minio/admin/v3/inflight-requests/cancel?id=... or ?accessKey=...
+func (a adminAPIHandlers) CancelInflightRequestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ForceUnlockAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	var canceled int
+	switch {
+	case r.Form.Get("id") != "":
+		if globalInflightRequests.cancelRequest(r.Form.Get("id")) {
+			canceled = 1
+		}
+	case r.Form.Get("accessKey") != "":
+		canceled = globalInflightRequests.cancelAccessKey(r.Form.Get("accessKey"))
+	default:
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(struct {
+		Canceled int `json:"canceled"`
+	}{Canceled: canceled})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/config/storageclass"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Inline data statistics collected by the scanner: for a set of
+// candidate inline block sizes, how many of the scanned objects would
+// have their data inlined into xl.meta. Shard sizes are approximated
+// from the object size and the data drive count of the first pool, so
+// operators can judge the effect of tuning the inline block before
+// changing it. Counters cover objects scanned by this node since it
+// started.
+var inlineStatThresholds = []int64{
+	16 * humanize.KiByte,
+	32 * humanize.KiByte,
+	64 * humanize.KiByte,
+	128 * humanize.KiByte,
+	256 * humanize.KiByte,
+	512 * humanize.KiByte,
+}
+
+var globalInlineStats = struct {
+	dataBlocks  atomic.Int64
+	scanned     atomic.Uint64
+	inlined     atomic.Uint64
+	wouldInline [6]atomic.Uint64
+}{}
+
+// initInlineStats derives the data drive count used to approximate
+// shard sizes from object sizes.
+func initInlineStats(z *erasureServerPools) {
+	setDriveCount := z.serverPools[0].setDriveCount
+	parity := globalStorageClass.GetParityForSC(storageclass.STANDARD)
+	if parity < 0 {
+		parity = storageclass.DefaultParityBlocks(setDriveCount)
+	}
+	if dataBlocks := int64(setDriveCount - parity); dataBlocks > 0 {
+		globalInlineStats.dataBlocks.Store(dataBlocks)
+	}
+}
+
+// recordInlineStats accounts one scanned object version.
+func recordInlineStats(oi ObjectInfo) {
+	if oi.DeleteMarker || oi.Size < 0 {
+		return
+	}
+	dataBlocks := globalInlineStats.dataBlocks.Load()
+	if dataBlocks == 0 {
+		return
+	}
+
+	globalInlineStats.scanned.Add(1)
+	if oi.Inlined {
+		globalInlineStats.inlined.Add(1)
+	}
+
+	shardSize := (oi.Size + dataBlocks - 1) / dataBlocks
+	for i, threshold := range inlineStatThresholds {
+		if shardSize <= threshold {
+			globalInlineStats.wouldInline[i].Add(1)
+		}
+	}
+}
+
+// inlineStatEntry is one candidate threshold in the response.
+type inlineStatEntry struct {
+	Threshold   int64  `json:"threshold"`
+	WouldInline uint64 `json:"wouldInline"`
+}
+
+// InlineDataStatsHandler - returns the configured inline blocks and the
+// scanner's would-inline counts at the candidate thresholds.
+//
+// GET /minio/admin/v3/inline-stats
+func (a adminAPIHandlers) InlineDataStatsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	entries := make([]inlineStatEntry, 0, len(inlineStatThresholds))
+	for i, threshold := range inlineStatThresholds {
+		entries = append(entries, inlineStatEntry{
+			Threshold:   threshold,
+			WouldInline: globalInlineStats.wouldInline[i].Load(),
+		})
+	}
+
+	data, err := json.Marshal(struct {
+		Node              string            `json:"node"`
+		InlineBlock       int64             `json:"inlineBlock"`
+		InlineBlockRRS    int64             `json:"inlineBlockRRS"`
+		ScannedObjects    uint64            `json:"scannedObjects"`
+		InlinedObjects    uint64            `json:"inlinedObjects"`
+		WouldInlineCounts []inlineStatEntry `json:"wouldInlineCounts"`
+	}{
+		Node:              globalLocalNodeName,
+		InlineBlock:       globalStorageClass.InlineBlockForSC(storageclass.STANDARD),
+		InlineBlockRRS:    globalStorageClass.InlineBlockForSC(storageclass.RRS),
+		ScannedObjects:    globalInlineStats.scanned.Load(),
+		InlinedObjects:    globalInlineStats.inlined.Load(),
+		WouldInlineCounts: entries,
+	})
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
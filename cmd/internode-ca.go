@@ -0,0 +1,252 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Automatic internode TLS. When MINIO_INTERNODE_AUTO_TLS=on every node
+// derives the same cluster CA from the cluster root credentials - the
+// shared secret all nodes already hold - and issues itself a short-lived
+// node certificate signed by it. Deriving the CA instead of distributing
+// it avoids the chicken-and-egg of shipping CA material between nodes
+// before TLS is up; the node that boots first simply becomes the first
+// holder of a CA every other node arrives at independently. Inter-node
+// clients always present their node certificate and verify peers against
+// the cluster CA, so internode traffic is mTLS without operators wiring
+// certificates per host. Certificates rotate automatically well before
+// expiry.
+const (
+	// EnvInternodeAutoTLS turns on automatic cluster CA based internode TLS.
+	EnvInternodeAutoTLS = "MINIO_INTERNODE_AUTO_TLS"
+
+	// internodeCertValidity is the lifetime of issued node certificates,
+	// rotation happens at two thirds of this.
+	internodeCertValidity = 24 * time.Hour
+
+	// internodeCASeedLabel salts the CA key derivation from the cluster
+	// root credentials. Bump the version on any incompatible change.
+	internodeCASeedLabel = "minio-cluster-ca-v1"
+)
+
+// globalInternodeCA is non-nil only when automatic internode TLS is
+// enabled via MINIO_INTERNODE_AUTO_TLS.
+var globalInternodeCA *internodeCA
+
+// internodeCA holds the derived cluster CA and the current node
+// certificate issued from it.
+type internodeCA struct {
+	caKey  ed25519.PrivateKey
+	caCert *x509.Certificate
+	pool   *x509.CertPool
+
+	mu       sync.RWMutex
+	nodeCert *tls.Certificate
+}
+
+// newInternodeCA derives the cluster CA from the cluster root
+// credentials. The derivation is deterministic - same secret key, same
+// CA on every node - so no CA material ever crosses the wire.
+func newInternodeCA(secretKey string) (*internodeCA, error) {
+	seed := sha256.Sum256([]byte(internodeCASeedLabel + "\x00" + secretKey))
+	caKey := ed25519.NewKeyFromSeed(seed[:])
+
+	// Fixed validity window and serial keep the CA certificate
+	// byte-identical across nodes; ed25519 signatures are deterministic.
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "MinIO Cluster CA", Organization: []string{"MinIO"}},
+		NotBefore:             time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2115, time.January, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, caKey.Public(), caKey)
+	if err != nil {
+		return nil, err
+	}
+	caCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	return &internodeCA{
+		caKey:  caKey,
+		caCert: caCert,
+		pool:   pool,
+	}, nil
+}
+
+// issueNodeCert issues a fresh short-lived certificate for this node and
+// installs it as the current certificate.
+func (ca *internodeCA) issueNodeCert() error {
+	_, nodeKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	host := globalLocalNodeName
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	dnsNames := []string{"localhost"}
+	ipAddrs := []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	if ip := net.ParseIP(host); ip != nil {
+		ipAddrs = append(ipAddrs, ip)
+	} else if host != "" {
+		dnsNames = append(dnsNames, host)
+	}
+	for _, ip := range mustGetLocalIPs() {
+		if ip != nil {
+			ipAddrs = append(ipAddrs, ip)
+		}
+	}
+
+	now := UTCNow()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host, Organization: []string{"MinIO"}},
+		// Pad NotBefore against clock skew between nodes.
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(internodeCertValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:    dnsNames,
+		IPAddresses: ipAddrs,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.caCert, nodeKey.Public(), ca.caKey)
+	if err != nil {
+		return err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return err
+	}
+
+	cert := &tls.Certificate{
+		Certificate: [][]byte{der, ca.caCert.Raw},
+		PrivateKey:  nodeKey,
+		Leaf:        leaf,
+	}
+
+	ca.mu.Lock()
+	ca.nodeCert = cert
+	ca.mu.Unlock()
+	return nil
+}
+
+// rotate re-issues the node certificate at two thirds of its lifetime,
+// retrying quickly on failure.
+func (ca *internodeCA) rotate(ctx context.Context) {
+	for {
+		wait := internodeCertValidity * 2 / 3
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if err := ca.issueNodeCert(); err != nil {
+			internalLogIf(ctx, fmt.Errorf("unable to rotate internode certificate: %w", err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Minute):
+			}
+		}
+	}
+}
+
+// getCertificate serves the current node certificate, used as server
+// certificate fallback when the operator configured no certificates.
+func (ca *internodeCA) getCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+	if ca.nodeCert == nil {
+		return nil, fmt.Errorf("internode certificate not yet issued")
+	}
+	return ca.nodeCert, nil
+}
+
+// internodeClientCertificate presents the node certificate on internode
+// connections. Resolved at handshake time so transports built before the
+// CA is initialized pick it up transparently; returns an empty
+// certificate - i.e. none - when automatic internode TLS is off.
+func internodeClientCertificate(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	ca := globalInternodeCA
+	if ca == nil {
+		return &tls.Certificate{}, nil
+	}
+	ca.mu.RLock()
+	defer ca.mu.RUnlock()
+	if ca.nodeCert == nil {
+		return &tls.Certificate{}, nil
+	}
+	return ca.nodeCert, nil
+}
+
+// initInternodeCA bootstraps the cluster CA and this node's certificate
+// when automatic internode TLS is enabled. Must run after credentials
+// are loaded and before the internode grid starts dialing peers.
+func initInternodeCA() {
+	if env.Get(EnvInternodeAutoTLS, config.EnableOff) != config.EnableOn {
+		return
+	}
+
+	ca, err := newInternodeCA(globalActiveCred.SecretKey)
+	logger.FatalIf(err, "Unable to derive the cluster CA for internode TLS")
+	logger.FatalIf(ca.issueNodeCert(), "Unable to issue the internode certificate")
+	go ca.rotate(GlobalContext)
+
+	// Trust the cluster CA on outbound internode connections. The pool
+	// is shared with already built transports, mutating it is enough.
+	if globalRootCAs == nil {
+		globalRootCAs = x509.NewCertPool()
+	}
+	globalRootCAs.AddCert(ca.caCert)
+
+	globalInternodeCA = ca
+
+	// Internode URLs must use https from here on even when the operator
+	// configured no certificates of their own.
+	globalIsTLS = true
+}
@@ -0,0 +1,303 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Consistent listing snapshots. A paginated listing under churn is not
+// a point-in-time view: keys created or deleted between pages appear
+// on some pages and not others, which breaks backup tools that need a
+// stable enumeration. With the x-minio-list-snapshot extension header
+// the first ListObjectsV2 request enumerates the whole prefix up
+// front, keeps the result server side and serves every page of the
+// pagination from that frozen view. The continuation token carries the
+// snapshot ID, so follow-up pages need no header. Snapshots are node
+// local and expire after listSnapshotTTL; a backup tool that paginates
+// through a proxy has to pin the pagination to one node the same way
+// regular listing continuation already requires.
+
+const (
+	// xMinioListSnapshot requests snapshot semantics for a
+	// ListObjectsV2 pagination.
+	xMinioListSnapshot = "x-minio-list-snapshot"
+
+	// listSnapshotTokenPrefix marks continuation tokens that page
+	// through a snapshot, followed by "<id>@<offset>".
+	listSnapshotTokenPrefix = "snap@"
+
+	// listSnapshotTTL is how long an idle snapshot is kept, refreshed
+	// on every page served from it.
+	listSnapshotTTL = 15 * time.Minute
+
+	// maxListSnapshots bounds the snapshots held per node, creating
+	// one beyond the cap evicts the least recently used.
+	maxListSnapshots = 32
+
+	// maxListSnapshotEntries bounds the size of one snapshot.
+	maxListSnapshotEntries = 1_000_000
+
+	// maxListSnapshotsMem bounds the approximate heap held by all
+	// snapshots of a node together; building a snapshot evicts least
+	// recently used ones to stay below it and fails when the snapshot
+	// alone would exceed it.
+	maxListSnapshotsMem = 256 << 20
+
+	// listSnapshotEntryOverhead approximates the fixed per-entry heap
+	// cost - the ObjectInfo struct, slice header and map overheads -
+	// on top of the string contents counted per entry.
+	listSnapshotEntryOverhead = 512
+)
+
+// listSnapshotEntry is one entry of the frozen view, either an object
+// or a common prefix.
+type listSnapshotEntry struct {
+	object ObjectInfo
+	prefix string
+}
+
+func (e listSnapshotEntry) name() string {
+	if e.prefix != "" {
+		return e.prefix
+	}
+	return e.object.Name
+}
+
+// memSize approximates the heap footprint of one entry in bytes.
+func (e listSnapshotEntry) memSize() int64 {
+	sz := int64(listSnapshotEntryOverhead)
+	if e.prefix != "" {
+		return sz + int64(len(e.prefix))
+	}
+	sz += int64(len(e.object.Name) + len(e.object.ETag) + len(e.object.VersionID) +
+		len(e.object.ContentType) + len(e.object.StorageClass))
+	for k, v := range e.object.UserDefined {
+		sz += int64(len(k) + len(v))
+	}
+	return sz
+}
+
+// listSnapshot is a frozen enumeration of one bucket/prefix.
+type listSnapshot struct {
+	id       string
+	bucket   string
+	entries  []listSnapshotEntry
+	mem      int64 // approximate heap held by entries
+	lastUsed time.Time
+}
+
+type listSnapshots struct {
+	mu        sync.Mutex
+	snapshots map[string]*listSnapshot
+	mem       int64 // approximate heap held by all snapshots
+}
+
+var globalListSnapshots = &listSnapshots{
+	snapshots: make(map[string]*listSnapshot),
+}
+
+// deleteLocked removes one snapshot and releases its memory budget,
+// the caller holds s.mu.
+func (s *listSnapshots) deleteLocked(id string) {
+	if snap, ok := s.snapshots[id]; ok {
+		s.mem -= snap.mem
+		delete(s.snapshots, id)
+	}
+}
+
+// put stores a freshly built snapshot, evicting expired snapshots and
+// least recently used ones while the count or memory cap is exceeded.
+func (s *listSnapshots) put(snap *listSnapshot) {
+	now := UTCNow()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, old := range s.snapshots {
+		if now.Sub(old.lastUsed) > listSnapshotTTL {
+			s.deleteLocked(id)
+		}
+	}
+	for len(s.snapshots) > 0 && (len(s.snapshots) >= maxListSnapshots || s.mem+snap.mem > maxListSnapshotsMem) {
+		var oldestID string
+		var oldest time.Time
+		for id, old := range s.snapshots {
+			if oldestID == "" || old.lastUsed.Before(oldest) {
+				oldestID, oldest = id, old.lastUsed
+			}
+		}
+		s.deleteLocked(oldestID)
+	}
+
+	snap.lastUsed = now
+	s.snapshots[snap.id] = snap
+	s.mem += snap.mem
+}
+
+// get returns a snapshot by ID and refreshes its idle timer.
+func (s *listSnapshots) get(id string) (*listSnapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap, ok := s.snapshots[id]
+	if !ok {
+		return nil, false
+	}
+	if UTCNow().Sub(snap.lastUsed) > listSnapshotTTL {
+		s.deleteLocked(id)
+		return nil, false
+	}
+	snap.lastUsed = UTCNow()
+	return snap, true
+}
+
+// mergePageEntries merges the objects and prefixes of one listing page
+// into key order, both inputs are already sorted.
+func mergePageEntries(objects []ObjectInfo, prefixes []string) []listSnapshotEntry {
+	entries := make([]listSnapshotEntry, 0, len(objects)+len(prefixes))
+	i, j := 0, 0
+	for i < len(objects) && j < len(prefixes) {
+		if objects[i].Name < prefixes[j] {
+			entries = append(entries, listSnapshotEntry{object: objects[i]})
+			i++
+		} else {
+			entries = append(entries, listSnapshotEntry{prefix: prefixes[j]})
+			j++
+		}
+	}
+	for ; i < len(objects); i++ {
+		entries = append(entries, listSnapshotEntry{object: objects[i]})
+	}
+	for ; j < len(prefixes); j++ {
+		entries = append(entries, listSnapshotEntry{prefix: prefixes[j]})
+	}
+	return entries
+}
+
+// buildListSnapshot enumerates the whole prefix and freezes the
+// result.
+func buildListSnapshot(ctx context.Context, objectAPI ObjectLayer, bucket, prefix, delimiter, startAfter string, fetchOwner bool) (*listSnapshot, error) {
+	snap := &listSnapshot{
+		id:     mustGetUUID(),
+		bucket: bucket,
+	}
+	token := ""
+	for {
+		page, err := objectAPI.ListObjectsV2(ctx, bucket, prefix, token, delimiter, maxObjectList, fetchOwner, startAfter)
+		if err != nil {
+			return nil, err
+		}
+		pageEntries := mergePageEntries(page.Objects, page.Prefixes)
+		for _, entry := range pageEntries {
+			snap.mem += entry.memSize()
+		}
+		snap.entries = append(snap.entries, pageEntries...)
+		if len(snap.entries) > maxListSnapshotEntries {
+			return nil, fmt.Errorf("listing snapshot of %s/%s exceeds %d entries", bucket, prefix, maxListSnapshotEntries)
+		}
+		if snap.mem > maxListSnapshotsMem {
+			return nil, fmt.Errorf("listing snapshot of %s/%s exceeds the %d byte memory budget", bucket, prefix, int64(maxListSnapshotsMem))
+		}
+		if !page.IsTruncated {
+			return snap, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// page serves one page of maxKeys entries starting at offset from the
+// frozen view.
+func (snap *listSnapshot) page(offset, maxKeys int) (info ListObjectsV2Info) {
+	if offset > len(snap.entries) {
+		offset = len(snap.entries)
+	}
+	end := offset + maxKeys
+	if end > len(snap.entries) {
+		end = len(snap.entries)
+	}
+	for _, entry := range snap.entries[offset:end] {
+		if entry.prefix != "" {
+			info.Prefixes = append(info.Prefixes, entry.prefix)
+		} else {
+			info.Objects = append(info.Objects, entry.object)
+		}
+	}
+	if end < len(snap.entries) {
+		info.IsTruncated = true
+		info.NextContinuationToken = fmt.Sprintf("%s%s@%d", listSnapshotTokenPrefix, snap.id, end)
+	}
+	return info
+}
+
+// parseListSnapshotToken splits a snapshot continuation token into
+// snapshot ID and page offset.
+func parseListSnapshotToken(token string) (id string, offset int, ok bool) {
+	rest, found := strings.CutPrefix(token, listSnapshotTokenPrefix)
+	if !found {
+		return "", 0, false
+	}
+	id, offStr, found := strings.Cut(rest, "@")
+	if !found {
+		return "", 0, false
+	}
+	offset, err := strconv.Atoi(offStr)
+	if err != nil || offset < 0 {
+		return "", 0, false
+	}
+	return id, offset, true
+}
+
+// useListSnapshot reports whether the request asked for snapshot
+// semantics, either via the extension header on the first page or via
+// a snapshot continuation token on follow-up pages.
+func useListSnapshot(r *http.Request, token string) bool {
+	return r.Header.Get(xMinioListSnapshot) == "true" || strings.HasPrefix(token, listSnapshotTokenPrefix)
+}
+
+// listObjectsV2FromSnapshot serves a ListObjectsV2 page with snapshot
+// semantics, building the snapshot on the first page.
+func listObjectsV2FromSnapshot(ctx context.Context, objectAPI ObjectLayer, bucket, prefix, token, delimiter, startAfter string, maxKeys int, fetchOwner bool) (ListObjectsV2Info, APIErrorCode, error) {
+	if token == "" {
+		snap, err := buildListSnapshot(ctx, objectAPI, bucket, prefix, delimiter, startAfter, fetchOwner)
+		if err != nil {
+			return ListObjectsV2Info{}, ErrNone, err
+		}
+		globalListSnapshots.put(snap)
+		return snap.page(0, maxKeys), ErrNone, nil
+	}
+
+	id, offset, ok := parseListSnapshotToken(token)
+	if !ok {
+		return ListObjectsV2Info{}, ErrIncorrectContinuationToken, nil
+	}
+	snap, ok := globalListSnapshots.get(id)
+	if !ok || snap.bucket != bucket {
+		// The snapshot expired, was evicted or lives on another node.
+		return ListObjectsV2Info{}, ErrIncorrectContinuationToken, nil
+	}
+	info := snap.page(offset, maxKeys)
+	info.ContinuationToken = token
+	return info, ErrNone, nil
+}
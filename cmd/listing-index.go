@@ -0,0 +1,353 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/pkg/v3/env"
+)
+
+// Opt-in per-bucket listing index serving ListObjects without walking
+// the drives. The index is fed by the write path and reconciled by the
+// scanner, with a periodic snapshot under the config prefix to speed up
+// warm starts. Since only locally observed writes feed it, the index is
+// restricted to single-node deployments; versioned listings always use
+// the regular path. Memory cost is roughly one entry per object, so it
+// is meant for buckets where listing latency dominates and the entry
+// count fits in memory.
+const (
+	// EnvListingIndexBuckets enables the index, a comma separated list
+	// of bucket names.
+	EnvListingIndexBuckets = "MINIO_LISTING_INDEX_BUCKETS"
+
+	// listingIndexPrefix is where the snapshots are stored.
+	listingIndexPrefix = minioConfigPrefix + "/listing-index"
+
+	// listingIndexSnapshotInterval is how often snapshots are written.
+	listingIndexSnapshotInterval = 5 * time.Minute
+)
+
+// listingIndexEntry is the indexed subset of object info needed to
+// serve a listing.
+type listingIndexEntry struct {
+	Size         int64     `json:"size"`
+	ModTime      time.Time `json:"modTime"`
+	ETag         string    `json:"etag"`
+	StorageClass string    `json:"storageClass,omitempty"`
+}
+
+// bucketListingIndex is the in-memory index of one bucket.
+type bucketListingIndex struct {
+	bucket string
+
+	mu      sync.RWMutex
+	entries map[string]listingIndexEntry
+	sorted  []string
+
+	ready atomic.Bool
+}
+
+// upsert adds or refreshes one object.
+func (idx *bucketListingIndex) upsert(object string, entry listingIndexEntry) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, ok := idx.entries[object]; !ok {
+		i := sort.SearchStrings(idx.sorted, object)
+		idx.sorted = append(idx.sorted, "")
+		copy(idx.sorted[i+1:], idx.sorted[i:])
+		idx.sorted[i] = object
+	}
+	idx.entries[object] = entry
+}
+
+// remove drops one object.
+func (idx *bucketListingIndex) remove(object string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(object)
+}
+
+// removeLocked drops one object, the caller holds idx.mu.
+func (idx *bucketListingIndex) removeLocked(object string) {
+	if _, ok := idx.entries[object]; !ok {
+		return
+	}
+	delete(idx.entries, object)
+	i := sort.SearchStrings(idx.sorted, object)
+	if i < len(idx.sorted) && idx.sorted[i] == object {
+		idx.sorted = append(idx.sorted[:i], idx.sorted[i+1:]...)
+	}
+}
+
+// list serves one listing page from the index.
+func (idx *bucketListingIndex) list(prefix, marker, delimiter string, maxKeys int) ListObjectsInfo {
+	var loi ListObjectsInfo
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	start := sort.SearchStrings(idx.sorted, prefix)
+	if marker != "" {
+		if i := sort.SearchStrings(idx.sorted, marker); i >= start {
+			start = i
+			// Marker is exclusive.
+			if start < len(idx.sorted) && idx.sorted[start] == marker {
+				start++
+			}
+		}
+		// A marker ending in the delimiter is a common prefix emitted
+		// on the previous page, resume after everything under it or the
+		// same prefix would be emitted again.
+		if delimiter != "" && strings.HasSuffix(marker, delimiter) {
+			start += sort.Search(len(idx.sorted)-start, func(i int) bool {
+				return !strings.HasPrefix(idx.sorted[start+i], marker)
+			})
+		}
+	}
+
+	seenPrefixes := make(map[string]struct{})
+	lastPrefix := ""
+	for i := start; i < len(idx.sorted); i++ {
+		object := idx.sorted[i]
+		if !strings.HasPrefix(object, prefix) {
+			break
+		}
+
+		if len(loi.Objects)+len(loi.Prefixes) >= maxKeys {
+			loi.IsTruncated = true
+			loi.NextMarker = lastPrefix
+			if loi.NextMarker == "" && len(loi.Objects) > 0 {
+				loi.NextMarker = loi.Objects[len(loi.Objects)-1].Name
+			}
+			break
+		}
+
+		if delimiter != "" {
+			if di := strings.Index(object[len(prefix):], delimiter); di >= 0 {
+				commonPrefix := object[:len(prefix)+di+len(delimiter)]
+				if _, seen := seenPrefixes[commonPrefix]; !seen {
+					seenPrefixes[commonPrefix] = struct{}{}
+					loi.Prefixes = append(loi.Prefixes, commonPrefix)
+				}
+				lastPrefix = commonPrefix
+				continue
+			}
+		}
+
+		entry := idx.entries[object]
+		loi.Objects = append(loi.Objects, ObjectInfo{
+			Bucket:       idx.bucket,
+			Name:         object,
+			Size:         entry.Size,
+			ModTime:      entry.ModTime,
+			ETag:         entry.ETag,
+			StorageClass: entry.StorageClass,
+			IsLatest:     true,
+		})
+		lastPrefix = ""
+	}
+
+	return loi
+}
+
+// listingIndexSys holds the enabled bucket indexes.
+type listingIndexSys struct {
+	mu      sync.RWMutex
+	buckets map[string]*bucketListingIndex
+}
+
+// globalListingIndex is nil until initListingIndex enables it.
+var globalListingIndex *listingIndexSys
+
+// get returns the index of the bucket, nil when not indexed.
+func (sys *listingIndexSys) get(bucket string) *bucketListingIndex {
+	if sys == nil {
+		return nil
+	}
+	sys.mu.RLock()
+	defer sys.mu.RUnlock()
+	return sys.buckets[bucket]
+}
+
+// upsert records a written object.
+func (sys *listingIndexSys) upsert(bucket string, oi ObjectInfo) {
+	idx := sys.get(bucket)
+	if idx == nil || oi.Name == "" {
+		return
+	}
+	idx.upsert(oi.Name, listingIndexEntry{
+		Size:         oi.Size,
+		ModTime:      oi.ModTime,
+		ETag:         oi.ETag,
+		StorageClass: oi.StorageClass,
+	})
+}
+
+// remove records a deleted object.
+func (sys *listingIndexSys) remove(bucket, object string) {
+	if idx := sys.get(bucket); idx != nil {
+		idx.remove(object)
+	}
+}
+
+// reconcile lets the scanner heal drift between drives and index.
+func (sys *listingIndexSys) reconcile(oi ObjectInfo) {
+	if sys == nil || oi.DeleteMarker {
+		return
+	}
+	sys.upsert(oi.Bucket, oi)
+}
+
+// serve returns a listing page from the index when the bucket is
+// indexed and the index finished its initial build.
+func (sys *listingIndexSys) serve(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, bool) {
+	idx := sys.get(bucket)
+	if idx == nil || !idx.ready.Load() {
+		return ListObjectsInfo{}, false
+	}
+	return idx.list(prefix, marker, delimiter, maxKeys), true
+}
+
+// initListingIndex enables the configured bucket indexes and starts
+// their initial builds.
+func initListingIndex(ctx context.Context, z *erasureServerPools) {
+	buckets := env.Get(EnvListingIndexBuckets, "")
+	if buckets == "" {
+		return
+	}
+	if globalIsDistErasure {
+		internalLogIf(ctx, fmt.Errorf("%s is only supported on single node deployments, ignoring", EnvListingIndexBuckets))
+		return
+	}
+
+	sys := &listingIndexSys{buckets: make(map[string]*bucketListingIndex)}
+	for _, bucket := range strings.Split(buckets, ",") {
+		if bucket = strings.TrimSpace(bucket); bucket == "" {
+			continue
+		}
+		idx := &bucketListingIndex{
+			bucket:  bucket,
+			entries: make(map[string]listingIndexEntry),
+		}
+		sys.buckets[bucket] = idx
+	}
+	if len(sys.buckets) == 0 {
+		return
+	}
+	globalListingIndex = sys
+
+	for _, idx := range sys.buckets {
+		go idx.build(ctx, z)
+	}
+	go sys.snapshotLoop(ctx, z)
+}
+
+// snapshotKey is the config path of a bucket's snapshot.
+func listingIndexSnapshotKey(bucket string) string {
+	return pathJoin(listingIndexPrefix, bucket+".json")
+}
+
+// build seeds the index from the last snapshot, then walks the bucket
+// once so the index is complete before it starts serving. Writes
+// arriving during the walk are applied concurrently, both paths upsert.
+// Snapshot entries the walk does not confirm are dropped afterwards,
+// they belong to objects deleted while the server was down.
+func (idx *bucketListingIndex) build(ctx context.Context, z *erasureServerPools) {
+	seeded := make(map[string]listingIndexEntry)
+	if data, err := readConfig(ctx, z, listingIndexSnapshotKey(idx.bucket)); err == nil {
+		var entries map[string]listingIndexEntry
+		if json.Unmarshal(data, &entries) == nil {
+			for object, entry := range entries {
+				idx.upsert(object, entry)
+				seeded[object] = entry
+			}
+		}
+	}
+
+	marker := ""
+	for {
+		res, err := z.ListObjects(ctx, idx.bucket, "", marker, "", maxObjectList)
+		if err != nil {
+			internalLogIf(ctx, fmt.Errorf("listing index build for bucket %s failed: %w", idx.bucket, err))
+			return
+		}
+		for _, oi := range res.Objects {
+			globalListingIndex.upsert(idx.bucket, oi)
+			delete(seeded, oi.Name)
+		}
+		if !res.IsTruncated {
+			break
+		}
+		marker = res.NextMarker
+	}
+
+	// Drop unseen snapshot entries. An entry refreshed by a write that
+	// raced the walk no longer matches its seeded copy and is kept.
+	idx.mu.Lock()
+	for object, old := range seeded {
+		cur, ok := idx.entries[object]
+		if ok && cur.ETag == old.ETag && cur.Size == old.Size && cur.ModTime.Equal(old.ModTime) {
+			idx.removeLocked(object)
+		}
+	}
+	idx.mu.Unlock()
+
+	idx.ready.Store(true)
+}
+
+// snapshotLoop periodically persists the bucket indexes.
+func (sys *listingIndexSys) snapshotLoop(ctx context.Context, z *erasureServerPools) {
+	ticker := time.NewTicker(listingIndexSnapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sys.mu.RLock()
+			indexes := make([]*bucketListingIndex, 0, len(sys.buckets))
+			for _, idx := range sys.buckets {
+				indexes = append(indexes, idx)
+			}
+			sys.mu.RUnlock()
+
+			for _, idx := range indexes {
+				if !idx.ready.Load() {
+					continue
+				}
+				idx.mu.RLock()
+				data, err := json.Marshal(idx.entries)
+				idx.mu.RUnlock()
+				if err != nil {
+					continue
+				}
+				if err = saveConfig(ctx, z, listingIndexSnapshotKey(idx.bucket), data); err != nil {
+					internalLogIf(ctx, fmt.Errorf("unable to persist listing index for bucket %s: %w", idx.bucket, err))
+				}
+			}
+		}
+	}
+}
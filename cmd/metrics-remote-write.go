@@ -0,0 +1,213 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/minio/pkg/v3/env"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	xhttp "github.com/minio/minio/internal/http"
+)
+
+// Prometheus remote-write push mode. Instead of being scraped, the node
+// periodically gathers its metrics and pushes them to a remote-write
+// endpoint - useful for edge deployments behind NAT that Prometheus
+// cannot reach.
+const (
+	// EnvMetricsRemoteWriteEndpoint enables push mode when set to a
+	// remote-write URL.
+	EnvMetricsRemoteWriteEndpoint = "MINIO_METRICS_REMOTE_WRITE_ENDPOINT"
+
+	// EnvMetricsRemoteWriteInterval is the push interval, defaults to 1m.
+	EnvMetricsRemoteWriteInterval = "MINIO_METRICS_REMOTE_WRITE_INTERVAL"
+
+	// EnvMetricsRemoteWriteAuthToken is sent verbatim as the
+	// Authorization header, e.g. "Bearer <token>".
+	EnvMetricsRemoteWriteAuthToken = "MINIO_METRICS_REMOTE_WRITE_AUTH_TOKEN"
+
+	defaultRemoteWriteInterval = time.Minute
+)
+
+// Minimal protobuf encoding of the remote-write WriteRequest message;
+// only the three message types the push path needs are implemented, so
+// no protobuf code generation is required.
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func pbAppendBytes(b []byte, field int, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func pbAppendDouble(b []byte, field int, v float64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|1)
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+}
+
+func pbAppendVarint(b []byte, field int, v int64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, uint64(v))
+}
+
+func pbLabel(name, value string) []byte {
+	b := pbAppendBytes(nil, 1, []byte(name))
+	return pbAppendBytes(b, 2, []byte(value))
+}
+
+func pbSample(value float64, timestampMs int64) []byte {
+	b := pbAppendDouble(nil, 1, value)
+	return pbAppendVarint(b, 2, timestampMs)
+}
+
+// remoteWriteSeries encodes one TimeSeries message for a sample with
+// the given name and labels.
+func remoteWriteSeries(name string, labels []*dto.LabelPair, extra map[string]string, value float64, timestampMs int64) []byte {
+	var ts []byte
+	ts = pbAppendBytes(ts, 1, pbLabel("__name__", name))
+	for _, l := range labels {
+		ts = pbAppendBytes(ts, 1, pbLabel(l.GetName(), l.GetValue()))
+	}
+	for k, v := range extra {
+		ts = pbAppendBytes(ts, 1, pbLabel(k, v))
+	}
+	return pbAppendBytes(ts, 2, pbSample(value, timestampMs))
+}
+
+// remoteWriteEncode converts gathered metric families into a snappy
+// compressed WriteRequest body.
+func remoteWriteEncode(families []*dto.MetricFamily, extra map[string]string) []byte {
+	now := time.Now().UnixMilli()
+	var req []byte
+	for _, mf := range families {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				req = pbAppendBytes(req, 1, remoteWriteSeries(name, m.GetLabel(), extra, m.GetCounter().GetValue(), now))
+			case dto.MetricType_GAUGE:
+				req = pbAppendBytes(req, 1, remoteWriteSeries(name, m.GetLabel(), extra, m.GetGauge().GetValue(), now))
+			case dto.MetricType_UNTYPED:
+				req = pbAppendBytes(req, 1, remoteWriteSeries(name, m.GetLabel(), extra, m.GetUntyped().GetValue(), now))
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.GetBucket() {
+					le := map[string]string{"le": strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)}
+					for k, v := range extra {
+						le[k] = v
+					}
+					req = pbAppendBytes(req, 1, remoteWriteSeries(name+"_bucket", m.GetLabel(), le, float64(b.GetCumulativeCount()), now))
+				}
+				req = pbAppendBytes(req, 1, remoteWriteSeries(name+"_sum", m.GetLabel(), extra, h.GetSampleSum(), now))
+				req = pbAppendBytes(req, 1, remoteWriteSeries(name+"_count", m.GetLabel(), extra, float64(h.GetSampleCount()), now))
+			}
+		}
+	}
+	return s2.EncodeSnappy(nil, req)
+}
+
+// runMetricsRemoteWrite gathers and pushes the node metrics until the
+// context is canceled.
+func runMetricsRemoteWrite(ctx context.Context, endpoint, authToken string, interval time.Duration) {
+	registry := prometheus.NewRegistry()
+	newMetricGroups(registry)
+
+	client := &http.Client{
+		Transport: globalRemoteTargetTransport,
+		Timeout:   30 * time.Second,
+	}
+	extra := map[string]string{
+		"instance": globalLocalNodeName,
+		"job":      "minio",
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		families, err := registry.Gather()
+		if err != nil {
+			internalLogOnceIf(ctx, err, "metrics-remote-write-gather")
+			continue
+		}
+
+		body := remoteWriteEncode(families, extra)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			internalLogOnceIf(ctx, err, "metrics-remote-write-request")
+			continue
+		}
+		req.Header.Set(xhttp.ContentType, "application/x-protobuf")
+		req.Header.Set(xhttp.ContentEncoding, "snappy")
+		req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+		if authToken != "" {
+			req.Header.Set("Authorization", authToken)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			internalLogOnceIf(ctx, err, "metrics-remote-write-send")
+			continue
+		}
+		xhttp.DrainBody(resp.Body)
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			internalLogOnceIf(ctx, fmt.Errorf("remote-write endpoint %s returned '%s'", endpoint, resp.Status),
+				"metrics-remote-write-status")
+		}
+	}
+}
+
+// initMetricsRemoteWrite starts pushing metrics to the configured
+// remote-write endpoint, if any.
+func initMetricsRemoteWrite(ctx context.Context) {
+	endpoint := env.Get(EnvMetricsRemoteWriteEndpoint, "")
+	if endpoint == "" {
+		return
+	}
+
+	interval := defaultRemoteWriteInterval
+	if v := env.Get(EnvMetricsRemoteWriteInterval, ""); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			internalLogIf(ctx, fmt.Errorf("invalid %s value '%s'", EnvMetricsRemoteWriteInterval, v))
+			return
+		}
+		interval = d
+	}
+
+	go runMetricsRemoteWrite(ctx, endpoint, env.Get(EnvMetricsRemoteWriteAuthToken, ""), interval)
+}
@@ -38,6 +38,11 @@ const (
 	apiRequests5xxErrorsTotal MetricName = "5xx_errors_total"
 	apiRequests4xxErrorsTotal MetricName = "4xx_errors_total"
 	apiRequestsCanceledTotal  MetricName = "canceled_total"
+	apiRequestsThrottledTotal MetricName = "throttled_total"
+
+	apiZeroCopyTotal         MetricName = "zero_copy_total"
+	apiZeroCopySentBytes     MetricName = "zero_copy_sent_bytes"
+	apiZeroCopyFallbackTotal MetricName = "zero_copy_fallback_total"
 
 	apiRequestsTTFBSecondsDistribution MetricName = "ttfb_seconds_distribution"
 
@@ -72,6 +77,15 @@ var (
 		"Total number of requests with 4xx errors", "name", "type")
 	apiRequestsCanceledTotalMD = NewCounterMD(apiRequestsCanceledTotal,
 		"Total number of requests canceled by the client", "name", "type")
+	apiRequestsThrottledTotalMD = NewCounterMD(apiRequestsThrottledTotal,
+		"Total number of requests rejected by rate limiting", "class", "type")
+
+	apiZeroCopyTotalMD = NewCounterMD(apiZeroCopyTotal,
+		"Total number of streams served via zero-copy handoff", "type")
+	apiZeroCopySentBytesMD = NewCounterMD(apiZeroCopySentBytes,
+		"Total number of bytes served via zero-copy handoff", "type")
+	apiZeroCopyFallbackMD = NewCounterMD(apiZeroCopyFallbackTotal,
+		"Total number of streams that fell back to userspace copies", "type")
 
 	apiRequestsTTFBSecondsDistributionMD = NewCounterMD(apiRequestsTTFBSecondsDistribution,
 		"Distribution of time to first byte across API calls", "name", "type", "le")
@@ -145,6 +159,29 @@ func loadAPIRequestsNetworkMetrics(ctx context.Context, m MetricValues, _ *metri
 	return nil
 }
 
+// loadAPIRequestsThrottledMetrics - loads rate limiting metrics.
+//
+// This is a `MetricsLoaderFn`.
+func loadAPIRequestsThrottledMetrics(ctx context.Context, m MetricValues, _ *metricsCache) error {
+	l := globalAPIRateLimiter
+	m.Set(apiRequestsThrottledTotal, float64(l.throttledRead.Load()), "class", slowOpClassRead, "type", "s3")
+	m.Set(apiRequestsThrottledTotal, float64(l.throttledWrite.Load()), "class", slowOpClassWrite, "type", "s3")
+	m.Set(apiRequestsThrottledTotal, float64(l.throttledList.Load()), "class", slowOpClassList, "type", "s3")
+	m.Set(apiRequestsThrottledTotal, float64(l.throttledDelete.Load()), "class", slowOpClassDelete, "type", "s3")
+	m.Set(apiRequestsThrottledTotal, float64(l.throttledOther.Load()), "class", slowOpClassOther, "type", "s3")
+	return nil
+}
+
+// loadAPIZeroCopyMetrics - loads zero-copy serving metrics.
+//
+// This is a `MetricsLoaderFn`.
+func loadAPIZeroCopyMetrics(ctx context.Context, m MetricValues, _ *metricsCache) error {
+	m.Set(apiZeroCopyTotal, float64(globalZeroCopyStats.handoffs.Load()), "type", "s3")
+	m.Set(apiZeroCopySentBytes, float64(globalZeroCopyStats.bytes.Load()), "type", "s3")
+	m.Set(apiZeroCopyFallbackTotal, float64(globalZeroCopyStats.fallback.Load()), "type", "s3")
+	return nil
+}
+
 // Metric Descriptions for bucket level S3 metrics.
 var (
 	bucketAPITrafficSentBytesMD = NewCounterMD(apiTrafficSentBytes,
@@ -20,8 +20,10 @@ package cmd
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"github.com/minio/madmin-go/v3"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // label constants
@@ -31,7 +33,8 @@ const (
 	setIndexL   = "set_index"
 	driveIndexL = "drive_index"
 
-	apiL = "api"
+	apiL    = "api"
+	ioTypeL = "io"
 
 	sectorSize = uint64(512)
 	kib        = float64(1 << 10)
@@ -61,6 +64,8 @@ const (
 	driveOnlineCount  = "online_count"
 	driveCount        = "count"
 
+	driveLatencySecondsDistribution = "latency_seconds_distribution"
+
 	// iostat related
 	driveReadsPerSec    = "reads_per_sec"
 	driveReadsKBPerSec  = "reads_kb_per_sec"
@@ -69,8 +74,45 @@ const (
 	driveWritesKBPerSec = "writes_kb_per_sec"
 	driveWritesAwait    = "writes_await"
 	drivePercUtil       = "perc_util"
+	driveIOQueueDepth   = "io_queue_depth"
+	driveIOPSSaturation = "iops_saturation"
+)
+
+// driveStorageLatency records storage operation latencies per drive as
+// a histogram, split by I/O direction.
+var driveStorageLatency = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "drive_storage_latency_seconds",
+		Help:    "Latency of storage operations per drive",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	},
+	[]string{driveL, ioTypeL},
 )
 
+// storageMetricIsWrite classifies which storage API calls count as
+// writes for the per-drive latency histogram.
+func storageMetricIsWrite(s storageMetric) bool {
+	switch s {
+	case storageMetricMakeVolBulk, storageMetricMakeVol, storageMetricDeleteVol,
+		storageMetricAppendFile, storageMetricCreateFile, storageMetricRenameFile,
+		storageMetricRenameData, storageMetricDelete, storageMetricDeleteVersions,
+		storageMetricWriteAll, storageMetricDeleteVersion, storageMetricWriteMetadata,
+		storageMetricUpdateMetadata, storageMetricDeleteAbandonedParts,
+		storageMetricDeleteBulk, storageMetricRenamePart:
+		return true
+	}
+	return false
+}
+
+// observeDriveLatency feeds the per-drive latency histogram.
+func observeDriveLatency(drive string, s storageMetric, duration time.Duration) {
+	ioType := "read"
+	if storageMetricIsWrite(s) {
+		ioType = "write"
+	}
+	driveStorageLatency.WithLabelValues(drive, ioType).Observe(duration.Seconds())
+}
+
 var (
 	driveUsedBytesMD = NewGaugeMD(driveUsedBytes,
 		"Total storage used on a drive in bytes", allDriveLabels...)
@@ -128,6 +170,16 @@ var (
 	drivePercUtilMD = NewGaugeMD(drivePercUtil,
 		"Percentage of time the disk was busy",
 		allDriveLabels...)
+	driveIOQueueDepthMD = NewGaugeMD(driveIOQueueDepth,
+		"Average number of I/O requests in flight on a drive (derived via Little's law)",
+		allDriveLabels...)
+	driveIOPSSaturationMD = NewGaugeMD(driveIOPSSaturation,
+		"Fraction of time the drive was busy serving I/O (0 to 1)",
+		allDriveLabels...)
+
+	driveLatencySecondsDistributionMD = NewCounterMD(driveLatencySecondsDistribution,
+		"Distribution of storage operation latencies per drive",
+		driveL, ioTypeL, "le")
 )
 
 func getCurrentDriveIOStats() map[string]madmin.DiskIOStats {
@@ -201,6 +253,12 @@ func (m *MetricValues) setDriveIOStatMetrics(ioStats driveIOStatMetrics, labels
 	}
 
 	m.Set(drivePercUtil, ioStats.percUtil, labels...)
+
+	// Average in-flight requests per Little's law, approximating the
+	// device queue depth, and busy time as a 0-1 saturation ratio.
+	queueDepth := (ioStats.readsPerSec*ioStats.readsAwait + ioStats.writesPerSec*ioStats.writesAwait) / 1000
+	m.Set(driveIOQueueDepth, queueDepth, labels...)
+	m.Set(driveIOPSSaturation, ioStats.percUtil/100, labels...)
 }
 
 // loadDriveMetrics - `MetricsLoaderFn` for node drive metrics.
@@ -226,6 +284,8 @@ func loadDriveMetrics(ctx context.Context, m MetricValues, c *metricsCache) erro
 		m.setDriveAPIMetrics(disk, labels)
 	}
 
+	m.SetHistogram(driveLatencySecondsDistribution, driveStorageLatency, nil, nil, nil)
+
 	m.Set(driveOfflineCount, float64(driveMetrics.offlineDrives))
 	m.Set(driveOnlineCount, float64(driveMetrics.onlineDrives))
 	m.Set(driveCount, float64(driveMetrics.totalDrives))
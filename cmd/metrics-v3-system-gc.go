@@ -0,0 +1,55 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+)
+
+const (
+	gcGOGCPercent      = "gogc_percent"
+	gcMemLimitBytes    = "mem_limit_bytes"
+	gcAllocBytesPerSec = "alloc_bytes_per_sec"
+	gcHeadroomPerc     = "headroom_perc"
+	gcAdjustmentsTotal = "adjustments_total"
+)
+
+var (
+	gcGOGCPercentMD      = NewGaugeMD(gcGOGCPercent, "Current GOGC value set by the GC governor")
+	gcMemLimitBytesMD    = NewGaugeMD(gcMemLimitBytes, "Current soft memory limit set by the GC governor")
+	gcAllocBytesPerSecMD = NewGaugeMD(gcAllocBytesPerSec, "Observed heap allocation rate")
+	gcHeadroomPercMD     = NewGaugeMD(gcHeadroomPerc, "Observed memory headroom percentage")
+	gcAdjustmentsTotalMD = NewCounterMD(gcAdjustmentsTotal, "Total GOGC and memory limit adjustments made by the GC governor")
+)
+
+// loadGCMetrics - `MetricsLoaderFn` for GC governor metrics.
+func loadGCMetrics(_ context.Context, m MetricValues, _ *metricsCache) error {
+	g := globalGCGovernor
+	if g == nil {
+		return nil
+	}
+
+	gogc, memLimit, allocRate, headroom, adjustments := g.snapshot()
+	m.Set(gcGOGCPercent, float64(gogc))
+	m.Set(gcMemLimitBytes, float64(memLimit))
+	m.Set(gcAllocBytesPerSec, allocRate)
+	m.Set(gcHeadroomPerc, headroom*100)
+	m.Set(gcAdjustmentsTotal, float64(adjustments))
+
+	return nil
+}
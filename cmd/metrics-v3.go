@@ -35,6 +35,9 @@ import (
 // for the bucket "mybucket" would be /minio/metrics/v3/bucket/api/mybucket
 const (
 	apiRequestsCollectorPath collectorPath = "/api/requests"
+	apiTenantCollectorPath   collectorPath = "/api/tenant"
+	cacheCollectorPath       collectorPath = "/cache"
+	bufferPoolCollectorPath  collectorPath = "/bufferpool"
 
 	bucketAPICollectorPath         collectorPath = "/bucket/api"
 	bucketReplicationCollectorPath collectorPath = "/bucket/replication"
@@ -44,6 +47,7 @@ const (
 	systemMemoryCollectorPath           collectorPath = "/system/memory"
 	systemCPUCollectorPath              collectorPath = "/system/cpu"
 	systemProcessCollectorPath          collectorPath = "/system/process"
+	systemGCCollectorPath               collectorPath = "/system/gc"
 
 	debugGoCollectorPath collectorPath = "/debug/go"
 
@@ -93,6 +97,11 @@ func newMetricGroups(r *prometheus.Registry) *metricsV3Collection {
 			apiRequests5xxErrorsTotalMD,
 			apiRequests4xxErrorsTotalMD,
 			apiRequestsCanceledTotalMD,
+			apiRequestsThrottledTotalMD,
+
+			apiZeroCopyTotalMD,
+			apiZeroCopySentBytesMD,
+			apiZeroCopyFallbackMD,
 
 			apiRequestsTTFBSecondsDistributionMD,
 
@@ -100,7 +109,42 @@ func newMetricGroups(r *prometheus.Registry) *metricsV3Collection {
 			apiTrafficRecvBytesMD,
 		},
 		JoinLoaders(loadAPIRequestsHTTPMetrics, loadAPIRequestsTTFBMetrics,
-			loadAPIRequestsNetworkMetrics),
+			loadAPIRequestsNetworkMetrics, loadAPIRequestsThrottledMetrics,
+			loadAPIZeroCopyMetrics),
+	)
+
+	apiTenantMG := NewMetricsGroup(apiTenantCollectorPath,
+		[]MetricDescriptor{
+			tenantRequestsTotalMD,
+			tenantErrors4xxTotalMD,
+			tenantErrors5xxTotalMD,
+			tenantTrafficRecvBytesMD,
+			tenantTrafficSentBytesMD,
+			tenantLatencyP50SecondsMD,
+			tenantLatencyP99SecondsMD,
+		},
+		loadTenantMetrics,
+	)
+
+	cacheMG := NewMetricsGroup(cacheCollectorPath,
+		[]MetricDescriptor{
+			cacheHitsTotalMD,
+			cacheMissesTotalMD,
+			cacheEvictionsTotalMD,
+			cacheUsageBytesMD,
+			cacheCapacityBytesMD,
+		},
+		loadReadCacheMetrics,
+	)
+
+	bufferPoolMG := NewMetricsGroup(bufferPoolCollectorPath,
+		[]MetricDescriptor{
+			bufferPoolGetsTotalMD,
+			bufferPoolAllocsTotalMD,
+			bufferPoolMemoryBytesMD,
+			bufferPoolBudgetBytesMD,
+		},
+		loadBufferPoolMetrics,
 	)
 
 	bucketAPIMG := NewBucketMetricsGroup(bucketAPICollectorPath,
@@ -169,6 +213,17 @@ func newMetricGroups(r *prometheus.Registry) *metricsV3Collection {
 		loadMemoryMetrics,
 	)
 
+	systemGCMG := NewMetricsGroup(systemGCCollectorPath,
+		[]MetricDescriptor{
+			gcGOGCPercentMD,
+			gcMemLimitBytesMD,
+			gcAllocBytesPerSecMD,
+			gcHeadroomPercMD,
+			gcAdjustmentsTotalMD,
+		},
+		loadGCMetrics,
+	)
+
 	systemCPUMG := NewMetricsGroup(systemCPUCollectorPath,
 		[]MetricDescriptor{
 			sysCPUAvgIdleMD,
@@ -233,6 +288,10 @@ func newMetricGroups(r *prometheus.Registry) *metricsV3Collection {
 			driveWritesKBPerSecMD,
 			driveWritesAwaitMD,
 			drivePercUtilMD,
+			driveIOQueueDepthMD,
+			driveIOPSSaturationMD,
+
+			driveLatencySecondsDistributionMD,
 		},
 		loadDriveMetrics,
 	)
@@ -397,12 +456,16 @@ func newMetricGroups(r *prometheus.Registry) *metricsV3Collection {
 
 	allMetricGroups := []*MetricsGroup{
 		apiRequestsMG,
+		apiTenantMG,
+		cacheMG,
+		bufferPoolMG,
 		bucketAPIMG,
 		bucketReplicationMG,
 
 		systemNetworkInternodeMG,
 		systemDriveMG,
 		systemMemoryMG,
+		systemGCMG,
 		systemCPUMG,
 		systemProcessMG,
 
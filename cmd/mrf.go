@@ -254,6 +254,10 @@ func (m *mrfState) healRoutine(z *erasureServerPools) {
 				time.Sleep(time.Second)
 			}
 
+			// Hold off MRF healing until the configured healing
+			// window opens.
+			waitForHealWindow(GlobalContext)
+
 			// wait on timer per heal
 			wait := healSleeper.Timer(context.Background())
 
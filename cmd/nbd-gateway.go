@@ -0,0 +1,552 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Experimental NBD gateway exporting buckets as sparse block devices
+// for VM image hosting. Each export maps a bucket/prefix to a fixed
+// size device stored as 4MiB chunk objects, absent chunks read as
+// zeros. Writes land in a per-export write-back cache and become
+// durable on NBD_CMD_FLUSH, when the dirty cache overflows, or at the
+// periodic flush interval - the admin API reports these semantics per
+// export. The listener carries no authentication, it is meant for
+// trusted in-cluster CSI/hypervisor hosts only.
+const (
+	// EnvNBDAddress enables the NBD gateway on the given listen address.
+	EnvNBDAddress = "MINIO_NBD_ADDRESS"
+
+	// EnvNBDExports defines exports as "name=bucket/prefix:size", comma
+	// separated, e.g. "vm1=images/vm1:10GiB".
+	EnvNBDExports = "MINIO_NBD_EXPORTS"
+
+	nbdChunkSize       = 4 << 20
+	nbdMaxDirtyChunks  = 64 // up to 256MiB of unflushed writes per export
+	nbdFlushInterval   = 30 * time.Second
+	nbdMaxRequestBytes = 32 << 20
+
+	// NBD protocol constants, see the NBD protocol specification.
+	nbdMagic       = 0x4e42444d41474943 // "NBDMAGIC"
+	nbdOptMagic    = 0x49484156454f5054 // "IHAVEOPT"
+	nbdOptReply    = 0x3e889045565a9
+	nbdReqMagic    = 0x25609513
+	nbdRepMagic    = 0x67446698
+	nbdFlagFixed   = 1 << 0
+	nbdOptExport   = 1
+	nbdOptAbort    = 2
+	nbdRepAck      = 1
+	nbdRepErrUnsup = (1 << 31) + 1
+
+	nbdCmdRead  = 0
+	nbdCmdWrite = 1
+	nbdCmdDisc  = 2
+	nbdCmdFlush = 3
+	nbdCmdTrim  = 4
+
+	// HAS_FLAGS | SEND_FLUSH | SEND_TRIM
+	nbdExportFlags = 1 | 4 | 32
+)
+
+// globalNBDGateway is nil unless MINIO_NBD_ADDRESS is set.
+var globalNBDGateway *nbdGateway
+
+type nbdChunk struct {
+	data  []byte
+	dirty bool
+}
+
+type nbdExport struct {
+	name   string
+	bucket string
+	prefix string
+	size   int64
+
+	mu     sync.Mutex
+	chunks map[int64]*nbdChunk
+}
+
+type nbdGateway struct {
+	exports map[string]*nbdExport
+	objAPI  ObjectLayer
+}
+
+// initNBDGateway starts the NBD gateway when MINIO_NBD_ADDRESS is set.
+func initNBDGateway(ctx context.Context, objAPI ObjectLayer) {
+	addr := env.Get(EnvNBDAddress, "")
+	if addr == "" {
+		return
+	}
+
+	exports := make(map[string]*nbdExport)
+	for _, def := range strings.Split(env.Get(EnvNBDExports, ""), ",") {
+		def = strings.TrimSpace(def)
+		if def == "" {
+			continue
+		}
+		exp, err := parseNBDExport(def)
+		if err != nil {
+			internalLogIf(ctx, fmt.Errorf("invalid %s entry %q: %w, ignoring", EnvNBDExports, def, err))
+			continue
+		}
+		exports[exp.name] = exp
+	}
+	if len(exports) == 0 {
+		internalLogIf(ctx, fmt.Errorf("%s is set but %s defines no valid exports, NBD gateway not started", EnvNBDAddress, EnvNBDExports))
+		return
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		internalLogIf(ctx, fmt.Errorf("unable to listen on %s for NBD: %w", addr, err))
+		return
+	}
+
+	gw := &nbdGateway{exports: exports, objAPI: objAPI}
+	globalNBDGateway = gw
+
+	logger.Info(fmt.Sprintf("NBD gateway (experimental) listening on %s with %d export(s)", addr, len(exports)))
+
+	go gw.acceptLoop(ctx, listener)
+	go gw.flushLoop(ctx)
+}
+
+// parseNBDExport parses one "name=bucket/prefix:size" export definition.
+func parseNBDExport(def string) (*nbdExport, error) {
+	name, target, ok := strings.Cut(def, "=")
+	if !ok {
+		return nil, errors.New("missing '='")
+	}
+	loc, sizeStr, ok := strings.Cut(target, ":")
+	if !ok {
+		return nil, errors.New("missing ':size'")
+	}
+	size, err := humanize.ParseBytes(sizeStr)
+	if err != nil {
+		return nil, err
+	}
+	bucket, prefix := path2BucketObject(SlashSeparator + loc)
+	if bucket == "" {
+		return nil, errors.New("missing bucket")
+	}
+	return &nbdExport{
+		name:   name,
+		bucket: bucket,
+		prefix: prefix,
+		size:   int64(size),
+		chunks: make(map[int64]*nbdChunk),
+	}, nil
+}
+
+func (gw *nbdGateway) acceptLoop(ctx context.Context, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		go func() {
+			defer conn.Close()
+			if err := gw.serveConn(ctx, conn); err != nil && !errors.Is(err, io.EOF) {
+				internalLogOnceIf(ctx, fmt.Errorf("NBD connection error: %w", err), "nbd-conn-error")
+			}
+		}()
+	}
+}
+
+func (gw *nbdGateway) flushLoop(ctx context.Context) {
+	t := time.NewTicker(nbdFlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			for _, exp := range gw.exports {
+				internalLogIf(ctx, exp.flush(ctx, gw.objAPI))
+			}
+		}
+	}
+}
+
+// serveConn performs the fixed-newstyle handshake and serves the
+// transmission phase for one client.
+func (gw *nbdGateway) serveConn(ctx context.Context, conn net.Conn) error {
+	var hdr [18]byte
+	binary.BigEndian.PutUint64(hdr[0:], nbdMagic)
+	binary.BigEndian.PutUint64(hdr[8:], nbdOptMagic)
+	binary.BigEndian.PutUint16(hdr[16:], nbdFlagFixed)
+	if _, err := conn.Write(hdr[:]); err != nil {
+		return err
+	}
+
+	var clientFlags [4]byte
+	if _, err := io.ReadFull(conn, clientFlags[:]); err != nil {
+		return err
+	}
+
+	// Option haggling.
+	var exp *nbdExport
+	for exp == nil {
+		var opt [16]byte
+		if _, err := io.ReadFull(conn, opt[:]); err != nil {
+			return err
+		}
+		if binary.BigEndian.Uint64(opt[0:]) != nbdOptMagic {
+			return errors.New("bad option magic")
+		}
+		option := binary.BigEndian.Uint32(opt[8:])
+		length := binary.BigEndian.Uint32(opt[12:])
+		if length > 4096 {
+			return errors.New("oversized option")
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(conn, data); err != nil {
+			return err
+		}
+
+		switch option {
+		case nbdOptExport:
+			e, ok := gw.exports[string(data)]
+			if !ok {
+				return fmt.Errorf("unknown export %q", string(data))
+			}
+			var reply [10]byte
+			binary.BigEndian.PutUint64(reply[0:], uint64(e.size))
+			binary.BigEndian.PutUint16(reply[8:], nbdExportFlags)
+			if _, err := conn.Write(reply[:]); err != nil {
+				return err
+			}
+			// 124 zero bytes of padding, the client did not negotiate
+			// NBD_FLAG_NO_ZEROES.
+			if _, err := conn.Write(make([]byte, 124)); err != nil {
+				return err
+			}
+			exp = e
+		case nbdOptAbort:
+			nbdOptionReply(conn, option, nbdRepAck, nil)
+			return nil
+		default:
+			if err := nbdOptionReply(conn, option, nbdRepErrUnsup, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return gw.transmission(ctx, conn, exp)
+}
+
+func nbdOptionReply(conn net.Conn, option, replyType uint32, data []byte) error {
+	var reply [20]byte
+	binary.BigEndian.PutUint64(reply[0:], nbdOptReply)
+	binary.BigEndian.PutUint32(reply[8:], option)
+	binary.BigEndian.PutUint32(reply[12:], replyType)
+	binary.BigEndian.PutUint32(reply[16:], uint32(len(data)))
+	if _, err := conn.Write(reply[:]); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		_, err := conn.Write(data)
+		return err
+	}
+	return nil
+}
+
+func (gw *nbdGateway) transmission(ctx context.Context, conn net.Conn, exp *nbdExport) error {
+	for {
+		var req [28]byte
+		if _, err := io.ReadFull(conn, req[:]); err != nil {
+			return err
+		}
+		if binary.BigEndian.Uint32(req[0:]) != nbdReqMagic {
+			return errors.New("bad request magic")
+		}
+		cmd := binary.BigEndian.Uint16(req[6:])
+		handle := binary.BigEndian.Uint64(req[8:])
+		offset := int64(binary.BigEndian.Uint64(req[16:]))
+		length := binary.BigEndian.Uint32(req[24:])
+
+		if length > nbdMaxRequestBytes {
+			return fmt.Errorf("request of %d bytes exceeds limit", length)
+		}
+
+		switch cmd {
+		case nbdCmdRead:
+			if offset+int64(length) > exp.size {
+				if err := nbdSimpleReply(conn, handle, 22 /* EINVAL */, nil); err != nil {
+					return err
+				}
+				continue
+			}
+			buf, err := exp.read(ctx, gw.objAPI, offset, int64(length))
+			if err != nil {
+				internalLogIf(ctx, err)
+				if err = nbdSimpleReply(conn, handle, 5 /* EIO */, nil); err != nil {
+					return err
+				}
+				continue
+			}
+			if err = nbdSimpleReply(conn, handle, 0, buf); err != nil {
+				return err
+			}
+		case nbdCmdWrite:
+			data := make([]byte, length)
+			if _, err := io.ReadFull(conn, data); err != nil {
+				return err
+			}
+			errno := uint32(0)
+			if offset+int64(length) > exp.size {
+				errno = 22 // EINVAL
+			} else if err := exp.write(ctx, gw.objAPI, offset, data); err != nil {
+				internalLogIf(ctx, err)
+				errno = 5 // EIO
+			}
+			if err := nbdSimpleReply(conn, handle, errno, nil); err != nil {
+				return err
+			}
+		case nbdCmdFlush:
+			errno := uint32(0)
+			if err := exp.flush(ctx, gw.objAPI); err != nil {
+				internalLogIf(ctx, err)
+				errno = 5 // EIO
+			}
+			if err := nbdSimpleReply(conn, handle, errno, nil); err != nil {
+				return err
+			}
+		case nbdCmdTrim:
+			errno := uint32(0)
+			if offset+int64(length) > exp.size {
+				errno = 22 // EINVAL
+			} else if err := exp.trim(offset, int64(length)); err != nil {
+				errno = 5 // EIO
+			}
+			if err := nbdSimpleReply(conn, handle, errno, nil); err != nil {
+				return err
+			}
+		case nbdCmdDisc:
+			return exp.flush(ctx, gw.objAPI)
+		default:
+			if err := nbdSimpleReply(conn, handle, 22 /* EINVAL */, nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func nbdSimpleReply(conn net.Conn, handle uint64, errno uint32, data []byte) error {
+	var reply [16]byte
+	binary.BigEndian.PutUint32(reply[0:], nbdRepMagic)
+	binary.BigEndian.PutUint32(reply[4:], errno)
+	binary.BigEndian.PutUint64(reply[8:], handle)
+	if _, err := conn.Write(reply[:]); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		_, err := conn.Write(data)
+		return err
+	}
+	return nil
+}
+
+func (exp *nbdExport) chunkObject(idx int64) string {
+	return pathJoin(exp.prefix, fmt.Sprintf("chunks/%08d", idx))
+}
+
+// getChunk returns the chunk at idx, loading it from the object layer
+// on a cache miss. Absent chunks read as zeros - the device is sparse.
+func (exp *nbdExport) getChunk(ctx context.Context, objAPI ObjectLayer, idx int64) (*nbdChunk, error) {
+	if c, ok := exp.chunks[idx]; ok {
+		return c, nil
+	}
+
+	c := &nbdChunk{data: make([]byte, nbdChunkSize)}
+	gr, err := objAPI.GetObjectNInfo(ctx, exp.bucket, exp.chunkObject(idx), nil, http.Header{}, ObjectOptions{})
+	if err == nil {
+		_, err = io.ReadFull(gr, c.data)
+		gr.Close()
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+	} else if !isErrObjectNotFound(err) {
+		return nil, err
+	}
+
+	exp.chunks[idx] = c
+	return c, nil
+}
+
+func (exp *nbdExport) read(ctx context.Context, objAPI ObjectLayer, offset, length int64) ([]byte, error) {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	buf := make([]byte, length)
+	for n := int64(0); n < length; {
+		idx := (offset + n) / nbdChunkSize
+		coff := (offset + n) % nbdChunkSize
+		c, err := exp.getChunk(ctx, objAPI, idx)
+		if err != nil {
+			return nil, err
+		}
+		n += int64(copy(buf[n:], c.data[coff:]))
+	}
+	return buf, nil
+}
+
+func (exp *nbdExport) write(ctx context.Context, objAPI ObjectLayer, offset int64, data []byte) error {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	for n := int64(0); n < int64(len(data)); {
+		idx := (offset + n) / nbdChunkSize
+		coff := (offset + n) % nbdChunkSize
+		c, err := exp.getChunk(ctx, objAPI, idx)
+		if err != nil {
+			return err
+		}
+		n += int64(copy(c.data[coff:], data[n:]))
+		c.dirty = true
+	}
+
+	if exp.dirtyCountLocked() > nbdMaxDirtyChunks {
+		return exp.flushLocked(ctx, objAPI)
+	}
+	return nil
+}
+
+func (exp *nbdExport) trim(offset, length int64) error {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+
+	// Only whole chunks are zeroed, partial trims are advisory anyway.
+	for idx := offset / nbdChunkSize; (idx+1)*nbdChunkSize <= offset+length; idx++ {
+		if c, ok := exp.chunks[idx]; ok {
+			clear(c.data)
+			c.dirty = true
+		}
+	}
+	return nil
+}
+
+func (exp *nbdExport) dirtyCountLocked() (n int) {
+	for _, c := range exp.chunks {
+		if c.dirty {
+			n++
+		}
+	}
+	return n
+}
+
+// flush persists every dirty chunk to the object layer.
+func (exp *nbdExport) flush(ctx context.Context, objAPI ObjectLayer) error {
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	return exp.flushLocked(ctx, objAPI)
+}
+
+func (exp *nbdExport) flushLocked(ctx context.Context, objAPI ObjectLayer) error {
+	for idx, c := range exp.chunks {
+		if !c.dirty {
+			// Drop clean chunks to bound memory, they reload on demand.
+			if len(exp.chunks) > nbdMaxDirtyChunks {
+				delete(exp.chunks, idx)
+			}
+			continue
+		}
+		hr, err := hash.NewReader(ctx, bytes.NewReader(c.data), nbdChunkSize, "", "", nbdChunkSize)
+		if err != nil {
+			return err
+		}
+		if _, err = objAPI.PutObject(ctx, exp.bucket, exp.chunkObject(idx), NewPutObjReader(hr), ObjectOptions{}); err != nil {
+			return err
+		}
+		c.dirty = false
+	}
+	return nil
+}
+
+// NBDExportsHandler - GET /minio/admin/v3/nbd-exports
+// Reports the configured NBD exports along with their durability
+// semantics.
+func (a adminAPIHandlers) NBDExportsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	type exportInfo struct {
+		Name        string `json:"name"`
+		Bucket      string `json:"bucket"`
+		Prefix      string `json:"prefix"`
+		Size        int64  `json:"size"`
+		DirtyChunks int    `json:"dirtyChunks"`
+		Durability  string `json:"durability"`
+	}
+
+	resp := struct {
+		Node    string       `json:"node"`
+		Enabled bool         `json:"enabled"`
+		Exports []exportInfo `json:"exports,omitempty"`
+	}{
+		Node:    globalLocalNodeName,
+		Enabled: globalNBDGateway != nil,
+	}
+	if globalNBDGateway != nil {
+		for _, exp := range globalNBDGateway.exports {
+			exp.mu.Lock()
+			dirty := exp.dirtyCountLocked()
+			exp.mu.Unlock()
+			resp.Exports = append(resp.Exports, exportInfo{
+				Name:        exp.name,
+				Bucket:      exp.bucket,
+				Prefix:      exp.prefix,
+				Size:        exp.size,
+				DirtyChunks: dirty,
+				Durability:  fmt.Sprintf("write-back: writes are acknowledged from the local cache and become durable on NBD flush, when more than %d chunks are dirty, or within %s", nbdMaxDirtyChunks, nbdFlushInterval),
+			})
+		}
+	}
+
+	buf, err := json.Marshal(resp)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
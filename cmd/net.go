@@ -121,7 +121,7 @@ func getHostIP(host string) (ipList set.StringSet, err error) {
 	}
 
 	ipList = set.NewStringSet()
-	for _, addr := range addrs {
+	for _, addr := range filterByAddressFamily(addrs) {
 		ipList.Add(addr)
 	}
 
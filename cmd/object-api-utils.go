@@ -50,6 +50,7 @@ import (
 	"github.com/minio/minio/internal/ioutil"
 	xioutil "github.com/minio/minio/internal/ioutil"
 	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/v3/env"
 	"github.com/minio/pkg/v3/trie"
 	"github.com/minio/pkg/v3/wildcard"
 	"github.com/valyala/bytebufferpool"
@@ -77,8 +78,17 @@ const (
 	compReadAheadBufSize = 1 << 20
 	// Pad Encrypted+Compressed files to a multiple of this.
 	compPadEncrypted = 256
-	// Disable compressed file indices below this size
-	compMinIndexSize = 8 << 20
+
+	// EnvCompressMinIndexSize overrides the part size above which an
+	// s2 seek index is written for compressed objects.
+	EnvCompressMinIndexSize = "MINIO_COMPRESS_MIN_INDEX_SIZE"
+
+	// Disable compressed file indices below this size. One s2 block
+	// decompresses as a unit anyway, so indexing only pays off once a
+	// part spans several blocks - but without an index every ranged
+	// GET decompresses the part from its start, so keep the bar at a
+	// single block.
+	defaultCompMinIndexSize = 1 << 20
 )
 
 // getkeyeparator - returns the separator to be used for
@@ -542,7 +552,7 @@ func (o *ObjectInfo) IsCompressedOK() (bool, error) {
 		return false, nil
 	}
 	switch scheme {
-	case compressionAlgorithmV1, compressionAlgorithmV2:
+	case compressionAlgorithmV1, compressionAlgorithmV2, compressionAlgorithmZstdDict:
 		return true, nil
 	}
 	return true, fmt.Errorf("unknown compression scheme: %s", scheme)
@@ -890,24 +900,41 @@ func NewGetObjectReader(rs *HTTPRangeSpec, oi ObjectInfo, opts ObjectOptions, h
 				oi.Size = decLength
 			}
 			// Decompression reader.
-			var dopts []s2.ReaderOption
-			if off > 0 || decOff > 0 {
-				// We are not starting at the beginning, so ignore stream identifiers.
-				dopts = append(dopts, s2.ReaderIgnoreStreamIdentifier())
-			}
-			s2Reader := s2.NewReader(inputReader, dopts...)
-			// Apply the skipLen and limit on the decompressed stream.
-			if decOff > 0 {
-				if err = s2Reader.Skip(decOff); err != nil {
+			var decReader io.Reader
+			if oi.UserDefined[ReservedMetadataPrefix+"compression"] == compressionAlgorithmZstdDict {
+				// Dictionary compressed objects are small, decompress
+				// fully and serve any range from the buffer.
+				decompressed, derr := zstdDictDecompress(GlobalContext, oi, inputReader)
+				if derr != nil {
 					// Call the cleanup funcs
 					for i := len(cFns) - 1; i >= 0; i-- {
 						cFns[i]()
 					}
-					return nil, err
+					return nil, derr
+				}
+				if decOff > int64(len(decompressed)) {
+					decOff = int64(len(decompressed))
+				}
+				decReader = io.LimitReader(bytes.NewReader(decompressed[decOff:]), decLength)
+			} else {
+				var dopts []s2.ReaderOption
+				if off > 0 || decOff > 0 {
+					// We are not starting at the beginning, so ignore stream identifiers.
+					dopts = append(dopts, s2.ReaderIgnoreStreamIdentifier())
+				}
+				s2Reader := s2.NewReader(inputReader, dopts...)
+				// Apply the skipLen and limit on the decompressed stream.
+				if decOff > 0 {
+					if err = s2Reader.Skip(decOff); err != nil {
+						// Call the cleanup funcs
+						for i := len(cFns) - 1; i >= 0; i-- {
+							cFns[i]()
+						}
+						return nil, err
+					}
 				}
+				decReader = io.LimitReader(s2Reader, decLength)
 			}
-
-			decReader := io.LimitReader(s2Reader, decLength)
 			if decLength > compReadAheadSize {
 				rah, err := readahead.NewReaderSize(decReader, compReadAheadBuffers, compReadAheadBufSize)
 				if err == nil {
@@ -1122,6 +1149,26 @@ func init() {
 	}
 }
 
+var (
+	compMinIndexSizeOnce sync.Once
+	compMinIndexSizeV    int64
+)
+
+// compMinIndexSize returns the part size above which compressed parts
+// carry a seek index, so ranged GETs decompress only the blocks that
+// cover the range instead of the whole part from its start.
+func compMinIndexSize() int64 {
+	compMinIndexSizeOnce.Do(func() {
+		compMinIndexSizeV = defaultCompMinIndexSize
+		if v := env.Get(EnvCompressMinIndexSize, ""); v != "" {
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+				compMinIndexSizeV = n
+			}
+		}
+	})
+	return compMinIndexSizeV
+}
+
 // newS2CompressReader will read data from r, compress it and return the compressed data as a Reader.
 // Use Close to ensure resources are released on incomplete streams.
 //
@@ -1157,7 +1204,7 @@ func newS2CompressReader(r io.Reader, on int64, encrypted bool) (rc io.ReadClose
 		}
 		// Close the stream.
 		// If more than compMinIndexSize was written, generate index.
-		if cn > compMinIndexSize {
+		if cn > compMinIndexSize() {
 			idx, err := comp.CloseIndex()
 			idx = s2.RemoveIndexHeaders(idx)
 			indexCh <- idx
@@ -0,0 +1,345 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+
+	sse "github.com/minio/minio/internal/bucket/encryption"
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/event"
+	"github.com/minio/minio/internal/fips"
+	"github.com/minio/minio/internal/handlers"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+	"github.com/minio/sio"
+)
+
+// Server-side object composition. The SDK ComposeObject helper stitches
+// objects together by driving a multipart upload from the client, which
+// still moves every byte through the client for cross-pool copies and
+// needs one round trip per source. The compose extension API does the
+// same stitching fully server side: one POST names the sources, the
+// server runs the multipart copy internally - across pools, since part
+// copies go through the object layer - and the client gets the composed
+// object's ETag back.
+
+const (
+	// maxComposeSources bounds the number of sources of one compose
+	// request.
+	maxComposeSources = 32
+
+	// maxComposeBodySize bounds the compose request body.
+	maxComposeBodySize = 1 << 20
+)
+
+// composeSource is one source object of a compose request.
+type composeSource struct {
+	Key       string `xml:"Key"`
+	VersionID string `xml:"VersionId"`
+}
+
+// composeObjectRequest - http request body of the compose API.
+type composeObjectRequest struct {
+	XMLName xml.Name        `xml:"ComposeRequest" json:"-"`
+	Sources []composeSource `xml:"Source"`
+}
+
+// composeObjectResult - http response body of the compose API.
+type composeObjectResult struct {
+	XMLName xml.Name `xml:"ComposeObjectResult" json:"-"`
+
+	Bucket string
+	Key    string
+	ETag   string
+}
+
+// ComposeObjectHandler - POST /bucket/object?compose
+// ----------
+// Concatenates up to maxComposeSources existing objects of the bucket
+// into a new object without moving the data through the client. Every
+// source except the last must be at least the minimum part size, the
+// same constraint a regular multipart upload puts on its parts.
+func (api objectAPIHandlers) ComposeObjectHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ComposeObject")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, object); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	// Apply the bucket default encryption configuration, composed
+	// objects must not bypass mandatory bucket SSE.
+	sseConfig, _ := globalBucketSSEConfigSys.Get(bucket)
+	sseConfig.Apply(r.Header, sse.ApplyOptions{
+		AutoEncrypt: globalAutoEncryption,
+	})
+
+	// Compose does not support client supplied encryption keys, the
+	// server cannot hold them across the internal part copies.
+	if crypto.SSEC.IsRequested(r.Header) || crypto.SSECopy.IsRequested(r.Header) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	composeReq := composeObjectRequest{}
+	if err = xmlDecoder(r.Body, &composeReq, maxComposeBodySize); err != nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrMalformedXML), r.URL)
+		return
+	}
+	if len(composeReq.Sources) == 0 || len(composeReq.Sources) > maxComposeSources {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	// The caller needs read access to every source.
+	for _, src := range composeReq.Sources {
+		if s3Error := checkRequestAuthType(ctx, r, policy.GetObjectAction, bucket, src.Key); s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+	}
+
+	// Validate the sources up front so the multipart upload is only
+	// created when the compose can succeed.
+	for i, src := range composeReq.Sources {
+		srcInfo, err := objectAPI.GetObjectInfo(ctx, bucket, src.Key, ObjectOptions{VersionID: src.VersionID})
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		actualSize, err := srcInfo.GetActualSize()
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		// All sources but the last must satisfy the minimum part size,
+		// CompleteMultipartUpload enforces the same.
+		if i < len(composeReq.Sources)-1 && !isMinAllowedPartSize(actualSize) {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrEntityTooSmall), r.URL)
+			return
+		}
+	}
+
+	encMetadata := map[string]string{}
+	if crypto.Requested(r.Header) {
+		if err = setEncryptionMetadata(r, bucket, object, encMetadata); err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		// Set this for multipart only operations, we need to
+		// differentiate during decryption if the file was actually
+		// multipart or not.
+		encMetadata[ReservedMetadataPrefix+"Encrypted-Multipart"] = ""
+	}
+
+	metadata, err := extractMetadataFromReq(ctx, r)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	for k, v := range encMetadata {
+		metadata[k] = v
+	}
+	opts, err := putOptsFromReq(ctx, r, bucket, object, metadata)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	res, err := objectAPI.NewMultipartUpload(ctx, bucket, object, opts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	uploadID := res.UploadID
+
+	completed := false
+	defer func() {
+		if !completed {
+			if aerr := objectAPI.AbortMultipartUpload(ctx, bucket, object, uploadID, ObjectOptions{}); aerr != nil {
+				internalLogIf(ctx, aerr)
+			}
+		}
+	}()
+
+	// Read compression and encryption metadata preserved in the init
+	// multipart for the decisions below.
+	mi, err := objectAPI.GetMultipartInfo(ctx, bucket, object, uploadID, ObjectOptions{})
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	_, isCompressed := mi.UserDefined[ReservedMetadataPrefix+"compression"]
+	_, isEncrypted := crypto.IsEncrypted(mi.UserDefined)
+
+	var objectEncryptionKey crypto.ObjectKey
+	if isEncrypted {
+		key, err := decryptObjectMeta(nil, bucket, object, mi.UserDefined)
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		copy(objectEncryptionKey[:], key)
+	}
+
+	// Copy every source into one part of the destination, the data
+	// never leaves the server.
+	uploadedParts := make([]CompletePart, 0, len(composeReq.Sources))
+	for i, src := range composeReq.Sources {
+		gr, err := objectAPI.GetObjectNInfo(ctx, bucket, src.Key, nil, http.Header{}, ObjectOptions{
+			VersionID: src.VersionID,
+		})
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		srcInfo := gr.ObjInfo
+		actualSize, err := srcInfo.GetActualSize()
+		if err != nil {
+			gr.Close()
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		length := actualSize
+		var reader io.Reader = gr
+		// Compress only if the compression is enabled during initial multipart.
+		var idxCb func() []byte
+		if isCompressed {
+			s2c, cb := newS2CompressReader(reader, actualSize, isEncrypted)
+			idxCb = cb
+			defer s2c.Close()
+			reader = s2c
+			length = -1
+		}
+		srcInfo.Reader, err = hash.NewReader(ctx, reader, length, "", "", actualSize)
+		if err != nil {
+			gr.Close()
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		pReader := NewPutObjReader(srcInfo.Reader)
+		if isEncrypted {
+			// Encrypt the part the same way CopyObjectPart handles it,
+			// the part key is derived from the object key and the part
+			// number.
+			partID := i + 1
+			var nonce [12]byte
+			tmp := sha256.Sum256([]byte(fmt.Sprint(uploadID, partID)))
+			copy(nonce[:], tmp[:12])
+
+			partEncryptionKey := objectEncryptionKey.DerivePartKey(uint32(partID))
+			encReader, err := sio.EncryptReader(reader, sio.Config{
+				Key:          partEncryptionKey[:],
+				CipherSuites: fips.DARECiphers(),
+				Nonce:        &nonce,
+			})
+			if err != nil {
+				gr.Close()
+				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+				return
+			}
+
+			wantSize := int64(-1)
+			if length >= 0 {
+				info := ObjectInfo{Size: length}
+				wantSize = info.EncryptedSize()
+			}
+			srcInfo.Reader, err = hash.NewReader(ctx, encReader, wantSize, "", "", actualSize)
+			if err != nil {
+				gr.Close()
+				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+				return
+			}
+			pReader, err = pReader.WithEncryption(srcInfo.Reader, &objectEncryptionKey)
+			if err != nil {
+				gr.Close()
+				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+				return
+			}
+			if idxCb != nil {
+				idxCb = compressionIndexEncrypter(objectEncryptionKey, idxCb)
+			}
+		}
+		srcInfo.PutObjReader = pReader
+		partInfo, err := objectAPI.CopyObjectPart(ctx, bucket, src.Key, bucket, object, uploadID, i+1,
+			0, length, srcInfo, ObjectOptions{VersionID: src.VersionID}, ObjectOptions{IndexCB: idxCb})
+		gr.Close()
+		if err != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+			return
+		}
+		uploadedParts = append(uploadedParts, CompletePart{
+			PartNumber: partInfo.PartNumber,
+			ETag:       partInfo.ETag,
+		})
+	}
+
+	completeOpts, err := completeMultipartOpts(ctx, r, bucket, object)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	objInfo, err := objectAPI.CompleteMultipartUpload(ctx, bucket, object, uploadID, uploadedParts, completeOpts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	completed = true
+
+	setPutObjHeaders(w, objInfo, false, r.Header)
+	response := composeObjectResult{
+		Bucket: objInfo.Bucket,
+		Key:    objInfo.Name,
+		ETag:   "\"" + objInfo.ETag + "\"",
+	}
+	writeSuccessResponseXML(w, encodeResponse(response))
+
+	// Notify object created event.
+	sendEvent(eventArgs{
+		EventName:    event.ObjectCreatedCompleteMultipartUpload,
+		BucketName:   bucket,
+		Object:       objInfo,
+		ReqParams:    extractReqParams(r),
+		RespElements: extractRespElements(w),
+		UserAgent:    r.UserAgent(),
+		Host:         handlers.GetSourceIP(r),
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Server-side decompression of stored compressed objects. Data lakes
+// keep logs as .gz or .zst objects; clients that want the plain bytes
+// otherwise have to download and decompress themselves. With the
+// x-minio-decompress extension query parameter a GET decompresses the
+// object on the server and streams the plain bytes. The decompressed
+// length is not known up front, so the response carries no
+// Content-Length and is transferred chunked.
+
+// xMinioDecompress is the extension query parameter requesting
+// server-side decompression.
+const xMinioDecompress = "x-minio-decompress"
+
+var errDecompressUnsupported = errors.New("object is not gzip or zstd compressed")
+
+// wantsObjectDecompress reports whether the request asked for
+// server-side decompression.
+func wantsObjectDecompress(r *http.Request) bool {
+	return r.Form.Get(xMinioDecompress) == "true"
+}
+
+// objectDecompressFormat detects the compression format of a stored
+// object from its name or content type.
+func objectDecompressFormat(objInfo ObjectInfo) (format string, ok bool) {
+	name := strings.ToLower(objInfo.Name)
+	switch {
+	case strings.HasSuffix(name, ".gz"), strings.HasSuffix(name, ".gzip"):
+		return "gzip", true
+	case strings.HasSuffix(name, ".zst"), strings.HasSuffix(name, ".zstd"):
+		return "zstd", true
+	}
+	switch objInfo.ContentType {
+	case "application/gzip", "application/x-gzip":
+		return "gzip", true
+	case "application/zstd", "application/x-zstd":
+		return "zstd", true
+	}
+	return "", false
+}
+
+// newObjectDecompressReader wraps the object stream in the matching
+// decompressor.
+func newObjectDecompressReader(r io.Reader, objInfo ObjectInfo) (io.ReadCloser, error) {
+	format, ok := objectDecompressFormat(objInfo)
+	if !ok {
+		return nil, errDecompressUnsupported
+	}
+	switch format {
+	case "gzip":
+		return gzip.NewReader(r)
+	default:
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/hash"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Opt-in content-addressed deduplication for backup workloads. When
+// MINIO_CONTENT_DEDUP=on and a PutObject carries a full-object SHA-256
+// (signed payload hash or x-amz-checksum-sha256), content already
+// present in the bucket is stored as a zero-byte metadata reference to
+// the existing data version instead of being written again. Reads
+// through a reference transparently serve the source version, and a
+// per-content reference count - maintained on version deletes -
+// protects the source data version from removal while references to
+// it remain. Only versioned writes participate: versioning keeps the
+// source data version immutable, overwrites create new versions and
+// plain deletes stack delete markers instead of destroying referenced
+// bytes. Encrypted uploads are never deduplicated.
+const (
+	// EnvContentDedup enables content deduplication when set to "on".
+	EnvContentDedup = "MINIO_CONTENT_DEDUP"
+
+	// dedupIndexPrefix is where per-content reference counts live,
+	// relative to minioMetaBucket.
+	dedupIndexPrefix = ".dedup-index"
+)
+
+// Internal metadata keys, stripped from client facing responses like
+// all reserved metadata.
+const (
+	// dedupMetaHash marks an object participating in dedup with its
+	// content hash, present on sources and references alike.
+	dedupMetaHash = ReservedMetadataPrefix + "dedup-hash"
+
+	// dedupMetaSource is the object name of the data version a
+	// reference points to, only present on references.
+	dedupMetaSource = ReservedMetadataPrefix + "dedup-source"
+
+	// dedupMetaSourceVID is the version ID of the data version a
+	// reference points to.
+	dedupMetaSourceVID = ReservedMetadataPrefix + "dedup-source-version"
+)
+
+// errDedupSourceReferenced is returned when a data version cannot be
+// deleted because deduplicated references still point to it.
+var errDedupSourceReferenced = errors.New("version is referenced by deduplicated objects")
+
+// dedupEntry tracks one content hash within a bucket.
+type dedupEntry struct {
+	Hash      string    `json:"hash"`
+	Bucket    string    `json:"bucket"`
+	Object    string    `json:"object"`
+	VersionID string    `json:"versionId,omitempty"`
+	Size      int64     `json:"size"`
+	ETag      string    `json:"etag"`
+	Refs      int       `json:"refs"` // objects sharing this content, including the source
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// contentDedupEnabled reports whether content deduplication is turned
+// on via environment.
+func contentDedupEnabled() bool {
+	return env.Get(EnvContentDedup, config.EnableOff) == config.EnableOn
+}
+
+// dedupIndexPath returns a content hash's index entry location
+// relative to minioMetaBucket.
+func dedupIndexPath(bucket, hash string) string {
+	return pathJoin(dedupIndexPrefix, bucket, hash+".json")
+}
+
+// dedupContentHash extracts a trustworthy full-object SHA-256 from the
+// request, either the signed payload hash or an additional checksum.
+// Streaming and unsigned payloads carry no usable hash.
+func dedupContentHash(h http.Header) (string, bool) {
+	if v := h.Get(xhttp.AmzContentSha256); len(v) == 64 {
+		if _, err := hex.DecodeString(v); err == nil && v != emptySHA256 {
+			return v, true
+		}
+	}
+	if v := h.Get(xhttp.AmzChecksumSHA256); v != "" {
+		if b, err := base64.StdEncoding.DecodeString(v); err == nil && len(b) == 32 {
+			return hex.EncodeToString(b), true
+		}
+	}
+	return "", false
+}
+
+// loadDedupEntry reads the index entry of a content hash.
+func loadDedupEntry(ctx context.Context, api ObjectLayer, bucket, hash string) (e dedupEntry, err error) {
+	data, err := readConfig(ctx, api, dedupIndexPath(bucket, hash))
+	if err != nil {
+		return e, err
+	}
+	err = json.Unmarshal(data, &e)
+	return e, err
+}
+
+// saveDedupEntry writes the index entry of a content hash.
+func saveDedupEntry(ctx context.Context, api ObjectLayer, e dedupEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, api, dedupIndexPath(e.Bucket, e.Hash), data)
+}
+
+// tryDedupPutObject stores object as a metadata reference when the
+// uploaded content already exists in the bucket. The request body is
+// fully read and hashed to prove the client holds the claimed content.
+// Returns false when no matching content is indexed and a regular
+// write should proceed.
+func tryDedupPutObject(ctx context.Context, api ObjectLayer, bucket, object, contentHash string, size int64, body io.Reader, opts ObjectOptions) (ObjectInfo, bool, error) {
+	entry, err := loadDedupEntry(ctx, api, bucket, contentHash)
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			err = nil
+		}
+		return ObjectInfo{}, false, err
+	}
+	if size >= 0 && entry.Size != size {
+		// Same hash, different size - never trust it.
+		return ObjectInfo{}, false, nil
+	}
+	if entry.VersionID == "" {
+		// Only immutable versioned sources may be referenced.
+		return ObjectInfo{}, false, nil
+	}
+	// The source data version must still be readable.
+	if _, err = api.GetObjectInfo(ctx, bucket, entry.Object, ObjectOptions{VersionID: entry.VersionID}); err != nil {
+		return ObjectInfo{}, false, nil
+	}
+
+	// The claimed hash is only as good as the bytes actually sent,
+	// verify before referencing existing content.
+	sum := sha256.New()
+	if _, err = io.Copy(sum, body); err != nil {
+		return ObjectInfo{}, false, err
+	}
+	if calculated := hex.EncodeToString(sum.Sum(nil)); calculated != contentHash {
+		return ObjectInfo{}, false, hash.SHA256Mismatch{
+			ExpectedSHA256:   contentHash,
+			CalculatedSHA256: calculated,
+		}
+	}
+
+	refOpts := opts
+	refOpts.UserDefined = make(map[string]string, len(opts.UserDefined)+3)
+	for k, v := range opts.UserDefined {
+		refOpts.UserDefined[k] = v
+	}
+	refOpts.UserDefined[dedupMetaHash] = contentHash
+	refOpts.UserDefined[dedupMetaSource] = entry.Object
+	refOpts.UserDefined[dedupMetaSourceVID] = entry.VersionID
+
+	hr, err := hash.NewReader(ctx, bytes.NewReader(nil), 0, "", "", 0)
+	if err != nil {
+		return ObjectInfo{}, false, err
+	}
+	oi, err := api.PutObject(ctx, bucket, object, NewPutObjReader(hr), refOpts)
+	if err != nil {
+		return ObjectInfo{}, false, err
+	}
+
+	entry.Refs++
+	entry.UpdatedAt = UTCNow()
+	if err = saveDedupEntry(ctx, api, entry); err != nil {
+		internalLogIf(ctx, err)
+	}
+
+	// Present the reference with the source's content identity.
+	oi.Size = entry.Size
+	oi.ETag = entry.ETag
+	return oi, true, nil
+}
+
+// registerDedupContent indexes a freshly written object as the source
+// data version for its content hash. Best effort - a lost entry only
+// costs future dedup opportunities.
+func registerDedupContent(ctx context.Context, api ObjectLayer, oi ObjectInfo, contentHash string) {
+	if oi.VersionID == "" {
+		// An unversioned write is mutable, never index it as a dedup
+		// source.
+		return
+	}
+	if _, err := loadDedupEntry(ctx, api, oi.Bucket, contentHash); err == nil {
+		// Concurrent upload of the same content won the race.
+		return
+	} else if !errors.Is(err, errConfigNotFound) {
+		internalLogIf(ctx, err)
+		return
+	}
+	now := UTCNow()
+	internalLogIf(ctx, saveDedupEntry(ctx, api, dedupEntry{
+		Hash:      contentHash,
+		Bucket:    oi.Bucket,
+		Object:    oi.Name,
+		VersionID: oi.VersionID,
+		Size:      oi.Size,
+		ETag:      oi.ETag,
+		Refs:      1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}))
+}
+
+// dedupRefSource returns the data version a deduplicated reference
+// points to.
+func dedupRefSource(oi ObjectInfo) (source, versionID string, ok bool) {
+	source = oi.UserDefined[dedupMetaSource]
+	return source, oi.UserDefined[dedupMetaSourceVID], source != ""
+}
+
+// dedupCheckDelete is consulted before permanently deleting a version
+// on a dedup enabled bucket. It refuses to delete a source data
+// version that references still point to, and returns a function to
+// run after a successful delete which maintains the reference count.
+func dedupCheckDelete(ctx context.Context, api ObjectLayer, bucket, object, versionID string) (postDelete func(), err error) {
+	oi, err := api.GetObjectInfo(ctx, bucket, object, ObjectOptions{VersionID: versionID})
+	if err != nil {
+		// Nothing to protect, let the delete surface its own error.
+		return nil, nil
+	}
+	contentHash := oi.UserDefined[dedupMetaHash]
+	if contentHash == "" {
+		return nil, nil
+	}
+	entry, err := loadDedupEntry(ctx, api, bucket, contentHash)
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	if _, _, isRef := dedupRefSource(oi); !isRef {
+		// Deleting the source data version: only allowed once no
+		// references remain.
+		if entry.Object == object && entry.VersionID == versionID && entry.Refs > 1 {
+			return nil, errDedupSourceReferenced
+		}
+	}
+
+	return func() {
+		entry, err := loadDedupEntry(ctx, api, bucket, contentHash)
+		if err != nil {
+			return
+		}
+		entry.Refs--
+		entry.UpdatedAt = UTCNow()
+		if entry.Refs <= 0 {
+			internalLogIf(ctx, deleteConfig(ctx, api, dedupIndexPath(bucket, contentHash)))
+			return
+		}
+		internalLogIf(ctx, saveDedupEntry(ctx, api, entry))
+	}, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/minio/minio/internal/config"
+)
+
+// TestDedupSourceSurvivesBulkDelete verifies that a source data version
+// with outstanding deduplicated references survives a bulk delete - the
+// reference check runs in the object layer, covering DeleteObjects
+// callers like the multi-delete handler, ILM and batch expiry.
+func TestDedupSourceSurvivesBulkDelete(t *testing.T) {
+	t.Setenv(EnvContentDedup, config.EnableOn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	obj, fsDirs, err := prepareErasure(ctx, 16)
+	if err != nil {
+		t.Fatal("Unable to initialize 'Erasure' object layer.", err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucketName := "dedup-bucket"
+	objectName := "source"
+	if err = obj.MakeBucket(ctx, bucketName, MakeBucketOptions{
+		VersioningEnabled: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("dedup-content")
+	sum := sha256.Sum256(content)
+	contentHash := hex.EncodeToString(sum[:])
+
+	oi, err := obj.PutObject(ctx, bucketName, objectName,
+		mustGetPutObjReader(t, bytes.NewReader(content), int64(len(content)), "", ""), ObjectOptions{
+			Versioned: true,
+			UserDefined: map[string]string{
+				dedupMetaHash: contentHash,
+			},
+		})
+	if err != nil {
+		t.Fatalf("Erasure Object upload failed: <ERROR> %s", err)
+	}
+	registerDedupContent(ctx, obj, oi, contentHash)
+
+	// A deduplicated reference points at the source.
+	entry, err := loadDedupEntry(ctx, obj, bucketName, contentHash)
+	if err != nil {
+		t.Fatalf("unable to load dedup entry: %v", err)
+	}
+	entry.Refs++
+	if err = saveDedupEntry(ctx, obj, entry); err != nil {
+		t.Fatalf("unable to save dedup entry: %v", err)
+	}
+
+	_, delErrs := obj.DeleteObjects(ctx, bucketName, []ObjectToDelete{
+		{ObjectV: ObjectV{ObjectName: objectName, VersionID: oi.VersionID}},
+	}, ObjectOptions{
+		Versioned: true,
+	})
+	if !errors.Is(delErrs[0], errDedupSourceReferenced) {
+		t.Fatalf("expected errDedupSourceReferenced, got %v", delErrs[0])
+	}
+
+	if _, err = obj.GetObjectInfo(ctx, bucketName, objectName, ObjectOptions{
+		VersionID: oi.VersionID,
+	}); err != nil {
+		t.Fatalf("referenced source did not survive the bulk delete: %v", err)
+	}
+
+	// With the last reference gone the source deletes normally.
+	entry.Refs--
+	if err = saveDedupEntry(ctx, obj, entry); err != nil {
+		t.Fatalf("unable to save dedup entry: %v", err)
+	}
+	_, delErrs = obj.DeleteObjects(ctx, bucketName, []ObjectToDelete{
+		{ObjectV: ObjectV{ObjectName: objectName, VersionID: oi.VersionID}},
+	}, ObjectOptions{
+		Versioned: true,
+	})
+	if delErrs[0] != nil {
+		t.Fatalf("unreferenced source failed to delete: %v", delErrs[0])
+	}
+}
@@ -77,6 +77,8 @@ var supportedHeadGetReqParams = map[string]string{
 const (
 	compressionAlgorithmV1 = "golang/snappy/LZ77"
 	compressionAlgorithmV2 = "klauspost/compress/s2"
+	// Small objects compressed with a per bucket trained dictionary.
+	compressionAlgorithmZstdDict = "klauspost/compress/zstd/dict"
 
 	// When an upload exceeds encryptBufferThreshold ...
 	encryptBufferThreshold = 1 << 20
@@ -356,6 +358,18 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 		return
 	}
 
+	// Requests carrying a server-tracked presigned grant ID are
+	// additionally checked against the grant's revocation status and
+	// download limit.
+	if gid := r.Form.Get(xMinioGrantID); gid != "" {
+		if gerr := usePresignedGrant(ctx, bucket, object, gid); gerr != nil {
+			apiErr := errorCodes.ToAPIErr(ErrAccessDenied)
+			apiErr.Description = gerr.Error()
+			writeErrorResponse(ctx, w, apiErr, r.URL)
+			return
+		}
+	}
+
 	getObjectNInfo := objectAPI.GetObjectNInfo
 
 	// Get request range.
@@ -466,6 +480,20 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 
 	objInfo := gr.ObjInfo
 
+	// Deduplicated references carry no data of their own, serve the
+	// recorded source data version under the reference's identity.
+	if src, srcVID, ok := dedupRefSource(objInfo); ok && !proxy.Proxy {
+		srcGr, srcErr := getObjectNInfo(ctx, bucket, src, rs, r.Header, ObjectOptions{VersionID: srcVID})
+		if srcErr != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, srcErr), r.URL)
+			return
+		}
+		defer srcGr.Close()
+		srcGr.ObjInfo.Name = objInfo.Name
+		srcGr.ObjInfo.VersionID = objInfo.VersionID
+		gr, objInfo = srcGr, srcGr.ObjInfo
+	}
+
 	if !proxy.Proxy { // apply lifecycle rules only for local requests
 		// Automatically remove the object/version if an expiry lifecycle rule can be applied
 		if lc, err := globalLifecycleSys.Get(bucket); err == nil {
@@ -536,8 +564,29 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 
 	setHeadGetRespHeaders(w, r.Form)
 
+	// Transparently decompress stored compressed objects when asked.
+	var src io.Reader = gr
+	if wantsObjectDecompress(r) {
+		if rs != nil || opts.PartNumber > 0 {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+		dr, derr := newObjectDecompressReader(gr, objInfo)
+		if derr != nil {
+			apiErr := errorCodes.ToAPIErr(ErrInvalidRequest)
+			apiErr.Description = derr.Error()
+			writeErrorResponse(ctx, w, apiErr, r.URL)
+			return
+		}
+		defer dr.Close()
+		src = dr
+		// The decompressed length is unknown up front, drop the
+		// Content-Length so the response goes out chunked.
+		w.Header().Del(xhttp.ContentLength)
+	}
+
 	var iw io.Writer
-	iw = w
+	iw = shapeGetWriter(ctx, bucket, objInfo.StorageClass, w)
 
 	statusCodeWritten := false
 	httpWriter := xioutil.WriteOnClose(iw)
@@ -547,7 +596,7 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 	}
 
 	// Write object content to response body
-	if _, err = xioutil.Copy(httpWriter, gr); err != nil {
+	if _, err = xioutil.Copy(httpWriter, src); err != nil {
 		if !httpWriter.HasWritten() && !statusCodeWritten {
 			// write error response only if no data or headers has been written to client yet
 			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
@@ -574,6 +623,8 @@ func (api objectAPIHandlers) getObjectHandler(ctx context.Context, objectAPI Obj
 		UserAgent:    r.UserAgent(),
 		Host:         handlers.GetSourceIP(r),
 	})
+
+	globalATimeTracker.record(bucket, object)
 }
 
 // GetObjectAttributes ...
@@ -960,6 +1011,13 @@ func (api objectAPIHandlers) headObjectHandler(ctx context.Context, objectAPI Ob
 	// Set any additional requested response headers.
 	setHeadGetRespHeaders(w, r.Form)
 
+	// Expose the recorded last-access time when tracking is enabled.
+	if globalATimeTracker != nil {
+		if at := globalATimeTracker.lastAccess(ctx, bucket, object); !at.IsZero() {
+			w.Header().Set(AmzMinioLastAccessTime, at.UTC().Format(http.TimeFormat))
+		}
+	}
+
 	// Successful response.
 	if rs != nil || opts.PartNumber > 0 {
 		w.WriteHeader(http.StatusPartialContent)
@@ -977,6 +1035,8 @@ func (api objectAPIHandlers) headObjectHandler(ctx context.Context, objectAPI Ob
 		UserAgent:    r.UserAgent(),
 		Host:         handlers.GetSourceIP(r),
 	})
+
+	globalATimeTracker.record(bucket, object)
 }
 
 // GetObjectAttributesHandles - GET Object
@@ -1064,6 +1124,7 @@ func getCpObjMetadataFromHeader(ctx context.Context, r *http.Request, userMeta m
 		if err != nil {
 			return nil, err
 		}
+		addProvenanceMetadata(ctx, r, emetadata)
 		if sc != "" {
 			emetadata[xhttp.AmzStorageClass] = sc
 		}
@@ -1777,6 +1838,12 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		}
 	}
 
+	// Enforce per-bucket encryption requirements if configured.
+	if s3Err := globalBucketEncryptionEnforceSys.checkEncryptionEnforcement(ctx, r, bucket, object); s3Err != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Err), r.URL)
+		return
+	}
+
 	clientETag, err := etag.FromContentMD5(r.Header)
 	if err != nil {
 		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidDigest), r.URL)
@@ -1817,6 +1884,7 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	addProvenanceMetadata(ctx, r, metadata)
 
 	if objTags := r.Header.Get(xhttp.AmzObjectTagging); objTags != "" {
 		if _, err := tags.ParseObjectTags(objTags); err != nil {
@@ -1900,7 +1968,7 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	})
 
 	var reader io.Reader
-	reader = rd
+	reader = shapePutReader(ctx, bucket, r.Header.Get(xhttp.AmzStorageClass), rd)
 
 	var opts ObjectOptions
 	opts, err = putOptsFromReq(ctx, r, bucket, object, metadata)
@@ -1913,7 +1981,6 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	var idxCb func() []byte
 	if isCompressible(r.Header, object) && size > minCompressibleSize {
 		// Storing the compression metadata.
-		metadata[ReservedMetadataPrefix+"compression"] = compressionAlgorithmV2
 		metadata[ReservedMetadataPrefix+"actual-size"] = strconv.FormatInt(size, 10)
 
 		actualReader, err := hash.NewReader(ctx, reader, size, md5hex, sha256hex, actualSize)
@@ -1928,12 +1995,26 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		opts.WantChecksum = actualReader.Checksum()
 
 		// Set compression metrics.
-		var s2c io.ReadCloser
 		wantEncryption := crypto.Requested(r.Header)
-		s2c, idxCb = newS2CompressReader(actualReader, actualSize, wantEncryption)
-		defer s2c.Close()
+		if dict := lookupZstdDict(ctx, bucket); dict != nil && !wantEncryption && size <= compDictMaxObjectSize {
+			// Small objects compress through the bucket's trained
+			// dictionary for better ratios.
+			metadata[ReservedMetadataPrefix+"compression"] = compressionAlgorithmZstdDict
+			metadata[ReservedMetadataPrefix+"compression-dict"] = dict.ID
+			zc, zerr := newZstdDictCompressReader(actualReader, actualSize, dict)
+			if zerr != nil {
+				writeErrorResponse(ctx, w, toAPIError(ctx, zerr), r.URL)
+				return
+			}
+			reader = etag.Wrap(zc, actualReader)
+		} else {
+			metadata[ReservedMetadataPrefix+"compression"] = compressionAlgorithmV2
+			var s2c io.ReadCloser
+			s2c, idxCb = newS2CompressReader(actualReader, actualSize, wantEncryption)
+			defer s2c.Close()
+			reader = etag.Wrap(s2c, actualReader)
+		}
 
-		reader = etag.Wrap(s2c, actualReader)
 		size = -1   // Since compressed size is un-predictable.
 		md5hex = "" // Do not try to verify the content.
 		sha256hex = ""
@@ -2060,6 +2141,40 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		}
 	}
 
+	// Content dedup: identical content already stored in this bucket is
+	// recorded as a metadata reference instead of being written again.
+	// Restricted to versioned writes - without versioning the source
+	// could be overwritten or deleted in place underneath its
+	// references.
+	var dedupHash string
+	var dedupOK bool
+	if _, encRequested := crypto.IsRequested(r.Header); contentDedupEnabled() && !encRequested && opts.Versioned && size > 0 {
+		dedupHash, dedupOK = dedupContentHash(r.Header)
+	}
+	if dedupOK {
+		refInfo, done, derr := tryDedupPutObject(ctx, objectAPI, bucket, object, dedupHash, size, pReader, opts)
+		if derr != nil {
+			writeErrorResponse(ctx, w, toAPIError(ctx, derr), r.URL)
+			return
+		}
+		if done {
+			setPutObjHeaders(w, refInfo, false, r.Header)
+			writeSuccessResponseHeadersOnly(w)
+
+			// Notify object created event.
+			sendEvent(eventArgs{
+				EventName:    event.ObjectCreatedPut,
+				BucketName:   bucket,
+				Object:       refInfo,
+				ReqParams:    extractReqParams(r),
+				RespElements: extractRespElements(w),
+				UserAgent:    r.UserAgent(),
+				Host:         handlers.GetSourceIP(r),
+			})
+			return
+		}
+	}
+
 	// Create the object..
 	objInfo, err := putObject(ctx, bucket, object, pReader, opts)
 	if err != nil {
@@ -2067,6 +2182,11 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if dedupOK {
+		// First copy of this content, index it for future dedup.
+		registerDedupContent(ctx, objectAPI, objInfo, dedupHash)
+	}
+
 	if r.Header.Get(xMinIOExtract) == "true" && HasSuffix(object, archiveExt) {
 		opts := ObjectOptions{VersionID: objInfo.VersionID, MTime: objInfo.ModTime}
 		if _, err := updateObjectMetadataWithZipInfo(ctx, objectAPI, bucket, object, opts); err != nil {
@@ -2572,6 +2692,24 @@ func (api objectAPIHandlers) DeleteObjectHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	// Soft delete protection: plain deletes only stack a delete marker,
+	// removing a specific version permanently requires the governance
+	// bypass permission. Replica deletes carry the source's decision.
+	if !replica && bucketSoftDeleteEnabled(ctx, bucket) {
+		if opts.DeletePrefix {
+			apiErr := toAPIError(ctx, errInvalidArgument)
+			apiErr.Description = "force-delete is forbidden on soft delete protected buckets"
+			writeErrorResponse(ctx, w, apiErr, r.URL)
+			return
+		}
+		if opts.VersionID == "" {
+			opts.Versioned = true
+		} else if s3Error := checkRequestAuthType(ctx, r, policy.BypassGovernanceRetentionAction, bucket, object); s3Error != ErrNone {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+			return
+		}
+	}
+
 	os := newObjSweeper(bucket, object).WithVersion(opts.VersionID).WithVersioning(opts.Versioned, opts.VersionSuspended)
 
 	opts.SetEvalMetadataFn(func(oi *ObjectInfo, gerr error) (dsc ReplicateDecision, err error) {
@@ -3535,3 +3673,93 @@ func (api objectAPIHandlers) PostRestoreObjectHandler(w http.ResponseWriter, r *
 		})
 	}()
 }
+
+// PostRotateObjectSSECKeyHandler - POST rotate SSE-C key handler.
+// ----------
+// MinIO extension API to rotate the SSE-C key of an object in place. The
+// current key is supplied through the SSE-C copy-source headers and the
+// new key through the regular SSE-C headers, exactly like a CopyObject
+// key rotation, but only the sealed object encryption key is re-wrapped
+// under the new key - object data is never read or copied, so rotation
+// cost does not grow with object size.
+func (api objectAPIHandlers) PostRotateObjectSSECKeyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "PostRotateObjectSSECKey")
+
+	defer logger.AuditLog(ctx, w, r, mustGetClaimsFromToken(r))
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if s3Err := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, object); s3Err != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Err), r.URL)
+		return
+	}
+
+	// Both the current and the new customer key must be present.
+	if !crypto.SSECopy.IsRequested(r.Header) || !crypto.SSEC.IsRequested(r.Header) {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidSSECustomerParameters), r.URL)
+		return
+	}
+
+	newKey, err := ParseSSECustomerRequest(r)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	opts, err := getOpts(ctx, r, bucket, object)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	popts := ObjectOptions{
+		MTime:     opts.MTime,
+		VersionID: opts.VersionID,
+		EvalMetadataFn: func(oi *ObjectInfo, gerr error) (ReplicateDecision, error) {
+			var dsc ReplicateDecision
+			if gerr != nil {
+				return dsc, gerr
+			}
+			if oi.DeleteMarker {
+				return dsc, toObjectErr(errMethodNotAllowed, bucket, object)
+			}
+			if kind, _ := crypto.IsEncrypted(oi.UserDefined); kind != crypto.SSEC {
+				return dsc, errInvalidEncryptionParameters
+			}
+			oldKey, perr := ParseSSECopyCustomerRequest(r.Header, oi.UserDefined)
+			if perr != nil {
+				return dsc, perr
+			}
+			// Unseals the object key with the old customer key and
+			// re-seals it with the new one, updating the metadata
+			// in place.
+			return dsc, rotateKey(ctx, oldKey, "", newKey, bucket, object, oi.UserDefined, nil)
+		},
+	}
+
+	objInfo, err := objectAPI.PutObjectMetadata(ctx, bucket, object, popts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	if objInfo.VersionID != "" && objInfo.VersionID != nullVersionID {
+		w.Header()[xhttp.AmzVersionID] = []string{objInfo.VersionID}
+	}
+	w.Header().Set(xhttp.AmzServerSideEncryptionCustomerAlgorithm, r.Header.Get(xhttp.AmzServerSideEncryptionCustomerAlgorithm))
+	w.Header().Set(xhttp.AmzServerSideEncryptionCustomerKeyMD5, r.Header.Get(xhttp.AmzServerSideEncryptionCustomerKeyMD5))
+
+	writeSuccessResponseHeadersOnly(w)
+}
@@ -91,6 +91,18 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		AutoEncrypt: globalAutoEncryption,
 	})
 
+	// Enforce per-bucket encryption requirements if configured.
+	if s3Err := globalBucketEncryptionEnforceSys.checkEncryptionEnforcement(ctx, r, bucket, object); s3Err != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Err), r.URL)
+		return
+	}
+
+	// Enforce per-bucket multipart upload quota if configured.
+	if s3Err := enforceMultipartQuota(ctx, objectAPI, bucket); s3Err != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Err), r.URL)
+		return
+	}
+
 	// Validate storage class metadata if present
 	if sc := r.Header.Get(xhttp.AmzStorageClass); sc != "" {
 		if !storageclass.IsValid(sc) {
@@ -147,6 +159,7 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	addProvenanceMetadata(ctx, r, metadata)
 
 	if objTags := r.Header.Get(xhttp.AmzObjectTagging); objTags != "" {
 		if _, err := tags.ParseObjectTags(objTags); err != nil {
@@ -936,6 +949,12 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 		return
 	}
 
+	// Abort the upload if it exceeded the bucket's part retention age.
+	if s3Err := enforceMultipartRetention(ctx, objectAPI, bucket, object, uploadID); s3Err != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Err), r.URL)
+		return
+	}
+
 	// Content-Length is required and should be non-zero
 	if r.ContentLength <= 0 {
 		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrMissingPart), r.URL)
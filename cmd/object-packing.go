@@ -0,0 +1,360 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Small-object packing coalesces many small objects under a prefix into
+// shared erasure-coded containers, cutting the per-object inode and
+// xl.meta overhead for workloads with very large numbers of tiny files.
+// Packed objects are replaced by zero-byte stubs whose metadata points
+// at the container and offset; GET and HEAD resolve the stubs
+// transparently. Packing is an explicit admin operation and races with
+// concurrent overwrites of the objects being packed, so it should be
+// run against quiesced prefixes.
+const (
+	// packContainerPrefix is where containers and their indexes are
+	// stored inside the metadata bucket.
+	packContainerPrefix = "pack"
+
+	// packSmallObjectThreshold is the default size below which objects
+	// are eligible for packing.
+	packSmallObjectThreshold = 128 * humanize.KiByte
+
+	// packContainerTargetSize is the size at which a container is
+	// flushed and a new one is started.
+	packContainerTargetSize = 32 * humanize.MiByte
+)
+
+// Stub metadata keys pointing at the packed location of an object.
+const (
+	packMetaContainer = ReservedMetadataPrefixLower + "pack-container"
+	packMetaOffset    = ReservedMetadataPrefixLower + "pack-offset"
+	packMetaLength    = ReservedMetadataPrefixLower + "pack-length"
+	packMetaETag      = ReservedMetadataPrefixLower + "pack-etag"
+)
+
+// packEntry locates one object inside a container.
+type packEntry struct {
+	Object  string    `json:"object"`
+	ETag    string    `json:"etag"`
+	Offset  int64     `json:"offset"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// packIndex is stored next to each container and describes its
+// contents, so tooling can audit or rewrite containers offline.
+type packIndex struct {
+	Bucket    string      `json:"bucket"`
+	Container string      `json:"container"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Entries   []packEntry `json:"entries"`
+}
+
+// packResult summarizes one packing run.
+type packResult struct {
+	Bucket     string `json:"bucket"`
+	Prefix     string `json:"prefix,omitempty"`
+	Objects    int    `json:"objects"`
+	Containers int    `json:"containers"`
+	Bytes      int64  `json:"bytes"`
+}
+
+// isPackedObject returns whether the object info describes a packing
+// stub.
+func isPackedObject(oi ObjectInfo) bool {
+	return oi.UserDefined[packMetaContainer] != ""
+}
+
+// packable returns whether an object qualifies for packing at all.
+// Encrypted and compressed objects are excluded: GetObjectNInfo
+// transparently decrypts and decompresses, so packing would store the
+// transformed bytes while the stub keeps the crypto and compression
+// metadata.
+func packable(oi ObjectInfo) bool {
+	if _, encrypted := crypto.IsEncrypted(oi.UserDefined); encrypted {
+		return false
+	}
+	return !oi.IsCompressed()
+}
+
+// packedLocation parses the container location out of stub metadata.
+func packedLocation(oi ObjectInfo) (container string, offset, length int64, err error) {
+	container = oi.UserDefined[packMetaContainer]
+	offset, err = strconv.ParseInt(oi.UserDefined[packMetaOffset], 10, 64)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	length, err = strconv.ParseInt(oi.UserDefined[packMetaLength], 10, 64)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return container, offset, length, nil
+}
+
+// packedObjectInfo rewrites a stub's object info to describe the packed
+// object, used by HEAD and anything else inspecting metadata.
+func packedObjectInfo(oi ObjectInfo) ObjectInfo {
+	if !isPackedObject(oi) {
+		return oi
+	}
+	_, _, length, err := packedLocation(oi)
+	if err != nil {
+		return oi
+	}
+	oi.Size = length
+	if etag := oi.UserDefined[packMetaETag]; etag != "" {
+		oi.ETag = etag
+	}
+	return oi
+}
+
+// packedGetObjectNInfo serves a packed object by reading its byte range
+// from the container. The stub reader is consumed only for its object
+// info and closed.
+func (z *erasureServerPools) packedGetObjectNInfo(ctx context.Context, gr *GetObjectReader, rs *HTTPRangeSpec, opts ObjectOptions) (*GetObjectReader, error) {
+	oi := gr.ObjInfo
+	gr.Close()
+
+	container, offset, length, err := packedLocation(oi)
+	if err != nil {
+		return nil, toObjectErr(errFileCorrupt, oi.Bucket, oi.Name)
+	}
+
+	oi = packedObjectInfo(oi)
+
+	start, rlen := int64(0), length
+	if rs != nil {
+		start, rlen, err = rs.GetOffsetLength(length)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rlen == 0 {
+		return NewGetObjectReaderFromReader(bytes.NewReader(nil), oi, opts)
+	}
+
+	crs := &HTTPRangeSpec{Start: offset + start, End: offset + start + rlen - 1}
+	cgr, err := z.GetObjectNInfo(ctx, minioMetaBucket, container, crs, http.Header{}, ObjectOptions{})
+	if err != nil {
+		return nil, toObjectErr(err, oi.Bucket, oi.Name)
+	}
+
+	return NewGetObjectReaderFromReader(io.LimitReader(cgr, rlen), oi, opts, func() {
+		cgr.Close()
+	})
+}
+
+// packObjects packs the objects below the size threshold under the
+// given prefix into containers, replacing them with stubs.
+func packObjects(ctx context.Context, objAPI ObjectLayer, bucket, prefix string, threshold int64) (packResult, error) {
+	result := packResult{Bucket: bucket, Prefix: prefix}
+
+	var buf bytes.Buffer
+	var entries []packEntry
+	var stubs []ObjectOptions
+
+	flush := func() error {
+		if len(entries) == 0 {
+			return nil
+		}
+		container := pathJoin(packContainerPrefix, bucket, mustGetUUID())
+		if err := saveConfigWithOpts(ctx, objAPI, container, buf.Bytes(), ObjectOptions{}); err != nil {
+			return err
+		}
+		index := packIndex{
+			Bucket:    bucket,
+			Container: container,
+			CreatedAt: UTCNow(),
+			Entries:   entries,
+		}
+		data, err := json.Marshal(index)
+		if err != nil {
+			return err
+		}
+		if err = saveConfig(ctx, objAPI, container+".index", data); err != nil {
+			return err
+		}
+		for i, entry := range entries {
+			stubs[i].UserDefined[packMetaContainer] = container
+			hr, err := hash.NewReader(ctx, bytes.NewReader(nil), 0, "", "", 0)
+			if err != nil {
+				return err
+			}
+			if _, err = objAPI.PutObject(ctx, bucket, entry.Object, NewPutObjReader(hr), stubs[i]); err != nil {
+				// The original object is left intact, only the
+				// container holds unused bytes.
+				internalLogIf(ctx, fmt.Errorf("unable to write packing stub for %s/%s: %w", bucket, entry.Object, err))
+				continue
+			}
+			result.Objects++
+			result.Bytes += entry.Size
+		}
+		result.Containers++
+		buf.Reset()
+		entries, stubs = nil, nil
+		return nil
+	}
+
+	marker := ""
+	for {
+		res, err := objAPI.ListObjects(ctx, bucket, prefix, marker, "", maxObjectList)
+		if err != nil {
+			return result, err
+		}
+		for _, obj := range res.Objects {
+			if obj.Size <= 0 || obj.Size >= threshold || strings.HasSuffix(obj.Name, SlashSeparator) {
+				continue
+			}
+			if isPackedObject(obj) || !packable(obj) {
+				continue
+			}
+			gr, err := objAPI.GetObjectNInfo(ctx, bucket, obj.Name, nil, http.Header{}, ObjectOptions{})
+			if err != nil {
+				internalLogIf(ctx, fmt.Errorf("unable to read %s/%s for packing: %w", bucket, obj.Name, err))
+				continue
+			}
+			// Listing metadata may be trimmed, re-check on the full
+			// object info before committing bytes to the container.
+			if isPackedObject(gr.ObjInfo) || !packable(gr.ObjInfo) {
+				gr.Close()
+				continue
+			}
+			offset := int64(buf.Len())
+			n, err := io.Copy(&buf, gr)
+			oi := gr.ObjInfo
+			gr.Close()
+			if err != nil {
+				buf.Truncate(int(offset))
+				internalLogIf(ctx, fmt.Errorf("unable to read %s/%s for packing: %w", bucket, obj.Name, err))
+				continue
+			}
+
+			md := make(map[string]string, len(oi.UserDefined)+5)
+			for k, v := range oi.UserDefined {
+				md[k] = v
+			}
+			if oi.ContentType != "" {
+				md["content-type"] = oi.ContentType
+			}
+			md[packMetaOffset] = strconv.FormatInt(offset, 10)
+			md[packMetaLength] = strconv.FormatInt(n, 10)
+			md[packMetaETag] = oi.ETag
+
+			entries = append(entries, packEntry{
+				Object:  obj.Name,
+				ETag:    oi.ETag,
+				Offset:  offset,
+				Size:    n,
+				ModTime: oi.ModTime,
+			})
+			stubs = append(stubs, ObjectOptions{UserDefined: md})
+
+			if buf.Len() >= packContainerTargetSize {
+				if err := flush(); err != nil {
+					return result, err
+				}
+			}
+		}
+		if !res.IsTruncated {
+			break
+		}
+		marker = res.NextMarker
+	}
+
+	return result, flush()
+}
+
+// PackObjectsHandler - packs the small objects under a prefix into
+// shared containers. Only unversioned buckets are supported, packing
+// replaces objects in place.
+//
+// POST /minio/admin/v3/pack-objects?bucket=...&prefix=...&threshold=...
+func (a adminAPIHandlers) PackObjectsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.RebalanceAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	if bucket == "" {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if globalBucketVersioningSys.Enabled(bucket) {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+
+	threshold := int64(packSmallObjectThreshold)
+	if s := r.Form.Get("threshold"); s != "" {
+		sz, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || sz <= 0 {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+			return
+		}
+		threshold = sz
+	}
+
+	prefix := r.Form.Get("prefix")
+	result, err := packObjects(ctx, objectAPI, bucket, prefix, threshold)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:   "pack-objects",
+		APIName: "PackObjects",
+		Bucket:  bucket,
+		Object:  prefix,
+		Tags: map[string]string{
+			"objects":    strconv.Itoa(result.Objects),
+			"containers": strconv.Itoa(result.Containers),
+		},
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
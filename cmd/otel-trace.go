@@ -0,0 +1,332 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/env"
+)
+
+const (
+	// EnvOTelTraceEndpoint is the OTLP/HTTP traces endpoint spans are
+	// exported to (e.g. http://collector:4318/v1/traces). Empty
+	// disables OpenTelemetry trace export.
+	EnvOTelTraceEndpoint = "MINIO_OTEL_TRACE_ENDPOINT"
+
+	// EnvOTelTraceSample is the sampling ratio (0.0 to 1.0) applied to
+	// spans without an upstream sampling decision, defaults to 1.0.
+	EnvOTelTraceSample = "MINIO_OTEL_TRACE_SAMPLE"
+
+	// EnvOTelServiceName overrides the service.name resource
+	// attribute, defaults to "minio".
+	EnvOTelServiceName = "MINIO_OTEL_SERVICE_NAME"
+
+	otelTraceFlushInterval = 5 * time.Second
+	otelTraceBatchSize     = 512
+	otelTraceQueueSize     = 8192
+)
+
+var (
+	otelTraceOnce     sync.Once
+	otelTraceEndpoint string
+	otelTraceSample   float64
+	otelServiceName   string
+)
+
+func otelTraceConfig() (endpoint string, sample float64, service string) {
+	otelTraceOnce.Do(func() {
+		otelTraceEndpoint = env.Get(EnvOTelTraceEndpoint, "")
+		otelTraceSample = 1.0
+		if v := env.Get(EnvOTelTraceSample, ""); v != "" {
+			if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+				otelTraceSample = f
+			}
+		}
+		otelServiceName = env.Get(EnvOTelServiceName, "minio")
+	})
+	return otelTraceEndpoint, otelTraceSample, otelServiceName
+}
+
+// otelSpan is one span in OTLP/JSON encoding.
+type otelSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otelAttribute `json:"attributes,omitempty"`
+	Status            *otelStatus     `json:"status,omitempty"`
+}
+
+type otelAttribute struct {
+	Key   string        `json:"key"`
+	Value otelAttrValue `json:"value"`
+}
+
+type otelAttrValue struct {
+	StringValue string `json:"stringValue,omitempty"`
+	IntValue    string `json:"intValue,omitempty"`
+}
+
+type otelStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// OTLP span kinds.
+const (
+	otelSpanKindInternal = 1
+	otelSpanKindServer   = 2
+	otelSpanKindClient   = 3
+)
+
+// OTLP status codes.
+const otelStatusError = 2
+
+func otelStringAttr(key, value string) otelAttribute {
+	return otelAttribute{Key: key, Value: otelAttrValue{StringValue: value}}
+}
+
+func otelIntAttr(key string, value int64) otelAttribute {
+	return otelAttribute{Key: key, Value: otelAttrValue{IntValue: strconv.FormatInt(value, 10)}}
+}
+
+func otelRandomID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// otelSampleHit rolls the sampling dice for spans without an upstream
+// sampling decision.
+func otelSampleHit(ratio float64) bool {
+	var b [8]byte
+	rand.Read(b[:])
+	return float64(binary.BigEndian.Uint64(b[:]))/float64(math.MaxUint64) < ratio
+}
+
+// otelTraceTypeName maps an admin trace type to a span attribute value.
+func otelTraceTypeName(t madmin.TraceType) string {
+	switch {
+	case t.Overlaps(madmin.TraceS3):
+		return "s3"
+	case t.Overlaps(madmin.TraceInternal):
+		return "internal"
+	case t.Overlaps(madmin.TraceStorage):
+		return "storage"
+	}
+	return "other"
+}
+
+// parseTraceparent extracts trace-id, parent span-id and the sampled
+// flag from a W3C traceparent header value.
+func parseTraceparent(v string) (traceID, parentID string, sampled, ok bool) {
+	fields := strings.Split(v, "-")
+	if len(fields) != 4 || len(fields[1]) != 32 || len(fields[2]) != 16 {
+		return "", "", false, false
+	}
+	return fields[1], fields[2], strings.HasSuffix(fields[3], "1"), true
+}
+
+// otelSpanFromTrace converts one admin trace entry into an OTLP span.
+// HTTP spans honor an incoming traceparent header so requests correlate
+// with upstream spans, all other trace types start their own trace.
+func otelSpanFromTrace(ti madmin.TraceInfo) otelSpan {
+	span := otelSpan{
+		TraceID:           otelRandomID(16),
+		SpanID:            otelRandomID(8),
+		Name:              ti.FuncName,
+		Kind:              otelSpanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(ti.Time.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(ti.Time.Add(ti.Duration).UnixNano(), 10),
+		Attributes: []otelAttribute{
+			otelStringAttr("minio.trace.type", otelTraceTypeName(ti.TraceType)),
+			otelStringAttr("server.address", ti.NodeName),
+		},
+	}
+	if ti.Path != "" {
+		span.Attributes = append(span.Attributes, otelStringAttr("minio.path", ti.Path))
+	}
+	if ti.Bytes > 0 {
+		span.Attributes = append(span.Attributes, otelIntAttr("minio.bytes", ti.Bytes))
+	}
+	if ti.Error != "" {
+		span.Status = &otelStatus{Code: otelStatusError, Message: ti.Error}
+	}
+
+	if ti.TraceType.Overlaps(madmin.TraceS3 | madmin.TraceInternal) {
+		span.Kind = otelSpanKindServer
+	}
+
+	if ti.HTTP != nil {
+		span.Attributes = append(span.Attributes,
+			otelStringAttr("http.request.method", ti.HTTP.ReqInfo.Method),
+			otelStringAttr("url.path", ti.HTTP.ReqInfo.Path),
+			otelStringAttr("client.address", ti.HTTP.ReqInfo.Client),
+			otelIntAttr("http.response.status_code", int64(ti.HTTP.RespInfo.StatusCode)),
+		)
+		if tp := ti.HTTP.ReqInfo.Headers.Get("Traceparent"); tp != "" {
+			if traceID, parentID, _, ok := parseTraceparent(tp); ok {
+				span.TraceID = traceID
+				span.ParentSpanID = parentID
+			}
+		}
+	}
+	return span
+}
+
+// otelTraceExporter subscribes to the global trace pubsub and exports
+// spans to the configured OTLP endpoint in batches.
+type otelTraceExporter struct {
+	endpoint string
+	service  string
+	client   *http.Client
+
+	mu    sync.Mutex
+	spans []otelSpan
+}
+
+func (e *otelTraceExporter) add(span otelSpan) {
+	e.mu.Lock()
+	e.spans = append(e.spans, span)
+	flush := len(e.spans) >= otelTraceBatchSize
+	e.mu.Unlock()
+	if flush {
+		e.flush(context.Background())
+	}
+}
+
+// flush posts all pending spans as a single OTLP/JSON request.
+func (e *otelTraceExporter) flush(ctx context.Context) {
+	e.mu.Lock()
+	spans := e.spans
+	e.spans = nil
+	e.mu.Unlock()
+	if len(spans) == 0 {
+		return
+	}
+
+	payload := map[string]interface{}{
+		"resourceSpans": []map[string]interface{}{{
+			"resource": map[string]interface{}{
+				"attributes": []otelAttribute{
+					otelStringAttr("service.name", e.service),
+					otelStringAttr("service.instance.id", globalLocalNodeName),
+				},
+			},
+			"scopeSpans": []map[string]interface{}{{
+				"scope": map[string]interface{}{"name": "minio"},
+				"spans": spans,
+			}},
+		}},
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		bugLogIf(ctx, err)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(data))
+	if err != nil {
+		internalLogIf(ctx, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		internalLogOnceIf(ctx, fmt.Errorf("unable to export traces to %s: %w", e.endpoint, err), "otel-trace-export")
+		return
+	}
+	xhttp.DrainBody(resp.Body)
+}
+
+// initOTelTraceExporter starts OTLP trace export when an endpoint is
+// configured. Spans cover the S3 API handlers, object layer internals,
+// per-drive storage calls and inter-node RPC as seen by the admin trace
+// subsystem.
+func initOTelTraceExporter(ctx context.Context) {
+	endpoint, sample, service := otelTraceConfig()
+	if endpoint == "" {
+		return
+	}
+
+	e := &otelTraceExporter{
+		endpoint: endpoint,
+		service:  service,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: globalRemoteTargetTransport,
+		},
+	}
+
+	traceCh := make(chan madmin.TraceInfo, otelTraceQueueSize)
+	mask := madmin.TraceS3 | madmin.TraceInternal | madmin.TraceStorage
+	err := globalTrace.Subscribe(mask, traceCh, ctx.Done(), func(entry madmin.TraceInfo) bool {
+		if sample >= 1 {
+			return true
+		}
+		if entry.HTTP != nil {
+			// Honor an upstream sampling decision, if present.
+			if tp := entry.HTTP.ReqInfo.Headers.Get("Traceparent"); tp != "" {
+				if _, _, sampled, ok := parseTraceparent(tp); ok {
+					return sampled
+				}
+			}
+		}
+		return otelSampleHit(sample)
+	})
+	if err != nil {
+		internalLogIf(ctx, err)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(otelTraceFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				e.flush(context.Background())
+				return
+			case ti := <-traceCh:
+				e.add(otelSpanFromTrace(ti))
+			case <-ticker.C:
+				e.flush(ctx)
+			}
+		}
+	}()
+}
@@ -0,0 +1,352 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config/storageclass"
+	"github.com/minio/minio/internal/hash"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// parityUpgradeBucketStatus tracks the progress of the parity upgrade
+// job within a single bucket.
+type parityUpgradeBucketStatus struct {
+	Scanned  uint64 `json:"scanned"`
+	Upgraded uint64 `json:"upgraded"`
+	Failed   uint64 `json:"failed"`
+	Bytes    uint64 `json:"bytes"`
+	Complete bool   `json:"complete"`
+}
+
+// parityUpgradeStatus is the overall state of the parity upgrade job,
+// rewriting objects stored with reduced parity at standard parity.
+type parityUpgradeStatus struct {
+	StartTime    time.Time                             `json:"startTime"`
+	TargetParity int                                   `json:"targetParity"`
+	Buckets      map[string]*parityUpgradeBucketStatus `json:"buckets"`
+	Complete     bool                                  `json:"complete"`
+	Error        string                                `json:"error,omitempty"`
+	cancel       context.CancelFunc
+}
+
+// parityUpgradeState holds the single parity upgrade job allowed to run
+// at a time on this cluster.
+type parityUpgradeState struct {
+	mu  sync.Mutex
+	job *parityUpgradeStatus
+}
+
+var globalParityUpgrade = &parityUpgradeState{}
+
+var errParityUpgradeRunning = errors.New("a parity upgrade job is already in progress")
+
+// standardParityFor returns the parity count objects should be upgraded
+// to, from the configured standard storage class falling back to the
+// default parity of the first pool.
+func standardParityFor(z *erasureServerPools) int {
+	if p := globalStorageClass.GetParityForSC(storageclass.STANDARD); p >= 0 {
+		return p
+	}
+	return z.serverPools[0].defaultParityCount
+}
+
+// startParityUpgrade begins rewriting reduced redundancy objects at
+// standard parity in the background. An empty bucket upgrades all
+// buckets.
+func (s *parityUpgradeState) start(z *erasureServerPools, bucket string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.job != nil && !s.job.Complete {
+		return errParityUpgradeRunning
+	}
+
+	ctx, cancel := context.WithCancel(GlobalContext)
+	job := &parityUpgradeStatus{
+		StartTime:    time.Now().UTC(),
+		TargetParity: standardParityFor(z),
+		Buckets:      make(map[string]*parityUpgradeBucketStatus),
+		cancel:       cancel,
+	}
+	s.job = job
+
+	go func() {
+		err := z.upgradeParity(ctx, bucket, job)
+		s.mu.Lock()
+		job.Complete = true
+		if err != nil {
+			job.Error = err.Error()
+		}
+		s.mu.Unlock()
+		cancel()
+	}()
+	return nil
+}
+
+// upgradeParity walks the given bucket (or all buckets) and rewrites
+// every object version stored with less than the target parity.
+func (z *erasureServerPools) upgradeParity(ctx context.Context, bucket string, job *parityUpgradeStatus) error {
+	var names []string
+	if bucket != "" {
+		names = []string{bucket}
+	} else {
+		buckets, err := z.ListBuckets(ctx, BucketOptions{})
+		if err != nil {
+			return err
+		}
+		for _, bi := range buckets {
+			if !isMinioMetaBucketName(bi.Name) {
+				names = append(names, bi.Name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		if err := z.upgradeBucketParity(ctx, name, job); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+func (z *erasureServerPools) upgradeBucketParity(ctx context.Context, bucket string, job *parityUpgradeStatus) error {
+	bs := &parityUpgradeBucketStatus{}
+	globalParityUpgrade.mu.Lock()
+	job.Buckets[bucket] = bs
+	globalParityUpgrade.mu.Unlock()
+
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	err := z.Walk(wctx, bucket, "", results, WalkOptions{
+		Filter: func(fi FileInfo) bool {
+			return !fi.Deleted && fi.Erasure.ParityBlocks > 0 && fi.Erasure.ParityBlocks < job.TargetParity
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for res := range results {
+		if res.Err != nil {
+			return res.Err
+		}
+		oi := res.Item
+
+		globalParityUpgrade.mu.Lock()
+		bs.Scanned++
+		globalParityUpgrade.mu.Unlock()
+
+		if err := z.upgradeObjectParity(ctx, bucket, oi); err != nil {
+			batchLogIf(ctx, fmt.Errorf("parity upgrade of %s/%s (%s) failed: %w", bucket, oi.Name, oi.VersionID, err))
+			globalParityUpgrade.mu.Lock()
+			bs.Failed++
+			globalParityUpgrade.mu.Unlock()
+			continue
+		}
+
+		globalParityUpgrade.mu.Lock()
+		bs.Upgraded++
+		bs.Bytes += uint64(oi.Size)
+		globalParityUpgrade.mu.Unlock()
+	}
+
+	globalParityUpgrade.mu.Lock()
+	bs.Complete = true
+	globalParityUpgrade.mu.Unlock()
+	return ctx.Err()
+}
+
+// upgradeObjectParity rewrites one object version in place at standard
+// parity, dropping any reduced redundancy storage class marker so the
+// rewrite picks up the standard parity configuration.
+func (z *erasureServerPools) upgradeObjectParity(ctx context.Context, bucket string, oi ObjectInfo) (err error) {
+	gr, err := z.GetObjectNInfo(ctx, bucket, oi.Name, nil, http.Header{}, ObjectOptions{
+		VersionID:    oi.VersionID,
+		NoDecryption: true,
+		NoLock:       true,
+		NoAuditLog:   true,
+	})
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	oi = gr.ObjInfo
+
+	actualSize, err := oi.GetActualSize()
+	if err != nil {
+		return err
+	}
+
+	userDefined := make(map[string]string, len(oi.UserDefined))
+	for k, v := range oi.UserDefined {
+		userDefined[k] = v
+	}
+	delete(userDefined, xhttp.AmzStorageClass)
+
+	if oi.isMultipart() {
+		res, err := z.NewMultipartUpload(ctx, bucket, oi.Name, ObjectOptions{
+			VersionID:   oi.VersionID,
+			UserDefined: userDefined,
+			NoAuditLog:  true,
+		})
+		if err != nil {
+			return fmt.Errorf("upgradeObjectParity: NewMultipartUpload() %w", err)
+		}
+		defer z.AbortMultipartUpload(ctx, bucket, oi.Name, res.UploadID, ObjectOptions{NoAuditLog: true})
+
+		parts := make([]CompletePart, len(oi.Parts))
+		for i, part := range oi.Parts {
+			hr, err := hash.NewReader(ctx, io.LimitReader(gr, part.Size), part.Size, "", "", part.ActualSize)
+			if err != nil {
+				return fmt.Errorf("upgradeObjectParity: hash.NewReader() %w", err)
+			}
+			pi, err := z.PutObjectPart(ctx, bucket, oi.Name, res.UploadID,
+				part.Number,
+				NewPutObjReader(hr),
+				ObjectOptions{
+					PreserveETag: part.ETag, // Preserve original ETag to ensure same metadata.
+					IndexCB: func() []byte {
+						return part.Index // Preserve part Index to ensure decompression works.
+					},
+					NoAuditLog: true,
+				})
+			if err != nil {
+				return fmt.Errorf("upgradeObjectParity: PutObjectPart() %w", err)
+			}
+			parts[i] = CompletePart{
+				ETag:       pi.ETag,
+				PartNumber: pi.PartNumber,
+			}
+		}
+		_, err = z.CompleteMultipartUpload(ctx, bucket, oi.Name, res.UploadID, parts, ObjectOptions{
+			MTime:      oi.ModTime,
+			NoAuditLog: true,
+		})
+		if err != nil {
+			err = fmt.Errorf("upgradeObjectParity: CompleteMultipartUpload() %w", err)
+		}
+		return err
+	}
+
+	hr, err := hash.NewReader(ctx, gr, oi.Size, "", "", actualSize)
+	if err != nil {
+		return fmt.Errorf("upgradeObjectParity: hash.NewReader() %w", err)
+	}
+
+	_, err = z.PutObject(ctx,
+		bucket,
+		oi.Name,
+		NewPutObjReader(hr),
+		ObjectOptions{
+			VersionID:    oi.VersionID,
+			MTime:        oi.ModTime,
+			UserDefined:  userDefined,
+			PreserveETag: oi.ETag, // Preserve original ETag to ensure same metadata.
+			IndexCB: func() []byte {
+				return oi.Parts[0].Index // Preserve part Index to ensure decompression works.
+			},
+			NoAuditLog: true,
+		})
+	if err != nil {
+		err = fmt.Errorf("upgradeObjectParity: PutObject() %w", err)
+	}
+	return err
+}
+
+// StartParityUpgradeHandler - starts a job rewriting reduced redundancy
+// objects at standard parity, optionally restricted to one bucket.
+func (a adminAPIHandlers) StartParityUpgradeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+	if bucket != "" {
+		if _, err := z.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+	}
+
+	if err := globalParityUpgrade.start(z, bucket); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// ParityUpgradeStatusHandler - returns per-bucket progress of the
+// running or last finished parity upgrade job.
+func (a adminAPIHandlers) ParityUpgradeStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	globalParityUpgrade.mu.Lock()
+	statusData, err := json.Marshal(globalParityUpgrade.job)
+	globalParityUpgrade.mu.Unlock()
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, statusData)
+}
+
+// CancelParityUpgradeHandler - cancels a running parity upgrade job.
+func (a adminAPIHandlers) CancelParityUpgradeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.HealAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	globalParityUpgrade.mu.Lock()
+	if globalParityUpgrade.job != nil && globalParityUpgrade.job.cancel != nil {
+		globalParityUpgrade.job.cancel()
+	}
+	globalParityUpgrade.mu.Unlock()
+
+	writeSuccessResponseHeadersOnly(w)
+}
@@ -27,6 +27,7 @@ import (
 	"io"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -754,6 +755,43 @@ func (client *peerRESTClient) SpeedTest(ctx context.Context, opts speedTestOpts)
 	return result, nil
 }
 
+func (client *peerRESTClient) SpeedTestProfile(ctx context.Context, opts speedTestProfileOpts) (SpeedTestProfileResult, error) {
+	sizes := make([]string, 0, len(opts.sizes))
+	for _, size := range opts.sizes {
+		sizes = append(sizes, strconv.Itoa(size))
+	}
+	values := make(url.Values)
+	values.Set(peerRESTSizes, strings.Join(sizes, ","))
+	values.Set(peerRESTReadPerc, strconv.Itoa(opts.readPerc))
+	values.Set(peerRESTListers, strconv.Itoa(opts.listers))
+	values.Set(peerRESTConcurrent, strconv.Itoa(opts.concurrency))
+	values.Set(peerRESTDuration, opts.duration.String())
+	values.Set(peerRESTStorageClass, opts.storageClass)
+	values.Set(peerRESTBucket, opts.bucketName)
+	values.Set(peerRESTEnableSha256, strconv.FormatBool(opts.enableSha256))
+	values.Set(peerRESTAccessKey, opts.creds.AccessKey)
+
+	respBody, err := client.callWithContext(context.Background(), peerRESTMethodSpeedTestProfile, values, nil, -1)
+	if err != nil {
+		return SpeedTestProfileResult{}, err
+	}
+	defer xhttp.DrainBody(respBody)
+	waitReader, err := waitForHTTPResponse(respBody)
+	if err != nil {
+		return SpeedTestProfileResult{}, err
+	}
+
+	var result SpeedTestProfileResult
+	err = gob.NewDecoder(waitReader).Decode(&result)
+	if err != nil {
+		return result, err
+	}
+	if result.Error != "" {
+		return result, errors.New(result.Error)
+	}
+	return result, nil
+}
+
 func (client *peerRESTClient) DriveSpeedTest(ctx context.Context, opts madmin.DriveSpeedTestOpts) (madmin.DriveSpeedTestResult, error) {
 	queryVals := make(url.Values)
 	if opts.Serial {
@@ -20,7 +20,7 @@ package cmd
 import "time"
 
 const (
-	peerRESTVersion       = "v39" // add more flags to speedtest API
+	peerRESTVersion       = "v40" // add speedtest profile API
 	peerRESTVersionPrefix = SlashSeparator + peerRESTVersion
 	peerRESTPrefix        = minioReservedBucketPath + "/peer"
 	peerRESTPath          = peerRESTPrefix + peerRESTVersionPrefix
@@ -33,6 +33,7 @@ const (
 	peerRESTMethodStartProfiling        = "/startprofiling"
 	peerRESTMethodDownloadProfilingData = "/downloadprofilingdata"
 	peerRESTMethodSpeedTest             = "/speedtest"
+	peerRESTMethodSpeedTestProfile      = "/speedtestprofile"
 	peerRESTMethodDriveSpeedTest        = "/drivespeedtest"
 	peerRESTMethodDevNull               = "/devnull"
 	peerRESTMethodNetperf               = "/netperf"
@@ -58,6 +59,9 @@ const (
 	peerRESTStorageClass    = "storage-class"
 	peerRESTEnableSha256    = "enableSha256"
 	peerRESTEnableMultipart = "enableMultipart"
+	peerRESTSizes           = "sizes"
+	peerRESTReadPerc        = "readPerc"
+	peerRESTListers         = "listers"
 	peerRESTAccessKey       = "access-key"
 	peerRESTMetricsTypes    = "types"
 	peerRESTDisk            = "disk"
@@ -475,6 +475,8 @@ func (s *peerRESTServer) DeleteBucketMetadataHandler(mss *grid.MSS) (np grid.NoP
 	globalBucketMetadataSys.Remove(bucketName)
 	globalBucketTargetSys.Delete(bucketName)
 	globalEventNotifier.RemoveNotification(bucketName)
+	globalBucketListConsistencySys.remove(bucketName)
+	globalBucketSoftDeleteSys.remove(bucketName)
 	globalBucketConnStats.delete(bucketName)
 	globalBucketHTTPStats.delete(bucketName)
 	if localMetacacheMgr != nil {
@@ -547,6 +549,12 @@ func (s *peerRESTServer) LoadBucketMetadataHandler(mss *grid.MSS) (np grid.NoPay
 
 	globalBucketMetadataSys.Set(bucketName, meta)
 
+	// The listing consistency and soft delete configurations live next
+	// to the bucket metadata, drop the cached values so the next lookup
+	// re-reads them.
+	globalBucketListConsistencySys.remove(bucketName)
+	globalBucketSoftDeleteSys.remove(bucketName)
+
 	if meta.notificationConfig != nil {
 		globalEventNotifier.AddRulesMap(bucketName, meta.notificationConfig.ToRulesMap())
 	}
@@ -1141,6 +1149,71 @@ func (s *peerRESTServer) SpeedTestHandler(w http.ResponseWriter, r *http.Request
 	peersLogIf(r.Context(), gob.NewEncoder(w).Encode(result))
 }
 
+func (s *peerRESTServer) SpeedTestProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		s.writeErrorResponse(w, errors.New("invalid request"))
+		return
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		s.writeErrorResponse(w, errServerNotInitialized)
+		return
+	}
+
+	u, ok := globalIAMSys.GetUser(r.Context(), r.Form.Get(peerRESTAccessKey))
+	if !ok {
+		s.writeErrorResponse(w, errAuthentication)
+		return
+	}
+
+	sizes, err := parseProfileSizes(r.Form.Get(peerRESTSizes))
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+
+	readPerc, err := strconv.Atoi(r.Form.Get(peerRESTReadPerc))
+	if err != nil {
+		readPerc = 50
+	}
+
+	listers, err := strconv.Atoi(r.Form.Get(peerRESTListers))
+	if err != nil {
+		listers = 0
+	}
+
+	concurrent, err := strconv.Atoi(r.Form.Get(peerRESTConcurrent))
+	if err != nil {
+		concurrent = 32
+	}
+
+	duration, err := time.ParseDuration(r.Form.Get(peerRESTDuration))
+	if err != nil {
+		duration = time.Second * 10
+	}
+
+	done := keepHTTPResponseAlive(w)
+
+	result, err := selfSpeedTestProfile(r.Context(), speedTestProfileOpts{
+		sizes:        sizes,
+		readPerc:     readPerc,
+		listers:      listers,
+		concurrency:  concurrent,
+		duration:     duration,
+		storageClass: r.Form.Get(peerRESTStorageClass),
+		bucketName:   r.Form.Get(peerRESTBucket),
+		enableSha256: r.Form.Get(peerRESTEnableSha256) == "true",
+		creds:        u.Credentials,
+	})
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	done(nil)
+	peersLogIf(r.Context(), gob.NewEncoder(w).Encode(result))
+}
+
 // GetLastDayTierStatsHandler - returns per-tier stats in the last 24hrs for this server
 func (s *peerRESTServer) GetLastDayTierStatsHandler(_ *grid.MSS) (*DailyAllTierStats, *grid.RemoteErr) {
 	if objAPI := newObjectLayerFn(); objAPI == nil || globalTransitionState == nil {
@@ -1362,6 +1435,7 @@ func registerPeerRESTHandlers(router *mux.Router, gm *grid.Manager) {
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodStartProfiling).HandlerFunc(h(server.StartProfilingHandler)).Queries(restQueries(peerRESTProfiler)...)
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodDownloadProfilingData).HandlerFunc(h(server.DownloadProfilingDataHandler))
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodSpeedTest).HandlerFunc(h(server.SpeedTestHandler))
+	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodSpeedTestProfile).HandlerFunc(h(server.SpeedTestProfileHandler))
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodDriveSpeedTest).HandlerFunc(h(server.DriveSpeedTestHandler))
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodNetperf).HandlerFunc(h(server.NetSpeedTestHandler))
 	subrouter.Methods(http.MethodPost).Path(peerRESTVersionPrefix + peerRESTMethodDevNull).HandlerFunc(h(server.DevNull))
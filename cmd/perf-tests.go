@@ -34,6 +34,7 @@ import (
 	"github.com/minio/madmin-go/v3"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio/internal/auth"
 	xhttp "github.com/minio/minio/internal/http"
 	xioutil "github.com/minio/minio/internal/ioutil"
 	"github.com/minio/pkg/v3/randreader"
@@ -72,6 +73,25 @@ func (f *firstByteRecorder) Read(p []byte) (n int, err error) {
 	return n, err
 }
 
+// speedTestClient returns the S3 client perf runs use against the
+// local node, scoped to the requesting credentials when root access
+// is not permitted.
+func speedTestClient(creds auth.Credentials) (*minio.Client, error) {
+	if globalAPIConfig.permitRootAccess() {
+		return globalMinioClient, nil
+	}
+	region := globalSite.Region()
+	if region == "" {
+		region = "us-east-1"
+	}
+	return minio.New(globalLocalNodeName, &minio.Options{
+		Creds:     credentials.NewStaticV4(creds.AccessKey, creds.SecretKey, creds.SessionToken),
+		Secure:    globalIsTLS,
+		Transport: globalRemoteTargetTransport,
+		Region:    region,
+	})
+}
+
 // Runs the speedtest on local MinIO process.
 func selfSpeedTest(ctx context.Context, opts speedTestOpts) (res SpeedTestResult, err error) {
 	objAPI := newObjectLayerFn()
@@ -100,21 +120,9 @@ func selfSpeedTest(ctx context.Context, opts speedTestOpts) (res SpeedTestResult
 		DisableMultipart:     !opts.enableMultipart,
 	}
 
-	clnt := globalMinioClient
-	if !globalAPIConfig.permitRootAccess() {
-		region := globalSite.Region()
-		if region == "" {
-			region = "us-east-1"
-		}
-		clnt, err = minio.New(globalLocalNodeName, &minio.Options{
-			Creds:     credentials.NewStaticV4(opts.creds.AccessKey, opts.creds.SecretKey, opts.creds.SessionToken),
-			Secure:    globalIsTLS,
-			Transport: globalRemoteTargetTransport,
-			Region:    region,
-		})
-		if err != nil {
-			return res, err
-		}
+	clnt, err := speedTestClient(opts.creds)
+	if err != nil {
+		return res, err
 	}
 
 	var mu sync.Mutex
@@ -0,0 +1,285 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/dustin/go-humanize"
+	xhttp "github.com/minio/minio/internal/http"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Pool placement pins buckets, prefixes or storage classes to specific
+// server pools so mixed-hardware clusters can tier within the cluster,
+// e.g. an NVMe pool for hot data and an HDD pool for archives. Unlike
+// the tag-based pool affinity, placement rules are a hard constraint:
+// new objects only land on the pinned pools, and since rebalance and
+// decommission route their writes through the same pool selection they
+// honor the pins as well.
+const (
+	// poolPlacementConfigFile is where the placement rules are stored.
+	poolPlacementConfigFile = minioConfigPrefix + "/pool-placement.json"
+)
+
+// placementRule pins objects matching bucket, optional prefix and
+// optional storage class to the given pool indices. More specific rules
+// win: a longer matching prefix beats a shorter one, and a storage
+// class match beats a rule without one.
+type placementRule struct {
+	Bucket       string `json:"bucket"`
+	Prefix       string `json:"prefix,omitempty"`
+	StorageClass string `json:"storageClass,omitempty"`
+	Pools        []int  `json:"pools"`
+}
+
+func (rule placementRule) key() string {
+	return rule.Bucket + "|" + rule.Prefix + "|" + rule.StorageClass
+}
+
+// match returns whether the rule applies to the object and how specific
+// the match is, higher being more specific.
+func (rule placementRule) match(bucket, object, storageClass string) (int, bool) {
+	if rule.Bucket != bucket {
+		return 0, false
+	}
+	if rule.Prefix != "" && !strings.HasPrefix(object, rule.Prefix) {
+		return 0, false
+	}
+	if rule.StorageClass != "" && rule.StorageClass != storageClass {
+		return 0, false
+	}
+	specificity := 2 * len(rule.Prefix)
+	if rule.StorageClass != "" {
+		specificity++
+	}
+	return specificity, true
+}
+
+var globalPoolPlacement = struct {
+	mu    sync.RWMutex
+	rules map[string]placementRule
+}{rules: make(map[string]placementRule)}
+
+// initPoolPlacement loads the placement rules, dropping rules that
+// reference pools no longer part of the deployment.
+func initPoolPlacement(ctx context.Context, z *erasureServerPools) {
+	data, err := readConfig(ctx, z, poolPlacementConfigFile)
+	if err != nil {
+		if !errors.Is(err, errConfigNotFound) {
+			internalLogIf(ctx, fmt.Errorf("unable to read pool placement config: %w", err))
+		}
+		return
+	}
+
+	var rules map[string]placementRule
+	if err = json.Unmarshal(data, &rules); err != nil {
+		internalLogIf(ctx, fmt.Errorf("unable to parse pool placement config: %w", err))
+		return
+	}
+
+	for key, rule := range rules {
+		if !validPlacementPools(rule.Pools, len(z.serverPools)) {
+			internalLogIf(ctx, fmt.Errorf("pool placement rule %s references unknown pool, skipping", key))
+			delete(rules, key)
+		}
+	}
+
+	globalPoolPlacement.mu.Lock()
+	globalPoolPlacement.rules = rules
+	globalPoolPlacement.mu.Unlock()
+}
+
+// savePoolPlacement persists the current rule set.
+func savePoolPlacement(ctx context.Context, objAPI ObjectLayer) error {
+	globalPoolPlacement.mu.RLock()
+	data, err := json.Marshal(globalPoolPlacement.rules)
+	globalPoolPlacement.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, objAPI, poolPlacementConfigFile, data)
+}
+
+// validPlacementPools checks the pool indices against the number of
+// configured pools.
+func validPlacementPools(pools []int, poolCount int) bool {
+	if len(pools) == 0 {
+		return false
+	}
+	for _, idx := range pools {
+		if idx < 0 || idx >= poolCount {
+			return false
+		}
+	}
+	return true
+}
+
+// placementStorageClass extracts the storage class from object metadata
+// for rule matching.
+func placementStorageClass(metadata map[string]string) string {
+	return metadata[xhttp.AmzStorageClass]
+}
+
+// placementMatchingPools returns the pinned pool indices of the most
+// specific rule matching the object, or nil when no rule applies.
+func placementMatchingPools(bucket, object, storageClass string) map[int]struct{} {
+	globalPoolPlacement.mu.RLock()
+	defer globalPoolPlacement.mu.RUnlock()
+
+	best, bestSpecificity := placementRule{}, -1
+	for _, rule := range globalPoolPlacement.rules {
+		if specificity, ok := rule.match(bucket, object, storageClass); ok && specificity > bestSpecificity {
+			best, bestSpecificity = rule, specificity
+		}
+	}
+	if bestSpecificity < 0 {
+		return nil
+	}
+
+	pinned := make(map[int]struct{}, len(best.Pools))
+	for _, idx := range best.Pools {
+		pinned[idx] = struct{}{}
+	}
+	return pinned
+}
+
+// FilterByPlacement keeps only the pinned pools. Unlike tag affinity
+// this is a hard constraint - when no pinned pool has space left the
+// write fails rather than spilling onto other pools.
+func (p serverPoolsAvailableSpace) FilterByPlacement(pinned map[int]struct{}) {
+	if len(pinned) == 0 {
+		return
+	}
+	for i, z := range p {
+		if _, found := pinned[z.Index]; found {
+			continue
+		}
+		p[i].Available = 0
+	}
+}
+
+// SetPoolPlacementHandler - creates or updates a placement rule.
+//
+// PUT /minio/admin/v3/pool-placement
+func (a adminAPIHandlers) SetPoolPlacementHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	z, ok := objectAPI.(*erasureServerPools)
+	if !ok {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrNotImplemented), r.URL)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, humanize.MiByte))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	var rule placementRule
+	if err = json.Unmarshal(data, &rule); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if rule.Bucket == "" || !validPlacementPools(rule.Pools, len(z.serverPools)) {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+
+	globalPoolPlacement.mu.Lock()
+	globalPoolPlacement.rules[rule.key()] = rule
+	globalPoolPlacement.mu.Unlock()
+
+	if err = savePoolPlacement(ctx, objectAPI); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// ListPoolPlacementHandler - lists the placement rules.
+//
+// GET /minio/admin/v3/pool-placement
+func (a adminAPIHandlers) ListPoolPlacementHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	globalPoolPlacement.mu.RLock()
+	rules := make([]placementRule, 0, len(globalPoolPlacement.rules))
+	for _, rule := range globalPoolPlacement.rules {
+		rules = append(rules, rule)
+	}
+	globalPoolPlacement.mu.RUnlock()
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// RemovePoolPlacementHandler - removes a placement rule.
+//
+// DELETE /minio/admin/v3/pool-placement?bucket=...&prefix=...&storageClass=...
+func (a adminAPIHandlers) RemovePoolPlacementHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	rule := placementRule{
+		Bucket:       r.Form.Get("bucket"),
+		Prefix:       r.Form.Get("prefix"),
+		StorageClass: r.Form.Get("storageClass"),
+	}
+
+	globalPoolPlacement.mu.Lock()
+	_, ok := globalPoolPlacement.rules[rule.key()]
+	delete(globalPoolPlacement.rules, rule.key())
+	globalPoolPlacement.mu.Unlock()
+	if !ok {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errConfigNotFound), r.URL)
+		return
+	}
+
+	if err := savePoolPlacement(ctx, objectAPI); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseHeadersOnly(w)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/minio/pkg/v3/env"
+)
+
+// EnvPoolTags assigns tags to server pools, e.g. "0=ssd,fast;1=archive".
+// Buckets carrying a bucket tag with key "pool" and a value matching one
+// of the pool tags place new objects preferentially on matching pools.
+const EnvPoolTags = "MINIO_POOL_TAGS"
+
+// poolAffinityTagKey is the bucket tag key expressing pool affinity.
+const poolAffinityTagKey = "pool"
+
+var (
+	poolTagsOnce sync.Once
+	poolTags     map[int][]string
+)
+
+// parsePoolTags parses the MINIO_POOL_TAGS value. Entries are separated
+// by ';', each entry is "<pool-index>=<tag>[,<tag>...]". Malformed
+// entries are skipped.
+func parsePoolTags(s string) map[int][]string {
+	tags := make(map[int][]string)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		idx, err := strconv.Atoi(strings.TrimSpace(kv[0]))
+		if err != nil || idx < 0 {
+			continue
+		}
+		var values []string
+		for _, tag := range strings.Split(kv[1], ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				values = append(values, tag)
+			}
+		}
+		if len(values) > 0 {
+			tags[idx] = values
+		}
+	}
+	return tags
+}
+
+// getPoolTags returns the configured pool tags, parsed once per process.
+func getPoolTags() map[int][]string {
+	poolTagsOnce.Do(func() {
+		if s := env.Get(EnvPoolTags, ""); s != "" {
+			poolTags = parsePoolTags(s)
+		}
+	})
+	return poolTags
+}
+
+// poolsMatchingBucketAffinity returns the set of pool indices whose tags
+// match the bucket's "pool" tag. A nil return means no affinity applies
+// and all pools are eligible.
+func poolsMatchingBucketAffinity(bucket string) map[int]struct{} {
+	tags := getPoolTags()
+	if len(tags) == 0 {
+		return nil
+	}
+
+	tcfg, _, err := globalBucketMetadataSys.GetTaggingConfig(bucket)
+	if err != nil || tcfg == nil {
+		return nil
+	}
+	want := tcfg.ToMap()[poolAffinityTagKey]
+	if want == "" {
+		return nil
+	}
+
+	matching := make(map[int]struct{})
+	for idx, values := range tags {
+		for _, v := range values {
+			if v == want {
+				matching[idx] = struct{}{}
+				break
+			}
+		}
+	}
+	if len(matching) == 0 {
+		return nil
+	}
+	return matching
+}
+
+// FilterByPoolAffinity removes pools not present in the matching set,
+// unless none of the matching pools has space left, in which case all
+// pools are preserved so writes do not fail on affinity alone.
+func (p serverPoolsAvailableSpace) FilterByPoolAffinity(matching map[int]struct{}) {
+	if len(matching) == 0 || len(p) <= 1 {
+		return
+	}
+	var ok bool
+	for _, z := range p {
+		if _, found := matching[z.Index]; found && z.Available > 0 {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		// No matching pool has space, do not modify.
+		return
+	}
+	for i, z := range p {
+		if _, found := matching[z.Index]; found {
+			continue
+		}
+		p[i].Available = 0
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePoolTags(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected map[int][]string
+	}{
+		{"", map[int][]string{}},
+		{"0=ssd", map[int][]string{0: {"ssd"}}},
+		{"0=ssd,fast;1=archive", map[int][]string{0: {"ssd", "fast"}, 1: {"archive"}}},
+		{" 0 = ssd , fast ; 1 = archive ", map[int][]string{0: {"ssd", "fast"}, 1: {"archive"}}},
+		// Malformed entries are skipped.
+		{"0=ssd;bad;x=1;-1=neg;2=", map[int][]string{0: {"ssd"}}},
+	}
+	for i, testCase := range testCases {
+		if got := parsePoolTags(testCase.input); !reflect.DeepEqual(got, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestFilterByPoolAffinity(t *testing.T) {
+	pools := serverPoolsAvailableSpace{
+		{Index: 0, Available: 100},
+		{Index: 1, Available: 200},
+		{Index: 2, Available: 300},
+	}
+	pools.FilterByPoolAffinity(map[int]struct{}{1: {}})
+	if pools[0].Available != 0 || pools[1].Available != 200 || pools[2].Available != 0 {
+		t.Errorf("expected only pool 1 to retain space, got %v", pools)
+	}
+
+	// No matching pool with space - all pools preserved.
+	pools = serverPoolsAvailableSpace{
+		{Index: 0, Available: 100},
+		{Index: 1, Available: 0},
+	}
+	pools.FilterByPoolAffinity(map[int]struct{}{1: {}})
+	if pools[0].Available != 100 {
+		t.Errorf("expected pools to be preserved when no matching pool has space, got %v", pools)
+	}
+}
@@ -0,0 +1,358 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Server-tracked presigned URL grants. Ordinary presigned URLs are
+// stateless - once handed out they cannot be revoked or limited to a
+// number of downloads before their expiry. A grant is a presigned GET
+// URL minted through the admin API with a grant ID folded into the
+// signed query string; the server records the grant and checks it on
+// every download, so a grant can be revoked early or capped to a
+// download count. URLs presigned by clients carry no grant ID and are
+// unaffected.
+
+const (
+	// presignedGrantPrefix is where grants live inside minioMetaBucket.
+	presignedGrantPrefix = ".presigned-grants"
+
+	// xMinioGrantID is the signed query parameter carrying the grant ID.
+	xMinioGrantID = "x-minio-grant"
+
+	// presignedGrantDefaultExpiry applies when no expiry is requested.
+	presignedGrantDefaultExpiry = time.Hour
+
+	// presignedGrantMaxExpiry matches the signature v4 presign limit.
+	presignedGrantMaxExpiry = 7 * 24 * time.Hour
+)
+
+// Grant enforcement errors, surfaced to the client as AccessDenied
+// with the specific reason in the description.
+var (
+	errGrantNotFound  = errors.New("presigned grant does not exist")
+	errGrantRevoked   = errors.New("presigned grant has been revoked")
+	errGrantExpired   = errors.New("presigned grant has expired")
+	errGrantExhausted = errors.New("presigned grant download limit reached")
+	errGrantMismatch  = errors.New("presigned grant does not cover this object")
+)
+
+// presignedGrant is the server side record of one minted URL.
+type presignedGrant struct {
+	ID        string    `json:"id"`
+	Bucket    string    `json:"bucket"`
+	Object    string    `json:"object"`
+	AccessKey string    `json:"accessKey"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// MaxUses caps downloads through this grant, zero is unlimited
+	// and one makes the URL single use.
+	MaxUses int `json:"maxUses,omitempty"`
+	Uses    int `json:"uses"`
+
+	Revoked   bool      `json:"revoked,omitempty"`
+	RevokedBy string    `json:"revokedBy,omitempty"`
+	RevokedAt time.Time `json:"revokedAt,omitempty"`
+}
+
+// active reports whether the grant still admits downloads.
+func (g presignedGrant) active() bool {
+	if g.Revoked || UTCNow().After(g.ExpiresAt) {
+		return false
+	}
+	return g.MaxUses <= 0 || g.Uses < g.MaxUses
+}
+
+// presignedGrantPath returns the grant location relative to
+// minioMetaBucket.
+func presignedGrantPath(id string) string {
+	return pathJoin(presignedGrantPrefix, id+".json")
+}
+
+// loadPresignedGrant reads one grant by ID.
+func loadPresignedGrant(ctx context.Context, api ObjectLayer, id string) (g presignedGrant, err error) {
+	data, err := readConfig(ctx, api, presignedGrantPath(id))
+	if err != nil {
+		if errors.Is(err, errConfigNotFound) {
+			return g, errGrantNotFound
+		}
+		return g, err
+	}
+	err = json.Unmarshal(data, &g)
+	return g, err
+}
+
+// savePresignedGrant persists one grant.
+func savePresignedGrant(ctx context.Context, api ObjectLayer, g presignedGrant) error {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	return saveConfig(ctx, api, presignedGrantPath(g.ID), data)
+}
+
+// usePresignedGrant validates the grant named by a download request
+// and consumes one use. The use is consumed before the response is
+// written, so an interrupted download still counts - erring this way
+// keeps a single-use URL single use even under concurrent replays.
+func usePresignedGrant(ctx context.Context, bucket, object, id string) error {
+	api := newObjectLayerFn()
+	if api == nil {
+		return errServerNotInitialized
+	}
+
+	// Serialize the read-modify-write of the grant so concurrent
+	// replays cannot all pass the MaxUses check.
+	lk := api.NewNSLock(minioMetaBucket, presignedGrantPath(id))
+	lkctx, err := lk.GetLock(ctx, globalOperationTimeout)
+	if err != nil {
+		return err
+	}
+	ctx = lkctx.Context()
+	defer lk.Unlock(lkctx)
+
+	g, err := loadPresignedGrant(ctx, api, id)
+	if err != nil {
+		return err
+	}
+	switch {
+	case g.Bucket != bucket || g.Object != object:
+		return errGrantMismatch
+	case g.Revoked:
+		return errGrantRevoked
+	case UTCNow().After(g.ExpiresAt):
+		return errGrantExpired
+	case g.MaxUses > 0 && g.Uses >= g.MaxUses:
+		return errGrantExhausted
+	}
+	g.Uses++
+	return savePresignedGrant(ctx, api, g)
+}
+
+// MintPresignedGrantHandler - POST /minio/admin/v3/presigned-grant?bucket=&object=
+// Mints a tracked presigned GET URL for an object. Optional query
+// parameters: expiry (seconds, default one hour) and max-uses
+// (downloads admitted, default unlimited, 1 for single use).
+func (a adminAPIHandlers) MintPresignedGrantHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := pathClean(vars["bucket"])
+	object, err := unescapePath(vars["object"])
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if object == "" {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+	if _, err := objectAPI.GetBucketInfo(ctx, bucket, BucketOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	expiry := presignedGrantDefaultExpiry
+	if v := r.Form.Get("expiry"); v != "" {
+		secs, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || secs <= 0 || time.Duration(secs)*time.Second > presignedGrantMaxExpiry {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+		expiry = time.Duration(secs) * time.Second
+	}
+
+	maxUses := 0
+	if v := r.Form.Get("max-uses"); v != "" {
+		if maxUses, err = strconv.Atoi(v); err != nil || maxUses < 0 {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+	}
+
+	grant := presignedGrant{
+		ID:        mustGetUUID(),
+		Bucket:    bucket,
+		Object:    object,
+		AccessKey: cred.AccessKey,
+		CreatedBy: cred.AccessKey,
+		CreatedAt: UTCNow(),
+		ExpiresAt: UTCNow().Add(expiry),
+		MaxUses:   maxUses,
+	}
+
+	// Presign with the grant ID folded into the signed query string,
+	// a request that strips or alters it fails signature verification.
+	q := make(url.Values)
+	q.Set(xMinioGrantID, grant.ID)
+	preq := &http.Request{
+		Method: http.MethodGet,
+		URL: &url.URL{
+			Scheme:   getURLScheme(globalIsTLS),
+			Host:     r.Host,
+			Path:     pathJoin(SlashSeparator, bucket, object),
+			RawQuery: q.Encode(),
+		},
+		Header: http.Header{},
+	}
+	preq.Host = r.Host
+	signed := signer.PreSignV4(*preq, cred.AccessKey, cred.SecretKey, cred.SessionToken, globalSite.Region(), int64(expiry/time.Second))
+
+	if err := savePresignedGrant(ctx, objectAPI, grant); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:   "MintPresignedGrant",
+		APIName: "MintPresignedGrant",
+		Bucket:  bucket,
+		Object:  object,
+		Status:  "success",
+		Tags: map[string]string{
+			"grantId": grant.ID,
+			"maxUses": strconv.Itoa(maxUses),
+		},
+	})
+
+	resp := struct {
+		ID        string    `json:"id"`
+		URL       string    `json:"url"`
+		ExpiresAt time.Time `json:"expiresAt"`
+		MaxUses   int       `json:"maxUses,omitempty"`
+	}{
+		ID:        grant.ID,
+		URL:       signed.URL.String(),
+		ExpiresAt: grant.ExpiresAt,
+		MaxUses:   maxUses,
+	}
+	buf, err := json.Marshal(resp)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
+
+// ListPresignedGrantsHandler - GET /minio/admin/v3/presigned-grants
+// Lists active grants, optionally restricted to one bucket.
+func (a adminAPIHandlers) ListPresignedGrantsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.DataUsageInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	bucket := r.Form.Get("bucket")
+
+	grants := []presignedGrant{}
+	results := make(chan itemOrErr[ObjectInfo], 100)
+	if err := objectAPI.Walk(ctx, minioMetaBucket, presignedGrantPrefix, results, WalkOptions{}); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	for res := range results {
+		if res.Err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, res.Err), r.URL)
+			return
+		}
+		id := strings.TrimSuffix(pathClean(strings.TrimPrefix(res.Item.Name, presignedGrantPrefix)), ".json")
+		g, err := loadPresignedGrant(ctx, objectAPI, id)
+		if err != nil {
+			continue
+		}
+		if !g.active() {
+			continue
+		}
+		if bucket != "" && g.Bucket != bucket {
+			continue
+		}
+		grants = append(grants, g)
+	}
+
+	buf, err := json.Marshal(grants)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, buf)
+}
+
+// RevokePresignedGrantHandler - DELETE /minio/admin/v3/presigned-grant?id=
+// Revokes a grant before its expiry. The record is retained so the
+// revocation remains visible and the URL stays dead.
+func (a adminAPIHandlers) RevokePresignedGrantHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	id := pathClean(mux.Vars(r)["id"])
+	g, err := loadPresignedGrant(ctx, objectAPI, id)
+	if err != nil {
+		if errors.Is(err, errGrantNotFound) {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAdminNoSuchConfigTarget), r.URL)
+			return
+		}
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if !g.Revoked {
+		g.Revoked = true
+		g.RevokedBy = cred.AccessKey
+		g.RevokedAt = UTCNow()
+		if err := savePresignedGrant(ctx, objectAPI, g); err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+			return
+		}
+	}
+
+	auditLogInternal(ctx, AuditLogOptions{
+		Event:   "RevokePresignedGrant",
+		APIName: "RevokePresignedGrant",
+		Bucket:  g.Bucket,
+		Object:  g.Object,
+		Status:  "success",
+		Tags:    map[string]string{"grantId": id},
+	})
+
+	writeSuccessNoContent(w)
+}
@@ -0,0 +1,461 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/crypto"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Per-node read-through cache on dedicated fast drives, for HDD based
+// deployments serving a hot working set. Full-object GETs are served
+// from the cache after the object's current ETag is validated against
+// the backend, so a cache hit costs one metadata read instead of an
+// HDD data read. Objects are admitted only after they have been read
+// admissionK times (LRU-K), evicted in LRU order, and encrypted or
+// packed objects are never cached.
+const (
+	// EnvCacheDrives enables the cache, a comma separated list of
+	// directories on fast local drives.
+	EnvCacheDrives = "MINIO_CACHE_DRIVES"
+
+	// EnvCacheMaxSize caps the total cached bytes across the cache
+	// drives, defaults to 10GiB.
+	EnvCacheMaxSize = "MINIO_CACHE_MAX_SIZE"
+
+	// EnvCacheAdmissionK is the number of reads after which an object
+	// is admitted, defaults to 2.
+	EnvCacheAdmissionK = "MINIO_CACHE_ADMISSION_K"
+
+	cacheDataExt = ".data"
+	cacheMetaExt = ".meta"
+
+	// cacheMaxObjectSize is the largest object the cache will admit.
+	cacheMaxObjectSize = 128 * humanize.MiByte
+
+	// cacheDefaultMaxSize is used when no size is configured.
+	cacheDefaultMaxSize = 10 * humanize.GiByte
+
+	// cacheMaxPendingAdmissions bounds the admission counter map.
+	cacheMaxPendingAdmissions = 100000
+)
+
+// cacheObjMeta is stored next to each cached object.
+type cacheObjMeta struct {
+	Bucket    string    `json:"bucket"`
+	Object    string    `json:"object"`
+	VersionID string    `json:"versionID,omitempty"`
+	ETag      string    `json:"etag"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"modTime"`
+}
+
+// cacheItem is one LRU entry.
+type cacheItem struct {
+	key  string
+	size int64
+}
+
+// readCache is the per-node cache state.
+type readCache struct {
+	dirs       []string
+	maxSize    int64
+	admissionK int
+
+	mu       sync.Mutex
+	lru      *list.List
+	items    map[string]*list.Element
+	accesses map[string]int
+	used     int64
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// globalReadCache is nil unless cache drives are configured.
+var globalReadCache *readCache
+
+// initReadCache builds the cache from the environment and restores the
+// entries found on the cache drives.
+func initReadCache(ctx context.Context) {
+	drives := env.Get(EnvCacheDrives, "")
+	if drives == "" {
+		return
+	}
+
+	c := &readCache{
+		maxSize:    cacheDefaultMaxSize,
+		admissionK: 2,
+		lru:        list.New(),
+		items:      make(map[string]*list.Element),
+		accesses:   make(map[string]int),
+	}
+
+	for _, dir := range strings.Split(drives, ",") {
+		if dir = strings.TrimSpace(dir); dir == "" {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0o777); err != nil {
+			internalLogIf(ctx, fmt.Errorf("unable to initialize cache drive %s: %w", dir, err))
+			continue
+		}
+		c.dirs = append(c.dirs, dir)
+	}
+	if len(c.dirs) == 0 {
+		return
+	}
+
+	if s := env.Get(EnvCacheMaxSize, ""); s != "" {
+		sz, err := humanize.ParseBytes(s)
+		if err != nil {
+			internalLogIf(ctx, fmt.Errorf("invalid %s value %s: %w", EnvCacheMaxSize, s, err))
+			return
+		}
+		c.maxSize = int64(sz)
+	}
+	if s := env.Get(EnvCacheAdmissionK, ""); s != "" {
+		k, err := strconv.Atoi(s)
+		if err != nil || k < 1 {
+			internalLogIf(ctx, fmt.Errorf("invalid %s value %s", EnvCacheAdmissionK, s))
+			return
+		}
+		c.admissionK = k
+	}
+
+	c.restore(ctx)
+	globalReadCache = c
+}
+
+// restore rebuilds the LRU from the metadata files on the cache drives.
+func (c *readCache) restore(ctx context.Context) {
+	for _, dir := range c.dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			internalLogIf(ctx, fmt.Errorf("unable to list cache drive %s: %w", dir, err))
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasSuffix(name, cacheMetaExt) {
+				continue
+			}
+			key := strings.TrimSuffix(name, cacheMetaExt)
+			meta, err := c.readMeta(key)
+			if err != nil {
+				c.removeFiles(key)
+				continue
+			}
+			c.items[key] = c.lru.PushBack(&cacheItem{key: key, size: meta.Size})
+			c.used += meta.Size
+		}
+	}
+}
+
+// key derives the cache key of an object version.
+func (c *readCache) key(bucket, object, versionID string) string {
+	sum := sha256.Sum256([]byte(pathJoin(bucket, object) + "@" + versionID))
+	return hex.EncodeToString(sum[:])
+}
+
+// dirFor spreads keys across the cache drives.
+func (c *readCache) dirFor(key string) string {
+	return c.dirs[int(key[0]%byte(len(c.dirs)))%len(c.dirs)]
+}
+
+func (c *readCache) dataPath(key string) string {
+	return filepath.Join(c.dirFor(key), key+cacheDataExt)
+}
+
+func (c *readCache) metaPath(key string) string {
+	return filepath.Join(c.dirFor(key), key+cacheMetaExt)
+}
+
+func (c *readCache) readMeta(key string) (*cacheObjMeta, error) {
+	data, err := os.ReadFile(c.metaPath(key))
+	if err != nil {
+		return nil, err
+	}
+	var meta cacheObjMeta
+	if err = json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+func (c *readCache) removeFiles(key string) {
+	os.Remove(c.dataPath(key))
+	os.Remove(c.metaPath(key))
+}
+
+// invalidate drops a cached object.
+func (c *readCache) invalidate(key string) {
+	c.mu.Lock()
+	if elem, ok := c.items[key]; ok {
+		c.used -= elem.Value.(*cacheItem).size
+		c.lru.Remove(elem)
+		delete(c.items, key)
+	}
+	c.mu.Unlock()
+	c.removeFiles(key)
+}
+
+// touch marks a cached object as recently used.
+func (c *readCache) touch(key string) {
+	c.mu.Lock()
+	if elem, ok := c.items[key]; ok {
+		c.lru.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+}
+
+// recordAccess counts a read towards LRU-K admission and reports
+// whether the object has been read often enough to be admitted.
+func (c *readCache) recordAccess(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.accesses) >= cacheMaxPendingAdmissions {
+		c.accesses = make(map[string]int)
+	}
+	c.accesses[key]++
+	if c.accesses[key] >= c.admissionK {
+		delete(c.accesses, key)
+		return true
+	}
+	return false
+}
+
+// insert records a completed cache fill, evicting in LRU order to make
+// room.
+func (c *readCache) insert(key string, size int64) {
+	var evicted []string
+	c.mu.Lock()
+	for c.used+size > c.maxSize && c.lru.Len() > 0 {
+		elem := c.lru.Back()
+		item := elem.Value.(*cacheItem)
+		c.used -= item.size
+		c.lru.Remove(elem)
+		delete(c.items, item.key)
+		evicted = append(evicted, item.key)
+	}
+	c.items[key] = c.lru.PushFront(&cacheItem{key: key, size: size})
+	c.used += size
+	c.mu.Unlock()
+
+	for _, key := range evicted {
+		c.removeFiles(key)
+		c.evictions.Add(1)
+	}
+}
+
+// cacheable returns whether the object qualifies for caching at all.
+func cacheable(oi ObjectInfo) bool {
+	if oi.Size <= 0 || oi.Size > cacheMaxObjectSize || oi.DeleteMarker {
+		return false
+	}
+	if _, encrypted := crypto.IsEncrypted(oi.UserDefined); encrypted {
+		return false
+	}
+	return !isPackedObject(oi)
+}
+
+// serve attempts to satisfy a full-object GET from the cache. The
+// object's current ETag is read from the backend first, a mismatch
+// invalidates the cached copy.
+func (c *readCache) serve(ctx context.Context, z *erasureServerPools, bucket, object string, rs *HTTPRangeSpec, opts ObjectOptions) (*GetObjectReader, bool) {
+	if c == nil || rs != nil || bucket == minioMetaBucket {
+		return nil, false
+	}
+
+	key := c.key(bucket, object, opts.VersionID)
+	c.mu.Lock()
+	_, cached := c.items[key]
+	c.mu.Unlock()
+	if !cached {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	meta, err := c.readMeta(key)
+	if err != nil {
+		c.invalidate(key)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	oi, err := z.GetObjectInfo(ctx, bucket, object, opts)
+	if err != nil || !cacheable(oi) || oi.ETag != meta.ETag {
+		c.invalidate(key)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	f, err := os.Open(c.dataPath(key))
+	if err != nil {
+		c.invalidate(key)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	gr, err := NewGetObjectReaderFromReader(f, oi, opts, func() {
+		f.Close()
+	})
+	if err != nil {
+		f.Close()
+		return nil, false
+	}
+
+	c.touch(key)
+	c.hits.Add(1)
+	return gr, true
+}
+
+// maybeCache wraps a backend read stream so the bytes are written into
+// the cache while being served, when the object passes admission.
+func (c *readCache) maybeCache(gr *GetObjectReader, rs *HTTPRangeSpec, opts ObjectOptions) *GetObjectReader {
+	if c == nil || rs != nil || gr.ObjInfo.Bucket == minioMetaBucket || !cacheable(gr.ObjInfo) {
+		return gr
+	}
+
+	key := c.key(gr.ObjInfo.Bucket, gr.ObjInfo.Name, opts.VersionID)
+	if !c.recordAccess(key) {
+		return gr
+	}
+
+	f, err := os.CreateTemp(c.dirFor(key), "fill-*")
+	if err != nil {
+		return gr
+	}
+
+	cw := &cacheFillWriter{c: c, key: key, f: f, want: gr.ObjInfo.Size, meta: cacheObjMeta{
+		Bucket:    gr.ObjInfo.Bucket,
+		Object:    gr.ObjInfo.Name,
+		VersionID: opts.VersionID,
+		ETag:      gr.ObjInfo.ETag,
+		Size:      gr.ObjInfo.Size,
+		ModTime:   gr.ObjInfo.ModTime,
+	}}
+	gr.Reader = io.TeeReader(gr.Reader, cw)
+	return gr.WithCleanupFuncs(cw.finish)
+}
+
+// cacheFillWriter writes the served bytes to a temporary file and
+// promotes it into the cache when the full object was read. Write never
+// fails - a cache write error only aborts the fill.
+type cacheFillWriter struct {
+	c       *readCache
+	key     string
+	f       *os.File
+	meta    cacheObjMeta
+	want    int64
+	written int64
+	failed  bool
+}
+
+func (w *cacheFillWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		if _, err := w.f.Write(p); err != nil {
+			w.failed = true
+		} else {
+			w.written += int64(len(p))
+		}
+	}
+	return len(p), nil
+}
+
+func (w *cacheFillWriter) finish() {
+	name := w.f.Name()
+	w.f.Close()
+	if w.failed || w.written != w.want {
+		os.Remove(name)
+		return
+	}
+	data, err := json.Marshal(w.meta)
+	if err != nil {
+		os.Remove(name)
+		return
+	}
+	if err = os.WriteFile(w.c.metaPath(w.key), data, 0o666); err != nil {
+		os.Remove(name)
+		return
+	}
+	if err = os.Rename(name, w.c.dataPath(w.key)); err != nil {
+		os.Remove(name)
+		os.Remove(w.c.metaPath(w.key))
+		return
+	}
+	w.c.insert(w.key, w.want)
+}
+
+// Metric names for the read cache.
+const (
+	cacheHitsTotal      MetricName = "hits_total"
+	cacheMissesTotal    MetricName = "misses_total"
+	cacheEvictionsTotal MetricName = "evictions_total"
+	cacheUsageBytes     MetricName = "usage_bytes"
+	cacheCapacityBytes  MetricName = "capacity_bytes"
+)
+
+// Metric descriptions for the read cache.
+var (
+	cacheHitsTotalMD = NewCounterMD(cacheHitsTotal,
+		"Total number of GETs served from the read cache")
+	cacheMissesTotalMD = NewCounterMD(cacheMissesTotal,
+		"Total number of GETs not served from the read cache")
+	cacheEvictionsTotalMD = NewCounterMD(cacheEvictionsTotal,
+		"Total number of objects evicted from the read cache")
+	cacheUsageBytesMD = NewGaugeMD(cacheUsageBytes,
+		"Current read cache usage in bytes")
+	cacheCapacityBytesMD = NewGaugeMD(cacheCapacityBytes,
+		"Configured read cache capacity in bytes")
+)
+
+// loadReadCacheMetrics - loads read cache metrics.
+//
+// This is a `MetricsLoaderFn`.
+func loadReadCacheMetrics(ctx context.Context, m MetricValues, _ *metricsCache) error {
+	c := globalReadCache
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	used := c.used
+	c.mu.Unlock()
+	m.Set(cacheHitsTotal, float64(c.hits.Load()))
+	m.Set(cacheMissesTotal, float64(c.misses.Load()))
+	m.Set(cacheEvictionsTotal, float64(c.evictions.Load()))
+	m.Set(cacheUsageBytes, float64(used))
+	m.Set(cacheCapacityBytes, float64(c.maxSize))
+	return nil
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minio/mux"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// rebalPoolThrottle holds the runtime adjustable rebalance limits of a
+// single pool together with its token bucket state.
+type rebalPoolThrottle struct {
+	maxBytesPerSec int64 // 0 => unlimited
+	workers        int   // 0 => default, applies to newly started rebalance
+
+	tokens   float64
+	lastFill time.Time
+}
+
+// rebalanceThrottler tracks per-pool throttle settings for the pool
+// rebalance subsystem, adjustable at runtime via admin API.
+type rebalanceThrottler struct {
+	mu    sync.Mutex
+	pools map[int]*rebalPoolThrottle
+}
+
+var globalRebalanceThrottle = &rebalanceThrottler{
+	pools: make(map[int]*rebalPoolThrottle),
+}
+
+func (t *rebalanceThrottler) pool(poolIdx int) *rebalPoolThrottle {
+	p, ok := t.pools[poolIdx]
+	if !ok {
+		p = &rebalPoolThrottle{lastFill: time.Now()}
+		t.pools[poolIdx] = p
+	}
+	return p
+}
+
+// setLimits updates pool limits. The byte rate applies to in-flight
+// rebalancing immediately, the worker count applies to the next
+// rebalance started on the pool.
+func (t *rebalanceThrottler) setLimits(poolIdx int, maxBytesPerSec int64, workers int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := t.pool(poolIdx)
+	p.maxBytesPerSec = maxBytesPerSec
+	p.workers = workers
+	p.tokens = 0
+	p.lastFill = time.Now()
+}
+
+func (t *rebalanceThrottler) limits(poolIdx int) (maxBytesPerSec int64, workers int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := t.pool(poolIdx)
+	return p.maxBytesPerSec, p.workers
+}
+
+// workersFor returns the configured worker override for the pool, 0
+// when the default should be used.
+func (t *rebalanceThrottler) workersFor(poolIdx int) int {
+	_, workers := t.limits(poolIdx)
+	return workers
+}
+
+// waitN blocks until n bytes are available in the pool token bucket,
+// a no-op when no byte rate is configured.
+func (t *rebalanceThrottler) waitN(ctx context.Context, poolIdx int, n int64) {
+	for {
+		t.mu.Lock()
+		p := t.pool(poolIdx)
+		if p.maxBytesPerSec <= 0 {
+			t.mu.Unlock()
+			return
+		}
+		now := time.Now()
+		p.tokens += now.Sub(p.lastFill).Seconds() * float64(p.maxBytesPerSec)
+		if maxBurst := float64(p.maxBytesPerSec); p.tokens > maxBurst {
+			p.tokens = maxBurst
+		}
+		p.lastFill = now
+		if p.tokens >= float64(n) {
+			p.tokens -= float64(n)
+			t.mu.Unlock()
+			return
+		}
+		t.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// rebalSetProgress tracks byte-level progress of one erasure set of a
+// rebalancing pool.
+type rebalSetProgress struct {
+	bytes     uint64
+	objects   uint64
+	startTime time.Time
+}
+
+// rebalanceProgressTracker tracks per erasure set progress of ongoing
+// rebalance operations, in-memory on the node driving the rebalance.
+type rebalanceProgressTracker struct {
+	mu   sync.RWMutex
+	sets map[int]map[int]*rebalSetProgress // pool -> set
+}
+
+var globalRebalanceProgress = &rebalanceProgressTracker{
+	sets: make(map[int]map[int]*rebalSetProgress),
+}
+
+func (p *rebalanceProgressTracker) initSet(poolIdx, setIdx int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sets[poolIdx] == nil {
+		p.sets[poolIdx] = make(map[int]*rebalSetProgress)
+	}
+	p.sets[poolIdx][setIdx] = &rebalSetProgress{startTime: time.Now().UTC()}
+}
+
+func (p *rebalanceProgressTracker) add(poolIdx, setIdx int, n uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if sp := p.sets[poolIdx][setIdx]; sp != nil {
+		sp.bytes += n
+		sp.objects++
+	}
+}
+
+// rebalanceSetProgress is the byte-level per erasure set progress as
+// reported by the admin API.
+type rebalanceSetProgress struct {
+	Pool           int     `json:"pool"`
+	Set            int     `json:"set"`
+	BytesMoved     uint64  `json:"bytesMoved"`
+	ObjectsMoved   uint64  `json:"objectsMoved"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
+	BytesPlanned   uint64  `json:"bytesPlanned"`
+	ETASeconds     float64 `json:"etaSeconds"`
+}
+
+// rebalanceProgressReport is the response of the rebalance progress
+// admin API.
+type rebalanceProgressReport struct {
+	Sets []rebalanceSetProgress `json:"sets"`
+}
+
+// report computes rates and ETAs. plannedPerPool is the estimated
+// number of bytes each pool still has to shed in total, split evenly
+// across its erasure sets for the per-set estimate.
+func (p *rebalanceProgressTracker) report(plannedPerPool map[int]uint64, setCount map[int]int) rebalanceProgressReport {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var rep rebalanceProgressReport
+	for poolIdx, sets := range p.sets {
+		for setIdx, sp := range sets {
+			sproc := rebalanceSetProgress{
+				Pool:         poolIdx,
+				Set:          setIdx,
+				BytesMoved:   sp.bytes,
+				ObjectsMoved: sp.objects,
+			}
+			if elapsed := time.Since(sp.startTime).Seconds(); elapsed > 0 {
+				sproc.BytesPerSecond = float64(sp.bytes) / elapsed
+			}
+			if n := setCount[poolIdx]; n > 0 {
+				sproc.BytesPlanned = plannedPerPool[poolIdx] / uint64(n)
+			}
+			if sproc.BytesPerSecond > 0 && sproc.BytesPlanned > sproc.BytesMoved {
+				sproc.ETASeconds = float64(sproc.BytesPlanned-sproc.BytesMoved) / sproc.BytesPerSecond
+			}
+			rep.Sets = append(rep.Sets, sproc)
+		}
+	}
+	return rep
+}
+
+// RebalanceThrottleHandler - adjusts the rebalance byte rate and worker
+// count of a pool at runtime.
+func (a adminAPIHandlers) RebalanceThrottleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.RebalanceAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	poolIdx, err := strconv.Atoi(vars["pool"])
+	if err != nil || poolIdx < 0 {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+
+	var maxBytesPerSec int64
+	if v := r.Form.Get("maxMiBps"); v != "" {
+		mibps, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || mibps < 0 {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+			return
+		}
+		maxBytesPerSec = mibps << 20
+	}
+	var workersCount int
+	if v := r.Form.Get("workers"); v != "" {
+		if workersCount, err = strconv.Atoi(v); err != nil || workersCount < 0 {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+			return
+		}
+	}
+
+	globalRebalanceThrottle.setLimits(poolIdx, maxBytesPerSec, workersCount)
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// RebalanceProgressHandler - returns byte-level rebalance progress and
+// ETA per erasure set.
+func (a adminAPIHandlers) RebalanceProgressHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.RebalanceAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	planned := make(map[int]uint64)
+	setCount := make(map[int]int)
+	if z, ok := objectAPI.(*erasureServerPools); ok {
+		z.rebalMu.RLock()
+		if z.rebalMeta != nil {
+			for poolIdx, ps := range z.rebalMeta.PoolStats {
+				if ps == nil || !ps.Participating {
+					continue
+				}
+				// Bytes the pool had to shed to reach the percent
+				// free goal, computed from its initial usage.
+				goal := z.rebalMeta.PercentFreeGoal * float64(ps.InitCapacity)
+				if shed := goal - float64(ps.InitFreeSpace); shed > 0 {
+					planned[poolIdx] = uint64(shed)
+				}
+				setCount[poolIdx] = z.serverPools[poolIdx].setCount
+			}
+		}
+		z.rebalMu.RUnlock()
+	}
+
+	progressData, err := json.Marshal(globalRebalanceProgress.report(planned, setCount))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, progressData)
+}
@@ -77,6 +77,10 @@ var globalMiddlewares = []mux.MiddlewareFunc{
 	setUploadForwardingMiddleware,
 	// Add bucket forwarding middleware
 	setBucketForwardingMiddleware,
+	// Reject requests against buckets frozen for maintenance.
+	setBucketFreezeMiddleware,
+	// Admit S3 requests under their per API class concurrency limit.
+	setAPIAdmissionMiddleware,
 	// Add new middlewares here.
 }
 
@@ -97,6 +101,9 @@ func configureServerHandler(endpointServerPools EndpointServerPools) (http.Handl
 	// Add healthCheck router
 	registerHealthCheckRouter(router)
 
+	// Add SDK transfer hints router
+	registerTransferHintsRouter(router)
+
 	// Add server metrics router
 	registerMetricsRouter(router)
 
@@ -57,6 +57,11 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+// EnvProxyProtocolTrustedCIDRs is a comma separated list of CIDRs. PROXY
+// protocol v1/v2 headers are honored only on connections from these
+// networks, typically the load balancer subnets.
+const EnvProxyProtocolTrustedCIDRs = "MINIO_PROXY_PROTOCOL_TRUSTED_CIDRS"
+
 // ServerFlags - server command specific flags
 var ServerFlags = []cli.Flag{
 	cli.StringFlag{
@@ -82,6 +87,11 @@ var ServerFlags = []cli.Flag{
 		Usage:  "bind to a specific ADDRESS:PORT for embedded Console UI, ADDRESS can be an IP or hostname",
 		EnvVar: "MINIO_CONSOLE_ADDRESS",
 	},
+	cli.StringFlag{
+		Name:   "address-quic",
+		Usage:  "bind to a specific ADDRESS:PORT serving the S3 API over experimental HTTP/3 (QUIC), requires TLS",
+		EnvVar: "MINIO_ADDRESS_QUIC",
+	},
 	cli.DurationFlag{
 		Name:   "shutdown-timeout",
 		Value:  time.Second * 30,
@@ -144,6 +154,10 @@ var ServerFlags = []cli.Flag{
 		Name:  "sftp",
 		Usage: "enable and configure an SFTP server",
 	},
+	cli.StringSliceFlag{
+		Name:  "webdav",
+		Usage: "enable and configure a WebDAV server",
+	},
 	cli.StringFlag{
 		Name:   "crossdomain-xml",
 		Usage:  "provide a custom crossdomain-xml configuration to report at http://endpoint/crossdomain.xml",
@@ -424,6 +438,14 @@ func serverHandleCmdArgs(ctxt serverCtxt) {
 		IdleTimeout: ctxt.IdleTimeout,
 	}
 
+	// Honor PROXY protocol headers from trusted load balancers so audit
+	// logs and aws:SourceIp conditions see real client addresses.
+	if v := env.Get(EnvProxyProtocolTrustedCIDRs, ""); v != "" {
+		cidrs, err := xhttp.ParseProxyTrustedCIDRs(v)
+		logger.FatalIf(err, "Invalid %s value", EnvProxyProtocolTrustedCIDRs)
+		globalTCPOptions.ProxyTrustedCIDRs = cidrs
+	}
+
 	// allow transport to be HTTP/1.1 for proxying.
 	globalProxyEndpoints = GetProxyEndpoints(globalEndpoints)
 	globalInternodeTransport = NewInternodeHTTPTransport(ctxt.MaxIdleConnsPerHost)()
@@ -484,6 +506,15 @@ func initAllSubsystems(ctx context.Context) {
 	// Create new bucket quota subsystem
 	globalBucketQuotaSys = NewBucketQuotaSys()
 
+	// Create new bucket region subsystem
+	globalBucketRegionSys = NewBucketRegionSys()
+
+	// Create new bucket encryption enforcement subsystem
+	globalBucketEncryptionEnforceSys = NewBucketEncryptionEnforceSys()
+
+	// Create new bucket listing consistency subsystem
+	globalBucketListConsistencySys = NewBucketListConsistencySys()
+
 	// Create new bucket versioning subsystem
 	if globalBucketVersioningSys == nil {
 		globalBucketVersioningSys = NewBucketVersioningSys()
@@ -654,8 +685,8 @@ func setGlobalInternodeInterface(interfaceName string) {
 				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 				defer cancel()
 
-				haddrs, err := globalDNSCache.LookupHost(ctx, host)
-				if err == nil {
+				haddrs, err := lookupHostByFamily(ctx, host)
+				if err == nil && len(haddrs) > 0 {
 					ip = haddrs[0]
 				}
 			}
@@ -690,8 +721,8 @@ func getServerListenAddrs() []string {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		defer cancel()
 
-		haddrs, err := globalDNSCache.LookupHost(ctx, host)
-		if err == nil {
+		haddrs, err := lookupHostByFamily(ctx, host)
+		if err == nil && len(haddrs) > 0 {
 			for _, addr := range haddrs {
 				addrs.Add(net.JoinHostPort(addr, globalMinioPort))
 			}
@@ -795,11 +826,16 @@ func serverMain(ctx *cli.Context) {
 	// the config file if not defined, set the default one.
 	loadRootCredentials()
 
+	// Bootstrap the cluster CA and this node's certificate when
+	// automatic internode TLS is enabled.
+	bootstrapTrace("initInternodeCA", initInternodeCA)
+
 	// Perform any self-tests
 	bootstrapTrace("selftests", func() {
 		bitrotSelfTest()
 		erasureSelfTest()
 		compressSelfTest()
+		logErasureAccel()
 	})
 
 	// Initialize KMS configuration
@@ -875,8 +911,10 @@ func serverMain(ctx *cli.Context) {
 		close(globalGridStart)
 		close(globalLockGridStart)
 
+		initGRPCDataPlane()
+
 		httpServer := xhttp.NewServer(getServerListenAddrs()).
-			UseHandler(setCriticalErrorHandler(corsHandler(handler))).
+			UseHandler(setCriticalErrorHandler(corsHandler(setGRPCDataPlaneHandler(setAltSvcHandler(handler))))).
 			UseTLSConfig(newTLSConfig(getCert)).
 			UseIdleTimeout(globalServerCtxt.IdleTimeout).
 			UseReadTimeout(globalServerCtxt.IdleTimeout).
@@ -899,6 +937,10 @@ func serverMain(ctx *cli.Context) {
 		}()
 
 		setHTTPServer(httpServer)
+
+		if quicAddr := ctx.String("address-quic"); quicAddr != "" {
+			startHTTP3Server(GlobalContext, quicAddr, setCriticalErrorHandler(corsHandler(handler)), newTLSConfig(getCert))
+		}
 	})
 
 	if globalIsDistErasure {
@@ -1012,6 +1054,13 @@ func serverMain(ctx *cli.Context) {
 				go startSFTPServer(globalServerCtxt.SFTP)
 			})
 		}
+
+		// If we see WebDAV args, start WebDAV if possible
+		if len(globalServerCtxt.WebDAV) > 0 {
+			bootstrapTrace("go startWebDAVServer", func() {
+				go startWebDAVServer(globalServerCtxt.WebDAV)
+			})
+		}
 	}()
 
 	go func() {
@@ -1114,6 +1163,32 @@ func serverMain(ctx *cli.Context) {
 			go globalBatchJobsMetrics.purgeJobMetrics()
 		})
 
+		// Initialize hash-chained audit log segments, if configured.
+		bootstrapTrace("initAuditChain", func() {
+			initAuditChain(GlobalContext, newObject)
+		})
+
+		// Initialize access time tracking, if enabled.
+		bootstrapTrace("initATimeTracker", func() {
+			initATimeTracker(GlobalContext)
+		})
+
+		// Initialize background compression dictionary training.
+		bootstrapTrace("initZstdDictTrainer", func() {
+			initZstdDictTrainer(GlobalContext, newObject)
+		})
+
+		// Initialize the adaptive GC governor, if enabled.
+		bootstrapTrace("initGCGovernor", func() {
+			initGCGovernor(GlobalContext)
+		})
+
+		// Record drive performance baselines and start the deviation
+		// monitor, if enabled.
+		bootstrapTrace("initDrivePerfBaselines", func() {
+			initDrivePerfBaselines(GlobalContext)
+		})
+
 		// Prints the formatted startup message, if err is not nil then it prints additional information as well.
 		printStartupMessage(getAPIEndpoints(), err)
 
@@ -47,6 +47,7 @@ type sftpDriver struct {
 	permissions *ssh.Permissions
 	endpoint    string
 	remoteIP    string
+	chroot      string
 }
 
 //msgp:ignore sftpMetrics
@@ -96,6 +97,7 @@ func NewSFTPDriver(perms *ssh.Permissions, remoteIP string) sftp.Handlers {
 		endpoint:    fmt.Sprintf("127.0.0.1:%s", globalMinioPort),
 		permissions: perms,
 		remoteIP:    remoteIP,
+		chroot:      perms.Extensions["Chroot"],
 	}
 	return sftp.Handlers{
 		FileGet:  handler,
@@ -137,6 +139,15 @@ func (f *sftpDriver) AccessKey() string {
 	return f.permissions.CriticalOptions["AccessKey"]
 }
 
+// bucketObject maps an SFTP request path to a bucket and object,
+// applying the session chroot when one is set.
+func (f *sftpDriver) bucketObject(filePath string) (bucket, object string) {
+	if f.chroot != "" {
+		filePath = path.Join(SlashSeparator+f.chroot, filePath)
+	}
+	return path2BucketObject(filePath)
+}
+
 func (f *sftpDriver) Fileread(r *sftp.Request) (ra io.ReaderAt, err error) {
 	// This is not timing the actual read operation, but the time it takes to prepare the reader.
 	stopFn := globalSftpMetrics.log(r, f.AccessKey())
@@ -148,7 +159,7 @@ func (f *sftpDriver) Fileread(r *sftp.Request) (ra io.ReaderAt, err error) {
 		return nil, os.ErrInvalid
 	}
 
-	bucket, object := path2BucketObject(r.Filepath)
+	bucket, object := f.bucketObject(r.Filepath)
 	if bucket == "" {
 		return nil, errors.New("bucket name cannot be empty")
 	}
@@ -259,7 +270,7 @@ func (f *sftpDriver) Filewrite(r *sftp.Request) (w io.WriterAt, err error) {
 		return nil, os.ErrInvalid
 	}
 
-	bucket, object := path2BucketObject(r.Filepath)
+	bucket, object := f.bucketObject(r.Filepath)
 	if bucket == "" {
 		return nil, errors.New("bucket name cannot be empty")
 	}
@@ -276,6 +287,12 @@ func (f *sftpDriver) Filewrite(r *sftp.Request) (w io.WriterAt, err error) {
 		return nil, os.ErrNotExist
 	}
 
+	// SFTP uploads stream with unknown length, reject the upload up
+	// front when the bucket is already at its hard quota.
+	if err = enforceBucketQuotaHard(r.Context(), bucket, 0); err != nil {
+		return nil, err
+	}
+
 	pr, pw := io.Pipe()
 
 	wa := &writerAt{
@@ -311,7 +328,7 @@ func (f *sftpDriver) Filecmd(r *sftp.Request) (err error) {
 		return sftp.ErrSSHFxOpUnsupported
 
 	case "Rmdir":
-		bucket, prefix := path2BucketObject(r.Filepath)
+		bucket, prefix := f.bucketObject(r.Filepath)
 		if bucket == "" {
 			return errors.New("deleting all buckets not allowed")
 		}
@@ -350,7 +367,7 @@ func (f *sftpDriver) Filecmd(r *sftp.Request) (err error) {
 		return err
 
 	case "Remove":
-		bucket, object := path2BucketObject(r.Filepath)
+		bucket, object := f.bucketObject(r.Filepath)
 		if bucket == "" {
 			return errors.New("bucket name cannot be empty")
 		}
@@ -358,7 +375,7 @@ func (f *sftpDriver) Filecmd(r *sftp.Request) (err error) {
 		return clnt.RemoveObject(context.Background(), bucket, object, minio.RemoveObjectOptions{})
 
 	case "Mkdir":
-		bucket, prefix := path2BucketObject(r.Filepath)
+		bucket, prefix := f.bucketObject(r.Filepath)
 		if bucket == "" {
 			return errors.New("bucket name cannot be empty")
 		}
@@ -406,7 +423,7 @@ func (f *sftpDriver) Filelist(r *sftp.Request) (la sftp.ListerAt, err error) {
 	case "List":
 		var files []os.FileInfo
 
-		bucket, prefix := path2BucketObject(r.Filepath)
+		bucket, prefix := f.bucketObject(r.Filepath)
 		if bucket == "" {
 			buckets, err := clnt.ListBuckets(r.Context())
 			if err != nil {
@@ -456,7 +473,7 @@ func (f *sftpDriver) Filelist(r *sftp.Request) (la sftp.ListerAt, err error) {
 			}}, nil
 		}
 
-		bucket, object := path2BucketObject(r.Filepath)
+		bucket, object := f.bucketObject(r.Filepath)
 		if bucket == "" {
 			return nil, errors.New("bucket name cannot be empty")
 		}
@@ -32,6 +32,7 @@ import (
 	"github.com/minio/minio/internal/auth"
 	"github.com/minio/minio/internal/logger"
 	xldap "github.com/minio/pkg/v3/ldap"
+	"github.com/minio/pkg/v3/policy"
 	xsftp "github.com/minio/pkg/v3/sftp"
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
@@ -64,6 +65,12 @@ var (
 // the final form of the key file will be set as this variable.
 var caPublicKey ssh.PublicKey
 
+// if the sftp parameter --pubkey-map-file is set, maps SSH public key
+// fingerprints (SHA256 form) to service account access keys. The SSH
+// layer proves possession of the private key, the map entry authorizes
+// it for the service account.
+var sftpPubKeyMap map[string]string
+
 // https://cs.opensource.google/go/x/crypto/+/refs/tags/v0.22.0:ssh/common.go;l=46
 // preferredKexAlgos specifies the default preference for key-exchange
 // algorithms in preference order. The diffie-hellman-group16-sha512 algorithm
@@ -156,7 +163,25 @@ func authenticateSSHConnection(c ssh.ConnMetadata, key ssh.PublicKey, pass []byt
 	}
 
 internalAuth:
+	if key != nil && len(sftpPubKeyMap) > 0 {
+		if accessKey, ok := sftpPubKeyMap[ssh.FingerprintSHA256(key)]; ok {
+			return processPubKeyMapAuthentication(user, accessKey)
+		}
+	}
+
 	ui, ok := globalIAMSys.GetUser(context.Background(), user)
+	if !ok && caPublicKey != nil && pass == nil && key != nil {
+		// With certificate logins the username on the wire may be any
+		// of the certificate principals, map the remaining principals
+		// to IAM users.
+		if cert, cok := key.(*ssh.Certificate); cok {
+			for _, principal := range cert.ValidPrincipals {
+				if ui, ok = globalIAMSys.GetUser(context.Background(), principal); ok {
+					break
+				}
+			}
+		}
+	}
 	if !ok {
 		return nil, errNoSuchUser
 	}
@@ -185,12 +210,104 @@ internalAuth:
 		copts["SessionToken"] = ui.Credentials.SessionToken
 	}
 
+	name := ui.Credentials.AccessKey
+	if ui.Credentials.ParentUser != "" {
+		name = ui.Credentials.ParentUser
+	}
+
 	return &ssh.Permissions{
 		CriticalOptions: copts,
-		Extensions:      make(map[string]string),
+		Extensions:      sftpExtensions(sftpChrootForUser(name, ui.Credentials.Groups)),
+	}, nil
+}
+
+// processPubKeyMapAuthentication authenticates a connection whose public
+// key fingerprint is mapped to a service account via --sftp=pubkey-map-file.
+func processPubKeyMapAuthentication(user, accessKey string) (*ssh.Permissions, error) {
+	sa, _, err := globalIAMSys.getServiceAccount(context.Background(), accessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// The login name must still identify the mapped account.
+	if user != sa.Credentials.AccessKey && user != sa.Credentials.ParentUser {
+		return nil, errAuthentication
+	}
+
+	return &ssh.Permissions{
+		CriticalOptions: map[string]string{
+			"AccessKey": sa.Credentials.AccessKey,
+			"SecretKey": sa.Credentials.SecretKey,
+		},
+		Extensions: sftpExtensions(sftpChrootForUser(sa.Credentials.ParentUser, sa.Credentials.Groups)),
 	}, nil
 }
 
+// sftpExtensions returns the session extensions, recording the chroot
+// directory when one applies.
+func sftpExtensions(chroot string) map[string]string {
+	exts := make(map[string]string)
+	if chroot != "" {
+		exts["Chroot"] = chroot
+	}
+	return exts
+}
+
+// sftpChrootForUser derives the session chroot from the user's policies.
+func sftpChrootForUser(name string, groups []string) string {
+	policies, err := globalIAMSys.PolicyDBGet(name, groups...)
+	if err != nil || len(policies) == 0 {
+		return ""
+	}
+	return chrootFromPolicy(globalIAMSys.GetCombinedPolicy(policies...))
+}
+
+// chrootFromPolicy computes the deepest directory that contains every S3
+// resource the policy allows. When all allowed resources live under a
+// single bucket - optionally under a common prefix inside it - the SFTP
+// session is rooted there, otherwise no chroot applies.
+func chrootFromPolicy(p policy.Policy) string {
+	var root []string
+	rootSet := false
+	for _, stmt := range p.Statements {
+		if stmt.Effect != policy.Allow {
+			continue
+		}
+		for res := range stmt.Resources {
+			arn := res.String()
+			if !strings.HasPrefix(arn, policy.ResourceARNPrefix) {
+				continue
+			}
+			pattern := strings.TrimPrefix(arn, policy.ResourceARNPrefix)
+			// Cut at the first wildcard or policy variable, a partial
+			// path segment cannot be part of the root.
+			if i := strings.IndexAny(pattern, "*?$"); i >= 0 {
+				pattern = pattern[:i]
+				if j := strings.LastIndex(pattern, SlashSeparator); j >= 0 {
+					pattern = pattern[:j]
+				} else {
+					pattern = ""
+				}
+			}
+			var segs []string
+			if pattern = strings.Trim(pattern, SlashSeparator); pattern != "" {
+				segs = strings.Split(pattern, SlashSeparator)
+			}
+			if !rootSet {
+				root, rootSet = segs, true
+				continue
+			}
+			n := min(len(root), len(segs))
+			i := 0
+			for i < n && root[i] == segs[i] {
+				i++
+			}
+			root = root[:i]
+		}
+	}
+	return strings.Join(root, SlashSeparator)
+}
+
 func processLDAPAuthentication(key ssh.PublicKey, pass []byte, user string) (perms *ssh.Permissions, err error) {
 	var lookupResult *xldap.DNSearchResult
 	var targetGroups []string
@@ -211,7 +328,7 @@ func processLDAPAuthentication(key ssh.PublicKey, pass []byte, user string) (per
 					"AccessKey": sa.Credentials.AccessKey,
 					"SecretKey": sa.Credentials.SecretKey,
 				},
-				Extensions: make(map[string]string),
+				Extensions: sftpExtensions(sftpChrootForUser(sa.Credentials.ParentUser, sa.Credentials.Groups)),
 			}, nil
 		}
 
@@ -310,7 +427,7 @@ func processLDAPAuthentication(key ssh.PublicKey, pass []byte, user string) (per
 			"SecretKey":    cred.SecretKey,
 			"SessionToken": cred.SessionToken,
 		},
-		Extensions: make(map[string]string),
+		Extensions: sftpExtensions(chrootFromPolicy(globalIAMSys.GetCombinedPolicy(ldapPolicies...))),
 	}, nil
 }
 
@@ -392,6 +509,7 @@ func startSFTPServer(args []string) {
 		publicIP        string
 		sshPrivateKey   string
 		userCaKeyFile   string
+		pubKeyMapFile   string
 		disablePassAuth bool
 	)
 
@@ -432,6 +550,8 @@ func startSFTPServer(args []string) {
 			allowMACs = filterAlgos(arg, strings.Split(tokens[1], ","), supportedMACs)
 		case "trusted-user-ca-key":
 			userCaKeyFile = tokens[1]
+		case "pubkey-map-file":
+			pubKeyMapFile = tokens[1]
 		case "password-auth":
 			disablePassAuth, _ = strconv.ParseBool(tokens[1])
 		}
@@ -467,6 +587,26 @@ func startSFTPServer(args []string) {
 		}
 	}
 
+	if pubKeyMapFile != "" {
+		mapBytes, err := os.ReadFile(pubKeyMapFile)
+		if err != nil {
+			logger.Fatal(fmt.Errorf("invalid arguments passed, public key map file is not accessible: %v", err), "unable to start SFTP server")
+		}
+
+		sftpPubKeyMap = make(map[string]string)
+		for _, line := range strings.Split(string(mapBytes), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				logger.Fatal(fmt.Errorf("invalid public key map entry %q, expected '<SHA256 fingerprint> <access key>'", line), "unable to start SFTP server")
+			}
+			sftpPubKeyMap[fields[0]] = fields[1]
+		}
+	}
+
 	// An SSH server is represented by a ServerConfig, which holds
 	// certificate details and handles authentication of ServerConns.
 	sshConfig := &ssh.ServerConfig{
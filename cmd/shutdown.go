@@ -0,0 +1,141 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/minio/pkg/v3/env"
+)
+
+// Environment variable to scale all per-subsystem shutdown timeouts,
+// e.g. "2" doubles every step deadline.
+const envShutdownTimeoutScale = "_MINIO_SHUTDOWN_TIMEOUT_SCALE"
+
+// shutdownStep is one stage of the ordered shutdown sequence. Steps run
+// strictly in order, each under its own deadline. A step exceeding its
+// deadline is abandoned and logged, the sequence continues with the
+// next step so shutdown always makes progress.
+type shutdownStep struct {
+	name    string
+	timeout time.Duration
+	fn      func(context.Context) error
+}
+
+// runShutdownSequence executes the given steps in order, each bounded
+// by its own timeout.
+func runShutdownSequence(steps []shutdownStep) {
+	scale := 1.0
+	if s := env.Get(envShutdownTimeoutScale, ""); s != "" {
+		if v, err := time.ParseDuration(s + "s"); err == nil && v > 0 {
+			scale = v.Seconds()
+		}
+	}
+
+	for _, step := range steps {
+		timeout := time.Duration(float64(step.timeout) * scale)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+		finished := make(chan error, 1)
+		go func(step shutdownStep) {
+			finished <- step.fn(ctx)
+		}(step)
+
+		select {
+		case err := <-finished:
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				shutdownLogIf(context.Background(), fmt.Errorf("shutdown step %q: %w", step.name, err))
+			}
+		case <-ctx.Done():
+			shutdownLogIf(context.Background(), fmt.Errorf("shutdown step %q did not finish within %s, continuing", step.name, timeout))
+		}
+		cancel()
+	}
+}
+
+// shutdownSteps returns the ordered shutdown sequence of the server:
+// flush pending heal MRF state, stop accepting S3 requests and drain
+// in-flight ones, stop background workers, flush notification targets
+// and finally checkpoint the object layer.
+func shutdownSteps() []shutdownStep {
+	return []shutdownStep{
+		{
+			name:    "heal-mrf",
+			timeout: time.Minute,
+			fn: func(context.Context) error {
+				globalMRFState.shutdown()
+				return nil
+			},
+		},
+		{
+			name:    "s3-drain",
+			timeout: 30 * time.Second,
+			fn: func(context.Context) error {
+				if httpServer := newHTTPServerFn(); httpServer != nil {
+					return httpServer.Shutdown()
+				}
+				return nil
+			},
+		},
+		{
+			name:    "background-workers",
+			timeout: 5 * time.Second,
+			fn: func(context.Context) error {
+				// Signal all background go-routines to quit.
+				cancelGlobalContext()
+				return nil
+			},
+		},
+		{
+			name:    "notification-targets",
+			timeout: 15 * time.Second,
+			fn: func(context.Context) error {
+				if globalEventNotifier != nil {
+					globalEventNotifier.RemoveAllBucketTargets()
+				}
+				return nil
+			},
+		},
+		{
+			name:    "object-layer",
+			timeout: 30 * time.Second,
+			fn: func(ctx context.Context) error {
+				if objAPI := newObjectLayerFn(); objAPI != nil {
+					return objAPI.Shutdown(ctx)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "console",
+			timeout: 5 * time.Second,
+			fn: func(context.Context) error {
+				if globalBrowserEnabled {
+					if srv := newConsoleServerFn(); srv != nil {
+						return srv.Shutdown()
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
@@ -19,30 +19,13 @@ package cmd
 
 import (
 	"context"
-	"errors"
-	"net/http"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/coreos/go-systemd/v22/daemon"
 	"github.com/minio/minio/internal/logger"
 )
 
-func shutdownHealMRFWithTimeout() {
-	const shutdownTimeout = time.Minute
-
-	finished := make(chan struct{})
-	go func() {
-		globalMRFState.shutdown()
-		close(finished)
-	}()
-	select {
-	case <-time.After(shutdownTimeout):
-	case <-finished:
-	}
-}
-
 func handleSignals() {
 	// Custom exit function
 	exit := func(success bool) {
@@ -65,32 +48,10 @@ func handleSignals() {
 	}
 
 	stopProcess := func() bool {
-		shutdownHealMRFWithTimeout() // this can take time sometimes, it needs to be executed
-		// before stopping s3 operations
-
-		// send signal to various go-routines that they need to quit.
-		cancelGlobalContext()
-
-		if httpServer := newHTTPServerFn(); httpServer != nil {
-			if err := httpServer.Shutdown(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-				shutdownLogIf(context.Background(), err)
-			}
-		}
-
-		if objAPI := newObjectLayerFn(); objAPI != nil {
-			shutdownLogIf(context.Background(), objAPI.Shutdown(context.Background()))
-		}
-
-		if globalBrowserEnabled {
-			if srv := newConsoleServerFn(); srv != nil {
-				shutdownLogIf(context.Background(), srv.Shutdown())
-			}
-		}
-
-		if globalEventNotifier != nil {
-			globalEventNotifier.RemoveAllBucketTargets()
-		}
-
+		// Run the ordered shutdown sequence, each subsystem under
+		// its own deadline so a stuck subsystem cannot wedge the
+		// process forever.
+		runShutdownSequence(shutdownSteps())
 		return true
 	}
 
@@ -0,0 +1,241 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/minio/internal/mcontext"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Structured slow operation log. Requests slower than the threshold of
+// their API class are recorded as JSON lines - one object per entry
+// with identity, sizes and a per-phase timing breakdown - either to a
+// rotating file or to the server log targets.
+const (
+	// EnvSlowOpLog enables the slow operation log, disabled by default.
+	EnvSlowOpLog = "MINIO_SLOW_OP_LOG"
+
+	// EnvSlowOpLogDir is the directory the rotating slow operation log
+	// files are written to, entries go to the server log targets when
+	// unset.
+	EnvSlowOpLogDir = "MINIO_SLOW_OP_LOG_DIR"
+
+	// EnvSlowOpLogThreshold is the default latency threshold applied to
+	// all API classes, defaults to 1s.
+	EnvSlowOpLogThreshold = "MINIO_SLOW_OP_LOG_THRESHOLD"
+
+	// Per API class threshold overrides.
+	EnvSlowOpLogReadThreshold   = "MINIO_SLOW_OP_LOG_READ_THRESHOLD"
+	EnvSlowOpLogWriteThreshold  = "MINIO_SLOW_OP_LOG_WRITE_THRESHOLD"
+	EnvSlowOpLogListThreshold   = "MINIO_SLOW_OP_LOG_LIST_THRESHOLD"
+	EnvSlowOpLogDeleteThreshold = "MINIO_SLOW_OP_LOG_DELETE_THRESHOLD"
+
+	defaultSlowOpThreshold = time.Second
+
+	// slowOpLogFileSize is the size one slow operation log file may
+	// reach before it is rotated.
+	slowOpLogFileSize = 64 << 20
+)
+
+// API classes of the slow operation log.
+const (
+	slowOpClassRead   = "read"
+	slowOpClassWrite  = "write"
+	slowOpClassList   = "list"
+	slowOpClassDelete = "delete"
+	slowOpClassOther  = "other"
+)
+
+// slowOpEntry is one JSON line in the slow operation log.
+type slowOpEntry struct {
+	Time       time.Time    `json:"time"`
+	API        string       `json:"api"`
+	Class      string       `json:"class"`
+	Bucket     string       `json:"bucket,omitempty"`
+	Object     string       `json:"object,omitempty"`
+	AccessKey  string       `json:"accessKey,omitempty"`
+	ParentUser string       `json:"parentUser,omitempty"`
+	StatusCode int          `json:"statusCode"`
+	BytesIn    int64        `json:"bytesIn"`
+	BytesOut   int64        `json:"bytesOut"`
+	DurationMs int64        `json:"durationMs"`
+	Phases     slowOpPhases `json:"phases"`
+}
+
+// slowOpPhases is the per-phase timing breakdown of one slow request,
+// in milliseconds. Auth covers request authentication, processing is
+// the server side work up to the first response byte (quorum reads,
+// disk and internode I/O) and transfer is the response body send.
+type slowOpPhases struct {
+	AuthMs       int64 `json:"authMs"`
+	ProcessingMs int64 `json:"processingMs"`
+	TransferMs   int64 `json:"transferMs"`
+}
+
+type slowOpLog struct {
+	enabled    bool
+	thresholds map[string]time.Duration
+
+	mu     sync.Mutex
+	writer io.WriteCloser
+}
+
+var (
+	slowOpLogOnce    sync.Once
+	globalSlowOpLog  = &slowOpLog{}
+	slowOpLogInitErr error
+)
+
+func slowOpLogConfig() *slowOpLog {
+	slowOpLogOnce.Do(func() {
+		if env.Get(EnvSlowOpLog, config.EnableOff) != config.EnableOn {
+			return
+		}
+
+		defThreshold := defaultSlowOpThreshold
+		if d, err := time.ParseDuration(env.Get(EnvSlowOpLogThreshold, "")); err == nil && d > 0 {
+			defThreshold = d
+		}
+		thresholds := map[string]time.Duration{
+			slowOpClassRead:   defThreshold,
+			slowOpClassWrite:  defThreshold,
+			slowOpClassList:   defThreshold,
+			slowOpClassDelete: defThreshold,
+			slowOpClassOther:  defThreshold,
+		}
+		for class, envVar := range map[string]string{
+			slowOpClassRead:   EnvSlowOpLogReadThreshold,
+			slowOpClassWrite:  EnvSlowOpLogWriteThreshold,
+			slowOpClassList:   EnvSlowOpLogListThreshold,
+			slowOpClassDelete: EnvSlowOpLogDeleteThreshold,
+		} {
+			if d, err := time.ParseDuration(env.Get(envVar, "")); err == nil && d > 0 {
+				thresholds[class] = d
+			}
+		}
+
+		globalSlowOpLog.thresholds = thresholds
+
+		if dir := env.Get(EnvSlowOpLogDir, ""); dir != "" {
+			writer, err := logger.NewDir(logger.Options{
+				Directory:       dir,
+				MaximumFileSize: slowOpLogFileSize,
+				FileNameFunc: func() string {
+					return fmt.Sprintf("minio-slowops-%X.log", time.Now().UTC().UnixNano())
+				},
+			})
+			if err != nil {
+				slowOpLogInitErr = fmt.Errorf("unable to initialize slow operation log directory %s: %w", dir, err)
+				internalLogIf(GlobalContext, slowOpLogInitErr)
+				return
+			}
+			globalSlowOpLog.writer = writer
+		}
+
+		globalSlowOpLog.enabled = true
+	})
+	return globalSlowOpLog
+}
+
+// slowOpClass maps an API name to its threshold class.
+func slowOpClass(api string) string {
+	switch {
+	case strings.HasPrefix(api, "List"):
+		return slowOpClassList
+	case strings.HasPrefix(api, "Get"), strings.HasPrefix(api, "Head"), strings.HasPrefix(api, "Select"):
+		return slowOpClassRead
+	case strings.HasPrefix(api, "Put"), strings.HasPrefix(api, "Copy"),
+		strings.HasPrefix(api, "NewMultipart"), strings.HasPrefix(api, "CompleteMultipart"):
+		return slowOpClassWrite
+	case strings.HasPrefix(api, "Delete"), strings.HasPrefix(api, "AbortMultipart"):
+		return slowOpClassDelete
+	}
+	return slowOpClassOther
+}
+
+// record writes one slow operation log entry when the request exceeded
+// the threshold of its API class.
+func (l *slowOpLog) record(api, bucket, object string, tc *mcontext.TraceCtxt) {
+	if l = slowOpLogConfig(); !l.enabled {
+		return
+	}
+
+	total := time.Since(tc.ResponseRecorder.StartTime)
+	class := slowOpClass(api)
+	if total < l.thresholds[class] {
+		return
+	}
+
+	var auth, processing time.Duration
+	if !tc.AuthTime.IsZero() {
+		auth = tc.AuthTime.Sub(tc.ResponseRecorder.StartTime)
+	}
+	ttfb := tc.ResponseRecorder.TTFB()
+	if ttfb > auth {
+		processing = ttfb - auth
+	}
+	var transfer time.Duration
+	if total > auth+processing {
+		transfer = total - auth - processing
+	}
+
+	entry := slowOpEntry{
+		Time:       tc.ResponseRecorder.StartTime,
+		API:        api,
+		Class:      class,
+		Bucket:     bucket,
+		Object:     object,
+		AccessKey:  tc.AccessKey,
+		ParentUser: tc.ParentUser,
+		StatusCode: tc.ResponseRecorder.StatusCode,
+		BytesIn:    int64(tc.RequestRecorder.Size()),
+		BytesOut:   int64(tc.ResponseRecorder.Size()),
+		DurationMs: total.Milliseconds(),
+		Phases: slowOpPhases{
+			AuthMs:       auth.Milliseconds(),
+			ProcessingMs: processing.Milliseconds(),
+			TransferMs:   transfer.Milliseconds(),
+		},
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		internalLogIf(GlobalContext, err)
+		return
+	}
+
+	if l.writer != nil {
+		l.mu.Lock()
+		_, err = l.writer.Write(append(data, '\n'))
+		l.mu.Unlock()
+		internalLogIf(GlobalContext, err)
+		return
+	}
+
+	logger.Event(context.Background(), "slowlog", "%s", string(data))
+}
@@ -0,0 +1,485 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio/internal/auth"
+	xioutil "github.com/minio/minio/internal/ioutil"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Profile speed tests. The classic speedtest measures sequential
+// GET/PUT of one object size, which overstates what a cluster delivers
+// under production mixes of small objects, concurrent reads and
+// writes, and listing traffic. A profile run drives all three at once:
+// writers pick sizes from a distribution, a configured share of the
+// workers reads back what was written, and optional listing workers
+// hammer metadata with full ListObjectsV2 sweeps. Results come back as
+// JSON with a per-node breakdown.
+
+// speedTestProfileOpts describes one profile run.
+type speedTestProfileOpts struct {
+	sizes        []int // object size distribution, picked uniformly per upload
+	readPerc     int   // percent of workers that read instead of write
+	listers      int   // additional metadata-heavy listing workers
+	concurrency  int
+	duration     time.Duration
+	storageClass string
+	bucketName   string
+	enableSha256 bool
+	creds        auth.Credentials
+}
+
+// SpeedTestProfileResult is the raw per node outcome of a profile run,
+// exchanged between peers.
+type SpeedTestProfileResult struct {
+	Endpoint      string
+	Uploads       uint64
+	UploadBytes   uint64
+	Downloads     uint64
+	DownloadBytes uint64
+	Listings      uint64
+	ListedEntries uint64
+	UploadTimes   madmin.TimeDurations
+	DownloadTimes madmin.TimeDurations
+	ListTimes     madmin.TimeDurations
+	Error         string
+}
+
+// speedTestProfileNodeReport is the per node JSON breakdown.
+type speedTestProfileNodeReport struct {
+	Endpoint         string         `json:"endpoint"`
+	Err              string         `json:"err,omitempty"`
+	PutThroughput    uint64         `json:"putThroughputPerSec"`
+	PutOpsPerSec     uint64         `json:"putObjectsPerSec"`
+	GetThroughput    uint64         `json:"getThroughputPerSec"`
+	GetOpsPerSec     uint64         `json:"getObjectsPerSec"`
+	ListingsPerSec   uint64         `json:"listingsPerSec"`
+	ListedEntries    uint64         `json:"listedEntries"`
+	PutResponseTime  madmin.Timings `json:"putResponseTime"`
+	GetResponseTime  madmin.Timings `json:"getResponseTime"`
+	ListResponseTime madmin.Timings `json:"listResponseTime"`
+}
+
+// speedTestProfileReport is the JSON result of a profile run.
+type speedTestProfileReport struct {
+	Version       string `json:"version"`
+	Servers       int    `json:"servers"`
+	Disks         int    `json:"disks"`
+	Concurrent    int    `json:"concurrent"`
+	ReadPerc      int    `json:"readPerc"`
+	Listers       int    `json:"listers"`
+	Sizes         []int  `json:"sizes"`
+	DurationSecs  int    `json:"durationSecs"`
+	PutThroughput uint64 `json:"putThroughputPerSec"`
+	PutOpsPerSec  uint64 `json:"putObjectsPerSec"`
+	GetThroughput uint64 `json:"getThroughputPerSec"`
+	GetOpsPerSec  uint64 `json:"getObjectsPerSec"`
+
+	Nodes []speedTestProfileNodeReport `json:"nodes"`
+}
+
+// selfSpeedTestProfile runs the mixed workload on the local node.
+func selfSpeedTestProfile(ctx context.Context, opts speedTestProfileOpts) (res SpeedTestProfileResult, err error) {
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return res, errServerNotInitialized
+	}
+
+	clnt, err := speedTestClient(opts.creds)
+	if err != nil {
+		return res, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, opts.duration)
+	defer cancel()
+
+	objNamePrefix := pathJoin(speedTest, mustGetUUID())
+
+	popts := minio.PutObjectOptions{
+		UserMetadata:         map[string]string{globalObjectPerfUserMetadata: "true"}, // Bypass S3 API freeze
+		DisableContentSha256: !opts.enableSha256,
+		DisableMultipart:     true,
+		StorageClass:         opts.storageClass,
+	}
+	gopts := minio.GetObjectOptions{}
+	gopts.Set(globalObjectPerfUserMetadata, "true") // Bypass S3 API freeze
+
+	readers := opts.concurrency * opts.readPerc / 100
+	writers := opts.concurrency - readers
+	if writers < 1 && opts.readPerc < 100 {
+		writers, readers = 1, opts.concurrency-1
+	}
+
+	var (
+		mu      sync.Mutex
+		written []string // names readers sample from
+		errOnce sync.Once
+		retErr  string
+
+		uploads, uploadBytes, downloads, downloadBytes, listings, listedEntries uint64
+
+		uploadTimes, downloadTimes, listTimes madmin.TimeDurations
+	)
+	fail := func(err error) {
+		if !contextCanceled(runCtx) {
+			errOnce.Do(func() {
+				retErr = err.Error()
+			})
+		}
+		cancel()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(time.Now().UnixNano() + int64(i)))
+			for n := 0; ; n++ {
+				if contextCanceled(runCtx) {
+					return
+				}
+				size := opts.sizes[rng.Intn(len(opts.sizes))]
+				name := pathJoin(objNamePrefix, fmt.Sprintf("%d/%d", i, n))
+				t := time.Now()
+				info, err := clnt.PutObject(runCtx, opts.bucketName, name, newRandomReader(size), int64(size), popts)
+				if err != nil {
+					if !contextCanceled(runCtx) {
+						fail(err)
+					}
+					return
+				}
+				atomic.AddUint64(&uploads, 1)
+				atomic.AddUint64(&uploadBytes, uint64(info.Size))
+				mu.Lock()
+				uploadTimes = append(uploadTimes, time.Since(t))
+				written = append(written, name)
+				mu.Unlock()
+			}
+		}(i)
+	}
+
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(time.Now().UnixNano() - int64(i)))
+			for {
+				if contextCanceled(runCtx) {
+					return
+				}
+				mu.Lock()
+				var name string
+				if len(written) > 0 {
+					name = written[rng.Intn(len(written))]
+				}
+				mu.Unlock()
+				if name == "" {
+					// Nothing written yet, wait for the writers.
+					time.Sleep(10 * time.Millisecond)
+					continue
+				}
+				t := time.Now()
+				obj, err := clnt.GetObject(runCtx, opts.bucketName, name, gopts)
+				if err != nil {
+					fail(err)
+					return
+				}
+				n, err := xioutil.Copy(xioutil.Discard, obj)
+				obj.Close()
+				if err != nil {
+					if !contextCanceled(runCtx) {
+						fail(err)
+					}
+					return
+				}
+				atomic.AddUint64(&downloads, 1)
+				atomic.AddUint64(&downloadBytes, uint64(n))
+				mu.Lock()
+				downloadTimes = append(downloadTimes, time.Since(t))
+				mu.Unlock()
+			}
+		}(i)
+	}
+
+	for i := 0; i < opts.listers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if contextCanceled(runCtx) {
+					return
+				}
+				t := time.Now()
+				var entries uint64
+				for obj := range clnt.ListObjects(runCtx, opts.bucketName, minio.ListObjectsOptions{
+					Prefix:       objNamePrefix,
+					Recursive:    true,
+					WithMetadata: true,
+				}) {
+					if obj.Err != nil {
+						if !contextCanceled(runCtx) {
+							fail(obj.Err)
+						}
+						return
+					}
+					entries++
+				}
+				atomic.AddUint64(&listings, 1)
+				atomic.AddUint64(&listedEntries, entries)
+				mu.Lock()
+				listTimes = append(listTimes, time.Since(t))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return SpeedTestProfileResult{
+		Uploads:       uploads,
+		UploadBytes:   uploadBytes,
+		Downloads:     downloads,
+		DownloadBytes: downloadBytes,
+		Listings:      listings,
+		ListedEntries: listedEntries,
+		UploadTimes:   uploadTimes,
+		DownloadTimes: downloadTimes,
+		ListTimes:     listTimes,
+		Error:         retErr,
+	}, nil
+}
+
+// objectSpeedTestProfile fans the profile run out to all nodes and
+// aggregates the report.
+func objectSpeedTestProfile(ctx context.Context, opts speedTestProfileOpts) speedTestProfileReport {
+	results := globalNotificationSys.SpeedTestProfile(ctx, opts)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Endpoint < results[j].Endpoint
+	})
+
+	durationSecs := uint64(opts.duration.Seconds())
+	if durationSecs == 0 {
+		durationSecs = 1
+	}
+
+	report := speedTestProfileReport{
+		Version:      Version,
+		Servers:      len(globalNotificationSys.peerClients) + 1,
+		Disks:        globalEndpoints.NEndpoints(),
+		Concurrent:   opts.concurrency,
+		ReadPerc:     opts.readPerc,
+		Listers:      opts.listers,
+		Sizes:        opts.sizes,
+		DurationSecs: int(durationSecs),
+	}
+	for _, result := range results {
+		report.PutThroughput += result.UploadBytes / durationSecs
+		report.PutOpsPerSec += result.Uploads / durationSecs
+		report.GetThroughput += result.DownloadBytes / durationSecs
+		report.GetOpsPerSec += result.Downloads / durationSecs
+
+		report.Nodes = append(report.Nodes, speedTestProfileNodeReport{
+			Endpoint:         result.Endpoint,
+			Err:              result.Error,
+			PutThroughput:    result.UploadBytes / durationSecs,
+			PutOpsPerSec:     result.Uploads / durationSecs,
+			GetThroughput:    result.DownloadBytes / durationSecs,
+			GetOpsPerSec:     result.Downloads / durationSecs,
+			ListingsPerSec:   result.Listings / durationSecs,
+			ListedEntries:    result.ListedEntries,
+			PutResponseTime:  result.UploadTimes.Measure(),
+			GetResponseTime:  result.DownloadTimes.Measure(),
+			ListResponseTime: result.ListTimes.Measure(),
+		})
+	}
+	return report
+}
+
+// parseProfileSizes parses a comma separated size list.
+func parseProfileSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, v := range strings.Split(s, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || size <= 0 {
+			return nil, errInvalidArgument
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// SpeedTestProfileHandler - POST /minio/admin/v3/speedtest/profile
+// Runs a mixed-workload benchmark. Query parameters: sizes (comma
+// separated object sizes, default a small-object mix), readPerc
+// (percent of workers reading, default 50), listers (listing workers,
+// default 0), concurrent and duration as in the classic speedtest.
+func (a adminAPIHandlers) SpeedTestProfileHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	objectAPI, creds := validateAdminReq(ctx, w, r, policy.HealthInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	if !globalAPIConfig.permitRootAccess() {
+		rd, wr := isAllowedRWAccess(r, creds, globalObjectPerfBucket)
+		if !rd || !wr {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+				Code: "XMinioSpeedtestInsufficientPermissions",
+				Message: fmt.Sprintf("%s does not have read and write access to '%s' bucket", creds.AccessKey,
+					globalObjectPerfBucket),
+				StatusCode: http.StatusForbidden,
+			}), r.URL)
+			return
+		}
+	}
+
+	sizes := []int{8 * humanize.KiByte, 64 * humanize.KiByte, humanize.MiByte}
+	if v := r.Form.Get(peerRESTSizes); v != "" {
+		var err error
+		if sizes, err = parseProfileSizes(v); err != nil {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+	}
+
+	readPerc := 50
+	if v := r.Form.Get(peerRESTReadPerc); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 0 || p > 100 {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+		readPerc = p
+	}
+
+	listers := 0
+	if v := r.Form.Get(peerRESTListers); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l < 0 {
+			writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+			return
+		}
+		listers = l
+	}
+
+	concurrent, err := strconv.Atoi(r.Form.Get(peerRESTConcurrent))
+	if err != nil || concurrent <= 0 {
+		concurrent = 32
+	}
+
+	duration, err := time.ParseDuration(r.Form.Get(peerRESTDuration))
+	if err != nil || duration <= 0 {
+		duration = time.Second * 10
+	}
+
+	maxSize := 0
+	for _, size := range sizes {
+		if size > maxSize {
+			maxSize = size
+		}
+	}
+	storageInfo := objectAPI.StorageInfo(ctx, false)
+	sufficientCapacity, _, capacityErrMsg := validateObjPerfOptions(ctx, storageInfo, concurrent, maxSize, false)
+	if !sufficientCapacity {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, AdminError{
+			Code:       "XMinioSpeedtestInsufficientCapacity",
+			Message:    capacityErrMsg,
+			StatusCode: http.StatusInsufficientStorage,
+		}), r.URL)
+		return
+	}
+
+	bucketExists, err := makeObjectPerfBucket(ctx, objectAPI, globalObjectPerfBucket)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if !bucketExists {
+		defer deleteObjectPerfBucket(objectAPI)
+	}
+	defer objectAPI.DeleteObject(ctx, globalObjectPerfBucket, speedTest+SlashSeparator, ObjectOptions{
+		DeletePrefix: true,
+	})
+
+	// Freeze all incoming S3 API calls before running the profile.
+	globalNotificationSys.ServiceFreeze(ctx, true)
+
+	// Unfreeze as soon as request context is canceled or when the function returns.
+	go func() {
+		<-ctx.Done()
+		globalNotificationSys.ServiceFreeze(ctx, false)
+	}()
+
+	opts := speedTestProfileOpts{
+		sizes:        sizes,
+		readPerc:     readPerc,
+		listers:      listers,
+		concurrency:  concurrent,
+		duration:     duration,
+		storageClass: strings.TrimSpace(r.Form.Get(peerRESTStorageClass)),
+		bucketName:   globalObjectPerfBucket,
+		enableSha256: r.Form.Get(peerRESTEnableSha256) == "true",
+		creds:        creds,
+	}
+
+	done := make(chan speedTestProfileReport, 1)
+	go func() {
+		done <- objectSpeedTestProfile(ctx, opts)
+	}()
+
+	// Keep the connection alive while the run is in progress.
+	keepAliveTicker := time.NewTicker(500 * time.Millisecond)
+	defer keepAliveTicker.Stop()
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAliveTicker.C:
+			if err := enc.Encode(speedTestProfileReport{}); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+		case report := <-done:
+			if err := enc.Encode(report); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/minio/minio/internal/event"
+	"github.com/minio/minio/internal/pubsub"
+	"github.com/minio/pkg/v3/env"
+)
+
+// EnvSystemEventsTargets is a comma separated list of notification
+// target IDs - in "<name>:<id>" form, e.g. "webhook:1,kafka:1" - that
+// receive infrastructure (system) events: heal start/completion per
+// erasure set, drive offline/online transitions and rebalance
+// milestones. System events are opt-in; without this variable they are
+// only visible to `ListenNotification` subscribers.
+const EnvSystemEventsTargets = "MINIO_SYSTEM_EVENTS_TARGETS"
+
+var (
+	systemEventTargetsOnce sync.Once
+	systemEventTargetIDs   event.TargetIDSet
+)
+
+// systemEventTargets returns the set of notification targets configured
+// to receive system events, parsed once from the environment.
+func systemEventTargets() event.TargetIDSet {
+	systemEventTargetsOnce.Do(func() {
+		systemEventTargetIDs = event.NewTargetIDSet()
+		for _, s := range strings.Split(env.Get(EnvSystemEventsTargets, ""), ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			tokens := strings.SplitN(s, ":", 2)
+			if len(tokens) != 2 {
+				continue
+			}
+			systemEventTargetIDs[event.TargetID{Name: tokens[0], ID: tokens[1]}] = struct{}{}
+		}
+	})
+	return systemEventTargetIDs
+}
+
+// sendSystemEvent sends an infrastructure event to the configured
+// system event targets and to `ListenNotification` subscribers. Unlike
+// object events, system events are not matched against per-bucket
+// notification rules - params carries the event specific details such
+// as pool/set indices or the drive endpoint.
+func sendSystemEvent(eventName event.Name, params map[string]string) {
+	args := eventArgs{
+		EventName:  eventName,
+		BucketName: minioReservedBucket,
+		ReqParams:  params,
+		Host:       globalLocalNodeName,
+	}
+
+	if globalHTTPListen.NumSubscribers(pubsub.MaskFromMaskable(args.EventName)) > 0 {
+		globalHTTPListen.Publish(args.ToEvent(false))
+	}
+
+	globalEventNotifier.SendToTargets(args, systemEventTargets())
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/internal/auth"
+	"github.com/minio/minio/internal/mcontext"
+	"github.com/minio/pkg/v3/policy"
+)
+
+const (
+	// tenantMetricsWindowSlots is the size of the sliding window ring,
+	// one slot per minute.
+	tenantMetricsWindowSlots = 60
+
+	// tenantMetricsMaxKeys caps the tracked access keys per slot, the
+	// overflow is aggregated under tenantMetricsOverflowKey.
+	tenantMetricsMaxKeys     = 1000
+	tenantMetricsOverflowKey = "_other"
+
+	// tenantMetricsDefaultWindow is the aggregation window used when a
+	// request does not specify one.
+	tenantMetricsDefaultWindow = 15 * time.Minute
+)
+
+// tenantLatencyBuckets are the upper bounds in seconds used for
+// percentile estimation of per-tenant request latencies.
+var tenantLatencyBuckets = [...]float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// tenantSlotStats accumulates requests of one access key within one
+// window slot.
+type tenantSlotStats struct {
+	parentUser string
+	requests   uint64
+	errors4xx  uint64
+	errors5xx  uint64
+	bytesIn    uint64
+	bytesOut   uint64
+	latency    [len(tenantLatencyBuckets) + 1]uint64 // +1 for +Inf
+}
+
+// tenantMetricsSlot is one minute of per-access-key aggregation.
+type tenantMetricsSlot struct {
+	start time.Time
+	keys  map[string]*tenantSlotStats
+}
+
+// tenantMetrics aggregates request counts, bytes, error rates and
+// latency distributions per access key over a sliding window.
+type tenantMetrics struct {
+	mu    sync.Mutex
+	slots [tenantMetricsWindowSlots]tenantMetricsSlot
+}
+
+var globalTenantMetrics = &tenantMetrics{}
+
+// setTenantInTraceCtxt stamps the authenticated requester identity into
+// the request trace context for per-tenant metrics accounting.
+func setTenantInTraceCtxt(ctx context.Context, cred auth.Credentials) {
+	if tc, ok := ctx.Value(mcontext.ContextTraceKey).(*mcontext.TraceCtxt); ok {
+		tc.AccessKey = cred.AccessKey
+		tc.ParentUser = cred.ParentUser
+		tc.AuthTime = time.Now().UTC()
+	}
+}
+
+func (t *tenantMetrics) slot(now time.Time) *tenantMetricsSlot {
+	idx := now.Unix() / 60 % tenantMetricsWindowSlots
+	s := &t.slots[idx]
+	minute := now.Truncate(time.Minute)
+	if !s.start.Equal(minute) {
+		s.start = minute
+		s.keys = make(map[string]*tenantSlotStats)
+	}
+	return s
+}
+
+// record accounts one finished request against its access key.
+func (t *tenantMetrics) record(accessKey, parentUser string, statusCode int, bytesIn, bytesOut int64, duration time.Duration) {
+	if accessKey == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.slot(time.Now().UTC())
+	ts, ok := s.keys[accessKey]
+	if !ok {
+		if len(s.keys) >= tenantMetricsMaxKeys {
+			accessKey = tenantMetricsOverflowKey
+			parentUser = ""
+		}
+		if ts, ok = s.keys[accessKey]; !ok {
+			ts = &tenantSlotStats{parentUser: parentUser}
+			s.keys[accessKey] = ts
+		}
+	}
+
+	ts.requests++
+	switch {
+	case statusCode >= 500:
+		ts.errors5xx++
+	case statusCode >= 400:
+		ts.errors4xx++
+	}
+	ts.bytesIn += uint64(bytesIn)
+	ts.bytesOut += uint64(bytesOut)
+
+	secs := duration.Seconds()
+	bucket := len(tenantLatencyBuckets)
+	for i, ub := range tenantLatencyBuckets {
+		if secs <= ub {
+			bucket = i
+			break
+		}
+	}
+	ts.latency[bucket]++
+}
+
+// tenantUsage is the aggregated usage of one access key as reported by
+// the admin API.
+type tenantUsage struct {
+	AccessKey   string  `json:"accessKey"`
+	ParentUser  string  `json:"parentUser,omitempty"`
+	Requests    uint64  `json:"requests"`
+	Errors4xx   uint64  `json:"errors4xx"`
+	Errors5xx   uint64  `json:"errors5xx"`
+	BytesIn     uint64  `json:"bytesIn"`
+	BytesOut    uint64  `json:"bytesOut"`
+	LatencyP50  float64 `json:"latencyP50"`
+	LatencyP90  float64 `json:"latencyP90"`
+	LatencyP99  float64 `json:"latencyP99"`
+	latencyHist [len(tenantLatencyBuckets) + 1]uint64
+}
+
+// latencyPercentile estimates a percentile as the upper bound of the
+// histogram bucket the percentile falls into.
+func latencyPercentile(hist [len(tenantLatencyBuckets) + 1]uint64, p float64) float64 {
+	var total uint64
+	for _, n := range hist {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	target := uint64(p * float64(total))
+	if target == 0 {
+		target = 1
+	}
+	var cum uint64
+	for i, n := range hist {
+		cum += n
+		if cum >= target {
+			if i == len(tenantLatencyBuckets) {
+				return tenantLatencyBuckets[len(tenantLatencyBuckets)-1]
+			}
+			return tenantLatencyBuckets[i]
+		}
+	}
+	return tenantLatencyBuckets[len(tenantLatencyBuckets)-1]
+}
+
+// report aggregates all slots within the window into per-access-key
+// usage, sorted by request count.
+func (t *tenantMetrics) report(window time.Duration) []tenantUsage {
+	if window <= 0 || window > tenantMetricsWindowSlots*time.Minute {
+		window = tenantMetricsWindowSlots * time.Minute
+	}
+	cutoff := time.Now().UTC().Add(-window)
+
+	t.mu.Lock()
+	byKey := make(map[string]*tenantUsage)
+	for i := range t.slots {
+		s := &t.slots[i]
+		if s.start.Before(cutoff) || s.keys == nil {
+			continue
+		}
+		for accessKey, ts := range s.keys {
+			u, ok := byKey[accessKey]
+			if !ok {
+				u = &tenantUsage{AccessKey: accessKey, ParentUser: ts.parentUser}
+				byKey[accessKey] = u
+			}
+			u.Requests += ts.requests
+			u.Errors4xx += ts.errors4xx
+			u.Errors5xx += ts.errors5xx
+			u.BytesIn += ts.bytesIn
+			u.BytesOut += ts.bytesOut
+			for j, n := range ts.latency {
+				u.latencyHist[j] += n
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	usages := make([]tenantUsage, 0, len(byKey))
+	for _, u := range byKey {
+		u.LatencyP50 = latencyPercentile(u.latencyHist, 0.50)
+		u.LatencyP90 = latencyPercentile(u.latencyHist, 0.90)
+		u.LatencyP99 = latencyPercentile(u.latencyHist, 0.99)
+		usages = append(usages, *u)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Requests > usages[j].Requests
+	})
+	return usages
+}
+
+// Per-tenant metric labels and names for the metrics/v3 endpoint.
+const (
+	accessKeyL  = "access_key"
+	parentUserL = "parent_user"
+
+	tenantRequestsTotal     = "requests_total"
+	tenantErrors4xxTotal    = "errors_4xx_total"
+	tenantErrors5xxTotal    = "errors_5xx_total"
+	tenantTrafficRecvBytes  = "traffic_received_bytes"
+	tenantTrafficSentBytes  = "traffic_sent_bytes"
+	tenantLatencyP50Seconds = "latency_p50_seconds"
+	tenantLatencyP99Seconds = "latency_p99_seconds"
+)
+
+var allTenantLabels = []string{accessKeyL, parentUserL}
+
+var (
+	tenantRequestsTotalMD = NewCounterMD(tenantRequestsTotal,
+		"Total requests by access key in the last hour", allTenantLabels...)
+	tenantErrors4xxTotalMD = NewCounterMD(tenantErrors4xxTotal,
+		"Total 4xx errors by access key in the last hour", allTenantLabels...)
+	tenantErrors5xxTotalMD = NewCounterMD(tenantErrors5xxTotal,
+		"Total 5xx errors by access key in the last hour", allTenantLabels...)
+	tenantTrafficRecvBytesMD = NewCounterMD(tenantTrafficRecvBytes,
+		"Total bytes received by access key in the last hour", allTenantLabels...)
+	tenantTrafficSentBytesMD = NewCounterMD(tenantTrafficSentBytes,
+		"Total bytes sent by access key in the last hour", allTenantLabels...)
+	tenantLatencyP50SecondsMD = NewGaugeMD(tenantLatencyP50Seconds,
+		"Estimated median request latency by access key in the last hour", allTenantLabels...)
+	tenantLatencyP99SecondsMD = NewGaugeMD(tenantLatencyP99Seconds,
+		"Estimated 99th percentile request latency by access key in the last hour", allTenantLabels...)
+)
+
+// loadTenantMetrics - `MetricsLoaderFn` for per-access-key metrics,
+// aggregated over the full sliding window.
+func loadTenantMetrics(_ context.Context, m MetricValues, _ *metricsCache) error {
+	for _, u := range globalTenantMetrics.report(tenantMetricsWindowSlots * time.Minute) {
+		labels := []string{accessKeyL, u.AccessKey, parentUserL, u.ParentUser}
+		m.Set(tenantRequestsTotal, float64(u.Requests), labels...)
+		m.Set(tenantErrors4xxTotal, float64(u.Errors4xx), labels...)
+		m.Set(tenantErrors5xxTotal, float64(u.Errors5xx), labels...)
+		m.Set(tenantTrafficRecvBytes, float64(u.BytesIn), labels...)
+		m.Set(tenantTrafficSentBytes, float64(u.BytesOut), labels...)
+		m.Set(tenantLatencyP50Seconds, u.LatencyP50, labels...)
+		m.Set(tenantLatencyP99Seconds, u.LatencyP99, labels...)
+	}
+	return nil
+}
+
+// TenantMetricsHandler - returns per-access-key usage aggregated over a
+// sliding window, for chargeback and abuse detection.
+//
+// GET /minio/admin/v3/tenant-metrics?window=15m
+func (a adminAPIHandlers) TenantMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	window := tenantMetricsDefaultWindow
+	if v := r.Form.Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+			return
+		}
+		window = d
+	}
+
+	usageData, err := json.Marshal(globalTenantMetrics.report(window))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, usageData)
+}
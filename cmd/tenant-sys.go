@@ -0,0 +1,353 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/auth"
+	"github.com/minio/pkg/v3/policy"
+)
+
+// Lightweight multi-tenancy. A tenant is a namespace prefix grouping
+// buckets, IAM users and policies, with a delegated admin user that may
+// manage entities only within that prefix through the existing admin
+// API. Tenant definitions are stored under the config prefix on the
+// object layer.
+const (
+	// tenantConfigPrefix is where tenant definitions are stored.
+	tenantConfigPrefix = minioConfigPrefix + "/tenants"
+
+	// tenantRefreshInterval is how often every node reloads the tenant
+	// definitions from the config store. The node handling a tenant
+	// change applies it immediately, its peers converge within this
+	// interval - without the reload they would only see changes after
+	// a restart and the prefix confinement would not hold cluster wide.
+	tenantRefreshInterval = time.Minute
+)
+
+// tenantInfo is one tenant definition.
+type tenantInfo struct {
+	// Name of the tenant.
+	Name string `json:"name"`
+
+	// Prefix all buckets, users and policies of the tenant share,
+	// defaults to '<name>-'.
+	Prefix string `json:"prefix"`
+
+	// AdminAccessKey is the IAM user delegated to administer the
+	// tenant namespace.
+	AdminAccessKey string `json:"adminAccessKey"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TenantSys holds the loaded tenant definitions.
+type TenantSys struct {
+	mu      sync.RWMutex
+	tenants map[string]*tenantInfo
+	byAdmin map[string]*tenantInfo
+}
+
+var globalTenantSys = &TenantSys{
+	tenants: make(map[string]*tenantInfo),
+	byAdmin: make(map[string]*tenantInfo),
+}
+
+func tenantConfigPath(name string) string {
+	return path.Join(tenantConfigPrefix, name+".json")
+}
+
+// initTenantSys loads the tenant definitions from the object layer and
+// keeps reloading them so changes made through a peer take effect here.
+func initTenantSys(ctx context.Context, objAPI ObjectLayer) {
+	globalTenantSys.load(ctx, objAPI)
+	if !globalIsTesting {
+		go globalTenantSys.refreshLoop(ctx, objAPI)
+	}
+}
+
+// load reads all tenant definitions from the config store and replaces
+// the in-memory maps wholesale, so removals propagate as well.
+func (sys *TenantSys) load(ctx context.Context, objAPI ObjectLayer) {
+	tenants := make(map[string]*tenantInfo)
+	marker := ""
+	for {
+		res, err := objAPI.ListObjects(ctx, minioMetaBucket, tenantConfigPrefix, marker, "", maxObjectList)
+		if err != nil {
+			internalLogIf(ctx, fmt.Errorf("unable to list tenant definitions: %w", err))
+			return
+		}
+		for _, obj := range res.Objects {
+			data, err := readConfig(ctx, objAPI, obj.Name)
+			if err != nil {
+				internalLogIf(ctx, fmt.Errorf("unable to read tenant definition %s: %w", obj.Name, err))
+				continue
+			}
+			var tenant tenantInfo
+			if err = json.Unmarshal(data, &tenant); err != nil {
+				internalLogIf(ctx, fmt.Errorf("unable to parse tenant definition %s: %w", obj.Name, err))
+				continue
+			}
+			tenants[tenant.Name] = &tenant
+		}
+		if !res.IsTruncated {
+			break
+		}
+		marker = res.NextMarker
+	}
+
+	byAdmin := make(map[string]*tenantInfo, len(tenants))
+	for _, tenant := range tenants {
+		byAdmin[tenant.AdminAccessKey] = tenant
+	}
+
+	sys.mu.Lock()
+	sys.tenants = tenants
+	sys.byAdmin = byAdmin
+	sys.mu.Unlock()
+}
+
+// refreshLoop reloads the tenant definitions every
+// tenantRefreshInterval.
+func (sys *TenantSys) refreshLoop(ctx context.Context, objAPI ObjectLayer) {
+	ticker := time.NewTicker(tenantRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sys.load(ctx, objAPI)
+		}
+	}
+}
+
+func (sys *TenantSys) set(tenant *tenantInfo) {
+	sys.mu.Lock()
+	defer sys.mu.Unlock()
+	if old, ok := sys.tenants[tenant.Name]; ok {
+		delete(sys.byAdmin, old.AdminAccessKey)
+	}
+	sys.tenants[tenant.Name] = tenant
+	sys.byAdmin[tenant.AdminAccessKey] = tenant
+}
+
+func (sys *TenantSys) remove(name string) {
+	sys.mu.Lock()
+	defer sys.mu.Unlock()
+	if old, ok := sys.tenants[name]; ok {
+		delete(sys.byAdmin, old.AdminAccessKey)
+		delete(sys.tenants, name)
+	}
+}
+
+func (sys *TenantSys) get(name string) (*tenantInfo, bool) {
+	sys.mu.RLock()
+	defer sys.mu.RUnlock()
+	tenant, ok := sys.tenants[name]
+	return tenant, ok
+}
+
+// list returns the tenants sorted by nothing in particular.
+func (sys *TenantSys) list() []*tenantInfo {
+	sys.mu.RLock()
+	defer sys.mu.RUnlock()
+	tenants := make([]*tenantInfo, 0, len(sys.tenants))
+	for _, tenant := range sys.tenants {
+		tenants = append(tenants, tenant)
+	}
+	return tenants
+}
+
+// tenantForAdmin returns the tenant the credentials administer, nil
+// when the caller is not a delegated tenant admin.
+func (sys *TenantSys) tenantForAdmin(cred auth.Credentials) *tenantInfo {
+	sys.mu.RLock()
+	defer sys.mu.RUnlock()
+	if tenant, ok := sys.byAdmin[cred.AccessKey]; ok {
+		return tenant
+	}
+	if cred.ParentUser != "" {
+		if tenant, ok := sys.byAdmin[cred.ParentUser]; ok {
+			return tenant
+		}
+	}
+	return nil
+}
+
+// tenantAllowsEntity returns false when the caller is a delegated
+// tenant admin and the named entity - bucket, user or policy - is
+// outside the tenant namespace.
+func tenantAllowsEntity(cred auth.Credentials, name string) bool {
+	tenant := globalTenantSys.tenantForAdmin(cred)
+	if tenant == nil {
+		return true
+	}
+	return strings.HasPrefix(name, tenant.Prefix)
+}
+
+// tenantFilterEntities removes map entries outside the caller's tenant
+// namespace, a no-op when the caller is not a delegated tenant admin.
+func tenantFilterEntities[V any](cred auth.Credentials, m map[string]V) {
+	tenant := globalTenantSys.tenantForAdmin(cred)
+	if tenant == nil {
+		return
+	}
+	for name := range m {
+		if !strings.HasPrefix(name, tenant.Prefix) {
+			delete(m, name)
+		}
+	}
+}
+
+// AddTenantHandler - creates or updates a tenant definition.
+//
+// PUT /minio/admin/v3/tenant
+func (a adminAPIHandlers) AddTenantHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	// Only the global admin may manage tenants.
+	if globalTenantSys.tenantForAdmin(cred) != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAccessDenied), r.URL)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, humanize.MiByte))
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrInvalidRequest), r.URL)
+		return
+	}
+
+	var tenant tenantInfo
+	if err = json.Unmarshal(data, &tenant); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if tenant.Name == "" || strings.ContainsAny(tenant.Name, "/\\") ||
+		tenant.AdminAccessKey == "" || tenant.AdminAccessKey == globalActiveCred.AccessKey {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+		return
+	}
+	if tenant.Prefix == "" {
+		tenant.Prefix = tenant.Name + "-"
+	}
+	tenant.CreatedAt = time.Now().UTC()
+
+	data, err = json.Marshal(&tenant)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	if err = saveConfig(ctx, objectAPI, tenantConfigPath(tenant.Name), data); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	globalTenantSys.set(&tenant)
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetTenantHandler - returns one tenant definition.
+//
+// GET /minio/admin/v3/tenant?name=...
+func (a adminAPIHandlers) GetTenantHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	tenant, ok := globalTenantSys.get(r.Form.Get("name"))
+	if !ok {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errConfigNotFound), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(tenant)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// ListTenantsHandler - lists the tenant definitions.
+//
+// GET /minio/admin/v3/tenants
+func (a adminAPIHandlers) ListTenantsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, _ := validateAdminReq(ctx, w, r, policy.ServerInfoAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	data, err := json.Marshal(globalTenantSys.list())
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// RemoveTenantHandler - removes a tenant definition, the buckets and
+// users of the tenant are left untouched.
+//
+// DELETE /minio/admin/v3/tenant?name=...
+func (a adminAPIHandlers) RemoveTenantHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	objectAPI, cred := validateAdminReq(ctx, w, r, policy.ConfigUpdateAdminAction)
+	if objectAPI == nil {
+		return
+	}
+
+	if globalTenantSys.tenantForAdmin(cred) != nil {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(ErrAccessDenied), r.URL)
+		return
+	}
+
+	name := r.Form.Get("name")
+	if _, ok := globalTenantSys.get(name); !ok {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errConfigNotFound), r.URL)
+		return
+	}
+	if err := deleteConfig(ctx, objectAPI, tenantConfigPath(name)); err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	globalTenantSys.remove(name)
+
+	writeSuccessResponseHeadersOnly(w)
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+	"github.com/minio/pkg/v3/env"
+	"github.com/minio/pkg/v3/policy"
+)
+
+const (
+	// EnvTraceBufferSize is the number of recent trace entries retained
+	// in memory per node for after-the-fact queries, `0` disables the
+	// buffer.
+	EnvTraceBufferSize = "MINIO_TRACE_BUFFER_SIZE"
+
+	defaultTraceBufferSize = 10000
+
+	// traceQueryMaxResults caps the entries returned by one query.
+	traceQueryMaxResults = 10000
+)
+
+// traceBuffer is a bounded ring buffer of recent trace entries, the
+// oldest entry is overwritten once the buffer is full.
+type traceBuffer struct {
+	mu      sync.Mutex
+	entries []madmin.TraceInfo
+	next    int
+	full    bool
+}
+
+var globalTraceBuffer = &traceBuffer{}
+
+func (tb *traceBuffer) add(entry madmin.TraceInfo) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if len(tb.entries) == 0 {
+		return
+	}
+	tb.entries[tb.next] = entry
+	tb.next = (tb.next + 1) % len(tb.entries)
+	if tb.next == 0 {
+		tb.full = true
+	}
+}
+
+// snapshot returns the buffered entries in chronological order.
+func (tb *traceBuffer) snapshot() []madmin.TraceInfo {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if !tb.full {
+		return append([]madmin.TraceInfo{}, tb.entries[:tb.next]...)
+	}
+	entries := make([]madmin.TraceInfo, 0, len(tb.entries))
+	entries = append(entries, tb.entries[tb.next:]...)
+	entries = append(entries, tb.entries[:tb.next]...)
+	return entries
+}
+
+// initTraceBuffer subscribes the ring buffer to the trace pubsub so
+// recent traces can be queried after the fact, without an active
+// `mc admin trace` session.
+func initTraceBuffer(ctx context.Context) {
+	size := defaultTraceBufferSize
+	if v := env.Get(EnvTraceBufferSize, ""); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			internalLogIf(ctx, errInvalidArgument)
+			return
+		}
+		size = n
+	}
+	if size == 0 {
+		return
+	}
+	globalTraceBuffer.entries = make([]madmin.TraceInfo, size)
+
+	traceCh := make(chan madmin.TraceInfo, 4000)
+	if err := globalTrace.Subscribe(madmin.TraceS3|madmin.TraceInternal, traceCh, ctx.Done(), nil); err != nil {
+		internalLogIf(ctx, err)
+		return
+	}
+	go func() {
+		for entry := range traceCh {
+			globalTraceBuffer.add(entry)
+		}
+	}()
+}
+
+// traceQueryFilter holds the parsed query parameters of one trace
+// buffer query.
+type traceQueryFilter struct {
+	bucket      string
+	api         string
+	statusCode  int
+	minDuration time.Duration
+	since       time.Time
+	until       time.Time
+}
+
+func (f traceQueryFilter) match(entry madmin.TraceInfo) bool {
+	if f.bucket != "" {
+		path := strings.TrimPrefix(entry.Path, SlashSeparator)
+		if path != f.bucket && !strings.HasPrefix(path, f.bucket+SlashSeparator) {
+			return false
+		}
+	}
+	if f.api != "" && !strings.EqualFold(entry.FuncName, f.api) {
+		return false
+	}
+	if f.statusCode != 0 {
+		if entry.HTTP == nil || entry.HTTP.RespInfo.StatusCode != f.statusCode {
+			return false
+		}
+	}
+	if f.minDuration > 0 && entry.Duration < f.minDuration {
+		return false
+	}
+	if !f.since.IsZero() && entry.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && entry.Time.After(f.until) {
+		return false
+	}
+	return true
+}
+
+// TraceQueryHandler - returns recent trace entries from the in-memory
+// ring buffer, filtered by bucket, API name, status code, latency
+// threshold and time range.
+//
+// GET /minio/admin/v3/trace/query?bucket=...&api=...&status-code=...&min-duration=...&since=...&until=...&limit=...
+func (a adminAPIHandlers) TraceQueryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Validate request signature.
+	_, adminAPIErr := checkAdminRequestAuth(ctx, r, policy.TraceAdminAction, "")
+	if adminAPIErr != ErrNone {
+		writeErrorResponseJSON(ctx, w, errorCodes.ToAPIErr(adminAPIErr), r.URL)
+		return
+	}
+
+	filter := traceQueryFilter{
+		bucket: r.Form.Get("bucket"),
+		api:    r.Form.Get("api"),
+	}
+	if v := r.Form.Get("status-code"); v != "" {
+		code, err := strconv.Atoi(v)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+			return
+		}
+		filter.statusCode = code
+	}
+	if v := r.Form.Get("min-duration"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+			return
+		}
+		filter.minDuration = d
+	}
+	if v := r.Form.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+			return
+		}
+		filter.since = t
+	}
+	if v := r.Form.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+			return
+		}
+		filter.until = t
+	}
+
+	limit := traceQueryMaxResults
+	if v := r.Form.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, errInvalidArgument), r.URL)
+			return
+		}
+		if n < limit {
+			limit = n
+		}
+	}
+
+	entries := globalTraceBuffer.snapshot()
+
+	// Walk newest to oldest so the limit keeps the most recent matches.
+	matched := make([]madmin.TraceInfo, 0, 16)
+	for i := len(entries) - 1; i >= 0 && len(matched) < limit; i-- {
+		if filter.match(entries[i]) {
+			matched = append(matched, entries[i])
+		}
+	}
+
+	data, err := json.Marshal(matched)
+	if err != nil {
+		writeErrorResponseJSON(ctx, w, toAdminAPIErr(ctx, err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/config/storageclass"
+	"github.com/minio/mux"
+)
+
+const transferHintsPath = minioReservedBucketPath + "/hints/transfer"
+
+// transferHints carries the transfer parameters this deployment
+// considers optimal for SDK clients, derived from the erasure layout
+// and server resources. Clients may use them to size multipart uploads
+// and tune concurrency instead of relying on SDK defaults.
+type transferHints struct {
+	// PartSize is the recommended multipart part size in bytes,
+	// aligned to a full erasure stripe write.
+	PartSize uint64 `json:"partSize"`
+	// MultipartThreshold is the object size in bytes above which
+	// clients should switch to multipart uploads.
+	MultipartThreshold uint64 `json:"multipartThreshold"`
+	// Concurrency is the recommended number of parallel part
+	// uploads or ranged downloads per object.
+	Concurrency int `json:"concurrency"`
+	// MaxObjectSize mirrors the maximum single-PUT object size.
+	MaxObjectSize int64 `json:"maxObjectSize"`
+}
+
+// computeTransferHints derives transfer hints from the current erasure
+// layout. Falls back to safe defaults when the object layer is not
+// initialized yet.
+func computeTransferHints() transferHints {
+	hints := transferHints{
+		PartSize:           64 * humanize.MiByte,
+		MultipartThreshold: 64 * humanize.MiByte,
+		Concurrency:        min(runtime.GOMAXPROCS(0), 8),
+		MaxObjectSize:      globalMaxObjectSize,
+	}
+
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return hints
+	}
+	z, ok := objAPI.(*erasureServerPools)
+	if !ok {
+		return hints
+	}
+
+	// Align the part size to a full stripe write of the widest pool so
+	// each part covers whole erasure blocks on every data drive.
+	var maxDataDrives int
+	for _, setDriveCount := range z.SetDriveCounts() {
+		parity := globalStorageClass.GetParityForSC(storageclass.STANDARD)
+		if parity < 0 || parity >= setDriveCount {
+			parity = setDriveCount / 2
+		}
+		if dataDrives := setDriveCount - parity; dataDrives > maxDataDrives {
+			maxDataDrives = dataDrives
+		}
+	}
+	if maxDataDrives > 0 {
+		stripeSize := uint64(maxDataDrives) * blockSizeV2
+		// Round the default up to the next stripe multiple.
+		hints.PartSize = (hints.PartSize + stripeSize - 1) / stripeSize * stripeSize
+	}
+	return hints
+}
+
+// TransferHintsHandler - GET /minio/hints/transfer
+// Returns recommended transfer parameters for SDK clients. The response
+// carries no deployment-sensitive information and is served without
+// authentication, like the healthcheck endpoints.
+func TransferHintsHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(computeTransferHints())
+	if err != nil {
+		writeErrorResponseJSON(r.Context(), w, toAdminAPIErr(r.Context(), err), r.URL)
+		return
+	}
+	writeSuccessResponseJSON(w, data)
+}
+
+// registerTransferHintsRouter - adds the SDK transfer hints route.
+func registerTransferHintsRouter(router *mux.Router) {
+	router.Methods(http.MethodGet).Path(transferHintsPath).HandlerFunc(httpTraceAll(TransferHintsHandler))
+}
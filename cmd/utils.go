@@ -572,7 +572,7 @@ func ToS3ETag(etag string) string {
 // GetDefaultConnSettings returns default HTTP connection settings.
 func GetDefaultConnSettings() xhttp.ConnSettings {
 	return xhttp.ConnSettings{
-		LookupHost:  globalDNSCache.LookupHost,
+		LookupHost:  lookupHostByFamily,
 		DialTimeout: rest.DefaultTimeout,
 		RootCAs:     globalRootCAs,
 		TCPOptions:  globalTCPOptions,
@@ -583,13 +583,14 @@ func GetDefaultConnSettings() xhttp.ConnSettings {
 // connections.
 func NewInternodeHTTPTransport(maxIdleConnsPerHost int) func() http.RoundTripper {
 	return xhttp.ConnSettings{
-		LookupHost:       globalDNSCache.LookupHost,
-		DialTimeout:      rest.DefaultTimeout,
-		RootCAs:          globalRootCAs,
-		CipherSuites:     fips.TLSCiphers(),
-		CurvePreferences: fips.TLSCurveIDs(),
-		EnableHTTP2:      false,
-		TCPOptions:       globalTCPOptions,
+		LookupHost:        lookupHostByFamily,
+		DialTimeout:       rest.DefaultTimeout,
+		RootCAs:           globalRootCAs,
+		CipherSuites:      fips.TLSCiphers(),
+		CurvePreferences:  fips.TLSCurveIDs(),
+		ClientCertificate: internodeClientCertificate,
+		EnableHTTP2:       false,
+		TCPOptions:        globalTCPOptions,
 	}.NewInternodeHTTPTransport(maxIdleConnsPerHost)
 }
 
@@ -942,10 +943,16 @@ func auditLogInternal(ctx context.Context, opts AuditLogOptions) {
 }
 
 func newTLSConfig(getCert certs.GetCertificateFunc) *tls.Config {
-	if getCert == nil {
+	if getCert == nil && globalInternodeCA == nil {
 		return nil
 	}
 
+	if getCert == nil {
+		// No operator configured certificates, serve the auto-issued
+		// internode certificate.
+		getCert = globalInternodeCA.getCertificate
+	}
+
 	tlsConfig := &tls.Config{
 		PreferServerCipherSuites: true,
 		MinVersion:               tls.VersionTLS12,
@@ -959,6 +966,18 @@ func newTLSConfig(getCert certs.GetCertificateFunc) *tls.Config {
 		tlsConfig.ClientAuth = tls.RequestClientCert
 	}
 
+	if globalInternodeCA != nil {
+		// Verify presented node certificates against the cluster CA.
+		// S3 clients share this port, so certificates cannot be
+		// required outright; internode clients always present theirs.
+		// STS TLS identity performs its own verification, do not
+		// tighten the handshake when it is enabled.
+		tlsConfig.ClientCAs = globalInternodeCA.pool
+		if !tlsClientIdentity {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
 	if secureCiphers := env.Get(api.EnvAPISecureCiphers, config.EnableOn) == config.EnableOn; secureCiphers {
 		tlsConfig.CipherSuites = fips.TLSCiphers()
 	} else {
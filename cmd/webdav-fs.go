@@ -0,0 +1,402 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio-go/v7"
+	xioutil "github.com/minio/minio/internal/ioutil"
+	"github.com/minio/pkg/v3/mimedb"
+	"golang.org/x/net/webdav"
+)
+
+// webdavFS maps WebDAV file system calls of one authenticated session
+// onto the object layer through the S3 port, the same way the FTP and
+// SFTP drivers do. Buckets appear as top level directories.
+type webdavFS struct {
+	clnt *minio.Client
+}
+
+var _ webdav.FileSystem = &webdavFS{}
+
+// Mkdir implements webdav.FileSystem
+func (fs *webdavFS) Mkdir(ctx context.Context, name string, _ os.FileMode) error {
+	bucket, prefix := path2BucketObject(name)
+	if bucket == "" {
+		return errors.New("bucket name cannot be empty")
+	}
+
+	if prefix == "" {
+		return fs.clnt.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: globalSite.Region()})
+	}
+
+	_, err := fs.clnt.PutObject(ctx, bucket, buildMinioDir(prefix), bytes.NewReader([]byte("")), 0,
+		minio.PutObjectOptions{DisableContentSha256: true})
+	return err
+}
+
+// Stat implements webdav.FileSystem
+func (fs *webdavFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	if name == SlashSeparator || name == "" {
+		return &minioFileInfo{p: SlashSeparator, isDir: true}, nil
+	}
+
+	bucket, object := path2BucketObject(name)
+	if bucket == "" {
+		return nil, errors.New("bucket name cannot be empty")
+	}
+
+	if object == "" {
+		ok, err := fs.clnt.BucketExists(ctx, bucket)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return &minioFileInfo{p: pathClean(bucket), info: minio.ObjectInfo{Key: bucket}, isDir: true}, nil
+	}
+
+	objInfo, err := fs.clnt.StatObject(ctx, bucket, object, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code != "NoSuchKey" {
+			return nil, err
+		}
+		// The path may be a directory prefix with content under it.
+		if fs.prefixExists(ctx, bucket, object) {
+			return &minioFileInfo{p: pathClean(object), info: minio.ObjectInfo{Key: object}, isDir: true}, nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	return &minioFileInfo{
+		p:     pathClean(object),
+		info:  objInfo,
+		isDir: strings.HasSuffix(objInfo.Key, SlashSeparator),
+	}, nil
+}
+
+func (fs *webdavFS) prefixExists(ctx context.Context, bucket, prefix string) bool {
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for object := range fs.clnt.ListObjects(cctx, bucket, minio.ListObjectsOptions{
+		Prefix:  retainSlash(prefix),
+		MaxKeys: 1,
+	}) {
+		return object.Err == nil
+	}
+	return false
+}
+
+// OpenFile implements webdav.FileSystem
+func (fs *webdavFS) OpenFile(ctx context.Context, name string, flag int, _ os.FileMode) (webdav.File, error) {
+	if flag&os.O_APPEND != 0 {
+		return nil, NotImplemented{}
+	}
+
+	if flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		bucket, object := path2BucketObject(name)
+		if bucket == "" || object == "" {
+			return nil, errors.New("bucket and object names cannot be empty")
+		}
+		return newWebdavWriteFile(ctx, fs.clnt, bucket, object), nil
+	}
+
+	fi, err := fs.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return &webdavDir{ctx: ctx, fs: fs, name: name, fi: fi}, nil
+	}
+
+	bucket, object := path2BucketObject(name)
+	obj, err := fs.clnt.GetObject(ctx, bucket, object, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &webdavReadFile{obj: obj, fi: fi}, nil
+}
+
+// RemoveAll implements webdav.FileSystem
+func (fs *webdavFS) RemoveAll(ctx context.Context, name string) error {
+	bucket, prefix := path2BucketObject(name)
+	if bucket == "" {
+		return errors.New("deleting all buckets not allowed")
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if prefix == "" {
+		// if all objects are not deleted yet this call may fail.
+		return fs.clnt.RemoveBucket(cctx, bucket)
+	}
+
+	// Remove the object itself along with everything under it as a
+	// directory prefix.
+	if err := fs.clnt.RemoveObject(cctx, bucket, prefix, minio.RemoveObjectOptions{}); err != nil {
+		return err
+	}
+
+	objectsCh := make(chan minio.ObjectInfo)
+	go func() {
+		defer xioutil.SafeClose(objectsCh)
+		for object := range fs.clnt.ListObjects(cctx, bucket, minio.ListObjectsOptions{
+			Prefix:    retainSlash(prefix),
+			Recursive: true,
+		}) {
+			if object.Err != nil {
+				return
+			}
+			objectsCh <- object
+		}
+	}()
+
+	for err := range fs.clnt.RemoveObjects(cctx, bucket, objectsCh, minio.RemoveObjectsOptions{}) {
+		if err.Err != nil {
+			return err.Err
+		}
+	}
+	return nil
+}
+
+// Rename implements webdav.FileSystem, backing the WebDAV MOVE method
+// with server side copies.
+func (fs *webdavFS) Rename(ctx context.Context, oldName, newName string) error {
+	srcBucket, srcObject := path2BucketObject(oldName)
+	dstBucket, dstObject := path2BucketObject(newName)
+	if srcBucket == "" || dstBucket == "" {
+		return errors.New("bucket name cannot be empty")
+	}
+	if srcObject == "" || dstObject == "" {
+		// Renaming buckets is not supported.
+		return NotImplemented{}
+	}
+
+	_, err := fs.clnt.StatObject(ctx, srcBucket, srcObject, minio.StatObjectOptions{})
+	if err == nil {
+		if _, err = fs.clnt.CopyObject(ctx, minio.CopyDestOptions{
+			Bucket: dstBucket,
+			Object: dstObject,
+		}, minio.CopySrcOptions{
+			Bucket: srcBucket,
+			Object: srcObject,
+		}); err != nil {
+			return err
+		}
+		return fs.clnt.RemoveObject(ctx, srcBucket, srcObject, minio.RemoveObjectOptions{})
+	}
+	if minio.ToErrorResponse(err).Code != "NoSuchKey" {
+		return err
+	}
+
+	// Directory rename, move every object under the prefix.
+	srcPrefix := retainSlash(srcObject)
+	dstPrefix := retainSlash(dstObject)
+	for object := range fs.clnt.ListObjects(ctx, srcBucket, minio.ListObjectsOptions{
+		Prefix:    srcPrefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			return object.Err
+		}
+		if _, err = fs.clnt.CopyObject(ctx, minio.CopyDestOptions{
+			Bucket: dstBucket,
+			Object: dstPrefix + strings.TrimPrefix(object.Key, srcPrefix),
+		}, minio.CopySrcOptions{
+			Bucket: srcBucket,
+			Object: object.Key,
+		}); err != nil {
+			return err
+		}
+		if err = fs.clnt.RemoveObject(ctx, srcBucket, object.Key, minio.RemoveObjectOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// webdavReadFile serves GET and COPY reads from an object.
+type webdavReadFile struct {
+	obj *minio.Object
+	fi  os.FileInfo
+}
+
+func (f *webdavReadFile) Read(p []byte) (int, error) { return f.obj.Read(p) }
+func (f *webdavReadFile) Seek(offset int64, whence int) (int64, error) {
+	return f.obj.Seek(offset, whence)
+}
+func (f *webdavReadFile) Close() error               { return f.obj.Close() }
+func (f *webdavReadFile) Stat() (os.FileInfo, error) { return f.fi, nil }
+
+func (f *webdavReadFile) Write([]byte) (int, error) {
+	return 0, errors.New("file opened for reading")
+}
+
+func (f *webdavReadFile) Readdir(int) ([]os.FileInfo, error) {
+	return nil, errors.New("not a directory")
+}
+
+// webdavDir serves PROPFIND listings for buckets and prefixes.
+type webdavDir struct {
+	ctx  context.Context
+	fs   *webdavFS
+	name string
+	fi   os.FileInfo
+
+	entries []os.FileInfo
+	listed  bool
+	offset  int
+}
+
+func (d *webdavDir) Read([]byte) (int, error)       { return 0, errors.New("is a directory") }
+func (d *webdavDir) Write([]byte) (int, error)      { return 0, errors.New("is a directory") }
+func (d *webdavDir) Seek(int64, int) (int64, error) { return 0, errors.New("is a directory") }
+func (d *webdavDir) Close() error                   { return nil }
+func (d *webdavDir) Stat() (os.FileInfo, error)     { return d.fi, nil }
+
+func (d *webdavDir) Readdir(count int) ([]os.FileInfo, error) {
+	if !d.listed {
+		if err := d.list(); err != nil {
+			return nil, err
+		}
+		d.listed = true
+	}
+
+	if count <= 0 {
+		entries := d.entries[d.offset:]
+		d.offset = len(d.entries)
+		return entries, nil
+	}
+
+	if d.offset >= len(d.entries) {
+		return nil, io.EOF
+	}
+	n := min(count, len(d.entries)-d.offset)
+	entries := d.entries[d.offset : d.offset+n]
+	d.offset += n
+	return entries, nil
+}
+
+func (d *webdavDir) list() error {
+	cctx, cancel := context.WithCancel(d.ctx)
+	defer cancel()
+
+	bucket, prefix := path2BucketObject(d.name)
+	if bucket == "" {
+		buckets, err := d.fs.clnt.ListBuckets(cctx)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			d.entries = append(d.entries, &minioFileInfo{
+				p:     pathClean(bucket.Name),
+				info:  minio.ObjectInfo{Key: retainSlash(bucket.Name), LastModified: bucket.CreationDate},
+				isDir: true,
+			})
+		}
+		return nil
+	}
+
+	prefix = retainSlash(prefix)
+	if prefix == SlashSeparator {
+		prefix = ""
+	}
+	for object := range d.fs.clnt.ListObjects(cctx, bucket, minio.ListObjectsOptions{
+		Prefix:       prefix,
+		Recursive:    false,
+		WithMetadata: true,
+	}) {
+		if object.Err != nil {
+			return object.Err
+		}
+		if object.Key == prefix || object.IsDeleteMarker {
+			continue
+		}
+		d.entries = append(d.entries, &minioFileInfo{
+			p:     pathClean(strings.TrimPrefix(object.Key, prefix)),
+			info:  object,
+			isDir: strings.HasSuffix(object.Key, SlashSeparator),
+		})
+	}
+	return nil
+}
+
+// webdavWriteFile streams PUT bodies into the object layer.
+type webdavWriteFile struct {
+	pw     *io.PipeWriter
+	bucket string
+	object string
+	wg     sync.WaitGroup
+	err    error
+	size   int64
+}
+
+func newWebdavWriteFile(ctx context.Context, clnt *minio.Client, bucket, object string) *webdavWriteFile {
+	pr, pw := io.Pipe()
+	f := &webdavWriteFile{pw: pw, bucket: bucket, object: object}
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		info, err := clnt.PutObject(ctx, bucket, object, pr, -1, minio.PutObjectOptions{
+			ContentType:          mimedb.TypeByExtension(path.Ext(object)),
+			DisableContentSha256: true,
+		})
+		f.err = err
+		f.size = info.Size
+		pr.CloseWithError(err)
+	}()
+	return f
+}
+
+func (f *webdavWriteFile) Write(p []byte) (int, error) { return f.pw.Write(p) }
+
+func (f *webdavWriteFile) Close() error {
+	f.pw.Close()
+	f.wg.Wait()
+	return f.err
+}
+
+func (f *webdavWriteFile) Read([]byte) (int, error) {
+	return 0, errors.New("file opened for writing")
+}
+
+func (f *webdavWriteFile) Seek(int64, int) (int64, error) {
+	return 0, errors.New("file opened for writing")
+}
+
+func (f *webdavWriteFile) Readdir(int) ([]os.FileInfo, error) {
+	return nil, errors.New("not a directory")
+}
+
+func (f *webdavWriteFile) Stat() (os.FileInfo, error) {
+	return &minioFileInfo{
+		p:    pathClean(f.object),
+		info: minio.ObjectInfo{Key: f.object, Size: f.size},
+	}, nil
+}
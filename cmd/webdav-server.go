@@ -0,0 +1,254 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio/internal/logger"
+	"golang.org/x/net/webdav"
+)
+
+// WebDAV front-end, enabled with --webdav="address=:8023". Requests
+// authenticate with HTTP Basic credentials holding an access key and
+// secret key, and map onto the object layer through the S3 port like
+// the FTP and SFTP front-ends. Class 2 locking (LOCK/UNLOCK) is backed
+// by an in-memory lock system, optionally paired with cluster wide
+// namespace locks so locks are honored across server pools.
+var globalWebdavLockSystem *webdavLockSystem
+
+// webdavLockSystem implements webdav.LockSystem. Lock tokens live in
+// the in-memory system, and with cluster locking enabled each token
+// additionally holds a namespace lock from the distributed lock
+// manager for the locked resource.
+type webdavLockSystem struct {
+	mem     webdav.LockSystem
+	cluster bool
+
+	mu   sync.Mutex
+	held map[string]func() // token -> namespace unlock
+}
+
+func newWebdavLockSystem(cluster bool) *webdavLockSystem {
+	return &webdavLockSystem{
+		mem:     webdav.NewMemLS(),
+		cluster: cluster,
+		held:    make(map[string]func()),
+	}
+}
+
+// Confirm implements webdav.LockSystem
+func (l *webdavLockSystem) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (func(), error) {
+	return l.mem.Confirm(now, name0, name1, conditions...)
+}
+
+// Create implements webdav.LockSystem
+func (l *webdavLockSystem) Create(now time.Time, details webdav.LockDetails) (string, error) {
+	token, err := l.mem.Create(now, details)
+	if err != nil {
+		return "", err
+	}
+	if !l.cluster {
+		return token, nil
+	}
+
+	objAPI := newObjectLayerFn()
+	bucket, object := path2BucketObject(SlashSeparator + strings.TrimPrefix(details.Root, SlashSeparator))
+	if objAPI == nil || bucket == "" {
+		return token, nil
+	}
+
+	lk := objAPI.NewNSLock(bucket, object)
+	lkctx, lerr := lk.GetLock(context.Background(), globalOperationTimeout)
+	if lerr != nil {
+		l.mem.Unlock(now, token)
+		return "", webdav.ErrLocked
+	}
+
+	l.mu.Lock()
+	l.held[token] = func() { lk.Unlock(lkctx) }
+	l.mu.Unlock()
+	return token, nil
+}
+
+// Refresh implements webdav.LockSystem
+func (l *webdavLockSystem) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	return l.mem.Refresh(now, token, duration)
+}
+
+// Unlock implements webdav.LockSystem
+func (l *webdavLockSystem) Unlock(now time.Time, token string) error {
+	l.mu.Lock()
+	if unlock, ok := l.held[token]; ok {
+		unlock()
+		delete(l.held, token)
+	}
+	l.mu.Unlock()
+	return l.mem.Unlock(now, token)
+}
+
+// webdavLogin validates the Basic auth credentials and returns an S3
+// client bound to them.
+func webdavLogin(endpoint, remoteIP, username, password string) (*minio.Client, error) {
+	var accessKey, secretKey string
+
+	ui, ok := globalIAMSys.GetUser(context.Background(), username)
+	switch {
+	case ok:
+		// Temporary credentials are not allowed.
+		if ui.Credentials.IsTemp() {
+			return nil, errAuthentication
+		}
+		if subtle.ConstantTimeCompare([]byte(ui.Credentials.SecretKey), []byte(password)) != 1 {
+			return nil, errAuthentication
+		}
+		accessKey, secretKey = ui.Credentials.AccessKey, ui.Credentials.SecretKey
+	case globalIAMSys.LDAPConfig.Enabled():
+		sa, _, err := globalIAMSys.getServiceAccount(context.Background(), username)
+		if err != nil {
+			return nil, err
+		}
+		if subtle.ConstantTimeCompare([]byte(sa.Credentials.SecretKey), []byte(password)) != 1 {
+			return nil, errAuthentication
+		}
+		accessKey, secretKey = sa.Credentials.AccessKey, sa.Credentials.SecretKey
+	default:
+		return nil, errNoSuchUser
+	}
+
+	tr := http.RoundTripper(globalRemoteFTPClientTransport)
+	if remoteIP != "" {
+		tr = forwardForTransport{tr: tr, fwd: remoteIP}
+	}
+	return minio.New(endpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure:    globalIsTLS,
+		Transport: tr,
+	})
+}
+
+func startWebDAVServer(args []string) {
+	var (
+		addr          string
+		tlsPrivateKey string
+		tlsPublicCert string
+		clusterLocks  bool
+	)
+
+	for _, arg := range args {
+		tokens := strings.SplitN(arg, "=", 2)
+		if len(tokens) != 2 {
+			logger.Fatal(fmt.Errorf("invalid arguments passed to --webdav=%s", arg), "unable to start WebDAV server")
+		}
+		switch tokens[0] {
+		case "address":
+			host, portStr, err := net.SplitHostPort(tokens[1])
+			if err != nil {
+				logger.Fatal(fmt.Errorf("invalid arguments passed to --webdav=%s (%v)", arg, err), "unable to start WebDAV server")
+			}
+			port, err := strconv.Atoi(portStr)
+			if err != nil {
+				logger.Fatal(fmt.Errorf("invalid arguments passed to --webdav=%s (%v)", arg, err), "unable to start WebDAV server")
+			}
+			if port < 1 || port > 65535 {
+				logger.Fatal(fmt.Errorf("invalid arguments passed to --webdav=%s, (port number must be between 1 to 65535)", arg), "unable to start WebDAV server")
+			}
+			addr = net.JoinHostPort(host, portStr)
+		case "tls-private-key":
+			tlsPrivateKey = tokens[1]
+		case "tls-public-cert":
+			tlsPublicCert = tokens[1]
+		case "cluster-locks":
+			clusterLocks, _ = strconv.ParseBool(tokens[1])
+		}
+	}
+
+	if addr == "" {
+		addr = ":8023" // Default WebDAV port, since no address was given.
+	}
+
+	if tlsPrivateKey == "" && tlsPublicCert != "" {
+		logger.Fatal(errors.New("invalid TLS arguments provided missing private key --webdav=\"tls-private-key=path/to/private.key\""), "unable to start WebDAV server")
+	}
+	if tlsPrivateKey != "" && tlsPublicCert == "" {
+		logger.Fatal(errors.New("invalid TLS arguments provided missing public cert --webdav=\"tls-public-cert=path/to/public.crt\""), "unable to start WebDAV server")
+	}
+
+	// If no TLS certs were provided and the S3 API runs under TLS,
+	// WebDAV follows suit.
+	if globalIsTLS && tlsPrivateKey == "" && tlsPublicCert == "" {
+		tlsPrivateKey = getPrivateKeyFile()
+		tlsPublicCert = getPublicCertFile()
+	}
+
+	globalWebdavLockSystem = newWebdavLockSystem(clusterLocks)
+	endpoint := fmt.Sprintf("127.0.0.1:%s", globalMinioPort)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="MinIO WebDAV"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var remoteIP string
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			remoteIP = host
+		}
+
+		clnt, err := webdavLogin(endpoint, remoteIP, username, password)
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		(&webdav.Handler{
+			FileSystem: &webdavFS{clnt: clnt},
+			LockSystem: globalWebdavLockSystem,
+		}).ServeHTTP(w, r)
+	})
+
+	name := "MinIO WebDAV Server"
+	if tlsPrivateKey != "" {
+		name = "MinIO WebDAV(Secure) Server"
+	}
+	logger.Info(fmt.Sprintf("%s listening on %s", name, addr))
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	if tlsPrivateKey != "" {
+		logger.FatalIf(srv.ListenAndServeTLS(tlsPublicCert, tlsPrivateKey), "unable to start WebDAV server")
+	} else {
+		logger.FatalIf(srv.ListenAndServe(), "unable to start WebDAV server")
+	}
+}
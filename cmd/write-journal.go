@@ -0,0 +1,327 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio/internal/hash"
+	"github.com/minio/minio/internal/logger"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Opt-in write-back journal for pools built on high-latency backends.
+// Small PUTs are appended to a journal on local fast media and
+// acknowledged after the journal fsync, destaging to erasure-coded
+// storage happens asynchronously with a replay of pending entries on
+// startup. The journal trades read-after-write visibility on other
+// nodes for PUT latency, so it is only suitable where the operator
+// knows the workload tolerates that.
+const (
+	// EnvWriteJournalDir enables the journal, a directory on local
+	// fast media.
+	EnvWriteJournalDir = "MINIO_WRITE_JOURNAL_DIR"
+
+	// EnvWriteJournalMaxSize is the largest object journaled, larger
+	// PUTs take the regular synchronous path.
+	EnvWriteJournalMaxSize = "MINIO_WRITE_JOURNAL_MAX_SIZE"
+
+	// writeJournalDefaultMaxSize is the default per-object threshold.
+	writeJournalDefaultMaxSize = humanize.MiByte
+
+	// writeJournalMaxPending bounds the entries waiting for destage,
+	// beyond it PUTs fall back to the synchronous path.
+	writeJournalMaxPending = 10000
+
+	// writeJournalWorkers destage concurrently.
+	writeJournalWorkers = 4
+
+	writeJournalDataExt = ".data"
+	writeJournalMetaExt = ".meta"
+)
+
+// writeJournalEntry is the persisted header of one journaled PUT. The
+// meta file is written and synced after the data file, its presence
+// marks the entry as complete for recovery.
+type writeJournalEntry struct {
+	ID          string            `json:"id"`
+	Bucket      string            `json:"bucket"`
+	Object      string            `json:"object"`
+	Size        int64             `json:"size"`
+	ETag        string            `json:"etag"`
+	UserDefined map[string]string `json:"userDefined,omitempty"`
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
+type writeJournal struct {
+	dir     string
+	maxSize int64
+
+	entries chan writeJournalEntry
+	pending atomic.Int64
+}
+
+// globalWriteJournal is nil unless MINIO_WRITE_JOURNAL_DIR is set.
+var globalWriteJournal *writeJournal
+
+// initWriteJournal enables the journal, replays entries left over from
+// a crash and starts the destage workers.
+func initWriteJournal(ctx context.Context, z *erasureServerPools) {
+	dir := env.Get(EnvWriteJournalDir, "")
+	if dir == "" {
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		internalLogIf(ctx, fmt.Errorf("unable to initialize write journal at %s: %w", dir, err))
+		return
+	}
+
+	maxSize := int64(writeJournalDefaultMaxSize)
+	if v := env.Get(EnvWriteJournalMaxSize, ""); v != "" {
+		if sz, err := humanize.ParseBytes(v); err == nil && sz > 0 {
+			maxSize = int64(sz)
+		} else {
+			internalLogIf(ctx, fmt.Errorf("invalid %s value: %s, using default", EnvWriteJournalMaxSize, v))
+		}
+	}
+
+	j := &writeJournal{
+		dir:     dir,
+		maxSize: maxSize,
+		entries: make(chan writeJournalEntry, writeJournalMaxPending),
+	}
+	globalWriteJournal = j
+
+	for i := 0; i < writeJournalWorkers; i++ {
+		go j.destageWorker(ctx, z)
+	}
+
+	// Crash recovery: re-queue complete entries, drop data files whose
+	// meta was never synced - those PUTs were never acknowledged.
+	go j.recover(ctx)
+}
+
+func (j *writeJournal) metaPath(id string) string {
+	return filepath.Join(j.dir, id+writeJournalMetaExt)
+}
+
+func (j *writeJournal) dataPath(id string) string {
+	return filepath.Join(j.dir, id+writeJournalDataExt)
+}
+
+func (j *writeJournal) recover(ctx context.Context) {
+	names, err := os.ReadDir(j.dir)
+	if err != nil {
+		internalLogIf(ctx, fmt.Errorf("write journal recovery failed: %w", err))
+		return
+	}
+	recovered := 0
+	for _, name := range names {
+		switch {
+		case name.IsDir():
+			continue
+		case filepath.Ext(name.Name()) == writeJournalMetaExt:
+			buf, err := os.ReadFile(filepath.Join(j.dir, name.Name()))
+			if err != nil {
+				continue
+			}
+			var entry writeJournalEntry
+			if err = json.Unmarshal(buf, &entry); err != nil || entry.ID == "" {
+				internalLogIf(ctx, fmt.Errorf("write journal: dropping corrupt entry %s", name.Name()))
+				os.Remove(filepath.Join(j.dir, name.Name()))
+				continue
+			}
+			j.pending.Add(1)
+			j.entries <- entry
+			recovered++
+		case filepath.Ext(name.Name()) == writeJournalDataExt:
+			// Incomplete entry without meta, the PUT was never
+			// acknowledged. Removed unless its meta is seen in this
+			// scan - destage removes data before meta so a pending
+			// entry never loses its data here.
+			id := name.Name()[:len(name.Name())-len(writeJournalDataExt)]
+			if _, err := os.Stat(j.metaPath(id)); err != nil {
+				os.Remove(filepath.Join(j.dir, name.Name()))
+			}
+		}
+	}
+	if recovered > 0 {
+		logger.Info("Write journal: replaying %d entr(ies) from %s", recovered, j.dir)
+	}
+}
+
+// intercept journals the PUT when it qualifies, returning ok=false to
+// let the caller continue on the synchronous path.
+func (j *writeJournal) intercept(ctx context.Context, bucket, object string, data *PutObjReader, opts ObjectOptions) (ObjectInfo, bool) {
+	if j == nil {
+		return ObjectInfo{}, false
+	}
+	switch {
+	case data.Size() < 0 || data.Size() > j.maxSize:
+		return ObjectInfo{}, false
+	case opts.Versioned || opts.VersionSuspended:
+		// Version IDs are assigned at destage time, acknowledging
+		// before that would return the wrong version.
+		return ObjectInfo{}, false
+	case opts.DataMovement || opts.ReplicationRequest || !opts.MTime.IsZero():
+		return ObjectInfo{}, false
+	case isMinioMetaBucketName(bucket):
+		return ObjectInfo{}, false
+	case j.pending.Load() >= writeJournalMaxPending:
+		return ObjectInfo{}, false
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(data, j.maxSize+1))
+	if err != nil || int64(len(payload)) != data.Size() {
+		// Could not buffer the body, nothing sent downstream yet so
+		// surface as an incomplete body.
+		return ObjectInfo{}, false
+	}
+
+	entry := writeJournalEntry{
+		ID:          mustGetUUID(),
+		Bucket:      bucket,
+		Object:      object,
+		Size:        int64(len(payload)),
+		ETag:        getMD5Hash(payload),
+		UserDefined: opts.UserDefined,
+		CreatedAt:   UTCNow(),
+	}
+
+	if err = j.persist(entry, payload); err != nil {
+		internalLogIf(ctx, fmt.Errorf("write journal append failed, falling back to synchronous PUT: %w", err))
+		return ObjectInfo{}, false
+	}
+
+	j.pending.Add(1)
+	j.entries <- entry
+
+	oi := ObjectInfo{
+		Bucket:      bucket,
+		Name:        object,
+		Size:        entry.Size,
+		ETag:        entry.ETag,
+		ModTime:     entry.CreatedAt,
+		UserDefined: opts.UserDefined,
+	}
+	globalListingIndex.upsert(bucket, oi)
+	return oi, true
+}
+
+// persist writes data then meta, each synced, so a meta file on disk
+// implies complete data.
+func (j *writeJournal) persist(entry writeJournalEntry, payload []byte) error {
+	df, err := os.OpenFile(j.dataPath(entry.ID), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = df.Write(payload); err == nil {
+		err = df.Sync()
+	}
+	if cerr := df.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(j.dataPath(entry.ID))
+		return err
+	}
+
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		os.Remove(j.dataPath(entry.ID))
+		return err
+	}
+	mf, err := os.OpenFile(j.metaPath(entry.ID), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		os.Remove(j.dataPath(entry.ID))
+		return err
+	}
+	if _, err = mf.Write(buf); err == nil {
+		err = mf.Sync()
+	}
+	if cerr := mf.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(j.metaPath(entry.ID))
+		os.Remove(j.dataPath(entry.ID))
+		return err
+	}
+	return nil
+}
+
+func (j *writeJournal) destageWorker(ctx context.Context, z *erasureServerPools) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entry := <-j.entries:
+			if err := j.destage(ctx, z, entry); err != nil {
+				internalLogIf(ctx, fmt.Errorf("write journal destage of %s/%s failed: %w, will retry", entry.Bucket, entry.Object, err))
+				// Leave the entry on disk and retry after a pause, it
+				// is replayed on restart should this node go down.
+				go func(entry writeJournalEntry) {
+					time.Sleep(time.Second)
+					j.entries <- entry
+				}(entry)
+				continue
+			}
+			j.pending.Add(-1)
+		}
+	}
+}
+
+// destage writes one journal entry to erasure-coded storage and
+// removes it from the journal.
+func (j *writeJournal) destage(ctx context.Context, z *erasureServerPools, entry writeJournalEntry) error {
+	payload, err := os.ReadFile(j.dataPath(entry.ID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Already destaged by a concurrent replay.
+			return nil
+		}
+		return err
+	}
+
+	hr, err := hash.NewReader(ctx, bytes.NewReader(payload), entry.Size, entry.ETag, "", entry.Size)
+	if err != nil {
+		return err
+	}
+	_, err = z.PutObject(ctx, entry.Bucket, entry.Object, NewPutObjReader(hr), ObjectOptions{
+		UserDefined: entry.UserDefined,
+		MTime:       entry.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Data before meta, recovery treats a meta-less data file as
+	// incomplete and removes it.
+	os.Remove(j.dataPath(entry.ID))
+	os.Remove(j.metaPath(entry.ID))
+	return nil
+}
@@ -33,6 +33,7 @@ import (
 
 	"github.com/minio/madmin-go/v3"
 	"github.com/minio/minio/internal/cachevalue"
+	"github.com/minio/minio/internal/event"
 	"github.com/minio/minio/internal/grid"
 	xioutil "github.com/minio/minio/internal/ioutil"
 	"github.com/minio/minio/internal/logger"
@@ -797,9 +798,11 @@ func (p *xlStorageDiskIDCheck) updateStorageMetrics(s storageMetric, paths ...st
 			if errors.Is(err, context.DeadlineExceeded) {
 				p.totalErrsTimeout.Add(1)
 			}
+			globalDriveQuarantine.recordIOError(p)
 		}
 
 		p.apiLatencies[s].add(duration)
+		observeDriveLatency(p.storage.String(), s, duration)
 
 		if trace {
 			custom := make(map[string]string, 2)
@@ -818,6 +821,9 @@ func (p *xlStorageDiskIDCheck) updateStorageMetrics(s storageMetric, paths ...st
 const (
 	diskHealthOK int32 = iota
 	diskHealthFaulty
+	// diskHealthQuarantined drives flapped beyond the configured
+	// threshold and stay offline until re-admitted by an operator.
+	diskHealthQuarantined
 )
 
 type diskHealthTracker struct {
@@ -956,6 +962,10 @@ func (p *xlStorageDiskIDCheck) monitorDiskStatus(spent time.Duration, fn string)
 				"node(%s): Read/Write/Delete successful, bringing drive %s online", globalLocalNodeName, p.storage.String())
 			p.health.status.Store(diskHealthOK)
 			p.health.waiting.Add(-1)
+			sendSystemEvent(event.DriveOnline, map[string]string{
+				"node":  globalLocalNodeName,
+				"drive": p.storage.String(),
+			})
 			return
 		}
 	}
@@ -1014,6 +1024,16 @@ func (p *xlStorageDiskIDCheck) monitorDiskWritable(ctx context.Context) {
 			if p.health.status.CompareAndSwap(diskHealthOK, diskHealthFaulty) {
 				storageLogAlwaysIf(ctx, fmt.Errorf("node(%s): taking drive %s offline: %v", globalLocalNodeName, p.storage.String(), err))
 				p.health.waiting.Add(1)
+				sendSystemEvent(event.DriveOffline, map[string]string{
+					"node":  globalLocalNodeName,
+					"drive": p.storage.String(),
+					"error": err.Error(),
+				})
+				if globalDriveQuarantine.recordOffline(p) {
+					// Drive flapped beyond the configured threshold,
+					// keep it offline until an operator re-admits it.
+					return
+				}
 				go p.monitorDiskStatus(spent, fn)
 			}
 		}
@@ -1701,6 +1701,12 @@ func (x *xlMetaV2) AddVersion(fi FileInfo) error {
 		}
 	}
 
+	// Apply the per-bucket maximum versions quota for new versions,
+	// the server-wide limit is enforced in addVersion.
+	if maxV := bucketQuotaMaxVersions(fi.Volume); maxV > 0 && int64(len(x.versions)+1) > maxV {
+		return errMaxVersionsExceeded
+	}
+
 	// We did not find it, add it.
 	return x.addVersion(ventry)
 }
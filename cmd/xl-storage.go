@@ -2020,6 +2020,24 @@ type sendFileReader struct {
 	io.Closer
 }
 
+// WriteTo hands the underlying file range to the destination's
+// io.ReaderFrom when zero-copy serving is enabled. The stream is a
+// *io.LimitedReader over the open file, so net/http can serve it via
+// sendfile on cleartext connections, skipping userspace copies. Any
+// destination without ReaderFrom support falls back to a regular copy.
+func (r *sendFileReader) WriteTo(w io.Writer) (int64, error) {
+	if zeroCopyEnabled() {
+		if rf, ok := w.(io.ReaderFrom); ok {
+			n, err := rf.ReadFrom(r.Reader)
+			recordZeroCopy(n, true)
+			return n, err
+		}
+	}
+	n, err := io.Copy(w, struct{ io.Reader }{r.Reader})
+	recordZeroCopy(n, false)
+	return n, err
+}
+
 // ReadFileStream - Returns the read stream of the file.
 func (s *xlStorage) ReadFileStream(ctx context.Context, volume, path string, offset, length int64) (io.ReadCloser, error) {
 	if offset < 0 {
@@ -2086,6 +2104,14 @@ func (s *xlStorage) ReadFileStream(ctx context.Context, volume, path string, off
 		return nil, errFileCorrupt
 	}
 
+	// Prefer the io_uring path for large sequential reads when enabled,
+	// any setup failure falls through to the regular read path.
+	if length >= xioutil.UringReadThreshold && xioutil.UringEnabled() {
+		if rc, uerr := xioutil.NewUringReader(file, offset, length); uerr == nil {
+			return rc, nil
+		}
+	}
+
 	if offset > 0 {
 		if _, err = file.Seek(offset, io.SeekStart); err != nil {
 			file.Close()
@@ -2166,10 +2192,10 @@ func (s *xlStorage) writeAllDirect(ctx context.Context, filePath string, fileSiz
 	var bufp *[]byte
 	switch {
 	case fileSize <= xioutil.SmallBlock:
-		bufp = xioutil.ODirectPoolSmall.Get().(*[]byte)
+		bufp = xioutil.ODirectPoolSmall.Get()
 		defer xioutil.ODirectPoolSmall.Put(bufp)
 	default:
-		bufp = xioutil.ODirectPoolLarge.Get().(*[]byte)
+		bufp = xioutil.ODirectPoolLarge.Get()
 		defer xioutil.ODirectPoolLarge.Put(bufp)
 	}
 
@@ -0,0 +1,69 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/v3/env"
+)
+
+// Zero-copy serving hands file-backed read streams to the destination's
+// io.ReaderFrom, which lets net/http use sendfile when the stream is a
+// plain file range and the connection is cleartext TCP (or kTLS). Only
+// streams that are stored and served as-is qualify - encrypted or
+// compressed objects are transformed in userspace and never reach this
+// path, which is the automatic fallback. Handoffs and fallbacks are
+// counted so the effect is visible in metrics.
+const EnvAPIZeroCopy = "MINIO_API_ZERO_COPY"
+
+var (
+	zeroCopyOnce sync.Once
+	zeroCopyOn   bool
+)
+
+// zeroCopyEnabled returns whether zero-copy serving is enabled via the
+// environment, parsed once per process.
+func zeroCopyEnabled() bool {
+	zeroCopyOnce.Do(func() {
+		zeroCopyOn = env.Get(EnvAPIZeroCopy, config.EnableOff) == config.EnableOn
+	})
+	return zeroCopyOn
+}
+
+// globalZeroCopyStats counts zero-copy handoffs and userspace
+// fallbacks.
+var globalZeroCopyStats = struct {
+	handoffs atomic.Uint64
+	bytes    atomic.Uint64
+	fallback atomic.Uint64
+}{}
+
+// recordZeroCopy accounts one served stream.
+func recordZeroCopy(n int64, handoff bool) {
+	if !handoff {
+		globalZeroCopyStats.fallback.Add(1)
+		return
+	}
+	globalZeroCopyStats.handoffs.Add(1)
+	if n > 0 {
+		globalZeroCopyStats.bytes.Add(uint64(n))
+	}
+}
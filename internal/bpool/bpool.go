@@ -18,6 +18,8 @@
 package bpool
 
 import (
+	"sync/atomic"
+
 	"github.com/klauspost/reedsolomon"
 )
 
@@ -26,6 +28,9 @@ type BytePoolCap struct {
 	c    chan []byte
 	w    int
 	wcap int
+
+	gets   atomic.Uint64
+	allocs atomic.Uint64
 }
 
 // NewBytePoolCap creates a new BytePool bounded to the given maxSize, with new
@@ -60,16 +65,27 @@ func (bp *BytePoolCap) Get() (b []byte) {
 	if bp == nil {
 		return nil
 	}
+	bp.gets.Add(1)
 	select {
 	case b = <-bp.c:
 		// reuse existing buffer
 	default:
 		// create new aligned buffer
+		bp.allocs.Add(1)
 		b = reedsolomon.AllocAligned(1, bp.wcap)[0][:bp.w]
 	}
 	return
 }
 
+// Stats returns the total gets and the subset of gets that had to
+// allocate a new buffer.
+func (bp *BytePoolCap) Stats() (gets, allocs uint64) {
+	if bp == nil {
+		return 0, 0
+	}
+	return bp.gets.Load(), bp.allocs.Load()
+}
+
 // Put returns the given Buffer to the BytePool.
 func (bp *BytePoolCap) Put(b []byte) {
 	if bp == nil {
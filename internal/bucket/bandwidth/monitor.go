@@ -211,6 +211,26 @@ func (m *Monitor) SetBandwidthLimit(bucket, arn string, limit int64) {
 	m.bucketsThrottle[BucketOptions{Name: bucket, ReplicationARN: arn}] = throttle
 }
 
+// SetBandwidthLimitBurst sets the bandwidth limit for a bucket with an
+// explicit burst allowance, both cluster-wide values divided across the
+// nodes.
+func (m *Monitor) SetBandwidthLimitBurst(bucket, arn string, limit, burst int64) {
+	m.tlock.Lock()
+	defer m.tlock.Unlock()
+	limitBytes := limit / int64(m.NodeCount)
+	burstBytes := burst / int64(m.NodeCount)
+	if burstBytes < limitBytes {
+		burstBytes = limitBytes
+	}
+	throttle, ok := m.bucketsThrottle[BucketOptions{Name: bucket, ReplicationARN: arn}]
+	if !ok {
+		throttle = &bucketThrottle{}
+	}
+	throttle.NodeBandwidthPerSec = limitBytes
+	throttle.Limiter = rate.NewLimiter(rate.Limit(float64(limitBytes)), int(burstBytes))
+	m.bucketsThrottle[BucketOptions{Name: bucket, ReplicationARN: arn}] = throttle
+}
+
 // IsThrottled returns true if a bucket has bandwidth throttling enabled.
 func (m *Monitor) IsThrottled(bucket, arn string) bool {
 	m.tlock.RLock()
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package bandwidth
+
+import (
+	"context"
+	"io"
+)
+
+// MonitoredWriter represents a throttled writer subject to bandwidth
+// monitoring, the write side counterpart of MonitoredReader.
+type MonitoredWriter struct {
+	w        io.Writer
+	throttle *bucketThrottle
+	ctx      context.Context // request context
+	m        *Monitor
+	opts     BucketOptions
+}
+
+// Write implements a throttled write
+func (w *MonitoredWriter) Write(p []byte) (n int, err error) {
+	if w.throttle == nil {
+		return w.w.Write(p)
+	}
+	for len(p) > 0 {
+		chunk := len(p)
+		if b := w.throttle.Burst(); chunk > b {
+			chunk = b
+		}
+		if err = w.throttle.WaitN(w.ctx, chunk); err != nil {
+			return
+		}
+		var nn int
+		nn, err = w.w.Write(p[:chunk])
+		n += nn
+		w.m.updateMeasurement(w.opts, uint64(nn))
+		if err != nil {
+			return
+		}
+		p = p[chunk:]
+	}
+	return
+}
+
+// NewMonitoredWriter returns reference to a monitored writer that
+// throttles writes to the configured bandwidth for the bucket.
+func NewMonitoredWriter(ctx context.Context, m *Monitor, w io.Writer, opts BucketOptions) *MonitoredWriter {
+	writer := MonitoredWriter{
+		w:        w,
+		throttle: m.throttle(opts),
+		m:        m,
+		opts:     opts,
+		ctx:      ctx,
+	}
+	writer.m.init(opts)
+	return &writer
+}
@@ -29,6 +29,7 @@ var (
 	errLifecycleInvalidDeleteMarker = Errorf("Delete marker cannot be specified with Days or Date in a Lifecycle Expiration Policy")
 	errLifecycleDateNotMidnight     = Errorf("'Date' must be at midnight GMT")
 	errLifecycleInvalidDeleteAll    = Errorf("Days (positive integer) should be present inside Expiration with ExpiredObjectAllVersions.")
+	errLifecycleInvalidDaysAccess   = Errorf("DaysSinceLastAccess cannot be specified with Days or Date in a Lifecycle Expiration Policy")
 )
 
 // ExpirationDays is a type alias to unmarshal Days in Expiration
@@ -121,6 +122,11 @@ type Expiration struct {
 	// if set to false the policy takes no action. This action uses the Days/Date to expire objects.
 	// This check is verified for latest version of the object.
 	DeleteAll Boolean `xml:"ExpiredObjectAllVersions"`
+	// Indicates the number of days since an object was last read after which
+	// it expires. Objects never read expire relative to their modtime. This
+	// requires access time tracking to be enabled on the server and is a
+	// MinIO only extension.
+	DaysSinceLastAccess ExpirationDays `xml:"DaysSinceLastAccess,omitempty"`
 
 	set bool
 }
@@ -178,7 +184,7 @@ func (e Expiration) Validate() error {
 		return errLifecycleInvalidDeleteMarker
 	}
 
-	if !e.DeleteMarker.set && !e.DeleteAll.set && e.IsDaysNull() && e.IsDateNull() {
+	if !e.DeleteMarker.set && !e.DeleteAll.set && e.IsDaysNull() && e.IsDateNull() && e.IsDaysSinceAccessNull() {
 		return errXMLNotWellFormed
 	}
 
@@ -187,6 +193,12 @@ func (e Expiration) Validate() error {
 		return errLifecycleInvalidExpiration
 	}
 
+	// DaysSinceLastAccess is an alternative age criterion, it cannot be
+	// combined with Days or Date.
+	if !e.IsDaysSinceAccessNull() && (!e.IsDaysNull() || !e.IsDateNull()) {
+		return errLifecycleInvalidDaysAccess
+	}
+
 	// DeleteAll set without expiration days
 	if e.DeleteAll.set && e.IsDaysNull() {
 		return errLifecycleInvalidDeleteAll
@@ -205,7 +217,12 @@ func (e Expiration) IsDateNull() bool {
 	return e.Date.Time.IsZero()
 }
 
-// IsNull returns true if both date and days fields are null
+// IsDaysSinceAccessNull returns true if days since last access field is null
+func (e Expiration) IsDaysSinceAccessNull() bool {
+	return e.DaysSinceLastAccess == ExpirationDays(0)
+}
+
+// IsNull returns true if date, days and days since last access fields are null
 func (e Expiration) IsNull() bool {
-	return e.IsDaysNull() && e.IsDateNull()
+	return e.IsDaysNull() && e.IsDateNull() && e.IsDaysSinceAccessNull()
 }
@@ -108,6 +108,19 @@ func TestInvalidExpiration(t *testing.T) {
                                     </Expiration>`,
 			expectedErr: errLifecycleInvalidDeleteAll,
 		},
+		{ // Expiration with a valid number of days since last access
+			inputXML: `<Expiration>
+                                    <DaysSinceLastAccess>30</DaysSinceLastAccess>
+                                    </Expiration>`,
+			expectedErr: nil,
+		},
+		{ // Expiration with both days and days since last access
+			inputXML: `<Expiration>
+                                    <Days>3</Days>
+                                    <DaysSinceLastAccess>30</DaysSinceLastAccess>
+                                    </Expiration>`,
+			expectedErr: errLifecycleInvalidDaysAccess,
+		},
 	}
 	for i, tc := range validationTestCases {
 		t.Run(fmt.Sprintf("Test %d", i+1), func(t *testing.T) {
@@ -125,6 +125,20 @@ func (lc Lifecycle) HasExpiry() bool {
 	return false
 }
 
+// UsesAccessTime returns 'true' if any lifecycle rule expires objects by
+// days since last access, requiring access time lookups during evaluation.
+func (lc Lifecycle) UsesAccessTime() bool {
+	for _, rule := range lc.Rules {
+		if rule.Status == Disabled {
+			continue
+		}
+		if !rule.Expiration.IsDaysSinceAccessNull() {
+			return true
+		}
+	}
+	return false
+}
+
 // UnmarshalXML - decodes XML data.
 func (lc *Lifecycle) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
 	switch start.Name.Local {
@@ -198,6 +212,9 @@ func (lc Lifecycle) HasActiveRules(prefix string) bool {
 		if !rule.Expiration.IsDaysNull() {
 			return true
 		}
+		if !rule.Expiration.IsDaysSinceAccessNull() {
+			return true
+		}
 		if rule.Expiration.DeleteMarker.val {
 			return true
 		}
@@ -311,6 +328,10 @@ type ObjectOpts struct {
 	TransitionStatus string
 	RestoreOngoing   bool
 	RestoreExpires   time.Time
+	// LastAccessTime is when the object was last read, zero when access
+	// time tracking is disabled or the object was never read. Only
+	// consulted by rules with DaysSinceLastAccess.
+	LastAccessTime time.Time
 }
 
 // ExpiredObjectDeleteMarker returns true if an object version referred to by o
@@ -464,6 +485,19 @@ func (lc Lifecycle) eval(obj ObjectOpts, now time.Time) Event {
 					}
 					events = append(events, event)
 				}
+			case !rule.Expiration.IsDaysSinceAccessNull():
+				// Objects never read age relative to their modtime.
+				base := obj.LastAccessTime
+				if base.IsZero() {
+					base = obj.ModTime
+				}
+				if expectedExpiry := ExpectedExpiryTime(base, int(rule.Expiration.DaysSinceLastAccess)); now.IsZero() || now.After(expectedExpiry) {
+					events = append(events, Event{
+						Action: DeleteAction,
+						RuleID: rule.ID,
+						Due:    expectedExpiry,
+					})
+				}
 			}
 
 			if obj.TransitionStatus != TransitionComplete {
@@ -59,6 +59,7 @@ type Cache[T any] struct {
 	// 以下是内部管理的状态字段
 	val          atomic.Pointer[T] // 原子指针，存储实际的缓存值
 	lastUpdateMs atomic.Int64      // 上次更新的时间戳（毫秒）
+	generation   atomic.Uint64     // 缓存值对应的版本令牌
 	updating     sync.Mutex        // 用于确保更新操作的互斥访问
 }
 
@@ -140,8 +141,51 @@ func (t *Cache[T]) Get() (T, error) {
 	return t.GetWithCtx(context.Background())
 }
 
+// GetWithGeneration 类似 GetWithCtx，但额外接受调用者已知的版本令牌 gen
+// （例如存储桶元数据的代数）。如果缓存值的版本早于 gen，即使 TTL 尚未过期，
+// 也会同步刷新后再返回，从而为基于此缓存构建的元数据缓存提供
+// "读己之写" 语义。刷新成功后，缓存记录的版本至少为 gen。
+func (t *Cache[T]) GetWithGeneration(ctx context.Context, gen uint64) (T, error) {
+	v := t.val.Load()
+	ttl := t.ttl
+	vTime := t.lastUpdateMs.Load()
+	tNow := time.Now().UnixMilli()
+
+	// 缓存值存在、未过期且不早于请求的版本时，直接返回
+	if v != nil && tNow-vTime < ttl.Milliseconds() && t.generation.Load() >= gen {
+		return *v, nil
+	}
+
+	t.updating.Lock()
+	defer t.updating.Unlock()
+
+	// 双重检查：持锁等待期间其他调用者可能已刷新到足够新的版本
+	if t.generation.Load() >= gen && time.Since(time.UnixMilli(t.lastUpdateMs.Load())) < ttl {
+		if v = t.val.Load(); v != nil {
+			return *v, nil
+		}
+	}
+
+	if err := t.updateGen(ctx, gen); err != nil {
+		var empty T
+		return empty, err
+	}
+
+	return *t.val.Load(), nil
+}
+
+// Generation 返回当前缓存值对应的版本令牌。
+func (t *Cache[T]) Generation() uint64 {
+	return t.generation.Load()
+}
+
 // update 是内部更新方法，处理实际的值更新逻辑
 func (t *Cache[T]) update(ctx context.Context) error {
+	return t.updateGen(ctx, t.generation.Load())
+}
+
+// updateGen 执行实际的值更新，并将缓存版本提升到至少 gen
+func (t *Cache[T]) updateGen(ctx context.Context, gen uint64) error {
 	val, err := t.updateFn(ctx)
 	if err != nil {
 		// ReturnLastGood 选项允许在更新失败时保留旧值
@@ -151,8 +195,15 @@ func (t *Cache[T]) update(ctx context.Context) error {
 		return err
 	}
 
-	// 原子操作更新缓存值和时间戳
+	// 原子操作更新缓存值、版本令牌和时间戳。
+	// 版本令牌只增不减。
 	t.val.Store(&val)
+	for {
+		cur := t.generation.Load()
+		if gen <= cur || t.generation.CompareAndSwap(cur, gen) {
+			break
+		}
+	}
 	t.lastUpdateMs.Store(time.Now().UnixMilli())
 	return nil
 }
@@ -108,6 +108,64 @@ func TestCache(t *testing.T) {
 	}
 }
 
+func TestCacheGeneration(t *testing.T) {
+	var updates int
+	cache := New[int]()
+	t.Parallel()
+	cache.InitOnce(time.Hour, Opts{},
+		func(ctx context.Context) (int, error) {
+			updates++
+			return updates, nil
+		},
+	)
+
+	ctx := context.Background()
+
+	v, err := cache.GetWithGeneration(ctx, 1)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if v != 1 {
+		t.Fatalf("expected first update, got %d", v)
+	}
+	if gen := cache.Generation(); gen != 1 {
+		t.Fatalf("expected generation 1, got %d", gen)
+	}
+
+	// Same or older generation must be served from cache.
+	v, err = cache.GetWithGeneration(ctx, 1)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if v != 1 {
+		t.Fatalf("expected cached value, got %d", v)
+	}
+	if v, _ = cache.GetWithGeneration(ctx, 0); v != 1 {
+		t.Fatalf("expected cached value, got %d", v)
+	}
+
+	// A newer generation must refresh synchronously even though the
+	// TTL has not expired.
+	v, err = cache.GetWithGeneration(ctx, 5)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if v != 2 {
+		t.Fatalf("expected second update, got %d", v)
+	}
+	if gen := cache.Generation(); gen != 5 {
+		t.Fatalf("expected generation 5, got %d", gen)
+	}
+
+	// Get must not reset the recorded generation.
+	if v, _ = cache.Get(); v != 2 {
+		t.Fatalf("expected cached value, got %d", v)
+	}
+	if gen := cache.Generation(); gen != 5 {
+		t.Fatalf("expected generation 5, got %d", gen)
+	}
+}
+
 func BenchmarkCache(b *testing.B) {
 	cache := New[time.Time]()
 	cache.InitOnce(1*time.Millisecond, Opts{},
@@ -31,15 +31,21 @@ import (
 
 // Compression environment variables
 const (
-	Bitrot       = "bitrotscan"
-	Sleep        = "max_sleep"
-	IOCount      = "max_io"
-	DriveWorkers = "drive_workers"
-
-	EnvBitrot       = "MINIO_HEAL_BITROTSCAN"
-	EnvSleep        = "MINIO_HEAL_MAX_SLEEP"
-	EnvIOCount      = "MINIO_HEAL_MAX_IO"
-	EnvDriveWorkers = "MINIO_HEAL_DRIVE_WORKERS"
+	Bitrot          = "bitrotscan"
+	Sleep           = "max_sleep"
+	IOCount         = "max_io"
+	DriveWorkers    = "drive_workers"
+	Window          = "window"
+	PriorityBuckets = "priority_buckets"
+	FastRebuild     = "fast_rebuild"
+
+	EnvBitrot          = "MINIO_HEAL_BITROTSCAN"
+	EnvSleep           = "MINIO_HEAL_MAX_SLEEP"
+	EnvIOCount         = "MINIO_HEAL_MAX_IO"
+	EnvDriveWorkers    = "MINIO_HEAL_DRIVE_WORKERS"
+	EnvWindow          = "MINIO_HEAL_WINDOW"
+	EnvPriorityBuckets = "MINIO_HEAL_PRIORITY_BUCKETS"
+	EnvFastRebuild     = "MINIO_HEAL_FAST_REBUILD"
 )
 
 var configMutex sync.RWMutex
@@ -55,10 +61,33 @@ type Config struct {
 
 	DriveWorkers int `json:"drive_workers"`
 
+	// Window restricts background healing to a daily time window in
+	// UTC, e.g. "22:00-06:00". Empty means healing runs at any time.
+	Window string `json:"window"`
+
+	// PriorityBuckets is a comma separated list of buckets healed
+	// before all others after a drive replacement.
+	PriorityBuckets string `json:"priority_buckets"`
+
+	// FastRebuild rebuilds a replaced drive by listing the namespace
+	// from the smallest drive set that still sees every object
+	// instead of a quorum listing across the set.
+	FastRebuild string `json:"fast_rebuild"`
+
 	// Cached value from Bitrot field
 	cache struct {
 		// -1: bitrot enabled, 0: bitrot disabled, > 0: bitrot cycle
 		bitrotCycle time.Duration
+
+		// Daily healing window in minutes since midnight UTC,
+		// both -1 when no window is configured.
+		windowStart, windowEnd int
+
+		// Set representation of PriorityBuckets.
+		priorityBuckets map[string]struct{}
+
+		// Parsed value of FastRebuild.
+		fastRebuild bool
 	}
 }
 
@@ -86,6 +115,40 @@ func (opts Config) GetWorkers() int {
 	return opts.DriveWorkers
 }
 
+// InWindow returns true if background healing may run at time t.
+// Always true when no healing window is configured.
+func (opts Config) InWindow(t time.Time) bool {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	start, end := opts.cache.windowStart, opts.cache.windowEnd
+	if start < 0 || end < 0 {
+		return true
+	}
+	now := t.UTC().Hour()*60 + t.UTC().Minute()
+	if start <= end {
+		return now >= start && now < end
+	}
+	// Window wraps past midnight, e.g. "22:00-06:00".
+	return now >= start || now < end
+}
+
+// FastRebuildEnabled returns true if drive rebuilds should list the
+// namespace from a reduced drive set per erasure set.
+func (opts Config) FastRebuildEnabled() bool {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	return opts.cache.fastRebuild
+}
+
+// IsPriorityBucket returns true if bucket is configured to be healed
+// before all others.
+func (opts Config) IsPriorityBucket(bucket string) bool {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	_, ok := opts.cache.priorityBuckets[bucket]
+	return ok
+}
+
 // Update updates opts with nopts
 func (opts *Config) Update(nopts Config) {
 	configMutex.Lock()
@@ -95,8 +158,14 @@ func (opts *Config) Update(nopts Config) {
 	opts.IOCount = nopts.IOCount
 	opts.Sleep = nopts.Sleep
 	opts.DriveWorkers = nopts.DriveWorkers
+	opts.Window = nopts.Window
+	opts.PriorityBuckets = nopts.PriorityBuckets
+	opts.FastRebuild = nopts.FastRebuild
 
 	opts.cache.bitrotCycle, _ = parseBitrotConfig(nopts.Bitrot)
+	opts.cache.windowStart, opts.cache.windowEnd, _ = parseWindow(nopts.Window)
+	opts.cache.priorityBuckets = parsePriorityBuckets(nopts.PriorityBuckets)
+	opts.cache.fastRebuild, _ = config.ParseBool(nopts.FastRebuild)
 }
 
 // DefaultKVS - default KV config for heal settings
@@ -117,6 +186,18 @@ var DefaultKVS = config.KVS{
 		Key:   DriveWorkers,
 		Value: "",
 	},
+	config.KV{
+		Key:   Window,
+		Value: "",
+	},
+	config.KV{
+		Key:   PriorityBuckets,
+		Value: "",
+	},
+	config.KV{
+		Key:   FastRebuild,
+		Value: config.EnableOff,
+	},
 }
 
 const minimumBitrotCycleInMonths = 1
@@ -150,6 +231,47 @@ func parseBitrotConfig(s string) (time.Duration, error) {
 	return time.Duration(months) * 30 * 24 * time.Hour, nil
 }
 
+// parseWindow parses a daily UTC time window in "HH:MM-HH:MM" form and
+// returns start and end as minutes since midnight. An empty string
+// returns -1, -1 meaning no window is configured.
+func parseWindow(s string) (start, end int, err error) {
+	if s == "" {
+		return -1, -1, nil
+	}
+	parseClock := func(v string) (int, error) {
+		t, err := time.Parse("15:04", v)
+		if err != nil {
+			return -1, err
+		}
+		return t.Hour()*60 + t.Minute(), nil
+	}
+	tokens := strings.SplitN(s, "-", 2)
+	if len(tokens) != 2 {
+		return -1, -1, errors.New("window must be of the form HH:MM-HH:MM")
+	}
+	if start, err = parseClock(tokens[0]); err != nil {
+		return -1, -1, err
+	}
+	if end, err = parseClock(tokens[1]); err != nil {
+		return -1, -1, err
+	}
+	if start == end {
+		return -1, -1, errors.New("window start and end must differ")
+	}
+	return start, end, nil
+}
+
+// parsePriorityBuckets parses a comma separated bucket list into a set.
+func parsePriorityBuckets(s string) map[string]struct{} {
+	buckets := make(map[string]struct{})
+	for _, bucket := range strings.Split(s, ",") {
+		if bucket = strings.TrimSpace(bucket); bucket != "" {
+			buckets[bucket] = struct{}{}
+		}
+	}
+	return buckets
+}
+
 // LookupConfig - lookup config and override with valid environment settings if any.
 func LookupConfig(kvs config.KVS) (cfg Config, err error) {
 	if err = config.CheckValidKeys(config.HealSubSys, kvs, DefaultKVS); err != nil {
@@ -184,5 +306,18 @@ func LookupConfig(kvs config.KVS) (cfg Config, err error) {
 		cfg.DriveWorkers = -1
 	}
 
+	cfg.Window = env.Get(EnvWindow, kvs.GetWithDefault(Window, DefaultKVS))
+	if cfg.cache.windowStart, cfg.cache.windowEnd, err = parseWindow(cfg.Window); err != nil {
+		return cfg, fmt.Errorf("'heal:window' value invalid: %w", err)
+	}
+
+	cfg.PriorityBuckets = env.Get(EnvPriorityBuckets, kvs.GetWithDefault(PriorityBuckets, DefaultKVS))
+	cfg.cache.priorityBuckets = parsePriorityBuckets(cfg.PriorityBuckets)
+
+	cfg.FastRebuild = env.Get(EnvFastRebuild, kvs.GetWithDefault(FastRebuild, DefaultKVS))
+	if cfg.cache.fastRebuild, err = config.ParseBool(cfg.FastRebuild); err != nil {
+		return cfg, fmt.Errorf("'heal:fast_rebuild' value invalid: %w", err)
+	}
+
 	return cfg, nil
 }
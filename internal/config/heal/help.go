@@ -51,5 +51,23 @@ var (
 			Optional:    true,
 			Type:        "int",
 		},
+		config.HelpKV{
+			Key:         Window,
+			Description: `restrict background healing to a daily UTC time window e.g. "22:00-06:00"` + defaultHelpPostfix(Window),
+			Optional:    true,
+			Type:        "string",
+		},
+		config.HelpKV{
+			Key:         PriorityBuckets,
+			Description: `comma separated list of buckets healed before all others` + defaultHelpPostfix(PriorityBuckets),
+			Optional:    true,
+			Type:        "csv",
+		},
+		config.HelpKV{
+			Key:         FastRebuild,
+			Description: `rebuild replaced drives listing the namespace from fewer drives per erasure set` + defaultHelpPostfix(FastRebuild),
+			Optional:    true,
+			Type:        "on|off",
+		},
 	}
 )
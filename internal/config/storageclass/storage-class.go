@@ -41,10 +41,11 @@ const (
 
 // Standard constants for config info storage class
 const (
-	ClassStandard = "standard"
-	ClassRRS      = "rrs"
-	Optimize      = "optimize"
-	InlineBlock   = "inline_block"
+	ClassStandard  = "standard"
+	ClassRRS       = "rrs"
+	Optimize       = "optimize"
+	InlineBlock    = "inline_block"
+	InlineBlockRRS = "inline_block_rrs"
 
 	// Reduced redundancy storage class environment variable
 	RRSEnv = "MINIO_STORAGE_CLASS_RRS"
@@ -60,6 +61,11 @@ const (
 	// inlining means data and metadata are written
 	// together in a single file i.e xl.meta
 	InlineBlockEnv = "MINIO_STORAGE_CLASS_INLINE_BLOCK"
+	// Inline block for objects written with the REDUCED_REDUNDANCY
+	// storage class, defaults to the STANDARD inline block when unset.
+	// Lets operators tune the metadata-vs-data tradeoff separately per
+	// storage class, e.g. larger inlining on an NVMe backed class.
+	InlineBlockRRSEnv = "MINIO_STORAGE_CLASS_INLINE_BLOCK_RRS"
 
 	// Supported storage class scheme is EC
 	schemePrefix = "EC"
@@ -91,6 +97,11 @@ var (
 			Value:         "",
 			HiddenIfEmpty: true,
 		},
+		config.KV{
+			Key:           InlineBlockRRS,
+			Value:         "",
+			HiddenIfEmpty: true,
+		},
 	}
 )
 
@@ -104,10 +115,11 @@ var ConfigLock sync.RWMutex
 
 // Config storage class configuration
 type Config struct {
-	Standard    StorageClass `json:"standard"`
-	RRS         StorageClass `json:"rrs"`
-	Optimize    string       `json:"optimize"`
-	inlineBlock int64
+	Standard       StorageClass `json:"standard"`
+	RRS            StorageClass `json:"rrs"`
+	Optimize       string       `json:"optimize"`
+	inlineBlock    int64
+	inlineBlockRRS int64
 
 	initialized bool
 }
@@ -276,16 +288,18 @@ func (sCfg *Config) GetParityForSC(sc string) (parity int) {
 // if versioned is true then we chosen 1/8th inline block size
 // to satisfy the same constraints.
 func (sCfg *Config) ShouldInline(shardSize int64, versioned bool) bool {
+	return sCfg.ShouldInlineForSC(STANDARD, shardSize, versioned)
+}
+
+// ShouldInlineForSC behaves like ShouldInline but uses the inline block
+// configured for the given storage class, an empty storage class is
+// treated as STANDARD.
+func (sCfg *Config) ShouldInlineForSC(sc string, shardSize int64, versioned bool) bool {
 	if shardSize < 0 {
 		return false
 	}
 
-	ConfigLock.RLock()
-	inlineBlock := int64(128 * humanize.KiByte)
-	if sCfg.initialized {
-		inlineBlock = sCfg.inlineBlock
-	}
-	ConfigLock.RUnlock()
+	inlineBlock := sCfg.InlineBlockForSC(sc)
 
 	if versioned {
 		return shardSize <= inlineBlock/8
@@ -298,11 +312,20 @@ func (sCfg *Config) ShouldInline(shardSize int64, versioned bool) bool {
 // bucket this value is automatically chosen to 1/8th of the this value, make
 // sure to put this into consideration when choosing this value.
 func (sCfg *Config) InlineBlock() int64 {
+	return sCfg.InlineBlockForSC(STANDARD)
+}
+
+// InlineBlockForSC returns the inline block configured for the given
+// storage class, an empty storage class is treated as STANDARD.
+func (sCfg *Config) InlineBlockForSC(sc string) int64 {
 	ConfigLock.RLock()
 	defer ConfigLock.RUnlock()
 	if !sCfg.initialized {
 		return 128 * humanize.KiByte
 	}
+	if strings.TrimSpace(sc) == RRS && sCfg.inlineBlockRRS > 0 {
+		return sCfg.inlineBlockRRS
+	}
 	return sCfg.inlineBlock
 }
 
@@ -341,6 +364,7 @@ func (sCfg *Config) Update(newCfg Config) {
 	sCfg.Standard = newCfg.Standard
 	sCfg.Optimize = newCfg.Optimize
 	sCfg.inlineBlock = newCfg.inlineBlock
+	sCfg.inlineBlockRRS = newCfg.inlineBlockRRS
 	sCfg.initialized = true
 }
 
@@ -425,6 +449,18 @@ func LookupConfig(kvs config.KVS, setDriveCount int) (cfg Config, err error) {
 		cfg.inlineBlock = 128 * humanize.KiByte
 	}
 
+	inlineBlockRRSStr := env.Get(InlineBlockRRSEnv, kvs.Get(InlineBlockRRS))
+	if inlineBlockRRSStr != "" {
+		inlineBlockRRS, err := humanize.ParseBytes(inlineBlockRRSStr)
+		if err != nil {
+			return cfg, err
+		}
+		if inlineBlockRRS > 128*humanize.KiByte {
+			configLogOnceIf(context.Background(), fmt.Errorf("inline block value bigger than recommended max of 128KiB -> %s, performance may degrade for PUT please benchmark the changes", inlineBlockRRSStr), inlineBlockRRSStr)
+		}
+		cfg.inlineBlockRRS = int64(inlineBlockRRS)
+	}
+
 	cfg.initialized = true
 
 	return cfg, nil
@@ -65,6 +65,22 @@ const (
 	PrefixManyFolders
 	ILMDelMarkerExpirationDelete
 
+	// MinIO infrastructure (system) event types. These are not tied
+	// to a particular object and are only delivered to explicitly
+	// configured system event targets.
+	HealSetStarted
+	HealSetCompleted
+	DriveOffline
+	DriveOnline
+	DriveQuarantined
+	DriveReadmitted
+	DrivePerfDegraded
+	RebalanceStarted
+	RebalanceBucketCompleted
+	RebalanceCompleted
+	ScrubObjectCorrupted
+	AnomalyAlert
+
 	objectSingleTypesEnd
 	// Start Compound types that require expansion:
 
@@ -75,6 +91,9 @@ const (
 	ObjectRestoreAll
 	ObjectTransitionAll
 	ObjectScannerAll
+	HealAll
+	DriveAll
+	RebalanceAll
 	Everything
 )
 
@@ -129,6 +148,25 @@ func (name Name) Expand() []Name {
 			ObjectLargeVersions,
 			PrefixManyFolders,
 		}
+	case HealAll:
+		return []Name{
+			HealSetStarted,
+			HealSetCompleted,
+		}
+	case DriveAll:
+		return []Name{
+			DriveOffline,
+			DriveOnline,
+			DriveQuarantined,
+			DriveReadmitted,
+			DrivePerfDegraded,
+		}
+	case RebalanceAll:
+		return []Name{
+			RebalanceStarted,
+			RebalanceBucketCompleted,
+			RebalanceCompleted,
+		}
 	case Everything:
 		res := make([]Name, objectSingleTypesEnd-1)
 		for i := range res {
@@ -233,6 +271,36 @@ func (name Name) String() string {
 
 	case PrefixManyFolders:
 		return "s3:Scanner:BigPrefix"
+	case HealAll:
+		return "s3:Heal:*"
+	case HealSetStarted:
+		return "s3:Heal:SetStarted"
+	case HealSetCompleted:
+		return "s3:Heal:SetCompleted"
+	case DriveAll:
+		return "s3:Drive:*"
+	case DriveOffline:
+		return "s3:Drive:Offline"
+	case DriveOnline:
+		return "s3:Drive:Online"
+	case DriveQuarantined:
+		return "s3:Drive:Quarantined"
+	case DriveReadmitted:
+		return "s3:Drive:Readmitted"
+	case DrivePerfDegraded:
+		return "s3:Drive:PerfDegraded"
+	case RebalanceAll:
+		return "s3:Rebalance:*"
+	case RebalanceStarted:
+		return "s3:Rebalance:Started"
+	case RebalanceBucketCompleted:
+		return "s3:Rebalance:BucketCompleted"
+	case RebalanceCompleted:
+		return "s3:Rebalance:Completed"
+	case ScrubObjectCorrupted:
+		return "s3:Scrub:ObjectCorrupted"
+	case AnomalyAlert:
+		return "s3:Anomaly:Alert"
 	}
 
 	return ""
@@ -359,6 +427,36 @@ func ParseName(s string) (Name, error) {
 		return ObjectLargeVersions, nil
 	case "s3:Scanner:BigPrefix":
 		return PrefixManyFolders, nil
+	case "s3:Heal:*":
+		return HealAll, nil
+	case "s3:Heal:SetStarted":
+		return HealSetStarted, nil
+	case "s3:Heal:SetCompleted":
+		return HealSetCompleted, nil
+	case "s3:Drive:*":
+		return DriveAll, nil
+	case "s3:Drive:Offline":
+		return DriveOffline, nil
+	case "s3:Drive:Online":
+		return DriveOnline, nil
+	case "s3:Drive:Quarantined":
+		return DriveQuarantined, nil
+	case "s3:Drive:Readmitted":
+		return DriveReadmitted, nil
+	case "s3:Drive:PerfDegraded":
+		return DrivePerfDegraded, nil
+	case "s3:Rebalance:*":
+		return RebalanceAll, nil
+	case "s3:Rebalance:Started":
+		return RebalanceStarted, nil
+	case "s3:Rebalance:BucketCompleted":
+		return RebalanceBucketCompleted, nil
+	case "s3:Rebalance:Completed":
+		return RebalanceCompleted, nil
+	case "s3:Scrub:ObjectCorrupted":
+		return ScrubObjectCorrupted, nil
+	case "s3:Anomaly:Alert":
+		return AnomalyAlert, nil
 	default:
 		return 0, &ErrInvalidEventName{s}
 	}
@@ -206,6 +206,9 @@ const (
 	// Reports number of drives currently healing
 	MinIOHealingDrives = "x-minio-healing-drives"
 
+	// Header to select listing consistency, "strict" or "fast".
+	MinIOListConsistency = "x-minio-list-consistency"
+
 	// Header indicates if the delete marker should be preserved by client
 	MinIOSourceDeleteMarker = "x-minio-source-deletemarker"
 
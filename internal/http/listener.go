@@ -75,7 +75,11 @@ func (listener *httpListener) Accept() (conn net.Conn, err error) {
 	select {
 	case result, ok := <-listener.acceptCh:
 		if ok {
-			return deadlineconn.New(result.conn).WithReadDeadline(listener.opts.IdleTimeout).WithWriteDeadline(listener.opts.IdleTimeout), result.err
+			conn = result.conn
+			if conn != nil && len(listener.opts.ProxyTrustedCIDRs) > 0 {
+				conn = newProxyProtoConn(conn, listener.opts.ProxyTrustedCIDRs)
+			}
+			return deadlineconn.New(conn).WithReadDeadline(listener.opts.IdleTimeout).WithWriteDeadline(listener.opts.IdleTimeout), result.err
 		}
 	case <-listener.ctx.Done():
 	}
@@ -131,6 +135,10 @@ type TCPOptions struct {
 	Interface   string           // This is a VRF device passed via `--interface` flag
 	Trace       func(msg string) // Trace when starting.
 	IdleTimeout time.Duration    // Incoming TCP read/write timeout
+
+	// ProxyTrustedCIDRs enables PROXY protocol v1/v2 parsing on accepted
+	// connections originating from these networks.
+	ProxyTrustedCIDRs []*net.IPNet
 }
 
 // ForWebsocket returns TCPOptions valid for websocket net.Conn
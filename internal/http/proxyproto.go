@@ -0,0 +1,234 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PROXY protocol support. Load balancers such as HAProxy and AWS NLB can
+// prepend a small header to each TCP connection carrying the original
+// client address. When the connecting peer is within a trusted CIDR the
+// header is parsed and RemoteAddr() reports the client address, so audit
+// logs, aws:SourceIp policy conditions and rate limiting see real client
+// IPs. Connections from untrusted peers are passed through untouched.
+
+// proxyProtoTimeout bounds how long we wait for the PROXY header to
+// arrive on a new connection before giving up on it.
+const proxyProtoTimeout = 10 * time.Second
+
+// proxyProtoV2Sig is the 12-byte signature prefixing a PROXY protocol v2
+// binary header.
+var proxyProtoV2Sig = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+// proxyProtoConn wraps an accepted connection and transparently consumes
+// a leading PROXY protocol v1 or v2 header. The header is parsed lazily
+// on the first Read or RemoteAddr call - net/http calls RemoteAddr before
+// it ever reads, so both paths must trigger the parse.
+type proxyProtoConn struct {
+	net.Conn
+	br      *bufio.Reader
+	trusted []*net.IPNet
+
+	parsed bool
+	remote net.Addr
+	err    error
+}
+
+// newProxyProtoConn wraps conn so a leading PROXY protocol header from a
+// trusted peer overrides RemoteAddr.
+func newProxyProtoConn(conn net.Conn, trusted []*net.IPNet) *proxyProtoConn {
+	return &proxyProtoConn{
+		Conn:    conn,
+		br:      bufio.NewReaderSize(conn, 512),
+		trusted: trusted,
+	}
+}
+
+func (c *proxyProtoConn) Read(b []byte) (int, error) {
+	c.parse()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.br.Read(b)
+}
+
+// RemoteAddr returns the client address from the PROXY header if one was
+// sent by a trusted peer, the transport peer address otherwise.
+func (c *proxyProtoConn) RemoteAddr() net.Addr {
+	c.parse()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// trustedPeer returns true if the transport peer is within one of the
+// trusted proxy CIDRs.
+func (c *proxyProtoConn) trustedPeer() bool {
+	tcpAddr, ok := c.Conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, ipnet := range c.trusted {
+		if ipnet.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// parse consumes the PROXY protocol header, if any. Idempotent; Read and
+// RemoteAddr are never called concurrently for net/http server
+// connections so no locking is needed.
+func (c *proxyProtoConn) parse() {
+	if c.parsed {
+		return
+	}
+	c.parsed = true
+
+	if !c.trustedPeer() {
+		return
+	}
+
+	// Do not wait forever for a proxy that never sends its header.
+	c.Conn.SetReadDeadline(time.Now().Add(proxyProtoTimeout))
+	defer c.Conn.SetReadDeadline(time.Time{})
+
+	sig, err := c.br.Peek(len(proxyProtoV2Sig))
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	switch {
+	case bytes.Equal(sig, proxyProtoV2Sig):
+		c.remote, c.err = parseProxyProtoV2(c.br)
+	case bytes.HasPrefix(sig, []byte("PROXY ")):
+		c.remote, c.err = parseProxyProtoV1(c.br)
+	}
+	// Neither signature: a trusted peer that does not speak the
+	// protocol, pass the stream through untouched.
+}
+
+// parseProxyProtoV1 consumes a v1 text header, e.g.
+// "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n".
+func parseProxyProtoV1(br *bufio.Reader) (net.Addr, error) {
+	// v1 headers are at most 107 bytes including CRLF.
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) > 107 || !strings.HasSuffix(line, "\r\n") {
+		return nil, errors.New("http: malformed PROXY protocol v1 header")
+	}
+	fields := strings.Split(strings.TrimSuffix(line, "\r\n"), " ")
+	if len(fields) == 2 && fields[1] == "UNKNOWN" {
+		// Proxy could not determine the client address.
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, errors.New("http: malformed PROXY protocol v1 header")
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.Atoi(fields[4])
+	if ip == nil || err != nil || port < 0 || port > 65535 {
+		return nil, errors.New("http: invalid source address in PROXY protocol v1 header")
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// parseProxyProtoV2 consumes a v2 binary header following the 12-byte
+// signature already verified by the caller.
+func parseProxyProtoV2(br *bufio.Reader) (net.Addr, error) {
+	hdr := make([]byte, 16)
+	if _, err := io.ReadFull(br, hdr); err != nil {
+		return nil, err
+	}
+	verCmd := hdr[12]
+	if verCmd>>4 != 0x2 {
+		return nil, fmt.Errorf("http: unsupported PROXY protocol version: %d", verCmd>>4)
+	}
+	famProto := hdr[13]
+	length := int(binary.BigEndian.Uint16(hdr[14:16]))
+	addrs := make([]byte, length)
+	if _, err := io.ReadFull(br, addrs); err != nil {
+		return nil, err
+	}
+	if verCmd&0xf == 0x0 {
+		// LOCAL command, e.g. health checks from the proxy itself.
+		return nil, nil
+	}
+	switch famProto >> 4 {
+	case 0x1: // AF_INET
+		if length < 12 {
+			return nil, errors.New("http: short PROXY protocol v2 header")
+		}
+		return &net.TCPAddr{
+			IP:   net.IP(addrs[0:4]),
+			Port: int(binary.BigEndian.Uint16(addrs[8:10])),
+		}, nil
+	case 0x2: // AF_INET6
+		if length < 36 {
+			return nil, errors.New("http: short PROXY protocol v2 header")
+		}
+		return &net.TCPAddr{
+			IP:   net.IP(addrs[0:16]),
+			Port: int(binary.BigEndian.Uint16(addrs[32:34])),
+		}, nil
+	}
+	// AF_UNSPEC or AF_UNIX - header consumed, keep transport address.
+	return nil, nil
+}
+
+// ParseProxyTrustedCIDRs parses a comma separated list of CIDRs for use
+// with TCPOptions.ProxyTrustedCIDRs. Bare IPs are accepted as /32 or
+// /128 networks.
+func ParseProxyTrustedCIDRs(s string) ([]*net.IPNet, error) {
+	var cidrs []*net.IPNet
+	for _, token := range strings.Split(s, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		if !strings.Contains(token, "/") {
+			if ip := net.ParseIP(token); ip != nil {
+				if ip.To4() != nil {
+					token += "/32"
+				} else {
+					token += "/128"
+				}
+			}
+		}
+		_, ipnet, err := net.ParseCIDR(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy CIDR %q: %w", token, err)
+		}
+		cidrs = append(cidrs, ipnet)
+	}
+	return cidrs, nil
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package http
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeProxyConn is a net.Conn stub serving a fixed byte stream from a
+// fixed remote TCP address.
+type fakeProxyConn struct {
+	io.Reader
+	remote net.Addr
+}
+
+func (c *fakeProxyConn) Write(b []byte) (int, error)        { return len(b), nil }
+func (c *fakeProxyConn) Close() error                       { return nil }
+func (c *fakeProxyConn) LocalAddr() net.Addr                { return &net.TCPAddr{} }
+func (c *fakeProxyConn) RemoteAddr() net.Addr               { return c.remote }
+func (c *fakeProxyConn) SetDeadline(t time.Time) error      { return nil }
+func (c *fakeProxyConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *fakeProxyConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func proxyV2Header(srcIP net.IP, srcPort int) []byte {
+	var buf bytes.Buffer
+	buf.Write(proxyProtoV2Sig)
+	buf.WriteByte(0x21) // version 2, PROXY command
+	buf.WriteByte(0x11) // AF_INET, STREAM
+	addrs := make([]byte, 12)
+	copy(addrs[0:4], srcIP.To4())
+	copy(addrs[4:8], net.ParseIP("10.0.0.1").To4())
+	binary.BigEndian.PutUint16(addrs[8:10], uint16(srcPort))
+	binary.BigEndian.PutUint16(addrs[10:12], 9000)
+	binary.Write(&buf, binary.BigEndian, uint16(len(addrs)))
+	buf.Write(addrs)
+	return buf.Bytes()
+}
+
+func TestProxyProtoConn(t *testing.T) {
+	_, trustedNet, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted := []*net.IPNet{trustedNet}
+	proxyAddr := &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 41000}
+	payload := "GET / HTTP/1.1\r\n"
+
+	testCases := []struct {
+		name        string
+		remote      net.Addr
+		stream      []byte
+		wantRemote  string
+		wantPayload []byte
+	}{
+		{
+			name:       "v1-trusted",
+			remote:     proxyAddr,
+			stream:     append([]byte("PROXY TCP4 192.0.2.7 10.0.0.2 56324 9000\r\n"), payload...),
+			wantRemote: "192.0.2.7:56324",
+		},
+		{
+			name:       "v2-trusted",
+			remote:     proxyAddr,
+			stream:     append(proxyV2Header(net.ParseIP("192.0.2.9"), 4711), payload...),
+			wantRemote: "192.0.2.9:4711",
+		},
+		{
+			name:       "v1-unknown",
+			remote:     proxyAddr,
+			stream:     append([]byte("PROXY UNKNOWN\r\n"), payload...),
+			wantRemote: proxyAddr.String(),
+		},
+		{
+			name:        "untrusted-passthrough",
+			remote:      &net.TCPAddr{IP: net.ParseIP("192.0.2.100"), Port: 41000},
+			stream:      append([]byte("PROXY TCP4 192.0.2.7 10.0.0.2 56324 9000\r\n"), payload...),
+			wantRemote:  "192.0.2.100:41000",
+			wantPayload: append([]byte("PROXY TCP4 192.0.2.7 10.0.0.2 56324 9000\r\n"), payload...),
+		},
+		{
+			name:       "trusted-no-header",
+			remote:     proxyAddr,
+			stream:     []byte(payload),
+			wantRemote: proxyAddr.String(),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			conn := newProxyProtoConn(&fakeProxyConn{
+				Reader: bytes.NewReader(testCase.stream),
+				remote: testCase.remote,
+			}, trusted)
+
+			// net/http asks for the remote address before reading.
+			if got := conn.RemoteAddr().String(); got != testCase.wantRemote {
+				t.Errorf("RemoteAddr: got %s, want %s", got, testCase.wantRemote)
+			}
+
+			rest, err := io.ReadAll(conn)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			wantPayload := testCase.wantPayload
+			if wantPayload == nil {
+				wantPayload = []byte(payload)
+			}
+			if !bytes.Equal(rest, wantPayload) {
+				t.Errorf("payload: got %q, want %q", rest, wantPayload)
+			}
+		})
+	}
+}
+
+func TestParseProxyTrustedCIDRs(t *testing.T) {
+	cidrs, err := ParseProxyTrustedCIDRs("10.0.0.0/8, 192.0.2.5, 2001:db8::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cidrs) != 3 {
+		t.Fatalf("expected 3 CIDRs, got %d", len(cidrs))
+	}
+	if !cidrs[1].Contains(net.ParseIP("192.0.2.5")) {
+		t.Errorf("bare IPv4 not parsed as /32")
+	}
+	if ones, _ := cidrs[2].Mask.Size(); ones != 128 {
+		t.Errorf("bare IPv6 mask: got /%d, want /128", ones)
+	}
+	if _, err = ParseProxyTrustedCIDRs("not-a-cidr"); err == nil {
+		t.Errorf("expected error for invalid CIDR")
+	}
+}
@@ -50,6 +50,10 @@ type ConnSettings struct {
 	CipherSuites     []uint16
 	CurvePreferences []tls.CurveID
 
+	// ClientCertificate, if set, is consulted on every handshake for the
+	// client certificate to present.
+	ClientCertificate func(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+
 	// HTTP2
 	EnableHTTP2 bool
 
@@ -68,10 +72,11 @@ func (s ConnSettings) getDefaultTransport(maxIdleConnsPerHost int) *http.Transpo
 	}
 
 	tlsClientConfig := tls.Config{
-		RootCAs:            s.RootCAs,
-		CipherSuites:       s.CipherSuites,
-		CurvePreferences:   s.CurvePreferences,
-		ClientSessionCache: tls.NewLRUClientSessionCache(tlsClientSessionCacheSize),
+		RootCAs:              s.RootCAs,
+		CipherSuites:         s.CipherSuites,
+		CurvePreferences:     s.CurvePreferences,
+		ClientSessionCache:   tls.NewLRUClientSessionCache(tlsClientSessionCacheSize),
+		GetClientCertificate: s.ClientCertificate,
 	}
 
 	// For more details about various values used here refer
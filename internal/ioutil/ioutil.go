@@ -26,6 +26,7 @@ import (
 	"os"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -39,26 +40,47 @@ const (
 	LargeBlock  = 1 * humanize.MiByte   // Default r/w block size for normal objects.
 )
 
-// aligned sync.Pool's
-var (
-	ODirectPoolLarge = sync.Pool{
-		New: func() interface{} {
-			b := disk.AlignedBlock(LargeBlock)
-			return &b
-		},
-	}
-	ODirectPoolMedium = sync.Pool{
-		New: func() interface{} {
-			b := disk.AlignedBlock(MediumBlock)
-			return &b
-		},
-	}
-	ODirectPoolSmall = sync.Pool{
-		New: func() interface{} {
-			b := disk.AlignedBlock(SmallBlock)
-			return &b
-		},
+// BlockPool is a sync.Pool of aligned blocks that counts gets and
+// fresh allocations, so pool efficiency can be exported as metrics.
+type BlockPool struct {
+	pool   sync.Pool
+	gets   atomic.Uint64
+	allocs atomic.Uint64
+}
+
+func newBlockPool(size int) *BlockPool {
+	p := &BlockPool{}
+	p.pool.New = func() interface{} {
+		p.allocs.Add(1)
+		b := disk.AlignedBlock(size)
+		return &b
 	}
+	return p
+}
+
+// Get returns an aligned block from the pool, allocating a fresh one
+// when the pool is empty.
+func (p *BlockPool) Get() *[]byte {
+	p.gets.Add(1)
+	return p.pool.Get().(*[]byte)
+}
+
+// Put returns the given block to the pool.
+func (p *BlockPool) Put(b *[]byte) {
+	p.pool.Put(b)
+}
+
+// Stats returns the total gets and the subset of gets that had to
+// allocate a new block.
+func (p *BlockPool) Stats() (gets, allocs uint64) {
+	return p.gets.Load(), p.allocs.Load()
+}
+
+// aligned block pools
+var (
+	ODirectPoolLarge  = newBlockPool(LargeBlock)
+	ODirectPoolMedium = newBlockPool(MediumBlock)
+	ODirectPoolSmall  = newBlockPool(SmallBlock)
 )
 
 // WriteOnCloser implements io.WriteCloser and always
@@ -309,7 +331,7 @@ type writerOnly struct {
 
 // Copy is exactly like io.Copy but with reusable buffers.
 func Copy(dst io.Writer, src io.Reader) (written int64, err error) {
-	bufp := ODirectPoolMedium.Get().(*[]byte)
+	bufp := ODirectPoolMedium.Get()
 	defer ODirectPoolMedium.Put(bufp)
 	buf := *bufp
 
@@ -0,0 +1,28 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package ioutil
+
+import "github.com/dustin/go-humanize"
+
+// EnvDriveIOUring enables the io_uring read path for large sequential
+// reads on Linux, the regular read path remains the fallback.
+const EnvDriveIOUring = "MINIO_DRIVE_IO_URING"
+
+// UringReadThreshold is the read length from which the io_uring path
+// is attempted, smaller reads are not worth the ring setup.
+const UringReadThreshold = 4 * humanize.MiByte
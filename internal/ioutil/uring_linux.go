@@ -0,0 +1,331 @@
+//go:build linux
+// +build linux
+
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package ioutil
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/minio/minio/internal/config"
+	"github.com/minio/pkg/v3/env"
+	"golang.org/x/sys/unix"
+)
+
+// io_uring ABI constants, kept local since only a small subset of the
+// interface is used: a two entry ring with a single registered buffer
+// issuing fixed reads of up to uringBufSize per submission.
+const (
+	uringOffSQRing = 0
+	uringOffCQRing = 0x8000000
+	uringOffSQEs   = 0x10000000
+
+	uringOpReadFixed = 4
+
+	uringEnterGetEvents = 1 << 0
+
+	uringRegisterBuffers = 0
+
+	uringSqeSize = 64
+	uringCqeSize = 16
+
+	// uringBufSize is the registered buffer size, reads are issued in
+	// chunks of this size.
+	uringBufSize = 2 << 20
+)
+
+// uringSqe mirrors struct io_uring_sqe.
+type uringSqe struct {
+	Opcode      uint8
+	Flags       uint8
+	Ioprio      uint16
+	Fd          int32
+	Off         uint64
+	Addr        uint64
+	Len         uint32
+	RwFlags     uint32
+	UserData    uint64
+	BufIndex    uint16
+	Personality uint16
+	SpliceFdIn  int32
+	_           [2]uint64
+}
+
+// uringCqe mirrors struct io_uring_cqe.
+type uringCqe struct {
+	UserData uint64
+	Res      int32
+	Flags    uint32
+}
+
+// uringSqringOffsets mirrors struct io_sqring_offsets.
+type uringSqringOffsets struct {
+	Head        uint32
+	Tail        uint32
+	RingMask    uint32
+	RingEntries uint32
+	Flags       uint32
+	Dropped     uint32
+	Array       uint32
+	Resv1       uint32
+	Resv2       uint64
+}
+
+// uringCqringOffsets mirrors struct io_cqring_offsets.
+type uringCqringOffsets struct {
+	Head        uint32
+	Tail        uint32
+	RingMask    uint32
+	RingEntries uint32
+	Overflow    uint32
+	Cqes        uint32
+	Flags       uint32
+	Resv1       uint32
+	Resv2       uint64
+}
+
+// uringParams mirrors struct io_uring_params.
+type uringParams struct {
+	SqEntries    uint32
+	CqEntries    uint32
+	Flags        uint32
+	SqThreadCPU  uint32
+	SqThreadIdle uint32
+	Features     uint32
+	WqFd         uint32
+	Resv         [3]uint32
+	SqOff        uringSqringOffsets
+	CqOff        uringCqringOffsets
+}
+
+var errUringUnsupported = errors.New("io_uring is not supported")
+
+var (
+	uringOnce      sync.Once
+	uringAvailable bool
+)
+
+// UringEnabled returns true when the io_uring read path is enabled via
+// the environment and the kernel supports it, probed once per process.
+func UringEnabled() bool {
+	uringOnce.Do(func() {
+		if env.Get(EnvDriveIOUring, config.EnableOff) != config.EnableOn {
+			return
+		}
+		var params uringParams
+		fd, _, errno := unix.Syscall(unix.SYS_IO_URING_SETUP, 2,
+			uintptr(unsafe.Pointer(&params)), 0)
+		if errno != 0 {
+			return
+		}
+		unix.Close(int(fd))
+		uringAvailable = true
+	})
+	return uringAvailable
+}
+
+// uringReader reads a fixed range of a file through a private io_uring
+// with one registered buffer. Reads are synchronous - each fill submits
+// one fixed read and waits for its completion - so the reader behaves
+// like any other io.ReadCloser and needs no background goroutine.
+type uringReader struct {
+	file   *os.File
+	ringFd int
+
+	sqRing []byte
+	cqRing []byte
+	sqes   []byte
+	buf    []byte
+
+	sqHead  *uint32
+	sqTail  *uint32
+	sqMask  *uint32
+	sqArray *uint32
+	cqHead  *uint32
+	cqTail  *uint32
+	cqMask  *uint32
+	cqes    unsafe.Pointer
+
+	off       int64
+	remaining int64
+	chunk     []byte
+}
+
+// NewUringReader sets up an io_uring reading length bytes of the file
+// starting at offset. The returned reader owns the file and closes it.
+func NewUringReader(file *os.File, offset, length int64) (io.ReadCloser, error) {
+	if !UringEnabled() {
+		return nil, errUringUnsupported
+	}
+
+	var params uringParams
+	fd, _, errno := unix.Syscall(unix.SYS_IO_URING_SETUP, 2,
+		uintptr(unsafe.Pointer(&params)), 0)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	r := &uringReader{
+		file:      file,
+		ringFd:    int(fd),
+		off:       offset,
+		remaining: length,
+	}
+
+	var err error
+	cleanup := func() {
+		r.unmap()
+		unix.Close(r.ringFd)
+	}
+
+	sqRingSize := int(params.SqOff.Array + params.SqEntries*4)
+	if r.sqRing, err = unix.Mmap(r.ringFd, uringOffSQRing, sqRingSize,
+		unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_POPULATE); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	cqRingSize := int(params.CqOff.Cqes + params.CqEntries*uringCqeSize)
+	if r.cqRing, err = unix.Mmap(r.ringFd, uringOffCQRing, cqRingSize,
+		unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_POPULATE); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	if r.sqes, err = unix.Mmap(r.ringFd, uringOffSQEs, int(params.SqEntries)*uringSqeSize,
+		unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_POPULATE); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	// Anonymous mapping keeps the registered buffer page aligned.
+	if r.buf, err = unix.Mmap(-1, 0, uringBufSize,
+		unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	iov := unix.Iovec{Base: &r.buf[0]}
+	iov.SetLen(len(r.buf))
+	if _, _, errno = unix.Syscall6(unix.SYS_IO_URING_REGISTER, uintptr(r.ringFd),
+		uringRegisterBuffers, uintptr(unsafe.Pointer(&iov)), 1, 0, 0); errno != 0 {
+		cleanup()
+		return nil, errno
+	}
+
+	base := unsafe.Pointer(&r.sqRing[0])
+	r.sqHead = (*uint32)(unsafe.Pointer(uintptr(base) + uintptr(params.SqOff.Head)))
+	r.sqTail = (*uint32)(unsafe.Pointer(uintptr(base) + uintptr(params.SqOff.Tail)))
+	r.sqMask = (*uint32)(unsafe.Pointer(uintptr(base) + uintptr(params.SqOff.RingMask)))
+	r.sqArray = (*uint32)(unsafe.Pointer(uintptr(base) + uintptr(params.SqOff.Array)))
+
+	base = unsafe.Pointer(&r.cqRing[0])
+	r.cqHead = (*uint32)(unsafe.Pointer(uintptr(base) + uintptr(params.CqOff.Head)))
+	r.cqTail = (*uint32)(unsafe.Pointer(uintptr(base) + uintptr(params.CqOff.Tail)))
+	r.cqMask = (*uint32)(unsafe.Pointer(uintptr(base) + uintptr(params.CqOff.RingMask)))
+	r.cqes = unsafe.Add(base, params.CqOff.Cqes)
+
+	return r, nil
+}
+
+func (r *uringReader) Read(p []byte) (int, error) {
+	if len(r.chunk) == 0 {
+		if r.remaining <= 0 {
+			return 0, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+// fill submits one fixed read and waits for its completion.
+func (r *uringReader) fill() error {
+	want := r.remaining
+	if want > int64(len(r.buf)) {
+		want = int64(len(r.buf))
+	}
+
+	tail := atomic.LoadUint32(r.sqTail)
+	idx := tail & *r.sqMask
+	sqe := (*uringSqe)(unsafe.Pointer(&r.sqes[idx*uringSqeSize]))
+	*sqe = uringSqe{
+		Opcode: uringOpReadFixed,
+		Fd:     int32(r.file.Fd()),
+		Off:    uint64(r.off),
+		Addr:   uint64(uintptr(unsafe.Pointer(&r.buf[0]))),
+		Len:    uint32(want),
+	}
+	*(*uint32)(unsafe.Pointer(uintptr(unsafe.Pointer(r.sqArray)) + uintptr(idx)*4)) = idx
+	atomic.StoreUint32(r.sqTail, tail+1)
+
+	for {
+		_, _, errno := unix.Syscall6(unix.SYS_IO_URING_ENTER, uintptr(r.ringFd),
+			1, 1, uringEnterGetEvents, 0, 0)
+		if errno == 0 {
+			break
+		}
+		if errno != unix.EINTR {
+			return errno
+		}
+	}
+
+	head := atomic.LoadUint32(r.cqHead)
+	if head == atomic.LoadUint32(r.cqTail) {
+		return errUringUnsupported
+	}
+	cqe := (*uringCqe)(unsafe.Add(r.cqes, uintptr(head&*r.cqMask)*uringCqeSize))
+	res := cqe.Res
+	atomic.StoreUint32(r.cqHead, head+1)
+
+	if res < 0 {
+		return unix.Errno(-res)
+	}
+	if res == 0 {
+		return io.ErrUnexpectedEOF
+	}
+
+	r.chunk = r.buf[:res]
+	r.off += int64(res)
+	r.remaining -= int64(res)
+	return nil
+}
+
+func (r *uringReader) unmap() {
+	for _, b := range [][]byte{r.sqRing, r.cqRing, r.sqes, r.buf} {
+		if b != nil {
+			unix.Munmap(b)
+		}
+	}
+	r.sqRing, r.cqRing, r.sqes, r.buf = nil, nil, nil, nil
+}
+
+func (r *uringReader) Close() error {
+	r.unmap()
+	unix.Close(r.ringFd)
+	return r.file.Close()
+}
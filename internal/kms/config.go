@@ -56,6 +56,8 @@ const (
 	EnvKESClientCert     = "MINIO_KMS_KES_CERT_FILE"    // Path to TLS certificate for authenticating to KES with mTLS - usually prefer API keys
 	EnvKESServerCA       = "MINIO_KMS_KES_CAPATH"       // Path to file/directory containing CA certificates to verify the KES server certificate
 	EnvKESClientPassword = "MINIO_KMS_KES_KEY_PASSWORD" // Optional password to decrypt an encrypt TLS private key
+
+	EnvKESSecondaryEndpoint = "MINIO_KMS_KES_SECONDARY_ENDPOINT" // Optional secondary KES deployment for automatic failover, shares credentials with the primary
 )
 
 // Environment variables for static KMS key.
@@ -64,6 +66,33 @@ const (
 	EnvKMSSecretKeyFile = "MINIO_KMS_SECRET_KEY_FILE" // Path to a file to read the static KMS key from
 )
 
+// Environment variables for a sealed local master key. Like the static
+// KMS key, but the key material on disk is sealed with a key derived
+// from a password and never stored in plaintext.
+const (
+	EnvKMSSealedKeyFile     = "MINIO_KMS_SEALED_KEY_FILE"     // Path to the sealed keyfile, created on first start if absent
+	EnvKMSSealedKeyPassword = "MINIO_KMS_SEALED_KEY_PASSWORD" // Password to unseal the keyfile, e.g. from a TPM-unsealed secret
+)
+
+// Environment variables for a PKCS#11 HSM as KMS. Requires a binary built
+// with the 'pkcs11' build tag.
+const (
+	EnvKMSPKCS11Module     = "MINIO_KMS_PKCS11_MODULE"      // Path to the PKCS#11 module (shared library) of the HSM vendor
+	EnvKMSPKCS11TokenLabel = "MINIO_KMS_PKCS11_TOKEN_LABEL" // Optional label selecting the token, defaults to the first token present
+	EnvKMSPKCS11PIN        = "MINIO_KMS_PKCS11_PIN"         // User PIN for the token - PIN and PIN file are mutually exclusive
+	EnvKMSPKCS11PINFile    = "MINIO_KMS_PKCS11_PIN_FILE"    // Path to a file to read the user PIN from
+	EnvKMSPKCS11KeyLabel   = "MINIO_KMS_PKCS11_KEY_LABEL"   // Label of the default root key on the token
+)
+
+// PKCS11Options is a structure containing options for connecting
+// to a PKCS#11 HSM as KMS backend.
+type PKCS11Options struct {
+	Module     string // Path to the PKCS#11 module
+	TokenLabel string // Label of the token to use, empty picks the first token
+	PIN        string // User PIN for the token
+	DefaultKey string // Label of the default root key
+}
+
 const (
 	tlsClientSessionCacheSize = 100
 )
@@ -81,6 +110,8 @@ type ConnectionOptions struct {
 //   - connection to MinIO KES if the "MINIO_KMS_KES_ENDPOINT" is present.
 //   - connection to a "local" KMS implementation using a static key if the
 //     "MINIO_KMS_SECRET_KEY" or "MINIO_KMS_SECRET_KEY_FILE" is present.
+//   - connection to a "local" KMS implementation using a sealed keyfile
+//     if the "MINIO_KMS_SEALED_KEY_FILE" variable is present.
 //
 // It returns an error if connecting to the KMS implementation fails,
 // e.g. due to incomplete config, or when configurations for multiple
@@ -241,16 +272,50 @@ func Connect(ctx context.Context, opts *ConnectionOptions) (*KMS, error) {
 			}
 		}()
 
-		return &KMS{
-			Type:       MinKES,
-			DefaultKey: env.Get(EnvKESDefaultKey, ""),
-			conn: &kesConn{
+		var kmsConn conn = &kesConn{
+			defaultKeyID: env.Get(EnvKESDefaultKey, ""),
+			client:       client,
+		}
+		// An optional secondary KES deployment provides automatic
+		// failover. It must be reachable with the same credentials
+		// and hold the same keys as the primary.
+		if rawSecondary := env.Get(EnvKESSecondaryEndpoint, ""); rawSecondary != "" {
+			secondaryEndpoints, err := expandEndpoints(rawSecondary)
+			if err != nil {
+				return nil, err
+			}
+			secondaryClient := kes.NewClientWithConfig("", conf)
+			secondaryClient.Endpoints = secondaryEndpoints
+			kmsConn = newFailoverConn(kmsConn, &kesConn{
 				defaultKeyID: env.Get(EnvKESDefaultKey, ""),
-				client:       client,
-			},
+				client:       secondaryClient,
+			})
+		}
+
+		return &KMS{
+			Type:           MinKES,
+			DefaultKey:     env.Get(EnvKESDefaultKey, ""),
+			conn:           kmsConn,
 			latencyBuckets: defaultLatencyBuckets,
 			latency:        make([]atomic.Uint64, len(defaultLatencyBuckets)),
 		}, nil
+	case lookup(EnvKMSPKCS11Module):
+		pin := env.Get(EnvKMSPKCS11PIN, "")
+		if lookup(EnvKMSPKCS11PINFile) {
+			b, err := os.ReadFile(env.Get(EnvKMSPKCS11PINFile, ""))
+			if err != nil {
+				return nil, err
+			}
+			pin = strings.TrimSpace(string(b))
+		}
+		return NewPKCS11(&PKCS11Options{
+			Module:     env.Get(EnvKMSPKCS11Module, ""),
+			TokenLabel: env.Get(EnvKMSPKCS11TokenLabel, ""),
+			PIN:        pin,
+			DefaultKey: env.Get(EnvKMSPKCS11KeyLabel, ""),
+		})
+	case lookup(EnvKMSSealedKeyFile):
+		return LoadSealedKey(env.Get(EnvKMSSealedKeyFile, ""), env.Get(EnvKMSSealedKeyPassword, ""))
 	default:
 		var s string
 		if lookup(EnvKMSSecretKeyFile) {
@@ -305,6 +370,7 @@ func IsPresent() (bool, error) {
 		EnvKESClientCert,
 		EnvKESClientPassword,
 		EnvKESServerCA,
+		EnvKESSecondaryEndpoint,
 	)
 	// We have to handle a special case for MINIO_KMS_SECRET_KEY and
 	// MINIO_KMS_SECRET_KEY_FILE. The docker image always sets the
@@ -332,6 +398,16 @@ func IsPresent() (bool, error) {
 	// values.
 	staticKeyPresent := isPresent(EnvKMSSecretKey, EnvKMSSecretKeyFile)
 
+	sealedKeyPresent := isPresent(EnvKMSSealedKeyFile, EnvKMSSealedKeyPassword)
+
+	hsmPresent := isPresent(
+		EnvKMSPKCS11Module,
+		EnvKMSPKCS11TokenLabel,
+		EnvKMSPKCS11PIN,
+		EnvKMSPKCS11PINFile,
+		EnvKMSPKCS11KeyLabel,
+	)
+
 	switch {
 	case kmsPresent && kesPresent:
 		return false, errors.New("kms: configuration for MinIO KMS and MinIO KES is present")
@@ -339,6 +415,10 @@ func IsPresent() (bool, error) {
 		return false, errors.New("kms: configuration for MinIO KMS and static KMS key is present")
 	case kesPresent && staticKeyPresent:
 		return false, errors.New("kms: configuration for MinIO KES and static KMS key is present")
+	case hsmPresent && (kmsPresent || kesPresent || staticKeyPresent):
+		return false, errors.New("kms: configuration for PKCS#11 HSM and another KMS is present")
+	case sealedKeyPresent && (kmsPresent || kesPresent || staticKeyPresent || hsmPresent):
+		return false, errors.New("kms: configuration for sealed KMS key and another KMS is present")
 	}
 
 	// Next, we check that all required configuration for the concrete
@@ -349,6 +429,17 @@ func IsPresent() (bool, error) {
 	switch {
 	default:
 		return false, nil // No KMS config present
+	case hsmPresent:
+		if !isPresent(EnvKMSPKCS11Module) {
+			return false, fmt.Errorf("kms: incomplete configuration for PKCS#11 HSM: missing '%s'", EnvKMSPKCS11Module)
+		}
+		if !isPresent(EnvKMSPKCS11KeyLabel) {
+			return false, fmt.Errorf("kms: incomplete configuration for PKCS#11 HSM: missing '%s'", EnvKMSPKCS11KeyLabel)
+		}
+		if isPresent(EnvKMSPKCS11PIN) && isPresent(EnvKMSPKCS11PINFile) {
+			return false, fmt.Errorf("kms: invalid configuration for PKCS#11 HSM: '%s' and '%s' are present", EnvKMSPKCS11PIN, EnvKMSPKCS11PINFile)
+		}
+		return true, nil
 	case kmsPresent:
 		if !isPresent(EnvKMSEndpoint) {
 			return false, fmt.Errorf("kms: incomplete configuration for MinIO KMS: missing '%s'", EnvKMSEndpoint)
@@ -368,6 +459,14 @@ func IsPresent() (bool, error) {
 			return false, fmt.Errorf("kms: invalid configuration for static KMS key: '%s' and '%s' are present", EnvKMSSecretKey, EnvKMSSecretKeyFile)
 		}
 		return true, nil
+	case sealedKeyPresent:
+		if !isPresent(EnvKMSSealedKeyFile) {
+			return false, fmt.Errorf("kms: incomplete configuration for sealed KMS key: missing '%s'", EnvKMSSealedKeyFile)
+		}
+		if !isPresent(EnvKMSSealedKeyPassword) {
+			return false, fmt.Errorf("kms: incomplete configuration for sealed KMS key: missing '%s'", EnvKMSSealedKeyPassword)
+		}
+		return true, nil
 	case kesPresent:
 		if !isPresent(EnvKESEndpoint) {
 			return false, fmt.Errorf("kms: incomplete configuration for MinIO KES: missing '%s'", EnvKESEndpoint)
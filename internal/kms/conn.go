@@ -85,6 +85,7 @@ const (
 	MinKMS  Type = iota + 1 // MinIO KMS
 	MinKES                  // MinIO MinKES
 	Builtin                 // Builtin single key KMS implementation
+	HSM                     // PKCS#11 HSM backend
 )
 
 // Type identifies the KMS type.
@@ -99,6 +100,8 @@ func (t Type) String() string {
 		return "MinIO KES"
 	case Builtin:
 		return "MinIO builtin"
+	case HSM:
+		return "PKCS#11 HSM"
 	default:
 		return "!INVALID:" + strconv.Itoa(int(t))
 	}
@@ -0,0 +1,167 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package kms
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/madmin-go/v3"
+)
+
+// failoverCooldown is how long the primary KMS is skipped after a
+// connectivity failure before it is probed again.
+const failoverCooldown = 30 * time.Second
+
+// failoverConn is a conn that forwards requests to a primary KMS and
+// fails over to a secondary when the primary is unreachable. After a
+// failure the primary is skipped for a cooldown period, then probed
+// again, so a recovered primary takes over automatically.
+//
+// Decrypt always tries both so that data keys wrapped by either KMS
+// remain readable regardless of which one is currently serving.
+type failoverConn struct {
+	primary   conn
+	secondary conn
+
+	// downUntil is the unix nano timestamp until which the primary
+	// is considered down. Zero means healthy.
+	downUntil atomic.Int64
+}
+
+var _ conn = (*failoverConn)(nil) // compiler check
+
+func newFailoverConn(primary, secondary conn) *failoverConn {
+	return &failoverConn{
+		primary:   primary,
+		secondary: secondary,
+	}
+}
+
+// primaryUsable returns true if the primary is healthy or its cooldown
+// has expired.
+func (c *failoverConn) primaryUsable() bool {
+	until := c.downUntil.Load()
+	return until == 0 || time.Now().UnixNano() > until
+}
+
+func (c *failoverConn) markPrimaryDown() {
+	c.downUntil.Store(time.Now().Add(failoverCooldown).UnixNano())
+}
+
+func (c *failoverConn) markPrimaryUp() {
+	c.downUntil.Store(0)
+}
+
+// do runs f against the primary first - if usable - and falls over to
+// the secondary on error.
+func (c *failoverConn) do(f func(conn) error) error {
+	if c.primaryUsable() {
+		if err := f(c.primary); err == nil {
+			c.markPrimaryUp()
+			return nil
+		}
+		c.markPrimaryDown()
+	}
+	return f(c.secondary)
+}
+
+func (c *failoverConn) Version(ctx context.Context) (version string, err error) {
+	err = c.do(func(target conn) error {
+		version, err = target.Version(ctx)
+		return err
+	})
+	return version, err
+}
+
+func (c *failoverConn) APIs(ctx context.Context) (apis []madmin.KMSAPI, err error) {
+	err = c.do(func(target conn) error {
+		apis, err = target.APIs(ctx)
+		return err
+	})
+	return apis, err
+}
+
+// Status merges the status of both KMS deployments.
+func (c *failoverConn) Status(ctx context.Context) (map[string]madmin.ItemState, error) {
+	status, err := c.primary.Status(ctx)
+	if err != nil {
+		status = map[string]madmin.ItemState{}
+	}
+	secondary, err := c.secondary.Status(ctx)
+	if err != nil {
+		return status, err
+	}
+	for endpoint, state := range secondary {
+		status[endpoint] = state
+	}
+	return status, nil
+}
+
+func (c *failoverConn) CreateKey(ctx context.Context, req *CreateKeyRequest) error {
+	return c.do(func(target conn) error {
+		return target.CreateKey(ctx, req)
+	})
+}
+
+func (c *failoverConn) ListKeys(ctx context.Context, req *ListRequest) (keys []madmin.KMSKeyInfo, continueAt string, err error) {
+	err = c.do(func(target conn) error {
+		keys, continueAt, err = target.ListKeys(ctx, req)
+		return err
+	})
+	return keys, continueAt, err
+}
+
+func (c *failoverConn) GenerateKey(ctx context.Context, req *GenerateKeyRequest) (dek DEK, err error) {
+	err = c.do(func(target conn) error {
+		dek, err = target.GenerateKey(ctx, req)
+		return err
+	})
+	return dek, err
+}
+
+// Decrypt tries the primary - if usable - and then the secondary
+// unconditionally. A ciphertext produced by either KMS decrypts as long
+// as one of them is reachable and holds the key.
+func (c *failoverConn) Decrypt(ctx context.Context, req *DecryptRequest) ([]byte, error) {
+	var err error
+	if c.primaryUsable() {
+		var plaintext []byte
+		if plaintext, err = c.primary.Decrypt(ctx, req); err == nil {
+			c.markPrimaryUp()
+			return plaintext, nil
+		}
+	}
+	plaintext, serr := c.secondary.Decrypt(ctx, req)
+	if serr != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, serr
+	}
+	return plaintext, nil
+}
+
+func (c *failoverConn) MAC(ctx context.Context, req *MACRequest) (mac []byte, err error) {
+	err = c.do(func(target conn) error {
+		mac, err = target.MAC(ctx, req)
+		return err
+	})
+	return mac, err
+}
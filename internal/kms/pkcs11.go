@@ -0,0 +1,349 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build pkcs11
+
+package kms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/miekg/pkcs11"
+	"github.com/secure-io/sio-go/sioutil"
+
+	"github.com/minio/madmin-go/v3"
+)
+
+// pkcs11Conn is a conn implementation that keeps root keys inside a
+// PKCS#11 token - typically an HSM - and wraps/unwraps object data
+// keys on the token. Data keys themselves are generated locally,
+// only the wrap/unwrap operations touch the hardware.
+//
+// PKCS#11 sessions must not be used concurrently, all token
+// operations are serialized via the connection lock.
+type pkcs11Conn struct {
+	lock    sync.Mutex
+	module  *pkcs11.Ctx
+	session pkcs11.SessionHandle
+
+	modulePath string
+	tokenLabel string
+	defaultKey string
+}
+
+var _ conn = (*pkcs11Conn)(nil) // compiler check
+
+// pkcs11Sealed is the ciphertext envelope produced by the PKCS#11
+// backend. The IV is consumed by the token during unwrapping, the
+// associated data binds the ciphertext to its KMS context.
+type pkcs11Sealed struct {
+	IV    []byte `json:"iv"`
+	Bytes []byte `json:"bytes"`
+}
+
+// NewPKCS11 connects to the PKCS#11 module referenced by the options
+// and returns a KMS that wraps data keys on the token.
+func NewPKCS11(opts *PKCS11Options) (*KMS, error) {
+	if opts.Module == "" {
+		return nil, errors.New("kms: no PKCS#11 module path provided")
+	}
+	if opts.DefaultKey == "" {
+		return nil, errors.New("kms: no PKCS#11 default key label provided")
+	}
+
+	module := pkcs11.New(opts.Module)
+	if module == nil {
+		return nil, errors.New("kms: unable to load PKCS#11 module '" + opts.Module + "'")
+	}
+	if err := module.Initialize(); err != nil {
+		return nil, err
+	}
+
+	slot, err := findPKCS11Slot(module, opts.TokenLabel)
+	if err != nil {
+		module.Finalize()
+		return nil, err
+	}
+	session, err := module.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		module.Finalize()
+		return nil, err
+	}
+	if opts.PIN != "" {
+		if err = module.Login(session, pkcs11.CKU_USER, opts.PIN); err != nil {
+			module.CloseSession(session)
+			module.Finalize()
+			return nil, err
+		}
+	}
+
+	c := &pkcs11Conn{
+		module:     module,
+		session:    session,
+		modulePath: opts.Module,
+		tokenLabel: opts.TokenLabel,
+		defaultKey: opts.DefaultKey,
+	}
+	if _, err = c.findKey(opts.DefaultKey); err != nil {
+		module.CloseSession(session)
+		module.Finalize()
+		return nil, errors.New("kms: PKCS#11 key '" + opts.DefaultKey + "' not found on token - create it via 'mc admin kms key create' or provision it out of band")
+	}
+	return &KMS{
+		Type:           HSM,
+		DefaultKey:     opts.DefaultKey,
+		conn:           c,
+		latencyBuckets: defaultLatencyBuckets,
+		latency:        make([]atomic.Uint64, len(defaultLatencyBuckets)),
+	}, nil
+}
+
+// findPKCS11Slot returns the slot holding the token with the given
+// label. An empty label selects the first slot with a token present.
+func findPKCS11Slot(module *pkcs11.Ctx, label string) (uint, error) {
+	slots, err := module.GetSlotList(true)
+	if err != nil {
+		return 0, err
+	}
+	for _, slot := range slots {
+		if label == "" {
+			return slot, nil
+		}
+		info, err := module.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(info.Label) == label {
+			return slot, nil
+		}
+	}
+	return 0, errors.New("kms: no PKCS#11 token found matching label '" + label + "'")
+}
+
+// findKey looks up the AES secret key with the given label on the token.
+// Callers must hold the connection lock.
+func (c *pkcs11Conn) findKey(label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_AES),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := c.module.FindObjectsInit(c.session, template); err != nil {
+		return 0, err
+	}
+	objects, _, err := c.module.FindObjects(c.session, 1)
+	c.module.FindObjectsFinal(c.session)
+	if err != nil {
+		return 0, err
+	}
+	if len(objects) == 0 {
+		return 0, ErrKeyNotFound
+	}
+	return objects[0], nil
+}
+
+// Version returns the PKCS#11 library description.
+func (c *pkcs11Conn) Version(context.Context) (string, error) {
+	info, err := c.module.GetInfo()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(info.LibraryDescription), nil
+}
+
+// APIs returns an error since the PKCS#11 backend does not expose a list of APIs.
+func (c *pkcs11Conn) APIs(context.Context) ([]madmin.KMSAPI, error) {
+	return nil, ErrNotSupported
+}
+
+// Status reports whether the token is still reachable through the module.
+func (c *pkcs11Conn) Status(context.Context) (map[string]madmin.ItemState, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	state := madmin.ItemOnline
+	if _, err := c.module.GetSessionInfo(c.session); err != nil {
+		state = madmin.ItemOffline
+	}
+	return map[string]madmin.ItemState{
+		c.modulePath: state,
+	}, nil
+}
+
+// CreateKey generates a new AES-256 key on the token with the given
+// name as its label.
+func (c *pkcs11Conn) CreateKey(_ context.Context, req *CreateKeyRequest) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, err := c.findKey(req.Name); err == nil {
+		return ErrKeyExists
+	}
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_AES),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, 32),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, req.Name),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_ENCRYPT, true),
+		pkcs11.NewAttribute(pkcs11.CKA_DECRYPT, true),
+	}
+	_, err := c.module.GenerateKey(c.session, []*pkcs11.Mechanism{
+		pkcs11.NewMechanism(pkcs11.CKM_AES_KEY_GEN, nil),
+	}, template)
+	return err
+}
+
+// ListKeys returns the AES secret keys present on the token.
+func (c *pkcs11Conn) ListKeys(_ context.Context, req *ListRequest) ([]madmin.KMSKeyInfo, string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_AES),
+	}
+	if err := c.module.FindObjectsInit(c.session, template); err != nil {
+		return nil, "", err
+	}
+	objects, _, err := c.module.FindObjects(c.session, 256)
+	c.module.FindObjectsFinal(c.session)
+	if err != nil {
+		return nil, "", err
+	}
+
+	keys := make([]madmin.KMSKeyInfo, 0, len(objects))
+	for _, object := range objects {
+		attrs, err := c.module.GetAttributeValue(c.session, object, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, nil),
+		})
+		if err != nil || len(attrs) == 0 {
+			continue
+		}
+		name := string(attrs[0].Value)
+		if !strings.HasPrefix(name, req.Prefix) || !strings.HasPrefix(name, req.ContinueAt) {
+			continue
+		}
+		keys = append(keys, madmin.KMSKeyInfo{Name: name})
+	}
+	return keys, "", nil
+}
+
+// GenerateKey generates a new data encryption key locally and wraps it
+// under the referenced token key using AES-GCM on the token.
+func (c *pkcs11Conn) GenerateKey(_ context.Context, req *GenerateKeyRequest) (DEK, error) {
+	name := req.Name
+	if name == "" {
+		name = c.defaultKey
+	}
+	associatedData, err := req.AssociatedData.MarshalText()
+	if err != nil {
+		return DEK{}, err
+	}
+
+	plaintext, err := sioutil.Random(32)
+	if err != nil {
+		return DEK{}, err
+	}
+	iv, err := sioutil.Random(12)
+	if err != nil {
+		return DEK{}, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key, err := c.findKey(name)
+	if err != nil {
+		return DEK{}, err
+	}
+	params := pkcs11.NewGCMParams(iv, associatedData, 128)
+	defer params.Free()
+
+	if err = c.module.EncryptInit(c.session, []*pkcs11.Mechanism{
+		pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params),
+	}, key); err != nil {
+		return DEK{}, err
+	}
+	sealed, err := c.module.Encrypt(c.session, plaintext)
+	if err != nil {
+		return DEK{}, err
+	}
+
+	ciphertext, err := json.Marshal(pkcs11Sealed{
+		IV:    iv,
+		Bytes: sealed,
+	})
+	if err != nil {
+		return DEK{}, err
+	}
+	return DEK{
+		KeyID:      name,
+		Plaintext:  plaintext,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+// Decrypt unwraps req.Ciphertext under the referenced token key.
+func (c *pkcs11Conn) Decrypt(_ context.Context, req *DecryptRequest) ([]byte, error) {
+	name := req.Name
+	if name == "" {
+		name = c.defaultKey
+	}
+	associatedData, err := req.AssociatedData.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	var sealed pkcs11Sealed
+	if err = json.Unmarshal(req.Ciphertext, &sealed); err != nil {
+		return nil, ErrDecrypt
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key, err := c.findKey(name)
+	if err != nil {
+		return nil, err
+	}
+	params := pkcs11.NewGCMParams(sealed.IV, associatedData, 128)
+	defer params.Free()
+
+	if err = c.module.DecryptInit(c.session, []*pkcs11.Mechanism{
+		pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params),
+	}, key); err != nil {
+		return nil, err
+	}
+	plaintext, err := c.module.Decrypt(c.session, sealed.Bytes)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// MAC is not supported by the PKCS#11 backend.
+func (c *pkcs11Conn) MAC(context.Context, *MACRequest) ([]byte, error) {
+	return nil, ErrNotSupported
+}
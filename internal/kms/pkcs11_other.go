@@ -0,0 +1,28 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !pkcs11
+
+package kms
+
+import "errors"
+
+// NewPKCS11 returns an error, the PKCS#11 backend requires a binary
+// built with the 'pkcs11' build tag since it depends on cgo.
+func NewPKCS11(*PKCS11Options) (*KMS, error) {
+	return nil, errors.New("kms: PKCS#11 support is not compiled in - rebuild with '-tags pkcs11'")
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package kms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/secure-io/sio-go/sioutil"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// sealedKeyFileVersion is the on-disk format version of a sealed keyfile.
+const sealedKeyFileVersion = 1
+
+// sealedKeyID is the key name of the master key stored within a sealed
+// keyfile. Like the static key KMS, the builtin KMS derived from a
+// sealed keyfile consists of exactly one key.
+const sealedKeyID = "minio-sealed-key"
+
+// Argon2id parameters used to derive the keyfile sealing key from the
+// unseal password. Values follow the RFC 9106 second recommendation.
+const (
+	sealedKeyArgon2Time    = 3
+	sealedKeyArgon2Memory  = 64 * 1024 // 64 MiB
+	sealedKeyArgon2Threads = 4
+)
+
+// sealedKeyFile is the JSON layout of a sealed keyfile. The master key
+// is sealed with ChaCha20-Poly1305 using a key derived from the unseal
+// password via Argon2id - the raw key material is never stored in
+// plaintext on disk.
+type sealedKeyFile struct {
+	Version   int    `json:"version"`
+	KeyID     string `json:"keyID"`
+	Salt      []byte `json:"salt"`
+	Nonce     []byte `json:"nonce"`
+	SealedKey []byte `json:"sealedKey"`
+}
+
+// LoadSealedKey returns a builtin single-key KMS whose master key is
+// stored sealed at path and unsealed with password. If no file exists
+// at path, a fresh random master key is generated, sealed and written
+// there, so single-node deployments without an external KMS can still
+// encrypt their config and IAM metadata at rest. The password may come
+// from any secret source, e.g. a TPM-unsealed secret or an init system
+// credential.
+func LoadSealedKey(path, password string) (*KMS, error) {
+	if password == "" {
+		return nil, errors.New("kms: no password to unseal keyfile provided")
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return createSealedKey(path, password)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file sealedKeyFile
+	if err = json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("kms: malformed sealed keyfile '%s': %v", path, err)
+	}
+	if file.Version != sealedKeyFileVersion {
+		return nil, fmt.Errorf("kms: unsupported sealed keyfile version %d", file.Version)
+	}
+
+	sealingKey := argon2.IDKey([]byte(password), file.Salt, sealedKeyArgon2Time, sealedKeyArgon2Memory, sealedKeyArgon2Threads, chacha20poly1305.KeySize)
+	aead, err := chacha20poly1305.New(sealingKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("kms: malformed sealed keyfile '%s': invalid nonce size", path)
+	}
+	key, err := aead.Open(nil, file.Nonce, file.SealedKey, []byte(file.KeyID))
+	if err != nil {
+		return nil, errors.New("kms: cannot unseal keyfile: invalid password or corrupted keyfile")
+	}
+	return NewBuiltin(file.KeyID, key)
+}
+
+// createSealedKey generates a fresh random master key, seals it with a
+// key derived from password and writes the sealed keyfile to path.
+func createSealedKey(path, password string) (*KMS, error) {
+	key, err := sioutil.Random(32)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := sioutil.Random(32)
+	if err != nil {
+		return nil, err
+	}
+
+	sealingKey := argon2.IDKey([]byte(password), salt, sealedKeyArgon2Time, sealedKeyArgon2Memory, sealedKeyArgon2Threads, chacha20poly1305.KeySize)
+	aead, err := chacha20poly1305.New(sealingKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := sioutil.Random(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	file := sealedKeyFile{
+		Version:   sealedKeyFileVersion,
+		KeyID:     sealedKeyID,
+		Salt:      salt,
+		Nonce:     nonce,
+		SealedKey: aead.Seal(nil, nonce, key, []byte(sealedKeyID)),
+	}
+	b, err := json.Marshal(file)
+	if err != nil {
+		return nil, err
+	}
+	// Write via a temporary file and rename so a crash never leaves a
+	// partially written keyfile behind.
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, b, 0o600); err != nil {
+		return nil, err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+	return NewBuiltin(sealedKeyID, key)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/minio/highwayhash"
+)
+
+// anonymizeKey is the keyed hashing key used to pseudonymize bucket
+// names, object keys and principals in anonymous mode. It is generated
+// once per process, so pseudonyms are stable within a single server run
+// and correlate across log lines, but cannot be reversed or compared
+// across restarts.
+var anonymizeKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		// Fall back to the static key, pseudonyms remain stable
+		// but are no longer keyed per process.
+		return magicHighwayHash256Key
+	}
+	return key
+}()
+
+// Anonymize returns a short stable pseudonym for the given value. The
+// same input always maps to the same pseudonym within a process, which
+// keeps logs shared with support correlatable while hiding the actual
+// names. Empty input remains empty.
+func Anonymize(input string) string {
+	if input == "" {
+		return ""
+	}
+	hh, _ := highwayhash.New(anonymizeKey)
+	hh.Write([]byte(input))
+	return hex.EncodeToString(hh.Sum(nil))[:16]
+}
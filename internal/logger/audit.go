@@ -116,6 +116,18 @@ func AuditLog(ctx context.Context, w http.ResponseWriter, r *http.Request, reqCl
 				VersionID:  ov.VersionID,
 			})
 		}
+		if anonFlag {
+			entry.AccessKey = Anonymize(entry.AccessKey)
+			entry.ParentUser = Anonymize(entry.ParentUser)
+			entry.API.Bucket = Anonymize(entry.API.Bucket)
+			entry.API.Object = Anonymize(entry.API.Object)
+			for i, ov := range entry.API.Objects {
+				entry.API.Objects[i].ObjectName = Anonymize(ov.ObjectName)
+			}
+			entry.RemoteHost = Anonymize(entry.RemoteHost)
+			// ReqPath embeds bucket and object names.
+			entry.ReqPath = Anonymize(entry.ReqPath)
+		}
 		entry.API.Status = http.StatusText(statusCode)
 		entry.API.StatusCode = statusCode
 		entry.API.InputBytes = r.ContentLength
@@ -32,6 +32,7 @@ import (
 	"github.com/minio/minio/internal/config"
 	"github.com/minio/minio/internal/logger/target/http"
 	"github.com/minio/minio/internal/logger/target/kafka"
+	"github.com/minio/minio/internal/logger/target/splunk"
 )
 
 // Console logger target
@@ -109,6 +110,16 @@ const (
 	EnvKafkaQueueDir      = "MINIO_AUDIT_KAFKA_QUEUE_DIR"
 	EnvKafkaQueueSize     = "MINIO_AUDIT_KAFKA_QUEUE_SIZE"
 
+	EnvAuditSplunkEnable     = "MINIO_AUDIT_SPLUNK_ENABLE"
+	EnvAuditSplunkEndpoint   = "MINIO_AUDIT_SPLUNK_ENDPOINT"
+	EnvAuditSplunkToken      = "MINIO_AUDIT_SPLUNK_TOKEN"
+	EnvAuditSplunkIndex      = "MINIO_AUDIT_SPLUNK_INDEX"
+	EnvAuditSplunkSourceType = "MINIO_AUDIT_SPLUNK_SOURCETYPE"
+	EnvAuditSplunkBatchSize  = "MINIO_AUDIT_SPLUNK_BATCH_SIZE"
+	EnvAuditSplunkCompress   = "MINIO_AUDIT_SPLUNK_COMPRESS"
+	EnvAuditSplunkQueueSize  = "MINIO_AUDIT_SPLUNK_QUEUE_SIZE"
+	EnvAuditSplunkQueueDir   = "MINIO_AUDIT_SPLUNK_QUEUE_DIR"
+
 	loggerTargetNamePrefix = "logger-"
 	auditTargetNamePrefix  = "audit-"
 )
@@ -445,6 +456,54 @@ func lookupAuditKafkaConfig(scfg config.Config, cfg Config) (Config, error) {
 	return cfg, nil
 }
 
+// LookupAuditSplunkConfig - returns the Splunk HTTP Event Collector audit
+// target configuration, the target is configured purely through the
+// environment.
+func LookupAuditSplunkConfig() (splunk.Config, error) {
+	cfg := splunk.Config{}
+	enabled, err := config.ParseBool(env.Get(EnvAuditSplunkEnable, config.EnableOff))
+	if err != nil {
+		return cfg, err
+	}
+	if !enabled {
+		return cfg, nil
+	}
+
+	url, err := xnet.ParseHTTPURL(env.Get(EnvAuditSplunkEndpoint, ""))
+	if err != nil {
+		return cfg, err
+	}
+
+	batchSize, err := strconv.Atoi(env.Get(EnvAuditSplunkBatchSize, "10"))
+	if err != nil {
+		return cfg, err
+	}
+	if batchSize <= 0 {
+		return cfg, errInvalidBatchSize
+	}
+
+	queueSize, err := strconv.Atoi(env.Get(EnvAuditSplunkQueueSize, "100000"))
+	if err != nil {
+		return cfg, err
+	}
+	if queueSize <= 0 {
+		return cfg, errInvalidQueueSize
+	}
+
+	cfg = splunk.Config{
+		Enabled:    true,
+		Endpoint:   url,
+		Token:      env.Get(EnvAuditSplunkToken, ""),
+		Index:      env.Get(EnvAuditSplunkIndex, ""),
+		SourceType: env.Get(EnvAuditSplunkSourceType, "minio-audit"),
+		BatchSize:  batchSize,
+		Compress:   env.Get(EnvAuditSplunkCompress, config.EnableOn) == config.EnableOn,
+		QueueSize:  queueSize,
+		QueueDir:   env.Get(EnvAuditSplunkQueueDir, ""),
+	}
+	return cfg, nil
+}
+
 func lookupLoggerWebhookConfig(scfg config.Config, cfg Config) (Config, error) {
 	for k, kv := range config.Merge(scfg[config.LoggerWebhookSubSys], EnvLoggerWebhookEnable, DefaultLoggerWebhookKVS) {
 		if v, ok := cfg.HTTP[k]; ok && v.Enabled {
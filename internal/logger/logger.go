@@ -372,9 +372,12 @@ func buildLogEntry(ctx context.Context, subsystem, message string, trace []strin
 	}
 
 	if anonFlag {
-		entry.API.Args.Bucket = HashString(entry.API.Args.Bucket)
-		entry.API.Args.Object = HashString(entry.API.Args.Object)
-		entry.RemoteHost = HashString(entry.RemoteHost)
+		entry.API.Args.Bucket = Anonymize(entry.API.Args.Bucket)
+		entry.API.Args.Object = Anonymize(entry.API.Args.Object)
+		for i, ov := range entry.API.Args.Objects {
+			entry.API.Args.Objects[i].ObjectName = Anonymize(ov.ObjectName)
+		}
+		entry.RemoteHost = Anonymize(entry.RemoteHost)
 		if entry.Trace != nil {
 			entry.Trace.Variables = make(map[string]interface{})
 		}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/minio/minio/internal/logger/target/http"
 	"github.com/minio/minio/internal/logger/target/kafka"
+	"github.com/minio/minio/internal/logger/target/splunk"
 	"github.com/minio/minio/internal/logger/target/types"
 )
 
@@ -141,6 +142,17 @@ func AddSystemTarget(ctx context.Context, t Target) error {
 	return nil
 }
 
+// AddAuditTarget adds a new audit logger target to the
+// list of enabled audit loggers
+func AddAuditTarget(ctx context.Context, t Target) error {
+	if err := t.Init(ctx); err != nil {
+		return err
+	}
+
+	auditTargets.add(t)
+	return nil
+}
+
 func initKafkaTargets(ctx context.Context, cfgMap map[string]kafka.Config) ([]Target, []error) {
 	tgts := []Target{}
 	errs := []error{}
@@ -249,3 +261,24 @@ func UpdateAuditKafkaTargets(ctx context.Context, cfg Config) []error {
 	cancelTargets(oldKafkaTgts) // cancel running targets
 	return errs
 }
+
+// UpdateAuditSplunkTargets swaps the audit splunk target with a newly loaded one from the cfg
+func UpdateAuditSplunkTargets(ctx context.Context, cfg splunk.Config) (errs []error) {
+	newSplunkTgts := []Target{}
+	if cfg.Enabled {
+		t := splunk.New(cfg)
+		if err := t.Init(ctx); err != nil {
+			errs = append(errs, err)
+		} else {
+			newSplunkTgts = append(newSplunkTgts, t)
+		}
+	}
+
+	// Retain the other audit targets
+	oldSplunkTgts, otherTgts := splitTargets(auditTargets.get(), types.TargetSplunk)
+	newSplunkTgts = append(newSplunkTgts, otherTgts...)
+	auditTargets.set(newSplunkTgts)
+
+	cancelTargets(oldSplunkTgts) // cancel running targets
+	return errs
+}
@@ -21,6 +21,8 @@ package mcontext
 // packages in github.com/minio/minio
 
 import (
+	"time"
+
 	xhttp "github.com/minio/minio/internal/http"
 )
 
@@ -37,4 +39,13 @@ type TraceCtxt struct {
 
 	FuncName string
 	AmzReqID string
+
+	// Authenticated requester identity, set once the request has been
+	// authenticated, used for per-tenant metrics aggregation.
+	AccessKey  string
+	ParentUser string
+
+	// AuthTime is when request authentication completed, used by the
+	// slow operation log for its phase breakdown.
+	AuthTime time.Time
 }
@@ -0,0 +1,179 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Per-peer circuit breaker for the internode REST client. MarkOffline
+// only catches peers that are hard down; a peer that still accepts
+// connections but times out or errors on most calls keeps every caller
+// waiting for full timeouts. The breaker trips on sustained network
+// failures, fails calls fast while open, lets a single half-open probe
+// through after a cooldown and additionally keeps an AIMD concurrency
+// limit so a slow peer sheds load before it degrades the whole set.
+const (
+	// breakerWindow is the failure accounting window.
+	breakerWindow = 10 * time.Second
+
+	// breakerTripFailures is the number of window failures, exceeding
+	// window successes, that trips the breaker.
+	breakerTripFailures = 8
+
+	// breakerBaseCooldown is the initial open duration, doubled on
+	// every failed half-open probe up to breakerMaxCooldown.
+	breakerBaseCooldown = time.Second
+	breakerMaxCooldown  = 30 * time.Second
+
+	// Bounds of the adaptive concurrency limit.
+	breakerMinConcurrency = 8
+	breakerMaxConcurrency = 512
+)
+
+// Breaker states.
+const (
+	breakerClosed int32 = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// ErrCircuitOpen is returned for calls failed fast because the peer's
+// circuit breaker is open or its concurrency limit is exhausted.
+var ErrCircuitOpen = errors.New("remote peer circuit breaker is open")
+
+type circuitBreaker struct {
+	state    atomic.Int32
+	openedAt atomic.Int64 // unix nanos when the breaker opened
+	cooldown atomic.Int64 // current open duration in nanos
+
+	windowStart atomic.Int64
+	failures    atomic.Int64
+	successes   atomic.Int64
+
+	inflight atomic.Int64
+	limit    atomic.Int64
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	b := &circuitBreaker{}
+	b.limit.Store(breakerMaxConcurrency)
+	b.cooldown.Store(int64(breakerBaseCooldown))
+	b.windowStart.Store(time.Now().UnixNano())
+	return b
+}
+
+// acquire reserves a call slot, every successful acquire must be paired
+// with exactly one release.
+func (b *circuitBreaker) acquire() error {
+	switch b.state.Load() {
+	case breakerOpen:
+		if time.Now().UnixNano()-b.openedAt.Load() < b.cooldown.Load() {
+			return ErrCircuitOpen
+		}
+		// Cooldown expired, let a single probe through.
+		if !b.state.CompareAndSwap(breakerOpen, breakerHalfOpen) {
+			return ErrCircuitOpen
+		}
+	case breakerHalfOpen:
+		// A probe is already in flight.
+		return ErrCircuitOpen
+	}
+	if b.inflight.Add(1) > b.limit.Load() && b.state.Load() == breakerClosed {
+		b.inflight.Add(-1)
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// release returns the call slot, failed indicates whether the call hit
+// a network or timeout error.
+func (b *circuitBreaker) release(failed bool) {
+	b.inflight.Add(-1)
+	b.maybeRollWindow()
+	if failed {
+		b.onFailure()
+	} else {
+		b.onSuccess()
+	}
+}
+
+func (b *circuitBreaker) maybeRollWindow() {
+	now := time.Now().UnixNano()
+	start := b.windowStart.Load()
+	if now-start < int64(breakerWindow) {
+		return
+	}
+	if b.windowStart.CompareAndSwap(start, now) {
+		b.failures.Store(0)
+		b.successes.Store(0)
+	}
+}
+
+func (b *circuitBreaker) onSuccess() {
+	if b.state.CompareAndSwap(breakerHalfOpen, breakerClosed) {
+		// Probe succeeded, peer recovered.
+		b.cooldown.Store(int64(breakerBaseCooldown))
+		b.failures.Store(0)
+		b.successes.Store(0)
+		return
+	}
+	b.successes.Add(1)
+	// Additive increase of the concurrency limit.
+	if l := b.limit.Load(); l < breakerMaxConcurrency {
+		b.limit.CompareAndSwap(l, l+1)
+	}
+}
+
+func (b *circuitBreaker) onFailure() {
+	// Multiplicative decrease of the concurrency limit.
+	for {
+		l := b.limit.Load()
+		if l <= breakerMinConcurrency {
+			break
+		}
+		half := l / 2
+		if half < breakerMinConcurrency {
+			half = breakerMinConcurrency
+		}
+		if b.limit.CompareAndSwap(l, half) {
+			break
+		}
+	}
+
+	if b.state.Load() == breakerHalfOpen {
+		// Probe failed, reopen with a longer cooldown.
+		cd := b.cooldown.Load() * 2
+		if cd > int64(breakerMaxCooldown) {
+			cd = int64(breakerMaxCooldown)
+		}
+		b.cooldown.Store(cd)
+		b.openedAt.Store(time.Now().UnixNano())
+		b.state.Store(breakerOpen)
+		return
+	}
+
+	f := b.failures.Add(1)
+	if f >= breakerTripFailures && f > b.successes.Load() {
+		if b.state.CompareAndSwap(breakerClosed, breakerOpen) {
+			b.openedAt.Store(time.Now().UnixNano())
+		}
+	}
+}
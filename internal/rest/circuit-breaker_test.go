@@ -0,0 +1,104 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTripAndRecover(t *testing.T) {
+	b := newCircuitBreaker()
+
+	// Sustained failures trip the breaker.
+	for i := 0; i < breakerTripFailures; i++ {
+		if err := b.acquire(); err != nil {
+			t.Fatalf("acquire %d: unexpected error: %v", i, err)
+		}
+		b.release(true)
+	}
+	if got := b.state.Load(); got != breakerOpen {
+		t.Fatalf("state = %d, want open after %d failures", got, breakerTripFailures)
+	}
+	if err := b.acquire(); err != ErrCircuitOpen {
+		t.Fatalf("acquire while open: err = %v, want ErrCircuitOpen", err)
+	}
+
+	// After the cooldown a single probe goes through, a second caller
+	// is still rejected.
+	b.openedAt.Store(time.Now().Add(-2 * breakerBaseCooldown).UnixNano())
+	if err := b.acquire(); err != nil {
+		t.Fatalf("half-open probe rejected: %v", err)
+	}
+	if err := b.acquire(); err != ErrCircuitOpen {
+		t.Fatalf("second half-open acquire: err = %v, want ErrCircuitOpen", err)
+	}
+
+	// Successful probe closes the breaker again.
+	b.release(false)
+	if got := b.state.Load(); got != breakerClosed {
+		t.Fatalf("state = %d, want closed after successful probe", got)
+	}
+	if err := b.acquire(); err != nil {
+		t.Fatalf("acquire after recovery: %v", err)
+	}
+	b.release(false)
+}
+
+func TestCircuitBreakerFailedProbeBacksOff(t *testing.T) {
+	b := newCircuitBreaker()
+	b.state.Store(breakerOpen)
+	b.openedAt.Store(time.Now().Add(-2 * breakerBaseCooldown).UnixNano())
+
+	if err := b.acquire(); err != nil {
+		t.Fatalf("half-open probe rejected: %v", err)
+	}
+	b.release(true)
+	if got := b.state.Load(); got != breakerOpen {
+		t.Fatalf("state = %d, want open after failed probe", got)
+	}
+	if got := time.Duration(b.cooldown.Load()); got != 2*breakerBaseCooldown {
+		t.Fatalf("cooldown = %v, want %v", got, 2*breakerBaseCooldown)
+	}
+}
+
+func TestCircuitBreakerAdaptiveLimit(t *testing.T) {
+	b := newCircuitBreaker()
+
+	// Failures halve the limit down to the floor.
+	for i := 0; i < 10; i++ {
+		b.onFailure()
+	}
+	if got := b.limit.Load(); got != breakerMinConcurrency {
+		t.Fatalf("limit = %d, want floor %d", got, breakerMinConcurrency)
+	}
+
+	// Successes grow it back additively.
+	b.successes.Store(1 << 20) // keep the breaker from tripping
+	b.onSuccess()
+	if got := b.limit.Load(); got != breakerMinConcurrency+1 {
+		t.Fatalf("limit = %d, want %d", got, breakerMinConcurrency+1)
+	}
+
+	// Exhausted limit rejects callers.
+	b.inflight.Store(b.limit.Load())
+	if err := b.acquire(); err != ErrCircuitOpen {
+		t.Fatalf("acquire over limit: err = %v, want ErrCircuitOpen", err)
+	}
+	b.inflight.Store(0)
+}
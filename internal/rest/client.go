@@ -99,6 +99,7 @@ type Client struct {
 	httpClient *http.Client
 	url        *url.URL
 	auth       func() string
+	breaker    *circuitBreaker
 
 	sync.RWMutex // mutex for lastErr
 	lastErr      error
@@ -304,6 +305,15 @@ func (c *Client) CallWithHTTPMethod(ctx context.Context, httpMethod, rpcMethod s
 
 	// client is still connected, attempt the request.
 
+	// Fail fast while the peer's circuit breaker is open or its
+	// adaptive concurrency limit is exhausted.
+	if err := c.breaker.acquire(); err != nil {
+		if !c.NoMetrics {
+			atomic.AddUint64(&globalStats.errs, 1)
+		}
+		return nil, &NetworkError{Err: err}
+	}
+
 	// Shallow copy. We don't modify the *UserInfo, if set.
 	// All other fields are copied.
 	u := *c.url
@@ -326,16 +336,23 @@ func (c *Client) CallWithHTTPMethod(ctx context.Context, httpMethod, rpcMethod s
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		if xnet.IsNetworkOrHostDown(err, expectTimeouts) {
+			c.breaker.release(true)
 			if !c.NoMetrics {
 				atomic.AddUint64(&globalStats.errs, 1)
 			}
 			if c.MarkOffline(err) {
 				logger.LogOnceIf(ctx, logSubsys, fmt.Errorf("Marking %s offline temporarily; caused by %w", c.url.Host, err), c.url.Host)
 			}
+		} else {
+			c.breaker.release(false)
 		}
 		return nil, &NetworkError{err}
 	}
 
+	// A response was received, the connection to the peer works. Server
+	// side errors carried in the response are not breaker failures.
+	c.breaker.release(false)
+
 	// If trace is enabled, dump http request and response,
 	// except when the traceErrorsOnly enabled and the response's status code is ok
 	if c.TraceOutput != nil && resp.StatusCode != http.StatusOK {
@@ -411,6 +428,7 @@ func NewClient(uu *url.URL, tr http.RoundTripper, auth func() string) *Client {
 		httpClient:               &http.Client{Transport: tr},
 		url:                      u,
 		auth:                     auth,
+		breaker:                  newCircuitBreaker(),
 		connected:                connected,
 		lastConn:                 time.Now().UnixNano(),
 		MaxErrResponseSize:       4096,
@@ -0,0 +1,196 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package arrow
+
+import "encoding/binary"
+
+// builder is a minimal flatbuffers builder, implementing just the
+// subset of the wire format needed to emit Arrow IPC metadata messages
+// (tables with scalar, offset and vector fields). Buffers are built
+// back to front as mandated by the flatbuffers format, offsets are
+// expressed as distance from the end of the buffer.
+type builder struct {
+	buf       []byte
+	head      int // index of first used byte in buf
+	minalign  int
+	vtable    []int
+	objectEnd int
+}
+
+func newBuilder() *builder {
+	return &builder{
+		buf:      make([]byte, 1024),
+		head:     1024,
+		minalign: 1,
+	}
+}
+
+// offset returns the current write position measured from the end of
+// the buffer.
+func (b *builder) offset() int {
+	return len(b.buf) - b.head
+}
+
+// grow doubles the buffer, keeping existing content at the end.
+func (b *builder) grow() {
+	old := b.buf
+	b.buf = make([]byte, 2*len(old))
+	copy(b.buf[len(old):], old)
+	b.head += len(old)
+}
+
+func (b *builder) pad(n int) {
+	for i := 0; i < n; i++ {
+		b.placeByte(0)
+	}
+}
+
+// prep aligns the buffer such that after size+additional bytes are
+// written the write position is aligned to size.
+func (b *builder) prep(size, additional int) {
+	if size > b.minalign {
+		b.minalign = size
+	}
+	alignSize := (^(len(b.buf) - b.head + additional) + 1) & (size - 1)
+	for b.head <= alignSize+size+additional {
+		b.grow()
+	}
+	b.pad(alignSize)
+}
+
+func (b *builder) placeByte(v byte) {
+	b.head--
+	b.buf[b.head] = v
+}
+
+func (b *builder) placeUint16(v uint16) {
+	b.head -= 2
+	binary.LittleEndian.PutUint16(b.buf[b.head:], v)
+}
+
+func (b *builder) placeUint32(v uint32) {
+	b.head -= 4
+	binary.LittleEndian.PutUint32(b.buf[b.head:], v)
+}
+
+func (b *builder) placeUint64(v uint64) {
+	b.head -= 8
+	binary.LittleEndian.PutUint64(b.buf[b.head:], v)
+}
+
+func (b *builder) prependByte(v byte) {
+	b.prep(1, 0)
+	b.placeByte(v)
+}
+
+func (b *builder) prependUint16(v uint16) {
+	b.prep(2, 0)
+	b.placeUint16(v)
+}
+
+func (b *builder) prependUint32(v uint32) {
+	b.prep(4, 0)
+	b.placeUint32(v)
+}
+
+func (b *builder) prependUint64(v uint64) {
+	b.prep(8, 0)
+	b.placeUint64(v)
+}
+
+// prependUOffset writes a 32-bit unsigned offset pointing forward to a
+// previously written element.
+func (b *builder) prependUOffset(off int) {
+	b.prep(4, 0)
+	b.placeUint32(uint32(b.offset() - off + 4))
+}
+
+// createString writes a null terminated, length prefixed string and
+// returns its offset.
+func (b *builder) createString(s string) int {
+	b.prep(4, len(s)+1)
+	b.placeByte(0)
+	b.head -= len(s)
+	copy(b.buf[b.head:], s)
+	b.placeUint32(uint32(len(s)))
+	return b.offset()
+}
+
+// startVector aligns for a vector of n elements of elemSize bytes.
+func (b *builder) startVector(elemSize, n, alignment int) {
+	b.prep(4, elemSize*n)
+	b.prep(alignment, elemSize*n)
+}
+
+// endVector writes the length prefix and returns the vector offset.
+func (b *builder) endVector(n int) int {
+	b.placeUint32(uint32(n))
+	return b.offset()
+}
+
+// startObject begins a table with the given number of vtable slots.
+func (b *builder) startObject(numFields int) {
+	b.vtable = make([]int, numFields)
+	b.objectEnd = b.offset()
+}
+
+// slot records the current offset as the value of vtable slot i, to be
+// called right after the field value is prepended.
+func (b *builder) slot(i int) {
+	b.vtable[i] = b.offset()
+}
+
+// endObject writes the vtable and returns the offset of the finished
+// table.
+func (b *builder) endObject() int {
+	// Placeholder for the soffset to the vtable.
+	b.prep(4, 0)
+	b.placeUint32(0)
+	objectOffset := b.offset()
+
+	// Trim trailing unset slots.
+	i := len(b.vtable)
+	for i > 0 && b.vtable[i-1] == 0 {
+		i--
+	}
+	vtable := b.vtable[:i]
+
+	for j := len(vtable) - 1; j >= 0; j-- {
+		if vtable[j] != 0 {
+			b.prependUint16(uint16(objectOffset - vtable[j]))
+		} else {
+			b.prependUint16(0)
+		}
+	}
+	b.prependUint16(uint16(objectOffset - b.objectEnd))
+	b.prependUint16(uint16((len(vtable) + 2) * 2))
+
+	// Patch the placeholder to point back at the vtable.
+	vtableOffset := b.offset()
+	loc := len(b.buf) - objectOffset
+	binary.LittleEndian.PutUint32(b.buf[loc:], uint32(vtableOffset-objectOffset))
+	b.vtable = nil
+	return objectOffset
+}
+
+// finish writes the root table offset and returns the finished buffer.
+func (b *builder) finish(root int) []byte {
+	b.prep(b.minalign, 4)
+	b.prependUOffset(root)
+	return b.buf[b.head:]
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package arrow implements a dependency-free Apache Arrow IPC stream
+// writer for S3 Select results. All result columns are emitted as
+// non-nullable Utf8 arrays so analytical clients can load query
+// results directly into dataframes.
+package arrow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Arrow flatbuffers enum values, from the Arrow format definitions.
+const (
+	metadataV5 = 4 // MetadataVersion.V5
+
+	// MessageHeader union members.
+	headerSchema      = 1
+	headerRecordBatch = 3
+
+	// Type union members.
+	typeUtf8 = 5
+)
+
+// Message encapsulation framing.
+const (
+	continuationMarker = 0xFFFFFFFF
+	bufferAlignment    = 8
+)
+
+// Writer streams Arrow IPC encapsulated messages. The schema message
+// is derived from the first batch and emitted once, every subsequent
+// batch must carry the same number of columns.
+type Writer struct {
+	names         []string
+	schemaWritten bool
+}
+
+// NewWriter - creates a new Arrow IPC stream writer.
+func NewWriter() *Writer {
+	return &Writer{}
+}
+
+func paddedLen(n int) int {
+	return (n + bufferAlignment - 1) &^ (bufferAlignment - 1)
+}
+
+// writeMessage writes one encapsulated message: continuation marker,
+// metadata length, flatbuffer metadata padded to 8 bytes, then body.
+func writeMessage(w io.Writer, meta, body []byte) error {
+	metaPadded := paddedLen(len(meta))
+	var prefix [8]byte
+	binary.LittleEndian.PutUint32(prefix[:4], continuationMarker)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(metaPadded))
+	if _, err := w.Write(prefix[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(meta); err != nil {
+		return err
+	}
+	if pad := metaPadded - len(meta); pad > 0 {
+		if _, err := w.Write(make([]byte, pad)); err != nil {
+			return err
+		}
+	}
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaMessage encodes a Message carrying a Schema of non-nullable
+// Utf8 fields named after the result columns.
+func schemaMessage(names []string) []byte {
+	b := newBuilder()
+
+	fieldOffsets := make([]int, len(names))
+	for i, name := range names {
+		nameOffset := b.createString(name)
+
+		// Utf8 is an empty table.
+		b.startObject(0)
+		utf8Offset := b.endObject()
+
+		// table Field { name, nullable, type_type, type, dictionary, children, custom_metadata }
+		b.startObject(7)
+		b.prependUOffset(nameOffset)
+		b.slot(0)
+		b.prependByte(1) // nullable, tolerate missing columns
+		b.slot(1)
+		b.prependByte(typeUtf8)
+		b.slot(2)
+		b.prependUOffset(utf8Offset)
+		b.slot(3)
+		fieldOffsets[i] = b.endObject()
+	}
+
+	b.startVector(4, len(fieldOffsets), 4)
+	for i := len(fieldOffsets) - 1; i >= 0; i-- {
+		b.prependUOffset(fieldOffsets[i])
+	}
+	fieldsOffset := b.endVector(len(fieldOffsets))
+
+	// table Schema { endianness, fields, custom_metadata, features }
+	b.startObject(4)
+	b.prependUOffset(fieldsOffset)
+	b.slot(1)
+	schemaOffset := b.endObject()
+
+	return messageAround(b, headerSchema, schemaOffset, 0)
+}
+
+// recordBatchMessage encodes the metadata Message of a record batch of
+// Utf8 columns and assembles its body buffers.
+func recordBatchMessage(rows [][]string, numCols int) (meta, body []byte) {
+	numRows := len(rows)
+
+	// Body: per column a validity buffer (empty, no nulls), an int32
+	// offsets buffer and the concatenated string data, each padded to
+	// 8 bytes.
+	type bufref struct{ offset, length int }
+	bufs := make([]bufref, 0, 3*numCols)
+	var bodyLen int
+	for col := 0; col < numCols; col++ {
+		bufs = append(bufs, bufref{bodyLen, 0}) // validity
+
+		offsets := make([]byte, (numRows+1)*4)
+		var pos uint32
+		data := make([]byte, 0, 64)
+		for row := range rows {
+			var v string
+			if col < len(rows[row]) {
+				v = rows[row][col]
+			}
+			binary.LittleEndian.PutUint32(offsets[row*4:], pos)
+			data = append(data, v...)
+			pos += uint32(len(v))
+		}
+		binary.LittleEndian.PutUint32(offsets[numRows*4:], pos)
+
+		bufs = append(bufs, bufref{bodyLen, len(offsets)})
+		bodyLen += paddedLen(len(offsets))
+		body = append(body, offsets...)
+		body = append(body, make([]byte, paddedLen(len(offsets))-len(offsets))...)
+
+		bufs = append(bufs, bufref{bodyLen, len(data)})
+		bodyLen += paddedLen(len(data))
+		body = append(body, data...)
+		body = append(body, make([]byte, paddedLen(len(data))-len(data))...)
+	}
+
+	b := newBuilder()
+
+	// buffers: [Buffer], struct Buffer { offset: long, length: long }
+	b.startVector(16, len(bufs), 8)
+	for i := len(bufs) - 1; i >= 0; i-- {
+		b.prep(8, 16)
+		b.placeUint64(uint64(bufs[i].length))
+		b.placeUint64(uint64(bufs[i].offset))
+	}
+	buffersOffset := b.endVector(len(bufs))
+
+	// nodes: [FieldNode], struct FieldNode { length: long, null_count: long }
+	b.startVector(16, numCols, 8)
+	for i := numCols - 1; i >= 0; i-- {
+		b.prep(8, 16)
+		b.placeUint64(0) // null_count
+		b.placeUint64(uint64(numRows))
+	}
+	nodesOffset := b.endVector(numCols)
+
+	// table RecordBatch { length, nodes, buffers, compression }
+	b.startObject(4)
+	b.prependUint64(uint64(numRows))
+	b.slot(0)
+	b.prependUOffset(nodesOffset)
+	b.slot(1)
+	b.prependUOffset(buffersOffset)
+	b.slot(2)
+	batchOffset := b.endObject()
+
+	return messageAround(b, headerRecordBatch, batchOffset, len(body)), body
+}
+
+// messageAround wraps a finished header table into the outer Message
+// table and returns the finished flatbuffer.
+func messageAround(b *builder, headerType byte, headerOffset, bodyLength int) []byte {
+	// table Message { version, header_type, header, bodyLength, custom_metadata }
+	b.startObject(5)
+	b.prependUint16(metadataV5)
+	b.slot(0)
+	b.prependByte(headerType)
+	b.slot(1)
+	b.prependUOffset(headerOffset)
+	b.slot(2)
+	b.prependUint64(uint64(bodyLength))
+	b.slot(3)
+	return b.finish(b.endObject())
+}
+
+// WriteBatch writes one record batch, emitting the schema message
+// first if this is the first batch of the stream.
+func (aw *Writer) WriteBatch(w io.Writer, names []string, rows [][]string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if !aw.schemaWritten {
+		aw.names = append([]string{}, names...)
+		if err := writeMessage(w, schemaMessage(aw.names), nil); err != nil {
+			return err
+		}
+		aw.schemaWritten = true
+	}
+	if len(names) != len(aw.names) {
+		return fmt.Errorf("arrow: expected %d columns, got %d", len(aw.names), len(names))
+	}
+	meta, body := recordBatchMessage(rows, len(aw.names))
+	return writeMessage(w, meta, body)
+}
+
+// Finish writes the end-of-stream marker. A no-op stream that never
+// produced a batch emits no schema either, which readers treat as an
+// empty result.
+func (aw *Writer) Finish(w io.Writer) error {
+	if !aw.schemaWritten {
+		return nil
+	}
+	// Continuation marker followed by a zero metadata length.
+	return writeMessage(w, nil, nil)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2015-2024 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package arrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// message is a parsed encapsulated IPC message.
+type message struct {
+	meta []byte
+	body []byte
+}
+
+// parseMessage splits one encapsulated message written by writeMessage.
+func parseMessage(t *testing.T, b []byte) message {
+	t.Helper()
+	if len(b) < 8 {
+		t.Fatalf("message too short: %d bytes", len(b))
+	}
+	if binary.LittleEndian.Uint32(b[:4]) != continuationMarker {
+		t.Fatalf("missing continuation marker")
+	}
+	metaLen := int(binary.LittleEndian.Uint32(b[4:8]))
+	if metaLen%8 != 0 {
+		t.Fatalf("metadata length %d not padded to 8 bytes", metaLen)
+	}
+	if len(b) < 8+metaLen {
+		t.Fatalf("truncated metadata: want %d bytes, have %d", metaLen, len(b)-8)
+	}
+	return message{meta: b[8 : 8+metaLen], body: b[8+metaLen:]}
+}
+
+// tableField reads a scalar field of a flatbuffer root table, returning
+// raw bytes of the field value and whether the slot is present.
+func tableField(t *testing.T, buf []byte, slot int) ([]byte, bool) {
+	t.Helper()
+	root := int(binary.LittleEndian.Uint32(buf[:4]))
+	soffset := int(int32(binary.LittleEndian.Uint32(buf[root:])))
+	vtable := root - soffset
+	vtableLen := int(binary.LittleEndian.Uint16(buf[vtable:]))
+	slotPos := vtable + 4 + slot*2
+	if slotPos >= vtable+vtableLen {
+		return nil, false
+	}
+	fieldOff := int(binary.LittleEndian.Uint16(buf[slotPos:]))
+	if fieldOff == 0 {
+		return nil, false
+	}
+	return buf[root+fieldOff:], true
+}
+
+func TestWriterStreamFraming(t *testing.T) {
+	w := NewWriter()
+
+	var schemaAndBatch bytes.Buffer
+	err := w.WriteBatch(&schemaAndBatch, []string{"a", "b"}, [][]string{
+		{"foo", "1"},
+		{"bar", "2"},
+		{"baz", "3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var eos bytes.Buffer
+	if err = w.Finish(&eos); err != nil {
+		t.Fatal(err)
+	}
+
+	// First message is the schema, it has no body. Locate the second
+	// message by scanning for the next continuation marker prefix.
+	b := schemaAndBatch.Bytes()
+	metaLen := int(binary.LittleEndian.Uint32(b[4:8]))
+	schemaMsg := parseMessage(t, b[:8+metaLen])
+	batchMsg := parseMessage(t, b[8+metaLen:])
+
+	// Message.header_type is slot 1, Schema=1 and RecordBatch=3.
+	ht, ok := tableField(t, schemaMsg.meta, 1)
+	if !ok || ht[0] != headerSchema {
+		t.Fatalf("expected schema header in first message")
+	}
+	if len(schemaMsg.body) != 0 {
+		t.Fatalf("schema message should have no body, got %d bytes", len(schemaMsg.body))
+	}
+
+	ht, ok = tableField(t, batchMsg.meta, 1)
+	if !ok || ht[0] != headerRecordBatch {
+		t.Fatalf("expected record batch header in second message")
+	}
+
+	// Message.bodyLength is slot 3 and must match the actual body.
+	bl, ok := tableField(t, batchMsg.meta, 3)
+	if !ok {
+		t.Fatalf("record batch message has no bodyLength")
+	}
+	if got := binary.LittleEndian.Uint64(bl); int(got) != len(batchMsg.body) {
+		t.Fatalf("bodyLength %d does not match body size %d", got, len(batchMsg.body))
+	}
+	if len(batchMsg.body)%8 != 0 {
+		t.Fatalf("body not padded to 8 bytes")
+	}
+	if !bytes.Contains(batchMsg.body, []byte("foobarbaz")) {
+		t.Fatalf("body does not contain concatenated column data")
+	}
+
+	// End of stream is a continuation marker with zero length.
+	if !bytes.Equal(eos.Bytes(), []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}) {
+		t.Fatalf("unexpected end-of-stream marker %v", eos.Bytes())
+	}
+}
+
+func TestWriterColumnMismatch(t *testing.T) {
+	w := NewWriter()
+	var buf bytes.Buffer
+	if err := w.WriteBatch(&buf, []string{"a"}, [][]string{{"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteBatch(&buf, []string{"a", "b"}, [][]string{{"1", "2"}}); err == nil {
+		t.Fatal("expected column count mismatch error")
+	}
+}
@@ -123,6 +123,18 @@ func (r *Record) WriteCSV(writer io.Writer, opts sql.WriteCSVOpts) error {
 	return w.Error()
 }
 
+// Columns - iterates all columns in order with values as strings.
+func (r *Record) Columns(fn func(name, value string) error) error {
+	for i, cn := range r.columnNames {
+		if i < len(r.csvRecord) {
+			if err := fn(cn, r.csvRecord[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // WriteJSON - encodes to JSON data.
 func (r *Record) WriteJSON(writer io.Writer) error {
 	var kvs jstream.KVS = make([]jstream.KV, 0, len(r.columnNames))
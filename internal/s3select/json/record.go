@@ -150,6 +150,37 @@ func (r *Record) WriteCSV(writer io.Writer, opts sql.WriteCSVOpts) error {
 	return w.Error()
 }
 
+// Columns - iterates all columns in order with values as strings.
+func (r *Record) Columns(fn func(name, value string) error) error {
+	for _, kv := range r.KVS {
+		var columnValue string
+		switch val := kv.Value.(type) {
+		case float64:
+			columnValue = jsonFloat(val)
+		case string:
+			columnValue = val
+		case bool, int64:
+			columnValue = fmt.Sprintf("%v", val)
+		case nil:
+			columnValue = ""
+		case RawJSON:
+			columnValue = string([]byte(val))
+		case []interface{}:
+			b, err := json.Marshal(val)
+			if err != nil {
+				return err
+			}
+			columnValue = string(b)
+		default:
+			return fmt.Errorf("Cannot marshal unhandled type: %T", kv.Value)
+		}
+		if err := fn(kv.Key, columnValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Raw - returns the underlying representation.
 func (r *Record) Raw() (sql.SelectObjectFormat, interface{}) {
 	return r.SelectFormat, r.KVS
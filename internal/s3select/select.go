@@ -34,6 +34,7 @@ import (
 	gzip "github.com/klauspost/pgzip"
 	"github.com/minio/minio/internal/config"
 	xioutil "github.com/minio/minio/internal/ioutil"
+	"github.com/minio/minio/internal/s3select/arrow"
 	"github.com/minio/minio/internal/s3select/csv"
 	"github.com/minio/minio/internal/s3select/json"
 	"github.com/minio/minio/internal/s3select/parquet"
@@ -55,6 +56,7 @@ const (
 	csvFormat     = "csv"
 	jsonFormat    = "json"
 	parquetFormat = "parquet"
+	arrowFormat   = "arrow"
 )
 
 // CompressionType - represents value inside <CompressionType/> in request XML.
@@ -176,8 +178,9 @@ func (input *InputSerialization) UnmarshalXML(d *xml.Decoder, start xml.StartEle
 
 // OutputSerialization - represents elements inside <OutputSerialization/> in request XML.
 type OutputSerialization struct {
-	CSVArgs     csv.WriterArgs  `xml:"CSV"`
-	JSONArgs    json.WriterArgs `xml:"JSON"`
+	CSVArgs     csv.WriterArgs   `xml:"CSV"`
+	JSONArgs    json.WriterArgs  `xml:"JSON"`
+	ArrowArgs   arrow.WriterArgs `xml:"Arrow"`
 	unmarshaled bool
 	format      string
 }
@@ -205,8 +208,12 @@ func (output *OutputSerialization) UnmarshalXML(d *xml.Decoder, start xml.StartE
 		parsedOutput.format = jsonFormat
 		found++
 	}
+	if !parsedOutput.ArrowArgs.IsEmpty() {
+		parsedOutput.format = arrowFormat
+		found++
+	}
 	if found != 1 {
-		return errObjectSerializationConflict(fmt.Errorf("either CSV or JSON should be present in OutputSerialization"))
+		return errObjectSerializationConflict(fmt.Errorf("either CSV, JSON or Arrow should be present in OutputSerialization"))
 	}
 
 	*output = OutputSerialization(parsedOutput)
@@ -297,6 +304,7 @@ type S3Select struct {
 	statement      *sql.SelectStatement
 	progressReader *progressReader
 	recordReader   recordReader
+	arrowWriter    *arrow.Writer
 }
 
 var legacyXMLName = "SelectObjectContentRequest"
@@ -352,7 +360,7 @@ func (s3Select *S3Select) outputRecord() sql.Record {
 	switch s3Select.Output.format {
 	case csvFormat:
 		return csv.NewRecord()
-	case jsonFormat:
+	case jsonFormat, arrowFormat:
 		return json.NewRecord(sql.SelectFmtJSON)
 	}
 
@@ -512,6 +520,38 @@ func (s3Select *S3Select) marshal(buf *bytes.Buffer, record sql.Record) error {
 	panic(fmt.Errorf("unknown output format '%v'", s3Select.Output.format))
 }
 
+// marshalArrow encodes all queued output records as a single Arrow IPC
+// record batch of string columns, emitting the schema message first on
+// the initial batch of the stream.
+func (s3Select *S3Select) marshalArrow(buf *bytes.Buffer, records []sql.Record) error {
+	if s3Select.arrowWriter == nil {
+		s3Select.arrowWriter = arrow.NewWriter()
+	}
+
+	var names []string
+	rows := make([][]string, 0, len(records))
+	first := true
+	for _, record := range records {
+		if record == nil {
+			continue
+		}
+		row := make([]string, 0, len(names))
+		if err := record.Columns(func(name, value string) error {
+			if first {
+				names = append(names, name)
+			}
+			row = append(row, value)
+			return nil
+		}); err != nil {
+			return err
+		}
+		first = false
+		rows = append(rows, row)
+	}
+
+	return s3Select.arrowWriter.WriteBatch(buf, names, rows)
+}
+
 // Evaluate - filters and sends records read from opened reader as per select statement to http response writer.
 func (s3Select *S3Select) Evaluate(w http.ResponseWriter) {
 	getProgressFunc := s3Select.getProgress
@@ -533,20 +573,32 @@ func (s3Select *S3Select) Evaluate(w http.ResponseWriter) {
 		buf := bufPool.Get().(*bytes.Buffer)
 		buf.Reset()
 
-		for _, outputRecord := range outputQueue {
-			if outputRecord == nil {
-				continue
-			}
-			before := buf.Len()
-			if err = s3Select.marshal(buf, outputRecord); err != nil {
+		if s3Select.Output.format == arrowFormat {
+			if err = s3Select.marshalArrow(buf, outputQueue); err != nil {
 				bufPool.Put(buf)
 				return false
 			}
-			if buf.Len()-before > maxRecordSize {
+			if buf.Len() > maxRecordSize {
 				writer.FinishWithError("OverMaxRecordSize", "The length of a record in the input or result is greater than maxCharsPerRecord of 1 MB.")
 				bufPool.Put(buf)
 				return false
 			}
+		} else {
+			for _, outputRecord := range outputQueue {
+				if outputRecord == nil {
+					continue
+				}
+				before := buf.Len()
+				if err = s3Select.marshal(buf, outputRecord); err != nil {
+					bufPool.Put(buf)
+					return false
+				}
+				if buf.Len()-before > maxRecordSize {
+					writer.FinishWithError("OverMaxRecordSize", "The length of a record in the input or result is greater than maxCharsPerRecord of 1 MB.")
+					bufPool.Put(buf)
+					return false
+				}
+			}
 		}
 
 		if err = writer.SendRecord(buf); err != nil {
@@ -559,6 +611,23 @@ func (s3Select *S3Select) Evaluate(w http.ResponseWriter) {
 		return true
 	}
 
+	// sendArrowEOS flushes the Arrow end-of-stream marker before the
+	// event stream is finished, a no-op for other output formats.
+	sendArrowEOS := func() {
+		if s3Select.Output.format != arrowFormat || s3Select.arrowWriter == nil {
+			return
+		}
+		buf := bufPool.Get().(*bytes.Buffer)
+		buf.Reset()
+		if werr := s3Select.arrowWriter.Finish(buf); werr != nil || buf.Len() == 0 {
+			bufPool.Put(buf)
+			return
+		}
+		if werr := writer.SendRecord(buf); werr != nil {
+			bufPool.Put(buf)
+		}
+	}
+
 	var rec sql.Record
 OuterLoop:
 	for {
@@ -566,6 +635,7 @@ OuterLoop:
 			if !sendRecord() {
 				break
 			}
+			sendArrowEOS()
 			if err = writer.Finish(s3Select.getProgress()); err != nil {
 				// FIXME: log this error.
 				err = nil
@@ -590,6 +660,7 @@ OuterLoop:
 				break
 			}
 
+			sendArrowEOS()
 			if err = writer.Finish(s3Select.getProgress()); err != nil {
 				// FIXME: log this error.
 				err = nil
@@ -638,6 +709,7 @@ OuterLoop:
 					if !sendRecord() {
 						break
 					}
+					sendArrowEOS()
 					if err = writer.Finish(s3Select.getProgress()); err != nil {
 						// FIXME: log this error.
 						err = nil
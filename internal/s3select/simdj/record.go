@@ -184,6 +184,40 @@ allElems:
 	return w.Error()
 }
 
+// Columns - iterates all columns in order with values as strings.
+func (r *Record) Columns(fn func(name, value string) error) error {
+	var tmp simdjson.Iter
+	obj := r.object
+	for {
+		name, typ, err := obj.NextElement(&tmp)
+		if err != nil {
+			return err
+		}
+		var columnValue string
+		switch typ {
+		case simdjson.TypeNull, simdjson.TypeFloat, simdjson.TypeUint, simdjson.TypeInt, simdjson.TypeBool, simdjson.TypeString:
+			val, err := tmp.StringCvt()
+			if err != nil {
+				return err
+			}
+			columnValue = val
+		case simdjson.TypeObject, simdjson.TypeArray:
+			b, err := tmp.MarshalJSON()
+			if err != nil {
+				return err
+			}
+			columnValue = string(b)
+		case simdjson.TypeNone:
+			return nil
+		default:
+			return fmt.Errorf("cannot marshal unhandled type: %s", typ.String())
+		}
+		if err := fn(name, columnValue); err != nil {
+			return err
+		}
+	}
+}
+
 // Raw - returns the underlying representation.
 func (r *Record) Raw() (sql.SelectObjectFormat, interface{}) {
 	return sql.SelectFmtSIMDJSON, r.object
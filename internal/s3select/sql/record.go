@@ -58,6 +58,10 @@ type Record interface {
 	WriteCSV(writer io.Writer, opts WriteCSVOpts) error
 	WriteJSON(writer io.Writer) error
 
+	// Columns iterates all columns of the record in order with
+	// values rendered as strings, used by columnar output formats.
+	Columns(fn func(name, value string) error) error
+
 	// Clone the record and if possible use the destination provided.
 	Clone(dst Record) Record
 	Reset()